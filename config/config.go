@@ -1,41 +1,399 @@
+// Package config memuat konfigurasi aplikasi secara berlapis: nilai default, file YAML,
+// variabel lingkungan (termasuk file secret lewat <ENV>_FILE), lalu flag command line.
 package config
 
 import (
-	"log"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
 )
 
+// Config adalah seluruh konfigurasi aplikasi. Setiap field punya key YAML, nama variabel
+// lingkungan (env) dan flag "-<section>.<key>"; field secret disamarkan oleh Redacted.
 type Config struct {
-	DatabaseURL string
-	ServerPort  string
-	JWTSecretKey string
+	Server       ServerConfig       `yaml:"server"`
+	Database     DatabaseConfig     `yaml:"database"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Password     PasswordConfig     `yaml:"password"`
+	Storage      StorageConfig      `yaml:"storage"`
+	CORS         CORSConfig         `yaml:"cors"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
+	SMTP         SMTPConfig         `yaml:"smtp"`
+	Notification NotificationConfig `yaml:"notification"`
+	Log          LogConfig          `yaml:"log"`
+	Metrics      MetricsConfig      `yaml:"metrics"`
+	API          APIConfig          `yaml:"api"`
+
+	// Timezone dipakai untuk tanggal batas lapor, pengingat dan ringkasan harian.
+	Timezone string `yaml:"timezone" env:"TIMEZONE" usage:"IANA time zone of the office"`
 }
 
-func LoadConfig() *Config {
-	// Try to load .env file if it exists (for local development)
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, assuming environment variables are set.")
+// ServerConfig mengatur server HTTP.
+type ServerConfig struct {
+	Port string `yaml:"port" env:"SERVER_PORT" usage:"listen address, e.g. :8080"`
+	// BaseURL adalah alamat dashboard untuk tautan di email.
+	BaseURL string `yaml:"base_url" env:"APP_BASE_URL" usage:"public URL of the dashboard, used in emails"`
+	// Read/Write cukup longgar untuk upload 20MB dan export Excel; stream notifikasi
+	// mengatur deadline-nya sendiri.
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" usage:"maximum duration for reading a request"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" usage:"maximum duration for writing a response"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" usage:"keep-alive idle timeout"`
+	// ShutdownTimeout adalah batas waktu menunggu request dan worker selesai setelah SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"grace period for draining requests and workers"`
+	// TrustedProxies adalah IP/CIDR load balancer yang boleh menentukan IP klien lewat
+	// X-Forwarded-For. Kosong berarti IP koneksi langsung yang dipakai (dan dibatasi rate limiter).
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"comma-separated IPs/CIDRs of reverse proxies"`
+}
+
+// DatabaseConfig mengatur koneksi PostgreSQL.
+type DatabaseConfig struct {
+	URL             string        `yaml:"url" env:"DATABASE_URL" secret:"password" usage:"PostgreSQL connection URL"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum open connections, 0 is unlimited"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum idle connections"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"maximum lifetime of a connection"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"maximum idle time of a connection"`
+	// StatementTimeout membatasi setiap panggilan repository; request yang dibatalkan klien
+	// tetap membatalkan query-nya walaupun batas ini belum tercapai.
+	StatementTimeout   time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT" usage:"maximum duration of one repository call, 0 is unlimited"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" usage:"log repository calls slower than this, 0 disables"`
+}
+
+// AuthConfig mengatur token login staf dan portal klien.
+type AuthConfig struct {
+	JWTSecret      string        `yaml:"jwt_secret" env:"JWT_SECRET_KEY" secret:"true" usage:"HMAC key for signing login tokens"`
+	TokenTTL       time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL" usage:"lifetime of staff login tokens"`
+	PortalTokenTTL time.Duration `yaml:"portal_token_ttl" env:"PORTAL_TOKEN_TTL" usage:"lifetime of client portal login tokens"`
+	// TOTPRequiredRoles adalah role staf yang wajib memakai login dua langkah; role lain boleh
+	// mengaktifkannya sendiri.
+	TOTPRequiredRoles []string `yaml:"totp_required_roles" env:"TOTP_REQUIRED_ROLES" usage:"comma-separated staff roles that must use 2FA"`
+	// TOTPIssuer adalah nama akun yang tampil di aplikasi authenticator.
+	TOTPIssuer string `yaml:"totp_issuer" env:"TOTP_ISSUER" usage:"issuer name shown in authenticator apps"`
+	// MFAChallengeTTL adalah batas waktu memasukkan kode 2FA setelah password benar.
+	MFAChallengeTTL time.Duration `yaml:"mfa_challenge_ttl" env:"MFA_CHALLENGE_TTL" usage:"time allowed for the second login step"`
+}
+
+// PasswordConfig mengatur kebijakan password staf, penguncian akun setelah login gagal
+// berulang, dan token reset password lewat email.
+type PasswordConfig struct {
+	MinLength  int `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" usage:"minimum password length"`
+	MinClasses int `yaml:"min_classes" env:"PASSWORD_MIN_CLASSES" usage:"minimum character classes (lower, upper, digit, symbol), 0-4"`
+	// BreachedListFile menambah daftar password bocor bawaan: satu password atau hash SHA-1 per baris.
+	BreachedListFile string `yaml:"breached_list_file" env:"PASSWORD_BREACHED_LIST_FILE" usage:"file of breached passwords or SHA-1 hashes, one per line"`
+	// LockoutThreshold 0 mematikan penguncian akun.
+	LockoutThreshold int           `yaml:"lockout_threshold" env:"LOGIN_LOCKOUT_THRESHOLD" usage:"failed logins before an account is locked, 0 disables lockout"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION" usage:"how long a locked account stays locked"`
+	ResetTokenTTL    time.Duration `yaml:"reset_token_ttl" env:"PASSWORD_RESET_TTL" usage:"lifetime of password reset links"`
+}
+
+// StorageConfig mengatur penyimpanan file upload.
+type StorageConfig struct {
+	// Backend saat ini hanya "local": file disimpan di UploadDir dan dilayani di /uploads.
+	Backend   string `yaml:"backend" env:"STORAGE_BACKEND" usage:"file storage backend (local)"`
+	UploadDir string `yaml:"upload_dir" env:"UPLOAD_DIR" usage:"directory of uploaded files"`
+	// PortalUploadDir menyimpan upload klien dari portal. Folder ini tidak dilayani
+	// publik; file hanya bisa diunduh lewat endpoint dokumen yang memeriksa akses.
+	PortalUploadDir string `yaml:"portal_upload_dir" env:"PORTAL_UPLOAD_DIR" usage:"directory of client portal uploads, never served publicly"`
+}
+
+// CORSConfig mengatur origin browser yang boleh memanggil API.
+type CORSConfig struct {
+	AllowedOrigins []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" usage:"comma-separated allowed origins, * for any"`
+	MaxAge         time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" usage:"how long browsers may cache preflight responses"`
+}
+
+// RateLimitConfig mengatur pembatasan percobaan login (token bucket): maksimal burst
+// percobaan sekaligus, lalu satu percobaan lagi setiap refill.
+type RateLimitConfig struct {
+	// Store "memory" untuk satu instance, "postgres" agar batas berlaku bersama di semua instance.
+	Store            string        `yaml:"store" env:"RATE_LIMIT_STORE" usage:"limiter store: memory or postgres"`
+	LoginIPBurst     int           `yaml:"login_ip_burst" env:"RATE_LIMIT_LOGIN_IP_BURST" usage:"login attempts per client IP before limiting"`
+	LoginIPRefill    time.Duration `yaml:"login_ip_refill" env:"RATE_LIMIT_LOGIN_IP_REFILL" usage:"interval of one more login attempt per client IP"`
+	LoginEmailBurst  int           `yaml:"login_email_burst" env:"RATE_LIMIT_LOGIN_EMAIL_BURST" usage:"login attempts per email before limiting"`
+	LoginEmailRefill time.Duration `yaml:"login_email_refill" env:"RATE_LIMIT_LOGIN_EMAIL_REFILL" usage:"interval of one more login attempt per email"`
+}
+
+// SMTPConfig mengatur pengiriman email notifikasi. Host kosong berarti email hanya dicatat
+// ke log. Untuk lokal gunakan MailHog: host localhost, port 1025.
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST" usage:"SMTP server host, empty logs emails instead"`
+	Port     int    `yaml:"port" env:"SMTP_PORT" usage:"SMTP server port"`
+	Username string `yaml:"username" env:"SMTP_USERNAME" usage:"SMTP username"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true" usage:"SMTP password"`
+	From     string `yaml:"from" env:"SMTP_FROM" usage:"sender address of notification emails"`
+}
+
+// NotificationConfig mengatur penjadwal notifikasi harian.
+type NotificationConfig struct {
+	DigestHour int `yaml:"digest_hour" env:"NOTIFICATION_DIGEST_HOUR" usage:"hour (0-23) of the daily emails"`
+}
+
+// LogConfig mengatur log terstruktur.
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" usage:"debug, info, warn or error"`
+}
+
+// MetricsConfig mengatur endpoint /metrics.
+type MetricsConfig struct {
+	// Token melindungi /metrics (Authorization: Bearer <token>). Kosong berarti /metrics tidak aktif.
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true" usage:"bearer token for /metrics, empty disables it"`
+}
+
+// APIConfig mengatur dokumentasi OpenAPI dan validasi request terhadapnya.
+type APIConfig struct {
+	// Docs melayani /api/v1/openapi.json dan Swagger UI di /api/v1/docs tanpa login, jadi
+	// defaultnya mati; aktifkan hanya di lingkungan pengembangan atau di belakang proxy internal.
+	Docs bool `yaml:"docs" env:"API_DOCS" usage:"serve the OpenAPI document and Swagger UI"`
+	// ValidateRequests menolak request /api/v1 yang tidak sesuai spesifikasi dengan 400
+	// sebelum sampai ke handler.
+	ValidateRequests bool `yaml:"validate_requests" env:"API_VALIDATE_REQUESTS" usage:"reject requests that do not match the OpenAPI document"`
+	// MaxJSONBody membatasi body JSON yang dibaca untuk validasi; body yang lebih besar ditolak dengan 413.
+	MaxJSONBody int `yaml:"max_json_body" env:"API_MAX_JSON_BODY" usage:"maximum JSON request body in bytes when validate_requests is on"`
+}
+
+// Default mengembalikan konfigurasi bawaan; field wajib (database.url, auth.jwt_secret)
+// tetap kosong.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            ":8080",
+			ReadTimeout:     60 * time.Second,
+			WriteTimeout:    120 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:       25,
+			MaxIdleConns:       10,
+			ConnMaxLifetime:    30 * time.Minute,
+			ConnMaxIdleTime:    5 * time.Minute,
+			StatementTimeout:   30 * time.Second,
+			SlowQueryThreshold: 500 * time.Millisecond,
+		},
+		Auth: AuthConfig{
+			TokenTTL:        24 * time.Hour,
+			PortalTokenTTL:  12 * time.Hour,
+			TOTPIssuer:      "Dashboard Pekerjaan",
+			MFAChallengeTTL: 5 * time.Minute,
+		},
+		Password: PasswordConfig{
+			MinLength:        10,
+			MinClasses:       3,
+			LockoutThreshold: 5,
+			LockoutDuration:  15 * time.Minute,
+			ResetTokenTTL:    time.Hour,
+		},
+		Storage: StorageConfig{
+			Backend:         "local",
+			UploadDir:       "uploads",
+			PortalUploadDir: "portal_uploads",
+		},
+		SMTP: SMTPConfig{
+			Port: 25,
+			From: "Dashboard Pekerjaan <no-reply@localhost>",
+		},
+		CORS: CORSConfig{MaxAge: time.Hour},
+		RateLimit: RateLimitConfig{
+			Store:            "memory",
+			LoginIPBurst:     20,
+			LoginIPRefill:    30 * time.Second,
+			LoginEmailBurst:  5,
+			LoginEmailRefill: time.Minute,
+		},
+		API:          APIConfig{MaxJSONBody: 1 << 20},
+		Notification: NotificationConfig{DigestHour: 7},
+		Log:          LogConfig{Level: "info"},
+		Timezone:     "Asia/Jakarta",
+	}
+}
+
+// ValidationError berisi semua masalah konfigurasi sekaligus, agar bisa diperbaiki dalam sekali jalan.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate memeriksa seluruh konfigurasi dan mengembalikan *ValidationError jika ada masalah.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port == "" {
+		add("server.port (SERVER_PORT) is required")
+	} else if _, _, err := net.SplitHostPort(c.Server.Port); err != nil {
+		add("server.port (SERVER_PORT) must be host:port or :port, got %q", c.Server.Port)
+	}
+	if c.Server.BaseURL != "" {
+		if err := checkHTTPURL(c.Server.BaseURL); err != nil {
+			add("server.base_url (APP_BASE_URL) %v", err)
+		}
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"auth.token_ttl", c.Auth.TokenTTL},
+		{"auth.portal_token_ttl", c.Auth.PortalTokenTTL},
+		{"auth.mfa_challenge_ttl", c.Auth.MFAChallengeTTL},
+		{"password.lockout_duration", c.Password.LockoutDuration},
+		{"password.reset_token_ttl", c.Password.ResetTokenTTL},
+		{"rate_limit.login_ip_refill", c.RateLimit.LoginIPRefill},
+		{"rate_limit.login_email_refill", c.RateLimit.LoginEmailRefill},
+	} {
+		if d.value <= 0 {
+			add("%s must be a positive duration, got %s", d.name, d.value)
+		}
+	}
+
+	if c.Database.URL == "" {
+		add("database.url (DATABASE_URL) is required")
+	} else if strings.Contains(c.Database.URL, "://") {
+		// Selain URL, lib/pq juga menerima DSN "host=... user=...", yang tidak diperiksa di sini
+		if u, err := url.Parse(c.Database.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			add("database.url (DATABASE_URL) must be a postgres:// URL or a key=value DSN")
+		}
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		add("database.max_open_conns and database.max_idle_conns must not be negative")
+	} else if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		add("database.conn_max_lifetime and database.conn_max_idle_time must not be negative")
+	}
+	if c.Database.StatementTimeout < 0 || c.Database.SlowQueryThreshold < 0 {
+		add("database.statement_timeout and database.slow_query_threshold must not be negative")
+	}
+
+	if c.Auth.JWTSecret == "" {
+		add("auth.jwt_secret (JWT_SECRET_KEY) is required")
+	}
+	if strings.TrimSpace(c.Auth.TOTPIssuer) == "" || strings.Contains(c.Auth.TOTPIssuer, ":") {
+		add("auth.totp_issuer (TOTP_ISSUER) is required and must not contain ':'")
+	}
+
+	// Di atas 72 byte bcrypt mengabaikan sisanya, di bawah 8 terlalu mudah ditebak
+	if c.Password.MinLength < 8 || c.Password.MinLength > 72 {
+		add("password.min_length (PASSWORD_MIN_LENGTH) must be between 8 and 72, got %d", c.Password.MinLength)
+	}
+	if c.Password.MinClasses < 0 || c.Password.MinClasses > 4 {
+		add("password.min_classes (PASSWORD_MIN_CLASSES) must be between 0 and 4, got %d", c.Password.MinClasses)
+	}
+	if c.Password.BreachedListFile != "" {
+		if _, err := os.Stat(c.Password.BreachedListFile); err != nil {
+			add("password.breached_list_file (PASSWORD_BREACHED_LIST_FILE): %v", err)
+		}
+	}
+	if c.Password.LockoutThreshold < 0 {
+		add("password.lockout_threshold (LOGIN_LOCKOUT_THRESHOLD) must not be negative")
+	}
+
+	if c.Storage.Backend != "local" {
+		add("storage.backend (STORAGE_BACKEND) %q is not supported, use local", c.Storage.Backend)
+	}
+	if c.Storage.UploadDir == "" {
+		add("storage.upload_dir (UPLOAD_DIR) is required")
+	}
+	if c.Storage.PortalUploadDir == "" {
+		add("storage.portal_upload_dir (PORTAL_UPLOAD_DIR) is required")
+	} else if c.Storage.UploadDir != "" && isWithinDir(c.Storage.UploadDir, c.Storage.PortalUploadDir) {
+		add("storage.portal_upload_dir (PORTAL_UPLOAD_DIR) must not be inside storage.upload_dir, which is served publicly")
 	}
 
-	cfg := &Config{
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		ServerPort:  os.Getenv("SERVER_PORT"),
-		JWTSecretKey: os.Getenv("JWT_SECRET_KEY"), // <-- Muat ini
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			add("cors.allowed_origins: %q must be * or scheme://host[:port]", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		add("cors.max_age must not be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				add("server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+		add("rate_limit.store (RATE_LIMIT_STORE) must be memory or postgres, got %q", c.RateLimit.Store)
+	}
+	if c.RateLimit.LoginIPBurst < 1 || c.RateLimit.LoginEmailBurst < 1 {
+		add("rate_limit.login_ip_burst and rate_limit.login_email_burst must be at least 1")
 	}
 
-	// Basic validation
-	if cfg.DatabaseURL == "" {
-		log.Fatal("DATABASE_URL environment variable is not set.")
+	if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+		add("smtp.port (SMTP_PORT) must be between 1 and 65535, got %d", c.SMTP.Port)
 	}
-	if cfg.ServerPort == "" {
-		log.Fatal("SERVER_PORT environment variable is not set.")
+	if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+		add("smtp.from (SMTP_FROM) is not a valid address: %v", err)
 	}
-    if cfg.JWTSecretKey == "" { // <-- Validasi ini juga
-        log.Fatal("JWT_SECRET_KEY environment variable is not set.")
-    }
 
-	return cfg
+	if c.API.ValidateRequests && c.API.MaxJSONBody <= 0 {
+		add("api.max_json_body (API_MAX_JSON_BODY) must be positive when api.validate_requests is on")
+	}
+	if c.Notification.DigestHour < 0 || c.Notification.DigestHour > 23 {
+		add("notification.digest_hour (NOTIFICATION_DIGEST_HOUR) must be between 0 and 23, got %d", c.Notification.DigestHour)
+	}
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		add("log.level (LOG_LEVEL): %v", err)
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil || c.Timezone == "" {
+		add("timezone (TIMEZONE) %q is not a valid IANA time zone", c.Timezone)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Location mengembalikan zona waktu kantor. Hanya valid setelah Validate berhasil.
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func checkHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http(s) URL, got %q", raw)
+	}
+	return nil
+}
+
+// isWithinDir melaporkan apakah path sama dengan dir atau berada di dalamnya.
+func isWithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile dibaca jika ada, kecuali -config atau CONFIG_FILE menunjuk file lain.
+const DefaultFile = "config.yaml"
+
+// RedactedValue menggantikan nilai secret di Redacted.
+const RedactedValue = "[REDACTED]"
+
+// Load memuat konfigurasi dengan urutan prioritas naik: Default, file YAML, variabel
+// lingkungan (<ENV> atau isi file <ENV>_FILE), lalu flag di args. Flag didaftarkan ke fset
+// sebagai "-config" dan "-<section>.<key>", sehingga pemanggil bisa menambah flag sendiri.
+// Semua masalah, termasuk hasil Validate, dikembalikan sekaligus.
+func Load(fset *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	fields := fieldsOf(cfg)
+
+	configFile := fset.String("config", "", "path of the YAML config file (default $CONFIG_FILE or "+DefaultFile+")")
+	// Flag dicatat dulu dan diterapkan paling akhir, setelah file dan env
+	type flagValue struct {
+		field *field
+		value string
+	}
+	var flagValues []flagValue
+	for i := range fields {
+		f := &fields[i]
+		usage := f.usage
+		if f.env != "" {
+			usage += " ($" + f.env + ")"
+		}
+		set := func(value string) error {
+			flagValues = append(flagValues, flagValue{f, value})
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			fset.BoolFunc(f.path, usage, set)
+		} else {
+			fset.Func(f.path, usage, set)
+		}
+	}
+	if err := fset.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Untuk pengembangan lokal; variabel yang sudah ada tidak ditimpa .env
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Gagal membaca .env", "error", err)
+	}
+
+	var problems []string
+	path, explicit := *configFile, true
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		path, explicit = DefaultFile, false
+	}
+	if err := loadFile(cfg, path); err != nil {
+		if explicit || !errors.Is(err, fs.ErrNotExist) {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for i := range fields {
+		if err := fields[i].loadEnv(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	for _, fv := range flagValues {
+		if err := fv.field.set(fv.value); err != nil {
+			problems = append(problems, fmt.Sprintf("flag -%s: %v", fv.field.path, err))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			problems = append(problems, verr.Problems...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadFile menimpa cfg dengan isi file YAML. Key yang tidak dikenal ditolak, agar salah
+// ketik tidak diam-diam memakai nilai default.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	// File kosong (atau hanya komentar) membuat Decode mengembalikan io.EOF
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Redacted mengembalikan salinan cfg dengan secret disamarkan: password di database.url
+// dan seluruh nilai field secret lainnya.
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	for _, f := range fieldsOf(&out) {
+		if f.secret == "" || f.value.String() == "" {
+			continue
+		}
+		if f.secret == "password" {
+			f.value.SetString(redactPassword(f.value.String()))
+		} else {
+			f.value.SetString(RedactedValue)
+		}
+	}
+	return &out
+}
+
+// WriteYAML menulis cfg sebagai YAML, dalam format yang bisa dibaca kembali oleh Load.
+func (c *Config) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// redactPassword menyamarkan password pada URL postgres:// maupun DSN "password=...".
+func redactPassword(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			return u.Redacted() // Password menjadi "xxxxx"
+		}
+		return RedactedValue
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+RedactedValue)
+}
+
+// field adalah satu nilai konfigurasi beserta tag-nya, dipakai untuk env, flag dan redaksi.
+type field struct {
+	path   string // Key YAML bertitik, juga nama flag, mis. "server.port"
+	env    string
+	secret string // "", "true" atau "password" (hanya password di URL yang disamarkan)
+	usage  string
+	value  reflect.Value
+}
+
+// fieldsOf mendaftar semua field daun cfg sesuai urutan deklarasi.
+func fieldsOf(cfg *Config) []field {
+	var fields []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Struct {
+				walk(fv, name)
+				continue
+			}
+			fields = append(fields, field{
+				path:   name,
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret"),
+				usage:  sf.Tag.Get("usage"),
+				value:  fv,
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return fields
+}
+
+// loadEnv menerapkan variabel lingkungan field. <ENV>_FILE (mis. Docker/Kubernetes secret)
+// dipakai jika <ENV> kosong.
+func (f *field) loadEnv() error {
+	if f.env == "" {
+		return nil
+	}
+	if value := os.Getenv(f.env); value != "" {
+		if err := f.set(value); err != nil {
+			return fmt.Errorf("%s: %v", f.env, err)
+		}
+		return nil
+	}
+	if path := os.Getenv(f.env + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %v", f.env, err)
+		}
+		if err := f.set(strings.TrimRight(string(data), "\r\n")); err != nil {
+			return fmt.Errorf("%s_FILE: %v", f.env, err)
+		}
+	}
+	return nil
+}
+
+// set mengisi field dari teks env atau flag. Daftar dipisah koma.
+func (f *field) set(value string) error {
+	v := f.value
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s or 2h, got %q", value)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", value)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
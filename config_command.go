@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/config"
+)
+
+// runConfigCommand menjalankan "config <subcommand>" dan mengembalikan exit code.
+//
+//	config print [--redacted] [-config file] [-<section>.<key> value ...]
+//
+// print menulis konfigurasi efektif (default, file, env dan flag) sebagai YAML, sehingga
+// hasilnya bisa langsung dipakai sebagai config.yaml.
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: config print [--redacted] [flags]")
+		return 2
+	}
+
+	fset := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := fset.Bool("redacted", false, "mask secrets such as the JWT secret and passwords")
+	cfg, err := config.Load(fset, args[1:])
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *redacted {
+		cfg = cfg.Redacted()
+	}
+	if err := cfg.WriteYAML(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -1,5 +1,20 @@
 -- init.sql
 -- Hapus tabel lama jika ada dan ingin rebuild
+DROP TABLE IF EXISTS monthly_tax_report_imports CASCADE;
+DROP TABLE IF EXISTS staff_recovery_codes CASCADE;
+DROP TABLE IF EXISTS staff_totp CASCADE;
+DROP TABLE IF EXISTS password_reset_tokens CASCADE;
+DROP TABLE IF EXISTS rate_limit_buckets CASCADE;
+DROP TABLE IF EXISTS document_requests CASCADE;
+DROP TABLE IF EXISTS client_users CASCADE;
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP TABLE IF EXISTS outbox_handled CASCADE;
+DROP TABLE IF EXISTS outbox_events CASCADE;
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhook_subscriptions CASCADE;
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS notification_log CASCADE;
+DROP TABLE IF EXISTS notification_preferences CASCADE;
 DROP TABLE IF EXISTS monthly_tax_reports CASCADE;
 DROP TABLE IF EXISTS monthly_jobs CASCADE;
 DROP TABLE IF EXISTS annual_tax_reports CASCADE;
@@ -9,6 +24,7 @@ DROP TABLE IF EXISTS sp2dk_jobs CASCADE;
 DROP TABLE IF EXISTS pemeriksaan_jobs CASCADE;
 DROP TABLE IF EXISTS invoices CASCADE; -- Tambahkan ini
 DROP TABLE IF EXISTS invoice_line_items CASCADE; -- Tambahkan ini
+DROP TABLE IF EXISTS documents CASCADE;
 DROP TABLE IF EXISTS clients CASCADE;
 DROP TABLE IF EXISTS staffs CASCADE;
 
@@ -16,7 +32,8 @@ DROP TABLE IF EXISTS staffs CASCADE;
 CREATE TABLE IF NOT EXISTS clients (
     client_id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     client_name                 VARCHAR(255) NOT NULL,
-    npwp_client                 VARCHAR(20) UNIQUE NOT NULL,
+    npwp_client                 VARCHAR(20) UNIQUE NOT NULL, -- Bentuk tampilan
+    npwp_canonical              VARCHAR(16) UNIQUE NOT NULL, -- 16 digit; NPWP 15 digit diberi awalan 0
     address_client              TEXT,
     membership_status           VARCHAR(50),
     phone_client                VARCHAR(20),
@@ -48,6 +65,10 @@ CREATE TABLE IF NOT EXISTS staffs (
     email           VARCHAR(255) UNIQUE NOT NULL,
     password_hashed VARCHAR(255) NOT NULL,
     role            VARCHAR(50) NOT NULL,
+    must_change_password  BOOLEAN NOT NULL DEFAULT FALSE, -- Password sementara dari admin, wajib diganti saat login
+    password_changed_at   TIMESTAMP WITH TIME ZONE,
+    failed_login_attempts INT NOT NULL DEFAULT 0, -- Direset setelah login berhasil atau akun terkunci
+    locked_until          TIMESTAMP WITH TIME ZONE, -- Login ditolak sampai waktu ini
     created_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -87,6 +108,10 @@ CREATE TABLE IF NOT EXISTS monthly_tax_reports (
     payment_amount      NUMERIC(18, 2),
     report_status       VARCHAR(50) DEFAULT 'Pending',
     report_date         DATE,
+    billing_code_expires_at TIMESTAMP WITH TIME ZONE,
+    billing_amount      NUMERIC(18, 2),
+    ntpn                VARCHAR(16),
+    payment_channel     VARCHAR(100),
     created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT fk_monthly_job FOREIGN KEY (job_id) REFERENCES monthly_jobs (job_id) ON DELETE CASCADE,
@@ -117,6 +142,10 @@ CREATE TABLE IF NOT EXISTS annual_tax_reports (
     payment_amount  NUMERIC(18, 2),
     report_date     DATE,
     report_status   VARCHAR(50) DEFAULT 'Pending',
+    billing_code_expires_at TIMESTAMP WITH TIME ZONE,
+    billing_amount  NUMERIC(18, 2),
+    ntpn            VARCHAR(16),
+    payment_channel VARCHAR(100),
     created_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
 
@@ -193,5 +222,284 @@ CREATE TABLE IF NOT EXISTS pemeriksaan_jobs (
     CONSTRAINT fk_pemeriksaan_assigned_pic_staff FOREIGN KEY (assigned_pic_staff_sigma_id) REFERENCES staffs (staff_id) ON DELETE SET NULL
 );
 
--- Tabel invoices dan invoice_line_items (Tidak ada perubahan)
--- ... (kode Anda untuk tabel ini) ...
\ No newline at end of file
+-- Tabel invoices dan invoice_line_items
+CREATE TABLE IF NOT EXISTS invoices (
+    invoice_id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_number      VARCHAR(50) UNIQUE NOT NULL,
+    client_id           UUID NOT NULL,
+    assigned_staff_id   UUID,
+    invoice_date        DATE NOT NULL,
+    due_date            DATE NOT NULL,
+    total_amount        NUMERIC(18, 2) DEFAULT 0,
+    status              VARCHAR(50) DEFAULT 'Pending',
+    notes               TEXT,
+    paid_at             TIMESTAMP WITH TIME ZONE, -- Diisi otomatis saat status menjadi 'Paid'
+    source_event_id     UUID UNIQUE, -- Event JobCompleted asal invoice otomatis, mencegah invoice ganda saat event diulang
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_invoice_client FOREIGN KEY (client_id) REFERENCES clients (client_id) ON DELETE CASCADE,
+    CONSTRAINT fk_invoice_assigned_staff FOREIGN KEY (assigned_staff_id) REFERENCES staffs (staff_id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoice_line_items (
+    line_item_id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id          UUID NOT NULL,
+    description         TEXT NOT NULL,
+    quantity            NUMERIC(18, 2) NOT NULL,
+    unit_price          NUMERIC(18, 2) NOT NULL,
+    amount              NUMERIC(18, 2) NOT NULL,
+    related_job_type    VARCHAR(50),
+    related_job_id      UUID,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_line_item_invoice FOREIGN KEY (invoice_id) REFERENCES invoices (invoice_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_invoice_date ON invoices (invoice_date);
+CREATE INDEX IF NOT EXISTS idx_invoices_paid_at ON invoices (paid_at) WHERE paid_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_invoice_line_items_related_job ON invoice_line_items (related_job_type, related_job_id);
+
+-- Tabel documents: file upload (bukti kerja, BPE, SKP, dll.) beserta teks hasil ekstraksi
+CREATE TABLE IF NOT EXISTS documents (
+    document_id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id               UUID NOT NULL,
+    job_type                VARCHAR(50) NOT NULL,
+    job_id                  UUID NOT NULL,
+    file_name               VARCHAR(255) NOT NULL,
+    file_url                TEXT NOT NULL,
+    uploaded_by_staff_id    UUID,
+    extraction_status       VARCHAR(50) DEFAULT 'Pending',
+    extraction_error        TEXT,
+    content_text            TEXT,
+    content_tsv             TSVECTOR GENERATED ALWAYS AS (
+                                to_tsvector('simple', COALESCE(file_name, '') || ' ' || COALESCE(content_text, ''))
+                            ) STORED,
+    extracted_at            TIMESTAMP WITH TIME ZONE,
+    created_at              TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_document_client FOREIGN KEY (client_id) REFERENCES clients (client_id) ON DELETE CASCADE,
+    CONSTRAINT fk_document_uploader FOREIGN KEY (uploaded_by_staff_id) REFERENCES staffs (staff_id) ON DELETE SET NULL,
+    CONSTRAINT unique_document_file_url UNIQUE (file_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_content_tsv ON documents USING GIN (content_tsv);
+CREATE INDEX IF NOT EXISTS idx_documents_client_job_type ON documents (client_id, job_type);
+CREATE INDEX IF NOT EXISTS idx_documents_pending ON documents (created_at) WHERE extraction_status = 'Pending';
+
+-- Tabel notification_preferences: jenis email yang ingin diterima tiap staf (tanpa baris = semua aktif)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    staff_id            UUID PRIMARY KEY,
+    job_assigned        BOOLEAN NOT NULL DEFAULT TRUE,
+    invoice_overdue     BOOLEAN NOT NULL DEFAULT TRUE,
+    report_due          BOOLEAN NOT NULL DEFAULT TRUE,
+    daily_digest        BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_notification_preferences_staff FOREIGN KEY (staff_id) REFERENCES staffs (staff_id) ON DELETE CASCADE
+);
+
+-- Tabel notification_log: email terjadwal yang sudah terkirim, agar tidak terkirim dua kali
+CREATE TABLE IF NOT EXISTS notification_log (
+    notification_key    VARCHAR(255) PRIMARY KEY, -- mis. report_due:<job_id>:<tanggal jatuh tempo>
+    staff_id            UUID,
+    event               VARCHAR(50) NOT NULL,
+    sent_at             TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_notification_log_staff FOREIGN KEY (staff_id) REFERENCES staffs (staff_id) ON DELETE CASCADE
+);
+
+-- Tabel notifications: kotak masuk in-app (ikon lonceng) per staf
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id     UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    staff_id            UUID NOT NULL,
+    event               VARCHAR(50) NOT NULL,
+    title               VARCHAR(255) NOT NULL,
+    body                TEXT NOT NULL DEFAULT '',
+    job_type            VARCHAR(50),
+    job_id              UUID,
+    invoice_id          UUID,
+    read_at             TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_notification_staff FOREIGN KEY (staff_id) REFERENCES staffs (staff_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_staff_created ON notifications (staff_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (staff_id) WHERE read_at IS NULL;
+
+-- Tabel webhook_subscriptions: endpoint eksternal (chat, akuntansi) yang menerima event dashboard
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id     UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url                 TEXT NOT NULL,
+    secret              VARCHAR(255) NOT NULL, -- Kunci HMAC-SHA256 untuk header X-Webhook-Signature
+    event_types         TEXT[] NOT NULL,       -- mis. {job.status_changed, invoice.paid}
+    description         TEXT,
+    is_active           BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabel webhook_deliveries: log pengiriman webhook beserta jadwal retry-nya
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id     UUID NOT NULL,
+    event_id            UUID NOT NULL,         -- Sama untuk semua subscription dan redelivery dari satu event
+    event_type          VARCHAR(50) NOT NULL,
+    payload             JSONB NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'Pending', -- Pending, Succeeded, Failed
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at     TIMESTAMP WITH TIME ZONE,
+    last_attempt_at     TIMESTAMP WITH TIME ZONE,
+    response_status     INTEGER,
+    response_body       TEXT,
+    last_error          TEXT,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_webhook_delivery_subscription FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (subscription_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'Pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, created_at DESC);
+
+-- Tabel outbox_events: domain event yang ditulis dalam transaksi yang sama dengan perubahan datanya,
+-- lalu diteruskan dispatcher ke subscriber di dalam aplikasi (at-least-once)
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id            UUID PRIMARY KEY,
+    sequence            BIGSERIAL NOT NULL, -- Urutan penulisan, dipakai untuk urutan pengiriman
+    event_type          VARCHAR(50) NOT NULL,
+    aggregate_type      VARCHAR(50) NOT NULL,
+    aggregate_id        UUID NOT NULL,
+    actor_staff_id      UUID,
+    payload             JSONB NOT NULL,
+    occurred_at         TIMESTAMP WITH TIME ZONE NOT NULL,
+    dispatched_at       TIMESTAMP WITH TIME ZONE, -- Diisi setelah semua subscriber berhasil
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error          TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (next_attempt_at) WHERE dispatched_at IS NULL;
+
+-- Tabel outbox_handled: subscriber yang sudah berhasil memproses sebuah event,
+-- agar saat event diulang hanya subscriber yang gagal yang dipanggil lagi
+CREATE TABLE IF NOT EXISTS outbox_handled (
+    event_id            UUID NOT NULL,
+    subscriber          VARCHAR(50) NOT NULL,
+    handled_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, subscriber),
+    CONSTRAINT fk_outbox_handled_event FOREIGN KEY (event_id) REFERENCES outbox_events (event_id) ON DELETE CASCADE
+);
+
+-- Tabel audit_log: jejak perubahan pekerjaan dan invoice, diisi subscriber audit dari outbox
+CREATE TABLE IF NOT EXISTS audit_log (
+    event_id            UUID PRIMARY KEY,
+    event_type          VARCHAR(50) NOT NULL,
+    aggregate_type      VARCHAR(50) NOT NULL,
+    aggregate_id        UUID NOT NULL,
+    actor_staff_id      UUID,
+    payload             JSONB NOT NULL,
+    occurred_at         TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_at         TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_audit_log_actor FOREIGN KEY (actor_staff_id) REFERENCES staffs (staff_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_aggregate ON audit_log (aggregate_type, aggregate_id, occurred_at DESC);
+
+-- Tabel client_users: login portal klien (read-only), terpisah dari staffs
+CREATE TABLE IF NOT EXISTS client_users (
+    client_user_id      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id           UUID NOT NULL,
+    nama                VARCHAR(255) NOT NULL,
+    email               VARCHAR(255) UNIQUE NOT NULL, -- Disimpan huruf kecil
+    password_hashed     VARCHAR(255) NOT NULL,
+    is_active           BOOLEAN NOT NULL DEFAULT TRUE,
+    last_login_at       TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_client_user_client FOREIGN KEY (client_id) REFERENCES clients (client_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_users_client ON client_users (client_id);
+
+-- Tabel document_requests: daftar dokumen yang harus dikirim klien untuk sebuah pekerjaan
+-- (mis. rekap penjualan, data gaji), diupload klien lewat portal
+CREATE TABLE IF NOT EXISTS document_requests (
+    request_id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_type                    VARCHAR(50) NOT NULL,
+    job_id                      UUID NOT NULL,
+    client_id                   UUID NOT NULL,
+    title                       VARCHAR(255) NOT NULL,
+    description                 TEXT,
+    due_date                    DATE NOT NULL,
+    status                      VARCHAR(50) NOT NULL DEFAULT 'Menunggu', -- Menunggu, Diupload, Diterima, Ditolak
+    document_id                 UUID,
+    uploaded_by_client_user_id  UUID,
+    uploaded_at                 TIMESTAMP WITH TIME ZONE,
+    review_note                 TEXT,
+    reviewed_by_staff_id        UUID,
+    reviewed_at                 TIMESTAMP WITH TIME ZONE,
+    created_by_staff_id         UUID,
+    created_at                  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at                  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_document_request_client FOREIGN KEY (client_id) REFERENCES clients (client_id) ON DELETE CASCADE,
+    CONSTRAINT fk_document_request_document FOREIGN KEY (document_id) REFERENCES documents (document_id) ON DELETE SET NULL,
+    CONSTRAINT fk_document_request_client_user FOREIGN KEY (uploaded_by_client_user_id) REFERENCES client_users (client_user_id) ON DELETE SET NULL,
+    CONSTRAINT fk_document_request_reviewer FOREIGN KEY (reviewed_by_staff_id) REFERENCES staffs (staff_id) ON DELETE SET NULL,
+    CONSTRAINT fk_document_request_creator FOREIGN KEY (created_by_staff_id) REFERENCES staffs (staff_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_requests_job ON document_requests (job_type, job_id);
+CREATE INDEX IF NOT EXISTS idx_document_requests_client_open ON document_requests (client_id, due_date) WHERE status IN ('Menunggu', 'Ditolak');
+
+-- Tabel rate_limit_buckets: token bucket rate limiter bersama untuk beberapa instance API
+-- (RATE_LIMIT_STORE=postgres). Bucket boleh dihapus setelah idle_until karena sudah penuh kembali.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key  VARCHAR(255) PRIMARY KEY,
+    tokens      DOUBLE PRECISION NOT NULL,
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    idle_until  TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_idle ON rate_limit_buckets (idle_until);
+
+-- Tabel password_reset_tokens: token lupa password yang dikirim lewat email. Yang disimpan hanya
+-- hash SHA-256 token; token hanya bisa dipakai sekali dan sebelum expires_at.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash  CHAR(64) PRIMARY KEY,
+    staff_id    UUID NOT NULL REFERENCES staffs (staff_id) ON DELETE CASCADE,
+    expires_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at     TIMESTAMP WITH TIME ZONE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_staff ON password_reset_tokens (staff_id);
+
+-- Tabel staff_totp: secret TOTP (RFC 6238) untuk login dua langkah. enabled_at NULL berarti
+-- enrollment belum dikonfirmasi dengan kode pertama. last_counter mencegah kode dipakai ulang.
+CREATE TABLE IF NOT EXISTS staff_totp (
+    staff_id      UUID PRIMARY KEY REFERENCES staffs (staff_id) ON DELETE CASCADE,
+    secret        VARCHAR(64) NOT NULL, -- Base32
+    last_counter  BIGINT NOT NULL DEFAULT 0,
+    enabled_at    TIMESTAMP WITH TIME ZONE,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabel staff_recovery_codes: kode cadangan sekali pakai jika perangkat authenticator hilang.
+-- Yang disimpan hanya hash SHA-256 kode.
+CREATE TABLE IF NOT EXISTS staff_recovery_codes (
+    staff_id    UUID NOT NULL REFERENCES staffs (staff_id) ON DELETE CASCADE,
+    code_hash   CHAR(64) NOT NULL,
+    used_at     TIMESTAMP WITH TIME ZONE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (staff_id, code_hash)
+);
+
+-- Tabel monthly_tax_report_imports: total per file ekspor e-Bupot/e-Faktur yang diimpor ke laporan
+-- pajak bulanan. billing_amount laporan adalah jumlah semua file, sehingga faktur keluaran dan
+-- masukan boleh diimpor terpisah. Mengimpor ulang file dengan nama yang sama menggantikan totalnya.
+CREATE TABLE IF NOT EXISTS monthly_tax_report_imports (
+    report_id    UUID NOT NULL REFERENCES monthly_tax_reports (report_id) ON DELETE CASCADE,
+    file_name    VARCHAR(255) NOT NULL,
+    line_count   INT NOT NULL,
+    tax_base     NUMERIC(18, 2) NOT NULL,
+    tax_amount   NUMERIC(18, 2) NOT NULL, -- PPN masukan yang dapat dikreditkan bernilai negatif
+    imported_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (report_id, file_name)
+);
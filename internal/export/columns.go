@@ -0,0 +1,286 @@
+package export
+
+import (
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// ClientColumns adalah kolom ekspor daftar klien. Password Coretax sengaja tidak diekspor.
+var ClientColumns = []Column[models.Client]{
+	{"client_id", "ID Klien", "Client ID", func(c models.Client) interface{} { return c.ClientID }},
+	{"client_name", "Nama Klien", "Client Name", func(c models.Client) interface{} { return c.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(c models.Client) interface{} { return c.NpwpClient }},
+	{"address_client", "Alamat", "Address", func(c models.Client) interface{} { return c.AddressClient }},
+	{"membership_status", "Status Keanggotaan", "Membership Status", func(c models.Client) interface{} { return c.MembershipStatus }},
+	{"phone_client", "Telepon", "Phone", func(c models.Client) interface{} { return c.PhoneClient }},
+	{"email_client", "Email", "Email", func(c models.Client) interface{} { return c.EmailClient }},
+	{"pic_client", "PIC Klien", "Client PIC", func(c models.Client) interface{} { return c.PicClient }},
+	{"djp_online_username", "Username DJP Online", "DJP Online Username", func(c models.Client) interface{} { return c.DjpOnlineUsername }},
+	{"coretax_username", "Username Coretax", "Coretax Username", func(c models.Client) interface{} { return c.CoretaxUsername }},
+	{"pic_staff_sigma_name", "PIC Staf", "Staff PIC", func(c models.Client) interface{} { return c.PicStaffSigmaName }},
+	{"client_category", "Kategori Klien", "Client Category", func(c models.Client) interface{} { return c.ClientCategory }},
+	{"pph_final_umkm", "PPh Final UMKM", "Final Income Tax (MSME)", func(c models.Client) interface{} { return c.PphFinalUmkm }},
+	{"pph_25", "PPh 25", "Income Tax Art. 25", func(c models.Client) interface{} { return c.Pph25 }},
+	{"pph_21", "PPh 21", "Income Tax Art. 21", func(c models.Client) interface{} { return c.Pph21 }},
+	{"pph_unifikasi", "PPh Unifikasi", "Unified Withholding Tax", func(c models.Client) interface{} { return c.PphUnifikasi }},
+	{"ppn", "PPN", "VAT", func(c models.Client) interface{} { return c.Ppn }},
+	{"spt_tahunan", "SPT Tahunan", "Annual Tax Return", func(c models.Client) interface{} { return c.SptTahunan }},
+	{"pelaporan_deviden", "Pelaporan Dividen", "Dividend Reporting", func(c models.Client) interface{} { return c.PelaporanDeviden }},
+	{"laporan_keuangan", "Laporan Keuangan", "Financial Statements", func(c models.Client) interface{} { return c.LaporanKeuangan }},
+	{"investasi_deviden", "Investasi Dividen", "Dividend Investment", func(c models.Client) interface{} { return c.InvestasiDeviden }},
+	{"created_at", "Dibuat", "Created At", func(c models.Client) interface{} { return c.CreatedAt }},
+	{"updated_at", "Diperbarui", "Updated At", func(c models.Client) interface{} { return c.UpdatedAt }},
+}
+
+// MonthlyJobRow adalah satu baris ekspor pekerjaan bulanan: satu per laporan pajak.
+// Report nil jika pekerjaan belum punya laporan.
+type MonthlyJobRow struct {
+	Job    *models.MonthlyJob
+	Report *models.MonthlyTaxReport
+}
+
+// MonthlyJobRows memecah satu pekerjaan bulanan menjadi satu baris per laporan pajak.
+func MonthlyJobRows(job *models.MonthlyJob) []MonthlyJobRow {
+	if len(job.TaxReports) == 0 {
+		return []MonthlyJobRow{{Job: job}}
+	}
+	rows := make([]MonthlyJobRow, len(job.TaxReports))
+	for i := range job.TaxReports {
+		rows[i] = MonthlyJobRow{Job: job, Report: &job.TaxReports[i]}
+	}
+	return rows
+}
+
+// MonthlyJobColumns adalah kolom ekspor pekerjaan bulanan.
+var MonthlyJobColumns = []Column[MonthlyJobRow]{
+	{"job_id", "ID Pekerjaan", "Job ID", func(r MonthlyJobRow) interface{} { return r.Job.JobID }},
+	{"client_name", "Nama Klien", "Client Name", func(r MonthlyJobRow) interface{} { return r.Job.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(r MonthlyJobRow) interface{} { return r.Job.NpwpClient }},
+	{"job_month", "Masa", "Month", func(r MonthlyJobRow) interface{} { return r.Job.JobMonth }},
+	{"job_year", "Tahun", "Year", func(r MonthlyJobRow) interface{} { return r.Job.JobYear }},
+	{"assigned_pic_staff_sigma_name", "PIC Staf", "Staff PIC", func(r MonthlyJobRow) interface{} { return r.Job.AssignedPicStaffSigmaName }},
+	{"overall_status", "Status Pekerjaan", "Job Status", func(r MonthlyJobRow) interface{} { return r.Job.OverallStatus }},
+	{"tax_type", "Jenis Pajak", "Tax Type", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.TaxType })
+	}},
+	{"billing_code", "Kode Billing", "Billing Code", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.BillingCode })
+	}},
+	{"billing_amount", "Jumlah Billing", "Billing Amount", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.BillingAmount })
+	}},
+	{"payment_date", "Tanggal Bayar", "Payment Date", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.PaymentDate })
+	}},
+	{"payment_amount", "Jumlah Bayar", "Payment Amount", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.PaymentAmount })
+	}},
+	{"ntpn", "NTPN", "NTPN", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.Ntpn })
+	}},
+	{"payment_channel", "Tempat Bayar", "Payment Channel", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.PaymentChannel })
+	}},
+	{"report_status", "Status Laporan", "Report Status", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.ReportStatus })
+	}},
+	{"report_date", "Tanggal Lapor", "Report Date", func(r MonthlyJobRow) interface{} {
+		return monthlyReportField(r, func(t *models.MonthlyTaxReport) interface{} { return t.ReportDate })
+	}},
+	{"updated_at", "Diperbarui", "Updated At", func(r MonthlyJobRow) interface{} { return r.Job.UpdatedAt }},
+}
+
+func monthlyReportField(r MonthlyJobRow, field func(*models.MonthlyTaxReport) interface{}) interface{} {
+	if r.Report == nil {
+		return nil
+	}
+	return field(r.Report)
+}
+
+// Jenis laporan pada ekspor pekerjaan tahunan
+const (
+	AnnualReportKindTax      = "SPT Tahunan"
+	AnnualReportKindDividend = "Dividen"
+)
+
+// AnnualJobRow adalah satu baris ekspor pekerjaan tahunan: satu per laporan SPT atau dividen.
+type AnnualJobRow struct {
+	Job            *models.AnnualJob
+	TaxReport      *models.AnnualTaxReport
+	DividendReport *models.AnnualDividendReport
+}
+
+func (r AnnualJobRow) reportKind() string {
+	switch {
+	case r.TaxReport != nil:
+		return AnnualReportKindTax
+	case r.DividendReport != nil:
+		return AnnualReportKindDividend
+	}
+	return ""
+}
+
+func (r AnnualJobRow) reportStatus() string {
+	switch {
+	case r.TaxReport != nil:
+		return r.TaxReport.ReportStatus
+	case r.DividendReport != nil:
+		return r.DividendReport.ReportStatus
+	}
+	return ""
+}
+
+func (r AnnualJobRow) reportDate() *time.Time {
+	switch {
+	case r.TaxReport != nil:
+		return r.TaxReport.ReportDate
+	case r.DividendReport != nil:
+		return r.DividendReport.ReportDate
+	}
+	return nil
+}
+
+// AnnualJobRows memecah satu pekerjaan tahunan menjadi satu baris per laporan SPT dan per laporan dividen.
+func AnnualJobRows(job *models.AnnualJob) []AnnualJobRow {
+	if len(job.TaxReports) == 0 && len(job.DividendReports) == 0 {
+		return []AnnualJobRow{{Job: job}}
+	}
+	rows := make([]AnnualJobRow, 0, len(job.TaxReports)+len(job.DividendReports))
+	for i := range job.TaxReports {
+		rows = append(rows, AnnualJobRow{Job: job, TaxReport: &job.TaxReports[i]})
+	}
+	for i := range job.DividendReports {
+		rows = append(rows, AnnualJobRow{Job: job, DividendReport: &job.DividendReports[i]})
+	}
+	return rows
+}
+
+// AnnualJobColumns adalah kolom ekspor pekerjaan tahunan.
+var AnnualJobColumns = []Column[AnnualJobRow]{
+	{"job_id", "ID Pekerjaan", "Job ID", func(r AnnualJobRow) interface{} { return r.Job.JobID }},
+	{"client_name", "Nama Klien", "Client Name", func(r AnnualJobRow) interface{} { return r.Job.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(r AnnualJobRow) interface{} { return r.Job.NpwpClient }},
+	{"job_year", "Tahun Pajak", "Tax Year", func(r AnnualJobRow) interface{} { return r.Job.JobYear }},
+	{"assigned_pic_staff_sigma_name", "PIC Staf", "Staff PIC", func(r AnnualJobRow) interface{} { return r.Job.AssignedPicStaffSigmaName }},
+	{"overall_status", "Status Pekerjaan", "Job Status", func(r AnnualJobRow) interface{} { return r.Job.OverallStatus }},
+	{"report_kind", "Jenis Laporan", "Report Kind", func(r AnnualJobRow) interface{} { return r.reportKind() }},
+	{"billing_code", "Kode Billing", "Billing Code", func(r AnnualJobRow) interface{} {
+		return annualTaxField(r, func(t *models.AnnualTaxReport) interface{} { return t.BillingCode })
+	}},
+	{"billing_amount", "Jumlah Billing", "Billing Amount", func(r AnnualJobRow) interface{} {
+		return annualTaxField(r, func(t *models.AnnualTaxReport) interface{} { return t.BillingAmount })
+	}},
+	{"payment_date", "Tanggal Bayar", "Payment Date", func(r AnnualJobRow) interface{} {
+		return annualTaxField(r, func(t *models.AnnualTaxReport) interface{} { return t.PaymentDate })
+	}},
+	{"payment_amount", "Jumlah Bayar", "Payment Amount", func(r AnnualJobRow) interface{} {
+		return annualTaxField(r, func(t *models.AnnualTaxReport) interface{} { return t.PaymentAmount })
+	}},
+	{"ntpn", "NTPN", "NTPN", func(r AnnualJobRow) interface{} {
+		return annualTaxField(r, func(t *models.AnnualTaxReport) interface{} { return t.Ntpn })
+	}},
+	{"is_reported", "Dividen Dilaporkan", "Dividend Reported", func(r AnnualJobRow) interface{} {
+		if r.DividendReport == nil {
+			return nil
+		}
+		return r.DividendReport.IsReported
+	}},
+	{"report_status", "Status Laporan", "Report Status", func(r AnnualJobRow) interface{} { return r.reportStatus() }},
+	{"report_date", "Tanggal Lapor", "Report Date", func(r AnnualJobRow) interface{} { return r.reportDate() }},
+	{"updated_at", "Diperbarui", "Updated At", func(r AnnualJobRow) interface{} { return r.Job.UpdatedAt }},
+}
+
+func annualTaxField(r AnnualJobRow, field func(*models.AnnualTaxReport) interface{}) interface{} {
+	if r.TaxReport == nil {
+		return nil
+	}
+	return field(r.TaxReport)
+}
+
+// Sp2dkJobColumns adalah kolom ekspor pekerjaan SP2DK.
+var Sp2dkJobColumns = []Column[models.Sp2dkJob]{
+	{"job_id", "ID Pekerjaan", "Job ID", func(j models.Sp2dkJob) interface{} { return j.JobID }},
+	{"client_name", "Nama Klien", "Client Name", func(j models.Sp2dkJob) interface{} { return j.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(j models.Sp2dkJob) interface{} { return j.NpwpClient }},
+	{"assigned_pic_staff_sigma_name", "PIC Staf", "Staff PIC", func(j models.Sp2dkJob) interface{} { return j.AssignedPicStaffSigmaName }},
+	{"contract_no", "No. Kontrak", "Contract No.", func(j models.Sp2dkJob) interface{} { return j.ContractNo }},
+	{"contract_date", "Tanggal Kontrak", "Contract Date", func(j models.Sp2dkJob) interface{} { return j.ContractDate }},
+	{"sp2dk_no", "No. SP2DK", "SP2DK No.", func(j models.Sp2dkJob) interface{} { return j.Sp2dkNo }},
+	{"sp2dk_date", "Tanggal SP2DK", "SP2DK Date", func(j models.Sp2dkJob) interface{} { return j.Sp2dkDate }},
+	{"bap2dk_no", "No. BAP2DK", "BAP2DK No.", func(j models.Sp2dkJob) interface{} { return j.Bap2dkNo }},
+	{"bap2dk_date", "Tanggal BAP2DK", "BAP2DK Date", func(j models.Sp2dkJob) interface{} { return j.Bap2dkDate }},
+	{"payment_date", "Tanggal Bayar", "Payment Date", func(j models.Sp2dkJob) interface{} { return j.PaymentDate }},
+	{"report_date", "Tanggal Lapor", "Report Date", func(j models.Sp2dkJob) interface{} { return j.ReportDate }},
+	{"overall_status", "Status Pekerjaan", "Job Status", func(j models.Sp2dkJob) interface{} { return j.OverallStatus }},
+	{"updated_at", "Diperbarui", "Updated At", func(j models.Sp2dkJob) interface{} { return j.UpdatedAt }},
+}
+
+// PemeriksaanJobColumns adalah kolom ekspor pekerjaan pemeriksaan.
+var PemeriksaanJobColumns = []Column[models.PemeriksaanJob]{
+	{"job_id", "ID Pekerjaan", "Job ID", func(j models.PemeriksaanJob) interface{} { return j.JobID }},
+	{"client_name", "Nama Klien", "Client Name", func(j models.PemeriksaanJob) interface{} { return j.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(j models.PemeriksaanJob) interface{} { return j.NpwpClient }},
+	{"assigned_pic_staff_sigma_name", "PIC Staf", "Staff PIC", func(j models.PemeriksaanJob) interface{} { return j.AssignedPicStaffSigmaName }},
+	{"contract_no", "No. Kontrak", "Contract No.", func(j models.PemeriksaanJob) interface{} { return j.ContractNo }},
+	{"contract_date", "Tanggal Kontrak", "Contract Date", func(j models.PemeriksaanJob) interface{} { return j.ContractDate }},
+	{"sp2_no", "No. SP2", "SP2 No.", func(j models.PemeriksaanJob) interface{} { return j.Sp2No }},
+	{"sp2_date", "Tanggal SP2", "SP2 Date", func(j models.PemeriksaanJob) interface{} { return j.Sp2Date }},
+	{"skp_no", "No. SKP", "SKP No.", func(j models.PemeriksaanJob) interface{} { return j.SkpNo }},
+	{"skp_date", "Tanggal SKP", "SKP Date", func(j models.PemeriksaanJob) interface{} { return j.SkpDate }},
+	{"overall_status", "Status Pekerjaan", "Job Status", func(j models.PemeriksaanJob) interface{} { return j.OverallStatus }},
+	{"updated_at", "Diperbarui", "Updated At", func(j models.PemeriksaanJob) interface{} { return j.UpdatedAt }},
+}
+
+// InvoiceRow adalah satu baris ekspor invoice: satu per line item.
+type InvoiceRow struct {
+	Invoice *models.Invoice
+	Item    *models.InvoiceLineItem
+}
+
+// InvoiceRows memecah satu invoice menjadi satu baris per line item.
+func InvoiceRows(invoice *models.Invoice) []InvoiceRow {
+	if len(invoice.LineItems) == 0 {
+		return []InvoiceRow{{Invoice: invoice}}
+	}
+	rows := make([]InvoiceRow, len(invoice.LineItems))
+	for i := range invoice.LineItems {
+		rows[i] = InvoiceRow{Invoice: invoice, Item: &invoice.LineItems[i]}
+	}
+	return rows
+}
+
+// InvoiceColumns adalah kolom ekspor invoice.
+var InvoiceColumns = []Column[InvoiceRow]{
+	{"invoice_number", "No. Invoice", "Invoice No.", func(r InvoiceRow) interface{} { return r.Invoice.InvoiceNumber }},
+	{"client_name", "Nama Klien", "Client Name", func(r InvoiceRow) interface{} { return r.Invoice.ClientName }},
+	{"npwp_client", "NPWP", "Tax ID (NPWP)", func(r InvoiceRow) interface{} { return r.Invoice.NpwpClient }},
+	{"assigned_staff_name", "PIC Staf", "Staff PIC", func(r InvoiceRow) interface{} { return r.Invoice.AssignedStaffName }},
+	{"invoice_date", "Tanggal Invoice", "Invoice Date", func(r InvoiceRow) interface{} { return r.Invoice.InvoiceDate.Time }},
+	{"due_date", "Jatuh Tempo", "Due Date", func(r InvoiceRow) interface{} { return r.Invoice.DueDate.Time }},
+	{"status", "Status", "Status", func(r InvoiceRow) interface{} { return r.Invoice.Status }},
+	{"total_amount", "Total Invoice", "Invoice Total", func(r InvoiceRow) interface{} { return r.Invoice.TotalAmount }},
+	{"description", "Uraian", "Description", func(r InvoiceRow) interface{} {
+		return invoiceItemField(r, func(i *models.InvoiceLineItem) interface{} { return i.Description })
+	}},
+	{"quantity", "Kuantitas", "Quantity", func(r InvoiceRow) interface{} {
+		return invoiceItemField(r, func(i *models.InvoiceLineItem) interface{} { return i.Quantity })
+	}},
+	{"unit_price", "Harga Satuan", "Unit Price", func(r InvoiceRow) interface{} {
+		return invoiceItemField(r, func(i *models.InvoiceLineItem) interface{} { return i.UnitPrice })
+	}},
+	{"amount", "Jumlah", "Amount", func(r InvoiceRow) interface{} {
+		return invoiceItemField(r, func(i *models.InvoiceLineItem) interface{} { return i.Amount })
+	}},
+	{"related_job_type", "Jenis Pekerjaan", "Job Type", func(r InvoiceRow) interface{} {
+		return invoiceItemField(r, func(i *models.InvoiceLineItem) interface{} { return i.RelatedJobType })
+	}},
+	{"notes", "Catatan", "Notes", func(r InvoiceRow) interface{} { return r.Invoice.Notes }},
+}
+
+func invoiceItemField(r InvoiceRow, field func(*models.InvoiceLineItem) interface{}) interface{} {
+	if r.Item == nil {
+		return nil
+	}
+	return field(r.Item)
+}
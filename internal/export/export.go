@@ -0,0 +1,368 @@
+// Package export menulis daftar data (klien, pekerjaan, invoice) sebagai CSV atau XLSX.
+// Kolom didefinisikan sekali per entitas; pemanggil dapat memilih kolom dan bahasa header.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Format adalah format keluaran daftar.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// Bahasa header kolom
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	csvContentType  = "text/csv; charset=utf-8"
+	csvFlushEvery   = 200
+)
+
+var (
+	ErrUnknownFormat = errors.New("format tidak dikenal, gunakan json, csv atau xlsx")
+	ErrUnknownColumn = errors.New("kolom tidak dikenal")
+	ErrUnknownLang   = errors.New("bahasa header tidak dikenal, gunakan id atau en")
+)
+
+// Negotiate menentukan format dari query param ?format=, lalu dari header Accept.
+// Tanpa keduanya hasilnya FormatJSON.
+func Negotiate(formatParam, accept string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(formatParam)) {
+	case "":
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	case "xlsx", "excel":
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV, nil
+		case xlsxContentType:
+			return FormatXLSX, nil
+		case "application/json":
+			return FormatJSON, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// ContentType mengembalikan Content-Type untuk format f.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return csvContentType
+	case FormatXLSX:
+		return xlsxContentType
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Column mendefinisikan satu kolom ekspor untuk baris bertipe T.
+type Column[T any] struct {
+	Key      string // Nama kolom untuk ?columns=, sama dengan field JSON
+	HeaderID string
+	HeaderEN string
+	Value    func(row T) interface{}
+}
+
+// Options adalah pilihan pemanggil: kolom (kosong = semua) dan bahasa header.
+type Options struct {
+	Columns []string
+	Lang    string
+}
+
+// ParseOptions membaca ?columns=a,b,c dan ?lang=id|en (default id).
+func ParseOptions(columnsParam, langParam string) (Options, error) {
+	opts := Options{Lang: LangID}
+	for _, key := range strings.Split(columnsParam, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			opts.Columns = append(opts.Columns, key)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(langParam)) {
+	case "", LangID:
+	case LangEN:
+		opts.Lang = LangEN
+	default:
+		return opts, ErrUnknownLang
+	}
+	return opts, nil
+}
+
+// Select mengembalikan kolom sesuai urutan keys; keys kosong berarti semua kolom.
+func Select[T any](all []Column[T], keys []string) ([]Column[T], error) {
+	if len(keys) == 0 {
+		return all, nil
+	}
+	byKey := make(map[string]Column[T], len(all))
+	for _, col := range all {
+		byKey[col.Key] = col
+	}
+	selected := make([]Column[T], 0, len(keys))
+	for _, key := range keys {
+		col, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, key)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+// Rows mengirim baris ekspor satu per satu ke yield, mis. langsung dari rows query database,
+// sehingga daftar tidak perlu dimuat seluruhnya ke memori. Error dari yield dikembalikan apa adanya.
+type Rows[T any] func(yield func(*T) error) error
+
+// Flatten memecah setiap entitas dari src menjadi beberapa baris ekspor, mis. MonthlyJobRows.
+func Flatten[S, T any](src Rows[S], rows func(*S) []T) Rows[T] {
+	return func(yield func(*T) error) error {
+		return src(func(s *S) error {
+			flat := rows(s)
+			for i := range flat {
+				if err := yield(&flat[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Write menulis header lalu setiap baris dari rows ke w begitu baris itu dibaca. Untuk CSV,
+// baris di-flush secara berkala sehingga unduhan besar langsung mengalir ke klien; sebelum
+// flush pertama belum ada yang ditulis ke w.
+func Write[T any](w io.Writer, format Format, sheet string, columns []Column[T], lang string, rows Rows[T]) error {
+	rw, err := newRowWriter(w, format, sheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.HeaderID
+		if lang == LangEN {
+			header[i] = col.HeaderEN
+		}
+	}
+	if err := rw.writeRow(header); err != nil {
+		rw.abort()
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	err = rows(func(row *T) error {
+		for i, col := range columns {
+			values[i] = formatValue(col.Value(*row), lang)
+		}
+		return rw.writeRow(values)
+	})
+	if err != nil {
+		rw.abort()
+		return err
+	}
+	return rw.close()
+}
+
+// FileName membangun nama file unduhan, mis. "klien-20250131.xlsx".
+func FileName(base string, format Format, now time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", base, now.Format("20060102"), format)
+}
+
+// formatValue menyamakan tampilan nilai: tanggal YYYY-MM-DD, pointer nil kosong,
+// boolean Ya/Tidak. Angka dibiarkan agar tetap numerik di XLSX; teks dinetralkan dari rumus.
+func formatValue(v interface{}, lang string) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return neutralizeFormula(val)
+	case *string:
+		if val == nil {
+			return ""
+		}
+		return neutralizeFormula(*val)
+	case *float64:
+		if val == nil {
+			return ""
+		}
+		return *val
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return formatValue(*val, lang)
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 {
+			return val.Format("2006-01-02")
+		}
+		return val.Format("2006-01-02 15:04:05")
+	case bool:
+		if lang == LangEN {
+			if val {
+				return "Yes"
+			}
+			return "No"
+		}
+		if val {
+			return "Ya"
+		}
+		return "Tidak"
+	}
+	return v
+}
+
+// formulaPrefixes adalah karakter awal yang membuat Excel dan LibreOffice membaca sel sebagai rumus.
+const formulaPrefixes = "=+-@\t\r"
+
+// neutralizeFormula mencegah formula injection: teks dari input pengguna, mis. nama klien
+// atau catatan invoice, yang diawali karakter rumus diberi awalan ' sehingga ditampilkan
+// sebagai teks biasa saat file dibuka di spreadsheet.
+func neutralizeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type rowWriter interface {
+	writeRow(values []interface{}) error
+	close() error
+	// abort melepas sumber daya tanpa menulis sisa data, setelah pengisian baris gagal
+	abort()
+}
+
+func newRowWriter(w io.Writer, format Format, sheet string) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvRowWriter{w: w, csv: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		f := excelize.NewFile()
+		if err := f.SetSheetName("Sheet1", sheet); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("gagal menyiapkan sheet: %w", err)
+		}
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("gagal menyiapkan stream XLSX: %w", err)
+		}
+		return &xlsxRowWriter{w: w, file: f, stream: sw}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+type csvRowWriter struct {
+	w    io.Writer
+	csv  *csv.Writer
+	rows int
+	buf  []string
+}
+
+func (cw *csvRowWriter) writeRow(values []interface{}) error {
+	cw.buf = cw.buf[:0]
+	for _, v := range values {
+		cw.buf = append(cw.buf, csvCell(v))
+	}
+	if err := cw.csv.Write(cw.buf); err != nil {
+		return fmt.Errorf("gagal menulis baris CSV: %w", err)
+	}
+	cw.rows++
+	if cw.rows%csvFlushEvery == 0 {
+		cw.flush()
+	}
+	return cw.csv.Error()
+}
+
+func (cw *csvRowWriter) flush() {
+	cw.csv.Flush()
+	if f, ok := cw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *csvRowWriter) close() error {
+	cw.flush()
+	return cw.csv.Error()
+}
+
+func (cw *csvRowWriter) abort() {}
+
+func csvCell(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(val)
+	}
+	return fmt.Sprint(v)
+}
+
+type xlsxRowWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (xw *xlsxRowWriter) writeRow(values []interface{}) error {
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	row := make([]interface{}, len(values))
+	copy(row, values)
+	if err := xw.stream.SetRow(cell, row); err != nil {
+		return fmt.Errorf("gagal menulis baris XLSX: %w", err)
+	}
+	return nil
+}
+
+func (xw *xlsxRowWriter) close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return fmt.Errorf("gagal menyelesaikan stream XLSX: %w", err)
+	}
+	if err := xw.file.Write(xw.w); err != nil {
+		return fmt.Errorf("gagal menulis file XLSX: %w", err)
+	}
+	return nil
+}
+
+func (xw *xlsxRowWriter) abort() {
+	xw.file.Close()
+}
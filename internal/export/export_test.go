@@ -0,0 +1,223 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// sliceRows returns a Rows source over list
+func sliceRows[T any](list []T) Rows[T] {
+	return func(yield func(*T) error) error {
+		for i := range list {
+			if err := yield(&list[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// readCSV writes rows as CSV and parses the output back
+func readCSV[T any](t *testing.T, columns []Column[T], lang string, rows Rows[T]) [][]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, "Sheet", columns, lang, rows); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	return records
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name, format, accept string
+		want                 Format
+		wantErr              bool
+	}{
+		{"default", "", "", FormatJSON, false},
+		{"format param", "CSV", "", FormatCSV, false},
+		{"excel alias", "excel", "", FormatXLSX, false},
+		{"param wins over Accept", "json", "text/csv", FormatJSON, false},
+		{"Accept csv", "", "text/csv; charset=utf-8", FormatCSV, false},
+		{"Accept xlsx after unknown type", "", "application/pdf, " + xlsxContentType, FormatXLSX, false},
+		{"Accept without known type", "", "text/html, */*", FormatJSON, false},
+		{"unknown format", "pdf", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Negotiate(tt.format, tt.accept)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Negotiate(%q, %q) = %q, %v; want %q, error %v", tt.format, tt.accept, got, err, tt.want, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrUnknownFormat) {
+				t.Errorf("err = %v, want ErrUnknownFormat", err)
+			}
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions(" client_name, ,ppn ", "EN")
+	if err != nil {
+		t.Fatalf("ParseOptions: %v", err)
+	}
+	if want := (Options{Columns: []string{"client_name", "ppn"}, Lang: LangEN}); !reflect.DeepEqual(opts, want) {
+		t.Errorf("opts = %+v, want %+v", opts, want)
+	}
+	if opts, _ := ParseOptions("", ""); opts.Columns != nil || opts.Lang != LangID {
+		t.Errorf("default opts = %+v, want all columns in %q", opts, LangID)
+	}
+	if _, err := ParseOptions("", "fr"); !errors.Is(err, ErrUnknownLang) {
+		t.Errorf("lang fr: err = %v, want ErrUnknownLang", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	all, err := Select(ClientColumns, nil)
+	if err != nil || len(all) != len(ClientColumns) {
+		t.Fatalf("Select(nil) = %d columns, %v; want all %d", len(all), err, len(ClientColumns))
+	}
+	cols, err := Select(ClientColumns, []string{"ppn", "client_name"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if len(cols) != 2 || cols[0].Key != "ppn" || cols[1].Key != "client_name" {
+		t.Errorf("Select kept %+v, want ppn then client_name", cols)
+	}
+	// Password Coretax tidak boleh bisa dipilih
+	if _, err := Select(ClientColumns, []string{"client_name", "coretax_password_hashed"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("err = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestWriteHeaderLanguage(t *testing.T) {
+	columns, err := Select(ClientColumns, []string{"client_name", "ppn", "created_at"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	clients := []models.Client{{ClientName: "PT Maju", Ppn: true, CreatedAt: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)}}
+	for lang, want := range map[string][][]string{
+		LangID: {{"Nama Klien", "PPN", "Dibuat"}, {"PT Maju", "Ya", "2025-01-31"}},
+		LangEN: {{"Client Name", "VAT", "Created At"}, {"PT Maju", "Yes", "2025-01-31"}},
+	} {
+		if got := readCSV(t, columns, lang, sliceRows(clients)); !reflect.DeepEqual(got, want) {
+			t.Errorf("lang %s: got %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestFlattenMonthlyJobs(t *testing.T) {
+	amount := 125000.0
+	jobs := []models.MonthlyJob{
+		{JobID: "job-1", TaxReports: []models.MonthlyTaxReport{
+			{TaxType: "PPN", TaxPaymentEvidence: models.TaxPaymentEvidence{BillingAmount: &amount}},
+			{TaxType: "PPH_21"},
+		}},
+		{JobID: "job-2"},
+	}
+	columns, err := Select(MonthlyJobColumns, []string{"job_id", "tax_type", "billing_amount"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	want := [][]string{
+		{"ID Pekerjaan", "Jenis Pajak", "Jumlah Billing"},
+		{"job-1", "PPN", "125000"},
+		{"job-1", "PPH_21", ""},
+		{"job-2", "", ""},
+	}
+	if got := readCSV(t, columns, LangID, Flatten(sliceRows(jobs), MonthlyJobRows)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Kegagalan sumber baris sebelum flush pertama tidak boleh menghasilkan file setengah jadi,
+// agar handler masih bisa menjawab dengan error.
+func TestWriteStopsOnRowsError(t *testing.T) {
+	errQuery := errors.New("query dibatalkan")
+	rows := func(yield func(*noteRow) error) error {
+		if err := yield(&noteRow{Note: "satu"}); err != nil {
+			return err
+		}
+		return errQuery
+	}
+	for _, format := range []Format{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, "Catatan", noteColumns, LangID, rows); !errors.Is(err, errQuery) {
+			t.Errorf("%s: err = %v, want %v", format, err, errQuery)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: wrote %d bytes before failing", format, buf.Len())
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	if got := FileName("klien", FormatXLSX, time.Date(2025, 1, 31, 15, 4, 5, 0, time.UTC)); got != "klien-20250131.xlsx" {
+		t.Errorf("FileName = %q", got)
+	}
+}
+
+type noteRow struct {
+	Note   string
+	Amount float64
+}
+
+var noteColumns = []Column[noteRow]{
+	{Key: "note", HeaderID: "Catatan", HeaderEN: "Note", Value: func(r noteRow) interface{} { return r.Note }},
+	{Key: "amount", HeaderID: "Jumlah", HeaderEN: "Amount", Value: func(r noteRow) interface{} { return r.Amount }},
+}
+
+// Teks dari pengguna tidak boleh dieksekusi sebagai rumus saat file dibuka di spreadsheet,
+// sedangkan angka negatif tetap angka.
+func TestWriteNeutralizesFormulas(t *testing.T) {
+	rows := []noteRow{
+		{Note: `=HYPERLINK("http://evil.example","klik")`, Amount: -1500},
+		{Note: "+62 812 0000", Amount: 0},
+		{Note: "-2+3", Amount: 1},
+		{Note: "@SUM(A1:A2)", Amount: 2},
+		{Note: "\tTAB", Amount: 3},
+		{Note: "Biasa = aman", Amount: 4},
+	}
+	want := [][]string{
+		{"Catatan", "Jumlah"},
+		{`'=HYPERLINK("http://evil.example","klik")`, "-1500"},
+		{"'+62 812 0000", "0"},
+		{"'-2+3", "1"},
+		{"'@SUM(A1:A2)", "2"},
+		{"'\tTAB", "3"},
+		{"Biasa = aman", "4"},
+	}
+
+	if got := readCSV(t, noteColumns, LangID, sliceRows(rows)); !reflect.DeepEqual(got, want) {
+		t.Errorf("CSV rows:\n%q\nwant:\n%q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, "Catatan", noteColumns, LangID, sliceRows(rows)); err != nil {
+		t.Fatalf("Write XLSX: %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("open XLSX: %v", err)
+	}
+	defer f.Close()
+	for i, row := range want {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if v, _ := f.GetCellValue("Catatan", cell); v != row[0] {
+			t.Errorf("XLSX %s = %q, want %q", cell, v, row[0])
+		}
+		if formula, _ := f.GetCellFormula("Catatan", cell); formula != "" {
+			t.Errorf("XLSX %s has formula %q", cell, formula)
+		}
+	}
+}
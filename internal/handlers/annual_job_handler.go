@@ -4,15 +4,15 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"       // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories" // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
 )
 
 // AnnualJobHandler handles HTTP requests for annual job operations
@@ -21,15 +21,17 @@ type AnnualJobHandler struct {
 	ClientRepo    repositories.ClientRepository
 	StaffRepo     repositories.StaffRepository
 	InvoiceService services.InvoiceService
+	DocumentService services.DocumentService
 }
 
 // NewAnnualJobHandler creates a new AnnualJobHandler
-func NewAnnualJobHandler(ajRepo repositories.AnnualJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService) *AnnualJobHandler {
+func NewAnnualJobHandler(ajRepo repositories.AnnualJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService, docService services.DocumentService) *AnnualJobHandler {
 	return &AnnualJobHandler{
 		AnnualJobRepo: ajRepo,
 		ClientRepo:    cRepo,
 		StaffRepo:     sRepo,
 		InvoiceService: invService,
+		DocumentService: docService,
 	}
 }
 
@@ -42,7 +44,7 @@ func (h *AnnualJobHandler) CreateAnnualJob(c *gin.Context) {
 	}
 
 	// Validate client_id existence
-	client, err := h.ClientRepo.GetClientByID(req.ClientID, "", true) // Admin check for client existence
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), req.ClientID, "", true) // Admin check for client existence
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate client ID: " + err.Error()})
 		return
@@ -54,7 +56,7 @@ func (h *AnnualJobHandler) CreateAnnualJob(c *gin.Context) {
 
 	// Validate assigned_pic_staff_sigma_id existence
 	if req.AssignedPicStaffSigmaID != "" {
-		staff, err := h.StaffRepo.GetStaffByID(req.AssignedPicStaffSigmaID)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), req.AssignedPicStaffSigmaID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate Assigned PIC Staff ID: " + err.Error()})
 			return
@@ -74,15 +76,24 @@ func (h *AnnualJobHandler) CreateAnnualJob(c *gin.Context) {
 
 	// Handle initial Annual Tax Report (if provided)
 	if req.TaxReport != nil {
-		annualJob.TaxReports = []models.AnnualTaxReport{
-			{
-				BillingCode:   req.TaxReport.BillingCode,
-				PaymentDate:   req.TaxReport.PaymentDate,
-				PaymentAmount: req.TaxReport.PaymentAmount,
-				ReportDate:    req.TaxReport.ReportDate,
-				ReportStatus:  req.TaxReport.ReportStatus,
+		taxReport := models.AnnualTaxReport{
+			BillingCode:   req.TaxReport.BillingCode,
+			PaymentDate:   req.TaxReport.PaymentDate,
+			PaymentAmount: req.TaxReport.PaymentAmount,
+			ReportDate:    req.TaxReport.ReportDate,
+			ReportStatus:  req.TaxReport.ReportStatus,
+			TaxPaymentEvidence: models.TaxPaymentEvidence{
+				BillingCodeExpiresAt: req.TaxReport.BillingCodeExpiresAt,
+				BillingAmount:        req.TaxReport.BillingAmount,
+				Ntpn:                 req.TaxReport.Ntpn,
+				PaymentChannel:       req.TaxReport.PaymentChannel,
 			},
 		}
+		if err := applyTaxPaymentRules(&taxReport.BillingCode, &taxReport.TaxPaymentEvidence, taxReport.PaymentDate, taxReport.ReportStatus, newReportIdentifiers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence: " + err.Error()})
+			return
+		}
+		annualJob.TaxReports = []models.AnnualTaxReport{taxReport}
 	}
 
 	// Handle initial Annual Dividend Report (if provided)
@@ -101,7 +112,27 @@ func (h *AnnualJobHandler) CreateAnnualJob(c *gin.Context) {
 		}
 	}
 
-	if err := h.AnnualJobRepo.CreateAnnualJob(annualJob); err != nil {
+	// Job ID dibuat di sini agar event ikut tersimpan dalam transaksi yang sama
+	jobID, err := utils.NewUUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate job ID: " + err.Error()})
+		return
+	}
+	annualJob.JobID = jobID
+	events, err := jobCreatedEvents(c, models.JobAssignment{
+		JobType:    models.JobTypeAnnual,
+		JobID:      annualJob.JobID,
+		ClientID:   annualJob.ClientID,
+		ClientName: client.ClientName,
+		Period:     annualJob.PeriodLabel(),
+		StaffID:    annualJob.AssignedPicStaffSigmaID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
+	}
+
+	if err := h.AnnualJobRepo.CreateAnnualJob(c.Request.Context(), annualJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create annual job: " + err.Error()})
 		return
 	}
@@ -122,7 +153,18 @@ func (h *AnnualJobHandler) GetAllAnnualJobs(c *gin.Context) {
 		return
 	}
 
-	jobs, err := h.AnnualJobRepo.GetAllAnnualJobs(userClaims.StaffID, userClaims.IsAdmin)
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		jobs := func(yield func(*models.AnnualJob) error) error {
+			return h.AnnualJobRepo.EachAnnualJob(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "pekerjaan-tahunan", "Pekerjaan Tahunan", export.AnnualJobColumns, export.Flatten(jobs, export.AnnualJobRows))
+		return
+	}
+	jobs, err := h.AnnualJobRepo.GetAllAnnualJobs(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve annual jobs: " + err.Error()})
 		return
@@ -145,7 +187,7 @@ func (h *AnnualJobHandler) GetAnnualJobByID(c *gin.Context) {
 		return
 	}
 
-	job, err := h.AnnualJobRepo.GetAnnualJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	job, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job not found or access denied"})
@@ -176,7 +218,7 @@ claims, exists := c.Get("user_claims")
 		return
 	}
 
-	existingJob, err := h.AnnualJobRepo.GetAnnualJobByID(id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
+	existingJob, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job not found"})
@@ -222,12 +264,7 @@ claims, exists := c.Get("user_claims")
 	if fileReceived {
 		// Buat nama file unik (misalnya JobID.pdf)
 		filename := fmt.Sprintf("%s.pdf", existingJob.JobID)
-		filePath := filepath.Join("uploads", filename) // Simpan di folder 'uploads'
-
-		// Pastikan folder 'uploads' ada
-		if _, err := os.Stat("uploads"); os.IsNotExist(err) {
-			os.Mkdir("uploads", os.ModePerm)
-		}
+		filePath := h.DocumentService.UploadPath(filename) // Simpan di folder upload (storage.upload_dir)
 
 		// Simpan file
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
@@ -241,12 +278,14 @@ claims, exists := c.Get("user_claims")
 	}
 
 	// --- TERAPKAN UPDATE KE existingJob BERDASARKAN FORM FIELD ---
+	previousPic := existingJob.AssignedPicStaffSigmaID
+	previousStatus := existingJob.OverallStatus
 	if overallStatusForm != "" {
 		existingJob.OverallStatus = overallStatusForm
 	}
 	if assignedPicStaffSigmaIDForm != "" {
 		// Validasi PIC Staff ID baru jika disediakan
-		staff, err := h.StaffRepo.GetStaffByID(assignedPicStaffSigmaIDForm)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), assignedPicStaffSigmaIDForm)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate new Assigned PIC Staff ID: " + err.Error()})
 			return
@@ -271,10 +310,28 @@ claims, exists := c.Get("user_claims")
 		existingJob.ProofOfWorkURL = uploadedFilePath
 	}
 
-	if err := h.AnnualJobRepo.UpdateAnnualJob(existingJob); err != nil {
+	events, err := jobUpdatedEvents(c, models.JobStatusChange{
+		JobType:    models.JobTypeAnnual,
+		JobID:      existingJob.JobID,
+		ClientID:   existingJob.ClientID,
+		ClientName: existingJob.ClientName,
+		Period:     existingJob.PeriodLabel(),
+		OldStatus:  previousStatus,
+		NewStatus:  existingJob.OverallStatus,
+		StaffID:    existingJob.AssignedPicStaffSigmaID,
+	}, previousPic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
+	}
+
+	if err := h.AnnualJobRepo.UpdateAnnualJob(c.Request.Context(), existingJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update annual job: " + err.Error()})
 		return
 	}
+	if uploadedFilePath != nil {
+		registerUploadedDocument(c, h.DocumentService, models.JobTypeAnnual, existingJob.JobID, existingJob.ClientID, *uploadedFilePath)
+	}
 	c.JSON(http.StatusOK, existingJob)
 }
 
@@ -299,7 +356,7 @@ func (h *AnnualJobHandler) CreateAnnualTaxReport(c *gin.Context) {
 	}
 
 	// Validate annual job existence AND user access
-	job, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	job, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job not found for tax report or access denied"})
@@ -326,9 +383,19 @@ func (h *AnnualJobHandler) CreateAnnualTaxReport(c *gin.Context) {
 		PaymentAmount: req.PaymentAmount,
 		ReportDate:    req.ReportDate,
 		ReportStatus:  req.ReportStatus,
+		TaxPaymentEvidence: models.TaxPaymentEvidence{
+			BillingCodeExpiresAt: req.BillingCodeExpiresAt,
+			BillingAmount:        req.BillingAmount,
+			Ntpn:                 req.Ntpn,
+			PaymentChannel:       req.PaymentChannel,
+		},
+	}
+	if err := applyTaxPaymentRules(&annualTaxReport.BillingCode, &annualTaxReport.TaxPaymentEvidence, annualTaxReport.PaymentDate, annualTaxReport.ReportStatus, newReportIdentifiers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence: " + err.Error()})
+		return
 	}
 
-	if err := h.AnnualJobRepo.CreateAnnualTaxReport(annualTaxReport); err != nil {
+	if err := h.AnnualJobRepo.CreateAnnualTaxReport(c.Request.Context(), annualTaxReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create annual tax report: " + err.Error()})
 		return
 	}
@@ -357,7 +424,7 @@ func (h *AnnualJobHandler) UpdateAnnualTaxReport(c *gin.Context) {
 		return
 	}
 
-	existingReport, err := h.AnnualJobRepo.GetAnnualTaxReportByID(reportID)
+	existingReport, err := h.AnnualJobRepo.GetAnnualTaxReportByID(c.Request.Context(), reportID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual tax report not found"})
@@ -377,7 +444,7 @@ func (h *AnnualJobHandler) UpdateAnnualTaxReport(c *gin.Context) {
     }
 
 	// Validate access to the parent annual job
-	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job for this tax report not found or access denied"})
@@ -408,8 +475,25 @@ func (h *AnnualJobHandler) UpdateAnnualTaxReport(c *gin.Context) {
 	if req.ReportStatus != nil {
 		existingReport.ReportStatus = *req.ReportStatus
 	}
+	if req.BillingCodeExpiresAt != nil {
+		existingReport.BillingCodeExpiresAt = req.BillingCodeExpiresAt
+	}
+	if req.BillingAmount != nil {
+		existingReport.BillingAmount = req.BillingAmount
+	}
+	if req.Ntpn != nil {
+		existingReport.Ntpn = *req.Ntpn
+	}
+	if req.PaymentChannel != nil {
+		existingReport.PaymentChannel = *req.PaymentChannel
+	}
+	if err := applyTaxPaymentRules(&existingReport.BillingCode, &existingReport.TaxPaymentEvidence, existingReport.PaymentDate, existingReport.ReportStatus,
+		paymentIdentifiers{BillingCode: req.BillingCode != nil, Ntpn: req.Ntpn != nil}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence: " + err.Error()})
+		return
+	}
 
-	if err := h.AnnualJobRepo.UpdateAnnualTaxReport(existingReport); err != nil {
+	if err := h.AnnualJobRepo.UpdateAnnualTaxReport(c.Request.Context(), existingReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update annual tax report: " + err.Error()})
 		return
 	}
@@ -432,7 +516,7 @@ func (h *AnnualJobHandler) DeleteAnnualTaxReport(c *gin.Context) {
 		return
 	}
 
-	existingReport, err := h.AnnualJobRepo.GetAnnualTaxReportByID(reportID)
+	existingReport, err := h.AnnualJobRepo.GetAnnualTaxReportByID(c.Request.Context(), reportID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual tax report not found"})
@@ -452,7 +536,7 @@ func (h *AnnualJobHandler) DeleteAnnualTaxReport(c *gin.Context) {
     }
 
 	// Validate access to the parent annual job
-	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job for this tax report not found or access denied"})
@@ -467,7 +551,7 @@ func (h *AnnualJobHandler) DeleteAnnualTaxReport(c *gin.Context) {
 	}
 
 
-	if err := h.AnnualJobRepo.DeleteAnnualTaxReport(reportID); err != nil {
+	if err := h.AnnualJobRepo.DeleteAnnualTaxReport(c.Request.Context(), reportID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete annual tax report: " + err.Error()})
 		return
 	}
@@ -501,7 +585,7 @@ claims, exists := c.Get("user_claims")
 	}
 
 	// Validate annual job existence AND user access
-	job, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	job, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job not found for dividend report or access denied"})
@@ -527,7 +611,7 @@ claims, exists := c.Get("user_claims")
 		ReportStatus: req.ReportStatus,
 	}
 
-	if err := h.AnnualJobRepo.CreateAnnualDividendReport(annualDividendReport); err != nil {
+	if err := h.AnnualJobRepo.CreateAnnualDividendReport(c.Request.Context(), annualDividendReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create annual dividend report: " + err.Error()})
 		return
 	}
@@ -554,7 +638,7 @@ func (h *AnnualJobHandler) UpdateAnnualDividendReport(c *gin.Context) {
 	// (misalnya client mengirim {"is_reported": true} tapi tidak ada "report_date")
 	// Ini butuh fetch existingReport dulu
 	if req.IsReported != nil && *req.IsReported && req.ReportDate == nil {
-		existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(reportID)
+		existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(c.Request.Context(), reportID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Annual dividend report not found"})
@@ -581,7 +665,7 @@ func (h *AnnualJobHandler) UpdateAnnualDividendReport(c *gin.Context) {
 		return
 	}
 
-	existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(reportID)
+	existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(c.Request.Context(), reportID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual dividend report not found"})
@@ -601,7 +685,7 @@ func (h *AnnualJobHandler) UpdateAnnualDividendReport(c *gin.Context) {
     }
 
 	// Validate access to the parent annual job
-	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job for this dividend report not found or access denied"})
@@ -626,7 +710,7 @@ func (h *AnnualJobHandler) UpdateAnnualDividendReport(c *gin.Context) {
 		existingReport.ReportStatus = *req.ReportStatus
 	}
 
-	if err := h.AnnualJobRepo.UpdateAnnualDividendReport(existingReport); err != nil {
+	if err := h.AnnualJobRepo.UpdateAnnualDividendReport(c.Request.Context(), existingReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update annual dividend report: " + err.Error()})
 		return
 	}
@@ -649,7 +733,7 @@ func (h *AnnualJobHandler) DeleteAnnualDividendReport(c *gin.Context) {
 		return
 	}
 
-	existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(reportID)
+	existingReport, err := h.AnnualJobRepo.GetAnnualDividendReportByID(c.Request.Context(), reportID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual dividend report not found"})
@@ -669,7 +753,7 @@ func (h *AnnualJobHandler) DeleteAnnualDividendReport(c *gin.Context) {
     }
 
 	// Validate access to the parent annual job
-	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	jobForReport, err := h.AnnualJobRepo.GetAnnualJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Annual job for this dividend report not found or access denied"})
@@ -683,7 +767,7 @@ func (h *AnnualJobHandler) DeleteAnnualDividendReport(c *gin.Context) {
 		return
 	}
 
-	if err := h.AnnualJobRepo.DeleteAnnualDividendReport(reportID); err != nil {
+	if err := h.AnnualJobRepo.DeleteAnnualDividendReport(c.Request.Context(), reportID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete annual dividend report: " + err.Error()})
 		return
 	}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	AuditRepo repositories.AuditRepository
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditRepo repositories.AuditRepository) *AuditHandler {
+	return &AuditHandler{AuditRepo: auditRepo}
+}
+
+// GetAuditLog returns recorded domain events, newest first. Admin only.
+// Filters: aggregate_type, aggregate_id, limit, and before (RFC3339) for paging.
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+	if !userClaims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can view the audit log"})
+		return
+	}
+
+	limit := defaultAuditLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	var before *time.Time
+	if beforeParam := c.Query("before"); beforeParam != "" {
+		t, err := time.Parse(time.RFC3339, beforeParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before, use RFC3339 format"})
+			return
+		}
+		before = &t
+	}
+
+	entries, err := h.AuditRepo.GetAuditLog(c.Request.Context(), c.Query("aggregate_type"), c.Query("aggregate_id"), limit, before)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
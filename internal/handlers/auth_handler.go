@@ -1,28 +1,45 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5" // Import library JWT
 
 	// Pastikan ini modul Anda
-	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories" // Pastikan ini modul Anda
-	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"             // Untuk CheckPasswordHash
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/password"
 )
 
 // AuthHandler handles authentication related requests
 type AuthHandler struct {
-	StaffRepo repositories.StaffRepository
-	JWTSecret string
+	StaffRepo        repositories.StaffRepository
+	AccountService   services.AccountService
+	TwoFactorService services.TwoFactorService
+	JWTSecret        string
+	TokenTTL         time.Duration
+	MFAChallengeTTL  time.Duration
 }
 
-// NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(staffRepo repositories.StaffRepository, jwtSecret string) *AuthHandler {
+// NewAuthHandler creates a new AuthHandler. tokenTTL is the lifetime of login tokens and
+// mfaChallengeTTL the time allowed between the password and the 2FA code.
+func NewAuthHandler(staffRepo repositories.StaffRepository, accountService services.AccountService, twoFactorService services.TwoFactorService,
+	jwtSecret string, tokenTTL, mfaChallengeTTL time.Duration) *AuthHandler {
 	return &AuthHandler{
-		StaffRepo: staffRepo,
-		JWTSecret: jwtSecret,
+		StaffRepo:        staffRepo,
+		AccountService:   accountService,
+		TwoFactorService: twoFactorService,
+		JWTSecret:        jwtSecret,
+		TokenTTL:         tokenTTL,
+		MFAChallengeTTL:  mfaChallengeTTL,
 	}
 }
 
@@ -32,7 +49,8 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// Login handles staff login and JWT token generation
+// Login is the first login step. Without 2FA it returns the JWT; with 2FA it returns a
+// short-lived mfa_token for POST /auth/login/verify instead.
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,36 +58,212 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	staff, err := h.StaffRepo.GetStaffByEmail(req.Email)
+	staff, err := h.AccountService.Authenticate(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
+		respondLoginError(c, err)
 		return
 	}
-	if staff == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+
+	if staff.TOTPEnabled {
+		mfaToken, err := h.issueMFAChallenge(staff.StaffID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(h.MFAChallengeTTL.Seconds()),
+		})
 		return
 	}
+	h.respondToken(c, staff)
+}
 
-	// Verify password
-	if !utils.CheckPasswordHash(req.Password, staff.PasswordHashed) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+// LoginVerify is the second login step: the mfa_token from Login plus a TOTP code or a
+// recovery code. Wrong codes count towards the account lockout.
+func (h *AuthHandler) LoginVerify(c *gin.Context) {
+	var req models.LoginVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	staffID, ok := h.parseMFAChallenge(req.MFAToken)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token, please log in again"})
+		return
+	}
+	staff, err := h.AccountService.VerifySecondFactor(c.Request.Context(), staffID, req.Code)
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+	h.respondToken(c, staff)
+}
+
+// ChangePassword lets the logged-in staff member change their own password. The response
+// contains a new token, because a token issued with a pending password change is limited.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.AccountService.ChangePassword(c.Request.Context(), claims.StaffID, req.CurrentPassword, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+		respondPasswordError(c, err)
+		return
+	}
+
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), claims.StaffID)
+	if err != nil || staff == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	signedToken, err := issueStaffToken(h.JWTSecret, h.TokenTTL, staff, h.TwoFactorService.Required(staff.Role))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully", "token": signedToken})
+}
+
+// ForgotPassword sends a password reset link by email. The response is the same whether or
+// not the email is registered.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.AccountService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		logger.FromContext(c.Request.Context()).Error("password reset request failed", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password reset request"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// ResetPassword sets a new password with the single-use token from the reset email
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.AccountService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in with the new password"})
+}
+
+// respondToken writes the login response with the staff JWT
+func (h *AuthHandler) respondToken(c *gin.Context, staff *models.Staff) {
+	twoFactorSetup := h.TwoFactorService.Required(staff.Role) && !staff.TOTPEnabled
+	signedToken, err := issueStaffToken(h.JWTSecret, h.TokenTTL, staff, twoFactorSetup)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Generate JWT token
+	// Selama salah satu flag true, token hanya bisa dipakai untuk ganti password dan enrollment 2FA
+	c.JSON(http.StatusOK, gin.H{
+		"token":                     signedToken,
+		"must_change_password":      staff.MustChangePassword,
+		"two_factor_setup_required": twoFactorSetup,
+	})
+}
+
+// issueMFAChallenge signs the token that links the two login steps. It has its own audience,
+// so it is not accepted as a login token.
+func (h *AuthHandler) issueMFAChallenge(staffID string) (string, error) {
+	claims := jwt.MapClaims{
+		"staff_id": staffID,
+		"aud":      auth.AudienceStaffMFA,
+		"exp":      time.Now().Add(h.MFAChallengeTTL).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.JWTSecret))
+}
+
+func (h *AuthHandler) parseMFAChallenge(tokenString string) (string, bool) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(h.JWTSecret), nil
+	}, jwt.WithAudience(auth.AudienceStaffMFA), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return "", false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", false
+	}
+	staffID, ok := claims["staff_id"].(string)
+	return staffID, ok && staffID != ""
+}
+
+// issueStaffToken signs a staff login token. The pwd_change and mfa_setup claims limit the
+// token to the account setup endpoints until the password is changed or 2FA is enrolled.
+func issueStaffToken(jwtSecret string, ttl time.Duration, staff *models.Staff, twoFactorSetup bool) (string, error) {
 	// Custom claims: staff_id and role
 	claims := jwt.MapClaims{
 		"staff_id": staff.StaffID,
 		"role":     staff.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token berlaku 24 jam
+		"aud":      auth.AudienceStaff,          // Membedakan token staf dari token portal klien
+		"exp":      time.Now().Add(ttl).Unix(), // Token berlaku sesuai auth.token_ttl
+	}
+	if staff.MustChangePassword {
+		claims[auth.ClaimPasswordChange] = true
+	}
+	if twoFactorSetup {
+		claims[auth.ClaimTwoFactorSetup] = true
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(h.JWTSecret))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// respondLoginError writes the response for a failed login step
+func respondLoginError(c *gin.Context, err error) {
+	var locked *services.AccountLockedError
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	case errors.Is(err, services.ErrInvalidTwoFactorCode), errors.Is(err, services.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+	case errors.As(err, &locked):
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(time.Until(locked.Until).Seconds())), 1)))
+		c.JSON(http.StatusLocked, gin.H{
+			"error":        "Account is temporarily locked after too many failed logins",
+			"locked_until": locked.Until,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
 	}
+}
 
-	c.JSON(http.StatusOK, gin.H{"token": signedToken})
-}
\ No newline at end of file
+// respondPasswordError writes the response for errors of setting a password: policy
+// violations are listed so the user can fix them all at once.
+func respondPasswordError(c *gin.Context, err error) {
+	var policyErr *password.PolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password does not meet the password policy", "problems": policyErr.Problems})
+	case errors.Is(err, services.ErrPasswordReused):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the current password"})
+	case errors.Is(err, services.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reset token is invalid or has expired"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+	}
+}
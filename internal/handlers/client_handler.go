@@ -3,11 +3,14 @@ package handlers
 import (
 	"database/sql"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils" // For password hashing
 )
 
@@ -45,7 +48,7 @@ func (h *ClientHandler) CreateClient(c *gin.Context) {
 
     // Optional: Validate if PicStaffSigmaID exists
     if req.PicStaffSigmaID != "" {
-        staff, err := h.StaffRepo.GetStaffByID(req.PicStaffSigmaID) // Need StaffRepo in ClientHandler
+        staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), req.PicStaffSigmaID) // Need StaffRepo in ClientHandler
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate PIC Staff ID"})
             return
@@ -56,6 +59,19 @@ func (h *ClientHandler) CreateClient(c *gin.Context) {
         }
     }
 
+	number, err := npwp.Parse(req.NpwpClient)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid NPWP: " + err.Error()})
+		return
+	}
+	if existing, err := h.ClientRepo.GetClientsByNpwp(c.Request.Context(), []string{number.Canonical()}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check NPWP: " + err.Error()})
+		return
+	} else if len(existing) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Client with this NPWP already exists: " + existing[0].ClientName})
+		return
+	}
+
 	// Hash the CoretaxPassword from the request
 	hashedPassword, err := utils.HashPassword(req.CoretaxPassword)
 	if err != nil {
@@ -65,7 +81,8 @@ func (h *ClientHandler) CreateClient(c *gin.Context) {
 
 	client := &models.Client{
 		ClientName:           req.ClientName,
-		NpwpClient:           req.NpwpClient,
+		NpwpClient:           number.Format(),
+		NpwpCanonical:        number.Canonical(),
 		AddressClient:        req.AddressClient,
 		MembershipStatus:     req.MembershipStatus,
 		PhoneClient:          req.PhoneClient,
@@ -87,15 +104,18 @@ func (h *ClientHandler) CreateClient(c *gin.Context) {
 		InvestasiDeviden:     req.InvestasiDeviden,
 	}
 
-	if err := h.ClientRepo.CreateClient(client); err != nil {
+	if err := h.ClientRepo.CreateClient(c.Request.Context(), client); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create client: " + err.Error()})
 		return
 	}
 
+	// client.created dikirim subscriber webhook dari event ClientCreated di outbox
+
 	c.JSON(http.StatusCreated, client)
 }
 
-// GetAllClients fetches all clients
+// GetAllClients fetches all clients.
+// Optional query param q filters by client name or NPWP (either 15- or 16-digit form).
 func (h *ClientHandler) GetAllClients(c *gin.Context) {
 	claims, exists := c.Get("user_claims")
 	if !exists {
@@ -107,7 +127,25 @@ func (h *ClientHandler) GetAllClients(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
 		return
 	}
-	clients, err := h.ClientRepo.GetAllClients(userClaims.StaffID, userClaims.IsAdmin)
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	q := strings.TrimSpace(c.Query("q"))
+	if format != export.FormatJSON {
+		clients := func(yield func(*models.Client) error) error {
+			return h.ClientRepo.EachClient(c.Request.Context(), q, userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "klien", "Klien", export.ClientColumns, clients)
+		return
+	}
+	var clients []models.Client
+	var err error
+	if q != "" {
+		clients, err = h.ClientRepo.SearchClients(c.Request.Context(), q, userClaims.StaffID, userClaims.IsAdmin)
+	} else {
+		clients, err = h.ClientRepo.GetAllClients(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve clients"})
 		return
@@ -131,7 +169,7 @@ func (h *ClientHandler) GetClientDashboardJobs(c *gin.Context) {
 	}
 
 	// 1. Validasi keberadaan klien
-	client, err := h.ClientRepo.GetClientByID(clientID, userClaims.StaffID, userClaims.IsAdmin)
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), clientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found or access denied"})
@@ -144,25 +182,25 @@ func (h *ClientHandler) GetClientDashboardJobs(c *gin.Context) {
 	// 2. Ambil semua jenis pekerjaan untuk klien ini
 	// ================== AWAL PERBAIKAN LOGIKA ==================
 
-	monthlyJobs, err := h.MonthlyJobRepo.GetMonthlyJobsByClientID(clientID, userClaims.StaffID, userClaims.IsAdmin)
+	monthlyJobs, err := h.MonthlyJobRepo.GetMonthlyJobsByClientID(c.Request.Context(), clientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil && err != sql.ErrNoRows { // Hanya return jika error BUKAN karena tidak ditemukan
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monthly jobs for client: " + err.Error()})
 		return
 	}
 
-	annualJobs, err := h.AnnualJobRepo.GetAnnualJobsByClientID(clientID, userClaims.StaffID, userClaims.IsAdmin)
+	annualJobs, err := h.AnnualJobRepo.GetAnnualJobsByClientID(c.Request.Context(), clientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil && err != sql.ErrNoRows { // Terapkan pola yang sama
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve annual jobs for client: " + err.Error()})
 		return
 	}
 
-	sp2dkJobs, err := h.Sp2dkJobRepo.GetSp2dkJobsByClientID(clientID, userClaims.StaffID, userClaims.IsAdmin)
+	sp2dkJobs, err := h.Sp2dkJobRepo.GetSp2dkJobsByClientID(c.Request.Context(), clientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil && err != sql.ErrNoRows { // Terapkan pola yang sama
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve SP2DK jobs for client: " + err.Error()})
 		return
 	}
 
-	pemeriksaanJobs, err := h.PemeriksaanJobRepo.GetPemeriksaanJobsByClientID(clientID, userClaims.StaffID, userClaims.IsAdmin)
+	pemeriksaanJobs, err := h.PemeriksaanJobRepo.GetPemeriksaanJobsByClientID(c.Request.Context(), clientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil && err != sql.ErrNoRows { // Terapkan pola yang sama
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Pemeriksaan jobs for client: " + err.Error()})
 		return
@@ -199,7 +237,7 @@ claims, exists := c.Get("user_claims")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
 		return
 	}
-	client, err := h.ClientRepo.GetClientByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows { // Ini akan menangani not found (baik karena ID salah atau tidak punya akses)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found or access denied"})
@@ -234,7 +272,7 @@ claims, exists := c.Get("user_claims")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
 		return
 	}
-	existingClient, err := h.ClientRepo.GetClientByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	existingClient, err := h.ClientRepo.GetClientByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
         if err == sql.ErrNoRows {
             c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
@@ -253,7 +291,24 @@ claims, exists := c.Get("user_claims")
 		existingClient.ClientName = *req.ClientName
 	}
 	if req.NpwpClient != nil {
-		existingClient.NpwpClient = *req.NpwpClient
+		number, err := npwp.Parse(*req.NpwpClient)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid NPWP: " + err.Error()})
+			return
+		}
+		if number.Canonical() != existingClient.NpwpCanonical {
+			others, err := h.ClientRepo.GetClientsByNpwp(c.Request.Context(), []string{number.Canonical()})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check NPWP: " + err.Error()})
+				return
+			}
+			if len(others) > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Client with this NPWP already exists: " + others[0].ClientName})
+				return
+			}
+		}
+		existingClient.NpwpClient = number.Format()
+		existingClient.NpwpCanonical = number.Canonical()
 	}
 	if req.AddressClient != nil {
 		existingClient.AddressClient = *req.AddressClient
@@ -279,7 +334,7 @@ claims, exists := c.Get("user_claims")
 	}
 	if req.PicStaffSigmaID != nil { // UBAH INI
         // Optional: Validate if PicStaffSigmaID exists
-        staff, err := h.StaffRepo.GetStaffByID(*req.PicStaffSigmaID) // Need StaffRepo
+        staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), *req.PicStaffSigmaID) // Need StaffRepo
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate PIC Staff ID"})
             return
@@ -306,7 +361,7 @@ claims, exists := c.Get("user_claims")
 		existingClient.CoretaxPasswordHashed = hashedPassword
 	}
 
-	if err := h.ClientRepo.UpdateClient(existingClient); err != nil {
+	if err := h.ClientRepo.UpdateClient(c.Request.Context(), existingClient); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update client: " + err.Error()})
 		return
 	}
@@ -330,7 +385,7 @@ func (h *ClientHandler) DeleteClient(c *gin.Context) {
 	}
 
 	// Check if client exists before deleting
-	client, err := h.ClientRepo.GetClientByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check client existence"})
 		return
@@ -340,7 +395,7 @@ func (h *ClientHandler) DeleteClient(c *gin.Context) {
 		return
 	}
 
-	if err := h.ClientRepo.DeleteClient(id); err != nil {
+	if err := h.ClientRepo.DeleteClient(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete client"})
 		return
 	}
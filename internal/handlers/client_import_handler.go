@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/tabular"
+)
+
+// ClientImportHandler handles bulk client onboarding from CSV/XLSX files
+type ClientImportHandler struct {
+	ClientImportService services.ClientImportService
+}
+
+// NewClientImportHandler creates a new ClientImportHandler
+func NewClientImportHandler(importService services.ClientImportService) *ClientImportHandler {
+	return &ClientImportHandler{ClientImportService: importService}
+}
+
+// ImportClients handles POST /clients/import (multipart form).
+// Fields: file (.csv/.xlsx), mapping (JSON: {"client_name": "Nama Klien", ...}),
+// mode ("insert" atau "upsert"), dry_run ("true" untuk validasi saja).
+func (h *ClientImportHandler) ImportClients(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required in form field 'file'"})
+		return
+	}
+
+	opts := services.ClientImportOptions{
+		Mode:    c.PostForm("mode"),
+		StaffID: userClaims.StaffID,
+		IsAdmin: userClaims.IsAdmin,
+	}
+	if dryRun := c.PostForm("dry_run"); dryRun != "" {
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+			return
+		}
+	}
+	if mapping := c.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping JSON: " + err.Error()})
+			return
+		}
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open uploaded file: " + err.Error()})
+		return
+	}
+	defer f.Close()
+
+	table, err := tabular.Read(fileHeader.Filename, f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file: " + err.Error()})
+		return
+	}
+
+	result, err := h.ClientImportService.ImportClients(c.Request.Context(), table, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidClientImportMapping) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import clients: " + err.Error()})
+		return
+	}
+
+	if result.ErrorRows > 0 && !result.DryRun {
+		// Tidak ada yang disimpan: perbaiki baris yang error lalu upload ulang
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// ClientUserHandler lets admins manage the client portal logins of a client
+type ClientUserHandler struct {
+	ClientUserRepo repositories.ClientUserRepository
+	ClientRepo     repositories.ClientRepository
+}
+
+// NewClientUserHandler creates a new ClientUserHandler
+func NewClientUserHandler(cuRepo repositories.ClientUserRepository, cRepo repositories.ClientRepository) *ClientUserHandler {
+	return &ClientUserHandler{ClientUserRepo: cuRepo, ClientRepo: cRepo}
+}
+
+// requirePortalAdmin rejects non-admin staff; only admins can hand out client logins.
+func requirePortalAdmin(c *gin.Context) bool {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return false
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return false
+	}
+	if !userClaims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage client portal users"})
+		return false
+	}
+	return true
+}
+
+// CreateClientUser creates a portal login for the client in the URL
+func (h *ClientUserHandler) CreateClientUser(c *gin.Context) {
+	if !requirePortalAdmin(c) {
+		return
+	}
+	var req models.NewClientUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clientID := c.Param("id")
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), clientID, "", true)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate client ID: " + err.Error()})
+		return
+	}
+	if client == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		return
+	}
+	existing, err := h.ClientUserRepo.GetClientUserByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email: " + err.Error()})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already used by another client user"})
+		return
+	}
+
+	hashed, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+	user := &models.ClientUser{
+		ClientID:       clientID,
+		ClientName:     client.ClientName,
+		Nama:           req.Nama,
+		Email:          req.Email,
+		PasswordHashed: hashed,
+		IsActive:       true,
+	}
+	if err := h.ClientUserRepo.CreateClientUser(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create client user: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// GetClientUsers lists the portal logins of the client in the URL
+func (h *ClientUserHandler) GetClientUsers(c *gin.Context) {
+	if !requirePortalAdmin(c) {
+		return
+	}
+	users, err := h.ClientUserRepo.GetClientUsersByClientID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve client users: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// UpdateClientUser changes name, email, password or active flag of a portal login
+func (h *ClientUserHandler) UpdateClientUser(c *gin.Context) {
+	if !requirePortalAdmin(c) {
+		return
+	}
+	var req models.UpdateClientUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.ClientUserRepo.GetClientUserByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve client user: " + err.Error()})
+		return
+	}
+
+	if req.Nama != nil {
+		user.Nama = *req.Nama
+	}
+	if req.Email != nil {
+		other, err := h.ClientUserRepo.GetClientUserByEmail(c.Request.Context(), *req.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email: " + err.Error()})
+			return
+		}
+		if other != nil && other.ClientUserID != user.ClientUserID {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already used by another client user"})
+			return
+		}
+		user.Email = *req.Email
+	}
+	if req.Password != nil {
+		hashed, err := utils.HashPassword(*req.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		user.PasswordHashed = hashed
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+
+	if err := h.ClientUserRepo.UpdateClientUser(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update client user: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteClientUser removes a portal login
+func (h *ClientUserHandler) DeleteClientUser(c *gin.Context) {
+	if !requirePortalAdmin(c) {
+		return
+	}
+	if err := h.ClientUserRepo.DeleteClientUser(c.Request.Context(), c.Param("id")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete client user: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Client user deleted successfully"})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// DocumentHandler handles HTTP requests for uploaded documents and content search
+type DocumentHandler struct {
+	DocumentRepo    repositories.DocumentRepository
+	DocumentService services.DocumentService
+}
+
+// NewDocumentHandler creates a new DocumentHandler
+func NewDocumentHandler(docRepo repositories.DocumentRepository, docService services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		DocumentRepo:    docRepo,
+		DocumentService: docService,
+	}
+}
+
+// SearchDocuments searches uploaded documents by their extracted text.
+// Query params: q (required), client_id, job_type, limit
+func (h *DocumentHandler) SearchDocuments(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	params := models.DocumentSearchParams{
+		Query:    strings.TrimSpace(c.Query("q")),
+		ClientID: c.Query("client_id"),
+	}
+	if params.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+	if jobTypeParam := c.Query("job_type"); jobTypeParam != "" {
+		jobType, ok := models.ParseJobType(jobTypeParam)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job_type"})
+			return
+		}
+		params.JobType = jobType
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+			return
+		}
+		params.Limit = limit
+	}
+
+	results, err := h.DocumentRepo.SearchDocuments(c.Request.Context(), params, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search documents: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetDocumentByID fetches a single document including its extracted text
+func (h *DocumentHandler) GetDocumentByID(c *gin.Context) {
+	id := c.Param("id")
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	doc, err := h.DocumentRepo.GetDocumentByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
+
+// DownloadDocument sends a document file as an attachment, with the same access rules as GetDocumentByID
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	id := c.Param("id")
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	doc, err := h.DocumentRepo.GetDocumentByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document: " + err.Error()})
+		return
+	}
+
+	filePath := h.DocumentService.LocalPath(doc.FileURL)
+	if _, err := os.Stat(filePath); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document file not found"})
+		return
+	}
+	c.FileAttachment(filePath, doc.FileName)
+}
+
+// ReextractDocument queues a document for text extraction again (e.g. after a failed run)
+func (h *DocumentHandler) ReextractDocument(c *gin.Context) {
+	id := c.Param("id")
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	doc, err := h.DocumentRepo.GetDocumentByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document: " + err.Error()})
+		return
+	}
+
+	if err := h.DocumentService.Reextract(c.Request.Context(), doc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue document extraction: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, doc)
+}
+
+// registerUploadedDocument records an uploaded file so its text gets extracted and indexed.
+// Failures are only logged: the upload itself has already succeeded.
+func registerUploadedDocument(c *gin.Context, docService services.DocumentService, jobType, jobID, clientID, fileURL string) {
+	if docService == nil {
+		return
+	}
+
+	doc := &models.Document{
+		ClientID: clientID,
+		JobType:  jobType,
+		JobID:    jobID,
+		FileName: path.Base(fileURL),
+		FileURL:  fileURL,
+	}
+	if claims, exists := c.Get("user_claims"); exists {
+		if userClaims, ok := claims.(*auth.Claims); ok && userClaims.StaffID != "" {
+			doc.UploadedByStaffID = &userClaims.StaffID
+		}
+	}
+
+	if err := docService.RegisterUpload(c.Request.Context(), doc); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("Gagal mencatat dokumen upload", "job_id", jobID, "error", err)
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+// DocumentRequestHandler handles the checklists of documents staff request from clients
+type DocumentRequestHandler struct {
+	DocumentRequestRepo repositories.DocumentRequestRepository
+}
+
+// NewDocumentRequestHandler creates a new DocumentRequestHandler
+func NewDocumentRequestHandler(drRepo repositories.DocumentRequestRepository) *DocumentRequestHandler {
+	return &DocumentRequestHandler{DocumentRequestRepo: drRepo}
+}
+
+// staffClaims reads the logged-in staff from user_claims.
+func staffClaims(c *gin.Context) (*auth.Claims, bool) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return nil, false
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return nil, false
+	}
+	return userClaims, true
+}
+
+// requestJob fetches the job of a checklist. Non-admins may only manage checklists of jobs
+// they are the PIC of; other jobs are reported as not found.
+func (h *DocumentRequestHandler) requestJob(c *gin.Context, jobType, jobID string) (*models.DocumentRequestJob, bool) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return nil, false
+	}
+	if !isKnownJobType(jobType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job_type"})
+		return nil, false
+	}
+	job, err := h.DocumentRequestRepo.GetJob(c.Request.Context(), jobType, jobID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job: " + err.Error()})
+		return nil, false
+	}
+	if err == sql.ErrNoRows || (!claims.IsAdmin && job.StaffID != claims.StaffID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return nil, false
+	}
+	return job, true
+}
+
+func isKnownJobType(jobType string) bool {
+	for _, t := range models.JobTypes {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
+
+// loadRequest fetches a checklist item the logged-in staff may manage
+func (h *DocumentRequestHandler) loadRequest(c *gin.Context) (*models.DocumentRequest, bool) {
+	req, err := h.DocumentRequestRepo.GetDocumentRequestByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document request not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document request: " + err.Error()})
+		return nil, false
+	}
+	if _, ok := h.requestJob(c, req.JobType, req.JobID); !ok {
+		return nil, false
+	}
+	return req, true
+}
+
+// CreateDocumentRequests adds checklist items to a job
+func (h *DocumentRequestHandler) CreateDocumentRequests(c *gin.Context) {
+	var input models.NewDocumentRequestsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job, ok := h.requestJob(c, input.JobType, input.JobID)
+	if !ok {
+		return
+	}
+
+	var createdBy *string
+	if staffID := actorStaffID(c); staffID != "" {
+		createdBy = &staffID
+	}
+	reqs := make([]models.DocumentRequest, 0, len(input.Items))
+	for _, item := range input.Items {
+		if strings.TrimSpace(item.Title) == "" || item.DueDate.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Every item needs a title and due_date"})
+			return
+		}
+		reqs = append(reqs, models.DocumentRequest{
+			JobType:          job.JobType,
+			JobID:            job.JobID,
+			ClientID:         job.ClientID,
+			ClientName:       job.ClientName,
+			Period:           job.Period,
+			Title:            strings.TrimSpace(item.Title),
+			Description:      item.Description,
+			DueDate:          item.DueDate,
+			CreatedByStaffID: createdBy,
+		})
+	}
+	if err := h.DocumentRequestRepo.CreateDocumentRequests(c.Request.Context(), reqs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create document requests: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, reqs)
+}
+
+// GetDocumentRequests lists checklist items. Filters: job_type, job_id, client_id, status,
+// outstanding=true. Non-admins only see items of their own jobs.
+func (h *DocumentRequestHandler) GetDocumentRequests(c *gin.Context) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	filter := models.DocumentRequestFilter{
+		JobType:         c.Query("job_type"),
+		JobID:           c.Query("job_id"),
+		ClientID:        c.Query("client_id"),
+		Status:          c.Query("status"),
+		OutstandingOnly: c.Query("outstanding") == "true",
+	}
+	if !claims.IsAdmin {
+		filter.StaffID = claims.StaffID
+	}
+	reqs, err := h.DocumentRequestRepo.GetDocumentRequests(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document requests: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reqs)
+}
+
+// GetJobChecklist returns the checklist of one job (?job_type=&job_id=) and the documents
+// still missing before work can start
+func (h *DocumentRequestHandler) GetJobChecklist(c *gin.Context) {
+	job, ok := h.requestJob(c, c.Query("job_type"), c.Query("job_id"))
+	if !ok {
+		return
+	}
+	reqs, err := h.DocumentRequestRepo.GetDocumentRequests(c.Request.Context(), models.DocumentRequestFilter{JobType: job.JobType, JobID: job.JobID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document requests: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.NewDocumentRequestChecklist(*job, reqs))
+}
+
+// GetDocumentRequestByID returns one checklist item
+func (h *DocumentRequestHandler) GetDocumentRequestByID(c *gin.Context) {
+	req, ok := h.loadRequest(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// UpdateDocumentRequest changes title, description or due date of a checklist item
+func (h *DocumentRequestHandler) UpdateDocumentRequest(c *gin.Context) {
+	var input models.UpdateDocumentRequestRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, ok := h.loadRequest(c)
+	if !ok {
+		return
+	}
+
+	if input.Title != nil {
+		if strings.TrimSpace(*input.Title) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		req.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Description != nil {
+		req.Description = input.Description
+	}
+	if input.DueDate != nil {
+		if input.DueDate.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Due date cannot be empty"})
+			return
+		}
+		req.DueDate = *input.DueDate
+	}
+
+	if err := h.DocumentRequestRepo.UpdateDocumentRequest(c.Request.Context(), req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document request: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// ReviewDocumentRequest accepts or rejects the document a client uploaded
+func (h *DocumentRequestHandler) ReviewDocumentRequest(c *gin.Context) {
+	var input models.ReviewDocumentRequestRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, ok := h.loadRequest(c)
+	if !ok {
+		return
+	}
+	if req.DocumentID == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nothing has been uploaded for this document request yet"})
+		return
+	}
+	if input.Status == models.DocumentRequestStatusRejected && (input.ReviewNote == nil || strings.TrimSpace(*input.ReviewNote) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "review_note is required when rejecting, so the client knows what to fix"})
+		return
+	}
+
+	now := time.Now()
+	req.Status = input.Status
+	req.ReviewNote = input.ReviewNote
+	req.ReviewedAt = &now
+	req.ReviewedByStaffID = nil
+	if staffID := actorStaffID(c); staffID != "" {
+		req.ReviewedByStaffID = &staffID
+	}
+	if err := h.DocumentRequestRepo.UpdateDocumentRequest(c.Request.Context(), req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review document request: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DeleteDocumentRequest removes a checklist item
+func (h *DocumentRequestHandler) DeleteDocumentRequest(c *gin.Context) {
+	req, ok := h.loadRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.DocumentRequestRepo.DeleteDocumentRequest(c.Request.Context(), req.RequestID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document request: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document request deleted successfully"})
+}
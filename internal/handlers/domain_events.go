@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+// actorStaffID returns the staff ID from user_claims, or "" if there are none.
+func actorStaffID(c *gin.Context) string {
+	if claims, exists := c.Get("user_claims"); exists {
+		if userClaims, ok := claims.(*auth.Claims); ok {
+			return userClaims.StaffID
+		}
+	}
+	return ""
+}
+
+// jobCreatedEvents builds the domain events for a new job. The job ID must already be set,
+// so the events can be written in the same transaction as the job itself.
+func jobCreatedEvents(c *gin.Context, assignment models.JobAssignment) ([]models.DomainEvent, error) {
+	if assignment.StaffID == "" {
+		return nil, nil
+	}
+	actor := actorStaffID(c)
+	assignment.AssignedBy = actor
+	event, err := models.NewDomainEvent(models.DomainEventJobReassigned, models.AggregateJob, assignment.JobID, actor, assignment)
+	if err != nil {
+		return nil, err
+	}
+	return []models.DomainEvent{event}, nil
+}
+
+// jobUpdatedEvents builds the domain events for a job update from its status change and
+// previous PIC: JobStatusChanged and JobCompleted when the status changed, JobReassigned
+// when the PIC changed. It returns no events if neither changed.
+func jobUpdatedEvents(c *gin.Context, change models.JobStatusChange, previousPic string) ([]models.DomainEvent, error) {
+	actor := actorStaffID(c)
+	change.ChangedBy = actor
+	change.ChangedAt = time.Now()
+
+	var events []models.DomainEvent
+	add := func(eventType string, payload interface{}) error {
+		event, err := models.NewDomainEvent(eventType, models.AggregateJob, change.JobID, actor, payload)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	}
+
+	if change.NewStatus != change.OldStatus {
+		if err := add(models.DomainEventJobStatusChanged, change); err != nil {
+			return nil, err
+		}
+		if change.NewStatus == models.JobStatusCompleted {
+			if err := add(models.DomainEventJobCompleted, change); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if change.StaffID != previousPic {
+		err := add(models.DomainEventJobReassigned, models.JobAssignment{
+			JobType:         change.JobType,
+			JobID:           change.JobID,
+			ClientID:        change.ClientID,
+			ClientName:      change.ClientName,
+			Period:          change.Period,
+			PreviousStaffID: previousPic,
+			StaffID:         change.StaffID,
+			AssignedBy:      actor,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
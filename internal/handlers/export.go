@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// negotiateExport reads ?format= and the Accept header of a list request.
+// It writes a 400 response and returns false when the format is unknown.
+func negotiateExport(c *gin.Context) (export.Format, bool) {
+	format, err := export.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format: " + err.Error()})
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport streams rows as a CSV or XLSX download while they are read from the repository.
+// The caller has already applied its visibility filter; ?columns= selects and orders columns,
+// ?lang=id|en picks the header language.
+func writeExport[T any](c *gin.Context, format export.Format, baseName, sheet string, all []export.Column[T], rows export.Rows[T]) {
+	opts, err := export.ParseOptions(c.Query("columns"), c.Query("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export option: " + err.Error()})
+		return
+	}
+	columns, err := export.Select(all, opts.Columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export option: " + err.Error()})
+		return
+	}
+
+	fileName := export.FileName(baseName, format, time.Now())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer, format, sheet, columns, opts.Lang, rows); err != nil {
+		if !c.Writer.Written() {
+			// Belum ada byte yang terkirim (mis. query gagal sebelum flush pertama), jadi masih bisa dijawab dengan error
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write export: " + err.Error()})
+			return
+		}
+		// Header sudah terkirim, jadi kegagalan di tengah stream hanya bisa dicatat
+		logger.FromContext(c.Request.Context()).Warn("Gagal menulis ekspor", "file_name", fileName, "error", err)
+	}
+}
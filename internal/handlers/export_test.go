@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/memory"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+func TestGetAllMonthlyJobsExportCSV(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	staffs := memory.NewStaffRepository(db)
+	clients := memory.NewClientRepository(db)
+	monthlyJobs := memory.NewMonthlyJobRepository(db)
+
+	staff := &models.Staff{Nama: "Staf Pajak", Email: "staf@example.com", PasswordHashed: "Rahasia-Lama-123", Role: "staff"}
+	if err := staffs.CreateStaff(ctx, staff); err != nil {
+		t.Fatalf("CreateStaff: %v", err)
+	}
+	client := &models.Client{ClientName: "=PT Rumus", NpwpClient: "01.234.567.8-901.000", NpwpCanonical: "012345678901000",
+		MembershipStatus: "Aktif", PicStaffSigmaID: staff.StaffID, ClientCategory: "Badan"}
+	if err := clients.CreateClient(ctx, client); err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	job := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 1, JobYear: 2025, AssignedPicStaffSigmaID: staff.StaffID,
+		OverallStatus: "Dikerjakan", TaxReports: []models.MonthlyTaxReport{{TaxType: models.TaxTypePpn}, {TaxType: models.TaxTypePph21}}}
+	if err := monthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_claims", &auth.Claims{StaffID: staff.StaffID, Role: "staff"}) })
+	r.GET("/monthly-jobs", NewMonthlyJobHandler(monthlyJobs, clients, staffs, nil, nil).GetAllMonthlyJobs)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/monthly-jobs?columns=client_name,tax_type&lang=en", nil))
+	// Accept tidak dikirim: tetap JSON
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("without format: status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/monthly-jobs?format=csv&columns=client_name,tax_type&lang=en", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != export.FormatCSV.ContentType() ||
+		!strings.HasPrefix(w.Header().Get("Content-Disposition"), `attachment; filename="pekerjaan-bulanan-`) {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+	got, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	want := [][]string{{"Client Name", "Tax Type"}, {"'=PT Rumus", models.TaxTypePph21}, {"'=PT Rumus", models.TaxTypePpn}} // urut jenis pajak
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
+
+// Query yang gagal sebelum ada byte terkirim masih dijawab dengan error JSON, bukan file kosong.
+func TestWriteExportReportsEarlyError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/clients", func(c *gin.Context) {
+		failing := func(yield func(*models.Client) error) error { return errors.New("connection refused") }
+		writeExport(c, export.FormatXLSX, "klien", "Klien", export.ClientColumns, failing)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/clients", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Disposition") != "" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || !strings.Contains(w.Body.String(), "connection refused") {
+		t.Errorf("status %d, headers %v, body %s; want a 500 JSON error", w.Code, w.Header(), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/clients?columns=coretax_password_hashed", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown column: status %d, want 400", w.Code)
+	}
+}
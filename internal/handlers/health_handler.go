@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// readinessCheckTimeout membatasi setiap pemeriksaan readiness, agar probe tidak menggantung
+// saat database lambat.
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthHandler handles the liveness and readiness probes
+type HealthHandler struct {
+	HealthRepo repositories.HealthRepository
+	UploadDir  string
+	draining   atomic.Bool
+}
+
+// NewHealthHandler creates a new HealthHandler. uploadDir is the local storage that must be writable.
+func NewHealthHandler(repo repositories.HealthRepository, uploadDir string) *HealthHandler {
+	return &HealthHandler{HealthRepo: repo, UploadDir: uploadDir}
+}
+
+// SetDraining makes /readyz fail from now on, so the load balancer stops sending new
+// requests while the server drains the ones in flight.
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Liveness reports that the process is up. It does not touch dependencies, so a slow
+// database never gets the container restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether the instance can serve traffic: the database answers, the
+// upload storage is writable and the schema from init.sql is complete.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	checks := gin.H{}
+	ready := true
+	run := func(name string, check func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+		defer cancel()
+		if err := check(ctx); err != nil {
+			ready = false
+			checks[name] = err.Error()
+			logger.FromContext(c.Request.Context()).Warn("readiness check failed", "check", name, "error", err.Error())
+			return
+		}
+		checks[name] = "ok"
+	}
+
+	run("database", h.HealthRepo.Ping)
+	run("storage", func(ctx context.Context) error { return checkWritable(h.UploadDir) })
+	run("migrations", func(ctx context.Context) error {
+		missing, err := h.HealthRepo.GetMissingTables(ctx, repositories.SchemaTables)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
+
+// checkWritable membuat lalu menghapus file sementara di dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("upload directory is not writable: %w", err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
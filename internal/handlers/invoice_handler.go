@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"database/sql"
 	"net/http"
+	"strings"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth" // Sesuaikan dengan lokasi package auth Anda
 )
 
@@ -27,10 +33,12 @@ func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
 
 	// Di sini Anda bisa menambahkan validasi lebih lanjut jika perlu
 
-	if err := h.repo.CreateInvoice(&invoice); err != nil {
+	if err := h.repo.CreateInvoice(c.Request.Context(), &invoice); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invoice: " + err.Error()})
 		return
 	}
+	// invoice.created dan invoice.paid dikirim subscriber webhook dari event InvoiceIssued dan
+	// InvoicePaid yang ditulis ke outbox bersama invoice-nya
 
 	c.JSON(http.StatusCreated, invoice)
 }
@@ -45,7 +53,18 @@ func (h *InvoiceHandler) GetAllInvoices(c *gin.Context) {
 
 	userClaims := claims.(*auth.Claims) // Lakukan type assertion ke struct Claims Anda
 
-	invoices, err := h.repo.GetAllInvoices(userClaims.StaffID, userClaims.IsAdmin)
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		invoices := func(yield func(*models.Invoice) error) error {
+			return h.repo.EachInvoice(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "invoice", "Invoice", export.InvoiceColumns, export.Flatten(invoices, export.InvoiceRows))
+		return
+	}
+	invoices, err := h.repo.GetAllInvoices(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invoices: " + err.Error()})
 		return
@@ -66,11 +85,99 @@ func (h *InvoiceHandler) GetInvoiceByID(c *gin.Context) {
 
 	userClaims := claims.(*auth.Claims)
 
-	invoice, err := h.repo.GetInvoiceByID(invoiceID, userClaims.StaffID, userClaims.IsAdmin)
+	invoice, err := h.repo.GetInvoiceByID(c.Request.Context(), invoiceID, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invoice by ID: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoicePDF mengunduh invoice sebagai PDF.
+func (h *InvoiceHandler) GetInvoicePDF(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found"})
+		return
+	}
+
+	userClaims := claims.(*auth.Claims)
+
+	invoice, err := h.repo.GetInvoiceByID(c.Request.Context(), c.Param("id"), userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invoice by ID: " + err.Error()})
+		return
+	}
+	writeInvoicePDF(c, invoice)
+}
+
+// writeInvoicePDF renders the invoice and sends it as a download named after its number.
+func writeInvoicePDF(c *gin.Context, invoice *models.Invoice) {
+	var buf bytes.Buffer
+	if err := services.WriteInvoicePDF(&buf, invoice); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invoice PDF: " + err.Error()})
+		return
+	}
+	// Nomor invoice bisa berisi "/", jadi hanya karakter aman yang dipakai di nama file
+	fileName := strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return r
+		}
+		return '-'
+	}, invoice.InvoiceNumber)
+	c.Header("Content-Disposition", `attachment; filename="invoice-`+fileName+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// UpdateInvoiceStatus mengubah status invoice, mis. menandai lunas. Perubahan ke Paid mencatat
+// event InvoicePaid di outbox, yang diteruskan sebagai webhook invoice.paid.
+func (h *InvoiceHandler) UpdateInvoiceStatus(c *gin.Context) {
+	invoiceID := c.Param("id")
+
+	var req models.UpdateInvoiceStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found"})
+		return
+	}
+
+	userClaims := claims.(*auth.Claims)
+
+	invoice, err := h.repo.GetInvoiceByID(c.Request.Context(), invoiceID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invoice by ID: " + err.Error()})
 		return
 	}
 
+	previousStatus := invoice.Status
+	invoice.Status = req.Status
+	var events []models.DomainEvent
+	if invoice.Status == models.InvoiceStatusPaid && previousStatus != models.InvoiceStatusPaid {
+		paid, err := models.NewDomainEvent(models.DomainEventInvoicePaid, models.AggregateInvoice, invoice.InvoiceID, actorStaffID(c), invoice)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		events = append(events, paid)
+	}
+	if err := h.repo.UpdateInvoice(c.Request.Context(), invoice, events...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update invoice status: " + err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, invoice)
-}
\ No newline at end of file
+}
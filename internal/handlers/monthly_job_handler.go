@@ -4,16 +4,16 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	// For time.Parse and time.Time pointers
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
 )
 
 // MonthlyJobHandler handles HTTP requests for monthly job operations
@@ -22,14 +22,17 @@ type MonthlyJobHandler struct {
 	ClientRepo     repositories.ClientRepository // Need client repo to validate client_id
 	StaffRepo      repositories.StaffRepository
 	InvoiceService services.InvoiceService
+	DocumentService services.DocumentService
 }
 
 // NewMonthlyJobHandler creates a new MonthlyJobHandler
-func NewMonthlyJobHandler(mjRepo repositories.MonthlyJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService,) *MonthlyJobHandler {
+func NewMonthlyJobHandler(mjRepo repositories.MonthlyJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService, docService services.DocumentService) *MonthlyJobHandler {
 	return &MonthlyJobHandler{
 		MonthlyJobRepo: mjRepo,
 		ClientRepo:     cRepo,
+		StaffRepo:      sRepo,
 		InvoiceService: invService,
+		DocumentService: docService,
 	}
 }
 
@@ -53,7 +56,7 @@ func (h *MonthlyJobHandler) CreateMonthlyJob(c *gin.Context) {
 	}
 
 	// Basic validation: Check if client_id exists
-	client, err := h.ClientRepo.GetClientByID(req.ClientID, userClaims.StaffID, userClaims.IsAdmin)
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), req.ClientID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate client ID"})
 		return
@@ -73,17 +76,48 @@ func (h *MonthlyJobHandler) CreateMonthlyJob(c *gin.Context) {
 
 	// Convert NewMonthlyTaxReportRequest to MonthlyTaxReport for the repository
 	for _, trReq := range req.TaxReports {
-		monthlyJob.TaxReports = append(monthlyJob.TaxReports, models.MonthlyTaxReport{
+		report := models.MonthlyTaxReport{
 			TaxType:       trReq.TaxType,
 			BillingCode:   trReq.BillingCode,
 			PaymentDate:   trReq.PaymentDate,
 			PaymentAmount: trReq.PaymentAmount,
 			ReportStatus:  trReq.ReportStatus,
 			ReportDate:    trReq.ReportDate,
-		})
+			TaxPaymentEvidence: models.TaxPaymentEvidence{
+				BillingCodeExpiresAt: trReq.BillingCodeExpiresAt,
+				BillingAmount:        trReq.BillingAmount,
+				Ntpn:                 trReq.Ntpn,
+				PaymentChannel:       trReq.PaymentChannel,
+			},
+		}
+		if err := applyTaxPaymentRules(&report.BillingCode, &report.TaxPaymentEvidence, report.PaymentDate, report.ReportStatus, newReportIdentifiers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence for tax type " + trReq.TaxType + ": " + err.Error()})
+			return
+		}
+		monthlyJob.TaxReports = append(monthlyJob.TaxReports, report)
+	}
+
+	// Job ID dibuat di sini agar event ikut tersimpan dalam transaksi yang sama
+	jobID, err := utils.NewUUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate job ID: " + err.Error()})
+		return
+	}
+	monthlyJob.JobID = jobID
+	events, err := jobCreatedEvents(c, models.JobAssignment{
+		JobType:    models.JobTypeMonthly,
+		JobID:      monthlyJob.JobID,
+		ClientID:   monthlyJob.ClientID,
+		ClientName: client.ClientName,
+		Period:     monthlyJob.PeriodLabel(),
+		StaffID:    monthlyJob.AssignedPicStaffSigmaID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
 	}
 
-	if err := h.MonthlyJobRepo.CreateMonthlyJob(monthlyJob); err != nil {
+	if err := h.MonthlyJobRepo.CreateMonthlyJob(c.Request.Context(), monthlyJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create monthly job: " + err.Error()})
 		return
 	}
@@ -106,7 +140,18 @@ claims, exists := c.Get("user_claims")
 		return
 	}
 
-	jobs, err := h.MonthlyJobRepo.GetAllMonthlyJobs(userClaims.StaffID, userClaims.IsAdmin) // <-- TERUSKAN PARAMETER FILTER
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		jobs := func(yield func(*models.MonthlyJob) error) error {
+			return h.MonthlyJobRepo.EachMonthlyJob(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "pekerjaan-bulanan", "Pekerjaan Bulanan", export.MonthlyJobColumns, export.Flatten(jobs, export.MonthlyJobRows))
+		return
+	}
+	jobs, err := h.MonthlyJobRepo.GetAllMonthlyJobs(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin) // <-- TERUSKAN PARAMETER FILTER
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monthly jobs: " + err.Error()})
 		return
@@ -129,7 +174,7 @@ claims, exists := c.Get("user_claims")
 		return
 	}
 
-	job, err := h.MonthlyJobRepo.GetMonthlyJobByID(id, userClaims.StaffID, userClaims.IsAdmin) // <-- TERUSKAN PARAMETER FILTER
+	job, err := h.MonthlyJobRepo.GetMonthlyJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin) // <-- TERUSKAN PARAMETER FILTER
 	if err != nil {
 		if err == sql.ErrNoRows { // Ini akan menangani not found (baik karena ID salah atau tidak punya akses)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly job not found or access denied"})
@@ -160,7 +205,7 @@ func (h *MonthlyJobHandler) UpdateMonthlyJob(c *gin.Context) {
 		return
 	}
 
-	existingJob, err := h.MonthlyJobRepo.GetMonthlyJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	existingJob, err := h.MonthlyJobRepo.GetMonthlyJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly job not found"})
@@ -211,12 +256,7 @@ func (h *MonthlyJobHandler) UpdateMonthlyJob(c *gin.Context) {
 	if fileReceived {
 		// Buat nama file unik (misalnya JobID.pdf)
 		filename := fmt.Sprintf("%s.pdf", existingJob.JobID)
-		filePath := filepath.Join("uploads", filename) // Simpan di folder 'uploads'
-
-		// Pastikan folder 'uploads' ada
-		if _, err := os.Stat("uploads"); os.IsNotExist(err) {
-			os.Mkdir("uploads", os.ModePerm)
-		}
+		filePath := h.DocumentService.UploadPath(filename) // Simpan di folder upload (storage.upload_dir)
 
 		// Simpan file
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
@@ -230,12 +270,14 @@ func (h *MonthlyJobHandler) UpdateMonthlyJob(c *gin.Context) {
 	}
 
 	// --- TERAPKAN UPDATE KE existingJob BERDASARKAN FORM FIELD ---
+	previousPic := existingJob.AssignedPicStaffSigmaID
+	previousStatus := existingJob.OverallStatus
 	if overallStatusForm != "" {
 		existingJob.OverallStatus = overallStatusForm
 	}
 	if assignedPicStaffSigmaIDForm != "" {
 		// Validasi PIC Staff ID baru jika disediakan
-		staff, err := h.StaffRepo.GetStaffByID(assignedPicStaffSigmaIDForm)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), assignedPicStaffSigmaIDForm)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate new Assigned PIC Staff ID: " + err.Error()})
 			return
@@ -268,10 +310,28 @@ func (h *MonthlyJobHandler) UpdateMonthlyJob(c *gin.Context) {
 		existingJob.ProofOfWorkURL = uploadedFilePath
 	}
 
-	if err := h.MonthlyJobRepo.UpdateMonthlyJob(existingJob); err != nil {
+	events, err := jobUpdatedEvents(c, models.JobStatusChange{
+		JobType:    models.JobTypeMonthly,
+		JobID:      existingJob.JobID,
+		ClientID:   existingJob.ClientID,
+		ClientName: existingJob.ClientName,
+		Period:     existingJob.PeriodLabel(),
+		OldStatus:  previousStatus,
+		NewStatus:  existingJob.OverallStatus,
+		StaffID:    existingJob.AssignedPicStaffSigmaID,
+	}, previousPic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
+	}
+
+	if err := h.MonthlyJobRepo.UpdateMonthlyJob(c.Request.Context(), existingJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monthly job: " + err.Error()})
 		return
 	}
+	if uploadedFilePath != nil {
+		registerUploadedDocument(c, h.DocumentService, models.JobTypeMonthly, existingJob.JobID, existingJob.ClientID, *uploadedFilePath)
+	}
 	c.JSON(http.StatusOK, existingJob)
 }
 
@@ -296,7 +356,7 @@ func (h *MonthlyJobHandler) CreateMonthlyTaxReport(c *gin.Context) {
 	}
 
 	// Optional: Validate if jobID exists before adding tax report
-	_, err := h.MonthlyJobRepo.GetMonthlyJobByID(jobID, userClaims.StaffID, userClaims.IsAdmin)
+	_, err := h.MonthlyJobRepo.GetMonthlyJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly job not found for tax report"})
@@ -314,9 +374,19 @@ func (h *MonthlyJobHandler) CreateMonthlyTaxReport(c *gin.Context) {
 		PaymentAmount: req.PaymentAmount,
 		ReportStatus:  req.ReportStatus,
 		ReportDate:    req.ReportDate,
+		TaxPaymentEvidence: models.TaxPaymentEvidence{
+			BillingCodeExpiresAt: req.BillingCodeExpiresAt,
+			BillingAmount:        req.BillingAmount,
+			Ntpn:                 req.Ntpn,
+			PaymentChannel:       req.PaymentChannel,
+		},
+	}
+	if err := applyTaxPaymentRules(&taxReport.BillingCode, &taxReport.TaxPaymentEvidence, taxReport.PaymentDate, taxReport.ReportStatus, newReportIdentifiers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence: " + err.Error()})
+		return
 	}
 
-	if err := h.MonthlyJobRepo.CreateMonthlyTaxReport(taxReport); err != nil {
+	if err := h.MonthlyJobRepo.CreateMonthlyTaxReport(c.Request.Context(), taxReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create monthly tax report: " + err.Error()})
 		return
 	}
@@ -348,7 +418,7 @@ func (h *MonthlyJobHandler) UpdateMonthlyTaxReport(c *gin.Context) {
 	// This part needs `GetMonthlyTaxReportByID` in the repository for proper partial update.
 	// For simplicity, we'll assume the request provides all fields for the update.
 	// Better approach for PATCH:
-    // existingReport, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(reportID) // This function is not yet in repo
+    // existingReport, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(c.Request.Context(), reportID) // This function is not yet in repo
     // if err != nil { ... handle not found ... }
     // if req.TaxType != nil { existingReport.TaxType = *req.TaxType }
     // ... apply other field updates ...
@@ -384,7 +454,7 @@ func (h *MonthlyJobHandler) UpdateMonthlyTaxReport(c *gin.Context) {
     // Given UpdateMonthlyTaxReportRequest uses pointers, we should fetch first.
 
     // **Revised UpdateMonthlyTaxReport handler to be robust with PATCH:**
-    existingReport, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(reportID) // Assumes this function exists now
+    existingReport, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(c.Request.Context(), reportID) // Assumes this function exists now
     if err != nil {
         if err == sql.ErrNoRows {
             c.JSON(http.StatusNotFound, gin.H{"error": "Tax report not found"})
@@ -417,8 +487,25 @@ func (h *MonthlyJobHandler) UpdateMonthlyTaxReport(c *gin.Context) {
     if req.ReportDate != nil {
         existingReport.ReportDate = req.ReportDate
     }
+	if req.BillingCodeExpiresAt != nil {
+		existingReport.BillingCodeExpiresAt = req.BillingCodeExpiresAt
+	}
+	if req.BillingAmount != nil {
+		existingReport.BillingAmount = req.BillingAmount
+	}
+	if req.Ntpn != nil {
+		existingReport.Ntpn = *req.Ntpn
+	}
+	if req.PaymentChannel != nil {
+		existingReport.PaymentChannel = *req.PaymentChannel
+	}
+	if err := applyTaxPaymentRules(&existingReport.BillingCode, &existingReport.TaxPaymentEvidence, existingReport.PaymentDate, existingReport.ReportStatus,
+		paymentIdentifiers{BillingCode: req.BillingCode != nil, Ntpn: req.Ntpn != nil}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment evidence: " + err.Error()})
+		return
+	}
 
-	if err := h.MonthlyJobRepo.UpdateMonthlyTaxReport(existingReport); err != nil {
+	if err := h.MonthlyJobRepo.UpdateMonthlyTaxReport(c.Request.Context(), existingReport); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monthly tax report: " + err.Error()})
 		return
 	}
@@ -430,7 +517,7 @@ func (h *MonthlyJobHandler) DeleteMonthlyTaxReport(c *gin.Context) {
 	reportID := c.Param("report_id")
 
 	// Optional: Check if report exists before deleting
-	_, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(reportID) // Assumes this function exists now
+	_, err := h.MonthlyJobRepo.GetMonthlyTaxReportByID(c.Request.Context(), reportID) // Assumes this function exists now
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Tax report not found"})
@@ -440,7 +527,7 @@ func (h *MonthlyJobHandler) DeleteMonthlyTaxReport(c *gin.Context) {
 		return
 	}
 
-	if err := h.MonthlyJobRepo.DeleteMonthlyTaxReport(reportID); err != nil {
+	if err := h.MonthlyJobRepo.DeleteMonthlyTaxReport(c.Request.Context(), reportID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete monthly tax report: " + err.Error()})
 		return
 	}
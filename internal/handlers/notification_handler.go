@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// NotificationHandler handles HTTP requests for notification preferences, the in-app inbox
+// and the live event stream
+type NotificationHandler struct {
+	NotificationRepo    repositories.NotificationRepository
+	NotificationService services.NotificationService
+	RealtimeService     services.RealtimeService
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(nRepo repositories.NotificationRepository, nService services.NotificationService, rtService services.RealtimeService) *NotificationHandler {
+	return &NotificationHandler{
+		NotificationRepo:    nRepo,
+		NotificationService: nService,
+		RealtimeService:     rtService,
+	}
+}
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+	streamKeepAlive   = 25 * time.Second
+)
+
+// GetMyPreferences returns the notification preferences of the logged-in staff
+func (h *NotificationHandler) GetMyPreferences(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	prefs, err := h.NotificationRepo.GetPreferences(c.Request.Context(), userClaims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateMyPreferences handles partial updates to the logged-in staff's notification preferences
+func (h *NotificationHandler) UpdateMyPreferences(c *gin.Context) {
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	prefs, err := h.NotificationRepo.GetPreferences(c.Request.Context(), userClaims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification preferences: " + err.Error()})
+		return
+	}
+	if req.JobAssigned != nil {
+		prefs.JobAssigned = *req.JobAssigned
+	}
+	if req.InvoiceOverdue != nil {
+		prefs.InvoiceOverdue = *req.InvoiceOverdue
+	}
+	if req.ReportDue != nil {
+		prefs.ReportDue = *req.ReportDue
+	}
+	if req.DailyDigest != nil {
+		prefs.DailyDigest = *req.DailyDigest
+	}
+
+	if err := h.NotificationRepo.UpsertPreferences(c.Request.Context(), prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// RunDaily sends today's scheduled emails immediately (admin only). Emails already sent today are skipped.
+func (h *NotificationHandler) RunDaily(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+	if !userClaims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can run scheduled notifications"})
+		return
+	}
+
+	if err := h.NotificationService.RunDaily(c.Request.Context(), time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run scheduled notifications: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled notifications processed"})
+}
+
+// GetMyNotifications returns the logged-in staff's inbox, newest first.
+// Query params: unread=true, limit (default 20, max 100), before (RFC3339 created_at cursor)
+func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	limit := defaultInboxLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 || n > maxInboxLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+	var before *time.Time
+	if beforeParam := c.Query("before"); beforeParam != "" {
+		t, err := time.Parse(time.RFC3339Nano, beforeParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before format, use RFC3339"})
+			return
+		}
+		before = &t
+	}
+
+	notifications, err := h.NotificationRepo.GetNotifications(c.Request.Context(), userClaims.StaffID, c.Query("unread") == "true", limit, before)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications: " + err.Error()})
+		return
+	}
+	unread, err := h.NotificationRepo.CountUnreadNotifications(c.Request.Context(), userClaims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.NotificationInbox{UnreadCount: unread, Notifications: notifications})
+}
+
+// MarkNotificationRead marks one of the logged-in staff's notifications as read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	id := c.Param("id")
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	notification, err := h.NotificationRepo.MarkNotificationRead(c.Request.Context(), id, userClaims.StaffID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllNotificationsRead marks every unread notification of the logged-in staff as read
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	updated, err := h.NotificationRepo.MarkAllNotificationsRead(c.Request.Context(), userClaims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": updated})
+}
+
+// StreamNotifications is a Server-Sent Events stream of new notifications ("notification")
+// and job status changes ("job_status") for the logged-in staff. Browsers' EventSource
+// cannot send headers, so the token may also be passed as ?access_token=.
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	unread, err := h.NotificationRepo.CountUnreadNotifications(c.Request.Context(), userClaims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications: " + err.Error()})
+		return
+	}
+
+	sub := h.RealtimeService.Subscribe(userClaims.StaffID, userClaims.IsAdmin)
+	defer h.RealtimeService.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Matikan buffering di reverse proxy nginx
+	// Stream hidup lebih lama dari WriteTimeout server; hapus deadline untuk koneksi ini saja
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("failed to clear write deadline for stream", "error", err.Error())
+	}
+	c.SSEvent("ready", gin.H{"unread_count": unread})
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			switch event.Type {
+			case models.RealtimeEventNotification:
+				c.SSEvent(event.Type, event.Notification)
+			case models.RealtimeEventJobStatus:
+				c.SSEvent(event.Type, event.JobStatus)
+			}
+			return true
+		case <-keepAlive.C:
+			// Komentar SSE menjaga koneksi tetap hidup melewati proxy
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/openapi"
+)
+
+// Swagger UI di-embed (lihat swaggerui/README.md), jadi halaman dokumentasi tidak memuat
+// script dari luar.
+//
+//go:embed swaggerui/index.html swaggerui/init.js swaggerui/swagger-ui-bundle.js swaggerui/swagger-ui.css
+var swaggerUIFS embed.FS
+
+// swaggerUIAssets adalah file yang boleh diminta lewat /api/v1/docs/:asset beserta content type-nya.
+var swaggerUIAssets = map[string]string{
+	"init.js":              "text/javascript; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+	"swagger-ui.css":       "text/css; charset=utf-8",
+}
+
+// swaggerUICSP melonggarkan CSP bawaan SecurityHeaders untuk halaman Swagger UI: script dan
+// stylesheet hanya dari origin sendiri, style inline yang dipasang Swagger UI, gambar data:
+// untuk ikon, dan fetch ke openapi.json di origin sendiri.
+const swaggerUICSP = "default-src 'none'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+// OpenAPIHandler serves the OpenAPI document of the API and a Swagger UI to browse it
+type OpenAPIHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHandler creates a new OpenAPIHandler. The document is encoded once, so doc must
+// be complete.
+func NewOpenAPIHandler(doc *openapi.Document) (*OpenAPIHandler, error) {
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIHandler{spec: spec}, nil
+}
+
+// GetSpec returns the OpenAPI document as JSON
+func (h *OpenAPIHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// SwaggerUI returns the documentation page
+func (h *OpenAPIHandler) SwaggerUI(c *gin.Context) {
+	h.serveAsset(c, "swaggerui/index.html", "text/html; charset=utf-8")
+}
+
+// SwaggerUIAsset returns a script or stylesheet of the documentation page
+func (h *OpenAPIHandler) SwaggerUIAsset(c *gin.Context) {
+	name := c.Param("asset")
+	contentType, ok := swaggerUIAssets[name]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	h.serveAsset(c, "swaggerui/"+name, contentType)
+}
+
+func (h *OpenAPIHandler) serveAsset(c *gin.Context, name, contentType string) {
+	data, err := swaggerUIFS.ReadFile(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load documentation page: " + err.Error()})
+		return
+	}
+	c.Header("Content-Security-Policy", swaggerUICSP)
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -3,16 +3,16 @@ package handlers
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
-	"path/filepath"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"       // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories" // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
 )
 
 // PemeriksaanJobHandler handles HTTP requests for Pemeriksaan job operations
@@ -21,15 +21,17 @@ type PemeriksaanJobHandler struct {
 	ClientRepo         repositories.ClientRepository
 	StaffRepo          repositories.StaffRepository
 	InvoiceService 		 services.InvoiceService
+	DocumentService    services.DocumentService
 }
 
 // NewPemeriksaanJobHandler creates a new PemeriksaanJobHandler
-func NewPemeriksaanJobHandler(pjRepo repositories.PemeriksaanJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService) *PemeriksaanJobHandler {
+func NewPemeriksaanJobHandler(pjRepo repositories.PemeriksaanJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService, docService services.DocumentService) *PemeriksaanJobHandler {
 	return &PemeriksaanJobHandler{
 		PemeriksaanJobRepo: pjRepo,
 		ClientRepo:         cRepo,
 		StaffRepo:          sRepo,
 		InvoiceService: invService,
+		DocumentService: docService,
 	}
 }
 
@@ -42,7 +44,7 @@ func (h *PemeriksaanJobHandler) CreatePemeriksaanJob(c *gin.Context) {
 	}
 
 	// Validate client_id existence
-	client, err := h.ClientRepo.GetClientByID(req.ClientID, "", true) // Admin check for client existence
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), req.ClientID, "", true) // Admin check for client existence
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate client ID: " + err.Error()})
 		return
@@ -54,7 +56,7 @@ func (h *PemeriksaanJobHandler) CreatePemeriksaanJob(c *gin.Context) {
 
 	// Validate assigned_pic_staff_sigma_id existence
 	if req.AssignedPicStaffSigmaID != "" {
-		staff, err := h.StaffRepo.GetStaffByID(req.AssignedPicStaffSigmaID)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), req.AssignedPicStaffSigmaID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate Assigned PIC Staff ID: " + err.Error()})
 			return
@@ -77,7 +79,27 @@ func (h *PemeriksaanJobHandler) CreatePemeriksaanJob(c *gin.Context) {
 		OverallStatus:         req.OverallStatus,
 	}
 
-	if err := h.PemeriksaanJobRepo.CreatePemeriksaanJob(pemeriksaanJob); err != nil {
+	// Job ID dibuat di sini agar event ikut tersimpan dalam transaksi yang sama
+	jobID, err := utils.NewUUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate job ID: " + err.Error()})
+		return
+	}
+	pemeriksaanJob.JobID = jobID
+	events, err := jobCreatedEvents(c, models.JobAssignment{
+		JobType:    models.JobTypePemeriksaan,
+		JobID:      pemeriksaanJob.JobID,
+		ClientID:   pemeriksaanJob.ClientID,
+		ClientName: client.ClientName,
+		Period:     pemeriksaanJob.PeriodLabel(),
+		StaffID:    pemeriksaanJob.AssignedPicStaffSigmaID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
+	}
+
+	if err := h.PemeriksaanJobRepo.CreatePemeriksaanJob(c.Request.Context(), pemeriksaanJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Pemeriksaan job: " + err.Error()})
 		return
 	}
@@ -98,7 +120,18 @@ claims, exists := c.Get("user_claims")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
 		return
 	}
-	jobs, err := h.PemeriksaanJobRepo.GetAllPemeriksaanJobs(userClaims.StaffID, userClaims.IsAdmin)
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		jobs := func(yield func(*models.PemeriksaanJob) error) error {
+			return h.PemeriksaanJobRepo.EachPemeriksaanJob(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "pekerjaan-pemeriksaan", "Pemeriksaan", export.PemeriksaanJobColumns, jobs)
+		return
+	}
+	jobs, err := h.PemeriksaanJobRepo.GetAllPemeriksaanJobs(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Pemeriksaan jobs: " + err.Error()})
 		return
@@ -121,7 +154,7 @@ func (h *PemeriksaanJobHandler) GetPemeriksaanJobByID(c *gin.Context) {
 		return
 	}
 
-	job, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	job, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Pemeriksaan job not found or access denied"})
@@ -156,7 +189,7 @@ func (h *PemeriksaanJobHandler) UpdatePemeriksaanJob(c *gin.Context) {
 	}
 
 	// 1. Ambil data pekerjaan yang ada dari database
-	existingJob, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	existingJob, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Pemeriksaan job not found"})
@@ -178,6 +211,8 @@ func (h *PemeriksaanJobHandler) UpdatePemeriksaanJob(c *gin.Context) {
 	skpDateForm := c.PostForm("skp_date")
 
 	// 3. Terapkan semua perubahan dari form ke objek 'existingJob'
+	previousPic := existingJob.AssignedPicStaffSigmaID
+	previousStatus := existingJob.OverallStatus
 	// Terapkan status baru ke field yang sudah distandarisasi
 	if overallStatusForm != "" {
 		existingJob.OverallStatus = overallStatusForm // <-- Gunakan field standar
@@ -185,7 +220,7 @@ func (h *PemeriksaanJobHandler) UpdatePemeriksaanJob(c *gin.Context) {
 
 	// Terapkan update untuk field lain...
 	if assignedPicStaffSigmaIDForm != "" {
-		staff, err := h.StaffRepo.GetStaffByID(assignedPicStaffSigmaIDForm)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), assignedPicStaffSigmaIDForm)
 		if err != nil || staff == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "New Assigned PIC Staff ID not found"})
 			return
@@ -211,38 +246,45 @@ func (h *PemeriksaanJobHandler) UpdatePemeriksaanJob(c *gin.Context) {
 	}
 	
 	// 4. Proses file upload (jika ada)
+	var uploadedFilePath *string
 	file, err := c.FormFile("proof_of_work_pdf")
 	if err == nil {
 		filename := fmt.Sprintf("%s.pdf", existingJob.JobID)
-		filePath := filepath.Join("uploads", filename)
+		filePath := h.DocumentService.UploadPath(filename)
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save proof of work PDF: " + err.Error()})
 			return
 		}
 		url := fmt.Sprintf("/uploads/%s", filename)
 		existingJob.ProofOfWorkURL = &url
+		uploadedFilePath = &url
 	}
 
-	// 5. Panggil pemicu invoice JIKA status diubah menjadi "Selesai"
-	// Gunakan field dan variabel yang sudah distandarisasi
-	if overallStatusForm == "Selesai" && existingJob.OverallStatus != "Selesai" {
-		log.Printf("INFO: Status pekerjaan %s diubah menjadi Selesai. Memicu pembuatan invoice...", existingJob.JobID)
-		_, err := h.InvoiceService.CreateInvoiceFromJob(
-			existingJob.JobID, "Pemeriksaan", existingJob.ClientID, existingJob.AssignedPicStaffSigmaID,
-		)
-		if err != nil {
-			log.Printf("PERINGATAN: Gagal membuat invoice otomatis untuk pekerjaan %s: %v", existingJob.JobID, err)
-		} else {
-			log.Printf("INFO: Invoice berhasil dibuat untuk pekerjaan %s.", existingJob.JobID)
-		}
+	// 5. Susun domain event. Invoice untuk pekerjaan yang Selesai dibuat oleh subscriber
+	// JobCompleted setelah perubahan ini tersimpan, bukan sebelumnya.
+	events, err := jobUpdatedEvents(c, models.JobStatusChange{
+		JobType:    models.JobTypePemeriksaan,
+		JobID:      existingJob.JobID,
+		ClientID:   existingJob.ClientID,
+		ClientName: existingJob.ClientName,
+		Period:     existingJob.PeriodLabel(),
+		OldStatus:  previousStatus,
+		NewStatus:  existingJob.OverallStatus,
+		StaffID:    existingJob.AssignedPicStaffSigmaID,
+	}, previousPic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
 	}
-	
-	// 6. Simpan SEMUA perubahan ke database
-	log.Printf("DEBUG: Menyimpan final -> JobID: %s, Status: '%s'", existingJob.JobID, existingJob.OverallStatus)
-	if err := h.PemeriksaanJobRepo.UpdatePemeriksaanJob(existingJob); err != nil {
+
+	// 6. Simpan SEMUA perubahan ke database, beserta event-nya dalam satu transaksi
+	if err := h.PemeriksaanJobRepo.UpdatePemeriksaanJob(c.Request.Context(), existingJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update Pemeriksaan job: " + err.Error()})
 		return
 	}
+	if uploadedFilePath != nil {
+		registerUploadedDocument(c, h.DocumentService, models.JobTypePemeriksaan, existingJob.JobID, existingJob.ClientID, *uploadedFilePath)
+	}
 	
 	c.JSON(http.StatusOK, existingJob)
 }
@@ -262,7 +304,7 @@ claims, exists := c.Get("user_claims")
 		return
 	}
 
-	existingJob, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
+	existingJob, err := h.PemeriksaanJobRepo.GetPemeriksaanJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Pemeriksaan job not found"})
@@ -276,7 +318,7 @@ claims, exists := c.Get("user_claims")
 		return
 	}
 
-	if err := h.PemeriksaanJobRepo.DeletePemeriksaanJob(id); err != nil {
+	if err := h.PemeriksaanJobRepo.DeletePemeriksaanJob(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete Pemeriksaan job: " + err.Error()})
 		return
 	}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+const (
+	// maxPortalUploadSize membatasi ukuran file yang diupload klien lewat portal
+	maxPortalUploadSize = 20 << 20
+)
+
+// portalUploadExtensions adalah jenis file yang boleh diupload klien untuk permintaan dokumen
+var portalUploadExtensions = map[string]bool{
+	".pdf": true, ".xlsx": true, ".xls": true, ".csv": true, ".jpg": true, ".jpeg": true, ".png": true, ".zip": true,
+}
+
+// PortalHandler handles the client portal. It is read-only except for uploading requested
+// documents. Every endpoint except Login reads the client from client_claims, so a client
+// user can only ever see their own client's data.
+type PortalHandler struct {
+	ClientUserRepo      repositories.ClientUserRepository
+	PortalRepo          repositories.PortalRepository
+	InvoiceRepo         repositories.InvoiceRepository
+	DocumentRepo        repositories.DocumentRepository
+	DocumentRequestRepo repositories.DocumentRequestRepository
+	DocumentService     services.DocumentService
+	JWTSecret           string
+	TokenTTL            time.Duration
+}
+
+// NewPortalHandler creates a new PortalHandler
+func NewPortalHandler(cuRepo repositories.ClientUserRepository, portalRepo repositories.PortalRepository, invRepo repositories.InvoiceRepository,
+	docRepo repositories.DocumentRepository, drRepo repositories.DocumentRequestRepository, docService services.DocumentService, jwtSecret string, tokenTTL time.Duration) *PortalHandler {
+	return &PortalHandler{
+		ClientUserRepo:      cuRepo,
+		PortalRepo:          portalRepo,
+		InvoiceRepo:         invRepo,
+		DocumentRepo:        docRepo,
+		DocumentRequestRepo: drRepo,
+		DocumentService:     docService,
+		JWTSecret:           jwtSecret,
+		TokenTTL:            tokenTTL,
+	}
+}
+
+// portalClaims reads the client user set by ClientPortalRequired.
+func portalClaims(c *gin.Context) (*auth.ClientClaims, bool) {
+	claims, exists := c.Get("client_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Client claims not found in context"})
+		return nil, false
+	}
+	clientClaims, ok := claims.(*auth.ClientClaims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse client claims"})
+		return nil, false
+	}
+	return clientClaims, true
+}
+
+// Login handles client user login and issues a token for the client-portal audience
+func (h *PortalHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.ClientUserRepo.GetClientUserByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
+		return
+	}
+	// Akun nonaktif dijawab sama seperti password salah
+	if user == nil || !user.IsActive || !utils.CheckPasswordHash(req.Password, user.PasswordHashed) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"client_user_id": user.ClientUserID,
+		"client_id":      user.ClientID,
+		"aud":            auth.AudienceClientPortal,
+		"exp":            time.Now().Add(h.TokenTTL).Unix(),
+	}
+	signedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.JWTSecret))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	if err := h.ClientUserRepo.UpdateLastLogin(c.Request.Context(), user.ClientUserID, time.Now()); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("Gagal mencatat login portal", "client_user_id", user.ClientUserID, "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": signedToken})
+}
+
+// GetMe returns the logged-in client user and their client's name
+func (h *PortalHandler) GetMe(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	user, err := h.ClientUserRepo.GetClientUserByID(c.Request.Context(), claims.ClientUserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve client user: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"client_user_id": user.ClientUserID,
+		"nama":           user.Nama,
+		"email":          user.Email,
+		"client_id":      user.ClientID,
+		"client_name":    user.ClientName,
+	})
+}
+
+// GetJobs lists the status of every job of the client
+func (h *PortalHandler) GetJobs(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	jobs, err := h.PortalRepo.GetJobs(c.Request.Context(), claims.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve jobs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetTaxPayments returns the tax reports and payments of a year (?year=, default this year)
+func (h *PortalHandler) GetTaxPayments(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	year := time.Now().Year()
+	if yearParam := c.Query("year"); yearParam != "" {
+		y, err := strconv.Atoi(yearParam)
+		if err != nil || y < 2000 || y > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = y
+	}
+
+	reports, err := h.PortalRepo.GetTaxReports(c.Request.Context(), claims.ClientID, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tax payments: " + err.Error()})
+		return
+	}
+	summary := models.PortalTaxPaymentSummary{Year: year, TotalReports: len(reports), Reports: reports}
+	for _, r := range reports {
+		if r.ReportDate != nil {
+			summary.ReportedCount++
+		}
+		if models.IsPaidReportStatus(r.ReportStatus) {
+			summary.PaidCount++
+			if r.PaymentAmount != nil {
+				summary.TotalPaid += *r.PaymentAmount
+			}
+		}
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetInvoices lists the client's invoices
+func (h *PortalHandler) GetInvoices(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	invoices, err := h.PortalRepo.GetInvoices(c.Request.Context(), claims.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invoices: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, invoices)
+}
+
+// clientInvoice fetches an invoice of the logged-in client. Invoices of other clients and
+// drafts are reported as not found.
+func (h *PortalHandler) clientInvoice(c *gin.Context) (*models.Invoice, bool) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return nil, false
+	}
+	invoice, err := h.InvoiceRepo.GetInvoiceByID(c.Request.Context(), c.Param("id"), "", true)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invoice: " + err.Error()})
+		return nil, false
+	}
+	if err == sql.ErrNoRows || invoice.ClientID != claims.ClientID || invoice.Status == models.InvoiceStatusDraft {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return nil, false
+	}
+	return invoice, true
+}
+
+// GetInvoiceByID returns one invoice with its line items
+func (h *PortalHandler) GetInvoiceByID(c *gin.Context) {
+	invoice, ok := h.clientInvoice(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.NewPortalInvoice(invoice))
+}
+
+// GetInvoicePDF downloads an invoice as PDF
+func (h *PortalHandler) GetInvoicePDF(c *gin.Context) {
+	invoice, ok := h.clientInvoice(c)
+	if !ok {
+		return
+	}
+	writeInvoicePDF(c, invoice)
+}
+
+// GetDocuments lists the client's proof-of-work documents
+func (h *PortalHandler) GetDocuments(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	docs, err := h.PortalRepo.GetDocuments(c.Request.Context(), claims.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve documents: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, docs)
+}
+
+// DownloadDocument sends a document file of the client as an attachment
+func (h *PortalHandler) DownloadDocument(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	doc, err := h.DocumentRepo.GetDocumentByID(c.Request.Context(), c.Param("id"), "", true)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document: " + err.Error()})
+		return
+	}
+	if err == sql.ErrNoRows || doc.ClientID != claims.ClientID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	path := h.DocumentService.LocalPath(doc.FileURL)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document file not found"})
+		return
+	}
+	c.FileAttachment(path, doc.FileName)
+}
+
+// GetDocumentRequests lists the documents staff asked the client for (?outstanding=true for
+// only those still to upload)
+func (h *PortalHandler) GetDocumentRequests(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	reqs, err := h.DocumentRequestRepo.GetDocumentRequests(c.Request.Context(), models.DocumentRequestFilter{
+		ClientID:        claims.ClientID,
+		OutstandingOnly: c.Query("outstanding") == "true",
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document requests: " + err.Error()})
+		return
+	}
+	result := make([]models.PortalDocumentRequest, 0, len(reqs))
+	for i := range reqs {
+		result = append(result, models.NewPortalDocumentRequest(&reqs[i]))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// UploadDocumentRequest uploads a file (multipart field "file") for a document request. The
+// file is registered as a document of the job and the request waits for staff review.
+func (h *PortalHandler) UploadDocumentRequest(c *gin.Context) {
+	claims, ok := portalClaims(c)
+	if !ok {
+		return
+	}
+	req, err := h.DocumentRequestRepo.GetDocumentRequestByID(c.Request.Context(), c.Param("id"))
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document request: " + err.Error()})
+		return
+	}
+	if err == sql.ErrNoRows || req.ClientID != claims.ClientID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document request not found"})
+		return
+	}
+	if req.Status == models.DocumentRequestStatusAccepted {
+		c.JSON(http.StatusConflict, gin.H{"error": "This document has already been accepted"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	if file.Size > maxPortalUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large, maximum is 20 MB"})
+		return
+	}
+	originalName := filepath.Base(file.Filename)
+	ext := strings.ToLower(filepath.Ext(originalName))
+	if !portalUploadExtensions[ext] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type, upload PDF, Excel, CSV, image or ZIP files"})
+		return
+	}
+
+	// Nama file unik per upload, agar upload ulang tidak menimpa file yang sedang diperiksa
+	now := time.Now()
+	filename := fmt.Sprintf("%s-%d%s", req.RequestID, now.Unix(), ext)
+	// Disimpan di folder upload portal yang tidak dilayani publik, bukan di /uploads
+	if err := c.SaveUploadedFile(file, h.DocumentService.PortalUploadPath(filename)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save uploaded file: " + err.Error()})
+		return
+	}
+
+	doc := &models.Document{
+		ClientID: req.ClientID,
+		JobType:  req.JobType,
+		JobID:    req.JobID,
+		FileName: originalName,
+		FileURL:  services.PortalFileURLPrefix + filename,
+	}
+	if err := h.DocumentService.RegisterUpload(c.Request.Context(), doc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register uploaded file: " + err.Error()})
+		return
+	}
+	if err := h.DocumentRequestRepo.AttachUpload(c.Request.Context(), req.RequestID, doc.DocumentID, claims.ClientUserID, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document request: " + err.Error()})
+		return
+	}
+
+	req.Status = models.DocumentRequestStatusUploaded
+	req.DocumentID = &doc.DocumentID
+	req.FileName = &originalName
+	req.UploadedAt = &now
+	req.ReviewNote = nil
+	c.JSON(http.StatusOK, models.NewPortalDocumentRequest(req))
+}
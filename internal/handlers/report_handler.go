@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+// ReportHandler handles HTTP requests for management reports
+type ReportHandler struct {
+	ReportRepo    repositories.ReportRepository
+	ReportService services.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler(reportRepo repositories.ReportRepository, reportService services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		ReportRepo:    reportRepo,
+		ReportService: reportService,
+	}
+}
+
+// GetWorkload returns open, overdue and completed job counts per PIC staff.
+// Query params: year, month (default: current month; year only = whole year),
+// job_type (repeatable or comma separated, e.g. monthly,sp2dk)
+func (h *ReportHandler) GetWorkload(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	start, end, err := parseReportPeriod(c.Query("year"), c.Query("month"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params := models.WorkloadParams{PeriodStart: start, PeriodEnd: end}
+
+	for _, value := range c.QueryArray("job_type") {
+		for _, part := range strings.Split(value, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			jobType, ok := models.ParseJobType(part)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job_type: " + part})
+				return
+			}
+			params.JobTypes = append(params.JobTypes, jobType)
+		}
+	}
+
+	report, err := h.ReportRepo.GetWorkload(c.Request.Context(), params, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build workload report: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetComplianceMatrix returns a client x month grid showing whether each subscribed
+// monthly tax was paid and reported. Query params: year (default: current year),
+// format=xlsx to download a color-coded spreadsheet instead of JSON
+func (h *ReportHandler) GetComplianceMatrix(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	year := time.Now().Year()
+	if yearParam := c.Query("year"); yearParam != "" {
+		y, err := strconv.Atoi(yearParam)
+		if err != nil || y < 2000 || y > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year format"})
+			return
+		}
+		year = y
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, use json or xlsx"})
+		return
+	}
+
+	matrix, err := h.ReportService.ComplianceMatrix(c.Request.Context(), year, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build compliance matrix: " + err.Error()})
+		return
+	}
+
+	if format == "xlsx" {
+		var buf bytes.Buffer
+		if err := h.ReportService.WriteComplianceMatrixXLSX(&buf, matrix); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export compliance matrix: " + err.Error()})
+			return
+		}
+		fileName := fmt.Sprintf("matriks-kepatuhan-%d.xlsx", year)
+		c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
+
+// GetRevenue returns billed and collected revenue by month, job type, client category
+// and PIC staff. Query params: year (default: current year), compare_year (default: year - 1)
+func (h *ReportHandler) GetRevenue(c *gin.Context) {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	year := time.Now().Year()
+	if yearParam := c.Query("year"); yearParam != "" {
+		y, err := strconv.Atoi(yearParam)
+		if err != nil || y < 2000 || y > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year format"})
+			return
+		}
+		year = y
+	}
+	compareYear := year - 1
+	if compareParam := c.Query("compare_year"); compareParam != "" {
+		y, err := strconv.Atoi(compareParam)
+		if err != nil || y < 2000 || y > 9999 || y == year {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid compare_year format"})
+			return
+		}
+		compareYear = y
+	}
+
+	report, err := h.ReportService.RevenueReport(c.Request.Context(), year, compareYear, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build revenue report: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseReportPeriod turns the year/month query params into a [start, end) range.
+// No params means the current month; a year without a month means the whole year.
+func parseReportPeriod(yearParam, monthParam string, now time.Time) (time.Time, time.Time, error) {
+	year, month := now.Year(), int(now.Month())
+	if yearParam != "" {
+		y, err := strconv.Atoi(yearParam)
+		if err != nil || y < 2000 || y > 9999 {
+			return time.Time{}, time.Time{}, errors.New("Invalid year format")
+		}
+		year = y
+		if monthParam == "" {
+			start := time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+			return start, start.AddDate(1, 0, 0), nil
+		}
+	}
+	if monthParam != "" {
+		m, err := strconv.Atoi(monthParam)
+		if err != nil || m < 1 || m > 12 {
+			return time.Time{}, time.Time{}, errors.New("Invalid month format")
+		}
+		month = m
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -3,16 +3,16 @@ package handlers
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
-	"path/filepath"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/export"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"       // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories" // Pastikan ini modul Anda
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
 )
 
 // Sp2dkJobHandler handles HTTP requests for SP2DK job operations
@@ -21,15 +21,17 @@ type Sp2dkJobHandler struct {
 	ClientRepo   repositories.ClientRepository
 	StaffRepo    repositories.StaffRepository
 	InvoiceService services.InvoiceService
+	DocumentService services.DocumentService
 }
 
 // NewSp2dkJobHandler creates a new Sp2dkJobHandler
-func NewSp2dkJobHandler(sjRepo repositories.Sp2dkJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService) *Sp2dkJobHandler {
+func NewSp2dkJobHandler(sjRepo repositories.Sp2dkJobRepository, cRepo repositories.ClientRepository, sRepo repositories.StaffRepository, invService services.InvoiceService, docService services.DocumentService) *Sp2dkJobHandler {
 	return &Sp2dkJobHandler{
 		Sp2dkJobRepo: sjRepo,
 		ClientRepo:   cRepo,
 		StaffRepo:    sRepo,
 		InvoiceService: invService,
+		DocumentService: docService,
 	}
 }
 
@@ -42,7 +44,7 @@ func (h *Sp2dkJobHandler) CreateSp2dkJob(c *gin.Context) {
 	}
 
 	// Validate client_id existence
-	client, err := h.ClientRepo.GetClientByID(req.ClientID, "", true) // Admin check for client existence
+	client, err := h.ClientRepo.GetClientByID(c.Request.Context(), req.ClientID, "", true) // Admin check for client existence
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate client ID: " + err.Error()})
 		return
@@ -54,7 +56,7 @@ func (h *Sp2dkJobHandler) CreateSp2dkJob(c *gin.Context) {
 
 	// Validate assigned_pic_staff_sigma_id existence
 	if req.AssignedPicStaffSigmaID != "" {
-		staff, err := h.StaffRepo.GetStaffByID(req.AssignedPicStaffSigmaID)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), req.AssignedPicStaffSigmaID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate Assigned PIC Staff ID: " + err.Error()})
 			return
@@ -79,7 +81,27 @@ func (h *Sp2dkJobHandler) CreateSp2dkJob(c *gin.Context) {
 		OverallStatus:    			req.OverallStatus,
 	}
 
-	if err := h.Sp2dkJobRepo.CreateSp2dkJob(sp2dkJob); err != nil {
+	// Job ID dibuat di sini agar event ikut tersimpan dalam transaksi yang sama
+	jobID, err := utils.NewUUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate job ID: " + err.Error()})
+		return
+	}
+	sp2dkJob.JobID = jobID
+	events, err := jobCreatedEvents(c, models.JobAssignment{
+		JobType:    models.JobTypeSp2dk,
+		JobID:      sp2dkJob.JobID,
+		ClientID:   sp2dkJob.ClientID,
+		ClientName: client.ClientName,
+		Period:     sp2dkJob.PeriodLabel(),
+		StaffID:    sp2dkJob.AssignedPicStaffSigmaID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
+	}
+
+	if err := h.Sp2dkJobRepo.CreateSp2dkJob(c.Request.Context(), sp2dkJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create SP2DK job: " + err.Error()})
 		return
 	}
@@ -100,7 +122,18 @@ func (h *Sp2dkJobHandler) GetAllSp2dkJobs(c *gin.Context) {
 		return
 	}
 
-	jobs, err := h.Sp2dkJobRepo.GetAllSp2dkJobs(userClaims.StaffID, userClaims.IsAdmin)
+	format, ok := negotiateExport(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		jobs := func(yield func(*models.Sp2dkJob) error) error {
+			return h.Sp2dkJobRepo.EachSp2dkJob(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin, yield)
+		}
+		writeExport(c, format, "pekerjaan-sp2dk", "SP2DK", export.Sp2dkJobColumns, jobs)
+		return
+	}
+	jobs, err := h.Sp2dkJobRepo.GetAllSp2dkJobs(c.Request.Context(), userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve SP2DK jobs: " + err.Error()})
 		return
@@ -123,7 +156,7 @@ func (h *Sp2dkJobHandler) GetSp2dkJobByID(c *gin.Context) {
 		return
 	}
 
-	job, err := h.Sp2dkJobRepo.GetSp2dkJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	job, err := h.Sp2dkJobRepo.GetSp2dkJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "SP2DK job not found or access denied"})
@@ -154,7 +187,7 @@ func (h *Sp2dkJobHandler) UpdateSp2dkJob(c *gin.Context) {
 	}
 
 	// 1. Ambil data pekerjaan yang ada dari database
-	existingJob, err := h.Sp2dkJobRepo.GetSp2dkJobByID(id, userClaims.StaffID, userClaims.IsAdmin)
+	existingJob, err := h.Sp2dkJobRepo.GetSp2dkJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin)
 	if err != nil {
 		// INI AKAN MENANGKAP ERROR "NOT FOUND" DARI REPOSITORY
 		if err == sql.ErrNoRows {
@@ -178,15 +211,16 @@ func (h *Sp2dkJobHandler) UpdateSp2dkJob(c *gin.Context) {
 	paymentDateForm := c.PostForm("payment_date")
 	reportDateForm := c.PostForm("report_date")
 
-	// 3. Simpan status lama sebelum diubah
+	// 3. Simpan status dan PIC lama sebelum diubah
 	statusSebelumnya := existingJob.OverallStatus
+	previousPic := existingJob.AssignedPicStaffSigmaID
 
 	// 4. Terapkan semua perubahan dari form ke objek 'existingJob'
 	if overallStatusForm != "" {
 		existingJob.OverallStatus = overallStatusForm
 	}
 	if assignedPicStaffSigmaIDForm != "" {
-		staff, err := h.StaffRepo.GetStaffByID(assignedPicStaffSigmaIDForm)
+		staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), assignedPicStaffSigmaIDForm)
 		if err != nil || staff == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "New Assigned PIC Staff ID not found"})
 			return
@@ -218,37 +252,45 @@ func (h *Sp2dkJobHandler) UpdateSp2dkJob(c *gin.Context) {
 	}
 
 	// 5. Proses file upload (jika ada)
+	var uploadedFilePath *string
 	file, err := c.FormFile("proof_of_work_pdf")
 	if err == nil {
 		filename := fmt.Sprintf("%s.pdf", existingJob.JobID)
-		filePath := filepath.Join("uploads", filename)
+		filePath := h.DocumentService.UploadPath(filename)
 		if err := c.SaveUploadedFile(file, filePath); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save proof of work PDF: " + err.Error()})
 			return
 		}
 		url := fmt.Sprintf("/uploads/%s", filename)
 		existingJob.ProofOfWorkURL = &url
+		uploadedFilePath = &url
 	}
 
-	// 6. Panggil pemicu invoice JIKA status diubah menjadi "Selesai"
-	if overallStatusForm == "Selesai" && statusSebelumnya != "Selesai" {
-		log.Printf("INFO: Status pekerjaan SP2DK %s diubah menjadi Selesai. Memicu pembuatan invoice...", existingJob.JobID)
-		_, err := h.InvoiceService.CreateInvoiceFromJob(
-			existingJob.JobID, "SP2DK", existingJob.ClientID, existingJob.AssignedPicStaffSigmaID,
-		)
-		if err != nil {
-			log.Printf("PERINGATAN: Gagal membuat invoice otomatis untuk pekerjaan SP2DK %s: %v", existingJob.JobID, err)
-		} else {
-			log.Printf("INFO: Invoice berhasil dibuat untuk pekerjaan SP2DK %s.", existingJob.JobID)
-		}
+	// 6. Susun domain event. Invoice untuk pekerjaan yang Selesai dibuat oleh subscriber
+	// JobCompleted setelah perubahan ini tersimpan, bukan sebelumnya.
+	events, err := jobUpdatedEvents(c, models.JobStatusChange{
+		JobType:    models.JobTypeSp2dk,
+		JobID:      existingJob.JobID,
+		ClientID:   existingJob.ClientID,
+		ClientName: existingJob.ClientName,
+		Period:     existingJob.PeriodLabel(),
+		OldStatus:  statusSebelumnya,
+		NewStatus:  existingJob.OverallStatus,
+		StaffID:    existingJob.AssignedPicStaffSigmaID,
+	}, previousPic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare job events: " + err.Error()})
+		return
 	}
-	
-	// 7. Simpan SEMUA perubahan ke database
-	log.Printf("DEBUG: Menyimpan final -> JobID: %s, Status: '%s'", existingJob.JobID, existingJob.OverallStatus)
-	if err := h.Sp2dkJobRepo.UpdateSp2dkJob(existingJob); err != nil {
+
+	// 7. Simpan SEMUA perubahan ke database, beserta event-nya dalam satu transaksi
+	if err := h.Sp2dkJobRepo.UpdateSp2dkJob(c.Request.Context(), existingJob, events...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update SP2DK job: " + err.Error()})
 		return
 	}
+	if uploadedFilePath != nil {
+		registerUploadedDocument(c, h.DocumentService, models.JobTypeSp2dk, existingJob.JobID, existingJob.ClientID, *uploadedFilePath)
+	}
 	
 	c.JSON(http.StatusOK, existingJob)
 }
@@ -268,7 +310,7 @@ func (h *Sp2dkJobHandler) DeleteSp2dkJob(c *gin.Context) {
 		return
 	}
 
-	existingJob, err := h.Sp2dkJobRepo.GetSp2dkJobByID(id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
+	existingJob, err := h.Sp2dkJobRepo.GetSp2dkJobByID(c.Request.Context(), id, userClaims.StaffID, userClaims.IsAdmin) // Filter by access
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "SP2DK job not found"})
@@ -282,7 +324,7 @@ func (h *Sp2dkJobHandler) DeleteSp2dkJob(c *gin.Context) {
 		return
 	}
 
-	if err := h.Sp2dkJobRepo.DeleteSp2dkJob(id); err != nil {
+	if err := h.Sp2dkJobRepo.DeleteSp2dkJob(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete SP2DK job: " + err.Error()})
 		return
 	}
@@ -7,17 +7,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
 )
 
 // StaffHandler handles HTTP requests for staff operations
 type StaffHandler struct {
-	StaffRepo repositories.StaffRepository
+	StaffRepo      repositories.StaffRepository
+	AccountService services.AccountService
 }
 
 // NewStaffHandler creates a new StaffHandler
-func NewStaffHandler(repo repositories.StaffRepository) *StaffHandler {
-	return &StaffHandler{StaffRepo: repo}
+func NewStaffHandler(repo repositories.StaffRepository, accountService services.AccountService) *StaffHandler {
+	return &StaffHandler{StaffRepo: repo, AccountService: accountService}
 }
 
 // CreateStaff handles the creation of a new staff member
@@ -29,7 +31,7 @@ func (h *StaffHandler) CreateStaff(c *gin.Context) {
 	}
 
 	// Check if email already exists
-	existingStaff, err := h.StaffRepo.GetStaffByEmail(req.Email)
+	existingStaff, err := h.StaffRepo.GetStaffByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email existence"})
 		return
@@ -38,15 +40,20 @@ func (h *StaffHandler) CreateStaff(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
 		return
 	}
+	if err := h.AccountService.ValidatePassword(req.Password, req.Email, req.Nama); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
 
 	staff := &models.Staff{
-		Nama:           req.Nama,
-		Email:          req.Email,
-		PasswordHashed: req.Password, // Temporarily hold plain password for hashing in repo
-		Role:           req.Role,
+		Nama:               req.Nama,
+		Email:              req.Email,
+		PasswordHashed:     req.Password, // Temporarily hold plain password for hashing in repo
+		Role:               req.Role,
+		MustChangePassword: true, // Password dari admin, staf wajib menggantinya saat login pertama
 	}
 
-	if err := h.StaffRepo.CreateStaff(staff); err != nil {
+	if err := h.StaffRepo.CreateStaff(c.Request.Context(), staff); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create staff: " + err.Error()})
 		return
 	}
@@ -58,7 +65,7 @@ func (h *StaffHandler) CreateStaff(c *gin.Context) {
 
 // GetAllStaffs fetches all staff members
 func (h *StaffHandler) GetAllStaffs(c *gin.Context) {
-	staffs, err := h.StaffRepo.GetAllStaffs()
+	staffs, err := h.StaffRepo.GetAllStaffs(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staffs: " + err.Error()})
 		return
@@ -75,7 +82,7 @@ func (h *StaffHandler) GetAllStaffs(c *gin.Context) {
 func (h *StaffHandler) GetStaffByID(c *gin.Context) {
 	id := c.Param("id")
 
-	staff, err := h.StaffRepo.GetStaffByID(id)
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
@@ -103,7 +110,7 @@ func (h *StaffHandler) UpdateStaff(c *gin.Context) {
 		return
 	}
 
-	existingStaff, err := h.StaffRepo.GetStaffByID(id)
+	existingStaff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
@@ -124,7 +131,7 @@ func (h *StaffHandler) UpdateStaff(c *gin.Context) {
 	if req.Email != nil {
 		// Check for email conflict if email is updated
 		if *req.Email != existingStaff.Email {
-			conflictStaff, err := h.StaffRepo.GetStaffByEmail(*req.Email)
+			conflictStaff, err := h.StaffRepo.GetStaffByEmail(c.Request.Context(), *req.Email)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check new email existence"})
 				return
@@ -147,7 +154,7 @@ func (h *StaffHandler) UpdateStaff(c *gin.Context) {
 		existingStaff.NIP = utils.GenerateNIP(existingStaff.Nama)
 	}
 
-	if err := h.StaffRepo.UpdateStaff(existingStaff); err != nil {
+	if err := h.StaffRepo.UpdateStaff(c.Request.Context(), existingStaff); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update staff: " + err.Error()})
 		return
 	}
@@ -162,7 +169,7 @@ func (h *StaffHandler) DeleteStaff(c *gin.Context) {
 	id := c.Param("id")
 
 	// Optional: Check if staff exists before deleting
-	staff, err := h.StaffRepo.GetStaffByID(id)
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check staff existence"})
 		return
@@ -172,50 +179,78 @@ func (h *StaffHandler) DeleteStaff(c *gin.Context) {
 		return
 	}
 
-	if err := h.StaffRepo.DeleteStaff(id); err != nil {
+	if err := h.StaffRepo.DeleteStaff(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete staff: " + err.Error()})
 		return
 	}
 	c.Status(http.StatusNoContent) // 204 No Content for successful deletion
 }
 
-// ChangeStaffPassword handles changing a staff member's password
+// ChangeStaffPassword (admin) sets a temporary password for a staff member. The staff
+// member must change it on the next login; the account is also unlocked.
 func (h *StaffHandler) ChangeStaffPassword(c *gin.Context) {
-    id := c.Param("id")
-    var req struct {
-        NewPassword string `json:"new_password" binding:"required,min=6"`
-    }
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    staff, err := h.StaffRepo.GetStaffByID(id)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff: " + err.Error()})
-        return
-    }
-    if staff == nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
-        return
-    }
-
-    hashedPassword, err := utils.HashPassword(req.NewPassword)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash new password"})
-        return
-    }
-
-    staff.PasswordHashed = hashedPassword // Update with the new hashed password
-
-    if err := h.StaffRepo.UpdateStaff(staff); err != nil { // Reuse UpdateStaff
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update staff password: " + err.Error()})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
-}
\ No newline at end of file
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	if !claims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can set a staff password"})
+		return
+	}
+
+	id := c.Param("id")
+	var req struct {
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff: " + err.Error()})
+		return
+	}
+	if staff == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
+		return
+	}
+
+	if err := h.AccountService.SetTemporaryPassword(c.Request.Context(), staff, req.NewPassword); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully, the staff member must change it on the next login"})
+}
+
+// UnlockStaff (admin) unlocks an account locked after too many failed logins
+func (h *StaffHandler) UnlockStaff(c *gin.Context) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	if !claims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can unlock staff accounts"})
+		return
+	}
+
+	id := c.Param("id")
+
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff: " + err.Error()})
+		return
+	}
+	if staff == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
+		return
+	}
+
+	if err := h.StaffRepo.ResetFailedLogins(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock staff: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Staff account unlocked"})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/memory"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+)
+
+// newStaffTestRouter mounts the staff admin routes behind a fake auth middleware that logs
+// claims in, the way AuthMiddleware does.
+func newStaffTestRouter(h *StaffHandler, claims *auth.Claims) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_claims", claims) })
+	r.PATCH("/staffs/:id/password", h.ChangeStaffPassword)
+	r.POST("/staffs/:id/unlock", h.UnlockStaff)
+	return r
+}
+
+func TestStaffAdminEndpointsRequireAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewStaffRepository(memory.NewDB())
+	target := &models.Staff{Nama: "Admin Kantor", Email: "admin@example.com", PasswordHashed: "Rahasia-Lama-123", Role: "admin"}
+	if err := repo.CreateStaff(ctx, target); err != nil {
+		t.Fatalf("CreateStaff: %v", err)
+	}
+	if _, err := repo.RecordFailedLogin(ctx, target.StaffID, 1, time.Hour); err != nil {
+		t.Fatalf("RecordFailedLogin: %v", err)
+	}
+	// AccountService tidak diperlukan: non-admin harus ditolak sebelum password disentuh
+	h := NewStaffHandler(repo, nil)
+	r := newStaffTestRouter(h, &auth.Claims{StaffID: "staff-biasa", Role: "staff"})
+
+	tests := []struct {
+		name, method, path, body string
+	}{
+		{"change password", http.MethodPatch, "/staffs/" + target.StaffID + "/password", `{"new_password":"Rahasia-Baru-456"}`},
+		{"unlock", http.MethodPost, "/staffs/" + target.StaffID + "/unlock", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+			}
+		})
+	}
+
+	got, err := repo.GetStaffByID(ctx, target.StaffID)
+	if err != nil {
+		t.Fatalf("GetStaffByID: %v", err)
+	}
+	if !got.IsLocked(time.Now()) {
+		t.Error("account was unlocked by a non-admin")
+	}
+	if got.PasswordHashed != target.PasswordHashed {
+		t.Error("password was changed by a non-admin")
+	}
+}
+
+func TestUnlockStaffAsAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewStaffRepository(memory.NewDB())
+	target := &models.Staff{Nama: "Staf Pajak", Email: "staf@example.com", PasswordHashed: "Rahasia-Lama-123", Role: "staff"}
+	if err := repo.CreateStaff(ctx, target); err != nil {
+		t.Fatalf("CreateStaff: %v", err)
+	}
+	if _, err := repo.RecordFailedLogin(ctx, target.StaffID, 1, time.Hour); err != nil {
+		t.Fatalf("RecordFailedLogin: %v", err)
+	}
+	r := newStaffTestRouter(NewStaffHandler(repo, nil), &auth.Claims{StaffID: "admin", Role: "admin", IsAdmin: true})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/staffs/"+target.StaffID+"/unlock", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	got, err := repo.GetStaffByID(ctx, target.StaffID)
+	if err != nil {
+		t.Fatalf("GetStaffByID: %v", err)
+	}
+	if got.IsLocked(time.Now()) {
+		t.Error("account is still locked")
+	}
+}
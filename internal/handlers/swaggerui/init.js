@@ -0,0 +1,9 @@
+// Dipisah dari index.html karena CSP halaman dokumentasi tidak mengizinkan script inline.
+window.addEventListener("load", function () {
+  window.ui = SwaggerUIBundle({
+    url: "openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    persistAuthorization: true,
+  });
+});
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/djpimport"
+)
+
+// TaxImportHandler handles uploads of e-Bupot and e-Faktur exports for monthly jobs
+type TaxImportHandler struct {
+	MonthlyJobRepo   repositories.MonthlyJobRepository
+	TaxImportService services.TaxImportService
+}
+
+// NewTaxImportHandler creates a new TaxImportHandler
+func NewTaxImportHandler(mjRepo repositories.MonthlyJobRepository, importService services.TaxImportService) *TaxImportHandler {
+	return &TaxImportHandler{
+		MonthlyJobRepo:   mjRepo,
+		TaxImportService: importService,
+	}
+}
+
+// ImportMonthlyJobFiles handles POST /monthly-jobs/:id/imports.
+// Form field "files" (boleh lebih dari satu) berisi file CSV/XML hasil ekspor e-Bupot Unifikasi atau e-Faktur.
+func (h *TaxImportHandler) ImportMonthlyJobFiles(c *gin.Context) {
+	jobID := c.Param("id")
+
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return
+	}
+
+	job, err := h.MonthlyJobRepo.GetMonthlyJobByID(c.Request.Context(), jobID, userClaims.StaffID, userClaims.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monthly job not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monthly job: " + err.Error()})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form: " + err.Error()})
+		return
+	}
+	fileHeaders := form.File["files"]
+	if len(fileHeaders) == 0 {
+		fileHeaders = form.File["file"]
+	}
+	if len(fileHeaders) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one file is required in form field 'files'"})
+		return
+	}
+
+	var files []services.TaxImportFile
+	for _, fh := range fileHeaders {
+		ext := strings.ToLower(filepath.Ext(fh.Filename))
+		if ext != ".csv" && ext != ".xml" && ext != ".txt" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only CSV or XML exports are allowed: " + fh.Filename})
+			return
+		}
+		if fh.Size > djpimport.MaxFileSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File is too large: " + fh.Filename})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open uploaded file: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		files = append(files, services.TaxImportFile{Name: fh.Filename, Reader: f})
+	}
+
+	result, err := h.TaxImportService.ImportMonthlyJobFiles(c.Request.Context(), job, files)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tax exports: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
+)
+
+var errPaidWithoutNTPN = errors.New("laporan tidak dapat berstatus lunas tanpa NTPN yang valid")
+
+// paymentIdentifiers tells applyTaxPaymentRules which identifiers a request sets. Stored
+// values that predate the format checks, such as free-text billing codes, are kept as they
+// are when an update does not change them.
+type paymentIdentifiers struct {
+	BillingCode bool
+	Ntpn        bool
+}
+
+// newReportIdentifiers checks every identifier of a report that is being created
+var newReportIdentifiers = paymentIdentifiers{BillingCode: true, Ntpn: true}
+
+// applyTaxPaymentRules normalizes the billing code and NTPN of a tax report in place and
+// validates its payment evidence. A report can only be marked as paid when it has an NTPN.
+func applyTaxPaymentRules(billingCode *string, ev *models.TaxPaymentEvidence, paymentDate *time.Time, reportStatus string, changed paymentIdentifiers) error {
+	if changed.BillingCode && strings.TrimSpace(*billingCode) != "" {
+		code := taxpayment.NormalizeBillingCode(*billingCode)
+		if err := taxpayment.ValidateBillingCode(code); err != nil {
+			return err
+		}
+		*billingCode = code
+	}
+
+	if changed.Ntpn {
+		if strings.TrimSpace(ev.Ntpn) != "" {
+			ntpn := taxpayment.NormalizeNTPN(ev.Ntpn)
+			if err := taxpayment.ValidateNTPN(ntpn); err != nil {
+				return err
+			}
+			ev.Ntpn = ntpn
+		} else {
+			ev.Ntpn = ""
+		}
+	}
+	ev.PaymentChannel = strings.TrimSpace(ev.PaymentChannel)
+
+	if ev.BillingAmount != nil && *ev.BillingAmount < 0 {
+		return errors.New("jumlah pada kode billing tidak boleh negatif")
+	}
+	if models.IsPaidReportStatus(reportStatus) && strings.TrimSpace(ev.Ntpn) == "" {
+		return errPaidWithoutNTPN
+	}
+	return taxpayment.CheckExpiry(ev.BillingCodeExpiresAt, paymentDate)
+}
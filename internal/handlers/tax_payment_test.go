@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/memory"
+)
+
+// Kode billing lama berupa teks bebas (mis. "BILL..." di koleksi Postman) tidak boleh membuat
+// perubahan field lain pada laporan gagal.
+func TestUpdateMonthlyTaxReportKeepsLegacyBillingCode(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	staffs := memory.NewStaffRepository(db)
+	clients := memory.NewClientRepository(db)
+	monthlyJobs := memory.NewMonthlyJobRepository(db)
+
+	staff := &models.Staff{Nama: "Staf Pajak", Email: "staf@example.com", PasswordHashed: "Rahasia-Lama-123", Role: "staff"}
+	if err := staffs.CreateStaff(ctx, staff); err != nil {
+		t.Fatalf("CreateStaff: %v", err)
+	}
+	client := &models.Client{ClientName: "PT Lama", NpwpClient: "01.234.567.8-901.000", NpwpCanonical: "012345678901000",
+		MembershipStatus: "Aktif", PicStaffSigmaID: staff.StaffID, ClientCategory: "Badan"}
+	if err := clients.CreateClient(ctx, client); err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	job := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 1, JobYear: 2025, AssignedPicStaffSigmaID: staff.StaffID,
+		OverallStatus: "Dikerjakan", TaxReports: []models.MonthlyTaxReport{
+			{TaxType: "PPH_FINAL_UMKM", BillingCode: "BILL20250112345", ReportStatus: "Pending Payment"},
+		}}
+	if err := monthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	reportID := job.TaxReports[0].ReportID
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PATCH("/monthly-jobs/:id/tax-reports/:report_id", NewMonthlyJobHandler(monthlyJobs, clients, staffs, nil, nil).UpdateMonthlyTaxReport)
+
+	tests := []struct {
+		name            string
+		body            string
+		wantStatus      int
+		wantBillingCode string
+	}{
+		{"report date only", `{"report_date":"2025-02-20T00:00:00Z"}`, http.StatusOK, "BILL20250112345"},
+		{"valid new billing code", `{"billing_code":"1234 5678 9012 345"}`, http.StatusOK, "123456789012345"},
+		{"invalid new billing code", `{"billing_code":"BILL-BARU"}`, http.StatusBadRequest, "123456789012345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/monthly-jobs/"+job.JobID+"/tax-reports/"+reportID, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code == http.StatusOK {
+				var got models.MonthlyTaxReport
+				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if got.BillingCode != tt.wantBillingCode {
+					t.Errorf("response billing code = %q, want %q", got.BillingCode, tt.wantBillingCode)
+				}
+			}
+			stored, err := monthlyJobs.GetMonthlyTaxReportByID(ctx, reportID)
+			if err != nil {
+				t.Fatalf("GetMonthlyTaxReportByID: %v", err)
+			}
+			if stored.BillingCode != tt.wantBillingCode {
+				t.Errorf("stored billing code = %q, want %q", stored.BillingCode, tt.wantBillingCode)
+			}
+		})
+	}
+}
+
+func TestApplyTaxPaymentRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		billingCode string
+		ntpn        string
+		status      string
+		changed     paymentIdentifiers
+		wantErr     bool
+		wantCode    string
+		wantNtpn    string
+	}{
+		{"new report normalizes", "1234-5678-9012-345", "0a1b 2c3d 4e5f 6g7h", "Lunas", newReportIdentifiers, false, "123456789012345", "0A1B2C3D4E5F6G7H"},
+		{"new report with legacy code", "BILL123", "", "", newReportIdentifiers, true, "", ""},
+		{"unchanged legacy code", "BILL123", "", "", paymentIdentifiers{}, false, "BILL123", ""},
+		{"changed invalid NTPN", "BILL123", "NTPN-LAMA", "", paymentIdentifiers{Ntpn: true}, true, "", ""},
+		{"paid without NTPN", "", "", "Lunas", paymentIdentifiers{}, true, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := tt.billingCode
+			ev := models.TaxPaymentEvidence{Ntpn: tt.ntpn}
+			err := applyTaxPaymentRules(&code, &ev, nil, tt.status, tt.changed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if code != tt.wantCode || ev.Ntpn != tt.wantNtpn {
+				t.Errorf("got (%q, %q), want (%q, %q)", code, ev.Ntpn, tt.wantCode, tt.wantNtpn)
+			}
+		})
+	}
+}
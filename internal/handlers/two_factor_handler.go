@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// TwoFactorHandler handles TOTP enrollment of the logged-in staff member and admin 2FA reset
+type TwoFactorHandler struct {
+	StaffRepo        repositories.StaffRepository
+	TwoFactorService services.TwoFactorService
+	JWTSecret        string
+	TokenTTL         time.Duration
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler. The JWT settings are used to issue a
+// new login token once enrollment removes the mfa_setup restriction.
+func NewTwoFactorHandler(staffRepo repositories.StaffRepository, twoFactorService services.TwoFactorService,
+	jwtSecret string, tokenTTL time.Duration) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		StaffRepo:        staffRepo,
+		TwoFactorService: twoFactorService,
+		JWTSecret:        jwtSecret,
+		TokenTTL:         tokenTTL,
+	}
+}
+
+// currentStaff loads the logged-in staff member
+func (h *TwoFactorHandler) currentStaff(c *gin.Context) (*models.Staff, bool) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return nil, false
+	}
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), claims.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff: " + err.Error()})
+		return nil, false
+	}
+	if staff == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Staff not found"})
+		return nil, false
+	}
+	return staff, true
+}
+
+// GetStatus returns whether 2FA is enabled or required and how many recovery codes are left
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	staff, ok := h.currentStaff(c)
+	if !ok {
+		return
+	}
+	status, err := h.TwoFactorService.Status(c.Request.Context(), staff)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve 2FA status: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// BeginSetup creates a new TOTP secret. The otpauth_uri is rendered as a QR code by the
+// frontend; 2FA is only enabled after ConfirmSetup with a code from the app.
+func (h *TwoFactorHandler) BeginSetup(c *gin.Context) {
+	staff, ok := h.currentStaff(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.TwoFactorService.BeginEnrollment(c.Request.Context(), staff)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmSetup enables 2FA with the first code from the authenticator app. The recovery codes
+// are only shown in this response; the new token no longer has the mfa_setup restriction.
+func (h *TwoFactorHandler) ConfirmSetup(c *gin.Context) {
+	staff, ok := h.currentStaff(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.TwoFactorService.ConfirmEnrollment(c.Request.Context(), staff.StaffID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	staff.TOTPEnabled = true
+	signedToken, err := issueStaffToken(h.JWTSecret, h.TokenTTL, staff, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled, store the recovery codes in a safe place",
+		"recovery_codes": codes,
+		"token":          signedToken,
+	})
+}
+
+// Disable turns off 2FA of the logged-in staff member, unless their role requires it
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	staff, ok := h.currentStaff(c)
+	if !ok {
+		return
+	}
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.TwoFactorService.Disable(c.Request.Context(), staff, req.Password, req.Code); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current code
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.TwoFactorService.RegenerateRecoveryCodes(c.Request.Context(), claims.StaffID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// ResetStaffTwoFactor (admin) removes the 2FA of a staff member who lost their authenticator
+// and recovery codes. Staff in a role that requires 2FA must enroll again on the next login.
+func (h *TwoFactorHandler) ResetStaffTwoFactor(c *gin.Context) {
+	claims, ok := staffClaims(c)
+	if !ok {
+		return
+	}
+	if !claims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can reset two-factor authentication"})
+		return
+	}
+
+	id := c.Param("id")
+	staff, err := h.StaffRepo.GetStaffByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff: " + err.Error()})
+		return
+	}
+	if staff == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
+		return
+	}
+
+	if err := h.TwoFactorService.Reset(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset 2FA: " + err.Error()})
+		return
+	}
+	logger.FromContext(c.Request.Context()).Warn("two-factor authentication reset", "target_staff_id", id)
+	c.Status(http.StatusNoContent)
+}
+
+// respondTwoFactorError writes the response for errors of the 2FA endpoints
+func respondTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is incorrect"})
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	case errors.Is(err, services.ErrTwoFactorNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "No pending enrollment, start with POST /api/v1/auth/2fa/setup"})
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update two-factor authentication"})
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/auth"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/hmacsig"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and the delivery log (admin only)
+type WebhookHandler struct {
+	WebhookRepo    repositories.WebhookRepository
+	WebhookService services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(whRepo repositories.WebhookRepository, whService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		WebhookRepo:    whRepo,
+		WebhookService: whService,
+	}
+}
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// requireAdmin writes 401/403 and returns false unless the caller is an admin
+func requireAdmin(c *gin.Context) bool {
+	claims, exists := c.Get("user_claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User claims not found in context"})
+		return false
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		return false
+	}
+	if !userClaims.IsAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage webhooks"})
+		return false
+	}
+	return true
+}
+
+// validateWebhookEvents returns an error message for the first unknown event type, or "" if all are valid
+func validateWebhookEvents(eventTypes []string) string {
+	for _, e := range eventTypes {
+		if !models.IsValidWebhookEvent(e) {
+			return "Unknown event type '" + e + "', valid types: " + strings.Join(models.WebhookEventTypes, ", ")
+		}
+	}
+	return ""
+}
+
+// CreateSubscription registers a new webhook endpoint. The secret is only returned in this response.
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	var req models.NewWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := validateWebhookEvents(req.EventTypes); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := hmacsig.NewSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret: " + err.Error()})
+			return
+		}
+		secret = generated
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := h.WebhookRepo.CreateSubscription(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook subscription: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetAllSubscriptions lists every webhook subscription (without secrets)
+func (h *WebhookHandler) GetAllSubscriptions(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	subs, err := h.WebhookRepo.GetAllSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscriptions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// GetSubscriptionByID returns a single webhook subscription (without its secret)
+func (h *WebhookHandler) GetSubscriptionByID(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	sub, err := h.WebhookRepo.GetSubscriptionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscription: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// UpdateSubscription handles partial updates of a webhook subscription
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	var req models.UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.WebhookRepo.GetSubscriptionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscription: " + err.Error()})
+		return
+	}
+
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.Secret != nil {
+		sub.Secret = *req.Secret
+	}
+	if req.EventTypes != nil {
+		if msg := validateWebhookEvents(*req.EventTypes); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		sub.EventTypes = *req.EventTypes
+	}
+	if req.Description != nil {
+		sub.Description = req.Description
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := h.WebhookRepo.UpdateSubscription(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook subscription: " + err.Error()})
+		return
+	}
+	sub.Secret = ""
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription removes a webhook subscription together with its delivery log
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	if err := h.WebhookRepo.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook subscription: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription deleted successfully"})
+}
+
+// GetDeliveries returns the delivery log, newest first.
+// Query params: subscription_id, status (Pending/Succeeded/Failed), limit (default 50, max 200)
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	h.listDeliveries(c, c.Query("subscription_id"))
+}
+
+// GetSubscriptionDeliveries returns the delivery log of one subscription
+func (h *WebhookHandler) GetSubscriptionDeliveries(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	h.listDeliveries(c, c.Param("id"))
+}
+
+func (h *WebhookHandler) listDeliveries(c *gin.Context, subscriptionID string) {
+	status := c.Query("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, use Pending, Succeeded or Failed"})
+		return
+	}
+	limit := defaultDeliveryLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 || n > maxDeliveryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.WebhookRepo.GetDeliveries(c.Request.Context(), subscriptionID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDeliveryByID returns a single delivery including the last response from the endpoint
+func (h *WebhookHandler) GetDeliveryByID(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	delivery, err := h.WebhookRepo.GetDeliveryByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook delivery: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedeliverDelivery queues the same event payload again as a new delivery, signed with the current secret
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	delivery, err := h.WebhookService.Redeliver(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver webhook: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
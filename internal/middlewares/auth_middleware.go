@@ -4,6 +4,7 @@ package middlewares
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -18,68 +19,155 @@ type AuthMiddleware struct {
 
 func NewAuthMiddleware(cfg *config.Config) *AuthMiddleware {
 	return &AuthMiddleware{
-		JWTSecret: []byte(cfg.JWTSecretKey),
+		JWTSecret: []byte(cfg.Auth.JWTSecret),
 	}
 }
 
+// AuthRequired hanya menerima token staf. Token portal klien ditolak meskipun tanda tangannya sah.
+// Token staf yang masih harus mengganti password (pwd_change) atau enrollment 2FA (mfa_setup)
+// ditolak dengan 403.
 func (m *AuthMiddleware) AuthRequired() gin.HandlerFunc {
+	return m.staffAuth(false)
+}
+
+// AuthRequiredForAccountSetup seperti AuthRequired, tetapi juga menerima token pwd_change dan
+// mfa_setup. Hanya untuk endpoint ganti password sendiri dan enrollment 2FA.
+func (m *AuthMiddleware) AuthRequiredForAccountSetup() gin.HandlerFunc {
+	return m.staffAuth(true)
+}
+
+func (m *AuthMiddleware) staffAuth(allowAccountSetup bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
-			c.Abort()
+		// EventSource di browser tidak bisa mengirim header, jadi stream SSE boleh memakai ?access_token=
+		if authHeader == "" && strings.Contains(c.GetHeader("Accept"), "text/event-stream") && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
+		claims, ok := m.parseToken(c, authHeader)
+		if !ok {
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format (Bearer token expected)"})
+		// Token staf lama dibuat sebelum ada audience, jadi aud kosong tetap dianggap staf
+		audience, _ := claims.GetAudience()
+		if len(audience) > 0 && !slices.Contains(audience, auth.AudienceStaff) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is not valid for this API"})
 			c.Abort()
 			return
 		}
-		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return m.JWTSecret, nil
-		})
+		// --- BLOK YANG DIPERBAIKI ---
 
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+		staffID, okID := claims["staff_id"].(string)
+		role, okRole := claims["role"].(string)
+
+		if !okID || !okRole {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-            // --- BLOK YANG DIPERBAIKI ---
-			
-			staffID, okID := claims["staff_id"].(string)
-			role, okRole := claims["role"].(string)
-
-			if !okID || !okRole {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		mustChangePassword, _ := claims[auth.ClaimPasswordChange].(bool)
+		twoFactorSetup, _ := claims[auth.ClaimTwoFactorSetup].(bool)
+		if !allowAccountSetup {
+			if mustChangePassword {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Password change required, use POST /api/v1/auth/change-password",
+					"code":  "password_change_required",
+				})
 				c.Abort()
 				return
 			}
-			
-			// Buat struct Claims dari data token
-			userClaims := &auth.Claims{
-				StaffID: staffID,
-				Role:    role,
-				IsAdmin: role == "admin", // Konversi 'role' string menjadi boolean 'IsAdmin'
+			if twoFactorSetup {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Two-factor authentication is required for your role, enroll with POST /api/v1/auth/2fa/setup",
+					"code":  "two_factor_setup_required",
+				})
+				c.Abort()
+				return
 			}
+		}
+
+		// Buat struct Claims dari data token
+		userClaims := &auth.Claims{
+			StaffID:                staffID,
+			Role:                   role,
+			IsAdmin:                role == "admin", // Konversi 'role' string menjadi boolean 'IsAdmin'
+			MustChangePassword:     mustChangePassword,
+			TwoFactorSetupRequired: twoFactorSetup,
+		}
 
-			// Simpan SELURUH STRUCT ke dalam konteks dengan SATU KUNCI
-			c.Set("user_claims", userClaims)
+		// Simpan SELURUH STRUCT ke dalam konteks dengan SATU KUNCI
+		c.Set("user_claims", userClaims)
+		addLogAttrs(c, "staff_id", staffID)
 
-            // --- AKHIR BLOK YANG DIPERBAIKI ---
+		// --- AKHIR BLOK YANG DIPERBAIKI ---
 
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Next()
+	}
+}
+
+// ClientPortalRequired hanya menerima token portal klien dan menyimpan *auth.ClientClaims
+// di context sebagai "client_claims". Token staf ditolak.
+func (m *AuthMiddleware) ClientPortalRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := m.parseToken(c, c.GetHeader("Authorization"), jwt.WithAudience(auth.AudienceClientPortal))
+		if !ok {
+			return
+		}
+
+		clientUserID, okUser := claims["client_user_id"].(string)
+		clientID, okClient := claims["client_id"].(string)
+		if !okUser || !okClient || clientUserID == "" || clientID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
+			return
+		}
+
+		c.Set("client_claims", &auth.ClientClaims{
+			ClientUserID: clientUserID,
+			ClientID:     clientID,
+		})
+		addLogAttrs(c, "client_user_id", clientUserID, "client_id", clientID)
+		c.Next()
+	}
+}
+
+// parseToken memvalidasi Bearer token dari authHeader. Jika tidak valid, respons 401 sudah
+// ditulis dan request dihentikan.
+func (m *AuthMiddleware) parseToken(c *gin.Context, authHeader string, opts ...jwt.ParserOption) (jwt.MapClaims, bool) {
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
+		c.Abort()
+		return nil, false
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format (Bearer token expected)"})
+		c.Abort()
+		return nil, false
+	}
+	tokenString := parts[1]
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
+		return m.JWTSecret, nil
+	}, opts...)
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+		c.Abort()
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return nil, false
 	}
-}
\ No newline at end of file
+	return claims, true
+}
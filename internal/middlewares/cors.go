@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	corsAllowMethods = strings.Join([]string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}, ", ")
+	corsAllowHeaders = strings.Join([]string{"Authorization", "Content-Type", RequestIDHeader}, ", ")
+	// Header yang boleh dibaca JavaScript frontend, mis. nama file export dan waktu tunggu 429
+	corsExposeHeaders = strings.Join([]string{
+		RequestIDHeader, "Content-Disposition", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining",
+	}, ", ")
+)
+
+// CORS mengizinkan frontend di allowedOrigins memanggil API dari browser. "*" mengizinkan
+// semua origin. API memakai token Bearer, bukan cookie, jadi credentials tidak diizinkan.
+// Preflight (OPTIONS) dijawab langsung dengan 204; tanpa origin yang cocok, header CORS
+// tidak dikirim dan browser menolak responsnya.
+func CORS(allowedOrigins []string, maxAge time.Duration) gin.HandlerFunc {
+	allowAll := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[strings.TrimSuffix(strings.ToLower(origin), "/")] = true
+	}
+	maxAgeSeconds := strconv.Itoa(int(maxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next() // Bukan request lintas origin dari browser
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if !allowAll && !allowed[strings.ToLower(origin)] {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+			c.Header("Access-Control-Max-Age", maxAgeSeconds)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/metrics"
+)
+
+// HTTPMetrics mencatat durasi setiap request ke h dengan label method, route dan status.
+// Route memakai pola gin (mis. /api/v1/monthly-jobs/:id), bukan path mentah, agar jumlah
+// label tetap kecil.
+func HTTPMetrics(h *metrics.HistogramVec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		h.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsTokenRequired hanya mengizinkan scraper yang mengirim "Authorization: Bearer <token>".
+// Token ini terpisah dari JWT staf, sehingga Prometheus tidak butuh akun dashboard.
+func MetricsTokenRequired(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+		c.Next()
+	}
+}
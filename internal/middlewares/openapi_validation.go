@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/openapi"
+)
+
+// ValidateRequest menolak request yang tidak sesuai dokumen OpenAPI dengan 400 sebelum
+// sampai ke handler: parameter path dan query, Content-Type, dan body JSON. Route yang
+// tidak ada di dokumen diteruskan apa adanya. doc boleh diisi setelah middleware dipasang,
+// karena dokumen baru bisa dibuat setelah semua route terdaftar. Body JSON yang lebih besar
+// dari maxBodySize byte ditolak dengan 413.
+func ValidateRequest(doc *openapi.Document, maxBodySize int64) gin.HandlerFunc {
+	validator := openapi.NewValidator(doc, maxBodySize)
+	return func(c *gin.Context) {
+		op := doc.Operation(c.Request.Method, openapi.PathTemplate(c.FullPath()))
+		if op == nil {
+			c.Next()
+			return
+		}
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		problems, err := validator.Validate(op, c.Request, params)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Request body is too large, maximum is %d bytes", tooLarge.Limit)})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body: " + err.Error()})
+			return
+		}
+		if len(problems) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Request does not match the API specification: " + strings.Join(problems, "; ")})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// RequestIDHeader dibaca dari request (mis. dari load balancer) dan selalu dikirim balik di respons.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern membatasi request ID dari luar agar tidak bisa menyisipkan isi aneh ke log.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestLogger memberi setiap request sebuah request ID, menyimpan logger per request
+// (request_id, method, route) di context request, lalu menulis satu log akses setelah
+// request selesai lengkap dengan status, latency dan user yang login.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			id, err := utils.NewUUID()
+			if err != nil {
+				id = fmt.Sprintf("%d", start.UnixNano()) // crypto/rand gagal; masih unik cukup untuk log
+			}
+			requestID = id
+		}
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched" // 404, jangan catat path mentah sebagai route
+		}
+		ctx := logger.With(c.Request.Context(),
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("path", c.Request.URL.Path), // Tanpa query string, yang bisa berisi access_token
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		// Pakai context request terakhir: middleware auth menambahkan staff_id / client_user_id
+		logger.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery menangkap panic di handler, mencatatnya ke logger request beserta stack trace,
+// dan menjawab 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.FromContext(c.Request.Context()).Error("panic recovered",
+			slog.String("error", fmt.Sprint(recovered)),
+			slog.String("stack", string(debug.Stack())),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
+
+// addLogAttrs menambahkan atribut ke logger di context request.
+func addLogAttrs(c *gin.Context, args ...any) {
+	c.Request = c.Request.WithContext(logger.With(c.Request.Context(), args...))
+}
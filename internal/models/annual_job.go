@@ -13,6 +13,7 @@ type AnnualTaxReport struct {
 	PaymentAmount  *float64   `json:"payment_amount"` // Use pointer for nullable NUMERIC
 	ReportDate     *time.Time `json:"report_date"` // Use pointer for nullable DATE
 	ReportStatus   string     `json:"report_status"`
+	TaxPaymentEvidence
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 }
@@ -64,6 +65,11 @@ type NewAnnualTaxReportRequest struct {
 	PaymentAmount *float64   `json:"payment_amount"`
 	ReportDate    *time.Time `json:"report_date"`
 	ReportStatus  string     `json:"report_status"`
+
+	BillingCodeExpiresAt *time.Time `json:"billing_code_expires_at"`
+	BillingAmount        *float64   `json:"billing_amount"`
+	Ntpn                 string     `json:"ntpn"`
+	PaymentChannel       string     `json:"payment_channel"`
 }
 
 // NewAnnualDividendReportRequest represents the input for creating an Investasi Dividen report
@@ -88,6 +94,11 @@ type UpdateAnnualTaxReportRequest struct {
 	PaymentAmount *float64   `json:"payment_amount"`
 	ReportDate    *time.Time `json:"report_date"`
 	ReportStatus  *string    `json:"report_status"`
+
+	BillingCodeExpiresAt *time.Time `json:"billing_code_expires_at"`
+	BillingAmount        *float64   `json:"billing_amount"`
+	Ntpn                 *string    `json:"ntpn"`
+	PaymentChannel       *string    `json:"payment_channel"`
 }
 
 // UpdateAnnualDividendReportRequest represents the input for updating an Investasi Dividen report
@@ -8,7 +8,8 @@ import (
 type Client struct {
 	ClientID             string    `json:"client_id"`
 	ClientName           string    `json:"client_name"`
-	NpwpClient           string    `json:"npwp_client"`
+	NpwpClient           string    `json:"npwp_client"`    // Bentuk tampilan, mis. 01.234.567.8-901.000
+	NpwpCanonical        string    `json:"npwp_canonical"` // 16 digit tanpa tanda baca, untuk pencocokan
 	AddressClient        string    `json:"address_client"`
 	MembershipStatus     string    `json:"membership_status"`
 	PhoneClient          string    `json:"phone_client"`
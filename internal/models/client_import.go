@@ -0,0 +1,40 @@
+package models
+
+// Mode impor klien
+const (
+	ClientImportModeInsert = "insert" // NPWP yang sudah terdaftar dianggap error
+	ClientImportModeUpsert = "upsert" // NPWP yang sudah terdaftar diperbarui
+)
+
+// Status per baris hasil impor klien
+const (
+	ClientImportStatusValid   = "valid" // Lolos validasi (dry run atau batal karena baris lain error)
+	ClientImportStatusError   = "error"
+	ClientImportStatusCreated = "created"
+	ClientImportStatusUpdated = "updated"
+)
+
+// ClientImportRowResult is the validation/import result of one row of the uploaded file
+type ClientImportRowResult struct {
+	Row        int      `json:"row"` // Nomor baris di file, termasuk header
+	Status     string   `json:"status"`
+	Action     string   `json:"action,omitempty"` // "create" atau "update" untuk baris yang valid
+	ClientID   string   `json:"client_id,omitempty"`
+	ClientName string   `json:"client_name"`
+	NpwpClient string   `json:"npwp_client"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ClientImportResult is the response of POST /clients/import
+type ClientImportResult struct {
+	DryRun    bool                    `json:"dry_run"`
+	Mode      string                  `json:"mode"`
+	Committed bool                    `json:"committed"`
+	TotalRows int                     `json:"total_rows"`
+	ValidRows int                     `json:"valid_rows"`
+	ErrorRows int                     `json:"error_rows"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Mapping   map[string]string       `json:"mapping"` // Field klien -> judul kolom di file
+	Rows      []ClientImportRowResult `json:"rows"`
+}
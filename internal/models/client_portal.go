@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+)
+
+// ClientUser is a login for the read-only client portal, linked to one client
+type ClientUser struct {
+	ClientUserID   string     `json:"client_user_id"`
+	ClientID       string     `json:"client_id"`
+	ClientName     string     `json:"client_name"` // Populated from clients table
+	Nama           string     `json:"nama"`
+	Email          string     `json:"email"`
+	PasswordHashed string     `json:"-"` // Jangan kirim password ke client
+	IsActive       bool       `json:"is_active"`
+	LastLoginAt    *time.Time `json:"last_login_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewClientUserRequest represents the input for creating a portal login for a client
+type NewClientUserRequest struct {
+	Nama     string `json:"nama" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// UpdateClientUserRequest represents a partial update of a portal login
+type UpdateClientUserRequest struct {
+	Nama     *string `json:"nama"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Password *string `json:"password" binding:"omitempty,min=8"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// PortalJob is the client-facing view of a job: what it is, its period and its status
+type PortalJob struct {
+	JobType         string    `json:"job_type"`
+	JobID           string    `json:"job_id"`
+	Period          string    `json:"period"`
+	OverallStatus   string    `json:"overall_status"`
+	ProofDocumentID *string   `json:"proof_document_id"` // Unduh lewat /portal/documents/:id/download
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PortalTaxReport is the client-facing view of a monthly or annual tax report and its payment
+type PortalTaxReport struct {
+	JobType        string     `json:"job_type"`
+	JobID          string     `json:"job_id"`
+	Period         string     `json:"period"`
+	TaxType        string     `json:"tax_type"`
+	ReportStatus   string     `json:"report_status"`
+	ReportDate     *time.Time `json:"report_date"`
+	BillingCode    string     `json:"billing_code"`
+	PaymentDate    *time.Time `json:"payment_date"`
+	PaymentAmount  *float64   `json:"payment_amount"`
+	Ntpn           string     `json:"ntpn"`
+	PaymentChannel string     `json:"payment_channel"`
+}
+
+// PortalTaxPaymentSummary sums the tax reports of a year for the client portal
+type PortalTaxPaymentSummary struct {
+	Year          int               `json:"year"`
+	TotalReports  int               `json:"total_reports"`
+	ReportedCount int               `json:"reported_count"` // Sudah ada tanggal lapor
+	PaidCount     int               `json:"paid_count"`
+	TotalPaid     float64           `json:"total_paid"`
+	Reports       []PortalTaxReport `json:"reports"`
+}
+
+// PortalInvoice is the client-facing view of an invoice, without internal staff data
+type PortalInvoice struct {
+	InvoiceID     string              `json:"invoice_id"`
+	InvoiceNumber string              `json:"invoice_number"`
+	InvoiceDate   CustomDate          `json:"invoice_date"`
+	DueDate       CustomDate          `json:"due_date"`
+	TotalAmount   float64             `json:"total_amount"`
+	Status        string              `json:"status"`
+	Notes         *string             `json:"notes"`
+	LineItems     []PortalInvoiceLine `json:"line_items,omitempty"` // Hanya pada detail invoice
+}
+
+// PortalInvoiceLine is one line of a PortalInvoice
+type PortalInvoiceLine struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
+
+// PortalDocument is a proof-of-work document the client can download
+type PortalDocument struct {
+	DocumentID string    `json:"document_id"`
+	JobType    string    `json:"job_type"`
+	JobID      string    `json:"job_id"`
+	FileName   string    `json:"file_name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewPortalInvoice builds the client-facing view of inv, including its line items.
+func NewPortalInvoice(inv *Invoice) PortalInvoice {
+	p := PortalInvoice{
+		InvoiceID:     inv.InvoiceID,
+		InvoiceNumber: inv.InvoiceNumber,
+		InvoiceDate:   inv.InvoiceDate,
+		DueDate:       inv.DueDate,
+		TotalAmount:   inv.TotalAmount,
+		Status:        inv.Status,
+		Notes:         inv.Notes,
+		LineItems:     []PortalInvoiceLine{},
+	}
+	for _, item := range inv.LineItems {
+		p.LineItems = append(p.LineItems, PortalInvoiceLine{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+	return p
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Status sel pada matriks kepatuhan, diurutkan dari yang paling bermasalah
+const (
+	ComplianceStatusMissingJob    = "missing_job"    // Tidak ada pekerjaan bulanan untuk masa ini
+	ComplianceStatusMissingReport = "missing_report" // Pekerjaan ada, tetapi laporan untuk jenis pajak ini belum dibuat
+	ComplianceStatusUnpaid        = "unpaid"         // Sudah lapor, belum ada bukti bayar
+	ComplianceStatusUnreported    = "unreported"     // Sudah bayar, belum lapor
+	ComplianceStatusPending       = "pending"        // Laporan ada, belum bayar dan belum lapor
+	ComplianceStatusUpcoming      = "upcoming"       // Belum jatuh tempo
+	ComplianceStatusComplete      = "complete"       // Sudah bayar (atau nihil) dan sudah lapor
+)
+
+// ComplianceStatusSeverity orders statuses so the worst one can be shown for a month
+var ComplianceStatusSeverity = map[string]int{
+	ComplianceStatusComplete:      0,
+	ComplianceStatusUpcoming:      1,
+	ComplianceStatusPending:       2,
+	ComplianceStatusUnreported:    3,
+	ComplianceStatusUnpaid:        4,
+	ComplianceStatusMissingReport: 5,
+	ComplianceStatusMissingJob:    6,
+}
+
+// ComplianceEntry is one monthly job (and optionally one of its tax reports)
+// as read from the database for the compliance matrix
+type ComplianceEntry struct {
+	ClientID      string
+	JobID         string
+	JobMonth      int
+	OverallStatus string
+	Report        *MonthlyTaxReport // nil when the job has no tax reports
+}
+
+// ComplianceTaxCell is the state of one tax type in one month
+type ComplianceTaxCell struct {
+	TaxType    string  `json:"tax_type"`
+	Subscribed bool    `json:"subscribed"` // false = ada laporan tetapi layanan tidak dicentang di data klien
+	ReportID   *string `json:"report_id"`
+	Paid       bool    `json:"paid"`
+	Reported   bool    `json:"reported"`
+	Nihil      bool    `json:"nihil"` // Tidak ada pajak terutang, jadi tidak perlu bayar
+	Status     string  `json:"status"`
+}
+
+// ComplianceMonthCell is one cell of the matrix: one client in one month
+type ComplianceMonthCell struct {
+	Month     int                 `json:"month"`
+	DueDate   time.Time           `json:"due_date"`
+	JobID     *string             `json:"job_id"`
+	JobStatus string              `json:"job_status"`
+	Status    string              `json:"status"` // Status terburuk dari semua jenis pajak; kosong jika tidak ada layanan
+	Taxes     []ComplianceTaxCell `json:"taxes"`
+}
+
+// ComplianceMatrixRow is one client with its twelve months
+type ComplianceMatrixRow struct {
+	ClientID          string                `json:"client_id"`
+	ClientName        string                `json:"client_name"`
+	NpwpClient        string                `json:"npwp_client"`
+	PicStaffSigmaName string                `json:"pic_staff_sigma_name"`
+	TaxTypes          []string              `json:"tax_types"` // Jenis pajak yang ditampilkan, sesuai urutan Taxes di tiap bulan
+	Months            []ComplianceMonthCell `json:"months"`
+}
+
+// ComplianceMatrix is the response of GET /reports/compliance-matrix
+type ComplianceMatrix struct {
+	Year        int                   `json:"year"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Summary     map[string]int        `json:"summary"` // Jumlah sel jenis pajak per status
+	Rows        []ComplianceMatrixRow `json:"rows"`
+}
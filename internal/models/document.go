@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Status ekstraksi teks dokumen
+const (
+	ExtractionStatusPending = "Pending"
+	ExtractionStatusDone    = "Selesai"
+	ExtractionStatusFailed  = "Gagal"
+)
+
+// Document represents an uploaded file (e.g. proof of work PDF) and its extracted text
+type Document struct {
+	DocumentID        string     `json:"document_id"`
+	ClientID          string     `json:"client_id"`
+	ClientName        string     `json:"client_name"` // Populated from clients table
+	JobType           string     `json:"job_type"`
+	JobID             string     `json:"job_id"`
+	FileName          string     `json:"file_name"`
+	FileURL           string     `json:"file_url"`
+	UploadedByStaffID *string    `json:"uploaded_by_staff_id"`
+	ExtractionStatus  string     `json:"extraction_status"`
+	ExtractionError   *string    `json:"extraction_error"`
+	ContentText       string     `json:"content_text,omitempty"` // Only returned on single document fetch
+	ExtractedAt       *time.Time `json:"extracted_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// DocumentSearchResult is a document matched by content search, with a highlighted snippet
+type DocumentSearchResult struct {
+	Document
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// DocumentSearchParams holds the filters for a content search
+type DocumentSearchParams struct {
+	Query    string
+	ClientID string
+	JobType  string
+	Limit    int
+}
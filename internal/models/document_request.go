@@ -0,0 +1,167 @@
+package models
+
+import (
+	"time"
+)
+
+// Status permintaan dokumen ke klien
+const (
+	DocumentRequestStatusPending  = "Menunggu" // Belum diupload klien
+	DocumentRequestStatusUploaded = "Diupload" // Sudah diupload, menunggu diperiksa staf
+	DocumentRequestStatusAccepted = "Diterima"
+	DocumentRequestStatusRejected = "Ditolak" // Klien perlu mengupload ulang
+)
+
+// IsOutstandingDocumentRequest reports whether the client still has to upload something for a
+// request with the given status.
+func IsOutstandingDocumentRequest(status string) bool {
+	return status == DocumentRequestStatusPending || status == DocumentRequestStatusRejected
+}
+
+// DocumentRequest is one item of the checklist of inputs a client has to send for a job,
+// e.g. "Rekap penjualan" for a monthly job
+type DocumentRequest struct {
+	RequestID              string     `json:"request_id"`
+	JobType                string     `json:"job_type"`
+	JobID                  string     `json:"job_id"`
+	ClientID               string     `json:"client_id"`
+	ClientName             string     `json:"client_name"` // Populated from clients table
+	Period                 string     `json:"period"`      // Populated from the job
+	Title                  string     `json:"title"`
+	Description            *string    `json:"description"`
+	DueDate                CustomDate `json:"due_date"`
+	Status                 string     `json:"status"`
+	DocumentID             *string    `json:"document_id"`
+	FileName               *string    `json:"file_name"` // Populated from documents table
+	UploadedByClientUserID *string    `json:"uploaded_by_client_user_id"`
+	UploadedAt             *time.Time `json:"uploaded_at"`
+	ReviewNote             *string    `json:"review_note"`
+	ReviewedByStaffID      *string    `json:"reviewed_by_staff_id"`
+	ReviewedAt             *time.Time `json:"reviewed_at"`
+	CreatedByStaffID       *string    `json:"created_by_staff_id"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+// DocumentRequestJob is the job a checklist belongs to
+type DocumentRequestJob struct {
+	JobType       string `json:"job_type"`
+	JobID         string `json:"job_id"`
+	ClientID      string `json:"client_id"`
+	ClientName    string `json:"client_name"`
+	Period        string `json:"period"`
+	OverallStatus string `json:"overall_status"`
+	StaffID       string `json:"assigned_pic_staff_sigma_id"`
+}
+
+// DocumentRequestFilter holds the filters for listing document requests. Empty fields are ignored.
+type DocumentRequestFilter struct {
+	JobType         string
+	JobID           string
+	ClientID        string
+	Status          string
+	OutstandingOnly bool   // Hanya yang masih Menunggu atau Ditolak
+	StaffID         string // Hanya pekerjaan dengan PIC ini
+}
+
+// DocumentRequestItem is one checklist item in NewDocumentRequestsRequest
+type DocumentRequestItem struct {
+	Title       string     `json:"title" binding:"required"`
+	Description *string    `json:"description"`
+	DueDate     CustomDate `json:"due_date" binding:"required"`
+}
+
+// NewDocumentRequestsRequest represents the input for adding checklist items to a job
+type NewDocumentRequestsRequest struct {
+	JobType string                `json:"job_type" binding:"required"`
+	JobID   string                `json:"job_id" binding:"required"`
+	Items   []DocumentRequestItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdateDocumentRequestRequest represents a partial update of a checklist item
+type UpdateDocumentRequestRequest struct {
+	Title       *string     `json:"title"`
+	Description *string     `json:"description"`
+	DueDate     *CustomDate `json:"due_date"`
+}
+
+// ReviewDocumentRequestRequest accepts or rejects an uploaded document. A rejected item goes
+// back to the client's list of missing documents.
+type ReviewDocumentRequestRequest struct {
+	Status     string  `json:"status" binding:"required,oneof=Diterima Ditolak"`
+	ReviewNote *string `json:"review_note"`
+}
+
+// DocumentRequestChecklist is the checklist of a job and what is still missing before staff can start
+type DocumentRequestChecklist struct {
+	DocumentRequestJob
+	Ready         bool              `json:"ready"` // Semua dokumen sudah diupload
+	TotalCount    int               `json:"total_count"`
+	MissingCount  int               `json:"missing_count"`
+	Missing       []string          `json:"missing"` // Judul dokumen yang belum diupload atau ditolak
+	AwaitingCount int               `json:"awaiting_review_count"`
+	Items         []DocumentRequest `json:"items"`
+}
+
+// NewDocumentRequestChecklist sums up the checklist items of job.
+func NewDocumentRequestChecklist(job DocumentRequestJob, items []DocumentRequest) DocumentRequestChecklist {
+	checklist := DocumentRequestChecklist{
+		DocumentRequestJob: job,
+		TotalCount:         len(items),
+		Missing:            []string{},
+		Items:              items,
+	}
+	for _, item := range items {
+		if IsOutstandingDocumentRequest(item.Status) {
+			checklist.Missing = append(checklist.Missing, item.Title)
+		} else if item.Status == DocumentRequestStatusUploaded {
+			checklist.AwaitingCount++
+		}
+	}
+	checklist.MissingCount = len(checklist.Missing)
+	checklist.Ready = checklist.MissingCount == 0
+	return checklist
+}
+
+// PortalDocumentRequest is the client-facing view of a DocumentRequest
+type PortalDocumentRequest struct {
+	RequestID   string     `json:"request_id"`
+	JobType     string     `json:"job_type"`
+	JobID       string     `json:"job_id"`
+	Period      string     `json:"period"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	DueDate     CustomDate `json:"due_date"`
+	Status      string     `json:"status"`
+	DocumentID  *string    `json:"document_id"` // Unduh lewat /portal/documents/:id/download
+	FileName    *string    `json:"file_name"`
+	UploadedAt  *time.Time `json:"uploaded_at"`
+	ReviewNote  *string    `json:"review_note"` // Alasan penolakan, jika Ditolak
+}
+
+// NewPortalDocumentRequest builds the client-facing view of req.
+func NewPortalDocumentRequest(req *DocumentRequest) PortalDocumentRequest {
+	return PortalDocumentRequest{
+		RequestID:   req.RequestID,
+		JobType:     req.JobType,
+		JobID:       req.JobID,
+		Period:      req.Period,
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+		Status:      req.Status,
+		DocumentID:  req.DocumentID,
+		FileName:    req.FileName,
+		UploadedAt:  req.UploadedAt,
+		ReviewNote:  req.ReviewNote,
+	}
+}
+
+// DocumentRequestReminder is an outstanding document request together with a client user to remind
+type DocumentRequestReminder struct {
+	ClientUserID string
+	ClientName   string
+	Nama         string
+	Email        string
+	Request      DocumentRequest
+}
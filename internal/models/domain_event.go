@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// Jenis domain event. Event ditulis ke tabel outbox_events dalam transaksi yang sama
+// dengan perubahan datanya, lalu diteruskan ke subscriber oleh dispatcher.
+const (
+	DomainEventJobStatusChanged = "JobStatusChanged" // Payload: JobStatusChange
+	DomainEventJobCompleted     = "JobCompleted"     // Payload: JobStatusChange dengan NewStatus "Selesai"
+	DomainEventJobReassigned    = "JobReassigned"    // Payload: JobAssignment
+	DomainEventInvoiceIssued    = "InvoiceIssued"    // Payload: Invoice beserta line item
+	DomainEventInvoicePaid      = "InvoicePaid"      // Payload: Invoice yang baru berstatus Paid
+	DomainEventClientCreated    = "ClientCreated"    // Payload: Client tanpa hash password Coretax
+)
+
+// DomainEventTypes lists every domain event type.
+var DomainEventTypes = []string{
+	DomainEventJobStatusChanged,
+	DomainEventJobCompleted,
+	DomainEventJobReassigned,
+	DomainEventInvoiceIssued,
+	DomainEventInvoicePaid,
+	DomainEventClientCreated,
+}
+
+// Jenis aggregate pada domain event
+const (
+	AggregateJob     = "job"
+	AggregateInvoice = "invoice"
+	AggregateClient  = "client"
+)
+
+// DomainEvent is a state change recorded in the outbox and delivered at least once to every subscriber
+type DomainEvent struct {
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	ActorStaffID  *string         `json:"actor_staff_id"` // Nil untuk event dari sistem
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempts      int             `json:"attempts"`
+}
+
+// NewDomainEvent membuat event baru dengan ID acak dan payload dalam bentuk JSON.
+func NewDomainEvent(eventType, aggregateType, aggregateID, actorStaffID string, payload interface{}) (DomainEvent, error) {
+	eventID, err := utils.NewUUID()
+	if err != nil {
+		return DomainEvent{}, fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return DomainEvent{}, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+	event := DomainEvent{
+		EventID:       eventID,
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		OccurredAt:    time.Now(),
+	}
+	if actorStaffID != "" {
+		event.ActorStaffID = &actorStaffID
+	}
+	return event, nil
+}
+
+// DecodePayload mengisi v dari payload event.
+func (e *DomainEvent) DecodePayload(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", e.EventType, err)
+	}
+	return nil
+}
+
+// AuditEntry is one domain event as recorded by the audit subscriber
+type AuditEntry struct {
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	ActorStaffID  *string         `json:"actor_staff_id"`
+	ActorName     *string         `json:"actor_name"` // Dari tabel staffs
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	RecordedAt    time.Time       `json:"recorded_at"`
+}
+
+// NewInvoiceCreatedEvents builds the events of a new invoice: InvoiceIssued, followed by
+// InvoicePaid if the invoice is created as already paid.
+func NewInvoiceCreatedEvents(invoice Invoice) ([]DomainEvent, error) {
+	issued, err := NewDomainEvent(DomainEventInvoiceIssued, AggregateInvoice, invoice.InvoiceID, "", invoice)
+	if err != nil {
+		return nil, err
+	}
+	events := []DomainEvent{issued}
+	if invoice.Status == InvoiceStatusPaid {
+		paid, err := NewDomainEvent(DomainEventInvoicePaid, AggregateInvoice, invoice.InvoiceID, "", invoice)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, paid)
+	}
+	return events, nil
+}
+
+// NewClientCreatedEvent builds the ClientCreated event of a new client. Hash password Coretax
+// tidak ikut di payload karena event diteruskan ke sistem luar lewat webhook.
+func NewClientCreatedEvent(client Client) (DomainEvent, error) {
+	client.CoretaxPasswordHashed = ""
+	return NewDomainEvent(DomainEventClientCreated, AggregateClient, client.ClientID, "", client)
+}
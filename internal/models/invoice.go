@@ -4,6 +4,15 @@ import (
 	"time"
 )
 
+// Status invoice
+const (
+	InvoiceStatusDraft     = "Draft"
+	InvoiceStatusPending   = "Pending"
+	InvoiceStatusIssued    = "Issued"
+	InvoiceStatusPaid      = "Paid"
+	InvoiceStatusCancelled = "Cancelled"
+)
+
 // InvoiceLineItem represents a single line item in an invoice
 type InvoiceLineItem struct {
 	LineItemID     string     `json:"line_item_id"`
@@ -35,6 +44,7 @@ type Invoice struct {
 	CreatedAt         time.Time         `json:"created_at"`
 	UpdatedAt         time.Time         `json:"updated_at"`
 	LineItems         []InvoiceLineItem `json:"line_items"` // Nested slice of line items
+	SourceEventID     *string           `json:"-"` // Event JobCompleted asal invoice otomatis
 }
 
 // NewInvoiceLineItemRequest for creating a line item
@@ -75,3 +85,8 @@ type UpdateInvoiceRequest struct {
 	Status            *string    `json:"status"`
 	Notes             *string    `json:"notes"`
 }
+
+// UpdateInvoiceStatusRequest for changing an invoice's status (PATCH /invoices/:id/status)
+type UpdateInvoiceStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=Draft Pending Issued Paid Cancelled"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Jenis pekerjaan. Nilai ini juga dipakai sebagai related_job_type pada invoice.
+const (
+	JobTypeMonthly     = "Pekerjaan Bulanan"
+	JobTypeAnnual      = "Pekerjaan Tahunan"
+	JobTypeSp2dk       = "SP2DK"
+	JobTypePemeriksaan = "Pemeriksaan"
+)
+
+// Status pekerjaan (overall_status) yang dipakai di semua jenis pekerjaan.
+const (
+	JobStatusInProgress = "Dalam Pengerjaan"
+	JobStatusCompleted  = "Selesai"
+	JobStatusCancelled  = "Dibatalkan"
+)
+
+// JobTypes lists every job type in display order.
+var JobTypes = []string{JobTypeMonthly, JobTypeAnnual, JobTypeSp2dk, JobTypePemeriksaan}
+
+// ParseJobType accepts either the display value ("Pekerjaan Bulanan") or the
+// short form used in URLs ("monthly") and returns the display value.
+func ParseJobType(s string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "monthly", "bulanan", strings.ToLower(JobTypeMonthly):
+		return JobTypeMonthly, true
+	case "annual", "tahunan", strings.ToLower(JobTypeAnnual):
+		return JobTypeAnnual, true
+	case "sp2dk":
+		return JobTypeSp2dk, true
+	case "pemeriksaan":
+		return JobTypePemeriksaan, true
+	}
+	return "", false
+}
+
+// PeriodLabel is the job's display period in notifications, matching the
+// period column built by the repositories.
+func (j *MonthlyJob) PeriodLabel() string {
+	return fmt.Sprintf("Masa %02d/%d", j.JobMonth, j.JobYear)
+}
+
+// PeriodLabel is the job's display period in notifications.
+func (j *AnnualJob) PeriodLabel() string {
+	return fmt.Sprintf("Tahun Pajak %d", j.JobYear)
+}
+
+// PeriodLabel is the job's display period in notifications.
+func (j *Sp2dkJob) PeriodLabel() string {
+	return "SP2DK " + letterNumber(j.Sp2dkNo)
+}
+
+// PeriodLabel is the job's display period in notifications.
+func (j *PemeriksaanJob) PeriodLabel() string {
+	return "SP2 " + letterNumber(j.Sp2No)
+}
+
+func letterNumber(no string) string {
+	if no == "" {
+		return "-"
+	}
+	return no
+}
@@ -0,0 +1,14 @@
+package models
+
+// JobStatusCount is the number of jobs of one type with one overall status
+type JobStatusCount struct {
+	JobType string
+	Status  string
+	Count   int
+}
+
+// UnpaidInvoiceSummary sums the issued invoices that are not paid yet
+type UnpaidInvoiceSummary struct {
+	Count       int
+	TotalAmount float64
+}
@@ -14,6 +14,7 @@ type MonthlyTaxReport struct {
 	PaymentAmount  *float64  `json:"payment_amount"`
 	ReportStatus   string    `json:"report_status"`
 	ReportDate     *time.Time `json:"report_date"`
+	TaxPaymentEvidence
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
@@ -57,6 +58,11 @@ type NewMonthlyTaxReportRequest struct {
 	PaymentAmount *float64   `json:"payment_amount"`
 	ReportStatus  string     `json:"report_status"`
 	ReportDate    *time.Time `json:"report_date"`
+
+	BillingCodeExpiresAt *time.Time `json:"billing_code_expires_at"`
+	BillingAmount        *float64   `json:"billing_amount"`
+	Ntpn                 string     `json:"ntpn"`
+	PaymentChannel       string     `json:"payment_channel"`
 }
 
 // UpdateMonthlyJobRequest (sesuaikan dengan perubahan)
@@ -75,4 +81,9 @@ type UpdateMonthlyTaxReportRequest struct {
 	PaymentAmount *float64   `json:"payment_amount"`
 	ReportStatus  *string    `json:"report_status"`
 	ReportDate    *time.Time `json:"report_date"`
+
+	BillingCodeExpiresAt *time.Time `json:"billing_code_expires_at"`
+	BillingAmount        *float64   `json:"billing_amount"`
+	Ntpn                 *string    `json:"ntpn"`
+	PaymentChannel       *string    `json:"payment_channel"`
 }
\ No newline at end of file
@@ -0,0 +1,150 @@
+package models
+
+import "time"
+
+// Jenis notifikasi email
+const (
+	NotificationEventJobAssigned    = "job_assigned"    // Pekerjaan baru ditugaskan ke staf
+	NotificationEventInvoiceOverdue = "invoice_overdue" // Invoice melewati jatuh tempo dan belum lunas
+	NotificationEventReportDue      = "report_due"      // Batas lapor pekerjaan tinggal beberapa hari
+	NotificationEventDailyDigest    = "daily_digest"    // Ringkasan harian pekerjaan terbuka
+
+	// NotificationEventDocumentRequestDue dikirim ke user portal klien, bukan staf,
+	// sehingga tidak mengikuti preferensi notifikasi.
+	NotificationEventDocumentRequestDue = "document_request_due" // Dokumen yang diminta dari klien belum diupload
+
+	// NotificationEventPasswordReset adalah email keamanan akun yang selalu dikirim.
+	NotificationEventPasswordReset = "password_reset" // Tautan reset password dari lupa password
+)
+
+// NotificationPreferences menyimpan jenis email yang ingin diterima seorang staf.
+// Staf yang belum pernah mengatur preferensi menerima semua jenis email.
+type NotificationPreferences struct {
+	StaffID        string    `json:"staff_id"`
+	JobAssigned    bool      `json:"job_assigned"`
+	InvoiceOverdue bool      `json:"invoice_overdue"`
+	ReportDue      bool      `json:"report_due"`
+	DailyDigest    bool      `json:"daily_digest"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreferences mengembalikan preferensi awal: semua notifikasi aktif.
+func DefaultNotificationPreferences(staffID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		StaffID:        staffID,
+		JobAssigned:    true,
+		InvoiceOverdue: true,
+		ReportDue:      true,
+		DailyDigest:    true,
+	}
+}
+
+// Enabled melaporkan apakah event diizinkan oleh preferensi ini.
+func (p *NotificationPreferences) Enabled(event string) bool {
+	switch event {
+	case NotificationEventJobAssigned:
+		return p.JobAssigned
+	case NotificationEventInvoiceOverdue:
+		return p.InvoiceOverdue
+	case NotificationEventReportDue:
+		return p.ReportDue
+	case NotificationEventDailyDigest:
+		return p.DailyDigest
+	}
+	return false
+}
+
+// UpdateNotificationPreferencesRequest represents a partial update of notification preferences (PATCH)
+type UpdateNotificationPreferencesRequest struct {
+	JobAssigned    *bool `json:"job_assigned"`
+	InvoiceOverdue *bool `json:"invoice_overdue"`
+	ReportDue      *bool `json:"report_due"`
+	DailyDigest    *bool `json:"daily_digest"`
+}
+
+// JobAssignment adalah data email "pekerjaan ditugaskan kepada Anda", sekaligus payload event JobReassigned.
+type JobAssignment struct {
+	JobType         string `json:"job_type"`
+	JobID           string `json:"job_id"`
+	ClientID        string `json:"client_id"`
+	ClientName      string `json:"client_name"`
+	Period          string `json:"period"`            // Masa/tahun pajak atau nomor surat, untuk ditampilkan
+	PreviousStaffID string `json:"previous_staff_id"` // Kosong untuk pekerjaan baru
+	StaffID         string `json:"staff_id"`          // Staf yang ditugaskan
+	AssignedBy      string `json:"assigned_by"`       // Staf yang menugaskan; tidak ada email jika sama dengan StaffID
+}
+
+// JobDeadline adalah pekerjaan terbuka beserta batas waktunya dan PIC-nya.
+type JobDeadline struct {
+	JobType       string
+	JobID         string
+	ClientName    string
+	Period        string
+	OverallStatus string
+	DueDate       *time.Time // Nil jika tanggal acuan (mis. tanggal SP2DK) belum diisi
+	StaffID       string
+	StaffName     string
+	StaffEmail    string
+}
+
+// OverdueInvoice adalah invoice yang lewat jatuh tempo beserta staf penanggung jawabnya.
+type OverdueInvoice struct {
+	InvoiceID     string
+	InvoiceNumber string
+	ClientName    string
+	DueDate       time.Time
+	TotalAmount   float64
+	StaffID       string
+	StaffName     string
+	StaffEmail    string
+}
+
+// NotificationEventJobStatusChanged adalah notifikasi in-app saat status pekerjaan diubah orang lain.
+const NotificationEventJobStatusChanged = "job_status_changed"
+
+// Notification is one entry of a staff member's in-app inbox (bell icon)
+type Notification struct {
+	NotificationID string     `json:"notification_id"`
+	StaffID        string     `json:"staff_id"`
+	Event          string     `json:"event"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	JobType        *string    `json:"job_type"`   // Diisi jika notifikasi terkait pekerjaan
+	JobID          *string    `json:"job_id"`     // Diisi jika notifikasi terkait pekerjaan
+	InvoiceID      *string    `json:"invoice_id"` // Diisi jika notifikasi terkait invoice
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// NotificationInbox is the response of GET /me/notifications
+type NotificationInbox struct {
+	UnreadCount   int            `json:"unread_count"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// JobStatusChange is pushed live to dashboards when a job's overall_status changes
+type JobStatusChange struct {
+	JobType    string    `json:"job_type"`
+	JobID      string    `json:"job_id"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Period     string    `json:"period"`
+	OldStatus  string    `json:"old_status"`
+	NewStatus  string    `json:"new_status"`
+	StaffID    string    `json:"staff_id"`   // PIC pekerjaan
+	ChangedBy  string    `json:"changed_by"` // Staf yang mengubah
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// Jenis RealtimeEvent, juga dipakai sebagai nama event SSE
+const (
+	RealtimeEventNotification = "notification"
+	RealtimeEventJobStatus    = "job_status"
+)
+
+// RealtimeEvent is the payload sent over PostgreSQL NOTIFY and pushed to connected dashboards
+type RealtimeEvent struct {
+	Type         string           `json:"type"`
+	Notification *Notification    `json:"notification,omitempty"`
+	JobStatus    *JobStatusChange `json:"job_status,omitempty"`
+}
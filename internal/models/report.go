@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// WorkloadParams holds the filters for the staff workload report
+type WorkloadParams struct {
+	PeriodStart time.Time // Inclusive
+	PeriodEnd   time.Time // Exclusive
+	JobTypes    []string  // Empty means all job types
+}
+
+// WorkloadJobCounts is the number of jobs held by one staff member.
+// Open and overdue are a snapshot as of now; completed counts jobs marked
+// "Selesai" within the requested period.
+type WorkloadJobCounts struct {
+	OpenJobs      int `json:"open_jobs"`
+	OverdueJobs   int `json:"overdue_jobs"`
+	CompletedJobs int `json:"completed_jobs"`
+}
+
+// WorkloadCounts adds the number of clients the staff member is PIC for
+type WorkloadCounts struct {
+	WorkloadJobCounts
+	Clients int `json:"clients"`
+}
+
+// StaffWorkload is one row of the workload report. StaffID is nil for jobs
+// and clients that have no PIC assigned.
+type StaffWorkload struct {
+	StaffID   *string                      `json:"staff_id"`
+	StaffName string                       `json:"staff_name"`
+	Role      string                       `json:"role"`
+	Totals    WorkloadCounts               `json:"totals"`
+	ByJobType map[string]WorkloadJobCounts `json:"by_job_type"`
+}
+
+// WorkloadReport is the response of GET /reports/workload
+type WorkloadReport struct {
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	JobTypes    []string        `json:"job_types"`
+	Staff       []StaffWorkload `json:"staff"`
+	Totals      WorkloadCounts  `json:"totals"`
+}
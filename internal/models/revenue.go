@@ -0,0 +1,48 @@
+package models
+
+// Jenis nilai pada RevenueEntry
+const (
+	RevenueKindBilled    = "billed"    // Ditagih: invoice selain Draft/Cancelled, menurut tanggal invoice
+	RevenueKindCollected = "collected" // Diterima: invoice berstatus Paid, menurut tanggal pelunasan
+)
+
+// RevenueEntry is one aggregated row read from invoices and their line items
+type RevenueEntry struct {
+	Kind           string
+	Year           int
+	Month          int
+	JobType        string // Kosong jika line item tidak terkait pekerjaan
+	ClientCategory string
+	StaffID        *string
+	StaffName      string
+	Amount         float64
+}
+
+// RevenueAmounts holds billed and collected revenue for the report year and
+// the comparison year. Growth is a percentage and nil when the comparison value is zero.
+type RevenueAmounts struct {
+	Billed            float64  `json:"billed"`
+	Collected         float64  `json:"collected"`
+	PreviousBilled    float64  `json:"previous_billed"`
+	PreviousCollected float64  `json:"previous_collected"`
+	BilledGrowth      *float64 `json:"billed_growth_pct"`
+	CollectedGrowth   *float64 `json:"collected_growth_pct"`
+}
+
+// RevenueBreakdownItem is one group (a month, job type, client category or staff) of the revenue report
+type RevenueBreakdownItem struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	RevenueAmounts
+}
+
+// RevenueReport is the response of GET /reports/revenue
+type RevenueReport struct {
+	Year             int                    `json:"year"`
+	CompareYear      int                    `json:"compare_year"`
+	Totals           RevenueAmounts         `json:"totals"`
+	ByMonth          []RevenueBreakdownItem `json:"by_month"`
+	ByJobType        []RevenueBreakdownItem `json:"by_job_type"`
+	ByClientCategory []RevenueBreakdownItem `json:"by_client_category"`
+	ByStaff          []RevenueBreakdownItem `json:"by_staff"`
+}
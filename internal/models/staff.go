@@ -6,21 +6,32 @@ import (
 
 // Staff represents a staff member
 type Staff struct {
-	StaffID        string    `json:"staff_id"`
-	NIP            string    `json:"nip"`
-	Nama           string    `json:"nama"`
-	Email          string    `json:"email"`
-	PasswordHashed string    `json:"-"` // Jangan kirim password ke client
-	Role           string    `json:"role"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	StaffID        string `json:"staff_id"`
+	NIP            string `json:"nip"`
+	Nama           string `json:"nama"`
+	Email          string `json:"email"`
+	PasswordHashed string `json:"-"` // Jangan kirim password ke client
+	Role           string `json:"role"`
+	// MustChangePassword true jika password diset admin; staf wajib menggantinya setelah login
+	MustChangePassword  bool       `json:"must_change_password"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at,omitempty"`
+	FailedLoginAttempts int        `json:"-"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"` // Akun terkunci karena login gagal berulang
+	TOTPEnabled         bool       `json:"totp_enabled"`           // Login dua langkah dengan aplikasi authenticator
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// IsLocked returns true if the account is locked at now
+func (s *Staff) IsLocked(now time.Time) bool {
+	return s.LockedUntil != nil && s.LockedUntil.After(now)
 }
 
 // NewStaffRequest represents the structure for creating a new staff member (input from API)
 type NewStaffRequest struct {
 	Nama     string `json:"nama" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // Diperiksa dengan kebijakan password
 	Role     string `json:"role" binding:"required"`
 }
 
@@ -29,4 +40,72 @@ type UpdateStaffRequest struct {
 	Nama  *string `json:"nama"`
 	Email *string `json:"email" binding:"omitempty,email"`
 	Role  *string `json:"role"`
-}
\ No newline at end of file
+}
+
+// ChangePasswordRequest is the input for a staff member changing their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ForgotPasswordRequest is the input for requesting a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest is the input for setting a new password with a reset token from email
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// PasswordResetToken is a single-use password reset token. Only the SHA-256 hash is stored.
+type PasswordResetToken struct {
+	TokenHash string     `json:"-"`
+	StaffID   string     `json:"staff_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// StaffTOTP is the TOTP second factor of a staff member. EnabledAt is nil until the enrollment
+// is confirmed with a first code.
+type StaffTOTP struct {
+	StaffID     string     `json:"staff_id"`
+	Secret      string     `json:"-"`
+	LastCounter int64      `json:"-"` // Periode kode terakhir yang dipakai, untuk menolak kode ulang
+	EnabledAt   *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TwoFactorEnrollment is returned when a staff member starts TOTP enrollment. OtpauthURI is
+// shown as a QR code; Secret is for typing it into the authenticator app manually.
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatus is the 2FA state of the logged-in staff member
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"` // Diwajibkan untuk role staf ini
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorCodeRequest carries a 6-digit TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// LoginVerifyRequest is the second login step: the challenge token from POST /auth/login and a code
+type LoginVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest confirms turning off 2FA with the password and a current code
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// TaxImportResult is the response of importing e-Bupot/e-Faktur exports into a monthly job
+type TaxImportResult struct {
+	JobID  string                 `json:"job_id"`
+	Files  []TaxImportFileSummary `json:"files"`
+	Totals []TaxImportTotal       `json:"totals"`
+	Errors []TaxImportLineError   `json:"errors"` // Baris yang tidak ikut dihitung
+}
+
+// TaxImportFileSummary summarizes one uploaded export file
+type TaxImportFileSummary struct {
+	FileName      string `json:"file_name"`
+	Format        string `json:"format"`
+	LinesRead     int    `json:"lines_read"`
+	LinesImported int    `json:"lines_imported"`
+}
+
+// TaxImportTotal is the computed total for one tax type and the report it was written to
+type TaxImportTotal struct {
+	TaxType       string  `json:"tax_type"`
+	LineCount     int     `json:"line_count"`
+	TaxBase       float64 `json:"tax_base"`
+	TaxAmount     float64 `json:"tax_amount"`     // Untuk PPN: keluaran dikurangi masukan yang dapat dikreditkan
+	BillingAmount float64 `json:"billing_amount"` // Nilai yang disimpan ke laporan (tidak pernah negatif)
+	ReportID      string  `json:"report_id"`
+	Action        string  `json:"action"` // "created" atau "updated"
+	// SourceFiles lists every file counted in the total, including files imported earlier
+	SourceFiles []string `json:"source_files"`
+}
+
+// TaxImportSource is the total one imported file contributed to a monthly tax report
+type TaxImportSource struct {
+	FileName  string
+	LineCount int
+	TaxBase   float64
+	TaxAmount float64
+}
+
+// TaxImportLineError describes a line that could not be imported
+type TaxImportLineError struct {
+	FileName  string `json:"file_name"`
+	Line      int    `json:"line"`
+	Reference string `json:"reference,omitempty"`
+	Reason    string `json:"reason"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Status laporan pajak yang dipakai di monthly_tax_reports dan annual_tax_reports
+const (
+	ReportStatusPending        = "Pending"
+	ReportStatusPendingPayment = "Pending Payment"
+	ReportStatusPaid           = "Paid"
+)
+
+// IsPaidReportStatus reports whether a report status means the tax has been paid.
+// Status ditulis bebas oleh user, jadi beberapa variasi bahasa Indonesia juga dikenali.
+func IsPaidReportStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "paid", "lunas", "dibayar", "sudah bayar", "sudah dibayar":
+		return true
+	}
+	return false
+}
+
+// TaxPaymentEvidence holds the structured payment evidence of a tax report.
+// Kode billing dan jumlah yang dibayar tetap memakai kolom billing_code dan payment_amount.
+type TaxPaymentEvidence struct {
+	BillingCodeExpiresAt *time.Time `json:"billing_code_expires_at"`
+	BillingAmount        *float64   `json:"billing_amount"`
+	Ntpn                 string     `json:"ntpn"`
+	PaymentChannel       string     `json:"payment_channel"` // Bank/pos persepsi tempat pembayaran
+	AmountMismatch       bool       `json:"amount_mismatch"` // Dihitung: billing_amount != payment_amount
+}
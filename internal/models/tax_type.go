@@ -0,0 +1,39 @@
+package models
+
+// Jenis pajak pada laporan pajak bulanan (monthly_tax_reports.tax_type).
+// Sesuai dengan layanan pajak yang dicentang pada data klien.
+const (
+	TaxTypePphFinalUmkm = "PPH_FINAL_UMKM"
+	TaxTypePph25        = "PPH_25"
+	TaxTypePph21        = "PPH_21"
+	TaxTypePphUnifikasi = "PPH_UNIFIKASI"
+	TaxTypePpn          = "PPN"
+)
+
+// MonthlyTaxTypes lists the monthly tax types in display order.
+var MonthlyTaxTypes = []string{TaxTypePphFinalUmkm, TaxTypePph25, TaxTypePph21, TaxTypePphUnifikasi, TaxTypePpn}
+
+// SubscribedMonthlyTaxTypes returns the monthly tax types a client subscribes to,
+// based on the service flags on the client record.
+func SubscribedMonthlyTaxTypes(client Client) []string {
+	var taxTypes []string
+	for _, taxType := range MonthlyTaxTypes {
+		var subscribed bool
+		switch taxType {
+		case TaxTypePphFinalUmkm:
+			subscribed = client.PphFinalUmkm
+		case TaxTypePph25:
+			subscribed = client.Pph25
+		case TaxTypePph21:
+			subscribed = client.Pph21
+		case TaxTypePphUnifikasi:
+			subscribed = client.PphUnifikasi
+		case TaxTypePpn:
+			subscribed = client.Ppn
+		}
+		if subscribed {
+			taxTypes = append(taxTypes, taxType)
+		}
+	}
+	return taxTypes
+}
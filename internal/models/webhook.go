@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis event webhook
+const (
+	WebhookEventJobStatusChanged = "job.status_changed"
+	WebhookEventInvoiceCreated   = "invoice.created"
+	WebhookEventInvoicePaid      = "invoice.paid"
+	WebhookEventClientCreated    = "client.created"
+)
+
+// WebhookEventTypes adalah semua event yang bisa dilanggan.
+var WebhookEventTypes = []string{
+	WebhookEventJobStatusChanged,
+	WebhookEventInvoiceCreated,
+	WebhookEventInvoicePaid,
+	WebhookEventClientCreated,
+}
+
+// IsValidWebhookEvent melaporkan apakah eventType dikenal.
+func IsValidWebhookEvent(eventType string) bool {
+	for _, e := range WebhookEventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "Pending"
+	WebhookDeliverySucceeded = "Succeeded"
+	WebhookDeliveryFailed    = "Failed" // Semua percobaan habis; bisa dikirim ulang manual
+)
+
+// WebhookSubscription is an external endpoint that receives dashboard events
+type WebhookSubscription struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Secret         string    `json:"secret,omitempty"` // Hanya dikembalikan saat dibuat
+	EventTypes     []string  `json:"event_types"`
+	Description    *string   `json:"description"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Subscribes melaporkan apakah subscription aktif dan melanggan eventType.
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	if !s.IsActive {
+		return false
+	}
+	for _, e := range s.EventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWebhookSubscriptionRequest for creating a webhook subscription.
+// If Secret is empty a random one is generated.
+type NewWebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Secret      string   `json:"secret"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Description *string  `json:"description"`
+}
+
+// UpdateWebhookSubscriptionRequest for updating a webhook subscription (PATCH)
+type UpdateWebhookSubscriptionRequest struct {
+	URL         *string   `json:"url" binding:"omitempty,url"`
+	Secret      *string   `json:"secret"`
+	EventTypes  *[]string `json:"event_types" binding:"omitempty,min=1"`
+	Description *string   `json:"description"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+// WebhookEnvelope is the JSON body POSTed to subscribers
+type WebhookEnvelope struct {
+	EventID    string      `json:"event_id"`
+	EventType  string      `json:"event_type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookDelivery is one attempt series of sending an event to a subscription
+type WebhookDelivery struct {
+	DeliveryID     string          `json:"delivery_id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	ResponseBody   *string         `json:"response_body"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// Diisi saat delivery diambil worker untuk dikirim
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookAttemptResult is the outcome of one HTTP attempt, recorded on the delivery
+type WebhookAttemptResult struct {
+	Status         string
+	ResponseStatus *int
+	ResponseBody   *string
+	Error          *string
+	NextAttemptAt  *time.Time // Diisi jika masih Pending
+	AttemptedAt    time.Time
+}
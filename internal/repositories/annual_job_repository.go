@@ -1,32 +1,38 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models" // Pastikan ini adalah modul Anda
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
 )
 
 // AnnualJobRepository defines the interface for annual job data operations
 type AnnualJobRepository interface {
-	CreateAnnualJob(job *models.AnnualJob) error
-	GetAllAnnualJobs(staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error)
-	GetAnnualJobByID(id string, staffIDFilter string, isAdmin bool) (*models.AnnualJob, error)
-	UpdateAnnualJob(job *models.AnnualJob) error
+	// CreateAnnualJob and UpdateAnnualJob write events to the outbox in the same transaction.
+	// A preset job.JobID is used as the new job's ID.
+	CreateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error
+	GetAllAnnualJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error)
+	// EachAnnualJob is the streaming form of GetAllAnnualJobs, see MonthlyJobRepository.EachMonthlyJob.
+	EachAnnualJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.AnnualJob) error) error
+	GetAnnualJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.AnnualJob, error)
+	UpdateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error
 
 	// Methods for Annual Tax Reports (SPT Tahunan)
-	CreateAnnualTaxReport(report *models.AnnualTaxReport) error
-	GetAnnualTaxReportByID(reportID string) (*models.AnnualTaxReport, error)
-	UpdateAnnualTaxReport(report *models.AnnualTaxReport) error
-	DeleteAnnualTaxReport(reportID string) error
+	CreateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error
+	GetAnnualTaxReportByID(ctx context.Context, reportID string) (*models.AnnualTaxReport, error)
+	UpdateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error
+	DeleteAnnualTaxReport(ctx context.Context, reportID string) error
 
 	// Methods for Annual Dividend Reports (Investasi Dividen)
-	CreateAnnualDividendReport(report *models.AnnualDividendReport) error
-	GetAnnualDividendReportByID(reportID string) (*models.AnnualDividendReport, error)
-	UpdateAnnualDividendReport(report *models.AnnualDividendReport) error
-	DeleteAnnualDividendReport(reportID string) error
-	GetAnnualJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error)
+	CreateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error
+	GetAnnualDividendReportByID(ctx context.Context, reportID string) (*models.AnnualDividendReport, error)
+	UpdateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error
+	DeleteAnnualDividendReport(ctx context.Context, reportID string) error
+	GetAnnualJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error)
 }
 
 // annualJobRepository implements AnnualJobRepository interface
@@ -41,8 +47,8 @@ func NewAnnualJobRepository(db *sql.DB) AnnualJobRepository {
 
 // CreateAnnualJob inserts a new annual job and its associated reports into the database.
 // This operation is wrapped in a transaction.
-func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
-	tx, err := r.db.Begin()
+func (r *annualJobRepository) CreateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -57,9 +63,9 @@ func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
 
 	// 1. Insert into annual_jobs table
 	jobQuery := `INSERT INTO annual_jobs (
-		client_id, job_year, assigned_pic_staff_sigma_id, overall_status, created_at, updated_at
+		client_id, job_year, assigned_pic_staff_sigma_id, overall_status, created_at, updated_at, job_id
 	) VALUES (
-		$1, $2, $3, $4, $5, $6
+		$1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, '')::uuid, gen_random_uuid())
 	) RETURNING job_id, created_at, updated_at`
 
 	if job.CreatedAt.IsZero() {
@@ -67,9 +73,9 @@ func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
 	}
 	job.UpdatedAt = time.Now()
 
-	err = tx.QueryRow(jobQuery,
+	err = tx.QueryRowContext(ctx, jobQuery,
 		job.ClientID, job.JobYear, assignedPicStaffSigmaID, job.OverallStatus,
-		job.CreatedAt, job.UpdatedAt,
+		job.CreatedAt, job.UpdatedAt, job.JobID,
 	).Scan(&job.JobID, &job.CreatedAt, &job.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create annual job: %w", err)
@@ -85,19 +91,22 @@ func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
 		report.UpdatedAt = time.Now()
 
 		taxReportQuery := `INSERT INTO annual_tax_reports (
-			job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at
+			job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at,
+			billing_code_expires_at, billing_amount, ntpn, payment_channel
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		) RETURNING report_id, created_at, updated_at`
 
-		err := tx.QueryRow(taxReportQuery,
+		err := tx.QueryRowContext(ctx, taxReportQuery,
 			report.JobID, report.BillingCode, report.PaymentDate, report.PaymentAmount,
 			report.ReportDate, report.ReportStatus,
 			report.CreatedAt, report.UpdatedAt,
+			report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
 		).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to create annual tax report for job %s: %w", job.JobID, err)
 		}
+		report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 	}
 
 	// 3. Insert associated Annual Dividend Report (if provided)
@@ -115,7 +124,7 @@ func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
 			$1, $2, $3, $4, $5, $6
 		) RETURNING report_id, created_at, updated_at`
 
-		err := tx.QueryRow(dividendReportQuery,
+		err := tx.QueryRowContext(ctx, dividendReportQuery,
 			report.JobID, report.IsReported, report.ReportDate, report.ReportStatus,
 			report.CreatedAt, report.UpdatedAt,
 		).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
@@ -124,11 +133,28 @@ func (r *annualJobRepository) CreateAnnualJob(job *models.AnnualJob) error {
 		}
 	}
 
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
 	return tx.Commit() // Commit the transaction
 }
 
 // GetAllAnnualJobs fetches all annual jobs with their associated client and reports.
-func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) {
+func (r *annualJobRepository) GetAllAnnualJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) {
+	var annualJobsList []models.AnnualJob
+	err := r.EachAnnualJob(ctx, staffIDFilter, isAdmin, func(job *models.AnnualJob) error {
+		annualJobsList = append(annualJobsList, *job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return annualJobsList, nil
+}
+
+// EachAnnualJob fetches all annual jobs with their associated client and reports, calling fn
+// for each job as soon as its rows are read.
+func (r *annualJobRepository) EachAnnualJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.AnnualJob) error) error {
 	query := `
 	SELECT
 		aj.job_id, aj.client_id, c.client_name, c.npwp_client, aj.job_year,
@@ -138,6 +164,7 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 		atr.report_id AS atr_report_id, atr.billing_code, atr.payment_date AS atr_payment_date,
 		atr.payment_amount AS atr_payment_amount, atr.report_date AS atr_report_date, atr.report_status AS atr_report_status,
 		atr.created_at AS atr_created_at, atr.updated_at AS atr_updated_at,
+		atr.billing_code_expires_at, atr.billing_amount, atr.ntpn, atr.payment_channel,
 
 		adr.report_id AS adr_report_id, adr.is_reported, adr.report_date AS adr_report_date, adr.report_status AS adr_report_status,
 		adr.created_at AS adr_created_at, adr.updated_at AS adr_updated_at
@@ -156,16 +183,15 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 		paramCounter++
 	}
 
-	query += " ORDER BY aj.job_year DESC, aj.created_at DESC;"
+	query += " ORDER BY aj.job_year DESC, aj.created_at DESC, aj.job_id;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all annual jobs: %w", err)
+		return fmt.Errorf("failed to get all annual jobs: %w", err)
 	}
 	defer rows.Close()
 
-	annualJobsMap := make(map[string]*models.AnnualJob)
-	var annualJobsList []models.AnnualJob
+	var job *models.AnnualJob // Job yang sedang dibaca; barisnya berurutan karena ORDER BY job_id
 
 	for rows.Next() {
 		var (
@@ -190,6 +216,7 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 			atrReportStatus   sql.NullString
 			atrCreatedAt      sql.NullTime
 			atrUpdatedAt      sql.NullTime
+			atrPayment        taxPaymentScan
 
 			// Annual Dividend Report fields (nullable from LEFT JOIN)
 			adrReportID       sql.NullString
@@ -207,16 +234,21 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 
 			&atrReportID, &atrBillingCode, &atrPaymentDate, &atrPaymentAmount, &atrReportDate, &atrReportStatus,
 			&atrCreatedAt, &atrUpdatedAt,
+			&atrPayment.billingCodeExpiresAt, &atrPayment.billingAmount, &atrPayment.ntpn, &atrPayment.paymentChannel,
 
 			&adrReportID, &adrIsReported, &adrReportDate, &adrReportStatus,
 			&adrCreatedAt, &adrUpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan annual job row: %w", err)
+			return fmt.Errorf("failed to scan annual job row: %w", err)
 		}
 
-		job, ok := annualJobsMap[jobID]
-		if !ok {
+		if job == nil || job.JobID != jobID {
+			if job != nil {
+				if err := fn(job); err != nil {
+					return err
+				}
+			}
 			job = &models.AnnualJob{
 				JobID:           jobID,
 				ClientID:        clientID,
@@ -240,8 +272,6 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 			} else {
 				job.ProofOfWorkURL = nil // Penting: set nil jika NULL di DB
 			}
-			annualJobsMap[jobID] = job
-			annualJobsList = append(annualJobsList, *job)
 		}
 
 		// Add Annual Tax Report if exists
@@ -269,6 +299,7 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 			} else {
 				taxReport.ReportDate = nil
 			}
+			atrPayment.apply(&taxReport.TaxPaymentEvidence, taxReport.PaymentAmount)
 			job.TaxReports = append(job.TaxReports, taxReport)
 			
 		}
@@ -293,14 +324,17 @@ func (r *annualJobRepository) GetAllAnnualJobs(staffIDFilter string, isAdmin boo
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration: %w", err)
+		return fmt.Errorf("error during rows iteration: %w", err)
 	}
 
-	return annualJobsList, nil
+	if job != nil {
+		return fn(job)
+	}
+	return nil
 }
 
 // GetAnnualJobByID fetches a single annual job by its ID with associated client and reports.
-func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string, isAdmin bool) (*models.AnnualJob, error) {
+func (r *annualJobRepository) GetAnnualJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.AnnualJob, error) {
 	query := `
 	SELECT
 		aj.job_id, aj.client_id, c.client_name, c.npwp_client, aj.job_year,
@@ -310,6 +344,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 		atr.report_id AS atr_report_id, atr.billing_code, atr.payment_date AS atr_payment_date,
 		atr.payment_amount AS atr_payment_amount, atr.report_date AS atr_report_date, atr.report_status AS atr_report_status,
 		atr.created_at AS atr_created_at, atr.updated_at AS atr_updated_at,
+		atr.billing_code_expires_at, atr.billing_amount, atr.ntpn, atr.payment_channel,
 
 		adr.report_id AS adr_report_id, adr.is_reported, adr.report_date AS adr_report_date, adr.report_status AS adr_report_status,
 		adr.created_at AS adr_created_at, adr.updated_at AS adr_updated_at
@@ -330,7 +365,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 
 	query += " ORDER BY atr.report_id ASC, adr.report_id ASC;" // Order reports for consistent scanning
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get annual job by ID: %w", err)
 	}
@@ -359,6 +394,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 			atrReportStatus   sql.NullString
 			atrCreatedAt      sql.NullTime
 			atrUpdatedAt      sql.NullTime
+			atrPayment        taxPaymentScan
 
 			adrReportID       sql.NullString
 			adrIsReported     sql.NullBool
@@ -375,6 +411,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 
 			&atrReportID, &atrBillingCode, &atrPaymentDate, &atrPaymentAmount, &atrReportDate, &atrReportStatus,
 			&atrCreatedAt, &atrUpdatedAt,
+			&atrPayment.billingCodeExpiresAt, &atrPayment.billingAmount, &atrPayment.ntpn, &atrPayment.paymentChannel,
 
 			&adrReportID, &adrIsReported, &adrReportDate, &adrReportStatus,
 			&adrCreatedAt, &adrUpdatedAt,
@@ -435,6 +472,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 			} else {
 				taxReport.ReportDate = nil
 			}
+			atrPayment.apply(&taxReport.TaxPaymentEvidence, taxReport.PaymentAmount)
 			annualJob.TaxReports = append(annualJob.TaxReports, taxReport)
 		}
 
@@ -468,7 +506,7 @@ func (r *annualJobRepository) GetAnnualJobByID(id string, staffIDFilter string,
 	return annualJob, nil
 }
 
-func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) { // <-- IMPLEMENTASI BARU
+func (r *annualJobRepository) GetAnnualJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) { // <-- IMPLEMENTASI BARU
 	query := `
 	SELECT
 		aj.job_id, aj.client_id, c.client_name, c.npwp_client, aj.job_year,
@@ -478,6 +516,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 		atr.report_id AS atr_report_id, atr.billing_code, atr.payment_date AS atr_payment_date,
 		atr.payment_amount AS atr_payment_amount, atr.report_date AS atr_report_date, atr.report_status AS atr_report_status,
 		atr.created_at AS atr_created_at, atr.updated_at AS atr_updated_at,
+		atr.billing_code_expires_at, atr.billing_amount, atr.ntpn, atr.payment_channel,
 
 		adr.report_id AS adr_report_id, adr.is_reported, adr.report_date AS adr_report_date, adr.report_status AS adr_report_status,
 		adr.created_at AS adr_created_at, adr.updated_at AS adr_updated_at
@@ -499,7 +538,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 
 	query += " ORDER BY aj.job_year DESC, aj.created_at DESC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get annual jobs by client ID: %w", err)
 	}
@@ -507,7 +546,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 
 	// Bagian rekonstruksi map dan slice sama persis dengan GetAllAnnualJobs
 	annualJobsMap := make(map[string]*models.AnnualJob)
-	var annualJobsList []models.AnnualJob
+	var annualJobIDs []string // Urutan dari query
 
 	for rows.Next() {
 		var (
@@ -530,6 +569,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 			atrReportStatus   sql.NullString
 			atrCreatedAt      sql.NullTime
 			atrUpdatedAt      sql.NullTime
+			atrPayment        taxPaymentScan
 
 			adrReportID       sql.NullString
 			adrIsReported     sql.NullBool
@@ -546,6 +586,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 
 			&atrReportID, &atrBillingCode, &atrPaymentDate, &atrPaymentAmount, &atrReportDate, &atrReportStatus,
 			&atrCreatedAt, &atrUpdatedAt,
+			&atrPayment.billingCodeExpiresAt, &atrPayment.billingAmount, &atrPayment.ntpn, &atrPayment.paymentChannel,
 
 			&adrReportID, &adrIsReported, &adrReportDate, &adrReportStatus,
 			&adrCreatedAt, &adrUpdatedAt,
@@ -575,7 +616,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 				job.AssignedPicStaffSigmaName = assignedPicStaffSigmaName.String
 			}
 			annualJobsMap[jobID] = job
-			annualJobsList = append(annualJobsList, *job)
+			annualJobIDs = append(annualJobIDs, jobID)
 		}
 
 		if atrReportID.Valid {
@@ -602,6 +643,7 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 			} else {
 				taxReport.ReportDate = nil
 			}
+			atrPayment.apply(&taxReport.TaxPaymentEvidence, taxReport.PaymentAmount)
 			job.TaxReports = append(job.TaxReports, taxReport)
 		}
 
@@ -627,11 +669,21 @@ func (r *annualJobRepository) GetAnnualJobsByClientID(clientID string, staffIDFi
 		return nil, fmt.Errorf("error during rows iteration for annual jobs by client ID: %w", err)
 	}
 
+	// Salin setelah semua baris dibaca; annualJobsMap berisi pointer yang masih ditambah laporannya
+	var annualJobsList []models.AnnualJob
+	for _, id := range annualJobIDs {
+		annualJobsList = append(annualJobsList, *annualJobsMap[id])
+	}
 	return annualJobsList, nil
 }
 
 // UpdateAnnualJob updates only the main fields of an annual job.
-func (r *annualJobRepository) UpdateAnnualJob(job *models.AnnualJob) error {
+func (r *annualJobRepository) UpdateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	var assignedPicStaffSigmaID sql.NullString
 	if job.AssignedPicStaffSigmaID != "" {
@@ -654,7 +706,7 @@ func (r *annualJobRepository) UpdateAnnualJob(job *models.AnnualJob) error {
 
 	job.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err = tx.ExecContext(ctx, query,
 		job.ClientID, job.JobYear, assignedPicStaffSigmaID,
 		job.OverallStatus, proofOfWorkURL, job.UpdatedAt, job.JobID,
 	)
@@ -662,15 +714,19 @@ func (r *annualJobRepository) UpdateAnnualJob(job *models.AnnualJob) error {
 	if err != nil {
 		return fmt.Errorf("failed to update annual job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // CreateAnnualTaxReport inserts a new annual tax report for an existing annual job
-func (r *annualJobRepository) CreateAnnualTaxReport(report *models.AnnualTaxReport) error {
+func (r *annualJobRepository) CreateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error {
 	query := `INSERT INTO annual_tax_reports (
-		job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at
+		job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at,
+		billing_code_expires_at, billing_amount, ntpn, payment_channel
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 	) RETURNING report_id, created_at, updated_at`
 
 	if report.CreatedAt.IsZero() {
@@ -678,30 +734,36 @@ func (r *annualJobRepository) CreateAnnualTaxReport(report *models.AnnualTaxRepo
 	}
 	report.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		report.JobID, report.BillingCode, report.PaymentDate, report.PaymentAmount,
 		report.ReportDate, report.ReportStatus,
 		report.CreatedAt, report.UpdatedAt,
+		report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
 	).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create annual tax report: %w", err)
 	}
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 	return nil
 }
 
 // GetAnnualTaxReportByID fetches a single annual tax report by its ID.
-func (r *annualJobRepository) GetAnnualTaxReportByID(reportID string) (*models.AnnualTaxReport, error) {
+func (r *annualJobRepository) GetAnnualTaxReportByID(ctx context.Context, reportID string) (*models.AnnualTaxReport, error) {
 	query := `SELECT
-		report_id, job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at
+		report_id, job_id, billing_code, payment_date, payment_amount, report_date, report_status, created_at, updated_at,
+		billing_code_expires_at, billing_amount, ntpn, payment_channel
 	FROM annual_tax_reports WHERE report_id = $1`
 
 	var report models.AnnualTaxReport
 	var paymentDate, reportDate sql.NullTime
 	var paymentAmount sql.NullFloat64
+	var billingCode sql.NullString
+	var payment taxPaymentScan
 
-	err := r.db.QueryRow(query, reportID).Scan(
-		&report.ReportID, &report.JobID, &report.BillingCode, &paymentDate, &paymentAmount,
-		&report.ReportDate, &report.ReportStatus, &report.CreatedAt, &report.UpdatedAt,
+	err := r.db.QueryRowContext(ctx, query, reportID).Scan(
+		&report.ReportID, &report.JobID, &billingCode, &paymentDate, &paymentAmount,
+		&reportDate, &report.ReportStatus, &report.CreatedAt, &report.UpdatedAt,
+		&payment.billingCodeExpiresAt, &payment.billingAmount, &payment.ntpn, &payment.paymentChannel,
 	)
 
 	if err != nil {
@@ -727,33 +789,40 @@ func (r *annualJobRepository) GetAnnualTaxReportByID(reportID string) (*models.A
 		report.ReportDate = nil
 	}
 
+	report.BillingCode = billingCode.String
+	payment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
+
 	return &report, nil
 }
 
 // UpdateAnnualTaxReport updates an existing annual tax report
-func (r *annualJobRepository) UpdateAnnualTaxReport(report *models.AnnualTaxReport) error {
+func (r *annualJobRepository) UpdateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error {
 	query := `UPDATE annual_tax_reports SET
 		billing_code = $1, payment_date = $2, payment_amount = $3, report_date = $4,
-		report_status = $5, updated_at = $6
-	WHERE report_id = $7`
+		report_status = $5, updated_at = $6,
+		billing_code_expires_at = $7, billing_amount = $8, ntpn = $9, payment_channel = $10
+	WHERE report_id = $11`
 
 	report.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		report.BillingCode, report.PaymentDate, report.PaymentAmount, report.ReportDate,
-		report.ReportStatus, report.UpdatedAt, report.ReportID,
+		report.ReportStatus, report.UpdatedAt,
+		report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
+		report.ReportID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update annual tax report: %w", err)
 	}
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 	return nil
 }
 
 // DeleteAnnualTaxReport deletes an annual tax report by its ID
-func (r *annualJobRepository) DeleteAnnualTaxReport(reportID string) error {
+func (r *annualJobRepository) DeleteAnnualTaxReport(ctx context.Context, reportID string) error {
 	query := `DELETE FROM annual_tax_reports WHERE report_id = $1`
-	_, err := r.db.Exec(query, reportID)
+	_, err := r.db.ExecContext(ctx, query, reportID)
 	if err != nil {
 		return fmt.Errorf("failed to delete annual tax report: %w", err)
 	}
@@ -761,7 +830,7 @@ func (r *annualJobRepository) DeleteAnnualTaxReport(reportID string) error {
 }
 
 // CreateAnnualDividendReport inserts a new annual dividend report for an existing annual job
-func (r *annualJobRepository) CreateAnnualDividendReport(report *models.AnnualDividendReport) error {
+func (r *annualJobRepository) CreateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error {
 	query := `INSERT INTO annual_dividend_reports (
 		job_id, is_reported, report_date, report_status, created_at, updated_at
 	) VALUES (
@@ -773,7 +842,7 @@ func (r *annualJobRepository) CreateAnnualDividendReport(report *models.AnnualDi
 	}
 	report.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		report.JobID, report.IsReported, report.ReportDate, report.ReportStatus,
 		report.CreatedAt, report.UpdatedAt,
 	).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
@@ -784,7 +853,7 @@ func (r *annualJobRepository) CreateAnnualDividendReport(report *models.AnnualDi
 }
 
 // GetAnnualDividendReportByID fetches a single annual dividend report by its ID.
-func (r *annualJobRepository) GetAnnualDividendReportByID(reportID string) (*models.AnnualDividendReport, error) {
+func (r *annualJobRepository) GetAnnualDividendReportByID(ctx context.Context, reportID string) (*models.AnnualDividendReport, error) {
 	query := `SELECT
 		report_id, job_id, is_reported, report_date, report_status, created_at, updated_at
 	FROM annual_dividend_reports WHERE report_id = $1`
@@ -793,7 +862,7 @@ func (r *annualJobRepository) GetAnnualDividendReportByID(reportID string) (*mod
 	var reportDate sql.NullTime
 	var isReported sql.NullBool // Tambahkan ini
 
-	err := r.db.QueryRow(query, reportID).Scan(
+	err := r.db.QueryRowContext(ctx, query, reportID).Scan(
 		&report.ReportID, &report.JobID, &isReported, &reportDate, &report.ReportStatus,
 		&report.CreatedAt, &report.UpdatedAt,
 	)
@@ -817,14 +886,14 @@ func (r *annualJobRepository) GetAnnualDividendReportByID(reportID string) (*mod
 }
 
 // UpdateAnnualDividendReport updates an existing annual dividend report
-func (r *annualJobRepository) UpdateAnnualDividendReport(report *models.AnnualDividendReport) error {
+func (r *annualJobRepository) UpdateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error {
 	query := `UPDATE annual_dividend_reports SET
 		is_reported = $1, report_date = $2, report_status = $3, updated_at = $4
 	WHERE report_id = $5`
 
 	report.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		report.IsReported, report.ReportDate, report.ReportStatus, report.UpdatedAt, report.ReportID,
 	)
 
@@ -835,9 +904,9 @@ func (r *annualJobRepository) UpdateAnnualDividendReport(report *models.AnnualDi
 }
 
 // DeleteAnnualDividendReport deletes an annual dividend report by its ID
-func (r *annualJobRepository) DeleteAnnualDividendReport(reportID string) error {
+func (r *annualJobRepository) DeleteAnnualDividendReport(ctx context.Context, reportID string) error {
 	query := `DELETE FROM annual_dividend_reports WHERE report_id = $1`
-	_, err := r.db.Exec(query, reportID)
+	_, err := r.db.ExecContext(ctx, query, reportID)
 	if err != nil {
 		return fmt.Errorf("failed to delete annual dividend report: %w", err)
 	}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// AuditRepository defines data operations for the audit log
+type AuditRepository interface {
+	// RecordEvent stores a domain event in the audit log. Recording the same event twice is a no-op.
+	RecordEvent(ctx context.Context, event *models.DomainEvent) error
+	GetAuditLog(ctx context.Context, aggregateType, aggregateID string, limit int, before *time.Time) ([]models.AuditEntry, error)
+}
+
+// auditRepository implements AuditRepository interface
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// RecordEvent inserts the event keyed by its ID, so redelivered events are ignored
+func (r *auditRepository) RecordEvent(ctx context.Context, event *models.DomainEvent) error {
+	query := `INSERT INTO audit_log (event_id, event_type, aggregate_type, aggregate_id, actor_staff_id, payload, occurred_at, recorded_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (event_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, event.EventID, event.EventType, event.AggregateType, event.AggregateID,
+		event.ActorStaffID, []byte(event.Payload), event.OccurredAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// GetAuditLog returns the newest audit entries, optionally for one aggregate and before a cursor
+func (r *auditRepository) GetAuditLog(ctx context.Context, aggregateType, aggregateID string, limit int, before *time.Time) ([]models.AuditEntry, error) {
+	query := `SELECT a.event_id, a.event_type, a.aggregate_type, a.aggregate_id, a.actor_staff_id, s.nama,
+		a.payload, a.occurred_at, a.recorded_at
+	FROM audit_log AS a
+	LEFT JOIN staffs AS s ON a.actor_staff_id = s.staff_id
+	WHERE 1=1`
+	args := []interface{}{}
+	if aggregateType != "" {
+		args = append(args, aggregateType)
+		query += fmt.Sprintf(" AND a.aggregate_type = $%d", len(args))
+	}
+	if aggregateID != "" {
+		args = append(args, aggregateID)
+		query += fmt.Sprintf(" AND a.aggregate_id = $%d", len(args))
+	}
+	if before != nil {
+		args = append(args, *before)
+		query += fmt.Sprintf(" AND a.occurred_at < $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY a.occurred_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		var actorID, actorName sql.NullString
+		var payload []byte
+		err := rows.Scan(&e.EventID, &e.EventType, &e.AggregateType, &e.AggregateID, &actorID, &actorName,
+			&payload, &e.OccurredAt, &e.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log row: %w", err)
+		}
+		if actorID.Valid {
+			e.ActorStaffID = &actorID.String
+		}
+		if actorName.Valid {
+			e.ActorName = &actorName.String
+		}
+		e.Payload = payload
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for audit log: %w", err)
+	}
+	return entries, nil
+}
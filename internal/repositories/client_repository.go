@@ -1,21 +1,33 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time" // Import time package
 
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils" // For password hashing
+	"github.com/lib/pq"
 )
 
 // ClientRepository defines the interface for client data operations
 type ClientRepository interface {
-	CreateClient(client *models.Client) error
-	GetAllClients(staffIDFilter string, isAdmin bool) ([]models.Client, error)
-	GetClientByID(id string, staffIDFilter string, isAdmin bool) (*models.Client, error) 
-	UpdateClient(client *models.Client) error
-	DeleteClient(id string) error
+	// CreateClient and ImportClients write a ClientCreated event to the outbox for every new
+	// client, in the same transaction.
+	CreateClient(ctx context.Context, client *models.Client) error
+	GetAllClients(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Client, error)
+	GetClientByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Client, error)
+	UpdateClient(ctx context.Context, client *models.Client) error
+	DeleteClient(ctx context.Context, id string) error
+	GetClientsByNpwp(ctx context.Context, canonicals []string) ([]models.Client, error)
+	SearchClients(ctx context.Context, query string, staffIDFilter string, isAdmin bool) ([]models.Client, error)
+	// EachClient streams the clients of SearchClients, or of GetAllClients when query is empty,
+	// see MonthlyJobRepository.EachMonthlyJob.
+	EachClient(ctx context.Context, query string, staffIDFilter string, isAdmin bool, fn func(*models.Client) error) error
+	ImportClients(ctx context.Context, creates []*models.Client, updates []*models.Client) error
 }
 
 // clientRepository implements ClientRepository interface
@@ -29,13 +41,13 @@ func NewClientRepository(db *sql.DB) ClientRepository {
 }
 
 // CreateClient inserts a new client into the database
-func (r *clientRepository) CreateClient(client *models.Client) error {
-    // Hash the password before saving
-    hashedPassword, err := utils.HashPassword(client.CoretaxPasswordHashed) // Use CoretaxPasswordHashed as the input for hashing
-    if err != nil {
-        return fmt.Errorf("failed to hash password: %w", err)
-    }
-    client.CoretaxPasswordHashed = hashedPassword // Update the struct field with the hashed password
+func (r *clientRepository) CreateClient(ctx context.Context, client *models.Client) error {
+	// Hash the password before saving
+	hashedPassword, err := utils.HashPassword(client.CoretaxPasswordHashed) // Use CoretaxPasswordHashed as the input for hashing
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	client.CoretaxPasswordHashed = hashedPassword // Update the struct field with the hashed password
 
 	var picStaffSigmaID sql.NullString
 	if client.PicStaffSigmaID != "" {
@@ -48,9 +60,9 @@ func (r *clientRepository) CreateClient(client *models.Client) error {
 		client_name, npwp_client, address_client, membership_status, phone_client, email_client,
 		pic_client, djp_online_username, coretax_username, coretax_password_hashed, pic_staff_sigma_id,
 		client_category, pph_final_umkm, pph_25, pph_21, pph_unifikasi, ppn, spt_tahunan,
-		pelaporan_deviden, laporan_keuangan, investasi_deviden, created_at, updated_at
+		pelaporan_deviden, laporan_keuangan, investasi_deviden, created_at, updated_at, npwp_canonical
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
 	) RETURNING client_id, created_at, updated_at`
 
 	// Set current time for CreatedAt and UpdatedAt if not already set
@@ -59,122 +71,53 @@ func (r *clientRepository) CreateClient(client *models.Client) error {
 	}
 	client.UpdatedAt = time.Now()
 
-	err = r.db.QueryRow(query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
 		client.ClientName, client.NpwpClient, client.AddressClient, client.MembershipStatus, client.PhoneClient,
 		client.EmailClient, client.PicClient, client.DjpOnlineUsername, client.CoretaxUsername,
 		client.CoretaxPasswordHashed, picStaffSigmaID, client.ClientCategory,
 		client.PphFinalUmkm, client.Pph25, client.Pph21, client.PphUnifikasi, client.Ppn,
 		client.SptTahunan, client.PelaporanDeviden, client.LaporanKeuangan, client.InvestasiDeviden,
-		client.CreatedAt, client.UpdatedAt,
+		client.CreatedAt, client.UpdatedAt, client.NpwpCanonical,
 	).Scan(&client.ClientID, &client.CreatedAt, &client.UpdatedAt) // Scan the returned client_id and timestamps
 
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
-	return nil
-}
-
-// GetAllClients fetches all clients from the database
-func (r *clientRepository) GetAllClients(staffIDFilter string, isAdmin bool) ([]models.Client, error) {
-	query := `SELECT
-		c.client_id, c.client_name, c.npwp_client, c.address_client, c.membership_status, c.phone_client, c.email_client,
-		c.pic_client, c.djp_online_username, c.coretax_username, c.coretax_password_hashed, c.pic_staff_sigma_id,
-		s.nama AS pic_staff_sigma_name,
-		c.client_category, c.pph_final_umkm, c.pph_25, c.pph_21, c.pph_unifikasi, c.ppn, c.spt_tahunan,
-		c.pelaporan_deviden, c.laporan_keuangan, c.investasi_deviden, c.created_at, c.updated_at
-	FROM clients AS c
-	LEFT JOIN staffs AS s ON c.pic_staff_sigma_id = s.staff_id`
-
-	args := []interface{}{}
-	paramCounter := 1
-
-	if !isAdmin && staffIDFilter != "" { // Jika bukan admin DAN ada staffID yang difilter
-		query += fmt.Sprintf(" WHERE c.pic_staff_sigma_id = $%d", paramCounter)
-		args = append(args, staffIDFilter)
-		paramCounter++
-	}
-
-	query += " ORDER BY c.client_name ASC"
 
-	rows, err := r.db.Query(query, args...)
+	// Catat event ClientCreated di outbox, dalam transaksi yang sama
+	created, err := models.NewClientCreatedEvent(*client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all clients: %w", err)
+		return err
 	}
-	defer rows.Close()
-
-	var clients []models.Client
-	for rows.Next() {
-		var client models.Client
-		var picStaffSigmaID sql.NullString // Gunakan sql.NullString untuk ID staf yang bisa NULL
-		var picStaffSigmaName sql.NullString
-
-		// Deklarasi variabel sql.NullBool untuk kolom pajak
-		var pphFinalUmkm sql.NullBool
-		var pph25 sql.NullBool
-		var pph21 sql.NullBool
-		var pphUnifikasi sql.NullBool
-		var ppn sql.NullBool
-		var sptTahunan sql.NullBool
-		var pelaporanDeviden sql.NullBool
-		var laporanKeuangan sql.NullBool
-		var investasiDeviden sql.NullBool
-
-		// --- PERBAIKAN PENTING DI SINI:
-		// HANYA gunakaan variabel sql.NullBool yang baru.
-		// Jangan sertakan lagi &client.PphFinalUmkm, &client.Pph25, dst. di sini.
-		// Total harus ada 25 variabel yang discan.
-		// ---
-		err := rows.Scan(
-			&client.ClientID, &client.ClientName, &client.NpwpClient, &client.AddressClient, &client.MembershipStatus,
-			&client.PhoneClient, &client.EmailClient, &client.PicClient, &client.DjpOnlineUsername,
-			&client.CoretaxUsername, &client.CoretaxPasswordHashed,
-			&picStaffSigmaID, &picStaffSigmaName,
-			&client.ClientCategory,
-			&pphFinalUmkm, &pph25, &pph21, &pphUnifikasi, &ppn, &sptTahunan,
-			&pelaporanDeviden, &laporanKeuangan, &investasiDeviden,
-			&client.CreatedAt, &client.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan client row: %w", err)
-		}
-
-		// Assignment dari sql.NullString ke string
-		if picStaffSigmaID.Valid {
-			client.PicStaffSigmaID = picStaffSigmaID.String
-		} else {
-			client.PicStaffSigmaID = ""
-		}
-		if picStaffSigmaName.Valid {
-			client.PicStaffSigmaName = picStaffSigmaName.String
-		} else {
-			client.PicStaffSigmaName = ""
-		}
-
-		// Assignment dari sql.NullBool ke bool
-		client.PphFinalUmkm = pphFinalUmkm.Bool
-		client.Pph25 = pph25.Bool
-		client.Pph21 = pph21.Bool
-		client.PphUnifikasi = pphUnifikasi.Bool
-		client.Ppn = ppn.Bool
-		client.SptTahunan = sptTahunan.Bool
-		client.PelaporanDeviden = pelaporanDeviden.Bool
-		client.LaporanKeuangan = laporanKeuangan.Bool
-		client.InvestasiDeviden = investasiDeviden.Bool
-
-		clients = append(clients, client) // <-- Hanya ada SATU append di sini
+	if err := writeOutbox(ctx, tx, []models.DomainEvent{created}); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration: %w", err)
+// GetAllClients fetches all clients from the database
+func (r *clientRepository) GetAllClients(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Client, error) {
+	var clients []models.Client
+	err := r.EachClient(ctx, "", staffIDFilter, isAdmin, func(client *models.Client) error {
+		clients = append(clients, *client)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return clients, nil
 }
 
 // GetClientByID fetches a client by their ID from the database
-func (r *clientRepository) GetClientByID(id string, staffIDFilter string, isAdmin bool) (*models.Client, error) {
+func (r *clientRepository) GetClientByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Client, error) {
 	query := `SELECT
-		c.client_id, c.client_name, c.npwp_client, c.address_client, c.membership_status, c.phone_client, c.email_client,
+		c.client_id, c.client_name, c.npwp_client, c.npwp_canonical, c.address_client, c.membership_status, c.phone_client, c.email_client,
 		c.pic_client, c.djp_online_username, c.coretax_username, c.coretax_password_hashed, c.pic_staff_sigma_id, -- UBAH INI
 		s.nama AS pic_staff_sigma_name, -- TAMBAH INI
 		c.client_category, c.pph_final_umkm, c.pph_25, c.pph_21, c.pph_unifikasi, c.ppn, c.spt_tahunan,
@@ -194,9 +137,9 @@ func (r *clientRepository) GetClientByID(id string, staffIDFilter string, isAdmi
 	}
 
 	var client models.Client
-	 var picStaffSigmaID sql.NullString
-    var picStaffSigmaName sql.NullString
-		var pphFinalUmkm sql.NullBool
+	var picStaffSigmaID sql.NullString
+	var picStaffSigmaName sql.NullString
+	var pphFinalUmkm sql.NullBool
 	var pph25 sql.NullBool
 	var pph21 sql.NullBool
 	var pphUnifikasi sql.NullBool
@@ -206,8 +149,8 @@ func (r *clientRepository) GetClientByID(id string, staffIDFilter string, isAdmi
 	var laporanKeuangan sql.NullBool
 	var investasiDeviden sql.NullBool
 
-err := r.db.QueryRow(query, args...).Scan(
-		&client.ClientID, &client.ClientName, &client.NpwpClient, &client.AddressClient, &client.MembershipStatus,
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&client.ClientID, &client.ClientName, &client.NpwpClient, &client.NpwpCanonical, &client.AddressClient, &client.MembershipStatus,
 		&client.PhoneClient, &client.EmailClient, &client.PicClient, &client.DjpOnlineUsername,
 		&client.CoretaxUsername, &client.CoretaxPasswordHashed,
 		&picStaffSigmaID, &picStaffSigmaName,
@@ -223,30 +166,30 @@ err := r.db.QueryRow(query, args...).Scan(
 		}
 		return nil, fmt.Errorf("failed to get client by ID: %w", err)
 	}
-	   if picStaffSigmaID.Valid {
-        client.PicStaffSigmaID = picStaffSigmaID.String
-    }
-    if picStaffSigmaName.Valid {
-        client.PicStaffSigmaName = picStaffSigmaName.String
-    }
-		  client.PphFinalUmkm = pphFinalUmkm.Bool
-    client.Pph25 = pph25.Bool
-    client.Pph21 = pph21.Bool
-    client.PphUnifikasi = pphUnifikasi.Bool
-    client.Ppn = ppn.Bool
-    client.SptTahunan = sptTahunan.Bool
-    client.PelaporanDeviden = pelaporanDeviden.Bool
-    client.LaporanKeuangan = laporanKeuangan.Bool
-    client.InvestasiDeviden = investasiDeviden.Bool
+	if picStaffSigmaID.Valid {
+		client.PicStaffSigmaID = picStaffSigmaID.String
+	}
+	if picStaffSigmaName.Valid {
+		client.PicStaffSigmaName = picStaffSigmaName.String
+	}
+	client.PphFinalUmkm = pphFinalUmkm.Bool
+	client.Pph25 = pph25.Bool
+	client.Pph21 = pph21.Bool
+	client.PphUnifikasi = pphUnifikasi.Bool
+	client.Ppn = ppn.Bool
+	client.SptTahunan = sptTahunan.Bool
+	client.PelaporanDeviden = pelaporanDeviden.Bool
+	client.LaporanKeuangan = laporanKeuangan.Bool
+	client.InvestasiDeviden = investasiDeviden.Bool
 	return &client, nil
 }
 
 // UpdateClient updates an existing client in the database
-func (r *clientRepository) UpdateClient(client *models.Client) error {
-    // Note: We don't hash password here unless it's explicitly updated
-    // If you allow updating password, you'd check if client.CoretaxPasswordHashed
-    // contains a new value (e.g., from NewClientRequest.CoretaxPassword) and hash it.
-    // For now, this assumes CoretaxPasswordHashed from the client struct is the final value.
+func (r *clientRepository) UpdateClient(ctx context.Context, client *models.Client) error {
+	// Note: We don't hash password here unless it's explicitly updated
+	// If you allow updating password, you'd check if client.CoretaxPasswordHashed
+	// contains a new value (e.g., from NewClientRequest.CoretaxPassword) and hash it.
+	// For now, this assumes CoretaxPasswordHashed from the client struct is the final value.
 	var picStaffSigmaID sql.NullString
 	if client.PicStaffSigmaID != "" {
 		picStaffSigmaID = sql.NullString{String: client.PicStaffSigmaID, Valid: true}
@@ -260,18 +203,18 @@ func (r *clientRepository) UpdateClient(client *models.Client) error {
 		coretax_username = $9, coretax_password_hashed = $10, pic_staff_sigma_id = $11, -- UBAH INI
 		client_category = $12, pph_final_umkm = $13, pph_25 = $14, pph_21 = $15,
 		pph_unifikasi = $16, ppn = $17, spt_tahunan = $18, pelaporan_deviden = $19,
-		laporan_keuangan = $20, investasi_deviden = $21, updated_at = $22
-	WHERE client_id = $23`
+		laporan_keuangan = $20, investasi_deviden = $21, updated_at = $22, npwp_canonical = $23
+	WHERE client_id = $24`
 
 	client.UpdatedAt = time.Now() // Update the timestamp
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		client.ClientName, client.NpwpClient, client.AddressClient, client.MembershipStatus,
 		client.PhoneClient, client.EmailClient, client.PicClient, client.DjpOnlineUsername,
 		client.CoretaxUsername, client.CoretaxPasswordHashed, picStaffSigmaID,
 		client.ClientCategory, client.PphFinalUmkm, client.Pph25, client.Pph21,
 		client.PphUnifikasi, client.Ppn, client.SptTahunan, client.PelaporanDeviden,
-		client.LaporanKeuangan, client.InvestasiDeviden, client.UpdatedAt,
+		client.LaporanKeuangan, client.InvestasiDeviden, client.UpdatedAt, client.NpwpCanonical,
 		client.ClientID,
 	)
 
@@ -282,11 +225,233 @@ func (r *clientRepository) UpdateClient(client *models.Client) error {
 }
 
 // DeleteClient deletes a client from the database
-func (r *clientRepository) DeleteClient(id string) error {
+func (r *clientRepository) DeleteClient(ctx context.Context, id string) error {
 	query := `DELETE FROM clients WHERE client_id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete client: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// GetClientsByNpwp fetches clients whose canonical (16-digit) NPWP is in canonicals.
+func (r *clientRepository) GetClientsByNpwp(ctx context.Context, canonicals []string) ([]models.Client, error) {
+	query := `SELECT
+		c.client_id, c.client_name, c.npwp_client, c.npwp_canonical, c.address_client, c.membership_status, c.phone_client, c.email_client,
+		c.pic_client, c.djp_online_username, c.coretax_username, c.coretax_password_hashed, c.pic_staff_sigma_id,
+		s.nama AS pic_staff_sigma_name,
+		c.client_category, c.pph_final_umkm, c.pph_25, c.pph_21, c.pph_unifikasi, c.ppn, c.spt_tahunan,
+		c.pelaporan_deviden, c.laporan_keuangan, c.investasi_deviden, c.created_at, c.updated_at
+	FROM clients AS c
+	LEFT JOIN staffs AS s ON c.pic_staff_sigma_id = s.staff_id
+	WHERE c.npwp_canonical = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(canonicals))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients by NPWP: %w", err)
+	}
+	defer rows.Close()
+	return scanClientRows(rows)
+}
+
+// SearchClients fetches clients whose name or NPWP matches query. A complete NPWP matches
+// by canonical form, so 01.234.567.8-901.000, 012345678901000 and 0012345678901000 find
+// the same client; shorter digit strings match any part of the NPWP.
+func (r *clientRepository) SearchClients(ctx context.Context, query string, staffIDFilter string, isAdmin bool) ([]models.Client, error) {
+	sqlQuery, args := clientListQuery(query, staffIDFilter, isAdmin)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search clients: %w", err)
+	}
+	defer rows.Close()
+	return scanClientRows(rows)
+}
+
+// EachClient fetches the clients of GetAllClients, or of SearchClients when query is not
+// empty, calling fn for each client as soon as it is read.
+func (r *clientRepository) EachClient(ctx context.Context, query string, staffIDFilter string, isAdmin bool, fn func(*models.Client) error) error {
+	sqlQuery, args := clientListQuery(query, staffIDFilter, isAdmin)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get clients: %w", err)
+	}
+	defer rows.Close()
+	return eachClientRow(rows, fn)
+}
+
+// clientListQuery builds the client list query ordered by name. A non-empty query adds the
+// name and NPWP match of SearchClients; non-admins only see the clients they are PIC for.
+func clientListQuery(query string, staffIDFilter string, isAdmin bool) (string, []interface{}) {
+	sqlQuery := `SELECT
+		c.client_id, c.client_name, c.npwp_client, c.npwp_canonical, c.address_client, c.membership_status, c.phone_client, c.email_client,
+		c.pic_client, c.djp_online_username, c.coretax_username, c.coretax_password_hashed, c.pic_staff_sigma_id,
+		s.nama AS pic_staff_sigma_name,
+		c.client_category, c.pph_final_umkm, c.pph_25, c.pph_21, c.pph_unifikasi, c.ppn, c.spt_tahunan,
+		c.pelaporan_deviden, c.laporan_keuangan, c.investasi_deviden, c.created_at, c.updated_at
+	FROM clients AS c
+	LEFT JOIN staffs AS s ON c.pic_staff_sigma_id = s.staff_id`
+
+	var conditions []string
+	args := []interface{}{}
+	paramCounter := 1
+
+	if n, err := npwp.Parse(query); err == nil {
+		conditions = append(conditions, fmt.Sprintf("c.npwp_canonical = $%d", paramCounter))
+		args = append(args, n.Canonical())
+		paramCounter++
+	} else if query != "" {
+		match := fmt.Sprintf("c.client_name ILIKE $%d", paramCounter)
+		args = append(args, "%"+query+"%")
+		paramCounter++
+		if digits := npwp.Digits(query); digits != "" {
+			match += fmt.Sprintf(" OR c.npwp_canonical LIKE $%d", paramCounter)
+			args = append(args, "%"+digits+"%")
+			paramCounter++
+		}
+		conditions = append(conditions, "("+match+")")
+	}
+
+	if !isAdmin && staffIDFilter != "" {
+		conditions = append(conditions, fmt.Sprintf("c.pic_staff_sigma_id = $%d", paramCounter))
+		args = append(args, staffIDFilter)
+		paramCounter++
+	}
+
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return sqlQuery + " ORDER BY c.client_name ASC", args
+}
+
+// scanClientRows scans rows selected with the column list used by GetAllClients.
+func scanClientRows(rows *sql.Rows) ([]models.Client, error) {
+	var clients []models.Client
+	err := eachClientRow(rows, func(client *models.Client) error {
+		clients = append(clients, *client)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// eachClientRow calls fn for every client in rows, see scanClientRows.
+func eachClientRow(rows *sql.Rows, fn func(*models.Client) error) error {
+	for rows.Next() {
+		var client models.Client
+		var picStaffSigmaID, picStaffSigmaName sql.NullString
+		var pphFinalUmkm, pph25, pph21, pphUnifikasi, ppn, sptTahunan sql.NullBool
+		var pelaporanDeviden, laporanKeuangan, investasiDeviden sql.NullBool
+
+		err := rows.Scan(
+			&client.ClientID, &client.ClientName, &client.NpwpClient, &client.NpwpCanonical, &client.AddressClient, &client.MembershipStatus,
+			&client.PhoneClient, &client.EmailClient, &client.PicClient, &client.DjpOnlineUsername,
+			&client.CoretaxUsername, &client.CoretaxPasswordHashed,
+			&picStaffSigmaID, &picStaffSigmaName,
+			&client.ClientCategory,
+			&pphFinalUmkm, &pph25, &pph21, &pphUnifikasi, &ppn, &sptTahunan,
+			&pelaporanDeviden, &laporanKeuangan, &investasiDeviden,
+			&client.CreatedAt, &client.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan client row: %w", err)
+		}
+
+		client.PicStaffSigmaID = picStaffSigmaID.String
+		client.PicStaffSigmaName = picStaffSigmaName.String
+		client.PphFinalUmkm = pphFinalUmkm.Bool
+		client.Pph25 = pph25.Bool
+		client.Pph21 = pph21.Bool
+		client.PphUnifikasi = pphUnifikasi.Bool
+		client.Ppn = ppn.Bool
+		client.SptTahunan = sptTahunan.Bool
+		client.PelaporanDeviden = pelaporanDeviden.Bool
+		client.LaporanKeuangan = laporanKeuangan.Bool
+		client.InvestasiDeviden = investasiDeviden.Bool
+
+		if err := fn(&client); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during rows iteration for clients: %w", err)
+	}
+	return nil
+}
+
+// ImportClients inserts and updates clients from a bulk import in a single transaction.
+// Coretax passwords must already be hashed; nothing is written if any statement fails.
+func (r *clientRepository) ImportClients(ctx context.Context, creates []*models.Client, updates []*models.Client) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback on error, commit manually on success
+
+	insertQuery := `INSERT INTO clients (
+		client_name, npwp_client, address_client, membership_status, phone_client, email_client,
+		pic_client, djp_online_username, coretax_username, coretax_password_hashed, pic_staff_sigma_id,
+		client_category, pph_final_umkm, pph_25, pph_21, pph_unifikasi, ppn, spt_tahunan,
+		pelaporan_deviden, laporan_keuangan, investasi_deviden, created_at, updated_at, npwp_canonical
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
+	) RETURNING client_id, created_at, updated_at`
+
+	now := time.Now()
+	events := make([]models.DomainEvent, 0, len(creates))
+	for _, client := range creates {
+		client.CreatedAt = now
+		client.UpdatedAt = now
+		err := tx.QueryRowContext(ctx, insertQuery,
+			client.ClientName, client.NpwpClient, client.AddressClient, client.MembershipStatus, client.PhoneClient,
+			client.EmailClient, client.PicClient, client.DjpOnlineUsername, client.CoretaxUsername,
+			client.CoretaxPasswordHashed, nullableString(client.PicStaffSigmaID), client.ClientCategory,
+			client.PphFinalUmkm, client.Pph25, client.Pph21, client.PphUnifikasi, client.Ppn,
+			client.SptTahunan, client.PelaporanDeviden, client.LaporanKeuangan, client.InvestasiDeviden,
+			client.CreatedAt, client.UpdatedAt, client.NpwpCanonical,
+		).Scan(&client.ClientID, &client.CreatedAt, &client.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to import client %s: %w", client.NpwpClient, err)
+		}
+		created, err := models.NewClientCreatedEvent(*client)
+		if err != nil {
+			return err
+		}
+		events = append(events, created)
+	}
+
+	updateQuery := `UPDATE clients SET
+		client_name = $1, npwp_client = $2, address_client = $3, membership_status = $4,
+		phone_client = $5, email_client = $6, pic_client = $7, djp_online_username = $8,
+		coretax_username = $9, coretax_password_hashed = $10, pic_staff_sigma_id = $11,
+		client_category = $12, pph_final_umkm = $13, pph_25 = $14, pph_21 = $15,
+		pph_unifikasi = $16, ppn = $17, spt_tahunan = $18, pelaporan_deviden = $19,
+		laporan_keuangan = $20, investasi_deviden = $21, updated_at = $22, npwp_canonical = $23
+	WHERE client_id = $24`
+
+	for _, client := range updates {
+		client.UpdatedAt = now
+		_, err := tx.ExecContext(ctx, updateQuery,
+			client.ClientName, client.NpwpClient, client.AddressClient, client.MembershipStatus,
+			client.PhoneClient, client.EmailClient, client.PicClient, client.DjpOnlineUsername,
+			client.CoretaxUsername, client.CoretaxPasswordHashed, nullableString(client.PicStaffSigmaID),
+			client.ClientCategory, client.PphFinalUmkm, client.Pph25, client.Pph21,
+			client.PphUnifikasi, client.Ppn, client.SptTahunan, client.PelaporanDeviden,
+			client.LaporanKeuangan, client.InvestasiDeviden, client.UpdatedAt, client.NpwpCanonical,
+			client.ClientID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update imported client %s: %w", client.NpwpClient, err)
+		}
+	}
+
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit client import: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// ClientUserRepository defines data operations for client portal logins
+type ClientUserRepository interface {
+	// CreateClientUser expects PasswordHashed to already hold a bcrypt hash.
+	CreateClientUser(ctx context.Context, user *models.ClientUser) error
+	GetClientUsersByClientID(ctx context.Context, clientID string) ([]models.ClientUser, error)
+	// GetClientUserByID returns sql.ErrNoRows if the user does not exist.
+	GetClientUserByID(ctx context.Context, id string) (*models.ClientUser, error)
+	// GetClientUserByEmail returns nil, nil if no user has the email.
+	GetClientUserByEmail(ctx context.Context, email string) (*models.ClientUser, error)
+	UpdateClientUser(ctx context.Context, user *models.ClientUser) error
+	// DeleteClientUser returns sql.ErrNoRows if the user does not exist.
+	DeleteClientUser(ctx context.Context, id string) error
+	UpdateLastLogin(ctx context.Context, id string, at time.Time) error
+}
+
+// clientUserRepository implements ClientUserRepository interface
+type clientUserRepository struct {
+	db *sql.DB
+}
+
+// NewClientUserRepository creates a new ClientUserRepository
+func NewClientUserRepository(db *sql.DB) ClientUserRepository {
+	return &clientUserRepository{db: db}
+}
+
+const clientUserSelect = `SELECT cu.client_user_id, cu.client_id, c.client_name, cu.nama, cu.email, cu.password_hashed,
+	cu.is_active, cu.last_login_at, cu.created_at, cu.updated_at
+FROM client_users AS cu
+JOIN clients AS c ON cu.client_id = c.client_id`
+
+func scanClientUser(row rowScanner) (*models.ClientUser, error) {
+	var u models.ClientUser
+	var lastLogin sql.NullTime
+	err := row.Scan(&u.ClientUserID, &u.ClientID, &u.ClientName, &u.Nama, &u.Email, &u.PasswordHashed,
+		&u.IsActive, &lastLogin, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastLogin.Valid {
+		u.LastLoginAt = &lastLogin.Time
+	}
+	return &u, nil
+}
+
+// CreateClientUser inserts a new portal login. Emails are stored in lower case.
+func (r *clientUserRepository) CreateClientUser(ctx context.Context, user *models.ClientUser) error {
+	query := `INSERT INTO client_users (client_id, nama, email, password_hashed, is_active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $6)
+	RETURNING client_user_id, created_at, updated_at`
+
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	err := r.db.QueryRowContext(ctx, query, user.ClientID, user.Nama, user.Email, user.PasswordHashed, user.IsActive, time.Now()).
+		Scan(&user.ClientUserID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create client user: %w", err)
+	}
+	return nil
+}
+
+// GetClientUsersByClientID lists the portal logins of a client
+func (r *clientUserRepository) GetClientUsersByClientID(ctx context.Context, clientID string) ([]models.ClientUser, error) {
+	rows, err := r.db.QueryContext(ctx, clientUserSelect+` WHERE cu.client_id = $1 ORDER BY cu.nama ASC`, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.ClientUser{}
+	for rows.Next() {
+		u, err := scanClientUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan client user row: %w", err)
+		}
+		users = append(users, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for client users: %w", err)
+	}
+	return users, nil
+}
+
+// GetClientUserByID fetches a portal login by ID
+func (r *clientUserRepository) GetClientUserByID(ctx context.Context, id string) (*models.ClientUser, error) {
+	u, err := scanClientUser(r.db.QueryRowContext(ctx, clientUserSelect+` WHERE cu.client_user_id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get client user by ID: %w", err)
+	}
+	return u, nil
+}
+
+// GetClientUserByEmail fetches a portal login by email, case-insensitively
+func (r *clientUserRepository) GetClientUserByEmail(ctx context.Context, email string) (*models.ClientUser, error) {
+	u, err := scanClientUser(r.db.QueryRowContext(ctx, clientUserSelect+` WHERE cu.email = $1`, strings.ToLower(strings.TrimSpace(email))))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get client user by email: %w", err)
+	}
+	return u, nil
+}
+
+// UpdateClientUser saves name, email, password hash and active flag
+func (r *clientUserRepository) UpdateClientUser(ctx context.Context, user *models.ClientUser) error {
+	query := `UPDATE client_users SET nama = $1, email = $2, password_hashed = $3, is_active = $4, updated_at = $5
+	WHERE client_user_id = $6`
+
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	user.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query, user.Nama, user.Email, user.PasswordHashed, user.IsActive, user.UpdatedAt, user.ClientUserID)
+	if err != nil {
+		return fmt.Errorf("failed to update client user: %w", err)
+	}
+	return nil
+}
+
+// DeleteClientUser removes a portal login
+func (r *clientUserRepository) DeleteClientUser(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM client_users WHERE client_user_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete client user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateLastLogin records a successful portal login
+func (r *clientUserRepository) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE client_users SET last_login_at = $1 WHERE client_user_id = $2`, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to update client user last login: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// DocumentRepository defines the interface for document data operations
+type DocumentRepository interface {
+	UpsertDocument(ctx context.Context, doc *models.Document) error
+	GetDocumentByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Document, error)
+	GetPendingDocuments(ctx context.Context, limit int) ([]models.Document, error)
+	UpdateDocumentExtraction(ctx context.Context, id string, status string, contentText string, extractionError string) error
+	SearchDocuments(ctx context.Context, params models.DocumentSearchParams, staffIDFilter string, isAdmin bool) ([]models.DocumentSearchResult, error)
+}
+
+// documentRepository implements DocumentRepository interface
+type documentRepository struct {
+	db *sql.DB
+}
+
+// NewDocumentRepository creates a new DocumentRepository
+func NewDocumentRepository(db *sql.DB) DocumentRepository {
+	return &documentRepository{db: db}
+}
+
+// UpsertDocument inserts a document record, or resets it for re-extraction when the
+// same file URL is uploaded again (proof of work files are overwritten per job).
+func (r *documentRepository) UpsertDocument(ctx context.Context, doc *models.Document) error {
+	var uploadedBy sql.NullString
+	if doc.UploadedByStaffID != nil && *doc.UploadedByStaffID != "" {
+		uploadedBy = sql.NullString{String: *doc.UploadedByStaffID, Valid: true}
+	}
+
+	query := `INSERT INTO documents (
+		client_id, job_type, job_id, file_name, file_url, uploaded_by_staff_id,
+		extraction_status, created_at, updated_at
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9
+	)
+	ON CONFLICT (file_url) DO UPDATE SET
+		client_id = EXCLUDED.client_id, job_type = EXCLUDED.job_type, job_id = EXCLUDED.job_id,
+		file_name = EXCLUDED.file_name, uploaded_by_staff_id = EXCLUDED.uploaded_by_staff_id,
+		extraction_status = EXCLUDED.extraction_status, extraction_error = NULL,
+		content_text = NULL, extracted_at = NULL, updated_at = EXCLUDED.updated_at
+	RETURNING document_id, created_at, updated_at`
+
+	now := time.Now()
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = now
+	}
+	doc.UpdatedAt = now
+	doc.ExtractionStatus = models.ExtractionStatusPending
+
+	err := r.db.QueryRowContext(ctx, query,
+		doc.ClientID, doc.JobType, doc.JobID, doc.FileName, doc.FileURL, uploadedBy,
+		doc.ExtractionStatus, doc.CreatedAt, doc.UpdatedAt,
+	).Scan(&doc.DocumentID, &doc.CreatedAt, &doc.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+	return nil
+}
+
+// documentJobJoin joins a document to its job (see allJobsCTE). Non-admin staff only see
+// documents of jobs assigned to them, the same rule as GetAllMonthlyJobs and the other job lists.
+const documentJobJoin = `LEFT JOIN jobs AS j ON j.job_type = d.job_type AND j.job_id = d.job_id`
+
+// GetDocumentByID fetches a single document including its extracted text.
+// Non-admin staff can only see documents of the jobs assigned to them.
+func (r *documentRepository) GetDocumentByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Document, error) {
+	query := allJobsCTE() + `SELECT
+		d.document_id, d.client_id, c.client_name, d.job_type, d.job_id, d.file_name, d.file_url,
+		d.uploaded_by_staff_id, d.extraction_status, d.extraction_error, d.content_text,
+		d.extracted_at, d.created_at, d.updated_at
+	FROM documents AS d
+	JOIN clients AS c ON d.client_id = c.client_id
+	` + documentJobJoin + `
+	WHERE d.document_id = $1`
+
+	args := []interface{}{id}
+	if !isAdmin && staffIDFilter != "" {
+		query += " AND j.staff_id = $2"
+		args = append(args, staffIDFilter)
+	}
+
+	var (
+		doc             models.Document
+		uploadedBy      sql.NullString
+		extractionError sql.NullString
+		contentText     sql.NullString
+		extractedAt     sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&doc.DocumentID, &doc.ClientID, &doc.ClientName, &doc.JobType, &doc.JobID, &doc.FileName, &doc.FileURL,
+		&uploadedBy, &doc.ExtractionStatus, &extractionError, &contentText,
+		&extractedAt, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get document by ID: %w", err)
+	}
+
+	if uploadedBy.Valid {
+		doc.UploadedByStaffID = &uploadedBy.String
+	}
+	if extractionError.Valid {
+		doc.ExtractionError = &extractionError.String
+	}
+	if extractedAt.Valid {
+		doc.ExtractedAt = &extractedAt.Time
+	}
+	doc.ContentText = contentText.String
+
+	return &doc, nil
+}
+
+// GetPendingDocuments returns documents still waiting for text extraction, oldest first.
+func (r *documentRepository) GetPendingDocuments(ctx context.Context, limit int) ([]models.Document, error) {
+	query := `
+	SELECT document_id, client_id, job_type, job_id, file_name, file_url, created_at, updated_at
+	FROM documents
+	WHERE extraction_status = $1
+	ORDER BY created_at ASC
+	LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, models.ExtractionStatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []models.Document
+	for rows.Next() {
+		doc := models.Document{ExtractionStatus: models.ExtractionStatusPending}
+		err := rows.Scan(
+			&doc.DocumentID, &doc.ClientID, &doc.JobType, &doc.JobID, &doc.FileName, &doc.FileURL,
+			&doc.CreatedAt, &doc.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pending document row: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for pending documents: %w", err)
+	}
+	return docs, nil
+}
+
+// UpdateDocumentExtraction stores the extraction result for a document.
+func (r *documentRepository) UpdateDocumentExtraction(ctx context.Context, id string, status string, contentText string, extractionError string) error {
+	var errMsg sql.NullString
+	if extractionError != "" {
+		errMsg = sql.NullString{String: extractionError, Valid: true}
+	}
+
+	query := `UPDATE documents SET
+		extraction_status = $1, content_text = $2, extraction_error = $3, extracted_at = $4, updated_at = $4
+	WHERE document_id = $5`
+
+	_, err := r.db.ExecContext(ctx, query, status, contentText, errMsg, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update document extraction: %w", err)
+	}
+	return nil
+}
+
+// SearchDocuments runs a full-text search over extracted document text and file names.
+// Non-admin staff only find documents of the jobs assigned to them.
+func (r *documentRepository) SearchDocuments(ctx context.Context, params models.DocumentSearchParams, staffIDFilter string, isAdmin bool) ([]models.DocumentSearchResult, error) {
+	query := allJobsCTE() + `SELECT
+		d.document_id, d.client_id, c.client_name, d.job_type, d.job_id, d.file_name, d.file_url,
+		d.uploaded_by_staff_id, d.extraction_status, d.extracted_at, d.created_at, d.updated_at,
+		ts_headline('simple', COALESCE(d.content_text, ''), q, 'MaxFragments=2, MaxWords=20, MinWords=5'),
+		ts_rank(d.content_tsv, q) AS rank
+	FROM documents AS d
+	JOIN clients AS c ON d.client_id = c.client_id
+	` + documentJobJoin + `,
+		websearch_to_tsquery('simple', $1) AS q
+	WHERE d.content_tsv @@ q`
+
+	args := []interface{}{params.Query}
+	paramCounter := 2
+
+	if params.ClientID != "" {
+		query += fmt.Sprintf(" AND d.client_id = $%d", paramCounter)
+		args = append(args, params.ClientID)
+		paramCounter++
+	}
+	if params.JobType != "" {
+		query += fmt.Sprintf(" AND d.job_type = $%d", paramCounter)
+		args = append(args, params.JobType)
+		paramCounter++
+	}
+	if !isAdmin && staffIDFilter != "" {
+		query += fmt.Sprintf(" AND j.staff_id = $%d", paramCounter)
+		args = append(args, staffIDFilter)
+		paramCounter++
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	query += fmt.Sprintf(" ORDER BY rank DESC, d.created_at DESC LIMIT $%d", paramCounter)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.DocumentSearchResult{}
+	for rows.Next() {
+		var (
+			res         models.DocumentSearchResult
+			uploadedBy  sql.NullString
+			extractedAt sql.NullTime
+		)
+		err := rows.Scan(
+			&res.DocumentID, &res.ClientID, &res.ClientName, &res.JobType, &res.JobID, &res.FileName, &res.FileURL,
+			&uploadedBy, &res.ExtractionStatus, &extractedAt, &res.CreatedAt, &res.UpdatedAt,
+			&res.Snippet, &res.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document search row: %w", err)
+		}
+		if uploadedBy.Valid {
+			res.UploadedByStaffID = &uploadedBy.String
+		}
+		if extractedAt.Valid {
+			res.ExtractedAt = &extractedAt.Time
+		}
+		results = append(results, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for document search: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,298 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// DocumentRequestRepository defines data operations for the document checklists of jobs
+type DocumentRequestRepository interface {
+	// GetJob returns the job a checklist is attached to, or sql.ErrNoRows if it does not exist.
+	GetJob(ctx context.Context, jobType, jobID string) (*models.DocumentRequestJob, error)
+	// CreateDocumentRequests inserts all items in one transaction.
+	CreateDocumentRequests(ctx context.Context, reqs []models.DocumentRequest) error
+	GetDocumentRequests(ctx context.Context, filter models.DocumentRequestFilter) ([]models.DocumentRequest, error)
+	// GetDocumentRequestByID returns sql.ErrNoRows if the request does not exist.
+	GetDocumentRequestByID(ctx context.Context, id string) (*models.DocumentRequest, error)
+	// UpdateDocumentRequest saves title, description, due date, status and review fields.
+	UpdateDocumentRequest(ctx context.Context, req *models.DocumentRequest) error
+	// AttachUpload links an uploaded document to the request and marks it Diupload.
+	AttachUpload(ctx context.Context, id, documentID, clientUserID string, at time.Time) error
+	// DeleteDocumentRequest returns sql.ErrNoRows if the request does not exist.
+	DeleteDocumentRequest(ctx context.Context, id string) error
+	// GetOutstandingReminders returns every outstanding request of an open job due on or before
+	// dueBefore, once per active client user of the client, ordered by client user and due date.
+	GetOutstandingReminders(ctx context.Context, dueBefore time.Time) ([]models.DocumentRequestReminder, error)
+}
+
+// documentRequestRepository implements DocumentRequestRepository interface
+type documentRequestRepository struct {
+	db *sql.DB
+}
+
+// NewDocumentRequestRepository creates a new DocumentRequestRepository
+func NewDocumentRequestRepository(db *sql.DB) DocumentRequestRepository {
+	return &documentRequestRepository{db: db}
+}
+
+// allJobsCTE is a WITH clause exposing every job as jobs(job_type, job_id, client_id, staff_id, ...).
+func allJobsCTE() string {
+	var sources []string
+	for _, jobType := range models.JobTypes {
+		sources = append(sources, jobSources[jobType])
+	}
+	return "WITH jobs AS (\n\t\t" + strings.Join(sources, "\n\t\tUNION ALL\n\t\t") + "\n\t)\n\t"
+}
+
+const documentRequestColumns = `dr.request_id, dr.job_type, dr.job_id, dr.client_id, c.client_name, COALESCE(j.period, ''),
+		dr.title, dr.description, dr.due_date, dr.status, dr.document_id, d.file_name, dr.uploaded_by_client_user_id,
+		dr.uploaded_at, dr.review_note, dr.reviewed_by_staff_id, dr.reviewed_at, dr.created_by_staff_id,
+		dr.created_at, dr.updated_at`
+
+const documentRequestFrom = `FROM document_requests AS dr
+	JOIN clients AS c ON dr.client_id = c.client_id
+	LEFT JOIN documents AS d ON dr.document_id = d.document_id`
+
+// scanDocumentRequest scans documentRequestColumns, after the optional leading columns in dest.
+func scanDocumentRequest(row rowScanner, dest ...interface{}) (*models.DocumentRequest, error) {
+	var req models.DocumentRequest
+	var description, documentID, fileName, uploadedBy, reviewNote, reviewedBy, createdBy sql.NullString
+	var uploadedAt, reviewedAt sql.NullTime
+	var dueDate time.Time
+	dest = append(dest, &req.RequestID, &req.JobType, &req.JobID, &req.ClientID, &req.ClientName, &req.Period,
+		&req.Title, &description, &dueDate, &req.Status, &documentID, &fileName, &uploadedBy,
+		&uploadedAt, &reviewNote, &reviewedBy, &reviewedAt, &createdBy,
+		&req.CreatedAt, &req.UpdatedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	req.DueDate = models.CustomDate{Time: dueDate}
+	req.Description = nullStringPtr(description)
+	req.DocumentID = nullStringPtr(documentID)
+	req.FileName = nullStringPtr(fileName)
+	req.UploadedByClientUserID = nullStringPtr(uploadedBy)
+	req.ReviewNote = nullStringPtr(reviewNote)
+	req.ReviewedByStaffID = nullStringPtr(reviewedBy)
+	req.CreatedByStaffID = nullStringPtr(createdBy)
+	if uploadedAt.Valid {
+		req.UploadedAt = &uploadedAt.Time
+	}
+	if reviewedAt.Valid {
+		req.ReviewedAt = &reviewedAt.Time
+	}
+	return &req, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// GetJob looks up a job of any type by its type and ID
+func (r *documentRequestRepository) GetJob(ctx context.Context, jobType, jobID string) (*models.DocumentRequestJob, error) {
+	source, ok := jobSources[jobType]
+	if !ok {
+		return nil, fmt.Errorf("unknown job type: %s", jobType)
+	}
+	query := `SELECT j.job_type, j.job_id, j.client_id, c.client_name, j.period, COALESCE(j.overall_status, ''),
+		COALESCE(j.staff_id::text, '')
+	FROM (` + source + `) AS j
+	JOIN clients AS c ON j.client_id = c.client_id
+	WHERE j.job_id = $1`
+
+	var job models.DocumentRequestJob
+	err := r.db.QueryRowContext(ctx, query, jobID).Scan(&job.JobType, &job.JobID, &job.ClientID, &job.ClientName, &job.Period,
+		&job.OverallStatus, &job.StaffID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get job for document requests: %w", err)
+	}
+	return &job, nil
+}
+
+// CreateDocumentRequests inserts checklist items with status Menunggu
+func (r *documentRequestRepository) CreateDocumentRequests(ctx context.Context, reqs []models.DocumentRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO document_requests (job_type, job_id, client_id, title, description, due_date, status,
+		created_by_staff_id, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	RETURNING request_id, created_at, updated_at`
+
+	now := time.Now()
+	for i := range reqs {
+		req := &reqs[i]
+		req.Status = models.DocumentRequestStatusPending
+		err := tx.QueryRowContext(ctx, query, req.JobType, req.JobID, req.ClientID, req.Title, req.Description, req.DueDate,
+			req.Status, req.CreatedByStaffID, now).Scan(&req.RequestID, &req.CreatedAt, &req.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create document request: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document requests: %w", err)
+	}
+	return nil
+}
+
+// GetDocumentRequests lists checklist items ordered by due date
+func (r *documentRequestRepository) GetDocumentRequests(ctx context.Context, filter models.DocumentRequestFilter) ([]models.DocumentRequest, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.JobType != "" {
+		addCondition("dr.job_type = $%d", filter.JobType)
+	}
+	if filter.JobID != "" {
+		addCondition("dr.job_id = $%d", filter.JobID)
+	}
+	if filter.ClientID != "" {
+		addCondition("dr.client_id = $%d", filter.ClientID)
+	}
+	if filter.Status != "" {
+		addCondition("dr.status = $%d", filter.Status)
+	}
+	if filter.StaffID != "" {
+		addCondition("j.staff_id = $%d", filter.StaffID)
+	}
+	if filter.OutstandingOnly {
+		conditions = append(conditions, fmt.Sprintf("dr.status IN ('%s', '%s')",
+			models.DocumentRequestStatusPending, models.DocumentRequestStatusRejected))
+	}
+
+	query := allJobsCTE() + `SELECT ` + documentRequestColumns + `
+	` + documentRequestFrom + `
+	LEFT JOIN jobs AS j ON j.job_type = dr.job_type AND j.job_id = dr.job_id`
+	if len(conditions) > 0 {
+		query += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += "\n\tORDER BY dr.due_date ASC, c.client_name ASC, dr.title ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document requests: %w", err)
+	}
+	defer rows.Close()
+
+	reqs := []models.DocumentRequest{}
+	for rows.Next() {
+		req, err := scanDocumentRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document request row: %w", err)
+		}
+		reqs = append(reqs, *req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for document requests: %w", err)
+	}
+	return reqs, nil
+}
+
+// GetDocumentRequestByID fetches one checklist item
+func (r *documentRequestRepository) GetDocumentRequestByID(ctx context.Context, id string) (*models.DocumentRequest, error) {
+	query := allJobsCTE() + `SELECT ` + documentRequestColumns + `
+	` + documentRequestFrom + `
+	LEFT JOIN jobs AS j ON j.job_type = dr.job_type AND j.job_id = dr.job_id
+	WHERE dr.request_id = $1`
+
+	req, err := scanDocumentRequest(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get document request by ID: %w", err)
+	}
+	return req, nil
+}
+
+// UpdateDocumentRequest saves the editable and review fields of a checklist item
+func (r *documentRequestRepository) UpdateDocumentRequest(ctx context.Context, req *models.DocumentRequest) error {
+	query := `UPDATE document_requests SET title = $1, description = $2, due_date = $3, status = $4,
+		review_note = $5, reviewed_by_staff_id = $6, reviewed_at = $7, updated_at = $8
+	WHERE request_id = $9`
+
+	req.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query, req.Title, req.Description, req.DueDate, req.Status,
+		req.ReviewNote, req.ReviewedByStaffID, req.ReviewedAt, req.UpdatedAt, req.RequestID)
+	if err != nil {
+		return fmt.Errorf("failed to update document request: %w", err)
+	}
+	return nil
+}
+
+// AttachUpload records a client upload. A previous review is cleared, since the new file
+// has not been checked yet.
+func (r *documentRequestRepository) AttachUpload(ctx context.Context, id, documentID, clientUserID string, at time.Time) error {
+	query := `UPDATE document_requests SET status = $1, document_id = $2, uploaded_by_client_user_id = $3,
+		uploaded_at = $4, review_note = NULL, reviewed_by_staff_id = NULL, reviewed_at = NULL, updated_at = $4
+	WHERE request_id = $5`
+
+	_, err := r.db.ExecContext(ctx, query, models.DocumentRequestStatusUploaded, documentID, clientUserID, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to attach upload to document request: %w", err)
+	}
+	return nil
+}
+
+// DeleteDocumentRequest removes a checklist item. An uploaded document is kept.
+func (r *documentRequestRepository) DeleteDocumentRequest(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM document_requests WHERE request_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete document request: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetOutstandingReminders lists outstanding requests per active client user. Requests of
+// completed, cancelled or deleted jobs are skipped.
+func (r *documentRequestRepository) GetOutstandingReminders(ctx context.Context, dueBefore time.Time) ([]models.DocumentRequestReminder, error) {
+	query := allJobsCTE() + `SELECT cu.client_user_id, cu.nama, cu.email, ` + documentRequestColumns + `
+	` + documentRequestFrom + `
+	JOIN jobs AS j ON j.job_type = dr.job_type AND j.job_id = dr.job_id
+	JOIN client_users AS cu ON cu.client_id = dr.client_id AND cu.is_active
+	WHERE dr.status IN ($1, $2) AND dr.due_date <= $3
+		AND COALESCE(j.overall_status, '') NOT IN ($4, $5)
+	ORDER BY cu.client_user_id, dr.due_date, dr.title`
+
+	rows, err := r.db.QueryContext(ctx, query, models.DocumentRequestStatusPending, models.DocumentRequestStatusRejected,
+		dueBefore, models.JobStatusCompleted, models.JobStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document request reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []models.DocumentRequestReminder
+	for rows.Next() {
+		var rem models.DocumentRequestReminder
+		req, err := scanDocumentRequest(rows, &rem.ClientUserID, &rem.Nama, &rem.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document request reminder row: %w", err)
+		}
+		rem.ClientName = req.ClientName
+		rem.Request = *req
+		reminders = append(reminders, rem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for document request reminders: %w", err)
+	}
+	return reminders, nil
+}
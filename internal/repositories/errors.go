@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicate is returned by repositories that check uniqueness themselves (such as the
+// in-memory ones) when a write would violate a unique constraint.
+var ErrDuplicate = errors.New("duplicate key value violates unique constraint")
+
+// IsDuplicate reports whether err is a unique constraint violation, either ErrDuplicate or
+// the unique_violation error of Postgres.
+func IsDuplicate(err error) bool {
+	if errors.Is(err, ErrDuplicate) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// SchemaTables adalah tabel yang dibuat init.sql dan dipakai aplikasi. Tambahkan tabel baru di
+// sini setiap kali init.sql berubah, agar /readyz gagal di database yang belum diperbarui.
+var SchemaTables = []string{
+	"clients", "staffs",
+	"monthly_jobs", "monthly_tax_reports",
+	"annual_jobs", "annual_tax_reports", "annual_dividend_reports",
+	"sp2dk_jobs", "pemeriksaan_jobs",
+	"invoices", "invoice_line_items",
+	"documents", "document_requests",
+	"notification_preferences", "notification_log", "notifications",
+	"webhook_subscriptions", "webhook_deliveries",
+	"outbox_events", "outbox_handled", "audit_log",
+	"client_users", "rate_limit_buckets", "password_reset_tokens",
+	"staff_totp", "staff_recovery_codes",
+}
+
+// HealthRepository defines the database checks behind the readiness probe
+type HealthRepository interface {
+	// Ping checks that a connection to the database can be made.
+	Ping(ctx context.Context) error
+	// GetMissingTables returns the tables of the given list that do not exist in the
+	// current search path.
+	GetMissingTables(ctx context.Context, tables []string) ([]string, error)
+}
+
+// healthRepository implements HealthRepository interface
+type healthRepository struct {
+	db *sql.DB
+}
+
+// NewHealthRepository creates a new HealthRepository
+func NewHealthRepository(db *sql.DB) HealthRepository {
+	return &healthRepository{db: db}
+}
+
+// Ping pings the database within ctx
+func (r *healthRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// GetMissingTables looks the tables up with to_regclass, which returns NULL for unknown names
+func (r *healthRepository) GetMissingTables(ctx context.Context, tables []string) ([]string, error) {
+	query := `SELECT name FROM unnest($1::text[]) AS name WHERE to_regclass(name) IS NULL ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tables))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schema tables: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan missing table row: %w", err)
+		}
+		missing = append(missing, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for schema tables: %w", err)
+	}
+	return missing, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//go:generate go run ../tools/repometricsgen -out instrumented_gen.go
+
+// ObserveFunc is called after every call of an instrumented repository with the interface
+// and method name, the start time of the call and the error it returned, if any.
+type ObserveFunc func(repository, method string, start time.Time, err error)
+
+// TraceFunc is called when an instrumented repository call starts. The returned context is
+// passed on to the repository (e.g. with a span or logger attached) and end is called with
+// the error of the call once it returns.
+type TraceFunc func(ctx context.Context, repository, method string) (_ context.Context, end func(err error))
+
+// Instrumentation is shared by the instrumented decorators of all repositories.
+type Instrumentation struct {
+	// Observe records every call, e.g. as a metric. Nil disables it.
+	Observe ObserveFunc
+	// StatementTimeout bounds every call on top of the deadline of the caller's context.
+	// Zero means only the caller's context applies.
+	StatementTimeout time.Duration
+	// Trace hooks run around every call, the first one outermost.
+	Trace []TraceFunc
+}
+
+// begin starts an instrumented call. The returned end must be called with the error of the
+// call; it releases the timeout and returns the error to hand back to the caller.
+func (in *Instrumentation) begin(ctx context.Context, repository, method string) (context.Context, func(err error) error) {
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if in.StatementTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, in.StatementTimeout)
+	}
+	ends := make([]func(error), 0, len(in.Trace))
+	for _, trace := range in.Trace {
+		var end func(error)
+		ctx, end = trace(ctx, repository, method)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(err error) error {
+		// Driver Postgres melaporkan query yang dibatalkan sebagai error server biasa; sertakan
+		// penyebabnya agar pemanggil bisa memeriksa context.DeadlineExceeded atau context.Canceled
+		if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+			err = fmt.Errorf("%w (%w)", err, ctx.Err())
+		}
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+		cancel()
+		if in.Observe != nil {
+			in.Observe(repository, method, start, err)
+		}
+		return err
+	}
+}
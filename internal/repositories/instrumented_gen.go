@@ -0,0 +1,1240 @@
+// Code generated by repometricsgen. DO NOT EDIT.
+
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/ratelimit"
+)
+
+// instrumentedAnnualJobRepository runs every AnnualJobRepository call through an Instrumentation.
+type instrumentedAnnualJobRepository struct {
+	next AnnualJobRepository
+	in   *Instrumentation
+}
+
+// InstrumentAnnualJobRepository wraps next so every call goes through in.
+func InstrumentAnnualJobRepository(next AnnualJobRepository, in *Instrumentation) AnnualJobRepository {
+	return &instrumentedAnnualJobRepository{next: next, in: in}
+}
+
+func (r *instrumentedAnnualJobRepository) CreateAnnualJob(p0 context.Context, p1 *models.AnnualJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "CreateAnnualJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateAnnualJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) GetAllAnnualJobs(p0 context.Context, p1 string, p2 bool) (r0 []models.AnnualJob, r1 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "GetAllAnnualJobs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllAnnualJobs(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) EachAnnualJob(p0 context.Context, p1 string, p2 bool, p3 func(*models.AnnualJob) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "EachAnnualJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachAnnualJob(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) GetAnnualJobByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.AnnualJob, r1 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "GetAnnualJobByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAnnualJobByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) UpdateAnnualJob(p0 context.Context, p1 *models.AnnualJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "UpdateAnnualJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateAnnualJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) CreateAnnualTaxReport(p0 context.Context, p1 *models.AnnualTaxReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "CreateAnnualTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateAnnualTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) GetAnnualTaxReportByID(p0 context.Context, p1 string) (r0 *models.AnnualTaxReport, r1 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "GetAnnualTaxReportByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAnnualTaxReportByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) UpdateAnnualTaxReport(p0 context.Context, p1 *models.AnnualTaxReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "UpdateAnnualTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateAnnualTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) DeleteAnnualTaxReport(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "DeleteAnnualTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteAnnualTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) CreateAnnualDividendReport(p0 context.Context, p1 *models.AnnualDividendReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "CreateAnnualDividendReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateAnnualDividendReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) GetAnnualDividendReportByID(p0 context.Context, p1 string) (r0 *models.AnnualDividendReport, r1 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "GetAnnualDividendReportByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAnnualDividendReportByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) UpdateAnnualDividendReport(p0 context.Context, p1 *models.AnnualDividendReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "UpdateAnnualDividendReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateAnnualDividendReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) DeleteAnnualDividendReport(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "DeleteAnnualDividendReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteAnnualDividendReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedAnnualJobRepository) GetAnnualJobsByClientID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 []models.AnnualJob, r1 error) {
+	ctx, end := r.in.begin(p0, "AnnualJobRepository", "GetAnnualJobsByClientID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAnnualJobsByClientID(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedAuditRepository runs every AuditRepository call through an Instrumentation.
+type instrumentedAuditRepository struct {
+	next AuditRepository
+	in   *Instrumentation
+}
+
+// InstrumentAuditRepository wraps next so every call goes through in.
+func InstrumentAuditRepository(next AuditRepository, in *Instrumentation) AuditRepository {
+	return &instrumentedAuditRepository{next: next, in: in}
+}
+
+func (r *instrumentedAuditRepository) RecordEvent(p0 context.Context, p1 *models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "AuditRepository", "RecordEvent")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.RecordEvent(ctx, p1)
+	return
+}
+
+func (r *instrumentedAuditRepository) GetAuditLog(p0 context.Context, p1 string, p2 string, p3 int, p4 *time.Time) (r0 []models.AuditEntry, r1 error) {
+	ctx, end := r.in.begin(p0, "AuditRepository", "GetAuditLog")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAuditLog(ctx, p1, p2, p3, p4)
+	return
+}
+
+// instrumentedClientRepository runs every ClientRepository call through an Instrumentation.
+type instrumentedClientRepository struct {
+	next ClientRepository
+	in   *Instrumentation
+}
+
+// InstrumentClientRepository wraps next so every call goes through in.
+func InstrumentClientRepository(next ClientRepository, in *Instrumentation) ClientRepository {
+	return &instrumentedClientRepository{next: next, in: in}
+}
+
+func (r *instrumentedClientRepository) CreateClient(p0 context.Context, p1 *models.Client) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "CreateClient")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateClient(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientRepository) GetAllClients(p0 context.Context, p1 string, p2 bool) (r0 []models.Client, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "GetAllClients")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllClients(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedClientRepository) GetClientByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.Client, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "GetClientByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetClientByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedClientRepository) UpdateClient(p0 context.Context, p1 *models.Client) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "UpdateClient")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateClient(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientRepository) DeleteClient(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "DeleteClient")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteClient(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientRepository) GetClientsByNpwp(p0 context.Context, p1 []string) (r0 []models.Client, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "GetClientsByNpwp")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetClientsByNpwp(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientRepository) SearchClients(p0 context.Context, p1 string, p2 string, p3 bool) (r0 []models.Client, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "SearchClients")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.SearchClients(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedClientRepository) EachClient(p0 context.Context, p1 string, p2 string, p3 bool, p4 func(*models.Client) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "EachClient")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachClient(ctx, p1, p2, p3, p4)
+	return
+}
+
+func (r *instrumentedClientRepository) ImportClients(p0 context.Context, p1 []*models.Client, p2 []*models.Client) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientRepository", "ImportClients")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.ImportClients(ctx, p1, p2)
+	return
+}
+
+// instrumentedClientUserRepository runs every ClientUserRepository call through an Instrumentation.
+type instrumentedClientUserRepository struct {
+	next ClientUserRepository
+	in   *Instrumentation
+}
+
+// InstrumentClientUserRepository wraps next so every call goes through in.
+func InstrumentClientUserRepository(next ClientUserRepository, in *Instrumentation) ClientUserRepository {
+	return &instrumentedClientUserRepository{next: next, in: in}
+}
+
+func (r *instrumentedClientUserRepository) CreateClientUser(p0 context.Context, p1 *models.ClientUser) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "CreateClientUser")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateClientUser(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) GetClientUsersByClientID(p0 context.Context, p1 string) (r0 []models.ClientUser, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "GetClientUsersByClientID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetClientUsersByClientID(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) GetClientUserByID(p0 context.Context, p1 string) (r0 *models.ClientUser, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "GetClientUserByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetClientUserByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) GetClientUserByEmail(p0 context.Context, p1 string) (r0 *models.ClientUser, r1 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "GetClientUserByEmail")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetClientUserByEmail(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) UpdateClientUser(p0 context.Context, p1 *models.ClientUser) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "UpdateClientUser")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateClientUser(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) DeleteClientUser(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "DeleteClientUser")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteClientUser(ctx, p1)
+	return
+}
+
+func (r *instrumentedClientUserRepository) UpdateLastLogin(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	ctx, end := r.in.begin(p0, "ClientUserRepository", "UpdateLastLogin")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateLastLogin(ctx, p1, p2)
+	return
+}
+
+// instrumentedDocumentRepository runs every DocumentRepository call through an Instrumentation.
+type instrumentedDocumentRepository struct {
+	next DocumentRepository
+	in   *Instrumentation
+}
+
+// InstrumentDocumentRepository wraps next so every call goes through in.
+func InstrumentDocumentRepository(next DocumentRepository, in *Instrumentation) DocumentRepository {
+	return &instrumentedDocumentRepository{next: next, in: in}
+}
+
+func (r *instrumentedDocumentRepository) UpsertDocument(p0 context.Context, p1 *models.Document) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRepository", "UpsertDocument")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpsertDocument(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRepository) GetDocumentByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.Document, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRepository", "GetDocumentByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDocumentByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedDocumentRepository) GetPendingDocuments(p0 context.Context, p1 int) (r0 []models.Document, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRepository", "GetPendingDocuments")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetPendingDocuments(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRepository) UpdateDocumentExtraction(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRepository", "UpdateDocumentExtraction")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateDocumentExtraction(ctx, p1, p2, p3, p4)
+	return
+}
+
+func (r *instrumentedDocumentRepository) SearchDocuments(p0 context.Context, p1 models.DocumentSearchParams, p2 string, p3 bool) (r0 []models.DocumentSearchResult, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRepository", "SearchDocuments")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.SearchDocuments(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedDocumentRequestRepository runs every DocumentRequestRepository call through an Instrumentation.
+type instrumentedDocumentRequestRepository struct {
+	next DocumentRequestRepository
+	in   *Instrumentation
+}
+
+// InstrumentDocumentRequestRepository wraps next so every call goes through in.
+func InstrumentDocumentRequestRepository(next DocumentRequestRepository, in *Instrumentation) DocumentRequestRepository {
+	return &instrumentedDocumentRequestRepository{next: next, in: in}
+}
+
+func (r *instrumentedDocumentRequestRepository) GetJob(p0 context.Context, p1 string, p2 string) (r0 *models.DocumentRequestJob, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "GetJob")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetJob(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) CreateDocumentRequests(p0 context.Context, p1 []models.DocumentRequest) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "CreateDocumentRequests")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateDocumentRequests(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) GetDocumentRequests(p0 context.Context, p1 models.DocumentRequestFilter) (r0 []models.DocumentRequest, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "GetDocumentRequests")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDocumentRequests(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) GetDocumentRequestByID(p0 context.Context, p1 string) (r0 *models.DocumentRequest, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "GetDocumentRequestByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDocumentRequestByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) UpdateDocumentRequest(p0 context.Context, p1 *models.DocumentRequest) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "UpdateDocumentRequest")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateDocumentRequest(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) AttachUpload(p0 context.Context, p1 string, p2 string, p3 string, p4 time.Time) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "AttachUpload")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.AttachUpload(ctx, p1, p2, p3, p4)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) DeleteDocumentRequest(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "DeleteDocumentRequest")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteDocumentRequest(ctx, p1)
+	return
+}
+
+func (r *instrumentedDocumentRequestRepository) GetOutstandingReminders(p0 context.Context, p1 time.Time) (r0 []models.DocumentRequestReminder, r1 error) {
+	ctx, end := r.in.begin(p0, "DocumentRequestRepository", "GetOutstandingReminders")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetOutstandingReminders(ctx, p1)
+	return
+}
+
+// instrumentedHealthRepository runs every HealthRepository call through an Instrumentation.
+type instrumentedHealthRepository struct {
+	next HealthRepository
+	in   *Instrumentation
+}
+
+// InstrumentHealthRepository wraps next so every call goes through in.
+func InstrumentHealthRepository(next HealthRepository, in *Instrumentation) HealthRepository {
+	return &instrumentedHealthRepository{next: next, in: in}
+}
+
+func (r *instrumentedHealthRepository) Ping(p0 context.Context) (r0 error) {
+	ctx, end := r.in.begin(p0, "HealthRepository", "Ping")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.Ping(ctx)
+	return
+}
+
+func (r *instrumentedHealthRepository) GetMissingTables(p0 context.Context, p1 []string) (r0 []string, r1 error) {
+	ctx, end := r.in.begin(p0, "HealthRepository", "GetMissingTables")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetMissingTables(ctx, p1)
+	return
+}
+
+// instrumentedInvoiceRepository runs every InvoiceRepository call through an Instrumentation.
+type instrumentedInvoiceRepository struct {
+	next InvoiceRepository
+	in   *Instrumentation
+}
+
+// InstrumentInvoiceRepository wraps next so every call goes through in.
+func InstrumentInvoiceRepository(next InvoiceRepository, in *Instrumentation) InvoiceRepository {
+	return &instrumentedInvoiceRepository{next: next, in: in}
+}
+
+func (r *instrumentedInvoiceRepository) CreateInvoice(p0 context.Context, p1 *models.Invoice) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "CreateInvoice")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateInvoice(ctx, p1)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) GetAllInvoices(p0 context.Context, p1 string, p2 bool) (r0 []models.Invoice, r1 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "GetAllInvoices")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllInvoices(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) EachInvoice(p0 context.Context, p1 string, p2 bool, p3 func(*models.Invoice) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "EachInvoice")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachInvoice(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) GetInvoiceByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.Invoice, r1 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "GetInvoiceByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetInvoiceByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) UpdateInvoice(p0 context.Context, p1 *models.Invoice, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "UpdateInvoice")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateInvoice(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) DeleteInvoice(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "DeleteInvoice")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteInvoice(ctx, p1)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) CreateInvoiceLineItem(p0 context.Context, p1 *models.InvoiceLineItem) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "CreateInvoiceLineItem")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateInvoiceLineItem(ctx, p1)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) UpdateInvoiceLineItem(p0 context.Context, p1 *models.InvoiceLineItem) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "UpdateInvoiceLineItem")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateInvoiceLineItem(ctx, p1)
+	return
+}
+
+func (r *instrumentedInvoiceRepository) DeleteInvoiceLineItem(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "InvoiceRepository", "DeleteInvoiceLineItem")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteInvoiceLineItem(ctx, p1)
+	return
+}
+
+// instrumentedMetricsRepository runs every MetricsRepository call through an Instrumentation.
+type instrumentedMetricsRepository struct {
+	next MetricsRepository
+	in   *Instrumentation
+}
+
+// InstrumentMetricsRepository wraps next so every call goes through in.
+func InstrumentMetricsRepository(next MetricsRepository, in *Instrumentation) MetricsRepository {
+	return &instrumentedMetricsRepository{next: next, in: in}
+}
+
+func (r *instrumentedMetricsRepository) GetJobStatusCounts(p0 context.Context) (r0 []models.JobStatusCount, r1 error) {
+	ctx, end := r.in.begin(p0, "MetricsRepository", "GetJobStatusCounts")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetJobStatusCounts(ctx)
+	return
+}
+
+func (r *instrumentedMetricsRepository) CountOverdueTaxReports(p0 context.Context, p1 time.Time) (r0 int, r1 error) {
+	ctx, end := r.in.begin(p0, "MetricsRepository", "CountOverdueTaxReports")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.CountOverdueTaxReports(ctx, p1)
+	return
+}
+
+func (r *instrumentedMetricsRepository) GetUnpaidInvoiceSummary(p0 context.Context) (r0 *models.UnpaidInvoiceSummary, r1 error) {
+	ctx, end := r.in.begin(p0, "MetricsRepository", "GetUnpaidInvoiceSummary")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetUnpaidInvoiceSummary(ctx)
+	return
+}
+
+// instrumentedMonthlyJobRepository runs every MonthlyJobRepository call through an Instrumentation.
+type instrumentedMonthlyJobRepository struct {
+	next MonthlyJobRepository
+	in   *Instrumentation
+}
+
+// InstrumentMonthlyJobRepository wraps next so every call goes through in.
+func InstrumentMonthlyJobRepository(next MonthlyJobRepository, in *Instrumentation) MonthlyJobRepository {
+	return &instrumentedMonthlyJobRepository{next: next, in: in}
+}
+
+func (r *instrumentedMonthlyJobRepository) GetMonthlyTaxReportByID(p0 context.Context, p1 string) (r0 *models.MonthlyTaxReport, r1 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "GetMonthlyTaxReportByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetMonthlyTaxReportByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) CreateMonthlyJob(p0 context.Context, p1 *models.MonthlyJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "CreateMonthlyJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateMonthlyJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) GetAllMonthlyJobs(p0 context.Context, p1 string, p2 bool) (r0 []models.MonthlyJob, r1 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "GetAllMonthlyJobs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllMonthlyJobs(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) EachMonthlyJob(p0 context.Context, p1 string, p2 bool, p3 func(*models.MonthlyJob) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "EachMonthlyJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachMonthlyJob(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) GetMonthlyJobByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.MonthlyJob, r1 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "GetMonthlyJobByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetMonthlyJobByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) UpdateMonthlyJob(p0 context.Context, p1 *models.MonthlyJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "UpdateMonthlyJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateMonthlyJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) CreateMonthlyTaxReport(p0 context.Context, p1 *models.MonthlyTaxReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "CreateMonthlyTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateMonthlyTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) UpdateMonthlyTaxReport(p0 context.Context, p1 *models.MonthlyTaxReport) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "UpdateMonthlyTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateMonthlyTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) DeleteMonthlyTaxReport(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "DeleteMonthlyTaxReport")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteMonthlyTaxReport(ctx, p1)
+	return
+}
+
+func (r *instrumentedMonthlyJobRepository) GetMonthlyJobsByClientID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 []models.MonthlyJob, r1 error) {
+	ctx, end := r.in.begin(p0, "MonthlyJobRepository", "GetMonthlyJobsByClientID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetMonthlyJobsByClientID(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedNotificationRepository runs every NotificationRepository call through an Instrumentation.
+type instrumentedNotificationRepository struct {
+	next NotificationRepository
+	in   *Instrumentation
+}
+
+// InstrumentNotificationRepository wraps next so every call goes through in.
+func InstrumentNotificationRepository(next NotificationRepository, in *Instrumentation) NotificationRepository {
+	return &instrumentedNotificationRepository{next: next, in: in}
+}
+
+func (r *instrumentedNotificationRepository) GetPreferences(p0 context.Context, p1 string) (r0 *models.NotificationPreferences, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "GetPreferences")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetPreferences(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) UpsertPreferences(p0 context.Context, p1 *models.NotificationPreferences) (r0 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "UpsertPreferences")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpsertPreferences(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) ClaimNotification(p0 context.Context, p1 string, p2 string, p3 string) (r0 bool, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "ClaimNotification")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.ClaimNotification(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedNotificationRepository) ReleaseNotification(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "ReleaseNotification")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.ReleaseNotification(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) GetOpenJobDeadlines(p0 context.Context) (r0 []models.JobDeadline, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "GetOpenJobDeadlines")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetOpenJobDeadlines(ctx)
+	return
+}
+
+func (r *instrumentedNotificationRepository) GetOverdueInvoices(p0 context.Context, p1 time.Time) (r0 []models.OverdueInvoice, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "GetOverdueInvoices")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetOverdueInvoices(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) CreateNotification(p0 context.Context, p1 *models.Notification) (r0 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "CreateNotification")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateNotification(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) GetNotifications(p0 context.Context, p1 string, p2 bool, p3 int, p4 *time.Time) (r0 []models.Notification, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "GetNotifications")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetNotifications(ctx, p1, p2, p3, p4)
+	return
+}
+
+func (r *instrumentedNotificationRepository) CountUnreadNotifications(p0 context.Context, p1 string) (r0 int, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "CountUnreadNotifications")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.CountUnreadNotifications(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) MarkNotificationRead(p0 context.Context, p1 string, p2 string) (r0 *models.Notification, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "MarkNotificationRead")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.MarkNotificationRead(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedNotificationRepository) MarkAllNotificationsRead(p0 context.Context, p1 string) (r0 int64, r1 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "MarkAllNotificationsRead")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.MarkAllNotificationsRead(ctx, p1)
+	return
+}
+
+func (r *instrumentedNotificationRepository) PublishEvent(p0 context.Context, p1 *models.RealtimeEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "NotificationRepository", "PublishEvent")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.PublishEvent(ctx, p1)
+	return
+}
+
+// instrumentedOutboxRepository runs every OutboxRepository call through an Instrumentation.
+type instrumentedOutboxRepository struct {
+	next OutboxRepository
+	in   *Instrumentation
+}
+
+// InstrumentOutboxRepository wraps next so every call goes through in.
+func InstrumentOutboxRepository(next OutboxRepository, in *Instrumentation) OutboxRepository {
+	return &instrumentedOutboxRepository{next: next, in: in}
+}
+
+func (r *instrumentedOutboxRepository) ClaimPendingEvents(p0 context.Context, p1 int, p2 time.Duration) (r0 []models.DomainEvent, r1 error) {
+	ctx, end := r.in.begin(p0, "OutboxRepository", "ClaimPendingEvents")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.ClaimPendingEvents(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedOutboxRepository) GetHandledSubscribers(p0 context.Context, p1 string) (r0 map[string]bool, r1 error) {
+	ctx, end := r.in.begin(p0, "OutboxRepository", "GetHandledSubscribers")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetHandledSubscribers(ctx, p1)
+	return
+}
+
+func (r *instrumentedOutboxRepository) MarkHandled(p0 context.Context, p1 string, p2 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "OutboxRepository", "MarkHandled")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.MarkHandled(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedOutboxRepository) MarkDispatched(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "OutboxRepository", "MarkDispatched")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.MarkDispatched(ctx, p1)
+	return
+}
+
+func (r *instrumentedOutboxRepository) MarkFailed(p0 context.Context, p1 string, p2 string, p3 time.Time) (r0 error) {
+	ctx, end := r.in.begin(p0, "OutboxRepository", "MarkFailed")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.MarkFailed(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedPasswordResetRepository runs every PasswordResetRepository call through an Instrumentation.
+type instrumentedPasswordResetRepository struct {
+	next PasswordResetRepository
+	in   *Instrumentation
+}
+
+// InstrumentPasswordResetRepository wraps next so every call goes through in.
+func InstrumentPasswordResetRepository(next PasswordResetRepository, in *Instrumentation) PasswordResetRepository {
+	return &instrumentedPasswordResetRepository{next: next, in: in}
+}
+
+func (r *instrumentedPasswordResetRepository) CreateResetToken(p0 context.Context, p1 *models.PasswordResetToken) (r0 error) {
+	ctx, end := r.in.begin(p0, "PasswordResetRepository", "CreateResetToken")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateResetToken(ctx, p1)
+	return
+}
+
+func (r *instrumentedPasswordResetRepository) GetResetToken(p0 context.Context, p1 string) (r0 *models.PasswordResetToken, r1 error) {
+	ctx, end := r.in.begin(p0, "PasswordResetRepository", "GetResetToken")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetResetToken(ctx, p1)
+	return
+}
+
+func (r *instrumentedPasswordResetRepository) ConsumeResetToken(p0 context.Context, p1 string, p2 string) (r0 string, r1 error) {
+	ctx, end := r.in.begin(p0, "PasswordResetRepository", "ConsumeResetToken")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.ConsumeResetToken(ctx, p1, p2)
+	return
+}
+
+// instrumentedPemeriksaanJobRepository runs every PemeriksaanJobRepository call through an Instrumentation.
+type instrumentedPemeriksaanJobRepository struct {
+	next PemeriksaanJobRepository
+	in   *Instrumentation
+}
+
+// InstrumentPemeriksaanJobRepository wraps next so every call goes through in.
+func InstrumentPemeriksaanJobRepository(next PemeriksaanJobRepository, in *Instrumentation) PemeriksaanJobRepository {
+	return &instrumentedPemeriksaanJobRepository{next: next, in: in}
+}
+
+func (r *instrumentedPemeriksaanJobRepository) CreatePemeriksaanJob(p0 context.Context, p1 *models.PemeriksaanJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "CreatePemeriksaanJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreatePemeriksaanJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) GetAllPemeriksaanJobs(p0 context.Context, p1 string, p2 bool) (r0 []models.PemeriksaanJob, r1 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "GetAllPemeriksaanJobs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllPemeriksaanJobs(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) EachPemeriksaanJob(p0 context.Context, p1 string, p2 bool, p3 func(*models.PemeriksaanJob) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "EachPemeriksaanJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachPemeriksaanJob(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) GetPemeriksaanJobByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.PemeriksaanJob, r1 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "GetPemeriksaanJobByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetPemeriksaanJobByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) UpdatePemeriksaanJob(p0 context.Context, p1 *models.PemeriksaanJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "UpdatePemeriksaanJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdatePemeriksaanJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) DeletePemeriksaanJob(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "DeletePemeriksaanJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeletePemeriksaanJob(ctx, p1)
+	return
+}
+
+func (r *instrumentedPemeriksaanJobRepository) GetPemeriksaanJobsByClientID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 []models.PemeriksaanJob, r1 error) {
+	ctx, end := r.in.begin(p0, "PemeriksaanJobRepository", "GetPemeriksaanJobsByClientID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetPemeriksaanJobsByClientID(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedPortalRepository runs every PortalRepository call through an Instrumentation.
+type instrumentedPortalRepository struct {
+	next PortalRepository
+	in   *Instrumentation
+}
+
+// InstrumentPortalRepository wraps next so every call goes through in.
+func InstrumentPortalRepository(next PortalRepository, in *Instrumentation) PortalRepository {
+	return &instrumentedPortalRepository{next: next, in: in}
+}
+
+func (r *instrumentedPortalRepository) GetJobs(p0 context.Context, p1 string) (r0 []models.PortalJob, r1 error) {
+	ctx, end := r.in.begin(p0, "PortalRepository", "GetJobs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetJobs(ctx, p1)
+	return
+}
+
+func (r *instrumentedPortalRepository) GetTaxReports(p0 context.Context, p1 string, p2 int) (r0 []models.PortalTaxReport, r1 error) {
+	ctx, end := r.in.begin(p0, "PortalRepository", "GetTaxReports")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetTaxReports(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedPortalRepository) GetInvoices(p0 context.Context, p1 string) (r0 []models.PortalInvoice, r1 error) {
+	ctx, end := r.in.begin(p0, "PortalRepository", "GetInvoices")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetInvoices(ctx, p1)
+	return
+}
+
+func (r *instrumentedPortalRepository) GetDocuments(p0 context.Context, p1 string) (r0 []models.PortalDocument, r1 error) {
+	ctx, end := r.in.begin(p0, "PortalRepository", "GetDocuments")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDocuments(ctx, p1)
+	return
+}
+
+// instrumentedRateLimitRepository runs every RateLimitRepository call through an Instrumentation.
+type instrumentedRateLimitRepository struct {
+	next RateLimitRepository
+	in   *Instrumentation
+}
+
+// InstrumentRateLimitRepository wraps next so every call goes through in.
+func InstrumentRateLimitRepository(next RateLimitRepository, in *Instrumentation) RateLimitRepository {
+	return &instrumentedRateLimitRepository{next: next, in: in}
+}
+
+func (r *instrumentedRateLimitRepository) Take(p0 context.Context, p1 string, p2 ratelimit.Limit) (r0 ratelimit.Result, r1 error) {
+	ctx, end := r.in.begin(p0, "RateLimitRepository", "Take")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.Take(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedRateLimitRepository) DeleteIdleBuckets(p0 context.Context) (r0 int64, r1 error) {
+	ctx, end := r.in.begin(p0, "RateLimitRepository", "DeleteIdleBuckets")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.DeleteIdleBuckets(ctx)
+	return
+}
+
+// instrumentedReportRepository runs every ReportRepository call through an Instrumentation.
+type instrumentedReportRepository struct {
+	next ReportRepository
+	in   *Instrumentation
+}
+
+// InstrumentReportRepository wraps next so every call goes through in.
+func InstrumentReportRepository(next ReportRepository, in *Instrumentation) ReportRepository {
+	return &instrumentedReportRepository{next: next, in: in}
+}
+
+func (r *instrumentedReportRepository) GetWorkload(p0 context.Context, p1 models.WorkloadParams, p2 string, p3 bool) (r0 *models.WorkloadReport, r1 error) {
+	ctx, end := r.in.begin(p0, "ReportRepository", "GetWorkload")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetWorkload(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedReportRepository) GetComplianceEntries(p0 context.Context, p1 int, p2 string, p3 bool) (r0 []models.ComplianceEntry, r1 error) {
+	ctx, end := r.in.begin(p0, "ReportRepository", "GetComplianceEntries")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetComplianceEntries(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedReportRepository) GetRevenueEntries(p0 context.Context, p1 []int, p2 string, p3 bool) (r0 []models.RevenueEntry, r1 error) {
+	ctx, end := r.in.begin(p0, "ReportRepository", "GetRevenueEntries")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetRevenueEntries(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedSp2dkJobRepository runs every Sp2dkJobRepository call through an Instrumentation.
+type instrumentedSp2dkJobRepository struct {
+	next Sp2dkJobRepository
+	in   *Instrumentation
+}
+
+// InstrumentSp2dkJobRepository wraps next so every call goes through in.
+func InstrumentSp2dkJobRepository(next Sp2dkJobRepository, in *Instrumentation) Sp2dkJobRepository {
+	return &instrumentedSp2dkJobRepository{next: next, in: in}
+}
+
+func (r *instrumentedSp2dkJobRepository) CreateSp2dkJob(p0 context.Context, p1 *models.Sp2dkJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "CreateSp2dkJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateSp2dkJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) GetAllSp2dkJobs(p0 context.Context, p1 string, p2 bool) (r0 []models.Sp2dkJob, r1 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "GetAllSp2dkJobs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllSp2dkJobs(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) EachSp2dkJob(p0 context.Context, p1 string, p2 bool, p3 func(*models.Sp2dkJob) error) (r0 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "EachSp2dkJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.EachSp2dkJob(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) GetSp2dkJobByID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 *models.Sp2dkJob, r1 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "GetSp2dkJobByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetSp2dkJobByID(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) UpdateSp2dkJob(p0 context.Context, p1 *models.Sp2dkJob, p2 ...models.DomainEvent) (r0 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "UpdateSp2dkJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateSp2dkJob(ctx, p1, p2...)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) DeleteSp2dkJob(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "DeleteSp2dkJob")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteSp2dkJob(ctx, p1)
+	return
+}
+
+func (r *instrumentedSp2dkJobRepository) GetSp2dkJobsByClientID(p0 context.Context, p1 string, p2 string, p3 bool) (r0 []models.Sp2dkJob, r1 error) {
+	ctx, end := r.in.begin(p0, "Sp2dkJobRepository", "GetSp2dkJobsByClientID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetSp2dkJobsByClientID(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedStaffRepository runs every StaffRepository call through an Instrumentation.
+type instrumentedStaffRepository struct {
+	next StaffRepository
+	in   *Instrumentation
+}
+
+// InstrumentStaffRepository wraps next so every call goes through in.
+func InstrumentStaffRepository(next StaffRepository, in *Instrumentation) StaffRepository {
+	return &instrumentedStaffRepository{next: next, in: in}
+}
+
+func (r *instrumentedStaffRepository) CreateStaff(p0 context.Context, p1 *models.Staff) (r0 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "CreateStaff")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateStaff(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) GetAllStaffs(p0 context.Context) (r0 []models.Staff, r1 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "GetAllStaffs")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllStaffs(ctx)
+	return
+}
+
+func (r *instrumentedStaffRepository) GetStaffByID(p0 context.Context, p1 string) (r0 *models.Staff, r1 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "GetStaffByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetStaffByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) GetStaffByEmail(p0 context.Context, p1 string) (r0 *models.Staff, r1 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "GetStaffByEmail")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetStaffByEmail(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) UpdateStaff(p0 context.Context, p1 *models.Staff) (r0 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "UpdateStaff")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateStaff(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) DeleteStaff(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "DeleteStaff")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteStaff(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) RecordFailedLogin(p0 context.Context, p1 string, p2 int, p3 time.Duration) (r0 *time.Time, r1 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "RecordFailedLogin")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.RecordFailedLogin(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedStaffRepository) ResetFailedLogins(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "ResetFailedLogins")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.ResetFailedLogins(ctx, p1)
+	return
+}
+
+func (r *instrumentedStaffRepository) SetPassword(p0 context.Context, p1 string, p2 string, p3 bool) (r0 error) {
+	ctx, end := r.in.begin(p0, "StaffRepository", "SetPassword")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.SetPassword(ctx, p1, p2, p3)
+	return
+}
+
+// instrumentedTaxImportRepository runs every TaxImportRepository call through an Instrumentation.
+type instrumentedTaxImportRepository struct {
+	next TaxImportRepository
+	in   *Instrumentation
+}
+
+// InstrumentTaxImportRepository wraps next so every call goes through in.
+func InstrumentTaxImportRepository(next TaxImportRepository, in *Instrumentation) TaxImportRepository {
+	return &instrumentedTaxImportRepository{next: next, in: in}
+}
+
+func (r *instrumentedTaxImportRepository) SaveImportSources(p0 context.Context, p1 string, p2 []models.TaxImportSource) (r0 *models.TaxImportTotal, r1 error) {
+	ctx, end := r.in.begin(p0, "TaxImportRepository", "SaveImportSources")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.SaveImportSources(ctx, p1, p2)
+	return
+}
+
+// instrumentedTwoFactorRepository runs every TwoFactorRepository call through an Instrumentation.
+type instrumentedTwoFactorRepository struct {
+	next TwoFactorRepository
+	in   *Instrumentation
+}
+
+// InstrumentTwoFactorRepository wraps next so every call goes through in.
+func InstrumentTwoFactorRepository(next TwoFactorRepository, in *Instrumentation) TwoFactorRepository {
+	return &instrumentedTwoFactorRepository{next: next, in: in}
+}
+
+func (r *instrumentedTwoFactorRepository) GetTOTP(p0 context.Context, p1 string) (r0 *models.StaffTOTP, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "GetTOTP")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetTOTP(ctx, p1)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) SavePendingTOTP(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "SavePendingTOTP")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.SavePendingTOTP(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) EnableTOTP(p0 context.Context, p1 string, p2 int64, p3 []string) (r0 bool, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "EnableTOTP")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.EnableTOTP(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) UseTOTPCounter(p0 context.Context, p1 string, p2 int64) (r0 bool, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "UseTOTPCounter")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.UseTOTPCounter(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) UseRecoveryCode(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "UseRecoveryCode")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.UseRecoveryCode(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) ReplaceRecoveryCodes(p0 context.Context, p1 string, p2 []string) (r0 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "ReplaceRecoveryCodes")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.ReplaceRecoveryCodes(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) CountRecoveryCodes(p0 context.Context, p1 string) (r0 int, r1 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "CountRecoveryCodes")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.CountRecoveryCodes(ctx, p1)
+	return
+}
+
+func (r *instrumentedTwoFactorRepository) DeleteTwoFactor(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "TwoFactorRepository", "DeleteTwoFactor")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteTwoFactor(ctx, p1)
+	return
+}
+
+// instrumentedWebhookRepository runs every WebhookRepository call through an Instrumentation.
+type instrumentedWebhookRepository struct {
+	next WebhookRepository
+	in   *Instrumentation
+}
+
+// InstrumentWebhookRepository wraps next so every call goes through in.
+func InstrumentWebhookRepository(next WebhookRepository, in *Instrumentation) WebhookRepository {
+	return &instrumentedWebhookRepository{next: next, in: in}
+}
+
+func (r *instrumentedWebhookRepository) CreateSubscription(p0 context.Context, p1 *models.WebhookSubscription) (r0 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "CreateSubscription")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.CreateSubscription(ctx, p1)
+	return
+}
+
+func (r *instrumentedWebhookRepository) GetAllSubscriptions(p0 context.Context) (r0 []models.WebhookSubscription, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "GetAllSubscriptions")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetAllSubscriptions(ctx)
+	return
+}
+
+func (r *instrumentedWebhookRepository) GetSubscriptionByID(p0 context.Context, p1 string) (r0 *models.WebhookSubscription, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "GetSubscriptionByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetSubscriptionByID(ctx, p1)
+	return
+}
+
+func (r *instrumentedWebhookRepository) UpdateSubscription(p0 context.Context, p1 *models.WebhookSubscription) (r0 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "UpdateSubscription")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.UpdateSubscription(ctx, p1)
+	return
+}
+
+func (r *instrumentedWebhookRepository) DeleteSubscription(p0 context.Context, p1 string) (r0 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "DeleteSubscription")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.DeleteSubscription(ctx, p1)
+	return
+}
+
+func (r *instrumentedWebhookRepository) CreateDeliveries(p0 context.Context, p1 string, p2 string, p3 []byte) (r0 int64, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "CreateDeliveries")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.CreateDeliveries(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedWebhookRepository) Redeliver(p0 context.Context, p1 string) (r0 *models.WebhookDelivery, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "Redeliver")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.Redeliver(ctx, p1)
+	return
+}
+
+func (r *instrumentedWebhookRepository) ClaimDueDeliveries(p0 context.Context, p1 int, p2 time.Duration) (r0 []models.WebhookDelivery, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "ClaimDueDeliveries")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.ClaimDueDeliveries(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedWebhookRepository) RecordAttempt(p0 context.Context, p1 string, p2 models.WebhookAttemptResult) (r0 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "RecordAttempt")
+	defer func() { r0 = end(r0) }()
+	r0 = r.next.RecordAttempt(ctx, p1, p2)
+	return
+}
+
+func (r *instrumentedWebhookRepository) GetDeliveries(p0 context.Context, p1 string, p2 string, p3 int) (r0 []models.WebhookDelivery, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "GetDeliveries")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDeliveries(ctx, p1, p2, p3)
+	return
+}
+
+func (r *instrumentedWebhookRepository) GetDeliveryByID(p0 context.Context, p1 string) (r0 *models.WebhookDelivery, r1 error) {
+	ctx, end := r.in.begin(p0, "WebhookRepository", "GetDeliveryByID")
+	defer func() { r1 = end(r1) }()
+	r0, r1 = r.next.GetDeliveryByID(ctx, p1)
+	return
+}
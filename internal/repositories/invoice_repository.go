@@ -1,7 +1,9 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt" // Untuk string manipulation (invoice number)
 	"time"
 
@@ -10,18 +12,27 @@ import (
 
 // InvoiceRepository defines the interface for invoice data operations
 type InvoiceRepository interface {
-	CreateInvoice(invoice *models.Invoice) error
-	GetAllInvoices(staffIDFilter string, isAdmin bool) ([]models.Invoice, error)
-	GetInvoiceByID(id string, staffIDFilter string, isAdmin bool) (*models.Invoice, error)
-	UpdateInvoice(invoice *models.Invoice) error // Update header info
-	DeleteInvoice(id string) error
+	// CreateInvoice also writes an InvoiceIssued event to the outbox, and InvoicePaid if the invoice
+	// is created as Paid. If invoice.SourceEventID is set and an invoice was already created from
+	// that event, it returns ErrInvoiceAlreadyIssued.
+	CreateInvoice(ctx context.Context, invoice *models.Invoice) error
+	GetAllInvoices(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Invoice, error)
+	// EachInvoice is the streaming form of GetAllInvoices, see MonthlyJobRepository.EachMonthlyJob.
+	EachInvoice(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Invoice) error) error
+	GetInvoiceByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Invoice, error)
+	// UpdateInvoice updates the header info and writes events to the outbox in the same transaction.
+	UpdateInvoice(ctx context.Context, invoice *models.Invoice, events ...models.DomainEvent) error
+	DeleteInvoice(ctx context.Context, id string) error
 
 	// Line item specific operations (if needed for individual line item management)
-	CreateInvoiceLineItem(item *models.InvoiceLineItem) error
-	UpdateInvoiceLineItem(item *models.InvoiceLineItem) error
-	DeleteInvoiceLineItem(itemID string) error
+	CreateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error
+	UpdateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error
+	DeleteInvoiceLineItem(ctx context.Context, itemID string) error
 }
 
+// ErrInvoiceAlreadyIssued is returned by CreateInvoice when the source event already produced an invoice
+var ErrInvoiceAlreadyIssued = errors.New("invoice already issued for this event")
+
 // invoiceRepository implements InvoiceRepository interface
 type invoiceRepository struct {
 	db *sql.DB
@@ -33,16 +44,16 @@ func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
 }
 
 // generateInvoiceNumber generates a unique invoice number (e.g., INV/YYYYMMDD/SEQ)
-func (r *invoiceRepository) generateInvoiceNumber(tx *sql.Tx, invoiceDate time.Time) (string, error) {
+func (r *invoiceRepository) generateInvoiceNumber(ctx context.Context, tx *sql.Tx, invoiceDate time.Time) (string, error) {
 	datePrefix := invoiceDate.Format("20060102") // YYYYMMDD
 	
 	// Get the last sequence number for today
 	var lastSeq int
 	// Query to find the maximum sequence number for today's date
-	seqQuery := `SELECT COALESCE(MAX(SUBSTRING(invoice_number FROM 11 FOR LENGTH(invoice_number))::INT), 0)
+	seqQuery := `SELECT COALESCE(MAX(SUBSTRING(invoice_number FROM 14)::INT), 0) -- Nomor urut setelah "INV/YYYYMMDD/"
                  FROM invoices WHERE invoice_number LIKE $1 || '%' AND invoice_date = $2`
 	
-	err := tx.QueryRow(seqQuery, "INV/"+datePrefix, invoiceDate).Scan(&lastSeq)
+	err := tx.QueryRowContext(ctx, seqQuery, "INV/"+datePrefix, invoiceDate).Scan(&lastSeq)
 	if err != nil && err != sql.ErrNoRows { // sql.ErrNoRows means no invoices yet for today, which is fine
 		return "", fmt.Errorf("failed to get last invoice sequence: %w", err)
 	}
@@ -62,15 +73,27 @@ func (r *invoiceRepository) calculateTotalAmount(lineItems []models.InvoiceLineI
 
 // CreateInvoice inserts a new invoice and its associated line items into the database.
 // This operation is wrapped in a a transaction.
-func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
-	tx, err := r.db.Begin()
+func (r *invoiceRepository) CreateInvoice(ctx context.Context, invoice *models.Invoice) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// 0. Event JobCompleted bisa dikirim ulang; invoice dari event yang sama hanya dibuat sekali
+	if invoice.SourceEventID != nil {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM invoices WHERE source_event_id = $1)`, *invoice.SourceEventID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check invoice source event: %w", err)
+		}
+		if exists {
+			return ErrInvoiceAlreadyIssued
+		}
+	}
+
 	// 1. Generate Invoice Number
-	invoice.InvoiceNumber, err = r.generateInvoiceNumber(tx, invoice.InvoiceDate.Time)
+	invoice.InvoiceNumber, err = r.generateInvoiceNumber(ctx, tx, invoice.InvoiceDate.Time)
 	if err != nil {
 		return fmt.Errorf("failed to generate invoice number: %w", err)
 	}
@@ -95,9 +118,11 @@ func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
 
 	// 4. Insert into invoices table
 	invoiceQuery := `INSERT INTO invoices (
-		invoice_number, client_id, assigned_staff_id, invoice_date, due_date, total_amount, status, notes, created_at, updated_at
+		invoice_number, client_id, assigned_staff_id, invoice_date, due_date, total_amount, status, notes, created_at, updated_at,
+		paid_at, source_event_id
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+		CASE WHEN $7 = '`+models.InvoiceStatusPaid+`' THEN $9::timestamptz END, $11
 	) RETURNING invoice_id, created_at, updated_at`
 
 	if invoice.CreatedAt.IsZero() {
@@ -105,10 +130,10 @@ func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
 	}
 	invoice.UpdatedAt = time.Now()
 
-	err = tx.QueryRow(invoiceQuery,
+	err = tx.QueryRowContext(ctx, invoiceQuery,
 		invoice.InvoiceNumber, invoice.ClientID, assignedStaffID, invoice.InvoiceDate, invoice.DueDate,
 		invoice.TotalAmount, invoice.Status, notes,
-		invoice.CreatedAt, invoice.UpdatedAt,
+		invoice.CreatedAt, invoice.UpdatedAt, invoice.SourceEventID,
 	).Scan(&invoice.InvoiceID, &invoice.CreatedAt, &invoice.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create invoice: %w", err)
@@ -146,7 +171,7 @@ func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
 			}
 			item.UpdatedAt = time.Now()
 
-			err := tx.QueryRow(lineItemQuery,
+			err := tx.QueryRowContext(ctx, lineItemQuery,
 				item.InvoiceID, item.Description, item.Quantity, item.UnitPrice, item.Amount,
 				relatedJobType, relatedJobID,
 				item.CreatedAt, item.UpdatedAt,
@@ -157,11 +182,34 @@ func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
 		}
 	}
 
+	// 6. Catat event InvoiceIssued (dan InvoicePaid) di outbox, dalam transaksi yang sama
+	events, err := models.NewInvoiceCreatedEvents(*invoice)
+	if err != nil {
+		return err
+	}
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // GetAllInvoices fetches all invoices with their associated client and staff info.
-func (r *invoiceRepository) GetAllInvoices(staffIDFilter string, isAdmin bool) ([]models.Invoice, error) {
+func (r *invoiceRepository) GetAllInvoices(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Invoice, error) {
+	var invoicesList []models.Invoice
+	err := r.EachInvoice(ctx, staffIDFilter, isAdmin, func(invoice *models.Invoice) error {
+		invoicesList = append(invoicesList, *invoice)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoicesList, nil
+}
+
+// EachInvoice fetches all invoices with their associated client and staff info, calling fn
+// for each invoice as soon as its line items are read.
+func (r *invoiceRepository) EachInvoice(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Invoice) error) error {
 	query := `
 	SELECT
 		i.invoice_id, i.invoice_number, i.client_id, c.client_name, c.npwp_client,
@@ -185,16 +233,15 @@ func (r *invoiceRepository) GetAllInvoices(staffIDFilter string, isAdmin bool) (
 		paramCounter++
 	}
 
-	query += " ORDER BY i.invoice_date DESC, i.invoice_number DESC, ili.line_item_id ASC;"
+	query += " ORDER BY i.invoice_date DESC, i.invoice_number DESC, i.invoice_id, ili.line_item_id ASC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all invoices: %w", err)
+		return fmt.Errorf("failed to get all invoices: %w", err)
 	}
 	defer rows.Close()
 
-	invoicesMap := make(map[string]*models.Invoice)
-	var invoicesList []models.Invoice
+	var invoice *models.Invoice // Invoice yang sedang dibaca; barisnya berurutan karena ORDER BY invoice_id
 
 	for rows.Next() {
 		var (
@@ -233,11 +280,15 @@ func (r *invoiceRepository) GetAllInvoices(staffIDFilter string, isAdmin bool) (
 			&relatedJobType, &relatedJobID, &itemCreatedAt, &itemUpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan invoice row: %w", err)
+			return fmt.Errorf("failed to scan invoice row: %w", err)
 		}
 
-		invoice, ok := invoicesMap[invoiceID]
-		if !ok {
+		if invoice == nil || invoice.InvoiceID != invoiceID {
+			if invoice != nil {
+				if err := fn(invoice); err != nil {
+					return err
+				}
+			}
 			invoice = &models.Invoice{
 				InvoiceID:     invoiceID,
 				InvoiceNumber: invoiceNumber,
@@ -267,8 +318,6 @@ func (r *invoiceRepository) GetAllInvoices(staffIDFilter string, isAdmin bool) (
 			} else {
 				invoice.Notes = nil
 			}
-			invoicesMap[invoiceID] = invoice
-			invoicesList = append(invoicesList, *invoice)
 		}
 
 		// Add line item if exists
@@ -298,14 +347,17 @@ func (r *invoiceRepository) GetAllInvoices(staffIDFilter string, isAdmin bool) (
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration for invoices: %w", err)
+		return fmt.Errorf("error during rows iteration for invoices: %w", err)
 	}
 
-	return invoicesList, nil
+	if invoice != nil {
+		return fn(invoice)
+	}
+	return nil
 }
 
 // GetInvoiceByID fetches a single invoice with its associated client, staff, and line items.
-func (r *invoiceRepository) GetInvoiceByID(id string, staffIDFilter string, isAdmin bool) (*models.Invoice, error) {
+func (r *invoiceRepository) GetInvoiceByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Invoice, error) {
 	query := `
 	SELECT
 		i.invoice_id, i.invoice_number, i.client_id, c.client_name, c.npwp_client,
@@ -331,7 +383,7 @@ func (r *invoiceRepository) GetInvoiceByID(id string, staffIDFilter string, isAd
 
 	query += " ORDER BY ili.line_item_id ASC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get invoice by ID: %w", err)
 	}
@@ -448,7 +500,7 @@ func (r *invoiceRepository) GetInvoiceByID(id string, staffIDFilter string, isAd
 }
 
 // UpdateInvoice updates an existing invoice header info. Total amount is re-calculated.
-func (r *invoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
+func (r *invoiceRepository) UpdateInvoice(ctx context.Context, invoice *models.Invoice, events ...models.DomainEvent) error {
 	// Re-calculate Total Amount based on current line items (assumes line items are managed externally or passed fully)
 	// For a PATCH on header, you'd usually only update header fields.
 	// If total_amount needs to be dynamic, it would be calculated in the handler based on fetched line items.
@@ -474,12 +526,19 @@ func (r *invoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
 
 	query := `UPDATE invoices SET
 		client_id = $1, assigned_staff_id = $2, invoice_date = $3, due_date = $4,
-		total_amount = $5, status = $6, notes = $7, updated_at = $8
+		total_amount = $5, status = $6, notes = $7, updated_at = $8,
+		paid_at = CASE WHEN $6 = '`+models.InvoiceStatusPaid+`' THEN COALESCE(paid_at, $8) ELSE NULL END
 	WHERE invoice_id = $9`
 
 	invoice.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query,
 		invoice.ClientID, assignedStaffID, invoice.InvoiceDate, invoice.DueDate,
 		invoice.TotalAmount, invoice.Status, notes,
 		invoice.UpdatedAt, invoice.InvoiceID,
@@ -488,13 +547,16 @@ func (r *invoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
 	if err != nil {
 		return fmt.Errorf("failed to update invoice: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DeleteInvoice deletes an invoice by its ID. Line items are deleted via CASCADE.
-func (r *invoiceRepository) DeleteInvoice(id string) error {
+func (r *invoiceRepository) DeleteInvoice(ctx context.Context, id string) error {
 	query := `DELETE FROM invoices WHERE invoice_id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete invoice: %w", err)
 	}
@@ -502,7 +564,7 @@ func (r *invoiceRepository) DeleteInvoice(id string) error {
 }
 
 // CreateInvoiceLineItem inserts a new line item for an existing invoice.
-func (r *invoiceRepository) CreateInvoiceLineItem(item *models.InvoiceLineItem) error {
+func (r *invoiceRepository) CreateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error {
 	// Re-calculate amount for line item
 	item.Amount = item.Quantity * item.UnitPrice
 
@@ -532,7 +594,7 @@ func (r *invoiceRepository) CreateInvoiceLineItem(item *models.InvoiceLineItem)
 	}
 	item.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		item.InvoiceID, item.Description, item.Quantity, item.UnitPrice, item.Amount,
 		relatedJobType, relatedJobID,
 		item.CreatedAt, item.UpdatedAt,
@@ -547,7 +609,7 @@ func (r *invoiceRepository) CreateInvoiceLineItem(item *models.InvoiceLineItem)
 }
 
 // UpdateInvoiceLineItem updates an existing line item.
-func (r *invoiceRepository) UpdateInvoiceLineItem(item *models.InvoiceLineItem) error {
+func (r *invoiceRepository) UpdateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error {
 	// Re-calculate amount for line item if quantity or unit_price are updated (handled in handler)
 	// For PATCH, quantity/unit_price might be nil. Amount should be calculated in handler if they are provided.
 	
@@ -573,7 +635,7 @@ func (r *invoiceRepository) UpdateInvoiceLineItem(item *models.InvoiceLineItem)
 
 	item.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		item.Description, item.Quantity, item.UnitPrice, item.Amount,
 		relatedJobType, relatedJobID,
 		item.UpdatedAt, item.LineItemID,
@@ -587,9 +649,9 @@ func (r *invoiceRepository) UpdateInvoiceLineItem(item *models.InvoiceLineItem)
 }
 
 // DeleteInvoiceLineItem deletes a line item by its ID.
-func (r *invoiceRepository) DeleteInvoiceLineItem(itemID string) error {
+func (r *invoiceRepository) DeleteInvoiceLineItem(ctx context.Context, itemID string) error {
 	query := `DELETE FROM invoice_line_items WHERE line_item_id = $1`
-	_, err := r.db.Exec(query, itemID)
+	_, err := r.db.ExecContext(ctx, query, itemID)
 	if err != nil {
 		return fmt.Errorf("failed to delete invoice line item: %w", err)
 	}
@@ -0,0 +1,371 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
+)
+
+// annualJobRepository implements repositories.AnnualJobRepository in memory
+type annualJobRepository struct {
+	db *DB
+}
+
+// NewAnnualJobRepository creates an in-memory AnnualJobRepository backed by db
+func NewAnnualJobRepository(db *DB) repositories.AnnualJobRepository {
+	return &annualJobRepository{db: db}
+}
+
+// CreateAnnualJob stores the job with its first tax and dividend report and writes events to the outbox
+func (r *annualJobRepository) CreateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row := *job
+	row.ProofOfWorkURL = nil // Bukti pekerjaan baru diisi lewat UpdateAnnualJob
+	if row.JobID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		row.JobID = id
+	} else if _, ok := r.db.annualJobs[row.JobID]; ok {
+		return fmt.Errorf("failed to create annual job: %w", duplicate("annual_jobs_pkey"))
+	}
+	if err := r.db.checkAnnualJobRow(row); err != nil {
+		return fmt.Errorf("failed to create annual job: %w", err)
+	}
+	taxReportID, err := newID()
+	if err != nil {
+		return err
+	}
+	dividendReportID, err := newID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	r.db.putAnnualJob(row)
+
+	job.JobID = row.JobID
+	job.CreatedAt = row.CreatedAt
+	job.UpdatedAt = row.UpdatedAt
+	// Seperti repository Postgres, hanya laporan pertama dari tiap jenis yang disimpan
+	if len(job.TaxReports) > 0 {
+		report := &job.TaxReports[0]
+		report.ReportID = taxReportID
+		report.JobID = job.JobID
+		if report.CreatedAt.IsZero() {
+			report.CreatedAt = now
+		}
+		report.UpdatedAt = now
+		report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
+		r.db.annualTaxReports[report.ReportID] = cloneAnnualTaxReport(*report)
+	}
+	if len(job.DividendReports) > 0 {
+		report := &job.DividendReports[0]
+		report.ReportID = dividendReportID
+		report.JobID = job.JobID
+		if report.CreatedAt.IsZero() {
+			report.CreatedAt = now
+		}
+		report.UpdatedAt = now
+		r.db.annualDividendReports[report.ReportID] = cloneAnnualDividendReport(*report)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// GetAllAnnualJobs returns the visible jobs, newest year first
+func (r *annualJobRepository) GetAllAnnualJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectAnnualJobs(func(job models.AnnualJob) bool {
+		return visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// EachAnnualJob calls fn for every element of GetAllAnnualJobs
+func (r *annualJobRepository) EachAnnualJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.AnnualJob) error) error {
+	list, err := r.GetAllAnnualJobs(ctx, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// GetAnnualJobByID returns sql.ErrNoRows if the job does not exist or is not visible
+func (r *annualJobRepository) GetAnnualJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.AnnualJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.annualJobs[id]
+	if !ok || !visible(row.AssignedPicStaffSigmaID, staffIDFilter, isAdmin) {
+		return nil, sql.ErrNoRows
+	}
+	job := r.db.joinAnnualJob(row)
+	return &job, nil
+}
+
+// GetAnnualJobsByClientID returns the visible jobs of a client, newest year first
+func (r *annualJobRepository) GetAnnualJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.AnnualJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectAnnualJobs(func(job models.AnnualJob) bool {
+		return job.ClientID == clientID && visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// UpdateAnnualJob saves the main job fields and writes events to the outbox
+func (r *annualJobRepository) UpdateAnnualJob(ctx context.Context, job *models.AnnualJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	row, ok := r.db.annualJobs[job.JobID]
+	if ok {
+		row.ClientID = job.ClientID
+		row.JobYear = job.JobYear
+		row.AssignedPicStaffSigmaID = job.AssignedPicStaffSigmaID
+		row.OverallStatus = job.OverallStatus
+		row.ProofOfWorkURL = nil
+		if job.ProofOfWorkURL != nil && *job.ProofOfWorkURL != "" {
+			row.ProofOfWorkURL = clonePtr(job.ProofOfWorkURL)
+		}
+		row.UpdatedAt = job.UpdatedAt
+		if err := r.db.checkAnnualJobRow(row); err != nil {
+			return fmt.Errorf("failed to update annual job: %w", err)
+		}
+		r.db.putAnnualJob(row)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// CreateAnnualTaxReport adds the tax report of an existing job; a job has at most one
+func (r *annualJobRepository) CreateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.annualJobs[report.JobID]; !ok {
+		return fmt.Errorf("failed to create annual tax report: annual job %s does not exist", report.JobID)
+	}
+	for _, existing := range r.db.annualTaxReports {
+		if existing.JobID == report.JobID {
+			return fmt.Errorf("failed to create annual tax report: %w", duplicate("unique_annual_tax_report_per_job"))
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	report.ReportID = id
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+	report.UpdatedAt = time.Now()
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
+	r.db.annualTaxReports[id] = cloneAnnualTaxReport(*report)
+	return nil
+}
+
+// GetAnnualTaxReportByID returns sql.ErrNoRows if the report does not exist
+func (r *annualJobRepository) GetAnnualTaxReportByID(ctx context.Context, reportID string) (*models.AnnualTaxReport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.annualTaxReports[reportID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	report := cloneAnnualTaxReport(row)
+	return &report, nil
+}
+
+// UpdateAnnualTaxReport saves an existing tax report; its job cannot be changed
+func (r *annualJobRepository) UpdateAnnualTaxReport(ctx context.Context, report *models.AnnualTaxReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	report.UpdatedAt = time.Now()
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
+	if existing, ok := r.db.annualTaxReports[report.ReportID]; ok {
+		row := cloneAnnualTaxReport(*report)
+		row.JobID = existing.JobID
+		row.CreatedAt = existing.CreatedAt
+		r.db.annualTaxReports[row.ReportID] = row
+	}
+	return nil
+}
+
+// DeleteAnnualTaxReport deletes a tax report; a missing report is not an error
+func (r *annualJobRepository) DeleteAnnualTaxReport(ctx context.Context, reportID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.annualTaxReports, reportID)
+	return nil
+}
+
+// CreateAnnualDividendReport adds the dividend report of an existing job; a job has at most one
+func (r *annualJobRepository) CreateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.annualJobs[report.JobID]; !ok {
+		return fmt.Errorf("failed to create annual dividend report: annual job %s does not exist", report.JobID)
+	}
+	for _, existing := range r.db.annualDividendReports {
+		if existing.JobID == report.JobID {
+			return fmt.Errorf("failed to create annual dividend report: %w", duplicate("unique_annual_dividend_report_per_job"))
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	report.ReportID = id
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+	report.UpdatedAt = time.Now()
+	r.db.annualDividendReports[id] = cloneAnnualDividendReport(*report)
+	return nil
+}
+
+// GetAnnualDividendReportByID returns sql.ErrNoRows if the report does not exist
+func (r *annualJobRepository) GetAnnualDividendReportByID(ctx context.Context, reportID string) (*models.AnnualDividendReport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.annualDividendReports[reportID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	report := cloneAnnualDividendReport(row)
+	return &report, nil
+}
+
+// UpdateAnnualDividendReport saves an existing dividend report; its job cannot be changed
+func (r *annualJobRepository) UpdateAnnualDividendReport(ctx context.Context, report *models.AnnualDividendReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	report.UpdatedAt = time.Now()
+	if existing, ok := r.db.annualDividendReports[report.ReportID]; ok {
+		existing.IsReported = report.IsReported
+		existing.ReportDate = clonePtr(report.ReportDate)
+		existing.ReportStatus = report.ReportStatus
+		existing.UpdatedAt = report.UpdatedAt
+		r.db.annualDividendReports[existing.ReportID] = existing
+	}
+	return nil
+}
+
+// DeleteAnnualDividendReport deletes a dividend report; a missing report is not an error
+func (r *annualJobRepository) DeleteAnnualDividendReport(ctx context.Context, reportID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.annualDividendReports, reportID)
+	return nil
+}
+
+// checkAnnualJobRow checks unique_annual_job_per_client and the foreign keys of row
+func (db *DB) checkAnnualJobRow(row models.AnnualJob) error {
+	if err := db.checkClient(row.ClientID); err != nil {
+		return err
+	}
+	if err := db.checkStaff(row.AssignedPicStaffSigmaID); err != nil {
+		return err
+	}
+	for id, job := range db.annualJobs {
+		if id != row.JobID && job.ClientID == row.ClientID && job.JobYear == row.JobYear {
+			return duplicate("unique_annual_job_per_client")
+		}
+	}
+	return nil
+}
+
+// putAnnualJob stores row without the joined columns and nested reports
+func (db *DB) putAnnualJob(row models.AnnualJob) {
+	row.ClientName = ""
+	row.NpwpClient = ""
+	row.AssignedPicStaffSigmaName = ""
+	row.ProofOfWorkURL = clonePtr(row.ProofOfWorkURL)
+	row.TaxReports = nil
+	row.DividendReports = nil
+	db.annualJobs[row.JobID] = row
+}
+
+// joinAnnualJob fills the client and staff columns and the reports of the job
+func (db *DB) joinAnnualJob(row models.AnnualJob) models.AnnualJob {
+	client := db.clients[row.ClientID]
+	row.ClientName = client.ClientName
+	row.NpwpClient = client.NpwpClient
+	row.AssignedPicStaffSigmaName = db.staffName(row.AssignedPicStaffSigmaID)
+	row.ProofOfWorkURL = clonePtr(row.ProofOfWorkURL)
+	row.TaxReports = []models.AnnualTaxReport{}
+	for _, report := range db.annualTaxReports {
+		if report.JobID == row.JobID {
+			row.TaxReports = append(row.TaxReports, cloneAnnualTaxReport(report))
+		}
+	}
+	row.DividendReports = []models.AnnualDividendReport{}
+	for _, report := range db.annualDividendReports {
+		if report.JobID == row.JobID {
+			row.DividendReports = append(row.DividendReports, cloneAnnualDividendReport(report))
+		}
+	}
+	return row
+}
+
+// selectAnnualJobs returns the jobs matching keep ordered by year descending, then newest first
+func (db *DB) selectAnnualJobs(keep func(models.AnnualJob) bool) []models.AnnualJob {
+	var jobs []models.AnnualJob
+	for _, row := range db.annualJobs {
+		if keep(row) {
+			jobs = append(jobs, db.joinAnnualJob(row))
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].JobYear != jobs[j].JobYear {
+			return jobs[i].JobYear > jobs[j].JobYear
+		}
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// deleteAnnualJob deletes a job with its reports (ON DELETE CASCADE)
+func (db *DB) deleteAnnualJob(id string) {
+	delete(db.annualJobs, id)
+	for reportID, report := range db.annualTaxReports {
+		if report.JobID == id {
+			delete(db.annualTaxReports, reportID)
+		}
+	}
+	for reportID, report := range db.annualDividendReports {
+		if report.JobID == id {
+			delete(db.annualDividendReports, reportID)
+		}
+	}
+}
+
+// cloneAnnualTaxReport copies report including the values behind its pointer fields
+func cloneAnnualTaxReport(report models.AnnualTaxReport) models.AnnualTaxReport {
+	report.PaymentDate = clonePtr(report.PaymentDate)
+	report.PaymentAmount = clonePtr(report.PaymentAmount)
+	report.ReportDate = clonePtr(report.ReportDate)
+	report.BillingCodeExpiresAt = clonePtr(report.BillingCodeExpiresAt)
+	report.BillingAmount = clonePtr(report.BillingAmount)
+	return report
+}
+
+// cloneAnnualDividendReport copies report including the value behind ReportDate
+func cloneAnnualDividendReport(report models.AnnualDividendReport) models.AnnualDividendReport {
+	report.ReportDate = clonePtr(report.ReportDate)
+	return report
+}
@@ -0,0 +1,296 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// clientRepository implements repositories.ClientRepository in memory
+type clientRepository struct {
+	db *DB
+}
+
+// NewClientRepository creates an in-memory ClientRepository backed by db
+func NewClientRepository(db *DB) repositories.ClientRepository {
+	return &clientRepository{db: db}
+}
+
+// CreateClient hashes the Coretax password, stores the client and writes a ClientCreated event
+// to the outbox
+func (r *clientRepository) CreateClient(ctx context.Context, client *models.Client) error {
+	hashedPassword, err := utils.HashPassword(client.CoretaxPasswordHashed)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	row := *client
+	row.ClientID = id
+	row.CoretaxPasswordHashed = hashedPassword
+	if err := r.db.checkClientRow(row); err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	row.UpdatedAt = time.Now()
+	created, err := models.NewClientCreatedEvent(row)
+	if err != nil {
+		return err
+	}
+	r.db.putClient(row)
+	r.db.writeOutbox([]models.DomainEvent{created})
+
+	client.ClientID = row.ClientID
+	client.CoretaxPasswordHashed = hashedPassword
+	client.CreatedAt = row.CreatedAt
+	client.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// GetAllClients returns the visible clients ordered by name
+func (r *clientRepository) GetAllClients(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Client, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectClients(func(c models.Client) bool {
+		return visible(c.PicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// GetClientByID returns sql.ErrNoRows if the client does not exist or is not visible
+func (r *clientRepository) GetClientByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Client, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.clients[id]
+	if !ok || !visible(row.PicStaffSigmaID, staffIDFilter, isAdmin) {
+		return nil, sql.ErrNoRows
+	}
+	client := r.db.joinClient(row)
+	return &client, nil
+}
+
+// UpdateClient saves every column of an existing client; a missing client is not an error
+func (r *clientRepository) UpdateClient(ctx context.Context, client *models.Client) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	client.UpdatedAt = time.Now()
+	existing, ok := r.db.clients[client.ClientID]
+	if !ok {
+		return nil
+	}
+	row := *client
+	row.CreatedAt = existing.CreatedAt
+	if err := r.db.checkClientRow(row); err != nil {
+		return fmt.Errorf("failed to update client: %w", err)
+	}
+	r.db.putClient(row)
+	return nil
+}
+
+// DeleteClient deletes the client together with its jobs and invoices
+func (r *clientRepository) DeleteClient(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.deleteClient(id)
+	return nil
+}
+
+// GetClientsByNpwp returns the clients whose canonical NPWP is in canonicals
+func (r *clientRepository) GetClientsByNpwp(ctx context.Context, canonicals []string) ([]models.Client, error) {
+	want := make(map[string]bool, len(canonicals))
+	for _, c := range canonicals {
+		want[c] = true
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectClients(func(c models.Client) bool {
+		return want[c.NpwpCanonical]
+	}), nil
+}
+
+// SearchClients matches a complete NPWP by canonical form, otherwise the name
+// (case-insensitive) or a part of the NPWP digits
+func (r *clientRepository) SearchClients(ctx context.Context, query string, staffIDFilter string, isAdmin bool) ([]models.Client, error) {
+	var match func(c models.Client) bool
+	if n, err := npwp.Parse(query); err == nil {
+		canonical := n.Canonical()
+		match = func(c models.Client) bool { return c.NpwpCanonical == canonical }
+	} else {
+		name := strings.ToLower(query)
+		digits := npwp.Digits(query)
+		match = func(c models.Client) bool {
+			return strings.Contains(strings.ToLower(c.ClientName), name) ||
+				(digits != "" && strings.Contains(c.NpwpCanonical, digits))
+		}
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectClients(func(c models.Client) bool {
+		return match(c) && visible(c.PicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// EachClient calls fn for every element of SearchClients, or of GetAllClients when query is empty
+func (r *clientRepository) EachClient(ctx context.Context, query string, staffIDFilter string, isAdmin bool, fn func(*models.Client) error) error {
+	if query == "" {
+		list, err := r.GetAllClients(ctx, staffIDFilter, isAdmin)
+		return each(list, err, fn)
+	}
+	list, err := r.SearchClients(ctx, query, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// ImportClients applies all creates and updates, or none of them if one fails, and writes a
+// ClientCreated event for every created client
+func (r *clientRepository) ImportClients(ctx context.Context, creates []*models.Client, updates []*models.Client) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// Terapkan ke salinan tabel dulu, baru ditukar jika semuanya lolos (pengganti transaksi)
+	staged := &DB{clients: make(map[string]models.Client, len(r.db.clients)+len(creates)), staffs: r.db.staffs}
+	for id, c := range r.db.clients {
+		staged.clients[id] = c
+	}
+
+	now := time.Now()
+	ids := make([]string, len(creates))
+	events := make([]models.DomainEvent, 0, len(creates))
+	for i, client := range creates {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		row := *client
+		row.ClientID = id
+		row.CreatedAt = now
+		row.UpdatedAt = now
+		if err := staged.checkClientRow(row); err != nil {
+			return fmt.Errorf("failed to import client %s: %w", client.NpwpClient, err)
+		}
+		created, err := models.NewClientCreatedEvent(row)
+		if err != nil {
+			return err
+		}
+		staged.putClient(row)
+		ids[i] = id
+		events = append(events, created)
+	}
+	for _, client := range updates {
+		existing, ok := staged.clients[client.ClientID]
+		if !ok {
+			continue
+		}
+		row := *client
+		row.CreatedAt = existing.CreatedAt
+		row.UpdatedAt = now
+		if err := staged.checkClientRow(row); err != nil {
+			return fmt.Errorf("failed to update imported client %s: %w", client.NpwpClient, err)
+		}
+		staged.putClient(row)
+	}
+
+	r.db.clients = staged.clients
+	r.db.writeOutbox(events)
+	for i, client := range creates {
+		client.ClientID = ids[i]
+		client.CreatedAt = now
+		client.UpdatedAt = now
+	}
+	for _, client := range updates {
+		client.UpdatedAt = now
+	}
+	return nil
+}
+
+// checkClientRow checks the unique NPWP columns and the PIC foreign key of row
+func (db *DB) checkClientRow(row models.Client) error {
+	for id, c := range db.clients {
+		if id == row.ClientID {
+			continue
+		}
+		if c.NpwpClient == row.NpwpClient {
+			return duplicate("clients_npwp_client_key")
+		}
+		if c.NpwpCanonical == row.NpwpCanonical {
+			return duplicate("clients_npwp_canonical_key")
+		}
+	}
+	return db.checkStaff(row.PicStaffSigmaID)
+}
+
+// putClient stores row without the joined columns
+func (db *DB) putClient(row models.Client) {
+	row.PicStaffSigmaName = ""
+	db.clients[row.ClientID] = row
+}
+
+// joinClient fills the columns the Postgres queries join from staffs
+func (db *DB) joinClient(row models.Client) models.Client {
+	row.PicStaffSigmaName = db.staffName(row.PicStaffSigmaID)
+	return row
+}
+
+// selectClients returns the clients matching keep, ordered by name like the Postgres queries
+func (db *DB) selectClients(keep func(models.Client) bool) []models.Client {
+	var clients []models.Client
+	for _, row := range db.clients {
+		if keep(row) {
+			clients = append(clients, db.joinClient(row))
+		}
+	}
+	sort.SliceStable(clients, func(i, j int) bool {
+		if clients[i].ClientName != clients[j].ClientName {
+			return clients[i].ClientName < clients[j].ClientName
+		}
+		return clients[i].ClientID < clients[j].ClientID
+	})
+	return clients
+}
+
+// deleteClient mengikuti ON DELETE CASCADE dari tabel pekerjaan dan invoice ke clients
+func (db *DB) deleteClient(id string) {
+	delete(db.clients, id)
+	for jobID, job := range db.monthlyJobs {
+		if job.ClientID == id {
+			db.deleteMonthlyJob(jobID)
+		}
+	}
+	for jobID, job := range db.annualJobs {
+		if job.ClientID == id {
+			db.deleteAnnualJob(jobID)
+		}
+	}
+	for jobID, job := range db.sp2dkJobs {
+		if job.ClientID == id {
+			delete(db.sp2dkJobs, jobID)
+		}
+	}
+	for jobID, job := range db.pemeriksaanJobs {
+		if job.ClientID == id {
+			delete(db.pemeriksaanJobs, jobID)
+		}
+	}
+	for invoiceID, invoice := range db.invoices {
+		if invoice.ClientID == id {
+			db.deleteInvoice(invoiceID)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package memory menyediakan implementasi in-memory dari repository inti (klien, staf, semua
+// jenis pekerjaan dan invoice), untuk test dan pengembangan tanpa Postgres.
+//
+// Semua repository dari satu DB berbagi data, seperti tabel-tabel dalam satu database:
+// nama klien dan staf ikut terisi pada hasil query, menghapus klien ikut menghapus pekerjaan
+// dan invoice-nya, dan menghapus staf mengosongkan PIC yang menunjuk ke staf tersebut.
+// Visibilitas staffIDFilter/isAdmin, urutan hasil, error saat data tidak ditemukan dan
+// constraint unik mengikuti repository Postgres. Pelanggaran constraint unik dikembalikan
+// sebagai repositories.ErrDuplicate.
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// DB holds the tables shared by the in-memory repositories. The zero value is not usable;
+// create one with NewDB.
+type DB struct {
+	mu sync.Mutex
+
+	clients               map[string]models.Client
+	staffs                map[string]models.Staff
+	monthlyJobs           map[string]models.MonthlyJob
+	monthlyTaxReports     map[string]models.MonthlyTaxReport
+	annualJobs            map[string]models.AnnualJob
+	annualTaxReports      map[string]models.AnnualTaxReport
+	annualDividendReports map[string]models.AnnualDividendReport
+	sp2dkJobs             map[string]models.Sp2dkJob
+	pemeriksaanJobs       map[string]models.PemeriksaanJob
+	invoices              map[string]models.Invoice
+	invoiceLineItems      map[string]models.InvoiceLineItem
+
+	outbox []models.DomainEvent
+}
+
+// NewDB creates an empty in-memory database.
+func NewDB() *DB {
+	return &DB{
+		clients:               make(map[string]models.Client),
+		staffs:                make(map[string]models.Staff),
+		monthlyJobs:           make(map[string]models.MonthlyJob),
+		monthlyTaxReports:     make(map[string]models.MonthlyTaxReport),
+		annualJobs:            make(map[string]models.AnnualJob),
+		annualTaxReports:      make(map[string]models.AnnualTaxReport),
+		annualDividendReports: make(map[string]models.AnnualDividendReport),
+		sp2dkJobs:             make(map[string]models.Sp2dkJob),
+		pemeriksaanJobs:       make(map[string]models.PemeriksaanJob),
+		invoices:              make(map[string]models.Invoice),
+		invoiceLineItems:      make(map[string]models.InvoiceLineItem),
+	}
+}
+
+// OutboxEvents returns the domain events written by the repositories, in the order they
+// were written. Tidak ada dispatcher; event hanya dicatat untuk diperiksa oleh test.
+func (db *DB) OutboxEvents() []models.DomainEvent {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	events := make([]models.DomainEvent, len(db.outbox))
+	copy(events, db.outbox)
+	return events
+}
+
+// writeOutbox mencatat events; dipanggil dengan db.mu terkunci, setelah semua pemeriksaan
+// lolos, sama seperti outbox Postgres yang ikut di-commit bersama perubahan datanya.
+func (db *DB) writeOutbox(events []models.DomainEvent) {
+	db.outbox = append(db.outbox, events...)
+}
+
+// newID generates a primary key in the same form as gen_random_uuid().
+func newID() (string, error) {
+	id, err := utils.NewUUID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return id, nil
+}
+
+// visible menerapkan aturan visibilitas yang sama dengan repository Postgres: staf non-admin
+// hanya melihat baris dengan PIC dirinya.
+func visible(picStaffID, staffIDFilter string, isAdmin bool) bool {
+	return isAdmin || staffIDFilter == "" || picStaffID == staffIDFilter
+}
+
+// each calls fn for every element of list, like the Each* methods of the Postgres
+// repositories. list is a copy taken under the lock, so fn may call the repository again.
+func each[T any](list []T, err error, fn func(*T) error) error {
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if err := fn(&list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// duplicate returns an error for a unique constraint violation on constraint.
+func duplicate(constraint string) error {
+	return fmt.Errorf("%w %q", repositories.ErrDuplicate, constraint)
+}
+
+// checkClient dan checkStaff menggantikan foreign key; staffID kosong berarti NULL.
+func (db *DB) checkClient(clientID string) error {
+	if _, ok := db.clients[clientID]; !ok {
+		return fmt.Errorf("client %s does not exist", clientID)
+	}
+	return nil
+}
+
+func (db *DB) checkStaff(staffID string) error {
+	if staffID == "" {
+		return nil
+	}
+	if _, ok := db.staffs[staffID]; !ok {
+		return fmt.Errorf("staff %s does not exist", staffID)
+	}
+	return nil
+}
+
+// staffName returns the name joined from staffs, or "" for a NULL or dangling staff ID.
+func (db *DB) staffName(staffID string) string {
+	return db.staffs[staffID].Nama
+}
+
+// clonePtr copies the value behind p so rows in the store never share memory with callers.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// sameDate compares two DATE values.
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// toDate truncates t to a DATE value, as Postgres does when storing a DATE column.
+func toDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,313 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+)
+
+// invoiceRepository implements repositories.InvoiceRepository in memory
+type invoiceRepository struct {
+	db *DB
+}
+
+// NewInvoiceRepository creates an in-memory InvoiceRepository backed by db
+func NewInvoiceRepository(db *DB) repositories.InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// CreateInvoice numbers the invoice (INV/YYYYMMDD/SEQ), stores it with its line items and
+// writes an InvoiceIssued event, and InvoicePaid for a paid invoice, to the outbox
+func (r *invoiceRepository) CreateInvoice(ctx context.Context, invoice *models.Invoice) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if invoice.SourceEventID != nil {
+		for _, existing := range r.db.invoices {
+			if existing.SourceEventID != nil && *existing.SourceEventID == *invoice.SourceEventID {
+				return repositories.ErrInvoiceAlreadyIssued
+			}
+		}
+	}
+	if err := r.db.checkInvoiceKeys(invoice.ClientID, invoice.AssignedStaffID); err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+	invoiceID, err := newID()
+	if err != nil {
+		return err
+	}
+	lineItemIDs := make([]string, len(invoice.LineItems))
+	for i := range lineItemIDs {
+		if lineItemIDs[i], err = newID(); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	invoice.InvoiceID = invoiceID
+	invoice.InvoiceNumber = r.db.nextInvoiceNumber(invoice.InvoiceDate.Time)
+	invoice.TotalAmount = 0
+	for _, item := range invoice.LineItems {
+		invoice.TotalAmount += item.Amount
+	}
+	if invoice.CreatedAt.IsZero() {
+		invoice.CreatedAt = now
+	}
+	invoice.UpdatedAt = now
+	for i := range invoice.LineItems {
+		item := &invoice.LineItems[i]
+		item.LineItemID = lineItemIDs[i]
+		item.InvoiceID = invoiceID
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = now
+		}
+		item.UpdatedAt = now
+	}
+
+	events, err := models.NewInvoiceCreatedEvents(*invoice)
+	if err != nil {
+		return err
+	}
+
+	r.db.putInvoice(*invoice)
+	for _, item := range invoice.LineItems {
+		r.db.putInvoiceLineItem(item)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// GetAllInvoices returns the invoices visible to the assigned staff member, newest first
+func (r *invoiceRepository) GetAllInvoices(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Invoice, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var invoices []models.Invoice
+	for _, row := range r.db.invoices {
+		if invoiceVisible(row, staffIDFilter, isAdmin) {
+			invoices = append(invoices, r.db.joinInvoice(row))
+		}
+	}
+	sort.SliceStable(invoices, func(i, j int) bool {
+		a, b := invoices[i], invoices[j]
+		if !a.InvoiceDate.Time.Equal(b.InvoiceDate.Time) {
+			return a.InvoiceDate.Time.After(b.InvoiceDate.Time)
+		}
+		return a.InvoiceNumber > b.InvoiceNumber
+	})
+	return invoices, nil
+}
+
+// EachInvoice calls fn for every element of GetAllInvoices
+func (r *invoiceRepository) EachInvoice(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Invoice) error) error {
+	list, err := r.GetAllInvoices(ctx, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// GetInvoiceByID returns sql.ErrNoRows if the invoice does not exist or is not visible
+func (r *invoiceRepository) GetInvoiceByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Invoice, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.invoices[id]
+	if !ok || !invoiceVisible(row, staffIDFilter, isAdmin) {
+		return nil, sql.ErrNoRows
+	}
+	invoice := r.db.joinInvoice(row)
+	return &invoice, nil
+}
+
+// UpdateInvoice saves the header of an existing invoice and writes events to the outbox; the
+// total is not recalculated
+func (r *invoiceRepository) UpdateInvoice(ctx context.Context, invoice *models.Invoice, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	invoice.UpdatedAt = time.Now()
+	if row, ok := r.db.invoices[invoice.InvoiceID]; ok {
+		if err := r.db.checkInvoiceKeys(invoice.ClientID, invoice.AssignedStaffID); err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		row.ClientID = invoice.ClientID
+		row.AssignedStaffID = invoice.AssignedStaffID
+		row.InvoiceDate = invoice.InvoiceDate
+		row.DueDate = invoice.DueDate
+		row.TotalAmount = invoice.TotalAmount
+		row.Status = invoice.Status
+		row.Notes = invoice.Notes
+		row.UpdatedAt = invoice.UpdatedAt
+		r.db.putInvoice(row)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// DeleteInvoice deletes an invoice with its line items
+func (r *invoiceRepository) DeleteInvoice(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.deleteInvoice(id)
+	return nil
+}
+
+// CreateInvoiceLineItem adds a line item to an existing invoice; the caller updates the total
+func (r *invoiceRepository) CreateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error {
+	item.Amount = item.Quantity * item.UnitPrice
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.invoices[item.InvoiceID]; !ok {
+		return fmt.Errorf("failed to create invoice line item: invoice %s does not exist", item.InvoiceID)
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	item.LineItemID = id
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	item.UpdatedAt = time.Now()
+	r.db.putInvoiceLineItem(*item)
+	return nil
+}
+
+// UpdateInvoiceLineItem saves an existing line item; the caller updates the total
+func (r *invoiceRepository) UpdateInvoiceLineItem(ctx context.Context, item *models.InvoiceLineItem) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	item.UpdatedAt = time.Now()
+	row, ok := r.db.invoiceLineItems[item.LineItemID]
+	if !ok {
+		return nil
+	}
+	row.Description = item.Description
+	row.Quantity = item.Quantity
+	row.UnitPrice = item.UnitPrice
+	row.Amount = item.Amount
+	row.RelatedJobType = item.RelatedJobType
+	row.RelatedJobID = item.RelatedJobID
+	row.UpdatedAt = item.UpdatedAt
+	r.db.putInvoiceLineItem(row)
+	return nil
+}
+
+// DeleteInvoiceLineItem deletes a line item; the caller updates the total
+func (r *invoiceRepository) DeleteInvoiceLineItem(ctx context.Context, itemID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.invoiceLineItems, itemID)
+	return nil
+}
+
+// invoiceVisible menerapkan visibilitas invoice: staf non-admin hanya melihat invoice yang
+// ditugaskan kepadanya
+func invoiceVisible(row models.Invoice, staffIDFilter string, isAdmin bool) bool {
+	assigned := ""
+	if row.AssignedStaffID != nil {
+		assigned = *row.AssignedStaffID
+	}
+	return visible(assigned, staffIDFilter, isAdmin)
+}
+
+// checkInvoiceKeys checks the client and assigned staff foreign keys of an invoice
+func (db *DB) checkInvoiceKeys(clientID string, assignedStaffID *string) error {
+	if err := db.checkClient(clientID); err != nil {
+		return err
+	}
+	if assignedStaffID != nil {
+		return db.checkStaff(*assignedStaffID)
+	}
+	return nil
+}
+
+// nextInvoiceNumber melanjutkan nomor urut invoice lain pada tanggal yang sama
+func (db *DB) nextInvoiceNumber(invoiceDate time.Time) string {
+	prefix := "INV/" + invoiceDate.Format("20060102") + "/"
+	lastSeq := 0
+	for _, invoice := range db.invoices {
+		if !sameDate(invoice.InvoiceDate.Time, invoiceDate) || !strings.HasPrefix(invoice.InvoiceNumber, prefix) {
+			continue
+		}
+		if seq, err := strconv.Atoi(strings.TrimPrefix(invoice.InvoiceNumber, prefix)); err == nil && seq > lastSeq {
+			lastSeq = seq
+		}
+	}
+	return fmt.Sprintf("%s%03d", prefix, lastSeq+1)
+}
+
+// putInvoice stores row like the Postgres columns: DATE values, empty strings as NULL and
+// without the joined columns and line items
+func (db *DB) putInvoice(row models.Invoice) {
+	row.ClientName = ""
+	row.NpwpClient = ""
+	row.AssignedStaffName = nil
+	row.AssignedStaffID = nullablePtr(row.AssignedStaffID)
+	row.Notes = nullablePtr(row.Notes)
+	row.SourceEventID = clonePtr(row.SourceEventID)
+	row.InvoiceDate = models.CustomDate{Time: toDate(row.InvoiceDate.Time)}
+	row.DueDate = models.CustomDate{Time: toDate(row.DueDate.Time)}
+	row.LineItems = nil
+	db.invoices[row.InvoiceID] = row
+}
+
+// putInvoiceLineItem stores item with empty related job fields as NULL
+func (db *DB) putInvoiceLineItem(item models.InvoiceLineItem) {
+	item.RelatedJobType = nullablePtr(item.RelatedJobType)
+	item.RelatedJobID = nullablePtr(item.RelatedJobID)
+	db.invoiceLineItems[item.LineItemID] = item
+}
+
+// joinInvoice fills the client and staff columns and the line items ordered by ID
+func (db *DB) joinInvoice(row models.Invoice) models.Invoice {
+	client := db.clients[row.ClientID]
+	row.ClientName = client.ClientName
+	row.NpwpClient = client.NpwpClient
+	row.AssignedStaffID = clonePtr(row.AssignedStaffID)
+	if row.AssignedStaffID != nil {
+		if staff, ok := db.staffs[*row.AssignedStaffID]; ok {
+			row.AssignedStaffName = &staff.Nama
+		}
+	}
+	row.Notes = clonePtr(row.Notes)
+	row.SourceEventID = nil // Tidak ikut di-SELECT oleh repository Postgres
+	row.LineItems = []models.InvoiceLineItem{}
+	for _, item := range db.invoiceLineItems {
+		if item.InvoiceID == row.InvoiceID {
+			item.RelatedJobType = clonePtr(item.RelatedJobType)
+			item.RelatedJobID = clonePtr(item.RelatedJobID)
+			row.LineItems = append(row.LineItems, item)
+		}
+	}
+	sort.Slice(row.LineItems, func(i, j int) bool {
+		return row.LineItems[i].LineItemID < row.LineItems[j].LineItemID
+	})
+	return row
+}
+
+// deleteInvoice deletes an invoice with its line items (ON DELETE CASCADE)
+func (db *DB) deleteInvoice(id string) {
+	delete(db.invoices, id)
+	for itemID, item := range db.invoiceLineItems {
+		if item.InvoiceID == id {
+			delete(db.invoiceLineItems, itemID)
+		}
+	}
+}
+
+// nullablePtr copies p, storing nil for an empty string like nullableString in the Postgres
+// repositories
+func nullablePtr(p *string) *string {
+	if p == nil || *p == "" {
+		return nil
+	}
+	return clonePtr(p)
+}
@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/memory"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/repotest"
+)
+
+func TestContract(t *testing.T) {
+	db := memory.NewDB()
+	repotest.Run(t, repotest.Repositories{
+		Clients:         memory.NewClientRepository(db),
+		Staffs:          memory.NewStaffRepository(db),
+		MonthlyJobs:     memory.NewMonthlyJobRepository(db),
+		AnnualJobs:      memory.NewAnnualJobRepository(db),
+		Sp2dkJobs:       memory.NewSp2dkJobRepository(db),
+		PemeriksaanJobs: memory.NewPemeriksaanJobRepository(db),
+		Invoices:        memory.NewInvoiceRepository(db),
+	})
+}
@@ -0,0 +1,317 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
+)
+
+// monthlyJobRepository implements repositories.MonthlyJobRepository in memory
+type monthlyJobRepository struct {
+	db *DB
+}
+
+// NewMonthlyJobRepository creates an in-memory MonthlyJobRepository backed by db
+func NewMonthlyJobRepository(db *DB) repositories.MonthlyJobRepository {
+	return &monthlyJobRepository{db: db}
+}
+
+// CreateMonthlyJob stores the job with its tax reports and writes events to the outbox
+func (r *monthlyJobRepository) CreateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row := *job
+	row.ProofOfWorkURL = nil // Bukti pekerjaan baru diisi lewat UpdateMonthlyJob
+	if row.JobID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		row.JobID = id
+	} else if _, ok := r.db.monthlyJobs[row.JobID]; ok {
+		return fmt.Errorf("failed to create monthly job: %w", duplicate("monthly_jobs_pkey"))
+	}
+	if err := r.db.checkMonthlyJobRow(row); err != nil {
+		return fmt.Errorf("failed to create monthly job: %w", err)
+	}
+
+	// Semua laporan diperiksa sebelum ada yang disimpan, seperti rollback transaksi
+	seen := make(map[string]bool, len(job.TaxReports))
+	for _, report := range job.TaxReports {
+		if seen[report.TaxType] {
+			return fmt.Errorf("failed to create tax report for job %s: %w", row.JobID, duplicate("unique_tax_report_per_job"))
+		}
+		seen[report.TaxType] = true
+	}
+	reportIDs := make([]string, len(job.TaxReports))
+	for i := range reportIDs {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		reportIDs[i] = id
+	}
+
+	now := time.Now()
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	r.db.putMonthlyJob(row)
+
+	job.JobID = row.JobID
+	job.CreatedAt = row.CreatedAt
+	job.UpdatedAt = row.UpdatedAt
+	for i := range job.TaxReports {
+		report := &job.TaxReports[i]
+		report.ReportID = reportIDs[i]
+		report.JobID = job.JobID
+		if report.CreatedAt.IsZero() {
+			report.CreatedAt = now
+		}
+		report.UpdatedAt = now
+		report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
+		r.db.monthlyTaxReports[report.ReportID] = cloneMonthlyTaxReport(*report)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// GetMonthlyTaxReportByID returns sql.ErrNoRows if the report does not exist
+func (r *monthlyJobRepository) GetMonthlyTaxReportByID(ctx context.Context, reportID string) (*models.MonthlyTaxReport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.monthlyTaxReports[reportID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	report := cloneMonthlyTaxReport(row)
+	return &report, nil
+}
+
+// GetAllMonthlyJobs returns the visible jobs, newest period first
+func (r *monthlyJobRepository) GetAllMonthlyJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectMonthlyJobs(func(job models.MonthlyJob) bool {
+		return visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// EachMonthlyJob calls fn for every element of GetAllMonthlyJobs
+func (r *monthlyJobRepository) EachMonthlyJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.MonthlyJob) error) error {
+	list, err := r.GetAllMonthlyJobs(ctx, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// GetMonthlyJobByID returns sql.ErrNoRows if the job does not exist or is not visible
+func (r *monthlyJobRepository) GetMonthlyJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.MonthlyJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.monthlyJobs[id]
+	if !ok || !visible(row.AssignedPicStaffSigmaID, staffIDFilter, isAdmin) {
+		return nil, sql.ErrNoRows
+	}
+	job := r.db.joinMonthlyJob(row)
+	return &job, nil
+}
+
+// GetMonthlyJobsByClientID returns the visible jobs of a client, newest period first
+func (r *monthlyJobRepository) GetMonthlyJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectMonthlyJobs(func(job models.MonthlyJob) bool {
+		return job.ClientID == clientID && visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// UpdateMonthlyJob saves the main job fields and writes events to the outbox
+func (r *monthlyJobRepository) UpdateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	row, ok := r.db.monthlyJobs[job.JobID]
+	if ok {
+		row.ClientID = job.ClientID
+		row.JobMonth = job.JobMonth
+		row.JobYear = job.JobYear
+		row.AssignedPicStaffSigmaID = job.AssignedPicStaffSigmaID
+		row.OverallStatus = job.OverallStatus
+		row.ProofOfWorkURL = nil
+		if job.ProofOfWorkURL != nil && *job.ProofOfWorkURL != "" {
+			row.ProofOfWorkURL = clonePtr(job.ProofOfWorkURL)
+		}
+		row.UpdatedAt = job.UpdatedAt
+		if err := r.db.checkMonthlyJobRow(row); err != nil {
+			return fmt.Errorf("failed to update monthly job: %w", err)
+		}
+		r.db.putMonthlyJob(row)
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// CreateMonthlyTaxReport adds a tax report to an existing job
+func (r *monthlyJobRepository) CreateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.monthlyJobs[report.JobID]; !ok {
+		return fmt.Errorf("failed to create monthly tax report: monthly job %s does not exist", report.JobID)
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	row := *report
+	row.ReportID = id
+	if err := r.db.checkMonthlyTaxReportRow(row); err != nil {
+		return fmt.Errorf("failed to create monthly tax report: %w", err)
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	row.UpdatedAt = time.Now()
+	row.AmountMismatch = taxpayment.AmountsMismatch(row.BillingAmount, row.PaymentAmount)
+	r.db.monthlyTaxReports[id] = cloneMonthlyTaxReport(row)
+
+	report.ReportID = id
+	report.CreatedAt = row.CreatedAt
+	report.UpdatedAt = row.UpdatedAt
+	report.AmountMismatch = row.AmountMismatch
+	return nil
+}
+
+// UpdateMonthlyTaxReport saves an existing tax report; its job cannot be changed
+func (r *monthlyJobRepository) UpdateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	report.UpdatedAt = time.Now()
+	existing, ok := r.db.monthlyTaxReports[report.ReportID]
+	if ok {
+		row := *report
+		row.JobID = existing.JobID
+		row.CreatedAt = existing.CreatedAt
+		if err := r.db.checkMonthlyTaxReportRow(row); err != nil {
+			return fmt.Errorf("failed to update monthly tax report: %w", err)
+		}
+		row.AmountMismatch = taxpayment.AmountsMismatch(row.BillingAmount, row.PaymentAmount)
+		r.db.monthlyTaxReports[row.ReportID] = cloneMonthlyTaxReport(row)
+	}
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
+	return nil
+}
+
+// DeleteMonthlyTaxReport deletes a tax report; a missing report is not an error
+func (r *monthlyJobRepository) DeleteMonthlyTaxReport(ctx context.Context, reportID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.monthlyTaxReports, reportID)
+	return nil
+}
+
+// checkMonthlyJobRow checks unique_monthly_job_per_client and the foreign keys of row
+func (db *DB) checkMonthlyJobRow(row models.MonthlyJob) error {
+	if err := db.checkClient(row.ClientID); err != nil {
+		return err
+	}
+	if err := db.checkStaff(row.AssignedPicStaffSigmaID); err != nil {
+		return err
+	}
+	for id, job := range db.monthlyJobs {
+		if id != row.JobID && job.ClientID == row.ClientID && job.JobMonth == row.JobMonth && job.JobYear == row.JobYear {
+			return duplicate("unique_monthly_job_per_client")
+		}
+	}
+	return nil
+}
+
+// checkMonthlyTaxReportRow checks unique_tax_report_per_job
+func (db *DB) checkMonthlyTaxReportRow(row models.MonthlyTaxReport) error {
+	for id, report := range db.monthlyTaxReports {
+		if id != row.ReportID && report.JobID == row.JobID && report.TaxType == row.TaxType {
+			return duplicate("unique_tax_report_per_job")
+		}
+	}
+	return nil
+}
+
+// putMonthlyJob stores row without the joined columns and nested reports
+func (db *DB) putMonthlyJob(row models.MonthlyJob) {
+	row.ClientName = ""
+	row.NpwpClient = ""
+	row.AssignedPicStaffSigmaName = ""
+	row.ProofOfWorkURL = clonePtr(row.ProofOfWorkURL)
+	row.TaxReports = nil
+	db.monthlyJobs[row.JobID] = row
+}
+
+// joinMonthlyJob fills the client and staff columns and the tax reports ordered by tax type
+func (db *DB) joinMonthlyJob(row models.MonthlyJob) models.MonthlyJob {
+	client := db.clients[row.ClientID]
+	row.ClientName = client.ClientName
+	row.NpwpClient = client.NpwpClient
+	row.AssignedPicStaffSigmaName = db.staffName(row.AssignedPicStaffSigmaID)
+	row.ProofOfWorkURL = clonePtr(row.ProofOfWorkURL)
+	row.TaxReports = []models.MonthlyTaxReport{}
+	for _, report := range db.monthlyTaxReports {
+		if report.JobID == row.JobID {
+			row.TaxReports = append(row.TaxReports, cloneMonthlyTaxReport(report))
+		}
+	}
+	sort.Slice(row.TaxReports, func(i, j int) bool {
+		return row.TaxReports[i].TaxType < row.TaxReports[j].TaxType
+	})
+	return row
+}
+
+// selectMonthlyJobs returns the jobs matching keep ordered like the Postgres queries:
+// year and month descending, then newest first
+func (db *DB) selectMonthlyJobs(keep func(models.MonthlyJob) bool) []models.MonthlyJob {
+	var jobs []models.MonthlyJob
+	for _, row := range db.monthlyJobs {
+		if keep(row) {
+			jobs = append(jobs, db.joinMonthlyJob(row))
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i], jobs[j]
+		if a.JobYear != b.JobYear {
+			return a.JobYear > b.JobYear
+		}
+		if a.JobMonth != b.JobMonth {
+			return a.JobMonth > b.JobMonth
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+	return jobs
+}
+
+// deleteMonthlyJob deletes a job with its tax reports (ON DELETE CASCADE)
+func (db *DB) deleteMonthlyJob(id string) {
+	delete(db.monthlyJobs, id)
+	for reportID, report := range db.monthlyTaxReports {
+		if report.JobID == id {
+			delete(db.monthlyTaxReports, reportID)
+		}
+	}
+}
+
+// cloneMonthlyTaxReport copies report including the values behind its pointer fields
+func cloneMonthlyTaxReport(report models.MonthlyTaxReport) models.MonthlyTaxReport {
+	report.PaymentDate = clonePtr(report.PaymentDate)
+	report.PaymentAmount = clonePtr(report.PaymentAmount)
+	report.ReportDate = clonePtr(report.ReportDate)
+	report.BillingCodeExpiresAt = clonePtr(report.BillingCodeExpiresAt)
+	report.BillingAmount = clonePtr(report.BillingAmount)
+	return report
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+)
+
+// pemeriksaanJobRepository implements repositories.PemeriksaanJobRepository in memory
+type pemeriksaanJobRepository struct {
+	db *DB
+}
+
+// NewPemeriksaanJobRepository creates an in-memory PemeriksaanJobRepository backed by db
+func NewPemeriksaanJobRepository(db *DB) repositories.PemeriksaanJobRepository {
+	return &pemeriksaanJobRepository{db: db}
+}
+
+// CreatePemeriksaanJob stores the job and writes events to the outbox
+func (r *pemeriksaanJobRepository) CreatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row := clonePemeriksaanJob(*job)
+	row.ProofOfWorkURL = nil // Bukti pekerjaan baru diisi lewat UpdatePemeriksaanJob
+	if row.JobID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		row.JobID = id
+	} else if _, ok := r.db.pemeriksaanJobs[row.JobID]; ok {
+		return fmt.Errorf("failed to create Pemeriksaan job: %w", duplicate("pemeriksaan_jobs_pkey"))
+	}
+	if err := r.db.checkJobKeys(row.ClientID, row.AssignedPicStaffSigmaID); err != nil {
+		return fmt.Errorf("failed to create Pemeriksaan job: %w", err)
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	row.UpdatedAt = time.Now()
+	r.db.pemeriksaanJobs[row.JobID] = row
+
+	job.JobID = row.JobID
+	job.CreatedAt = row.CreatedAt
+	job.UpdatedAt = row.UpdatedAt
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// GetAllPemeriksaanJobs returns the visible jobs, newest first
+func (r *pemeriksaanJobRepository) GetAllPemeriksaanJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectPemeriksaanJobs(func(job models.PemeriksaanJob) bool {
+		return visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// EachPemeriksaanJob calls fn for every element of GetAllPemeriksaanJobs
+func (r *pemeriksaanJobRepository) EachPemeriksaanJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.PemeriksaanJob) error) error {
+	list, err := r.GetAllPemeriksaanJobs(ctx, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// GetPemeriksaanJobByID returns nil, nil if the job does not exist or is not visible
+func (r *pemeriksaanJobRepository) GetPemeriksaanJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.PemeriksaanJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.pemeriksaanJobs[id]
+	if !ok || !visible(row.AssignedPicStaffSigmaID, staffIDFilter, isAdmin) {
+		return nil, nil
+	}
+	job := r.db.joinPemeriksaanJob(row)
+	return &job, nil
+}
+
+// UpdatePemeriksaanJob saves every column of an existing job and writes events to the outbox
+func (r *pemeriksaanJobRepository) UpdatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	if existing, ok := r.db.pemeriksaanJobs[job.JobID]; ok {
+		row := clonePemeriksaanJob(*job)
+		row.CreatedAt = existing.CreatedAt
+		if err := r.db.checkJobKeys(row.ClientID, row.AssignedPicStaffSigmaID); err != nil {
+			return fmt.Errorf("failed to update Pemeriksaan job: %w", err)
+		}
+		r.db.pemeriksaanJobs[row.JobID] = row
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// DeletePemeriksaanJob deletes a job; a missing job is not an error
+func (r *pemeriksaanJobRepository) DeletePemeriksaanJob(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.pemeriksaanJobs, id)
+	return nil
+}
+
+// GetPemeriksaanJobsByClientID returns the visible jobs of a client, newest first
+func (r *pemeriksaanJobRepository) GetPemeriksaanJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectPemeriksaanJobs(func(job models.PemeriksaanJob) bool {
+		return job.ClientID == clientID && visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// joinPemeriksaanJob fills the columns the Postgres queries join from clients and staffs
+func (db *DB) joinPemeriksaanJob(row models.PemeriksaanJob) models.PemeriksaanJob {
+	client := db.clients[row.ClientID]
+	row = clonePemeriksaanJob(row)
+	row.ClientName = client.ClientName
+	row.NpwpClient = client.NpwpClient
+	row.AssignedPicStaffSigmaName = db.staffName(row.AssignedPicStaffSigmaID)
+	return row
+}
+
+// selectPemeriksaanJobs returns the jobs matching keep, newest first
+func (db *DB) selectPemeriksaanJobs(keep func(models.PemeriksaanJob) bool) []models.PemeriksaanJob {
+	var jobs []models.PemeriksaanJob
+	for _, row := range db.pemeriksaanJobs {
+		if keep(row) {
+			jobs = append(jobs, db.joinPemeriksaanJob(row))
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// clonePemeriksaanJob copies job including the values behind its pointer fields
+func clonePemeriksaanJob(job models.PemeriksaanJob) models.PemeriksaanJob {
+	job.ClientName = ""
+	job.NpwpClient = ""
+	job.AssignedPicStaffSigmaName = ""
+	job.ContractDate = clonePtr(job.ContractDate)
+	job.Sp2Date = clonePtr(job.Sp2Date)
+	job.SkpDate = clonePtr(job.SkpDate)
+	job.ProofOfWorkURL = clonePtr(job.ProofOfWorkURL)
+	return job
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+)
+
+// sp2dkJobRepository implements repositories.Sp2dkJobRepository in memory
+type sp2dkJobRepository struct {
+	db *DB
+}
+
+// NewSp2dkJobRepository creates an in-memory Sp2dkJobRepository backed by db
+func NewSp2dkJobRepository(db *DB) repositories.Sp2dkJobRepository {
+	return &sp2dkJobRepository{db: db}
+}
+
+// CreateSp2dkJob stores the job and writes events to the outbox
+func (r *sp2dkJobRepository) CreateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row := cloneSp2dkJob(*job)
+	row.ProofOfWorkURL = nil // Bukti pekerjaan baru diisi lewat UpdateSp2dkJob
+	if row.JobID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		row.JobID = id
+	} else if _, ok := r.db.sp2dkJobs[row.JobID]; ok {
+		return fmt.Errorf("failed to create SP2DK job: %w", duplicate("sp2dk_jobs_pkey"))
+	}
+	if err := r.db.checkJobKeys(row.ClientID, row.AssignedPicStaffSigmaID); err != nil {
+		return fmt.Errorf("failed to create SP2DK job: %w", err)
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	row.UpdatedAt = time.Now()
+	r.db.sp2dkJobs[row.JobID] = row
+
+	job.JobID = row.JobID
+	job.CreatedAt = row.CreatedAt
+	job.UpdatedAt = row.UpdatedAt
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// GetAllSp2dkJobs returns the visible jobs, newest first
+func (r *sp2dkJobRepository) GetAllSp2dkJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectSp2dkJobs(func(job models.Sp2dkJob) bool {
+		return visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// EachSp2dkJob calls fn for every element of GetAllSp2dkJobs
+func (r *sp2dkJobRepository) EachSp2dkJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Sp2dkJob) error) error {
+	list, err := r.GetAllSp2dkJobs(ctx, staffIDFilter, isAdmin)
+	return each(list, err, fn)
+}
+
+// GetSp2dkJobByID returns sql.ErrNoRows if the job does not exist or is not visible
+func (r *sp2dkJobRepository) GetSp2dkJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Sp2dkJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.sp2dkJobs[id]
+	if !ok || !visible(row.AssignedPicStaffSigmaID, staffIDFilter, isAdmin) {
+		return nil, sql.ErrNoRows
+	}
+	job := r.db.joinSp2dkJob(row)
+	return &job, nil
+}
+
+// UpdateSp2dkJob saves every column of an existing job and writes events to the outbox
+func (r *sp2dkJobRepository) UpdateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	if existing, ok := r.db.sp2dkJobs[job.JobID]; ok {
+		row := cloneSp2dkJob(*job)
+		row.CreatedAt = existing.CreatedAt
+		if err := r.db.checkJobKeys(row.ClientID, row.AssignedPicStaffSigmaID); err != nil {
+			return fmt.Errorf("failed to update SP2DK job: %w", err)
+		}
+		r.db.sp2dkJobs[row.JobID] = row
+	}
+	r.db.writeOutbox(events)
+	return nil
+}
+
+// DeleteSp2dkJob deletes a job; a missing job is not an error
+func (r *sp2dkJobRepository) DeleteSp2dkJob(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	delete(r.db.sp2dkJobs, id)
+	return nil
+}
+
+// GetSp2dkJobsByClientID returns the visible jobs of a client, newest first
+func (r *sp2dkJobRepository) GetSp2dkJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.selectSp2dkJobs(func(job models.Sp2dkJob) bool {
+		return job.ClientID == clientID && visible(job.AssignedPicStaffSigmaID, staffIDFilter, isAdmin)
+	}), nil
+}
+
+// checkJobKeys checks the client and PIC foreign keys of an SP2DK or Pemeriksaan job
+func (db *DB) checkJobKeys(clientID, picStaffID string) error {
+	if err := db.checkClient(clientID); err != nil {
+		return err
+	}
+	return db.checkStaff(picStaffID)
+}
+
+// joinSp2dkJob fills the columns the Postgres queries join from clients and staffs
+func (db *DB) joinSp2dkJob(row models.Sp2dkJob) models.Sp2dkJob {
+	client := db.clients[row.ClientID]
+	row = cloneSp2dkJob(row)
+	row.ClientName = client.ClientName
+	row.NpwpClient = client.NpwpClient
+	row.AssignedPicStaffSigmaName = db.staffName(row.AssignedPicStaffSigmaID)
+	return row
+}
+
+// selectSp2dkJobs returns the jobs matching keep, newest first
+func (db *DB) selectSp2dkJobs(keep func(models.Sp2dkJob) bool) []models.Sp2dkJob {
+	var jobs []models.Sp2dkJob
+	for _, row := range db.sp2dkJobs {
+		if keep(row) {
+			jobs = append(jobs, db.joinSp2dkJob(row))
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// cloneSp2dkJob copies job including the values behind its pointer fields
+func cloneSp2dkJob(job models.Sp2dkJob) models.Sp2dkJob {
+	job.ClientName = ""
+	job.NpwpClient = ""
+	job.AssignedPicStaffSigmaName = ""
+	job.ContractDate = clonePtr(job.ContractDate)
+	job.Sp2dkDate = clonePtr(job.Sp2dkDate)
+	job.Bap2dkDate = clonePtr(job.Bap2dkDate)
+	job.PaymentDate = clonePtr(job.PaymentDate)
+	job.ReportDate = clonePtr(job.ReportDate)
+	job.ProofOfWorkURL = clonePtr(job.ProofOfWorkURL)
+	return job
+}
@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// staffRepository implements repositories.StaffRepository in memory
+type staffRepository struct {
+	db *DB
+}
+
+// NewStaffRepository creates an in-memory StaffRepository backed by db
+func NewStaffRepository(db *DB) repositories.StaffRepository {
+	return &staffRepository{db: db}
+}
+
+// CreateStaff generates the NIP, hashes the password and stores the staff member
+func (r *staffRepository) CreateStaff(ctx context.Context, staff *models.Staff) error {
+	hashedPassword, err := utils.HashPassword(staff.PasswordHashed)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	row := *staff
+	row.StaffID = id
+	row.NIP = utils.GenerateNIP(staff.Nama)
+	row.PasswordHashed = hashedPassword
+	row.FailedLoginAttempts = 0
+	row.LockedUntil = nil
+	row.TOTPEnabled = false
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	row.UpdatedAt = time.Now()
+	row.PasswordChangedAt = clonePtr(&row.CreatedAt)
+	if err := r.db.checkStaffRow(row); err != nil {
+		return fmt.Errorf("failed to create staff: %w", err)
+	}
+	r.db.staffs[id] = row
+
+	staff.StaffID = id
+	staff.NIP = row.NIP
+	staff.PasswordHashed = hashedPassword
+	staff.CreatedAt = row.CreatedAt
+	staff.UpdatedAt = row.UpdatedAt
+	staff.PasswordChangedAt = &staff.CreatedAt
+	return nil
+}
+
+// GetAllStaffs returns all staff members ordered by name
+func (r *staffRepository) GetAllStaffs(ctx context.Context) ([]models.Staff, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var staffs []models.Staff
+	for _, row := range r.db.staffs {
+		staffs = append(staffs, cloneStaff(row))
+	}
+	sort.SliceStable(staffs, func(i, j int) bool {
+		if staffs[i].Nama != staffs[j].Nama {
+			return staffs[i].Nama < staffs[j].Nama
+		}
+		return staffs[i].StaffID < staffs[j].StaffID
+	})
+	return staffs, nil
+}
+
+// GetStaffByID returns nil, nil if the staff member does not exist
+func (r *staffRepository) GetStaffByID(ctx context.Context, id string) (*models.Staff, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.staffs[id]
+	if !ok {
+		return nil, nil
+	}
+	staff := cloneStaff(row)
+	return &staff, nil
+}
+
+// GetStaffByEmail returns nil, nil if no staff member has the email
+func (r *staffRepository) GetStaffByEmail(ctx context.Context, email string) (*models.Staff, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, row := range r.db.staffs {
+		if row.Email == email {
+			staff := cloneStaff(row)
+			return &staff, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateStaff saves the profile columns, hashing PasswordHashed if it is a plain password
+func (r *staffRepository) UpdateStaff(ctx context.Context, staff *models.Staff) error {
+	if len(staff.PasswordHashed) > 0 && !utils.IsBcryptHash(staff.PasswordHashed) {
+		hashedPassword, err := utils.HashPassword(staff.PasswordHashed)
+		if err != nil {
+			return fmt.Errorf("failed to hash new password: %w", err)
+		}
+		staff.PasswordHashed = hashedPassword
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	staff.UpdatedAt = time.Now()
+	row, ok := r.db.staffs[staff.StaffID]
+	if !ok {
+		return nil
+	}
+	row.NIP = staff.NIP
+	row.Nama = staff.Nama
+	row.Email = staff.Email
+	row.PasswordHashed = staff.PasswordHashed
+	row.Role = staff.Role
+	row.UpdatedAt = staff.UpdatedAt
+	if err := r.db.checkStaffRow(row); err != nil {
+		return fmt.Errorf("failed to update staff: %w", err)
+	}
+	r.db.staffs[row.StaffID] = row
+	return nil
+}
+
+// DeleteStaff deletes the staff member and clears the PIC of their clients, jobs and invoices
+func (r *staffRepository) DeleteStaff(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.deleteStaff(id)
+	return nil
+}
+
+// RecordFailedLogin counts a failed login and locks the account once threshold is reached
+func (r *staffRepository) RecordFailedLogin(ctx context.Context, id string, threshold int, lockout time.Duration) (*time.Time, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.staffs[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to record failed login: %w", sql.ErrNoRows)
+	}
+	if row.FailedLoginAttempts+1 >= threshold {
+		row.FailedLoginAttempts = 0
+		lockedUntil := time.Now().Add(lockout)
+		row.LockedUntil = &lockedUntil
+	} else {
+		row.FailedLoginAttempts++
+	}
+	r.db.staffs[id] = row
+	return clonePtr(row.LockedUntil), nil
+}
+
+// ResetFailedLogins clears the failed login count and unlocks the account
+func (r *staffRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if row, ok := r.db.staffs[id]; ok {
+		row.FailedLoginAttempts = 0
+		row.LockedUntil = nil
+		r.db.staffs[id] = row
+	}
+	return nil
+}
+
+// SetPassword saves an already hashed password and unlocks the account
+func (r *staffRepository) SetPassword(ctx context.Context, id, passwordHashed string, mustChange bool) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.staffs[id]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	row.PasswordHashed = passwordHashed
+	row.MustChangePassword = mustChange
+	row.PasswordChangedAt = &now
+	row.FailedLoginAttempts = 0
+	row.LockedUntil = nil
+	row.UpdatedAt = now
+	r.db.staffs[id] = row
+	return nil
+}
+
+// checkStaffRow checks the unique NIP and email columns of row
+func (db *DB) checkStaffRow(row models.Staff) error {
+	for id, s := range db.staffs {
+		if id == row.StaffID {
+			continue
+		}
+		if s.NIP == row.NIP {
+			return duplicate("staffs_nip_key")
+		}
+		if s.Email == row.Email {
+			return duplicate("staffs_email_key")
+		}
+	}
+	return nil
+}
+
+// deleteStaff mengikuti ON DELETE SET NULL dari kolom PIC ke staffs
+func (db *DB) deleteStaff(id string) {
+	delete(db.staffs, id)
+	for clientID, c := range db.clients {
+		if c.PicStaffSigmaID == id {
+			c.PicStaffSigmaID = ""
+			db.clients[clientID] = c
+		}
+	}
+	for jobID, job := range db.monthlyJobs {
+		if job.AssignedPicStaffSigmaID == id {
+			job.AssignedPicStaffSigmaID = ""
+			db.monthlyJobs[jobID] = job
+		}
+	}
+	for jobID, job := range db.annualJobs {
+		if job.AssignedPicStaffSigmaID == id {
+			job.AssignedPicStaffSigmaID = ""
+			db.annualJobs[jobID] = job
+		}
+	}
+	for jobID, job := range db.sp2dkJobs {
+		if job.AssignedPicStaffSigmaID == id {
+			job.AssignedPicStaffSigmaID = ""
+			db.sp2dkJobs[jobID] = job
+		}
+	}
+	for jobID, job := range db.pemeriksaanJobs {
+		if job.AssignedPicStaffSigmaID == id {
+			job.AssignedPicStaffSigmaID = ""
+			db.pemeriksaanJobs[jobID] = job
+		}
+	}
+	for invoiceID, invoice := range db.invoices {
+		if invoice.AssignedStaffID != nil && *invoice.AssignedStaffID == id {
+			invoice.AssignedStaffID = nil
+			db.invoices[invoiceID] = invoice
+		}
+	}
+}
+
+// cloneStaff copies row including the values behind its pointer fields
+func cloneStaff(row models.Staff) models.Staff {
+	row.PasswordChangedAt = clonePtr(row.PasswordChangedAt)
+	row.LockedUntil = clonePtr(row.LockedUntil)
+	return row
+}
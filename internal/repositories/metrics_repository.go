@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// MetricsRepository defines the aggregate queries behind the business gauges on /metrics
+type MetricsRepository interface {
+	// GetJobStatusCounts counts jobs that are not completed or cancelled, per job type and status.
+	GetJobStatusCounts(ctx context.Context) ([]models.JobStatusCount, error)
+	// CountOverdueTaxReports counts monthly and annual tax reports of open jobs that are not
+	// reported yet while their due date is before asOf.
+	CountOverdueTaxReports(ctx context.Context, asOf time.Time) (int, error)
+	// GetUnpaidInvoiceSummary sums invoices that are issued but not paid or cancelled.
+	GetUnpaidInvoiceSummary(ctx context.Context) (*models.UnpaidInvoiceSummary, error)
+}
+
+// metricsRepository implements MetricsRepository interface
+type metricsRepository struct {
+	db *sql.DB
+}
+
+// NewMetricsRepository creates a new MetricsRepository
+func NewMetricsRepository(db *sql.DB) MetricsRepository {
+	return &metricsRepository{db: db}
+}
+
+// GetJobStatusCounts groups the open jobs of every job type by overall status
+func (r *metricsRepository) GetJobStatusCounts(ctx context.Context) ([]models.JobStatusCount, error) {
+	query := allJobsCTE() + `SELECT job_type, COALESCE(overall_status, ''), COUNT(*)
+	FROM jobs
+	WHERE COALESCE(overall_status, '') NOT IN ($1, $2)
+	GROUP BY job_type, COALESCE(overall_status, '')
+	ORDER BY job_type, 2`
+
+	rows, err := r.db.QueryContext(ctx, query, models.JobStatusCompleted, models.JobStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs by status: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []models.JobStatusCount
+	for rows.Next() {
+		var c models.JobStatusCount
+		if err := rows.Scan(&c.JobType, &c.Status, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan job status count row: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for job status counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CountOverdueTaxReports uses the same due dates as the compliance report: the 20th of the
+// following month for monthly reports and 30 April of the following year for the annual SPT
+func (r *metricsRepository) CountOverdueTaxReports(ctx context.Context, asOf time.Time) (int, error) {
+	query := `SELECT
+		(SELECT COUNT(*)
+		FROM monthly_tax_reports AS mtr
+		JOIN monthly_jobs AS mj ON mtr.job_id = mj.job_id
+		WHERE mtr.report_date IS NULL
+			AND COALESCE(mj.overall_status, '') NOT IN ($1, $2)
+			AND ` + monthlyDueDateSQL("mj.job_year", "mj.job_month") + ` < $3::date)
+		+
+		(SELECT COUNT(*)
+		FROM annual_tax_reports AS atr
+		JOIN annual_jobs AS aj ON atr.job_id = aj.job_id
+		WHERE atr.report_date IS NULL
+			AND COALESCE(aj.overall_status, '') NOT IN ($1, $2)
+			AND ` + annualDueDateSQL("aj.job_year") + ` < $3::date)`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, models.JobStatusCompleted, models.JobStatusCancelled, asOf).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count overdue tax reports: %w", err)
+	}
+	return count, nil
+}
+
+// GetUnpaidInvoiceSummary counts Pending and Issued invoices and sums their totals
+func (r *metricsRepository) GetUnpaidInvoiceSummary(ctx context.Context) (*models.UnpaidInvoiceSummary, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+	FROM invoices
+	WHERE status NOT IN ($1, $2, $3)`
+
+	var s models.UnpaidInvoiceSummary
+	err := r.db.QueryRowContext(ctx, query, models.InvoiceStatusDraft, models.InvoiceStatusPaid, models.InvoiceStatusCancelled).
+		Scan(&s.Count, &s.TotalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum unpaid invoices: %w", err)
+	}
+	return &s, nil
+}
@@ -1,24 +1,33 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models" // Pastikan ini adalah modul Anda
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
 )
 
 // MonthlyJobRepository defines the interface for monthly job data operations
 type MonthlyJobRepository interface {
-	GetMonthlyTaxReportByID(reportID string) (*models.MonthlyTaxReport, error)
-	CreateMonthlyJob(job *models.MonthlyJob) error
-	GetAllMonthlyJobs(staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error)
-	GetMonthlyJobByID(id string, staffIDFilter string, isAdmin bool) (*models.MonthlyJob, error)
-	UpdateMonthlyJob(job *models.MonthlyJob) error // For updating main job fields
-	CreateMonthlyTaxReport(report *models.MonthlyTaxReport) error
-	UpdateMonthlyTaxReport(report *models.MonthlyTaxReport) error
-	DeleteMonthlyTaxReport(reportID string) error
-	GetMonthlyJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error)
+	GetMonthlyTaxReportByID(ctx context.Context, reportID string) (*models.MonthlyTaxReport, error)
+	// CreateMonthlyJob and UpdateMonthlyJob write events to the outbox in the same transaction.
+	// A preset job.JobID is used as the new job's ID.
+	CreateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error
+	GetAllMonthlyJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error)
+	// EachMonthlyJob calls fn with every job of GetAllMonthlyJobs, in the same order, while the
+	// rows are still being read, so exports don't hold the whole list in memory. An error from
+	// fn stops the iteration and is returned as is. fn runs inside the call, so the statement
+	// timeout of an instrumented repository also bounds the time fn takes, e.g. a slow download.
+	EachMonthlyJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.MonthlyJob) error) error
+	GetMonthlyJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.MonthlyJob, error)
+	UpdateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error // For updating main job fields
+	CreateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error
+	UpdateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error
+	DeleteMonthlyTaxReport(ctx context.Context, reportID string) error
+	GetMonthlyJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error)
 }
 
 // monthlyJobRepository implements MonthlyJobRepository interface
@@ -33,8 +42,8 @@ func NewMonthlyJobRepository(db *sql.DB) MonthlyJobRepository {
 
 // CreateMonthlyJob inserts a new monthly job and its associated tax reports into the database.
 // This operation is wrapped in a transaction to ensure atomicity.
-func (r *monthlyJobRepository) CreateMonthlyJob(job *models.MonthlyJob) error {
-	tx, err := r.db.Begin()
+func (r *monthlyJobRepository) CreateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -50,9 +59,9 @@ func (r *monthlyJobRepository) CreateMonthlyJob(job *models.MonthlyJob) error {
 	// 1. Insert into monthly_jobs table
 	// --- PERUBAHAN DI SINI: Gunakan assigned_pic_staff_sigma_id ---
 	jobQuery := `INSERT INTO monthly_jobs (
-		client_id, job_month, job_year, assigned_pic_staff_sigma_id, overall_status, created_at, updated_at
+		client_id, job_month, job_year, assigned_pic_staff_sigma_id, overall_status, created_at, updated_at, job_id
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7
+		$1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, '')::uuid, gen_random_uuid())
 	) RETURNING job_id, created_at, updated_at`
 
 	if job.CreatedAt.IsZero() {
@@ -60,9 +69,9 @@ func (r *monthlyJobRepository) CreateMonthlyJob(job *models.MonthlyJob) error {
 	}
 	job.UpdatedAt = time.Now()
 
-	err = tx.QueryRow(jobQuery,
+	err = tx.QueryRowContext(ctx, jobQuery,
 		job.ClientID, job.JobMonth, job.JobYear, assignedPicStaffSigmaID, job.OverallStatus, // Gunakan AssignedPicStaffSigmaID
-		job.CreatedAt, job.UpdatedAt,
+		job.CreatedAt, job.UpdatedAt, job.JobID,
 	).Scan(&job.JobID, &job.CreatedAt, &job.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create monthly job: %w", err)
@@ -71,9 +80,10 @@ func (r *monthlyJobRepository) CreateMonthlyJob(job *models.MonthlyJob) error {
 	// 2. Insert associated tax reports into monthly_tax_reports table
 	if len(job.TaxReports) > 0 {
 		reportQuery := `INSERT INTO monthly_tax_reports (
-			job_id, tax_type, billing_code, payment_date, payment_amount, report_status, report_date, created_at, updated_at
+			job_id, tax_type, billing_code, payment_date, payment_amount, report_status, report_date, created_at, updated_at,
+			billing_code_expires_at, billing_amount, ntpn, payment_channel
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		) RETURNING report_id, created_at, updated_at`
 
 		for i := range job.TaxReports {
@@ -85,34 +95,43 @@ func (r *monthlyJobRepository) CreateMonthlyJob(job *models.MonthlyJob) error {
 			}
 			report.UpdatedAt = time.Now()
 
-			err := tx.QueryRow(reportQuery,
+			err := tx.QueryRowContext(ctx, reportQuery,
 				report.JobID, report.TaxType, report.BillingCode, report.PaymentDate,
 				report.PaymentAmount, report.ReportStatus, report.ReportDate,
 				report.CreatedAt, report.UpdatedAt,
+				report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
 			).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
 			if err != nil {
 				return fmt.Errorf("failed to create tax report for job %s: %w", job.JobID, err)
 			}
+			report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 		}
 	}
 
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
 	return tx.Commit() // Commit the transaction if all inserts are successful
 }
 
 // GetMonthlyTaxReportByID fetches a single monthly tax report by its ID.
-func (r *monthlyJobRepository) GetMonthlyTaxReportByID(reportID string) (*models.MonthlyTaxReport, error) {
+func (r *monthlyJobRepository) GetMonthlyTaxReportByID(ctx context.Context, reportID string) (*models.MonthlyTaxReport, error) {
 	query := `SELECT
 		report_id, job_id, tax_type, billing_code, payment_date, payment_amount,
-		report_status, report_date, created_at, updated_at
+		report_status, report_date, created_at, updated_at,
+		billing_code_expires_at, billing_amount, ntpn, payment_channel
 	FROM monthly_tax_reports WHERE report_id = $1`
 
 	var report models.MonthlyTaxReport
 	var paymentDate, reportDate sql.NullTime
 	var paymentAmount sql.NullFloat64
+	var billingCode sql.NullString
+	var payment taxPaymentScan
 
-	err := r.db.QueryRow(query, reportID).Scan(
-		&report.ReportID, &report.JobID, &report.TaxType, &report.BillingCode, &paymentDate, &paymentAmount,
-		&report.ReportStatus, &report.ReportDate, &report.CreatedAt, &report.UpdatedAt,
+	err := r.db.QueryRowContext(ctx, query, reportID).Scan(
+		&report.ReportID, &report.JobID, &report.TaxType, &billingCode, &paymentDate, &paymentAmount,
+		&report.ReportStatus, &reportDate, &report.CreatedAt, &report.UpdatedAt,
+		&payment.billingCodeExpiresAt, &payment.billingAmount, &payment.ntpn, &payment.paymentChannel,
 	)
 
 	if err != nil {
@@ -138,12 +157,28 @@ func (r *monthlyJobRepository) GetMonthlyTaxReportByID(reportID string) (*models
 	} else {
 		report.ReportDate = nil
 	}
+	report.BillingCode = billingCode.String
+	payment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
 
 	return &report, nil
 }
 
-// GetAllMonthlyJobs fetches all monthly jobs with their associated client and tax reports.
-func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) {
+// GetAllMonthlyJobs fetches all monthly jobs with their associated client and reports.
+func (r *monthlyJobRepository) GetAllMonthlyJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) {
+	var monthlyJobsList []models.MonthlyJob
+	err := r.EachMonthlyJob(ctx, staffIDFilter, isAdmin, func(job *models.MonthlyJob) error {
+		monthlyJobsList = append(monthlyJobsList, *job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return monthlyJobsList, nil
+}
+
+// EachMonthlyJob fetches all monthly jobs with their associated client and reports, calling fn
+// for each job as soon as its rows are read.
+func (r *monthlyJobRepository) EachMonthlyJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.MonthlyJob) error) error {
 	query := `
 	SELECT
 		mj.job_id, mj.client_id, c.client_name, c.npwp_client, mj.job_month, mj.job_year,
@@ -152,7 +187,8 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 		mj.created_at, mj.updated_at,
 		
 		mtr.report_id, mtr.tax_type, mtr.billing_code, mtr.payment_date, mtr.payment_amount, -- <-- URUTAN KOLOM MTR DI SINI
-		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at
+		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at,
+		mtr.billing_code_expires_at, mtr.billing_amount, mtr.ntpn, mtr.payment_channel
 	FROM monthly_jobs AS mj
 	JOIN clients AS c ON mj.client_id = c.client_id
 	LEFT JOIN staffs AS s ON mj.assigned_pic_staff_sigma_id = s.staff_id
@@ -167,16 +203,15 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 		paramCounter++
 	}
 
-	query += " ORDER BY mj.job_year DESC, mj.job_month DESC, mj.created_at DESC, mtr.tax_type ASC;"
+	query += " ORDER BY mj.job_year DESC, mj.job_month DESC, mj.created_at DESC, mj.job_id, mtr.tax_type ASC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all monthly jobs: %w", err)
+		return fmt.Errorf("failed to get all monthly jobs: %w", err)
 	}
 	defer rows.Close()
 
-	var monthlyJobsMap map[string]*models.MonthlyJob
-	var monthlyJobsList []models.MonthlyJob
+	var job *models.MonthlyJob // Job yang sedang dibaca; barisnya berurutan karena ORDER BY job_id
 
 	for rows.Next() {
 		var (
@@ -203,6 +238,7 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 			reportDate      sql.NullTime
 			reportCreatedAt sql.NullTime
 			reportUpdatedAt sql.NullTime
+			reportPayment   taxPaymentScan
 		)
 
 		err := rows.Scan(
@@ -214,14 +250,19 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 			// --- PERBAIKAN PENTING DI SINI: PASTIKAN URUTANNYA SAMA PERSIS DENGAN SELECT MTR ---
 			&reportID, &taxType, &billingCode, &paymentDate, &paymentAmount, // <-- URUTANNYA
 			&reportStatus, &reportDate, &reportCreatedAt, &reportUpdatedAt,
+			&reportPayment.billingCodeExpiresAt, &reportPayment.billingAmount, &reportPayment.ntpn, &reportPayment.paymentChannel,
 			// --- AKHIR PERBAIKAN ---
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan monthly job row: %w", err)
+			return fmt.Errorf("failed to scan monthly job row: %w", err)
 		}
 
-		job, ok := monthlyJobsMap[jobID]
-		if !ok {
+		if job == nil || job.JobID != jobID {
+			if job != nil {
+				if err := fn(job); err != nil {
+					return err
+				}
+			}
 			job = &models.MonthlyJob{
 				JobID:                 jobID,
 				ClientID:              clientID,
@@ -250,7 +291,6 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 				job.ProofOfWorkURL = nil
 			}
 
-			monthlyJobsList = append(monthlyJobsList, *job)
 		}
 
 		// Penambahan tax reports jika ada
@@ -280,19 +320,23 @@ func (r *monthlyJobRepository) GetAllMonthlyJobs(staffIDFilter string, isAdmin b
 				report.ReportDate = nil
 			}
 
+			reportPayment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
 			job.TaxReports = append(job.TaxReports, report)
 		}
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration: %w", err)
+		return fmt.Errorf("error during rows iteration: %w", err)
 	}
 
-	return monthlyJobsList, nil
+	if job != nil {
+		return fn(job)
+	}
+	return nil
 }
 
 // GetMonthlyJobByID fetches a single monthly job by its ID with associated client and tax reports.
-func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string, isAdmin bool) (*models.MonthlyJob, error) {
+func (r *monthlyJobRepository) GetMonthlyJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.MonthlyJob, error) {
 	query := `
 	SELECT
 		mj.job_id, mj.client_id, c.client_name, c.npwp_client, mj.job_month, mj.job_year,
@@ -301,7 +345,8 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 		mj.created_at, mj.updated_at,
 		
 		mtr.report_id, mtr.tax_type, mtr.billing_code, mtr.payment_date, mtr.payment_amount,
-		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at
+		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at,
+		mtr.billing_code_expires_at, mtr.billing_amount, mtr.ntpn, mtr.payment_channel
 	FROM monthly_jobs AS mj
 	JOIN clients AS c ON mj.client_id = c.client_id
 	LEFT JOIN staffs AS s ON mj.assigned_pic_staff_sigma_id = s.staff_id
@@ -318,7 +363,7 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 
 	query += " ORDER BY mtr.tax_type ASC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly job by ID: %w", err)
 	}
@@ -350,6 +395,7 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 			reportDate      sql.NullTime
 			reportCreatedAt sql.NullTime
 			reportUpdatedAt sql.NullTime
+			reportPayment   taxPaymentScan
 		)
 
 		err := rows.Scan(
@@ -361,6 +407,7 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 			// --- PERBAIKAN PENTING DI SINI: PASTIKAN URUTANNYA SAMA PERSIS DENGAN SELECT MTR ---
 			&reportID, &taxType, &billingCode, &paymentDate, &paymentAmount, // <-- URUTANNYA
 			&reportStatus, &reportDate, &reportCreatedAt, &reportUpdatedAt,
+			&reportPayment.billingCodeExpiresAt, &reportPayment.billingAmount, &reportPayment.ntpn, &reportPayment.paymentChannel,
 			// --- AKHIR PERBAIKAN ---
 		)
 		if err != nil {
@@ -424,6 +471,7 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 				report.ReportDate = nil
 			}
 
+			reportPayment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
 			monthlyJob.TaxReports = append(monthlyJob.TaxReports, report)
 		}
 	}
@@ -439,7 +487,7 @@ func (r *monthlyJobRepository) GetMonthlyJobByID(id string, staffIDFilter string
 	return monthlyJob, nil
 }
 
-func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) { // <-- IMPLEMENTASI BARU
+func (r *monthlyJobRepository) GetMonthlyJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.MonthlyJob, error) { // <-- IMPLEMENTASI BARU
 	query := `
 	SELECT
 		mj.job_id, mj.client_id, c.client_name, c.npwp_client, mj.job_month, mj.job_year,
@@ -447,7 +495,8 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 		mj.overall_status, mj.created_at, mj.updated_at,
 		
 		mtr.report_id, mtr.tax_type, mtr.billing_code, mtr.payment_date, mtr.payment_amount,
-		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at
+		mtr.report_status, mtr.report_date, mtr.created_at AS report_created_at, mtr.updated_at AS report_updated_at,
+		mtr.billing_code_expires_at, mtr.billing_amount, mtr.ntpn, mtr.payment_channel
 	FROM monthly_jobs AS mj
 	JOIN clients AS c ON mj.client_id = c.client_id
 	LEFT JOIN staffs AS s ON mj.assigned_pic_staff_sigma_id = s.staff_id
@@ -465,7 +514,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 
 	query += " ORDER BY mj.job_year DESC, mj.job_month DESC, mj.created_at DESC, mtr.tax_type ASC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly jobs by client ID: %w", err)
 	}
@@ -473,7 +522,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 
 	// Bagian rekonstruksi map dan slice sama persis dengan GetAllMonthlyJobs
 	monthlyJobsMap := make(map[string]*models.MonthlyJob)
-	var monthlyJobsList []models.MonthlyJob
+	var monthlyJobIDs []string // Urutan dari query
 
 	for rows.Next() {
 		var (
@@ -498,6 +547,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 			reportDate      sql.NullTime
 			reportCreatedAt sql.NullTime
 			reportUpdatedAt sql.NullTime
+			reportPayment   taxPaymentScan
 		)
 
 		err := rows.Scan(
@@ -506,6 +556,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 			&overallStatus, &jobCreatedAt, &jobUpdatedAt,
 			&reportID, &taxType, &billingCode, &paymentDate, &paymentAmount,
 			&reportStatus, &reportDate, &reportCreatedAt, &reportUpdatedAt,
+			&reportPayment.billingCodeExpiresAt, &reportPayment.billingAmount, &reportPayment.ntpn, &reportPayment.paymentChannel,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan monthly job row by client ID: %w", err)
@@ -532,7 +583,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 				job.AssignedPicStaffSigmaName = assignedPicStaffSigmaName.String
 			}
 			monthlyJobsMap[jobID] = job
-			monthlyJobsList = append(monthlyJobsList, *job)
+			monthlyJobIDs = append(monthlyJobIDs, jobID)
 		}
 
 		if reportID.Valid {
@@ -552,6 +603,7 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 			if !paymentAmount.Valid { report.PaymentAmount = nil }
 			if !reportDate.Valid { report.ReportDate = nil }
 
+			reportPayment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
 			job.TaxReports = append(job.TaxReports, report)
 		}
 	}
@@ -560,12 +612,23 @@ func (r *monthlyJobRepository) GetMonthlyJobsByClientID(clientID string, staffID
 		return nil, fmt.Errorf("error during rows iteration for monthly jobs by client ID: %w", err)
 	}
 
+	// Salin setelah semua baris dibaca; monthlyJobsMap berisi pointer yang masih ditambah laporannya
+	var monthlyJobsList []models.MonthlyJob
+	for _, id := range monthlyJobIDs {
+		monthlyJobsList = append(monthlyJobsList, *monthlyJobsMap[id])
+	}
 	return monthlyJobsList, nil
 }
 
 // UpdateMonthlyJob updates only the main fields of a monthly job.
 // Tax reports are handled via separate functions if partial updates are needed.
-func (r *monthlyJobRepository) UpdateMonthlyJob(job *models.MonthlyJob) error {
+func (r *monthlyJobRepository) UpdateMonthlyJob(ctx context.Context, job *models.MonthlyJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// --- PERUBAHAN DI SINI: Gunakan assigned_pic_staff_sigma_id ---
 	var assignedPicStaffSigmaID sql.NullString
 	if job.AssignedPicStaffSigmaID != "" {
@@ -595,7 +658,7 @@ func (r *monthlyJobRepository) UpdateMonthlyJob(job *models.MonthlyJob) error {
 
 	job.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err = tx.ExecContext(ctx, query,
 		job.ClientID, job.JobMonth, job.JobYear, assignedPicStaffSigmaID, // Gunakan AssignedPicStaffSigmaID
 		job.OverallStatus, proofOfWorkURLValue, job.UpdatedAt, job.JobID,
 	)
@@ -603,15 +666,19 @@ func (r *monthlyJobRepository) UpdateMonthlyJob(job *models.MonthlyJob) error {
 	if err != nil {
 		return fmt.Errorf("failed to update monthly job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // CreateMonthlyTaxReport inserts a new tax report for an existing monthly job
-func (r *monthlyJobRepository) CreateMonthlyTaxReport(report *models.MonthlyTaxReport) error {
+func (r *monthlyJobRepository) CreateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error {
 	query := `INSERT INTO monthly_tax_reports (
-		job_id, tax_type, billing_code, payment_date, payment_amount, report_status, report_date, created_at, updated_at
+		job_id, tax_type, billing_code, payment_date, payment_amount, report_status, report_date, created_at, updated_at,
+		billing_code_expires_at, billing_amount, ntpn, payment_channel
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 	) RETURNING report_id, created_at, updated_at`
 
 	if report.CreatedAt.IsZero() {
@@ -619,41 +686,47 @@ func (r *monthlyJobRepository) CreateMonthlyTaxReport(report *models.MonthlyTaxR
 	}
 	report.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		report.JobID, report.TaxType, report.BillingCode, report.PaymentDate,
 		report.PaymentAmount, report.ReportStatus, report.ReportDate,
 		report.CreatedAt, report.UpdatedAt,
+		report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
 	).Scan(&report.ReportID, &report.CreatedAt, &report.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create monthly tax report: %w", err)
 	}
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 	return nil
 }
 
 // UpdateMonthlyTaxReport updates an existing monthly tax report
-func (r *monthlyJobRepository) UpdateMonthlyTaxReport(report *models.MonthlyTaxReport) error {
+func (r *monthlyJobRepository) UpdateMonthlyTaxReport(ctx context.Context, report *models.MonthlyTaxReport) error {
 	query := `UPDATE monthly_tax_reports SET
 		tax_type = $1, billing_code = $2, payment_date = $3, payment_amount = $4,
-		report_status = $5, report_date = $6, updated_at = $7
-	WHERE report_id = $8`
+		report_status = $5, report_date = $6, updated_at = $7,
+		billing_code_expires_at = $8, billing_amount = $9, ntpn = $10, payment_channel = $11
+	WHERE report_id = $12`
 
 	report.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		report.TaxType, report.BillingCode, report.PaymentDate, report.PaymentAmount,
-		report.ReportStatus, report.ReportDate, report.UpdatedAt, report.ReportID,
+		report.ReportStatus, report.ReportDate, report.UpdatedAt,
+		report.BillingCodeExpiresAt, report.BillingAmount, nullableString(report.Ntpn), nullableString(report.PaymentChannel),
+		report.ReportID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to update monthly tax report: %w", err)
 	}
+	report.AmountMismatch = taxpayment.AmountsMismatch(report.BillingAmount, report.PaymentAmount)
 	return nil
 }
 
 // DeleteMonthlyTaxReport deletes a monthly tax report by its ID
-func (r *monthlyJobRepository) DeleteMonthlyTaxReport(reportID string) error {
+func (r *monthlyJobRepository) DeleteMonthlyTaxReport(ctx context.Context, reportID string) error {
 	query := `DELETE FROM monthly_tax_reports WHERE report_id = $1`
-	_, err := r.db.Exec(query, reportID)
+	_, err := r.db.ExecContext(ctx, query, reportID)
 	if err != nil {
 		return fmt.Errorf("failed to delete monthly tax report: %w", err)
 	}
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// NotificationRepository defines data operations for notifications: email preferences,
+// the sent log, the queries behind scheduled emails and the in-app inbox
+type NotificationRepository interface {
+	GetPreferences(ctx context.Context, staffID string) (*models.NotificationPreferences, error)
+	UpsertPreferences(ctx context.Context, prefs *models.NotificationPreferences) error
+	// ClaimNotification records key as sent. It returns false if key was already claimed.
+	// staffID may be empty for notifications that are not sent to a staff member.
+	ClaimNotification(ctx context.Context, key, staffID, event string) (bool, error)
+	// ReleaseNotification removes a claim so a failed email is retried on the next run.
+	ReleaseNotification(ctx context.Context, key string) error
+	GetOpenJobDeadlines(ctx context.Context) ([]models.JobDeadline, error)
+	GetOverdueInvoices(ctx context.Context, asOf time.Time) ([]models.OverdueInvoice, error)
+
+	// CreateNotification stores an in-app notification and publishes it on RealtimeChannel.
+	CreateNotification(ctx context.Context, n *models.Notification) error
+	GetNotifications(ctx context.Context, staffID string, unreadOnly bool, limit int, before *time.Time) ([]models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, staffID string) (int, error)
+	MarkNotificationRead(ctx context.Context, notificationID, staffID string) (*models.Notification, error)
+	MarkAllNotificationsRead(ctx context.Context, staffID string) (int64, error)
+	// PublishEvent sends event to every API instance listening on RealtimeChannel.
+	PublishEvent(ctx context.Context, event *models.RealtimeEvent) error
+}
+
+// RealtimeChannel is the PostgreSQL LISTEN/NOTIFY channel used to fan out live events
+// to every API instance.
+const RealtimeChannel = "dashboard_events"
+
+// maxNotifyPayload stays below PostgreSQL's 8000 byte NOTIFY payload limit.
+const maxNotifyPayload = 7900
+
+// notificationRepository implements NotificationRepository interface
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// GetPreferences returns the staff member's preferences, or the defaults if none were saved
+func (r *notificationRepository) GetPreferences(ctx context.Context, staffID string) (*models.NotificationPreferences, error) {
+	query := `SELECT staff_id, job_assigned, invoice_overdue, report_due, daily_digest, updated_at
+	FROM notification_preferences WHERE staff_id = $1`
+
+	prefs := &models.NotificationPreferences{}
+	err := r.db.QueryRowContext(ctx, query, staffID).Scan(
+		&prefs.StaffID, &prefs.JobAssigned, &prefs.InvoiceOverdue, &prefs.ReportDue, &prefs.DailyDigest, &prefs.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return models.DefaultNotificationPreferences(staffID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// UpsertPreferences saves the staff member's preferences
+func (r *notificationRepository) UpsertPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	query := `INSERT INTO notification_preferences (staff_id, job_assigned, invoice_overdue, report_due, daily_digest, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (staff_id) DO UPDATE SET
+		job_assigned = EXCLUDED.job_assigned,
+		invoice_overdue = EXCLUDED.invoice_overdue,
+		report_due = EXCLUDED.report_due,
+		daily_digest = EXCLUDED.daily_digest,
+		updated_at = EXCLUDED.updated_at`
+
+	prefs.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query, prefs.StaffID, prefs.JobAssigned, prefs.InvoiceOverdue, prefs.ReportDue, prefs.DailyDigest, prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// ClaimNotification inserts key into the sent log unless it is already there
+func (r *notificationRepository) ClaimNotification(ctx context.Context, key, staffID, event string) (bool, error) {
+	query := `INSERT INTO notification_log (notification_key, staff_id, event, sent_at)
+	VALUES ($1, $2, $3, $4) ON CONFLICT (notification_key) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, key, sql.NullString{String: staffID, Valid: staffID != ""}, event, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim notification: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// ReleaseNotification deletes key from the sent log
+func (r *notificationRepository) ReleaseNotification(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM notification_log WHERE notification_key = $1`, key); err != nil {
+		return fmt.Errorf("failed to release notification: %w", err)
+	}
+	return nil
+}
+
+// GetOpenJobDeadlines returns every job that is not completed or cancelled and has a PIC
+func (r *notificationRepository) GetOpenJobDeadlines(ctx context.Context) ([]models.JobDeadline, error) {
+	var sources []string
+	for _, jobType := range models.JobTypes {
+		sources = append(sources, jobSources[jobType])
+	}
+
+	query := `WITH jobs AS (
+		` + strings.Join(sources, "\n\t\tUNION ALL\n\t\t") + `
+	)
+	SELECT j.job_type, j.job_id, c.client_name, j.period, COALESCE(j.overall_status, ''), j.due_date,
+		s.staff_id, s.nama, COALESCE(s.email, '')
+	FROM jobs AS j
+	JOIN clients AS c ON j.client_id = c.client_id
+	JOIN staffs AS s ON j.staff_id = s.staff_id
+	WHERE COALESCE(j.overall_status, '') NOT IN ($1, $2)
+	ORDER BY s.staff_id, j.due_date NULLS LAST, c.client_name`
+
+	rows, err := r.db.QueryContext(ctx, query, models.JobStatusCompleted, models.JobStatusCancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open job deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var deadlines []models.JobDeadline
+	for rows.Next() {
+		var d models.JobDeadline
+		var dueDate sql.NullTime
+		if err := rows.Scan(&d.JobType, &d.JobID, &d.ClientName, &d.Period, &d.OverallStatus, &dueDate,
+			&d.StaffID, &d.StaffName, &d.StaffEmail); err != nil {
+			return nil, fmt.Errorf("failed to scan job deadline row: %w", err)
+		}
+		if dueDate.Valid {
+			d.DueDate = &dueDate.Time
+		}
+		deadlines = append(deadlines, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for job deadlines: %w", err)
+	}
+	return deadlines, nil
+}
+
+// GetOverdueInvoices returns issued invoices past their due date on asOf that are not paid or cancelled
+func (r *notificationRepository) GetOverdueInvoices(ctx context.Context, asOf time.Time) ([]models.OverdueInvoice, error) {
+	query := `SELECT i.invoice_id, i.invoice_number, c.client_name, i.due_date, i.total_amount,
+		s.staff_id, s.nama, COALESCE(s.email, '')
+	FROM invoices AS i
+	JOIN clients AS c ON i.client_id = c.client_id
+	JOIN staffs AS s ON i.assigned_staff_id = s.staff_id
+	WHERE i.status NOT IN ($1, $2, $3) AND i.due_date < $4::date
+	ORDER BY s.staff_id, i.due_date`
+
+	rows, err := r.db.QueryContext(ctx, query, models.InvoiceStatusDraft, models.InvoiceStatusPaid, models.InvoiceStatusCancelled, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []models.OverdueInvoice
+	for rows.Next() {
+		var inv models.OverdueInvoice
+		if err := rows.Scan(&inv.InvoiceID, &inv.InvoiceNumber, &inv.ClientName, &inv.DueDate, &inv.TotalAmount,
+			&inv.StaffID, &inv.StaffName, &inv.StaffEmail); err != nil {
+			return nil, fmt.Errorf("failed to scan overdue invoice row: %w", err)
+		}
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for overdue invoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// notificationColumns is the column list scanned by scanNotification
+const notificationColumns = `notification_id, staff_id, event, title, body, job_type, job_id, invoice_id, read_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var n models.Notification
+	var jobType, jobID, invoiceID sql.NullString
+	var readAt sql.NullTime
+	if err := row.Scan(&n.NotificationID, &n.StaffID, &n.Event, &n.Title, &n.Body,
+		&jobType, &jobID, &invoiceID, &readAt, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	if jobType.Valid {
+		n.JobType = &jobType.String
+	}
+	if jobID.Valid {
+		n.JobID = &jobID.String
+	}
+	if invoiceID.Valid {
+		n.InvoiceID = &invoiceID.String
+	}
+	if readAt.Valid {
+		n.ReadAt = &readAt.Time
+	}
+	return &n, nil
+}
+
+// CreateNotification inserts the notification and issues NOTIFY in the same transaction,
+// so listeners only see it once it is committed
+func (r *notificationRepository) CreateNotification(ctx context.Context, n *models.Notification) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO notifications (staff_id, event, title, body, job_type, job_id, invoice_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING notification_id`
+
+	n.CreatedAt = time.Now()
+	n.ReadAt = nil
+	err = tx.QueryRowContext(ctx, query, n.StaffID, n.Event, n.Title, n.Body, n.JobType, n.JobID, n.InvoiceID, n.CreatedAt).
+		Scan(&n.NotificationID)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	if err := notify(ctx, tx, &models.RealtimeEvent{Type: models.RealtimeEventNotification, Notification: n}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetNotifications returns the newest notifications of a staff member, optionally only unread
+// ones and only those created before a cursor
+func (r *notificationRepository) GetNotifications(ctx context.Context, staffID string, unreadOnly bool, limit int, before *time.Time) ([]models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE staff_id = $1`
+	args := []interface{}{staffID}
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	if before != nil {
+		args = append(args, *before)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification row: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// CountUnreadNotifications counts a staff member's unread notifications
+func (r *notificationRepository) CountUnreadNotifications(ctx context.Context, staffID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE staff_id = $1 AND read_at IS NULL`, staffID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one of the staff member's notifications as read.
+// It returns sql.ErrNoRows if the notification does not exist or belongs to someone else.
+func (r *notificationRepository) MarkNotificationRead(ctx context.Context, notificationID, staffID string) (*models.Notification, error) {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, $3)
+	WHERE notification_id = $1 AND staff_id = $2
+	RETURNING ` + notificationColumns
+
+	n, err := scanNotification(r.db.QueryRowContext(ctx, query, notificationID, staffID, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return n, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of the staff member as read
+func (r *notificationRepository) MarkAllNotificationsRead(ctx context.Context, staffID string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = $2 WHERE staff_id = $1 AND read_at IS NULL`, staffID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark all notifications as read: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// PublishEvent issues NOTIFY on RealtimeChannel outside of any transaction
+func (r *notificationRepository) PublishEvent(ctx context.Context, event *models.RealtimeEvent) error {
+	return notify(ctx, r.db, event)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func notify(ctx context.Context, db execer, event *models.RealtimeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("realtime event too large for NOTIFY (%d bytes)", len(payload))
+	}
+	if _, err := db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, RealtimeChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish realtime event: %w", err)
+	}
+	return nil
+}
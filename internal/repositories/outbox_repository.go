@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// OutboxRepository defines the dispatcher's operations on the outbox_events table.
+// Events are written by the repositories that change state, inside their own transaction.
+type OutboxRepository interface {
+	// ClaimPendingEvents leases up to limit undispatched events that are due by pushing their
+	// next_attempt_at forward by lease, so other API instances skip them meanwhile.
+	ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]models.DomainEvent, error)
+	// GetHandledSubscribers returns the subscribers that already processed eventID.
+	GetHandledSubscribers(ctx context.Context, eventID string) (map[string]bool, error)
+	MarkHandled(ctx context.Context, eventID, subscriber string) error
+	MarkDispatched(ctx context.Context, eventID string) error
+	// MarkFailed records the error and schedules the event for another attempt at nextAttemptAt.
+	MarkFailed(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time) error
+}
+
+// OutboxChannel is the PostgreSQL NOTIFY channel signalled when new outbox events are committed.
+const OutboxChannel = "outbox_events"
+
+// outboxRepository implements OutboxRepository interface
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// writeOutbox inserts events as part of the caller's transaction and wakes the dispatcher on commit
+func writeOutbox(ctx context.Context, tx execer, events []models.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	query := `INSERT INTO outbox_events (event_id, event_type, aggregate_type, aggregate_id, actor_staff_id, payload, occurred_at, next_attempt_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`
+
+	for _, e := range events {
+		_, err := tx.ExecContext(ctx, query, e.EventID, e.EventType, e.AggregateType, e.AggregateID, e.ActorStaffID, []byte(e.Payload), e.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to write %s event to outbox: %w", e.EventType, err)
+		}
+	}
+	// NOTIFY di dalam transaksi baru terkirim saat commit
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, '')`, OutboxChannel); err != nil {
+		return fmt.Errorf("failed to signal outbox: %w", err)
+	}
+	return nil
+}
+
+// ClaimPendingEvents returns leased events in the order they were written
+func (r *outboxRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]models.DomainEvent, error) {
+	query := `UPDATE outbox_events o SET next_attempt_at = $2
+	FROM (
+		SELECT event_id FROM outbox_events
+		WHERE dispatched_at IS NULL AND next_attempt_at <= $1
+		ORDER BY sequence
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	) due
+	WHERE o.event_id = due.event_id
+	RETURNING o.sequence, o.event_id, o.event_type, o.aggregate_type, o.aggregate_id, o.actor_staff_id, o.payload, o.occurred_at, o.attempts`
+
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	type sequenced struct {
+		sequence int64
+		event    models.DomainEvent
+	}
+	var claimed []sequenced
+	for rows.Next() {
+		var s sequenced
+		var actor sql.NullString
+		var payload []byte
+		err := rows.Scan(&s.sequence, &s.event.EventID, &s.event.EventType, &s.event.AggregateType, &s.event.AggregateID,
+			&actor, &payload, &s.event.OccurredAt, &s.event.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event row: %w", err)
+		}
+		if actor.Valid {
+			s.event.ActorStaffID = &actor.String
+		}
+		s.event.Payload = payload
+		claimed = append(claimed, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for outbox events: %w", err)
+	}
+
+	// RETURNING tidak menjamin urutan
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].sequence < claimed[j].sequence })
+	events := make([]models.DomainEvent, len(claimed))
+	for i := range claimed {
+		events[i] = claimed[i].event
+	}
+	return events, nil
+}
+
+// GetHandledSubscribers lists the subscribers recorded as done for an event
+func (r *outboxRepository) GetHandledSubscribers(ctx context.Context, eventID string) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT subscriber FROM outbox_handled WHERE event_id = $1`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handled subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	handled := make(map[string]bool)
+	for rows.Next() {
+		var subscriber string
+		if err := rows.Scan(&subscriber); err != nil {
+			return nil, fmt.Errorf("failed to scan handled subscriber row: %w", err)
+		}
+		handled[subscriber] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for handled subscribers: %w", err)
+	}
+	return handled, nil
+}
+
+// MarkHandled records that subscriber processed eventID
+func (r *outboxRepository) MarkHandled(ctx context.Context, eventID, subscriber string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO outbox_handled (event_id, subscriber, handled_at) VALUES ($1, $2, $3)
+	ON CONFLICT (event_id, subscriber) DO NOTHING`, eventID, subscriber, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event handled: %w", err)
+	}
+	return nil
+}
+
+// MarkDispatched marks an event as delivered to every subscriber
+func (r *outboxRepository) MarkDispatched(ctx context.Context, eventID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox_events SET dispatched_at = $2, attempts = attempts + 1, last_error = NULL WHERE event_id = $1`,
+		eventID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event dispatched: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed schedules another attempt for an event
+func (r *outboxRepository) MarkFailed(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE event_id = $1`,
+		eventID, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// PasswordResetRepository defines the data operations for password reset tokens
+type PasswordResetRepository interface {
+	// CreateResetToken saves a new token and removes the other unused tokens of the staff
+	// member, so only the most recent email link works.
+	CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	// GetResetToken returns the token with the given hash, or nil if there is none.
+	GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// ConsumeResetToken marks the token as used and sets the new password in one transaction.
+	// It returns the staff ID, or "" if the token is unknown, expired or already used.
+	ConsumeResetToken(ctx context.Context, tokenHash, passwordHashed string) (string, error)
+}
+
+// passwordResetRepository implements PasswordResetRepository interface
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository creates a new PasswordResetRepository
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// CreateResetToken also cleans up tokens that expired more than a day ago
+func (r *passwordResetRepository) CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_tokens
+		WHERE (staff_id = $1 AND used_at IS NULL) OR expires_at < NOW() - INTERVAL '1 day'`, token.StaffID)
+	if err != nil {
+		return fmt.Errorf("failed to delete old password reset tokens: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO password_reset_tokens (token_hash, staff_id, expires_at)
+		VALUES ($1, $2, $3) RETURNING created_at`,
+		token.TokenHash, token.StaffID, token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit password reset token: %w", err)
+	}
+	return nil
+}
+
+// GetResetToken fetches a token by hash, including used and expired ones
+func (r *passwordResetRepository) GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := `SELECT token_hash, staff_id, expires_at, used_at, created_at
+	FROM password_reset_tokens WHERE token_hash = $1`
+
+	var token models.PasswordResetToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.TokenHash, &token.StaffID, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// ConsumeResetToken claims the token with a conditional UPDATE, so two requests with the
+// same token cannot both change the password.
+func (r *passwordResetRepository) ConsumeResetToken(ctx context.Context, tokenHash, passwordHashed string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var staffID string
+	err = tx.QueryRowContext(ctx, `UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING staff_id`, tokenHash).Scan(&staffID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	// Reset lewat email juga membuka kunci akun dan mengakhiri status password sementara
+	_, err = tx.ExecContext(ctx, `UPDATE staffs SET
+		password_hashed = $2, must_change_password = FALSE, password_changed_at = NOW(),
+		failed_login_attempts = 0, locked_until = NULL, updated_at = NOW()
+	WHERE staff_id = $1`, staffID, passwordHashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to reset password: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE staff_id = $1 AND used_at IS NULL`, staffID)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete other password reset tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit password reset: %w", err)
+	}
+	return staffID, nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,12 +11,16 @@ import (
 
 // PemeriksaanJobRepository defines the interface for Pemeriksaan job data operations
 type PemeriksaanJobRepository interface {
-	CreatePemeriksaanJob(job *models.PemeriksaanJob) error
-	GetAllPemeriksaanJobs(staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error)
-	GetPemeriksaanJobByID(id string, staffIDFilter string, isAdmin bool) (*models.PemeriksaanJob, error)
-	UpdatePemeriksaanJob(job *models.PemeriksaanJob) error
-	DeletePemeriksaanJob(id string) error
-	GetPemeriksaanJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error)
+	// CreatePemeriksaanJob and UpdatePemeriksaanJob write events to the outbox in the same transaction.
+	// A preset job.JobID is used as the new job's ID.
+	CreatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error
+	GetAllPemeriksaanJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error)
+	// EachPemeriksaanJob is the streaming form of GetAllPemeriksaanJobs, see MonthlyJobRepository.EachMonthlyJob.
+	EachPemeriksaanJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.PemeriksaanJob) error) error
+	GetPemeriksaanJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.PemeriksaanJob, error)
+	UpdatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error
+	DeletePemeriksaanJob(ctx context.Context, id string) error
+	GetPemeriksaanJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error)
 }
 
 // pemeriksaanJobRepository implements PemeriksaanJobRepository interface
@@ -29,13 +34,19 @@ func NewPemeriksaanJobRepository(db *sql.DB) PemeriksaanJobRepository {
 }
 
 // CreatePemeriksaanJob inserts a new Pemeriksaan job into the database.
-func (r *pemeriksaanJobRepository) CreatePemeriksaanJob(job *models.PemeriksaanJob) error {
+func (r *pemeriksaanJobRepository) CreatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// PERBAIKAN: Menggunakan `overall_status` di query dan `job.OverallStatus` sebagai parameter
 	query := `INSERT INTO pemeriksaan_jobs (
 		client_id, assigned_pic_staff_sigma_id, contract_no, contract_date, sp2_no, sp2_date,
-		skp_no, skp_date, overall_status, created_at, updated_at
+		skp_no, skp_date, overall_status, created_at, updated_at, job_id
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE(NULLIF($12, '')::uuid, gen_random_uuid())
 	) RETURNING job_id, created_at, updated_at`
 
 	if job.CreatedAt.IsZero() {
@@ -43,20 +54,36 @@ func (r *pemeriksaanJobRepository) CreatePemeriksaanJob(job *models.PemeriksaanJ
 	}
 	job.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err = tx.QueryRowContext(ctx, query,
 		job.ClientID, job.AssignedPicStaffSigmaID, job.ContractNo, job.ContractDate, job.Sp2No, job.Sp2Date,
 		job.SkpNo, job.SkpDate, job.OverallStatus,
-		job.CreatedAt, job.UpdatedAt,
+		job.CreatedAt, job.UpdatedAt, job.JobID,
 	).Scan(&job.JobID, &job.CreatedAt, &job.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create Pemeriksaan job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetAllPemeriksaanJobs fetches all Pemeriksaan jobs.
-func (r *pemeriksaanJobRepository) GetAllPemeriksaanJobs(staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
+func (r *pemeriksaanJobRepository) GetAllPemeriksaanJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
+	var pemeriksaanJobs []models.PemeriksaanJob
+	err := r.EachPemeriksaanJob(ctx, staffIDFilter, isAdmin, func(job *models.PemeriksaanJob) error {
+		pemeriksaanJobs = append(pemeriksaanJobs, *job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pemeriksaanJobs, nil
+}
+
+// EachPemeriksaanJob fetches all Pemeriksaan jobs, calling fn for each job as soon as it is read.
+func (r *pemeriksaanJobRepository) EachPemeriksaanJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.PemeriksaanJob) error) error {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -75,13 +102,12 @@ func (r *pemeriksaanJobRepository) GetAllPemeriksaanJobs(staffIDFilter string, i
 	}
 	query += " ORDER BY pj.created_at DESC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all Pemeriksaan jobs: %w", err)
+		return fmt.Errorf("failed to get all Pemeriksaan jobs: %w", err)
 	}
 	defer rows.Close()
 
-	var pemeriksaanJobs []models.PemeriksaanJob
 	for rows.Next() {
 		var job models.PemeriksaanJob
 		// PERBAIKAN: Menyederhanakan Scan langsung ke field model
@@ -92,19 +118,21 @@ func (r *pemeriksaanJobRepository) GetAllPemeriksaanJobs(staffIDFilter string, i
 			&job.OverallStatus, &job.ProofOfWorkURL, &job.CreatedAt, &job.UpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan Pemeriksaan job row: %w", err)
+			return fmt.Errorf("failed to scan Pemeriksaan job row: %w", err)
+		}
+		if err := fn(&job); err != nil {
+			return err
 		}
-		pemeriksaanJobs = append(pemeriksaanJobs, job)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration for Pemeriksaan jobs: %w", err)
+		return fmt.Errorf("error during rows iteration for Pemeriksaan jobs: %w", err)
 	}
-	return pemeriksaanJobs, nil
+	return nil
 }
 
 // GetPemeriksaanJobByID fetches a single Pemeriksaan job by its ID.
-func (r *pemeriksaanJobRepository) GetPemeriksaanJobByID(id string, staffIDFilter string, isAdmin bool) (*models.PemeriksaanJob, error) {
+func (r *pemeriksaanJobRepository) GetPemeriksaanJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.PemeriksaanJob, error) {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -125,7 +153,7 @@ func (r *pemeriksaanJobRepository) GetPemeriksaanJobByID(id string, staffIDFilte
 
 	var job models.PemeriksaanJob
 	// PERBAIKAN: Menyederhanakan Scan langsung ke field model
-	err := r.db.QueryRow(query, args...).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&job.JobID, &job.ClientID, &job.ClientName, &job.NpwpClient,
 		&job.AssignedPicStaffSigmaID, &job.AssignedPicStaffSigmaName,
 		&job.ContractNo, &job.ContractDate, &job.Sp2No, &job.Sp2Date, &job.SkpNo, &job.SkpDate,
@@ -142,7 +170,13 @@ func (r *pemeriksaanJobRepository) GetPemeriksaanJobByID(id string, staffIDFilte
 }
 
 // UpdatePemeriksaanJob updates an existing Pemeriksaan job.
-func (r *pemeriksaanJobRepository) UpdatePemeriksaanJob(job *models.PemeriksaanJob) error {
+func (r *pemeriksaanJobRepository) UpdatePemeriksaanJob(ctx context.Context, job *models.PemeriksaanJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// PERBAIKAN: Menggunakan `overall_status`
 	query := `UPDATE pemeriksaan_jobs SET
 		client_id = $1, assigned_pic_staff_sigma_id = $2, contract_no = $3, contract_date = $4,
@@ -152,7 +186,7 @@ func (r *pemeriksaanJobRepository) UpdatePemeriksaanJob(job *models.PemeriksaanJ
 
 	job.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err = tx.ExecContext(ctx, query,
 		job.ClientID, job.AssignedPicStaffSigmaID, job.ContractNo, job.ContractDate,
 		job.Sp2No, job.Sp2Date, job.SkpNo, job.SkpDate,
 		job.OverallStatus, job.ProofOfWorkURL, job.UpdatedAt, job.JobID,
@@ -161,13 +195,16 @@ func (r *pemeriksaanJobRepository) UpdatePemeriksaanJob(job *models.PemeriksaanJ
 	if err != nil {
 		return fmt.Errorf("failed to update Pemeriksaan job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DeletePemeriksaanJob deletes a Pemeriksaan job by its ID.
-func (r *pemeriksaanJobRepository) DeletePemeriksaanJob(id string) error {
+func (r *pemeriksaanJobRepository) DeletePemeriksaanJob(ctx context.Context, id string) error {
 	query := `DELETE FROM pemeriksaan_jobs WHERE job_id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete Pemeriksaan job: %w", err)
 	}
@@ -175,7 +212,7 @@ func (r *pemeriksaanJobRepository) DeletePemeriksaanJob(id string) error {
 }
 
 // GetPemeriksaanJobsByClientID fetches all Pemeriksaan jobs for a specific client.
-func (r *pemeriksaanJobRepository) GetPemeriksaanJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
+func (r *pemeriksaanJobRepository) GetPemeriksaanJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.PemeriksaanJob, error) {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -195,7 +232,7 @@ func (r *pemeriksaanJobRepository) GetPemeriksaanJobsByClientID(clientID string,
 	}
 	query += " ORDER BY pj.created_at DESC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Pemeriksaan jobs by client ID: %w", err)
 	}
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// PortalRepository defines the read-only queries behind the client portal. Every query is
+// scoped to one client ID and returns only client-facing columns.
+type PortalRepository interface {
+	GetJobs(ctx context.Context, clientID string) ([]models.PortalJob, error)
+	// GetTaxReports returns the monthly and annual tax reports of jobs in the given year.
+	GetTaxReports(ctx context.Context, clientID string, year int) ([]models.PortalTaxReport, error)
+	// GetInvoices lists the client's invoices without line items. Draft invoices are not shown.
+	GetInvoices(ctx context.Context, clientID string) ([]models.PortalInvoice, error)
+	GetDocuments(ctx context.Context, clientID string) ([]models.PortalDocument, error)
+}
+
+// portalRepository implements PortalRepository interface
+type portalRepository struct {
+	db *sql.DB
+}
+
+// NewPortalRepository creates a new PortalRepository
+func NewPortalRepository(db *sql.DB) PortalRepository {
+	return &portalRepository{db: db}
+}
+
+// GetJobs lists every job of the client, newest first, with the proof-of-work document if any
+func (r *portalRepository) GetJobs(ctx context.Context, clientID string) ([]models.PortalJob, error) {
+	query := `SELECT j.job_type, j.job_id, j.job_month, j.job_year, j.letter_no, j.overall_status, j.updated_at, d.document_id
+	FROM (
+		SELECT '` + models.JobTypeMonthly + `' AS job_type, job_id, client_id, job_month, job_year, NULL AS letter_no,
+			overall_status, proof_of_work_url, updated_at
+		FROM monthly_jobs WHERE client_id = $1
+		UNION ALL
+		SELECT '` + models.JobTypeAnnual + `', job_id, client_id, NULL, job_year, NULL,
+			overall_status, proof_of_work_url, updated_at
+		FROM annual_jobs WHERE client_id = $1
+		UNION ALL
+		SELECT '` + models.JobTypeSp2dk + `', job_id, client_id, NULL, NULL, sp2dk_no,
+			overall_status, proof_of_work_url, updated_at
+		FROM sp2dk_jobs WHERE client_id = $1
+		UNION ALL
+		SELECT '` + models.JobTypePemeriksaan + `', job_id, client_id, NULL, NULL, sp2_no,
+			overall_status, proof_of_work_url, updated_at
+		FROM pemeriksaan_jobs WHERE client_id = $1
+	) AS j
+	LEFT JOIN documents AS d ON d.file_url = j.proof_of_work_url AND d.client_id = j.client_id
+	ORDER BY j.updated_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.PortalJob{}
+	for rows.Next() {
+		var job models.PortalJob
+		var month, year sql.NullInt64
+		var letterNo, status, documentID sql.NullString
+		err := rows.Scan(&job.JobType, &job.JobID, &month, &year, &letterNo, &status, &job.UpdatedAt, &documentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portal job row: %w", err)
+		}
+		job.Period = portalPeriod(job.JobType, int(month.Int64), int(year.Int64), letterNo.String)
+		job.OverallStatus = status.String
+		if documentID.Valid {
+			job.ProofDocumentID = &documentID.String
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for portal jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// portalPeriod memakai label periode yang sama dengan notifikasi
+func portalPeriod(jobType string, month, year int, letterNo string) string {
+	switch jobType {
+	case models.JobTypeMonthly:
+		return (&models.MonthlyJob{JobMonth: month, JobYear: year}).PeriodLabel()
+	case models.JobTypeAnnual:
+		return (&models.AnnualJob{JobYear: year}).PeriodLabel()
+	case models.JobTypeSp2dk:
+		return (&models.Sp2dkJob{Sp2dkNo: letterNo}).PeriodLabel()
+	case models.JobTypePemeriksaan:
+		return (&models.PemeriksaanJob{Sp2No: letterNo}).PeriodLabel()
+	}
+	return ""
+}
+
+// GetTaxReports returns the tax reports of the year ordered by period and tax type
+func (r *portalRepository) GetTaxReports(ctx context.Context, clientID string, year int) ([]models.PortalTaxReport, error) {
+	query := `SELECT job_type, job_id, job_month, job_year, tax_type, report_status, report_date,
+		billing_code, payment_date, payment_amount, ntpn, payment_channel
+	FROM (
+		SELECT '` + models.JobTypeMonthly + `' AS job_type, mj.job_id, mj.job_month, mj.job_year, mtr.tax_type,
+			mtr.report_status, mtr.report_date, mtr.billing_code, mtr.payment_date, mtr.payment_amount,
+			mtr.ntpn, mtr.payment_channel
+		FROM monthly_tax_reports AS mtr
+		JOIN monthly_jobs AS mj ON mtr.job_id = mj.job_id
+		WHERE mj.client_id = $1 AND mj.job_year = $2
+		UNION ALL
+		SELECT '` + models.JobTypeAnnual + `', aj.job_id, 13, aj.job_year, 'SPT Tahunan',
+			atr.report_status, atr.report_date, atr.billing_code, atr.payment_date, atr.payment_amount,
+			atr.ntpn, atr.payment_channel
+		FROM annual_tax_reports AS atr
+		JOIN annual_jobs AS aj ON atr.job_id = aj.job_id
+		WHERE aj.client_id = $1 AND aj.job_year = $2
+	) AS t
+	ORDER BY job_month, tax_type`
+
+	rows, err := r.db.QueryContext(ctx, query, clientID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal tax reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.PortalTaxReport{}
+	for rows.Next() {
+		var rep models.PortalTaxReport
+		var month, jobYear int
+		var status, billingCode, ntpn, channel sql.NullString
+		var reportDate, paymentDate sql.NullTime
+		var paymentAmount sql.NullFloat64
+		err := rows.Scan(&rep.JobType, &rep.JobID, &month, &jobYear, &rep.TaxType, &status, &reportDate,
+			&billingCode, &paymentDate, &paymentAmount, &ntpn, &channel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portal tax report row: %w", err)
+		}
+		rep.Period = portalPeriod(rep.JobType, month, jobYear, "")
+		rep.ReportStatus = status.String
+		rep.BillingCode = billingCode.String
+		rep.Ntpn = ntpn.String
+		rep.PaymentChannel = channel.String
+		if reportDate.Valid {
+			rep.ReportDate = &reportDate.Time
+		}
+		if paymentDate.Valid {
+			rep.PaymentDate = &paymentDate.Time
+		}
+		if paymentAmount.Valid {
+			rep.PaymentAmount = &paymentAmount.Float64
+		}
+		reports = append(reports, rep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for portal tax reports: %w", err)
+	}
+	return reports, nil
+}
+
+// GetInvoices lists the client's invoices, newest first
+func (r *portalRepository) GetInvoices(ctx context.Context, clientID string) ([]models.PortalInvoice, error) {
+	query := `SELECT invoice_id, invoice_number, invoice_date, due_date, total_amount, status, notes
+	FROM invoices
+	WHERE client_id = $1 AND status <> '` + models.InvoiceStatusDraft + `'
+	ORDER BY invoice_date DESC, invoice_number DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []models.PortalInvoice{}
+	for rows.Next() {
+		var inv models.PortalInvoice
+		var invoiceDate, dueDate time.Time
+		var notes sql.NullString
+		err := rows.Scan(&inv.InvoiceID, &inv.InvoiceNumber, &invoiceDate, &dueDate, &inv.TotalAmount, &inv.Status, &notes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portal invoice row: %w", err)
+		}
+		inv.InvoiceDate = models.CustomDate{Time: invoiceDate}
+		inv.DueDate = models.CustomDate{Time: dueDate}
+		if notes.Valid {
+			inv.Notes = &notes.String
+		}
+		invoices = append(invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for portal invoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// GetDocuments lists the client's uploaded documents, newest first
+func (r *portalRepository) GetDocuments(ctx context.Context, clientID string) ([]models.PortalDocument, error) {
+	query := `SELECT document_id, job_type, job_id, file_name, created_at
+	FROM documents WHERE client_id = $1
+	ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []models.PortalDocument{}
+	for rows.Next() {
+		var doc models.PortalDocument
+		if err := rows.Scan(&doc.DocumentID, &doc.JobType, &doc.JobID, &doc.FileName, &doc.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan portal document row: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for portal documents: %w", err)
+	}
+	return docs, nil
+}
@@ -0,0 +1,338 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/repotest"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/database"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+)
+
+// openTestDB membuka database dari DATABASE_URL, atau melewati test jika tidak diisi. Skema
+// harus sudah dibuat dengan init.sql; setiap case menghapus datanya sendiri.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	db, err := database.InitDB(databaseURL, database.PoolConfig{})
+	if err != nil {
+		t.Fatalf("connect to database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestContract(t *testing.T) {
+	db := openTestDB(t)
+	repotest.Run(t, repotest.Repositories{
+		Clients:         repositories.NewClientRepository(db),
+		Staffs:          repositories.NewStaffRepository(db),
+		MonthlyJobs:     repositories.NewMonthlyJobRepository(db),
+		AnnualJobs:      repositories.NewAnnualJobRepository(db),
+		Sp2dkJobs:       repositories.NewSp2dkJobRepository(db),
+		PemeriksaanJobs: repositories.NewPemeriksaanJobRepository(db),
+		Invoices:        repositories.NewInvoiceRepository(db),
+	})
+}
+
+// newTestClient membuat klien dengan NPWP acak yang dihapus, beserta pekerjaan dan invoice-nya,
+// saat test selesai
+func newTestClient(ctx context.Context, t *testing.T, db *sql.DB) *models.Client {
+	t.Helper()
+	var n npwp.Number
+	for {
+		var err error
+		if n, err = npwp.Parse(fmt.Sprintf("%015d", rand.Int63n(1e15))); err == nil {
+			break
+		}
+	}
+	clients := repositories.NewClientRepository(db)
+	client := &models.Client{
+		ClientName:       "Regression " + n.Canonical(),
+		NpwpClient:       n.Format(),
+		NpwpCanonical:    n.Canonical(),
+		MembershipStatus: "Aktif",
+		ClientCategory:   "Badan",
+	}
+	if err := clients.CreateClient(ctx, client); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { clients.DeleteClient(context.Background(), client.ClientID) })
+	return client
+}
+
+// Regression: daftar pekerjaan dan invoice menyalin struct sebelum laporan atau line item
+// dari baris berikutnya ditambahkan, sehingga hasil daftar selalu tanpa laporan.
+func TestListsIncludeChildRows(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	client := newTestClient(ctx, t, db)
+
+	monthlyJobs := repositories.NewMonthlyJobRepository(db)
+	monthly := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 5, JobYear: 2001, OverallStatus: "Dikerjakan",
+		TaxReports: []models.MonthlyTaxReport{{TaxType: models.TaxTypePpn}, {TaxType: models.TaxTypePph21}}}
+	if err := monthlyJobs.CreateMonthlyJob(ctx, monthly); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	annualJobs := repositories.NewAnnualJobRepository(db)
+	annual := &models.AnnualJob{ClientID: client.ClientID, JobYear: 2001, OverallStatus: "Dikerjakan",
+		TaxReports: []models.AnnualTaxReport{{ReportStatus: "Belum Lapor"}, {ReportStatus: "Belum Lapor"}}}
+	if err := annualJobs.CreateAnnualJob(ctx, annual); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+	invoices := repositories.NewInvoiceRepository(db)
+	invoice := &models.Invoice{ClientID: client.ClientID, Status: models.InvoiceStatusDraft,
+		InvoiceDate: models.CustomDate{Time: time.Date(2001, 5, 1, 0, 0, 0, 0, time.UTC)},
+		DueDate:     models.CustomDate{Time: time.Date(2001, 5, 15, 0, 0, 0, 0, time.UTC)},
+		LineItems: []models.InvoiceLineItem{
+			{Description: "Jasa 1", Quantity: 1, UnitPrice: 1000, Amount: 1000},
+			{Description: "Jasa 2", Quantity: 1, UnitPrice: 2000, Amount: 2000},
+		}}
+	if err := invoices.CreateInvoice(ctx, invoice); err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+
+	counts := map[string]int{}
+	allMonthly, err := monthlyJobs.GetAllMonthlyJobs(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllMonthlyJobs: %v", err)
+	}
+	byClientMonthly, err := monthlyJobs.GetMonthlyJobsByClientID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobsByClientID: %v", err)
+	}
+	for name, jobs := range map[string][]models.MonthlyJob{"GetAllMonthlyJobs": allMonthly, "GetMonthlyJobsByClientID": byClientMonthly} {
+		for _, job := range jobs {
+			if job.JobID == monthly.JobID {
+				counts[name] = len(job.TaxReports)
+			}
+		}
+	}
+	allAnnual, err := annualJobs.GetAllAnnualJobs(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllAnnualJobs: %v", err)
+	}
+	byClientAnnual, err := annualJobs.GetAnnualJobsByClientID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetAnnualJobsByClientID: %v", err)
+	}
+	for name, jobs := range map[string][]models.AnnualJob{"GetAllAnnualJobs": allAnnual, "GetAnnualJobsByClientID": byClientAnnual} {
+		for _, job := range jobs {
+			if job.JobID == annual.JobID {
+				counts[name] = len(job.TaxReports)
+			}
+		}
+	}
+	allInvoices, err := invoices.GetAllInvoices(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllInvoices: %v", err)
+	}
+	for _, inv := range allInvoices {
+		if inv.InvoiceID == invoice.InvoiceID {
+			counts["GetAllInvoices"] = len(inv.LineItems)
+		}
+	}
+
+	for _, name := range []string{"GetAllMonthlyJobs", "GetMonthlyJobsByClientID", "GetAllAnnualJobs", "GetAnnualJobsByClientID", "GetAllInvoices"} {
+		if counts[name] != 2 {
+			t.Errorf("%s returned %d child rows, want 2", name, counts[name])
+		}
+	}
+}
+
+// Regression: nomor urut dibaca dari posisi di tengah tanggal, sehingga invoice kedua pada
+// tanggal yang sama gagal dibuat.
+func TestInvoiceNumberSequence(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	client := newTestClient(ctx, t, db)
+	invoices := repositories.NewInvoiceRepository(db)
+
+	// Tanggal lama yang acak, supaya tidak melanjutkan nomor invoice yang sudah ada
+	date := time.Date(1980+rand.Intn(10), time.Month(1+rand.Intn(12)), 1+rand.Intn(28), 0, 0, 0, 0, time.UTC)
+	prefix := "INV/" + date.Format("20060102") + "/"
+	var numbers []string
+	for i := 0; i < 3; i++ {
+		invoice := &models.Invoice{ClientID: client.ClientID, Status: models.InvoiceStatusDraft,
+			InvoiceDate: models.CustomDate{Time: date}, DueDate: models.CustomDate{Time: date.AddDate(0, 0, 14)}}
+		if err := invoices.CreateInvoice(ctx, invoice); err != nil {
+			t.Fatalf("CreateInvoice %d on %s: %v", i+1, date.Format("2006-01-02"), err)
+		}
+		numbers = append(numbers, invoice.InvoiceNumber)
+	}
+
+	var first int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(numbers[0], prefix), "%d", &first); err != nil || !strings.HasPrefix(numbers[0], prefix) {
+		t.Fatalf("invoice number %q does not start with %q", numbers[0], prefix)
+	}
+	for i, number := range numbers {
+		if want := fmt.Sprintf("%s%03d", prefix, first+i); number != want {
+			t.Errorf("invoice %d number = %q, want %q", i+1, number, want)
+		}
+	}
+}
+
+func TestSaveImportSources(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	client := newTestClient(ctx, t, db)
+	monthlyJobs := repositories.NewMonthlyJobRepository(db)
+	job := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 6, JobYear: 2001, OverallStatus: "Dikerjakan",
+		TaxReports: []models.MonthlyTaxReport{{TaxType: models.TaxTypePpn}}}
+	if err := monthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	reportID := job.TaxReports[0].ReportID
+	imports := repositories.NewTaxImportRepository(db)
+
+	steps := []struct {
+		name        string
+		sources     []models.TaxImportSource
+		wantAmount  float64
+		wantBilling float64
+		wantFiles   int
+	}{
+		{"keluaran", []models.TaxImportSource{{FileName: "keluaran.xml", LineCount: 2, TaxBase: 1500000, TaxAmount: 165000}}, 165000, 165000, 1},
+		{"masukan in a later import", []models.TaxImportSource{{FileName: "masukan.csv", LineCount: 1, TaxAmount: -55000}}, 110000, 110000, 2},
+		{"masukan imported again", []models.TaxImportSource{{FileName: "masukan.csv", LineCount: 1, TaxAmount: -200000}}, -35000, 0, 2},
+	}
+	for _, step := range steps {
+		total, err := imports.SaveImportSources(ctx, reportID, step.sources)
+		if err != nil {
+			t.Fatalf("%s: SaveImportSources: %v", step.name, err)
+		}
+		if total.TaxAmount != step.wantAmount || total.BillingAmount != step.wantBilling || len(total.SourceFiles) != step.wantFiles {
+			t.Errorf("%s: total = %+v, want tax amount %v, billing amount %v from %d files",
+				step.name, total, step.wantAmount, step.wantBilling, step.wantFiles)
+		}
+		report, err := monthlyJobs.GetMonthlyTaxReportByID(ctx, reportID)
+		if err != nil {
+			t.Fatalf("%s: GetMonthlyTaxReportByID: %v", step.name, err)
+		}
+		if report.BillingAmount == nil || *report.BillingAmount != step.wantBilling {
+			t.Errorf("%s: stored billing amount = %v, want %v", step.name, report.BillingAmount, step.wantBilling)
+		}
+	}
+}
+
+// newTestStaff membuat staf dengan email acak yang dihapus saat test selesai
+func newTestStaff(ctx context.Context, t *testing.T, db *sql.DB) *models.Staff {
+	t.Helper()
+	staffs := repositories.NewStaffRepository(db)
+	staff := &models.Staff{Nama: "Regression", Email: fmt.Sprintf("regression-%d@example.com", rand.Int63()),
+		PasswordHashed: "x", Role: "staff"}
+	if err := staffs.CreateStaff(ctx, staff); err != nil {
+		t.Fatalf("create staff: %v", err)
+	}
+	t.Cleanup(func() { staffs.DeleteStaff(context.Background(), staff.StaffID) })
+	return staff
+}
+
+// Staf non-admin hanya menemukan dokumen dari pekerjaan yang ditugaskan kepadanya, walaupun
+// dokumen lain diunggah olehnya sendiri.
+func TestSearchDocuments(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	pic := newTestStaff(ctx, t, db)
+	other := newTestStaff(ctx, t, db)
+	client := newTestClient(ctx, t, db)
+	second := newTestClient(ctx, t, db)
+
+	monthlyJobs := repositories.NewMonthlyJobRepository(db)
+	monthly := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 7, JobYear: 2001, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	secondMonthly := &models.MonthlyJob{ClientID: second.ClientID, JobMonth: 7, JobYear: 2001, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	for _, job := range []*models.MonthlyJob{monthly, secondMonthly} {
+		if err := monthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+			t.Fatalf("CreateMonthlyJob: %v", err)
+		}
+	}
+	annual := &models.AnnualJob{ClientID: client.ClientID, JobYear: 2001, AssignedPicStaffSigmaID: other.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repositories.NewAnnualJobRepository(db).CreateAnnualJob(ctx, annual); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+
+	// Kata unik supaya dokumen dari test lain tidak ikut ditemukan
+	token := fmt.Sprintf("regresi%d", rand.Int63())
+	documents := repositories.NewDocumentRepository(db)
+	newDocument := func(clientID, jobType, jobID string, text string) string {
+		t.Helper()
+		doc := &models.Document{ClientID: clientID, JobType: jobType, JobID: jobID, FileName: jobType + ".pdf",
+			FileURL: "/uploads/" + token + "/" + jobID + ".pdf", UploadedByStaffID: &pic.StaffID}
+		if err := documents.UpsertDocument(ctx, doc); err != nil {
+			t.Fatalf("UpsertDocument: %v", err)
+		}
+		if err := documents.UpdateDocumentExtraction(ctx, doc.DocumentID, models.ExtractionStatusDone, text, ""); err != nil {
+			t.Fatalf("UpdateDocumentExtraction: %v", err)
+		}
+		return doc.DocumentID
+	}
+	monthlyDoc := newDocument(client.ClientID, models.JobTypeMonthly, monthly.JobID, "Bukti Penerimaan Elektronik "+token+" PPN")
+	secondDoc := newDocument(second.ClientID, models.JobTypeMonthly, secondMonthly.JobID, "Bukti Penerimaan Elektronik "+token+" PPh 21")
+	annualDoc := newDocument(client.ClientID, models.JobTypeAnnual, annual.JobID, "SPT Tahunan "+token)
+
+	tests := []struct {
+		name    string
+		params  models.DocumentSearchParams
+		staffID string
+		isAdmin bool
+		want    []string
+	}{
+		{"admin", models.DocumentSearchParams{Query: token}, "", true, []string{monthlyDoc, secondDoc, annualDoc}},
+		{"words of the query", models.DocumentSearchParams{Query: token + " tahunan"}, "", true, []string{annualDoc}},
+		{"client filter", models.DocumentSearchParams{Query: token, ClientID: second.ClientID}, "", true, []string{secondDoc}},
+		{"job type filter", models.DocumentSearchParams{Query: token, JobType: models.JobTypeAnnual}, "", true, []string{annualDoc}},
+		{"job PIC", models.DocumentSearchParams{Query: token}, pic.StaffID, false, []string{monthlyDoc, secondDoc}},
+		{"other job PIC", models.DocumentSearchParams{Query: token}, other.StaffID, false, []string{annualDoc}},
+		{"job PIC with job type filter", models.DocumentSearchParams{Query: token, JobType: models.JobTypeAnnual}, pic.StaffID, false, nil},
+		{"no match", models.DocumentSearchParams{Query: token + "x"}, "", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := documents.SearchDocuments(ctx, tt.params, tt.staffID, tt.isAdmin)
+			if err != nil {
+				t.Fatalf("SearchDocuments: %v", err)
+			}
+			if results == nil {
+				t.Error("results are nil, want an empty list")
+			}
+			got := map[string]bool{}
+			for _, result := range results {
+				got[result.DocumentID] = true
+			}
+			if len(got) != len(tt.want) || len(results) != len(tt.want) {
+				t.Fatalf("found %d documents, want %d", len(results), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("document %s not found", id)
+				}
+			}
+		})
+	}
+
+	results, err := documents.SearchDocuments(ctx, models.DocumentSearchParams{Query: token, Limit: 2}, "", true)
+	if err != nil {
+		t.Fatalf("SearchDocuments with limit: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("limit 2 returned %d documents", len(results))
+	}
+
+	if _, err := documents.GetDocumentByID(ctx, monthlyDoc, pic.StaffID, false); err != nil {
+		t.Errorf("GetDocumentByID of own job: %v", err)
+	}
+	if _, err := documents.GetDocumentByID(ctx, annualDoc, pic.StaffID, false); err != sql.ErrNoRows {
+		t.Errorf("GetDocumentByID of another PIC's job: err = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/ratelimit"
+)
+
+// RateLimitRepository defines the shared token bucket store for multi-instance deployments
+type RateLimitRepository interface {
+	// Take takes one token from the bucket of key, creating a full bucket if there is none.
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+	// DeleteIdleBuckets deletes buckets that are full again, and returns how many were deleted.
+	DeleteIdleBuckets(ctx context.Context) (int64, error)
+}
+
+// rateLimitRepository implements RateLimitRepository interface
+type rateLimitRepository struct {
+	db *sql.DB
+}
+
+// NewRateLimitRepository creates a new RateLimitRepository
+func NewRateLimitRepository(db *sql.DB) RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// Take locks the bucket row, so concurrent requests on other instances wait for each other.
+// Elapsed time is measured with the database clock to avoid clock skew between instances.
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at, idle_until)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (bucket_key) DO NOTHING`, key, limit.Burst)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var tokens, elapsedSeconds float64
+	err = tx.QueryRowContext(ctx, `SELECT tokens, GREATEST(EXTRACT(EPOCH FROM NOW() - updated_at), 0)
+		FROM rate_limit_buckets WHERE bucket_key = $1 FOR UPDATE`, key).Scan(&tokens, &elapsedSeconds)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to lock rate limit bucket: %w", err)
+	}
+
+	tokens, result := ratelimit.Apply(tokens, time.Duration(elapsedSeconds*float64(time.Second)), limit)
+	// Bucket penuh kembali setelah token yang kurang terisi ulang
+	idle := time.Duration((float64(limit.Burst) - tokens) * float64(limit.Refill))
+	_, err = tx.ExecContext(ctx, `UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = NOW(), idle_until = NOW() + $3 * INTERVAL '1 microsecond'
+		WHERE bucket_key = $1`, key, tokens, idle.Microseconds())
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to commit rate limit bucket: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteIdleBuckets removes buckets past their idle_until
+func (r *rateLimitRepository) DeleteIdleBuckets(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE idle_until < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle rate limit buckets: %w", err)
+	}
+	return res.RowsAffected()
+}
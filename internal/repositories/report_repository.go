@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxcalendar"
+	"github.com/lib/pq"
+)
+
+// ReportRepository defines read-only aggregate queries for management reports
+type ReportRepository interface {
+	GetWorkload(ctx context.Context, params models.WorkloadParams, staffIDFilter string, isAdmin bool) (*models.WorkloadReport, error)
+	GetComplianceEntries(ctx context.Context, year int, staffIDFilter string, isAdmin bool) ([]models.ComplianceEntry, error)
+	GetRevenueEntries(ctx context.Context, years []int, staffIDFilter string, isAdmin bool) ([]models.RevenueEntry, error)
+}
+
+// reportRepository implements ReportRepository interface
+type reportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new ReportRepository
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// Batas waktu dihitung di SQL dari aturan pkg/taxcalendar, agar filter dan agregasi
+// tetap di database. Argumennya adalah nama kolom.
+func monthlyDueDateSQL(yearColumn, monthColumn string) string {
+	return fmt.Sprintf("(make_date(%s, %s, %d) + INTERVAL '1 month')::date", yearColumn, monthColumn, taxcalendar.MonthlyReportDay)
+}
+
+func annualDueDateSQL(yearColumn string) string {
+	return fmt.Sprintf("make_date(%s + 1, %d, %d)", yearColumn, int(taxcalendar.AnnualReportMonth), taxcalendar.AnnualReportDay)
+}
+
+// jobSources maps each job type to a SELECT producing
+// (job_type, job_id, client_id, staff_id, overall_status, updated_at, due_date, period).
+// Due dates follow pkg/taxcalendar: monthly reports on the 20th of the following month,
+// annual SPT on 30 April of the following year, an SP2DK response 14 days after the
+// SP2DK date, and an audit 6 months after the SP2 date. period is a display label
+// (tax period or letter number).
+var jobSources = map[string]string{
+	models.JobTypeMonthly: `SELECT '` + models.JobTypeMonthly + `' AS job_type, job_id, client_id, assigned_pic_staff_sigma_id AS staff_id,
+		overall_status, updated_at, ` + monthlyDueDateSQL("job_year", "job_month") + ` AS due_date,
+		'Masa ' || lpad(job_month::text, 2, '0') || '/' || job_year AS period
+	FROM monthly_jobs`,
+	models.JobTypeAnnual: `SELECT '` + models.JobTypeAnnual + `', job_id, client_id, assigned_pic_staff_sigma_id,
+		overall_status, updated_at, ` + annualDueDateSQL("job_year") + `,
+		'Tahun Pajak ' || job_year
+	FROM annual_jobs`,
+	models.JobTypeSp2dk: `SELECT '` + models.JobTypeSp2dk + `', job_id, client_id, assigned_pic_staff_sigma_id,
+		overall_status, updated_at, ` + fmt.Sprintf("sp2dk_date + %d", taxcalendar.Sp2dkResponseDays) + `,
+		'SP2DK ' || COALESCE(NULLIF(sp2dk_no, ''), '-')
+	FROM sp2dk_jobs`,
+	models.JobTypePemeriksaan: `SELECT '` + models.JobTypePemeriksaan + `', job_id, client_id, assigned_pic_staff_sigma_id,
+		overall_status, updated_at, ` + fmt.Sprintf("(sp2_date + INTERVAL '%d months')::date", taxcalendar.AuditMonths) + `,
+		'SP2 ' || COALESCE(NULLIF(sp2_no, ''), '-')
+	FROM pemeriksaan_jobs`,
+}
+
+// GetWorkload aggregates open, overdue and completed jobs plus client counts per PIC staff.
+// Non-admins only see their own row.
+func (r *reportRepository) GetWorkload(ctx context.Context, params models.WorkloadParams, staffIDFilter string, isAdmin bool) (*models.WorkloadReport, error) {
+	jobTypes := params.JobTypes
+	if len(jobTypes) == 0 {
+		jobTypes = models.JobTypes
+	}
+	var sources []string
+	for _, jobType := range jobTypes {
+		source, ok := jobSources[jobType]
+		if !ok {
+			return nil, fmt.Errorf("unknown job type: %s", jobType)
+		}
+		sources = append(sources, source)
+	}
+
+	query := `WITH jobs AS (
+		` + strings.Join(sources, "\n\t\tUNION ALL\n\t\t") + `
+	)
+	SELECT staff_id, job_type,
+		COUNT(*) FILTER (WHERE COALESCE(overall_status, '') NOT IN ($1, $2)) AS open_jobs,
+		COUNT(*) FILTER (WHERE COALESCE(overall_status, '') NOT IN ($1, $2) AND due_date < CURRENT_DATE) AS overdue_jobs,
+		COUNT(*) FILTER (WHERE overall_status = $1 AND updated_at >= $3 AND updated_at < $4) AS completed_jobs
+	FROM jobs`
+
+	args := []interface{}{models.JobStatusCompleted, models.JobStatusCancelled, params.PeriodStart, params.PeriodEnd}
+	if !isAdmin && staffIDFilter != "" {
+		query += " WHERE staff_id = $5"
+		args = append(args, staffIDFilter)
+	}
+	query += " GROUP BY staff_id, job_type"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workload: %w", err)
+	}
+	defer rows.Close()
+
+	byStaff := make(map[string]*models.StaffWorkload) // "" = belum ada PIC
+	row := func(staffID sql.NullString) *models.StaffWorkload {
+		w, ok := byStaff[staffID.String]
+		if !ok {
+			w = &models.StaffWorkload{ByJobType: make(map[string]models.WorkloadJobCounts)}
+			if staffID.Valid {
+				id := staffID.String
+				w.StaffID = &id
+			} else {
+				w.StaffName = "Belum ada PIC"
+			}
+			byStaff[staffID.String] = w
+		}
+		return w
+	}
+
+	for rows.Next() {
+		var staffID sql.NullString
+		var jobType string
+		var counts models.WorkloadJobCounts
+		if err := rows.Scan(&staffID, &jobType, &counts.OpenJobs, &counts.OverdueJobs, &counts.CompletedJobs); err != nil {
+			return nil, fmt.Errorf("failed to scan workload row: %w", err)
+		}
+		w := row(staffID)
+		w.ByJobType[jobType] = counts
+		w.Totals.OpenJobs += counts.OpenJobs
+		w.Totals.OverdueJobs += counts.OverdueJobs
+		w.Totals.CompletedJobs += counts.CompletedJobs
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for workload: %w", err)
+	}
+
+	// Jumlah klien per PIC
+	clientQuery := `SELECT pic_staff_sigma_id, COUNT(*) FROM clients`
+	clientArgs := []interface{}{}
+	if !isAdmin && staffIDFilter != "" {
+		clientQuery += " WHERE pic_staff_sigma_id = $1"
+		clientArgs = append(clientArgs, staffIDFilter)
+	}
+	clientQuery += " GROUP BY pic_staff_sigma_id"
+
+	clientRows, err := r.db.QueryContext(ctx, clientQuery, clientArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clients per staff: %w", err)
+	}
+	defer clientRows.Close()
+	for clientRows.Next() {
+		var staffID sql.NullString
+		var count int
+		if err := clientRows.Scan(&staffID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan client count row: %w", err)
+		}
+		row(staffID).Totals.Clients = count
+	}
+	if err := clientRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for client counts: %w", err)
+	}
+
+	// Semua staf tetap muncul walaupun belum memegang pekerjaan
+	staffQuery := `SELECT staff_id, nama, role FROM staffs`
+	staffArgs := []interface{}{}
+	if !isAdmin && staffIDFilter != "" {
+		staffQuery += " WHERE staff_id = $1"
+		staffArgs = append(staffArgs, staffIDFilter)
+	}
+	staffRows, err := r.db.QueryContext(ctx, staffQuery, staffArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staffs for workload: %w", err)
+	}
+	defer staffRows.Close()
+	for staffRows.Next() {
+		var staffID sql.NullString
+		var name, role string
+		if err := staffRows.Scan(&staffID, &name, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan staff row: %w", err)
+		}
+		w := row(staffID)
+		w.StaffName = name
+		w.Role = role
+	}
+	if err := staffRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for staffs: %w", err)
+	}
+
+	report := &models.WorkloadReport{
+		PeriodStart: params.PeriodStart,
+		PeriodEnd:   params.PeriodEnd,
+		JobTypes:    jobTypes,
+		Staff:       make([]models.StaffWorkload, 0, len(byStaff)),
+	}
+	for _, w := range byStaff {
+		report.Staff = append(report.Staff, *w)
+		report.Totals.OpenJobs += w.Totals.OpenJobs
+		report.Totals.OverdueJobs += w.Totals.OverdueJobs
+		report.Totals.CompletedJobs += w.Totals.CompletedJobs
+		report.Totals.Clients += w.Totals.Clients
+	}
+	// Beban kerja terbesar di atas; baris tanpa PIC selalu di akhir
+	sort.Slice(report.Staff, func(i, j int) bool {
+		a, b := report.Staff[i], report.Staff[j]
+		if (a.StaffID == nil) != (b.StaffID == nil) {
+			return b.StaffID == nil
+		}
+		if a.Totals.OpenJobs != b.Totals.OpenJobs {
+			return a.Totals.OpenJobs > b.Totals.OpenJobs
+		}
+		return a.StaffName < b.StaffName
+	})
+	return report, nil
+}
+
+// GetComplianceEntries fetches every monthly job of the year with its tax reports, one row per report.
+// Non-admins only see jobs of clients they are PIC for, matching ClientRepository.GetAllClients.
+func (r *reportRepository) GetComplianceEntries(ctx context.Context, year int, staffIDFilter string, isAdmin bool) ([]models.ComplianceEntry, error) {
+	query := `SELECT
+		mj.client_id, mj.job_id, mj.job_month, COALESCE(mj.overall_status, ''),
+		mtr.report_id, mtr.tax_type, mtr.billing_code, mtr.payment_date, mtr.payment_amount,
+		mtr.report_status, mtr.report_date,
+		mtr.billing_code_expires_at, mtr.billing_amount, mtr.ntpn, mtr.payment_channel
+	FROM monthly_jobs AS mj
+	JOIN clients AS c ON mj.client_id = c.client_id
+	LEFT JOIN monthly_tax_reports AS mtr ON mj.job_id = mtr.job_id
+	WHERE mj.job_year = $1`
+
+	args := []interface{}{year}
+	if !isAdmin && staffIDFilter != "" {
+		query += " AND c.pic_staff_sigma_id = $2"
+		args = append(args, staffIDFilter)
+	}
+	query += " ORDER BY mj.client_id, mj.job_month, mtr.tax_type"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compliance entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.ComplianceEntry
+	for rows.Next() {
+		var entry models.ComplianceEntry
+		var reportID, taxType, billingCode, reportStatus sql.NullString
+		var paymentDate, reportDate sql.NullTime
+		var paymentAmount sql.NullFloat64
+		var payment taxPaymentScan
+
+		err := rows.Scan(
+			&entry.ClientID, &entry.JobID, &entry.JobMonth, &entry.OverallStatus,
+			&reportID, &taxType, &billingCode, &paymentDate, &paymentAmount,
+			&reportStatus, &reportDate,
+			&payment.billingCodeExpiresAt, &payment.billingAmount, &payment.ntpn, &payment.paymentChannel,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compliance entry: %w", err)
+		}
+
+		if reportID.Valid {
+			report := &models.MonthlyTaxReport{
+				ReportID:     reportID.String,
+				JobID:        entry.JobID,
+				TaxType:      taxType.String,
+				BillingCode:  billingCode.String,
+				ReportStatus: reportStatus.String,
+			}
+			if paymentDate.Valid {
+				report.PaymentDate = &paymentDate.Time
+			}
+			if paymentAmount.Valid {
+				report.PaymentAmount = &paymentAmount.Float64
+			}
+			if reportDate.Valid {
+				report.ReportDate = &reportDate.Time
+			}
+			payment.apply(&report.TaxPaymentEvidence, report.PaymentAmount)
+			entry.Report = report
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for compliance entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetRevenueEntries sums invoice line items per year, month, job type, client category and staff.
+// Billed revenue is dated by invoice_date; collected revenue by paid_at (or updated_at for
+// invoices marked Paid before paid_at existed). Non-admins only see their own invoices.
+func (r *reportRepository) GetRevenueEntries(ctx context.Context, years []int, staffIDFilter string, isAdmin bool) ([]models.RevenueEntry, error) {
+	staffCondition := ""
+	args := []interface{}{pq.Array(years), models.InvoiceStatusDraft, models.InvoiceStatusCancelled, models.InvoiceStatusPaid}
+	if !isAdmin && staffIDFilter != "" {
+		staffCondition = " AND i.assigned_staff_id = $5"
+		args = append(args, staffIDFilter)
+	}
+
+	query := `SELECT '` + models.RevenueKindBilled + `' AS kind,
+		EXTRACT(YEAR FROM i.invoice_date)::int, EXTRACT(MONTH FROM i.invoice_date)::int,
+		COALESCE(ili.related_job_type, ''), COALESCE(c.client_category, ''), i.assigned_staff_id, COALESCE(s.nama, ''),
+		SUM(ili.amount)
+	FROM invoices AS i
+	JOIN invoice_line_items AS ili ON i.invoice_id = ili.invoice_id
+	JOIN clients AS c ON i.client_id = c.client_id
+	LEFT JOIN staffs AS s ON i.assigned_staff_id = s.staff_id
+	WHERE EXTRACT(YEAR FROM i.invoice_date)::int = ANY($1)
+		AND COALESCE(i.status, '') NOT IN ($2, $3)` + staffCondition + `
+	GROUP BY 2, 3, 4, 5, 6, 7
+	UNION ALL
+	SELECT '` + models.RevenueKindCollected + `',
+		EXTRACT(YEAR FROM COALESCE(i.paid_at, i.updated_at))::int, EXTRACT(MONTH FROM COALESCE(i.paid_at, i.updated_at))::int,
+		COALESCE(ili.related_job_type, ''), COALESCE(c.client_category, ''), i.assigned_staff_id, COALESCE(s.nama, ''),
+		SUM(ili.amount)
+	FROM invoices AS i
+	JOIN invoice_line_items AS ili ON i.invoice_id = ili.invoice_id
+	JOIN clients AS c ON i.client_id = c.client_id
+	LEFT JOIN staffs AS s ON i.assigned_staff_id = s.staff_id
+	WHERE EXTRACT(YEAR FROM COALESCE(i.paid_at, i.updated_at))::int = ANY($1)
+		AND i.status = $4` + staffCondition + `
+	GROUP BY 2, 3, 4, 5, 6, 7`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.RevenueEntry
+	for rows.Next() {
+		var entry models.RevenueEntry
+		var staffID sql.NullString
+		if err := rows.Scan(&entry.Kind, &entry.Year, &entry.Month, &entry.JobType, &entry.ClientCategory,
+			&staffID, &entry.StaffName, &entry.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue entry: %w", err)
+		}
+		if staffID.Valid {
+			entry.StaffID = &staffID.String
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for revenue entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,186 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+func newAnnualJob(ctx context.Context, t *testing.T, repos Repositories, clientID, picStaffID string, year int) *models.AnnualJob {
+	job := &models.AnnualJob{ClientID: clientID, JobYear: year, AssignedPicStaffSigmaID: picStaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.AnnualJobs.CreateAnnualJob(ctx, job); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+	return job
+}
+
+func testAnnualJobCreateAndGet(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Tahunan", staff.StaffID)
+	job := &models.AnnualJob{
+		ClientID:                client.ClientID,
+		JobYear:                 2004,
+		AssignedPicStaffSigmaID: staff.StaffID,
+		OverallStatus:           "Dikerjakan",
+		TaxReports: []models.AnnualTaxReport{{BillingCode: "987654321098765", PaymentAmount: floatPtr(75000), ReportStatus: "Sudah Bayar",
+			TaxPaymentEvidence: models.TaxPaymentEvidence{BillingAmount: floatPtr(70000)}}},
+		DividendReports: []models.AnnualDividendReport{{IsReported: true, ReportStatus: "Sudah Lapor"}},
+	}
+	if err := repos.AnnualJobs.CreateAnnualJob(ctx, job); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+	if job.JobID == "" || job.TaxReports[0].ReportID == "" || job.DividendReports[0].ReportID == "" {
+		t.Fatalf("CreateAnnualJob did not set the IDs")
+	}
+
+	got, err := repos.AnnualJobs.GetAnnualJobByID(ctx, job.JobID, "", true)
+	if err != nil {
+		t.Fatalf("GetAnnualJobByID: %v", err)
+	}
+	if got.ClientName != client.ClientName || got.AssignedPicStaffSigmaName != staff.Nama || got.JobYear != 2004 || got.ProofOfWorkURL != nil {
+		t.Errorf("GetAnnualJobByID = %+v", got)
+	}
+	if len(got.TaxReports) != 1 || len(got.DividendReports) != 1 {
+		t.Fatalf("GetAnnualJobByID has %d tax and %d dividend reports, want 1 and 1", len(got.TaxReports), len(got.DividendReports))
+	}
+	if got.TaxReports[0].BillingCode != "987654321098765" || !got.TaxReports[0].AmountMismatch {
+		t.Errorf("tax report = %+v, want the billing code and an amount mismatch", got.TaxReports[0])
+	}
+	if !got.DividendReports[0].IsReported || got.DividendReports[0].ReportStatus != "Sudah Lapor" {
+		t.Errorf("dividend report = %+v", got.DividendReports[0])
+	}
+
+	missing, _ := utils.NewUUID()
+	if _, err := repos.AnnualJobs.GetAnnualJobByID(ctx, missing, "", true); !isNotFound(err) {
+		t.Errorf("GetAnnualJobByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testAnnualJobUnique(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Tahunan Unik", "")
+	job := newAnnualJob(ctx, t, repos, client.ClientID, "", 2005)
+
+	dup := &models.AnnualJob{ClientID: client.ClientID, JobYear: 2005, OverallStatus: "Dikerjakan"}
+	if err := repos.AnnualJobs.CreateAnnualJob(ctx, dup); !repositories.IsDuplicate(err) {
+		t.Errorf("CreateAnnualJob for an existing year error = %v, want a duplicate error", err)
+	}
+
+	// Satu pekerjaan tahunan punya paling banyak satu laporan SPT dan satu laporan dividen
+	if err := repos.AnnualJobs.CreateAnnualTaxReport(ctx, &models.AnnualTaxReport{JobID: job.JobID, ReportStatus: "Belum Lapor"}); err != nil {
+		t.Fatalf("CreateAnnualTaxReport: %v", err)
+	}
+	if err := repos.AnnualJobs.CreateAnnualTaxReport(ctx, &models.AnnualTaxReport{JobID: job.JobID}); !repositories.IsDuplicate(err) {
+		t.Errorf("second CreateAnnualTaxReport error = %v, want a duplicate error", err)
+	}
+	if err := repos.AnnualJobs.CreateAnnualDividendReport(ctx, &models.AnnualDividendReport{JobID: job.JobID, ReportStatus: "Belum Lapor"}); err != nil {
+		t.Fatalf("CreateAnnualDividendReport: %v", err)
+	}
+	if err := repos.AnnualJobs.CreateAnnualDividendReport(ctx, &models.AnnualDividendReport{JobID: job.JobID}); !repositories.IsDuplicate(err) {
+		t.Errorf("second CreateAnnualDividendReport error = %v, want a duplicate error", err)
+	}
+}
+
+func testAnnualJobVisibility(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Tahunan Visibilitas", pic.StaffID)
+	older := newAnnualJob(ctx, t, repos, client.ClientID, pic.StaffID, 2005)
+	newer := newAnnualJob(ctx, t, repos, client.ClientID, pic.StaffID, 2006)
+
+	if _, err := repos.AnnualJobs.GetAnnualJobByID(ctx, older.JobID, other.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetAnnualJobByID by other staff error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.AnnualJobs.GetAnnualJobByID(ctx, older.JobID, pic.StaffID, false); err != nil {
+		t.Errorf("GetAnnualJobByID by PIC: %v", err)
+	}
+
+	all, err := repos.AnnualJobs.GetAllAnnualJobs(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllAnnualJobs: %v", err)
+	}
+	byClient, err := repos.AnnualJobs.GetAnnualJobsByClientID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetAnnualJobsByClientID: %v", err)
+	}
+	want := []string{newer.JobID, older.JobID}
+	for name, jobs := range map[string][]models.AnnualJob{"GetAllAnnualJobs": all, "GetAnnualJobsByClientID": byClient} {
+		var ids []string
+		for _, job := range jobs {
+			if job.ClientID == client.ClientID {
+				ids = append(ids, job.JobID)
+			}
+		}
+		if !equalStrings(ids, want) {
+			t.Errorf("%s = %v, want %v (newest year first, each job once)", name, ids, want)
+		}
+	}
+
+	jobs, err := repos.AnnualJobs.GetAnnualJobsByClientID(ctx, client.ClientID, other.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAnnualJobsByClientID: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("GetAnnualJobsByClientID by other staff listed %d jobs, want 0", len(jobs))
+	}
+}
+
+func testAnnualJobReports(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Tahunan Laporan", "")
+	job := newAnnualJob(ctx, t, repos, client.ClientID, "", 2007)
+
+	tax := &models.AnnualTaxReport{JobID: job.JobID, ReportStatus: "Belum Lapor"}
+	if err := repos.AnnualJobs.CreateAnnualTaxReport(ctx, tax); err != nil {
+		t.Fatalf("CreateAnnualTaxReport: %v", err)
+	}
+	tax.ReportStatus = "Sudah Lapor"
+	tax.PaymentAmount = floatPtr(5000)
+	if err := repos.AnnualJobs.UpdateAnnualTaxReport(ctx, tax); err != nil {
+		t.Fatalf("UpdateAnnualTaxReport: %v", err)
+	}
+	gotTax, err := repos.AnnualJobs.GetAnnualTaxReportByID(ctx, tax.ReportID)
+	if err != nil {
+		t.Fatalf("GetAnnualTaxReportByID: %v", err)
+	}
+	if gotTax.JobID != job.JobID || gotTax.ReportStatus != "Sudah Lapor" || gotTax.PaymentAmount == nil || *gotTax.PaymentAmount != 5000 {
+		t.Errorf("UpdateAnnualTaxReport was not saved: %+v", gotTax)
+	}
+
+	dividend := &models.AnnualDividendReport{JobID: job.JobID, ReportStatus: "Belum Lapor"}
+	if err := repos.AnnualJobs.CreateAnnualDividendReport(ctx, dividend); err != nil {
+		t.Fatalf("CreateAnnualDividendReport: %v", err)
+	}
+	dividend.IsReported = true
+	if err := repos.AnnualJobs.UpdateAnnualDividendReport(ctx, dividend); err != nil {
+		t.Fatalf("UpdateAnnualDividendReport: %v", err)
+	}
+	gotDividend, err := repos.AnnualJobs.GetAnnualDividendReportByID(ctx, dividend.ReportID)
+	if err != nil {
+		t.Fatalf("GetAnnualDividendReportByID: %v", err)
+	}
+	if !gotDividend.IsReported {
+		t.Errorf("UpdateAnnualDividendReport was not saved")
+	}
+
+	if err := repos.AnnualJobs.DeleteAnnualTaxReport(ctx, tax.ReportID); err != nil {
+		t.Fatalf("DeleteAnnualTaxReport: %v", err)
+	}
+	if err := repos.AnnualJobs.DeleteAnnualDividendReport(ctx, dividend.ReportID); err != nil {
+		t.Fatalf("DeleteAnnualDividendReport: %v", err)
+	}
+	if _, err := repos.AnnualJobs.GetAnnualTaxReportByID(ctx, tax.ReportID); !isNotFound(err) {
+		t.Errorf("GetAnnualTaxReportByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.AnnualJobs.GetAnnualDividendReportByID(ctx, dividend.ReportID); !isNotFound(err) {
+		t.Errorf("GetAnnualDividendReportByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	got, err := repos.AnnualJobs.GetAnnualJobByID(ctx, job.JobID, "", true)
+	if err != nil {
+		t.Fatalf("GetAnnualJobByID: %v", err)
+	}
+	if len(got.TaxReports) != 0 || len(got.DividendReports) != 0 {
+		t.Errorf("job still has %d tax and %d dividend reports after delete", len(got.TaxReports), len(got.DividendReports))
+	}
+}
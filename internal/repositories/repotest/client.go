@@ -0,0 +1,318 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+func testClientCreateAndGet(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Create", staff.StaffID)
+	if client.ClientID == "" || client.CreatedAt.IsZero() {
+		t.Fatalf("CreateClient did not set the ID and timestamps: %+v", client)
+	}
+	if !utils.CheckPasswordHash("coretax-rahasia", client.CoretaxPasswordHashed) {
+		t.Errorf("CreateClient did not hash the Coretax password")
+	}
+
+	got, err := repos.Clients.GetClientByID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetClientByID: %v", err)
+	}
+	if got.ClientName != client.ClientName || got.NpwpClient != client.NpwpClient || got.NpwpCanonical != client.NpwpCanonical {
+		t.Errorf("GetClientByID = %q %q %q, want %q %q %q", got.ClientName, got.NpwpClient, got.NpwpCanonical,
+			client.ClientName, client.NpwpClient, client.NpwpCanonical)
+	}
+	if got.PicStaffSigmaID != staff.StaffID || got.PicStaffSigmaName != staff.Nama {
+		t.Errorf("GetClientByID PIC = %q %q, want %q %q", got.PicStaffSigmaID, got.PicStaffSigmaName, staff.StaffID, staff.Nama)
+	}
+	if !got.Ppn || got.Pph21 || got.ClientCategory != "Badan" {
+		t.Errorf("GetClientByID did not keep the services and category: %+v", got)
+	}
+
+	missing, _ := utils.NewUUID()
+	if _, err := repos.Clients.GetClientByID(ctx, missing, "", true); !isNotFound(err) {
+		t.Errorf("GetClientByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testClientVisibility(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Visibility", pic.StaffID)
+	unassigned := newClient(ctx, t, repos, "Unassigned", "")
+
+	if _, err := repos.Clients.GetClientByID(ctx, client.ClientID, pic.StaffID, false); err != nil {
+		t.Errorf("GetClientByID by PIC: %v", err)
+	}
+	if _, err := repos.Clients.GetClientByID(ctx, client.ClientID, other.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetClientByID by other staff error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.Clients.GetClientByID(ctx, client.ClientID, other.StaffID, true); err != nil {
+		t.Errorf("GetClientByID by admin: %v", err)
+	}
+
+	clients, err := repos.Clients.GetAllClients(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllClients: %v", err)
+	}
+	if !containsClient(clients, client.ClientID) || containsClient(clients, unassigned.ClientID) {
+		t.Errorf("GetAllClients by PIC must list only the clients of the PIC")
+	}
+	for _, c := range clients {
+		if c.PicStaffSigmaID != pic.StaffID {
+			t.Errorf("GetAllClients by PIC listed client %s of PIC %q", c.ClientID, c.PicStaffSigmaID)
+		}
+	}
+	clients, err = repos.Clients.GetAllClients(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllClients: %v", err)
+	}
+	if !containsClient(clients, client.ClientID) || !containsClient(clients, unassigned.ClientID) {
+		t.Errorf("GetAllClients by admin must list every client")
+	}
+}
+
+func testClientUniqueNpwp(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Unique", "")
+
+	dup := newClientValue(client.ClientName+" Dup", "")
+	dup.NpwpClient, dup.NpwpCanonical = client.NpwpClient, client.NpwpCanonical
+	err := repos.Clients.CreateClient(ctx, dup)
+	if err == nil {
+		t.Cleanup(func() { repos.Clients.DeleteClient(ctx, dup.ClientID) })
+	}
+	if !repositories.IsDuplicate(err) {
+		t.Errorf("CreateClient with an existing NPWP error = %v, want a duplicate error", err)
+	}
+
+	// Bentuk tampilan berbeda, kanonik sama: tetap dianggap NPWP yang sama
+	dup = newClientValue(client.ClientName+" Dup", "")
+	dup.NpwpCanonical = client.NpwpCanonical
+	err = repos.Clients.CreateClient(ctx, dup)
+	if err == nil {
+		t.Cleanup(func() { repos.Clients.DeleteClient(ctx, dup.ClientID) })
+	}
+	if !repositories.IsDuplicate(err) {
+		t.Errorf("CreateClient with an existing canonical NPWP error = %v, want a duplicate error", err)
+	}
+}
+
+func testClientOrder(ctx context.Context, t *testing.T, repos Repositories) {
+	prefix := "Repotest Urut " + uniqueSuffix(t)
+	var ids []string
+	for _, name := range []string{"C", "A", "B"} {
+		client := newClientValue(prefix+" "+name, "")
+		createClient(ctx, t, repos, client)
+		ids = append(ids, client.ClientID)
+	}
+	want := []string{ids[1], ids[2], ids[0]}
+
+	clients, err := repos.Clients.GetAllClients(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllClients: %v", err)
+	}
+	if got := clientIDsWithPrefix(clients, prefix); !equalStrings(got, want) {
+		t.Errorf("GetAllClients order = %v, want %v (by name)", got, want)
+	}
+	clients, err = repos.Clients.SearchClients(ctx, prefix, "", true)
+	if err != nil {
+		t.Fatalf("SearchClients: %v", err)
+	}
+	if got := clientIDsWithPrefix(clients, prefix); !equalStrings(got, want) {
+		t.Errorf("SearchClients order = %v, want %v (by name)", got, want)
+	}
+}
+
+func testClientSearch(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Cari", pic.StaffID)
+	n, err := npwp.Parse(client.NpwpCanonical)
+	if err != nil {
+		t.Fatalf("parse fixture NPWP: %v", err)
+	}
+	legacy, _ := n.Legacy()
+
+	for _, query := range []string{
+		client.NpwpClient,
+		legacy,
+		client.NpwpCanonical,
+		strings.ToLower(client.ClientName),
+		legacy[3:11],
+	} {
+		clients, err := repos.Clients.SearchClients(ctx, query, "", true)
+		if err != nil {
+			t.Fatalf("SearchClients(%q): %v", query, err)
+		}
+		if !containsClient(clients, client.ClientID) {
+			t.Errorf("SearchClients(%q) did not find the client", query)
+		}
+	}
+
+	clients, err := repos.Clients.SearchClients(ctx, client.NpwpClient, other.StaffID, false)
+	if err != nil {
+		t.Fatalf("SearchClients: %v", err)
+	}
+	if containsClient(clients, client.ClientID) {
+		t.Errorf("SearchClients by other staff found a client of another PIC")
+	}
+
+	clients, err = repos.Clients.GetClientsByNpwp(ctx, []string{client.NpwpCanonical, randomNpwp().Canonical()})
+	if err != nil {
+		t.Fatalf("GetClientsByNpwp: %v", err)
+	}
+	if len(clients) != 1 || clients[0].ClientID != client.ClientID {
+		t.Errorf("GetClientsByNpwp returned %d clients, want only the fixture client", len(clients))
+	}
+}
+
+func testClientUpdate(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Update", "")
+	other := newClient(ctx, t, repos, "Update Other", "")
+
+	client.ClientName += " Baru"
+	client.PicStaffSigmaID = pic.StaffID
+	client.Pph21 = true
+	if err := repos.Clients.UpdateClient(ctx, client); err != nil {
+		t.Fatalf("UpdateClient: %v", err)
+	}
+	got, err := repos.Clients.GetClientByID(ctx, client.ClientID, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetClientByID after update: %v", err)
+	}
+	if got.ClientName != client.ClientName || got.PicStaffSigmaName != pic.Nama || !got.Pph21 {
+		t.Errorf("UpdateClient was not saved: %+v", got)
+	}
+
+	client.NpwpClient, client.NpwpCanonical = other.NpwpClient, other.NpwpCanonical
+	if err := repos.Clients.UpdateClient(ctx, client); !repositories.IsDuplicate(err) {
+		t.Errorf("UpdateClient to an existing NPWP error = %v, want a duplicate error", err)
+	}
+}
+
+func testClientImport(ctx context.Context, t *testing.T, repos Repositories) {
+	existing := newClient(ctx, t, repos, "Import", "")
+	suffix := uniqueSuffix(t)
+
+	// Baris kedua bentrok dengan klien yang sudah ada: tidak ada yang boleh tersimpan
+	fresh := newClientValue("Repotest Import Baru "+suffix, "")
+	clash := newClientValue("Repotest Import Bentrok "+suffix, "")
+	clash.NpwpClient, clash.NpwpCanonical = existing.NpwpClient, existing.NpwpCanonical
+	renamed := *existing
+	renamed.ClientName += " Diubah"
+	err := repos.Clients.ImportClients(ctx, []*models.Client{fresh, clash}, []*models.Client{&renamed})
+	if !repositories.IsDuplicate(err) {
+		t.Errorf("ImportClients with a clashing NPWP error = %v, want a duplicate error", err)
+	}
+	if err == nil {
+		t.Cleanup(func() {
+			repos.Clients.DeleteClient(ctx, fresh.ClientID)
+			repos.Clients.DeleteClient(ctx, clash.ClientID)
+		})
+	}
+	if clients, err := repos.Clients.GetClientsByNpwp(ctx, []string{fresh.NpwpCanonical}); err != nil || len(clients) != 0 {
+		t.Errorf("failed ImportClients still created a client (%d, %v)", len(clients), err)
+	}
+	if got, err := repos.Clients.GetClientByID(ctx, existing.ClientID, "", true); err != nil || got.ClientName != existing.ClientName {
+		t.Errorf("failed ImportClients still updated a client")
+	}
+
+	fresh = newClientValue("Repotest Import Baru "+suffix, "")
+	err = repos.Clients.ImportClients(ctx, []*models.Client{fresh}, []*models.Client{&renamed})
+	if err != nil {
+		t.Fatalf("ImportClients: %v", err)
+	}
+	t.Cleanup(func() { repos.Clients.DeleteClient(ctx, fresh.ClientID) })
+	if fresh.ClientID == "" {
+		t.Fatalf("ImportClients did not set the ID of the created client")
+	}
+	if _, err := repos.Clients.GetClientByID(ctx, fresh.ClientID, "", true); err != nil {
+		t.Errorf("GetClientByID of the imported client: %v", err)
+	}
+	if got, err := repos.Clients.GetClientByID(ctx, existing.ClientID, "", true); err != nil || got.ClientName != renamed.ClientName {
+		t.Errorf("ImportClients did not update the existing client")
+	}
+}
+
+func testClientDeleteCascade(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Delete", pic.StaffID)
+	monthly := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 1, JobYear: 2001, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, monthly); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	annual := &models.AnnualJob{ClientID: client.ClientID, JobYear: 2001, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.AnnualJobs.CreateAnnualJob(ctx, annual); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+	sp2dk := &models.Sp2dkJob{ClientID: client.ClientID, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.Sp2dkJobs.CreateSp2dkJob(ctx, sp2dk); err != nil {
+		t.Fatalf("CreateSp2dkJob: %v", err)
+	}
+	invoice := newInvoice(ctx, t, repos, client.ClientID, nil, randomDate(), 100000)
+
+	if err := repos.Clients.DeleteClient(ctx, client.ClientID); err != nil {
+		t.Fatalf("DeleteClient: %v", err)
+	}
+	if _, err := repos.Clients.GetClientByID(ctx, client.ClientID, "", true); !isNotFound(err) {
+		t.Errorf("GetClientByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, monthly.JobID, "", true); !isNotFound(err) {
+		t.Errorf("monthly job of a deleted client: error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.AnnualJobs.GetAnnualJobByID(ctx, annual.JobID, "", true); !isNotFound(err) {
+		t.Errorf("annual job of a deleted client: error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.Sp2dkJobs.GetSp2dkJobByID(ctx, sp2dk.JobID, "", true); !isNotFound(err) {
+		t.Errorf("SP2DK job of a deleted client: error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, "", true); !isNotFound(err) {
+		t.Errorf("invoice of a deleted client: error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func containsClient(clients []models.Client, id string) bool {
+	for _, c := range clients {
+		if c.ClientID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIDsWithPrefix returns, in order, the IDs of the clients whose name starts with prefix
+func clientIDsWithPrefix(clients []models.Client, prefix string) []string {
+	var ids []string
+	for _, c := range clients {
+		if strings.HasPrefix(c.ClientName, prefix) {
+			ids = append(ids, c.ClientID)
+		}
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// recent reports whether ts was set by the repository during this run
+func recent(ts time.Time) bool {
+	return !ts.IsZero() && time.Since(ts) < time.Hour
+}
@@ -0,0 +1,114 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+var errStop = errors.New("stop")
+
+// checkEach compares what each passes to fn with list, the result of the matching GetAll*
+// method, then checks that an error from fn ends the iteration and is returned as is.
+func checkEach[T any](t *testing.T, name string, list []T, each func(fn func(*T) error) error) {
+	t.Helper()
+	var got []T
+	if err := each(func(v *T) error { got = append(got, *v); return nil }); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if len(got) == 0 || !reflect.DeepEqual(got, list) {
+		t.Errorf("%s passed %d elements, want the %d of the list in the same order:\n got %+v\nwant %+v", name, len(got), len(list), got, list)
+	}
+
+	calls := 0
+	err := each(func(*T) error { calls++; return errStop })
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("%s after fn failed: %d calls, error %v; want 1 call and the error of fn", name, calls, err)
+	}
+}
+
+// Setiap data dibuat dengan PIC baru, jadi daftar non-admin hanya berisi data case ini.
+func testEachMatchesGetAll(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Stream", pic.StaffID)
+	second := newClient(ctx, t, repos, "Stream Dua", pic.StaffID)
+	newMonthlyJob(ctx, t, repos, client.ClientID, pic.StaffID, 3, 2001, models.TaxTypePpn, models.TaxTypePph21)
+	newMonthlyJob(ctx, t, repos, second.ClientID, pic.StaffID, 3, 2001)
+	newMonthlyJob(ctx, t, repos, client.ClientID, pic.StaffID, 4, 2001, models.TaxTypePph25)
+	annual := &models.AnnualJob{ClientID: client.ClientID, JobYear: 2001, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan",
+		TaxReports: []models.AnnualTaxReport{{ReportStatus: "Belum Lapor"}}}
+	if err := repos.AnnualJobs.CreateAnnualJob(ctx, annual); err != nil {
+		t.Fatalf("CreateAnnualJob: %v", err)
+	}
+	newAnnualJob(ctx, t, repos, second.ClientID, pic.StaffID, 2001)
+	for _, clientID := range []string{client.ClientID, second.ClientID} {
+		if err := repos.Sp2dkJobs.CreateSp2dkJob(ctx, &models.Sp2dkJob{ClientID: clientID, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}); err != nil {
+			t.Fatalf("CreateSp2dkJob: %v", err)
+		}
+		if err := repos.PemeriksaanJobs.CreatePemeriksaanJob(ctx, &models.PemeriksaanJob{ClientID: clientID, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}); err != nil {
+			t.Fatalf("CreatePemeriksaanJob: %v", err)
+		}
+	}
+	invoiceDate := time.Date(2001, 5, 2, 0, 0, 0, 0, time.UTC)
+	newInvoice(ctx, t, repos, client.ClientID, &pic.StaffID, invoiceDate, 100000, 50000)
+	newInvoice(ctx, t, repos, second.ClientID, &pic.StaffID, invoiceDate)
+
+	clients, err := repos.Clients.GetAllClients(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllClients: %v", err)
+	}
+	checkEach(t, "EachClient", clients, func(fn func(*models.Client) error) error {
+		return repos.Clients.EachClient(ctx, "", pic.StaffID, false, fn)
+	})
+	found, err := repos.Clients.SearchClients(ctx, "stream dua", pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("SearchClients: %v", err)
+	}
+	checkEach(t, "EachClient with query", found, func(fn func(*models.Client) error) error {
+		return repos.Clients.EachClient(ctx, "stream dua", pic.StaffID, false, fn)
+	})
+
+	monthly, err := repos.MonthlyJobs.GetAllMonthlyJobs(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllMonthlyJobs: %v", err)
+	}
+	checkEach(t, "EachMonthlyJob", monthly, func(fn func(*models.MonthlyJob) error) error {
+		return repos.MonthlyJobs.EachMonthlyJob(ctx, pic.StaffID, false, fn)
+	})
+
+	annuals, err := repos.AnnualJobs.GetAllAnnualJobs(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllAnnualJobs: %v", err)
+	}
+	checkEach(t, "EachAnnualJob", annuals, func(fn func(*models.AnnualJob) error) error {
+		return repos.AnnualJobs.EachAnnualJob(ctx, pic.StaffID, false, fn)
+	})
+
+	sp2dk, err := repos.Sp2dkJobs.GetAllSp2dkJobs(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllSp2dkJobs: %v", err)
+	}
+	checkEach(t, "EachSp2dkJob", sp2dk, func(fn func(*models.Sp2dkJob) error) error {
+		return repos.Sp2dkJobs.EachSp2dkJob(ctx, pic.StaffID, false, fn)
+	})
+
+	pemeriksaan, err := repos.PemeriksaanJobs.GetAllPemeriksaanJobs(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllPemeriksaanJobs: %v", err)
+	}
+	checkEach(t, "EachPemeriksaanJob", pemeriksaan, func(fn func(*models.PemeriksaanJob) error) error {
+		return repos.PemeriksaanJobs.EachPemeriksaanJob(ctx, pic.StaffID, false, fn)
+	})
+
+	invoices, err := repos.Invoices.GetAllInvoices(ctx, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllInvoices: %v", err)
+	}
+	checkEach(t, "EachInvoice", invoices, func(fn func(*models.Invoice) error) error {
+		return repos.Invoices.EachInvoice(ctx, pic.StaffID, false, fn)
+	})
+}
@@ -0,0 +1,140 @@
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// fixturePassword memenuhi kebijakan password supaya fixture staf juga bisa dipakai untuk login
+const fixturePassword = "Rahasia-123!"
+
+// uniqueSuffix membedakan data satu run dari data lain di database yang sama
+func uniqueSuffix(t *testing.T) string {
+	t.Helper()
+	id, err := utils.NewUUID()
+	if err != nil {
+		t.Fatalf("generate suffix: %v", err)
+	}
+	return strings.ToUpper(id[:8])
+}
+
+// randomNpwp returns a valid 15-digit NPWP; the KPP and taxpayer digits are never all zero
+func randomNpwp() npwp.Number {
+	for {
+		var b strings.Builder
+		for i := 0; i < 15; i++ {
+			b.WriteByte(byte('0' + rand.Intn(10)))
+		}
+		if n, err := npwp.Parse(b.String()); err == nil {
+			return n
+		}
+	}
+}
+
+// randomDate returns a date far in the past, so invoice numbers of the case do not continue
+// the sequence of real invoices
+func randomDate() time.Time {
+	return time.Date(1990+rand.Intn(10), time.Month(1+rand.Intn(12)), 1+rand.Intn(28), 0, 0, 0, 0, time.UTC)
+}
+
+// newStaff creates a staff member with a unique email; it is deleted when the case ends
+func newStaff(ctx context.Context, t *testing.T, repos Repositories, role string) *models.Staff {
+	t.Helper()
+	suffix := uniqueSuffix(t)
+	staff := &models.Staff{
+		Nama:           "Repotest " + suffix,
+		Email:          "repotest-" + strings.ToLower(suffix) + "@example.com",
+		PasswordHashed: fixturePassword,
+		Role:           role,
+	}
+	if err := repos.Staffs.CreateStaff(ctx, staff); err != nil {
+		t.Fatalf("create staff: %v", err)
+	}
+	t.Cleanup(func() { repos.Staffs.DeleteStaff(ctx, staff.StaffID) })
+	return staff
+}
+
+// newClientValue returns an unsaved client with a random NPWP
+func newClientValue(name, picStaffID string) *models.Client {
+	n := randomNpwp()
+	return &models.Client{
+		ClientName:            name,
+		NpwpClient:            n.Format(),
+		NpwpCanonical:         n.Canonical(),
+		MembershipStatus:      "Aktif",
+		CoretaxPasswordHashed: "coretax-rahasia",
+		PicStaffSigmaID:       picStaffID,
+		ClientCategory:        "Badan",
+		Ppn:                   true,
+	}
+}
+
+// newClient creates a client named after name and a unique suffix; it is deleted, with its
+// jobs and invoices, when the case ends
+func newClient(ctx context.Context, t *testing.T, repos Repositories, name, picStaffID string) *models.Client {
+	t.Helper()
+	client := newClientValue(fmt.Sprintf("Repotest %s %s", name, uniqueSuffix(t)), picStaffID)
+	createClient(ctx, t, repos, client)
+	return client
+}
+
+// createClient saves client and deletes it when the case ends
+func createClient(ctx context.Context, t *testing.T, repos Repositories, client *models.Client) {
+	t.Helper()
+	if err := repos.Clients.CreateClient(ctx, client); err != nil {
+		t.Fatalf("create client %s: %v", client.ClientName, err)
+	}
+	t.Cleanup(func() { repos.Clients.DeleteClient(ctx, client.ClientID) })
+}
+
+// newInvoice creates an invoice for client with one line item per amount
+func newInvoice(ctx context.Context, t *testing.T, repos Repositories, clientID string, assignedStaffID *string, invoiceDate time.Time, amounts ...float64) *models.Invoice {
+	t.Helper()
+	invoice := &models.Invoice{
+		ClientID:        clientID,
+		AssignedStaffID: assignedStaffID,
+		InvoiceDate:     models.CustomDate{Time: invoiceDate},
+		DueDate:         models.CustomDate{Time: invoiceDate.AddDate(0, 0, 14)},
+		Status:          models.InvoiceStatusDraft,
+	}
+	for i, amount := range amounts {
+		invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{
+			Description: fmt.Sprintf("Jasa %d", i+1),
+			Quantity:    1,
+			UnitPrice:   amount,
+			Amount:      amount,
+		})
+	}
+	if err := repos.Invoices.CreateInvoice(ctx, invoice); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+	t.Cleanup(func() { repos.Invoices.DeleteInvoice(ctx, invoice.InvoiceID) })
+	return invoice
+}
+
+// floatPtr and stringPtr build the pointer fields of the models
+func floatPtr(v float64) *float64 { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+// sameDay compares DATE values; the time of day is not stored
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// isNotFound reports whether err is the sql.ErrNoRows returned for a missing or hidden row
+func isNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
@@ -0,0 +1,215 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+func testInvoiceCreate(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Invoice", staff.StaffID)
+	date := randomDate()
+	first := newInvoice(ctx, t, repos, client.ClientID, &staff.StaffID, date, 100000, 250000)
+	second := newInvoice(ctx, t, repos, client.ClientID, nil, date, 50000)
+	later := newInvoice(ctx, t, repos, client.ClientID, nil, date.AddDate(0, 0, 1), 75000)
+
+	// Nomor urut melanjutkan invoice lain pada tanggal yang sama, dan mulai lagi pada tanggal lain
+	prefix := "INV/" + date.Format("20060102") + "/"
+	var seq int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(first.InvoiceNumber, prefix), "%d", &seq); err != nil || !strings.HasPrefix(first.InvoiceNumber, prefix) {
+		t.Fatalf("invoice number %q does not start with %q", first.InvoiceNumber, prefix)
+	}
+	if want := fmt.Sprintf("%s%03d", prefix, seq+1); second.InvoiceNumber != want {
+		t.Errorf("second invoice number on the same date = %q, want %q", second.InvoiceNumber, want)
+	}
+	if !strings.HasPrefix(later.InvoiceNumber, "INV/"+date.AddDate(0, 0, 1).Format("20060102")+"/") {
+		t.Errorf("invoice number %q does not use the invoice date", later.InvoiceNumber)
+	}
+	if first.TotalAmount != 350000 {
+		t.Errorf("TotalAmount = %v, want the sum of the line items 350000", first.TotalAmount)
+	}
+
+	got, err := repos.Invoices.GetInvoiceByID(ctx, first.InvoiceID, "", true)
+	if err != nil {
+		t.Fatalf("GetInvoiceByID: %v", err)
+	}
+	if got.InvoiceNumber != first.InvoiceNumber || got.TotalAmount != 350000 || got.Status != models.InvoiceStatusDraft {
+		t.Errorf("GetInvoiceByID = %+v", got)
+	}
+	if got.ClientName != client.ClientName || got.NpwpClient != client.NpwpClient || got.AssignedStaffName == nil || *got.AssignedStaffName != staff.Nama {
+		t.Errorf("GetInvoiceByID did not join the client and staff names")
+	}
+	if !sameDay(got.InvoiceDate.Time, date) || !sameDay(got.DueDate.Time, date.AddDate(0, 0, 14)) {
+		t.Errorf("GetInvoiceByID dates = %v %v", got.InvoiceDate.Time, got.DueDate.Time)
+	}
+	if len(got.LineItems) != 2 {
+		t.Fatalf("GetInvoiceByID has %d line items, want 2", len(got.LineItems))
+	}
+	for _, item := range got.LineItems {
+		if item.LineItemID == "" || item.InvoiceID != first.InvoiceID || item.RelatedJobID != nil {
+			t.Errorf("line item = %+v", item)
+		}
+	}
+
+	all, err := repos.Invoices.GetAllInvoices(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllInvoices: %v", err)
+	}
+	var ids []string
+	for _, invoice := range all {
+		if invoice.ClientID == client.ClientID {
+			ids = append(ids, invoice.InvoiceID)
+			if invoice.InvoiceID == first.InvoiceID && len(invoice.LineItems) != 2 {
+				t.Errorf("GetAllInvoices listed %d line items of the first invoice, want 2", len(invoice.LineItems))
+			}
+		}
+	}
+	if want := []string{later.InvoiceID, second.InvoiceID, first.InvoiceID}; !equalStrings(ids, want) {
+		t.Errorf("GetAllInvoices = %v, want %v (newest date, then highest number first)", ids, want)
+	}
+
+	got.Status = models.InvoiceStatusIssued
+	got.Notes = stringPtr("Termin pertama")
+	if err := repos.Invoices.UpdateInvoice(ctx, got); err != nil {
+		t.Fatalf("UpdateInvoice: %v", err)
+	}
+	updated, err := repos.Invoices.GetInvoiceByID(ctx, first.InvoiceID, "", true)
+	if err != nil {
+		t.Fatalf("GetInvoiceByID after update: %v", err)
+	}
+	if updated.Status != models.InvoiceStatusIssued || updated.Notes == nil || *updated.Notes != "Termin pertama" || len(updated.LineItems) != 2 {
+		t.Errorf("UpdateInvoice was not saved: %+v", updated)
+	}
+
+	if err := repos.Invoices.DeleteInvoice(ctx, second.InvoiceID); err != nil {
+		t.Fatalf("DeleteInvoice: %v", err)
+	}
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, second.InvoiceID, "", true); !isNotFound(err) {
+		t.Errorf("GetInvoiceByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	missing, _ := utils.NewUUID()
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, missing, "", true); !isNotFound(err) {
+		t.Errorf("GetInvoiceByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testInvoiceSourceEvent(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Invoice Event", "")
+	eventID, err := utils.NewUUID()
+	if err != nil {
+		t.Fatalf("generate event ID: %v", err)
+	}
+	date := randomDate()
+
+	invoice := &models.Invoice{ClientID: client.ClientID, InvoiceDate: models.CustomDate{Time: date}, DueDate: models.CustomDate{Time: date},
+		Status: models.InvoiceStatusDraft, SourceEventID: &eventID}
+	if err := repos.Invoices.CreateInvoice(ctx, invoice); err != nil {
+		t.Fatalf("CreateInvoice: %v", err)
+	}
+	t.Cleanup(func() { repos.Invoices.DeleteInvoice(ctx, invoice.InvoiceID) })
+
+	// Event yang dikirim ulang tidak boleh menghasilkan invoice kedua
+	again := &models.Invoice{ClientID: client.ClientID, InvoiceDate: models.CustomDate{Time: date}, DueDate: models.CustomDate{Time: date},
+		Status: models.InvoiceStatusDraft, SourceEventID: &eventID}
+	err = repos.Invoices.CreateInvoice(ctx, again)
+	if err == nil {
+		t.Cleanup(func() { repos.Invoices.DeleteInvoice(ctx, again.InvoiceID) })
+	}
+	if err != repositories.ErrInvoiceAlreadyIssued {
+		t.Errorf("CreateInvoice for the same source event error = %v, want ErrInvoiceAlreadyIssued", err)
+	}
+	if invoice.TotalAmount != 0 {
+		t.Errorf("TotalAmount of an invoice without line items = %v, want 0", invoice.TotalAmount)
+	}
+}
+
+func testInvoiceVisibility(ctx context.Context, t *testing.T, repos Repositories) {
+	assigned := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	// Visibilitas invoice mengikuti staf yang ditugaskan, bukan PIC klien
+	client := newClient(ctx, t, repos, "Invoice Visibilitas", other.StaffID)
+	invoice := newInvoice(ctx, t, repos, client.ClientID, &assigned.StaffID, randomDate(), 10000)
+	unassigned := newInvoice(ctx, t, repos, client.ClientID, nil, randomDate(), 10000)
+
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, assigned.StaffID, false); err != nil {
+		t.Errorf("GetInvoiceByID by assigned staff: %v", err)
+	}
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, other.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetInvoiceByID by other staff error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.Invoices.GetInvoiceByID(ctx, unassigned.InvoiceID, assigned.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetInvoiceByID of an unassigned invoice by staff error = %v, want sql.ErrNoRows", err)
+	}
+
+	invoices, err := repos.Invoices.GetAllInvoices(ctx, assigned.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllInvoices: %v", err)
+	}
+	for _, i := range invoices {
+		if i.AssignedStaffID == nil || *i.AssignedStaffID != assigned.StaffID {
+			t.Errorf("GetAllInvoices by staff listed invoice %s of another staff member", i.InvoiceNumber)
+		}
+	}
+	if len(invoices) != 1 || invoices[0].InvoiceID != invoice.InvoiceID {
+		t.Errorf("GetAllInvoices by assigned staff listed %d invoices, want 1", len(invoices))
+	}
+}
+
+func testInvoiceLineItems(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Invoice Item", "")
+	invoice := newInvoice(ctx, t, repos, client.ClientID, nil, randomDate(), 100000)
+
+	// Amount dihitung ulang dari quantity dan unit price; total invoice diurus pemanggil
+	item := &models.InvoiceLineItem{InvoiceID: invoice.InvoiceID, Description: "Jasa SPT Tahunan", Quantity: 2, UnitPrice: 150000,
+		RelatedJobType: stringPtr("annual"), RelatedJobID: stringPtr(invoice.InvoiceID)}
+	if err := repos.Invoices.CreateInvoiceLineItem(ctx, item); err != nil {
+		t.Fatalf("CreateInvoiceLineItem: %v", err)
+	}
+	if item.LineItemID == "" || item.Amount != 300000 {
+		t.Errorf("CreateInvoiceLineItem set ID %q and amount %v, want an ID and 300000", item.LineItemID, item.Amount)
+	}
+
+	item.Description = "Jasa SPT Tahunan Badan"
+	item.Quantity = 3
+	item.Amount = 450000
+	item.RelatedJobType = nil
+	item.RelatedJobID = nil
+	if err := repos.Invoices.UpdateInvoiceLineItem(ctx, item); err != nil {
+		t.Fatalf("UpdateInvoiceLineItem: %v", err)
+	}
+	got, err := repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, "", true)
+	if err != nil {
+		t.Fatalf("GetInvoiceByID: %v", err)
+	}
+	if len(got.LineItems) != 2 {
+		t.Fatalf("invoice has %d line items, want 2", len(got.LineItems))
+	}
+	for _, li := range got.LineItems {
+		if li.LineItemID != item.LineItemID {
+			continue
+		}
+		if li.Description != item.Description || li.Quantity != 3 || li.Amount != 450000 || li.RelatedJobType != nil {
+			t.Errorf("UpdateInvoiceLineItem was not saved: %+v", li)
+		}
+	}
+	if got.TotalAmount != 100000 {
+		t.Errorf("TotalAmount = %v, want 100000: line item changes do not update the total", got.TotalAmount)
+	}
+
+	if err := repos.Invoices.DeleteInvoiceLineItem(ctx, item.LineItemID); err != nil {
+		t.Fatalf("DeleteInvoiceLineItem: %v", err)
+	}
+	got, err = repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, "", true)
+	if err != nil {
+		t.Fatalf("GetInvoiceByID: %v", err)
+	}
+	if len(got.LineItems) != 1 {
+		t.Errorf("invoice has %d line items after delete, want 1", len(got.LineItems))
+	}
+}
@@ -0,0 +1,262 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+func newMonthlyJob(ctx context.Context, t *testing.T, repos Repositories, clientID, picStaffID string, month, year int, taxTypes ...string) *models.MonthlyJob {
+	job := &models.MonthlyJob{
+		ClientID:                clientID,
+		JobMonth:                month,
+		JobYear:                 year,
+		AssignedPicStaffSigmaID: picStaffID,
+		OverallStatus:           "Dikerjakan",
+	}
+	for _, taxType := range taxTypes {
+		job.TaxReports = append(job.TaxReports, models.MonthlyTaxReport{TaxType: taxType, ReportStatus: "Belum Lapor"})
+	}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	return job
+}
+
+func testMonthlyJobCreateAndGet(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Bulanan", staff.StaffID)
+	job := &models.MonthlyJob{
+		ClientID:                client.ClientID,
+		JobMonth:                3,
+		JobYear:                 2002,
+		AssignedPicStaffSigmaID: staff.StaffID,
+		OverallStatus:           "Dikerjakan",
+		TaxReports: []models.MonthlyTaxReport{
+			{TaxType: models.TaxTypePpn, BillingCode: "123456789012345", PaymentAmount: floatPtr(90000), ReportStatus: "Sudah Bayar",
+				TaxPaymentEvidence: models.TaxPaymentEvidence{BillingAmount: floatPtr(100000), Ntpn: "ABCDEF0123456789"}},
+			{TaxType: models.TaxTypePph25, ReportStatus: "Belum Lapor"},
+			{TaxType: models.TaxTypePph21, PaymentAmount: floatPtr(50000), ReportStatus: "Sudah Bayar",
+				TaxPaymentEvidence: models.TaxPaymentEvidence{BillingAmount: floatPtr(50000)}},
+		},
+	}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	if job.JobID == "" || !recent(job.CreatedAt) {
+		t.Fatalf("CreateMonthlyJob did not set the ID and timestamps")
+	}
+	for _, report := range job.TaxReports {
+		if report.ReportID == "" || report.JobID != job.JobID {
+			t.Errorf("CreateMonthlyJob did not set the IDs of report %s", report.TaxType)
+		}
+	}
+
+	got, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobByID: %v", err)
+	}
+	if got.ClientName != client.ClientName || got.NpwpClient != client.NpwpClient || got.AssignedPicStaffSigmaName != staff.Nama {
+		t.Errorf("GetMonthlyJobByID joined %q %q %q", got.ClientName, got.NpwpClient, got.AssignedPicStaffSigmaName)
+	}
+	if got.JobMonth != 3 || got.JobYear != 2002 || got.OverallStatus != "Dikerjakan" || got.ProofOfWorkURL != nil {
+		t.Errorf("GetMonthlyJobByID = %+v", got)
+	}
+	want := []string{models.TaxTypePph21, models.TaxTypePph25, models.TaxTypePpn}
+	if taxTypes := monthlyTaxTypes(got.TaxReports); !equalStrings(taxTypes, want) {
+		t.Fatalf("tax reports = %v, want %v (by tax type)", taxTypes, want)
+	}
+	if got.TaxReports[0].AmountMismatch || got.TaxReports[1].AmountMismatch || !got.TaxReports[2].AmountMismatch {
+		t.Errorf("AmountMismatch must be set only where billing and payment amounts differ")
+	}
+	if got.TaxReports[2].Ntpn != "ABCDEF0123456789" || got.TaxReports[2].BillingCode != "123456789012345" {
+		t.Errorf("payment evidence was not saved: %+v", got.TaxReports[2])
+	}
+
+	// ID yang sudah ditentukan pemanggil dipakai apa adanya (pekerjaan dari jadwal berulang)
+	presetID, _ := utils.NewUUID()
+	preset := &models.MonthlyJob{JobID: presetID, ClientID: client.ClientID, JobMonth: 4, JobYear: 2002, OverallStatus: "Dikerjakan"}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, preset); err != nil {
+		t.Fatalf("CreateMonthlyJob with an ID: %v", err)
+	}
+	if preset.JobID != presetID {
+		t.Errorf("CreateMonthlyJob replaced the given ID %s with %s", presetID, preset.JobID)
+	}
+	again := &models.MonthlyJob{JobID: presetID, ClientID: client.ClientID, JobMonth: 5, JobYear: 2002, OverallStatus: "Dikerjakan"}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, again); !repositories.IsDuplicate(err) {
+		t.Errorf("CreateMonthlyJob with an existing ID error = %v, want a duplicate error", err)
+	}
+
+	missing, _ := utils.NewUUID()
+	if _, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, missing, "", true); !isNotFound(err) {
+		t.Errorf("GetMonthlyJobByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testMonthlyJobUnique(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Bulanan Unik", "")
+	newMonthlyJob(ctx, t, repos, client.ClientID, "", 6, 2002)
+
+	dup := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 6, JobYear: 2002, OverallStatus: "Dikerjakan"}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, dup); !repositories.IsDuplicate(err) {
+		t.Errorf("CreateMonthlyJob for an existing period error = %v, want a duplicate error", err)
+	}
+
+	// Jenis pajak ganda membatalkan seluruh pekerjaan, bukan hanya laporan keduanya
+	twice := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 7, JobYear: 2002, OverallStatus: "Dikerjakan",
+		TaxReports: []models.MonthlyTaxReport{{TaxType: models.TaxTypePpn}, {TaxType: models.TaxTypePpn}}}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, twice); !repositories.IsDuplicate(err) {
+		t.Errorf("CreateMonthlyJob with a tax type twice error = %v, want a duplicate error", err)
+	}
+	jobs, err := repos.MonthlyJobs.GetMonthlyJobsByClientID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobsByClientID: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Errorf("client has %d monthly jobs after the failed creates, want 1", len(jobs))
+	}
+}
+
+func testMonthlyJobVisibility(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Bulanan Visibilitas", other.StaffID)
+	job := newMonthlyJob(ctx, t, repos, client.ClientID, pic.StaffID, 8, 2002)
+
+	// Visibilitas pekerjaan mengikuti PIC pekerjaan, bukan PIC klien
+	if _, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, pic.StaffID, false); err != nil {
+		t.Errorf("GetMonthlyJobByID by PIC: %v", err)
+	}
+	if _, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, other.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetMonthlyJobByID by other staff error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, other.StaffID, true); err != nil {
+		t.Errorf("GetMonthlyJobByID by admin: %v", err)
+	}
+
+	jobs, err := repos.MonthlyJobs.GetAllMonthlyJobs(ctx, other.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllMonthlyJobs: %v", err)
+	}
+	if containsMonthlyJob(jobs, job.JobID) {
+		t.Errorf("GetAllMonthlyJobs by other staff listed a job of another PIC")
+	}
+	jobs, err = repos.MonthlyJobs.GetMonthlyJobsByClientID(ctx, client.ClientID, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobsByClientID: %v", err)
+	}
+	if !containsMonthlyJob(jobs, job.JobID) {
+		t.Errorf("GetMonthlyJobsByClientID by PIC did not list the job")
+	}
+}
+
+func testMonthlyJobList(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Bulanan Daftar", staff.StaffID)
+	older := newMonthlyJob(ctx, t, repos, client.ClientID, staff.StaffID, 12, 2002, models.TaxTypePpn, models.TaxTypePph21)
+	newer := newMonthlyJob(ctx, t, repos, client.ClientID, staff.StaffID, 1, 2003, models.TaxTypePph25)
+	empty := newMonthlyJob(ctx, t, repos, client.ClientID, staff.StaffID, 11, 2002)
+	want := []string{newer.JobID, older.JobID, empty.JobID}
+
+	all, err := repos.MonthlyJobs.GetAllMonthlyJobs(ctx, staff.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllMonthlyJobs: %v", err)
+	}
+	byClient, err := repos.MonthlyJobs.GetMonthlyJobsByClientID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobsByClientID: %v", err)
+	}
+	for name, jobs := range map[string][]models.MonthlyJob{"GetAllMonthlyJobs": all, "GetMonthlyJobsByClientID": byClient} {
+		var ids []string
+		for _, job := range jobs {
+			if job.ClientID == client.ClientID {
+				ids = append(ids, job.JobID)
+			}
+		}
+		// Setiap pekerjaan muncul sekali, lengkap dengan laporannya, urut periode terbaru
+		if !equalStrings(ids, want) {
+			t.Errorf("%s = %v, want %v (newest period first, each job once)", name, ids, want)
+			continue
+		}
+		reports := map[string]int{}
+		for _, job := range jobs {
+			if job.ClientID == client.ClientID {
+				reports[job.JobID] = len(job.TaxReports)
+				if job.AssignedPicStaffSigmaName != staff.Nama || job.ClientName != client.ClientName {
+					t.Errorf("%s did not join the client and PIC names of job %s", name, job.JobID)
+				}
+			}
+		}
+		if reports[older.JobID] != 2 || reports[newer.JobID] != 1 || reports[empty.JobID] != 0 {
+			t.Errorf("%s report counts = %v, want 2, 1 and 0", name, reports)
+		}
+	}
+}
+
+func testMonthlyTaxReports(ctx context.Context, t *testing.T, repos Repositories) {
+	client := newClient(ctx, t, repos, "Bulanan Laporan", "")
+	job := newMonthlyJob(ctx, t, repos, client.ClientID, "", 9, 2002, models.TaxTypePpn)
+
+	report := &models.MonthlyTaxReport{JobID: job.JobID, TaxType: models.TaxTypePph21, ReportStatus: "Belum Lapor",
+		PaymentAmount: floatPtr(10000), TaxPaymentEvidence: models.TaxPaymentEvidence{BillingAmount: floatPtr(10000)}}
+	if err := repos.MonthlyJobs.CreateMonthlyTaxReport(ctx, report); err != nil {
+		t.Fatalf("CreateMonthlyTaxReport: %v", err)
+	}
+	if report.ReportID == "" {
+		t.Fatalf("CreateMonthlyTaxReport did not set the ID")
+	}
+	dup := &models.MonthlyTaxReport{JobID: job.JobID, TaxType: models.TaxTypePph21}
+	if err := repos.MonthlyJobs.CreateMonthlyTaxReport(ctx, dup); !repositories.IsDuplicate(err) {
+		t.Errorf("CreateMonthlyTaxReport for an existing tax type error = %v, want a duplicate error", err)
+	}
+
+	report.PaymentAmount = floatPtr(12000)
+	report.ReportStatus = "Sudah Bayar"
+	if err := repos.MonthlyJobs.UpdateMonthlyTaxReport(ctx, report); err != nil {
+		t.Fatalf("UpdateMonthlyTaxReport: %v", err)
+	}
+	got, err := repos.MonthlyJobs.GetMonthlyTaxReportByID(ctx, report.ReportID)
+	if err != nil {
+		t.Fatalf("GetMonthlyTaxReportByID: %v", err)
+	}
+	if got.JobID != job.JobID || got.ReportStatus != "Sudah Bayar" || got.PaymentAmount == nil || *got.PaymentAmount != 12000 {
+		t.Errorf("UpdateMonthlyTaxReport was not saved: %+v", got)
+	}
+	if !got.AmountMismatch {
+		t.Errorf("AmountMismatch must be recalculated after the payment amount changes")
+	}
+
+	if err := repos.MonthlyJobs.DeleteMonthlyTaxReport(ctx, report.ReportID); err != nil {
+		t.Fatalf("DeleteMonthlyTaxReport: %v", err)
+	}
+	if _, err := repos.MonthlyJobs.GetMonthlyTaxReportByID(ctx, report.ReportID); !isNotFound(err) {
+		t.Errorf("GetMonthlyTaxReportByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	gotJob, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobByID: %v", err)
+	}
+	if taxTypes := monthlyTaxTypes(gotJob.TaxReports); !equalStrings(taxTypes, []string{models.TaxTypePpn}) {
+		t.Errorf("tax reports after delete = %v, want only PPN", taxTypes)
+	}
+}
+
+func monthlyTaxTypes(reports []models.MonthlyTaxReport) []string {
+	taxTypes := []string{}
+	for _, report := range reports {
+		taxTypes = append(taxTypes, report.TaxType)
+	}
+	return taxTypes
+}
+
+func containsMonthlyJob(jobs []models.MonthlyJob, id string) bool {
+	for _, job := range jobs {
+		if job.JobID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// Berbeda dengan jenis pekerjaan lain, GetPemeriksaanJobByID mengembalikan nil, nil untuk
+// pekerjaan yang tidak ada atau tidak terlihat.
+func testPemeriksaanJobs(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Pemeriksaan", pic.StaffID)
+	sp2Date := randomDate()
+	job := &models.PemeriksaanJob{ClientID: client.ClientID, AssignedPicStaffSigmaID: pic.StaffID, ContractNo: "KTR-002",
+		Sp2No: "PRIN-00001/WPJ.04/2024", Sp2Date: &sp2Date, OverallStatus: "Dikerjakan"}
+	if err := repos.PemeriksaanJobs.CreatePemeriksaanJob(ctx, job); err != nil {
+		t.Fatalf("CreatePemeriksaanJob: %v", err)
+	}
+
+	got, err := repos.PemeriksaanJobs.GetPemeriksaanJobByID(ctx, job.JobID, pic.StaffID, false)
+	if err != nil || got == nil {
+		t.Fatalf("GetPemeriksaanJobByID = %v, %v", got, err)
+	}
+	if got.ClientName != client.ClientName || got.AssignedPicStaffSigmaName != pic.Nama || got.Sp2No != job.Sp2No {
+		t.Errorf("GetPemeriksaanJobByID = %+v", got)
+	}
+	if got.Sp2Date == nil || !sameDay(*got.Sp2Date, sp2Date) || got.SkpDate != nil {
+		t.Errorf("GetPemeriksaanJobByID dates = %v %v, want %v and nil", got.Sp2Date, got.SkpDate, sp2Date)
+	}
+	if got, err := repos.PemeriksaanJobs.GetPemeriksaanJobByID(ctx, job.JobID, other.StaffID, false); got != nil || err != nil {
+		t.Errorf("GetPemeriksaanJobByID by other staff = %v, %v, want nil, nil", got, err)
+	}
+
+	skpDate := sp2Date.AddDate(0, 3, 0)
+	got.SkpNo = "SKP-001"
+	got.SkpDate = &skpDate
+	got.OverallStatus = "Selesai"
+	if err := repos.PemeriksaanJobs.UpdatePemeriksaanJob(ctx, got); err != nil {
+		t.Fatalf("UpdatePemeriksaanJob: %v", err)
+	}
+	updated, err := repos.PemeriksaanJobs.GetPemeriksaanJobByID(ctx, job.JobID, "", true)
+	if err != nil || updated == nil {
+		t.Fatalf("GetPemeriksaanJobByID after update = %v, %v", updated, err)
+	}
+	if updated.OverallStatus != "Selesai" || updated.SkpNo != "SKP-001" || updated.SkpDate == nil || !sameDay(*updated.SkpDate, skpDate) {
+		t.Errorf("UpdatePemeriksaanJob was not saved: %+v", updated)
+	}
+
+	jobs, err := repos.PemeriksaanJobs.GetAllPemeriksaanJobs(ctx, other.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetAllPemeriksaanJobs: %v", err)
+	}
+	for _, j := range jobs {
+		if j.JobID == job.JobID {
+			t.Errorf("GetAllPemeriksaanJobs by other staff listed a job of another PIC")
+		}
+	}
+	jobs, err = repos.PemeriksaanJobs.GetPemeriksaanJobsByClientID(ctx, client.ClientID, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetPemeriksaanJobsByClientID: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].JobID != job.JobID {
+		t.Errorf("GetPemeriksaanJobsByClientID by PIC listed %d jobs, want 1", len(jobs))
+	}
+
+	if err := repos.PemeriksaanJobs.DeletePemeriksaanJob(ctx, job.JobID); err != nil {
+		t.Fatalf("DeletePemeriksaanJob: %v", err)
+	}
+	if got, err := repos.PemeriksaanJobs.GetPemeriksaanJobByID(ctx, job.JobID, "", true); got != nil || err != nil {
+		t.Errorf("GetPemeriksaanJobByID after delete = %v, %v, want nil, nil", got, err)
+	}
+}
@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// SP2DK dan Pemeriksaan selalu diberi PIC: repository Postgres memindai PIC langsung ke string.
+func testSp2dkJobs(ctx context.Context, t *testing.T, repos Repositories) {
+	pic := newStaff(ctx, t, repos, "staff")
+	other := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "SP2DK", pic.StaffID)
+	sp2dkDate := randomDate()
+	job := &models.Sp2dkJob{ClientID: client.ClientID, AssignedPicStaffSigmaID: pic.StaffID, ContractNo: "KTR-001",
+		Sp2dkNo: "S-123/WPJ.04/2024", Sp2dkDate: &sp2dkDate, OverallStatus: "Dikerjakan", ProofOfWorkURL: stringPtr("/uploads/bukti.pdf")}
+	if err := repos.Sp2dkJobs.CreateSp2dkJob(ctx, job); err != nil {
+		t.Fatalf("CreateSp2dkJob: %v", err)
+	}
+	second := &models.Sp2dkJob{ClientID: client.ClientID, AssignedPicStaffSigmaID: pic.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.Sp2dkJobs.CreateSp2dkJob(ctx, second); err != nil {
+		t.Fatalf("CreateSp2dkJob: %v", err)
+	}
+
+	got, err := repos.Sp2dkJobs.GetSp2dkJobByID(ctx, job.JobID, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetSp2dkJobByID: %v", err)
+	}
+	if got.ClientName != client.ClientName || got.AssignedPicStaffSigmaName != pic.Nama || got.Sp2dkNo != job.Sp2dkNo {
+		t.Errorf("GetSp2dkJobByID = %+v", got)
+	}
+	if got.Sp2dkDate == nil || !sameDay(*got.Sp2dkDate, sp2dkDate) || got.ContractDate != nil {
+		t.Errorf("GetSp2dkJobByID dates = %v %v, want %v and nil", got.Sp2dkDate, got.ContractDate, sp2dkDate)
+	}
+	// Bukti pekerjaan tidak ikut disimpan saat pekerjaan dibuat
+	if got.ProofOfWorkURL != nil {
+		t.Errorf("CreateSp2dkJob saved the proof of work %q", *got.ProofOfWorkURL)
+	}
+	if _, err := repos.Sp2dkJobs.GetSp2dkJobByID(ctx, job.JobID, other.StaffID, false); !isNotFound(err) {
+		t.Errorf("GetSp2dkJobByID by other staff error = %v, want sql.ErrNoRows", err)
+	}
+
+	got.OverallStatus = "Selesai"
+	got.ProofOfWorkURL = stringPtr("/uploads/bukti.pdf")
+	got.AssignedPicStaffSigmaID = other.StaffID
+	if err := repos.Sp2dkJobs.UpdateSp2dkJob(ctx, got); err != nil {
+		t.Fatalf("UpdateSp2dkJob: %v", err)
+	}
+	updated, err := repos.Sp2dkJobs.GetSp2dkJobByID(ctx, job.JobID, other.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetSp2dkJobByID after update: %v", err)
+	}
+	if updated.OverallStatus != "Selesai" || updated.ProofOfWorkURL == nil || updated.AssignedPicStaffSigmaName != other.Nama {
+		t.Errorf("UpdateSp2dkJob was not saved: %+v", updated)
+	}
+
+	jobs, err := repos.Sp2dkJobs.GetSp2dkJobsByClientID(ctx, client.ClientID, pic.StaffID, false)
+	if err != nil {
+		t.Fatalf("GetSp2dkJobsByClientID: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].JobID != second.JobID {
+		t.Errorf("GetSp2dkJobsByClientID by PIC listed %d jobs, want only the job still assigned to the PIC", len(jobs))
+	}
+	all, err := repos.Sp2dkJobs.GetAllSp2dkJobs(ctx, "", true)
+	if err != nil {
+		t.Fatalf("GetAllSp2dkJobs: %v", err)
+	}
+	var ids []string
+	for _, j := range all {
+		if j.ClientID == client.ClientID {
+			ids = append(ids, j.JobID)
+		}
+	}
+	if want := []string{second.JobID, job.JobID}; !equalStrings(ids, want) {
+		t.Errorf("GetAllSp2dkJobs = %v, want %v (newest first)", ids, want)
+	}
+
+	if err := repos.Sp2dkJobs.DeleteSp2dkJob(ctx, job.JobID); err != nil {
+		t.Fatalf("DeleteSp2dkJob: %v", err)
+	}
+	if _, err := repos.Sp2dkJobs.GetSp2dkJobByID(ctx, job.JobID, "", true); !isNotFound(err) {
+		t.Errorf("GetSp2dkJobByID after delete error = %v, want sql.ErrNoRows", err)
+	}
+	missing, _ := utils.NewUUID()
+	if err := repos.Sp2dkJobs.DeleteSp2dkJob(ctx, missing); err != nil {
+		t.Errorf("DeleteSp2dkJob(missing): %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+func testStaffCreateAndGet(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	if staff.StaffID == "" || staff.NIP == "" || !recent(staff.CreatedAt) {
+		t.Fatalf("CreateStaff did not set the ID, NIP and timestamps: %+v", staff)
+	}
+	if !utils.CheckPasswordHash(fixturePassword, staff.PasswordHashed) {
+		t.Errorf("CreateStaff did not hash the password")
+	}
+
+	got, err := repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || got == nil {
+		t.Fatalf("GetStaffByID = %v, %v", got, err)
+	}
+	if got.Email != staff.Email || got.Nama != staff.Nama || got.NIP != staff.NIP || got.Role != "staff" {
+		t.Errorf("GetStaffByID = %+v, want %+v", got, staff)
+	}
+	if got.PasswordChangedAt == nil || got.LockedUntil != nil || got.FailedLoginAttempts != 0 {
+		t.Errorf("new staff must have a password change time and no lockout: %+v", got)
+	}
+
+	got, err = repos.Staffs.GetStaffByEmail(ctx, staff.Email)
+	if err != nil || got == nil || got.StaffID != staff.StaffID {
+		t.Errorf("GetStaffByEmail = %v, %v", got, err)
+	}
+
+	// Staf yang tidak ada dikembalikan sebagai nil, nil (bukan sql.ErrNoRows)
+	missing, _ := utils.NewUUID()
+	if got, err := repos.Staffs.GetStaffByID(ctx, missing); got != nil || err != nil {
+		t.Errorf("GetStaffByID(missing) = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := repos.Staffs.GetStaffByEmail(ctx, "tidak-ada-"+strings.ToLower(uniqueSuffix(t))+"@example.com"); got != nil || err != nil {
+		t.Errorf("GetStaffByEmail(missing) = %v, %v, want nil, nil", got, err)
+	}
+
+	staffs, err := repos.Staffs.GetAllStaffs(ctx)
+	if err != nil {
+		t.Fatalf("GetAllStaffs: %v", err)
+	}
+	found := false
+	for i, s := range staffs {
+		found = found || s.StaffID == staff.StaffID
+		if i > 0 && staffs[i-1].Nama > s.Nama {
+			t.Errorf("GetAllStaffs is not ordered by name: %q before %q", staffs[i-1].Nama, s.Nama)
+			break
+		}
+	}
+	if !found {
+		t.Errorf("GetAllStaffs did not list the new staff member")
+	}
+}
+
+func testStaffUniqueEmail(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+
+	dup := &models.Staff{Nama: staff.Nama + " Dup", Email: staff.Email, PasswordHashed: fixturePassword, Role: "staff"}
+	err := repos.Staffs.CreateStaff(ctx, dup)
+	if err == nil {
+		t.Cleanup(func() { repos.Staffs.DeleteStaff(ctx, dup.StaffID) })
+	}
+	if !repositories.IsDuplicate(err) {
+		t.Errorf("CreateStaff with an existing email error = %v, want a duplicate error", err)
+	}
+
+	other := newStaff(ctx, t, repos, "staff")
+	other.Email = staff.Email
+	if err := repos.Staffs.UpdateStaff(ctx, other); !repositories.IsDuplicate(err) {
+		t.Errorf("UpdateStaff to an existing email error = %v, want a duplicate error", err)
+	}
+}
+
+func testStaffFailedLogins(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	const threshold = 3
+	lockout := 15 * time.Minute
+
+	for i := 1; i < threshold; i++ {
+		lockedUntil, err := repos.Staffs.RecordFailedLogin(ctx, staff.StaffID, threshold, lockout)
+		if err != nil {
+			t.Fatalf("RecordFailedLogin: %v", err)
+		}
+		if lockedUntil != nil {
+			t.Fatalf("account locked after %d of %d failed logins", i, threshold)
+		}
+	}
+	got, err := repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || got == nil {
+		t.Fatalf("GetStaffByID = %v, %v", got, err)
+	}
+	if got.FailedLoginAttempts != threshold-1 {
+		t.Errorf("FailedLoginAttempts = %d, want %d", got.FailedLoginAttempts, threshold-1)
+	}
+
+	lockedUntil, err := repos.Staffs.RecordFailedLogin(ctx, staff.StaffID, threshold, lockout)
+	if err != nil {
+		t.Fatalf("RecordFailedLogin: %v", err)
+	}
+	if lockedUntil == nil || time.Until(*lockedUntil) < lockout-time.Minute || time.Until(*lockedUntil) > lockout+time.Minute {
+		t.Fatalf("RecordFailedLogin at the threshold locked until %v, want about %v from now", lockedUntil, lockout)
+	}
+	got, err = repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || got == nil {
+		t.Fatalf("GetStaffByID = %v, %v", got, err)
+	}
+	// Hitungan dimulai lagi dari nol selama akun terkunci
+	if got.FailedLoginAttempts != 0 || got.LockedUntil == nil {
+		t.Errorf("locked staff has %d failed logins, locked until %v", got.FailedLoginAttempts, got.LockedUntil)
+	}
+
+	if err := repos.Staffs.ResetFailedLogins(ctx, staff.StaffID); err != nil {
+		t.Fatalf("ResetFailedLogins: %v", err)
+	}
+	got, err = repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || got == nil {
+		t.Fatalf("GetStaffByID = %v, %v", got, err)
+	}
+	if got.FailedLoginAttempts != 0 || got.LockedUntil != nil {
+		t.Errorf("ResetFailedLogins left %d failed logins, locked until %v", got.FailedLoginAttempts, got.LockedUntil)
+	}
+
+	missing, _ := utils.NewUUID()
+	if _, err := repos.Staffs.RecordFailedLogin(ctx, missing, threshold, lockout); !isNotFound(err) {
+		t.Errorf("RecordFailedLogin(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testStaffSetPassword(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	if _, err := repos.Staffs.RecordFailedLogin(ctx, staff.StaffID, 1, time.Minute); err != nil {
+		t.Fatalf("RecordFailedLogin: %v", err)
+	}
+
+	hashed, err := utils.HashPassword("Rahasia-Baru-456!")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	if err := repos.Staffs.SetPassword(ctx, staff.StaffID, hashed, true); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	got, err := repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || got == nil {
+		t.Fatalf("GetStaffByID = %v, %v", got, err)
+	}
+	if got.PasswordHashed != hashed || !got.MustChangePassword {
+		t.Errorf("SetPassword did not save the hash and the must-change flag")
+	}
+	if got.LockedUntil != nil || got.PasswordChangedAt == nil || !recent(*got.PasswordChangedAt) {
+		t.Errorf("SetPassword must unlock the account and record the change: %+v", got)
+	}
+
+	// UpdateStaff meng-hash password polos, tetapi tidak meng-hash ulang hash yang sudah ada
+	got.Nama += " Baru"
+	if err := repos.Staffs.UpdateStaff(ctx, got); err != nil {
+		t.Fatalf("UpdateStaff: %v", err)
+	}
+	updated, err := repos.Staffs.GetStaffByID(ctx, staff.StaffID)
+	if err != nil || updated == nil {
+		t.Fatalf("GetStaffByID = %v, %v", updated, err)
+	}
+	if updated.Nama != got.Nama || !utils.CheckPasswordHash("Rahasia-Baru-456!", updated.PasswordHashed) {
+		t.Errorf("UpdateStaff did not keep the hashed password")
+	}
+}
+
+func testStaffDeleteClearsPic(ctx context.Context, t *testing.T, repos Repositories) {
+	staff := newStaff(ctx, t, repos, "staff")
+	client := newClient(ctx, t, repos, "Tanpa PIC", staff.StaffID)
+	job := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 2, JobYear: 2001, AssignedPicStaffSigmaID: staff.StaffID, OverallStatus: "Dikerjakan"}
+	if err := repos.MonthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	invoice := newInvoice(ctx, t, repos, client.ClientID, &staff.StaffID, randomDate(), 250000)
+
+	if err := repos.Staffs.DeleteStaff(ctx, staff.StaffID); err != nil {
+		t.Fatalf("DeleteStaff: %v", err)
+	}
+	if got, err := repos.Staffs.GetStaffByID(ctx, staff.StaffID); got != nil || err != nil {
+		t.Errorf("GetStaffByID after delete = %v, %v, want nil, nil", got, err)
+	}
+
+	gotClient, err := repos.Clients.GetClientByID(ctx, client.ClientID, "", true)
+	if err != nil {
+		t.Fatalf("GetClientByID: %v", err)
+	}
+	if gotClient.PicStaffSigmaID != "" || gotClient.PicStaffSigmaName != "" {
+		t.Errorf("client PIC after DeleteStaff = %q %q, want empty", gotClient.PicStaffSigmaID, gotClient.PicStaffSigmaName)
+	}
+	gotJob, err := repos.MonthlyJobs.GetMonthlyJobByID(ctx, job.JobID, "", true)
+	if err != nil {
+		t.Fatalf("GetMonthlyJobByID: %v", err)
+	}
+	if gotJob.AssignedPicStaffSigmaID != "" {
+		t.Errorf("monthly job PIC after DeleteStaff = %q, want empty", gotJob.AssignedPicStaffSigmaID)
+	}
+	gotInvoice, err := repos.Invoices.GetInvoiceByID(ctx, invoice.InvoiceID, "", true)
+	if err != nil {
+		t.Fatalf("GetInvoiceByID: %v", err)
+	}
+	if gotInvoice.AssignedStaffID != nil {
+		t.Errorf("invoice staff after DeleteStaff = %q, want nil", *gotInvoice.AssignedStaffID)
+	}
+}
@@ -0,0 +1,83 @@
+// Package repotest adalah contract suite untuk implementasi repository inti. Suite yang sama
+// dijalankan terhadap repository Postgres dan repository in-memory (package memory), sehingga
+// perbedaan perilaku (visibilitas staffIDFilter/isAdmin, constraint unik, urutan hasil, error
+// saat data tidak ditemukan) ketahuan sebelum implementasi in-memory dipakai di test lain.
+//
+// Setiap case membuat datanya sendiri dengan NPWP dan email acak lalu menghapusnya lagi,
+// jadi suite aman dijalankan pada database Postgres yang sudah berisi data. Suite dijalankan
+// oleh go test di package memory dan, jika DATABASE_URL diisi, di package repositories.
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+)
+
+// Repositories is one set of implementations under test. All of them must share the same
+// data, like the Postgres repositories share one database.
+type Repositories struct {
+	Clients         repositories.ClientRepository
+	Staffs          repositories.StaffRepository
+	MonthlyJobs     repositories.MonthlyJobRepository
+	AnnualJobs      repositories.AnnualJobRepository
+	Sp2dkJobs       repositories.Sp2dkJobRepository
+	PemeriksaanJobs repositories.PemeriksaanJobRepository
+	Invoices        repositories.InvoiceRepository
+}
+
+type contractCase struct {
+	name string
+	run  func(ctx context.Context, t *testing.T, repos Repositories)
+}
+
+// cases berurutan per repository; nama dipakai sebagai nama subtest, mis. go test -run 'Contract/invoices/'
+var cases = []contractCase{
+	{"clients/create and get", testClientCreateAndGet},
+	{"clients/visibility", testClientVisibility},
+	{"clients/unique npwp", testClientUniqueNpwp},
+	{"clients/order by name", testClientOrder},
+	{"clients/search", testClientSearch},
+	{"clients/update", testClientUpdate},
+	{"clients/import is all or nothing", testClientImport},
+	{"clients/delete cascades to jobs and invoices", testClientDeleteCascade},
+
+	{"staffs/create and get", testStaffCreateAndGet},
+	{"staffs/unique email", testStaffUniqueEmail},
+	{"staffs/failed logins and lockout", testStaffFailedLogins},
+	{"staffs/set password", testStaffSetPassword},
+	{"staffs/delete clears pic", testStaffDeleteClearsPic},
+
+	{"monthly jobs/create and get", testMonthlyJobCreateAndGet},
+	{"monthly jobs/unique period and tax type", testMonthlyJobUnique},
+	{"monthly jobs/visibility", testMonthlyJobVisibility},
+	{"monthly jobs/list with reports", testMonthlyJobList},
+	{"monthly jobs/tax reports", testMonthlyTaxReports},
+
+	{"annual jobs/create and get", testAnnualJobCreateAndGet},
+	{"annual jobs/unique year and reports", testAnnualJobUnique},
+	{"annual jobs/visibility", testAnnualJobVisibility},
+	{"annual jobs/reports", testAnnualJobReports},
+
+	{"sp2dk jobs/crud and visibility", testSp2dkJobs},
+	{"pemeriksaan jobs/crud and visibility", testPemeriksaanJobs},
+
+	{"invoices/create numbers and totals", testInvoiceCreate},
+	{"invoices/source event issued once", testInvoiceSourceEvent},
+	{"invoices/visibility", testInvoiceVisibility},
+	{"invoices/line items", testInvoiceLineItems},
+
+	{"lists/each matches get all", testEachMatchesGetAll},
+}
+
+// Run runs every contract case against repos as a subtest of t, one after another.
+// Data created by a case is deleted by t.Cleanup when the case ends.
+func Run(t *testing.T, repos Repositories) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Bukan t.Context(): context itu sudah dibatalkan saat cleanup menghapus data case
+			c.run(context.Background(), t, repos)
+		})
+	}
+}
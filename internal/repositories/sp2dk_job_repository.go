@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,12 +11,16 @@ import (
 
 // Sp2dkJobRepository defines the interface for SP2DK job data operations
 type Sp2dkJobRepository interface {
-	CreateSp2dkJob(job *models.Sp2dkJob) error
-	GetAllSp2dkJobs(staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error)
-	GetSp2dkJobByID(id string, staffIDFilter string, isAdmin bool) (*models.Sp2dkJob, error)
-	UpdateSp2dkJob(job *models.Sp2dkJob) error
-	DeleteSp2dkJob(id string) error
-	GetSp2dkJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error)
+	// CreateSp2dkJob and UpdateSp2dkJob write events to the outbox in the same transaction.
+	// A preset job.JobID is used as the new job's ID.
+	CreateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error
+	GetAllSp2dkJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error)
+	// EachSp2dkJob is the streaming form of GetAllSp2dkJobs, see MonthlyJobRepository.EachMonthlyJob.
+	EachSp2dkJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Sp2dkJob) error) error
+	GetSp2dkJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Sp2dkJob, error)
+	UpdateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error
+	DeleteSp2dkJob(ctx context.Context, id string) error
+	GetSp2dkJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error)
 }
 
 // sp2dkJobRepository implements Sp2dkJobRepository interface
@@ -29,13 +34,19 @@ func NewSp2dkJobRepository(db *sql.DB) Sp2dkJobRepository {
 }
 
 // CreateSp2dkJob inserts a new SP2DK job into the database.
-func (r *sp2dkJobRepository) CreateSp2dkJob(job *models.Sp2dkJob) error {
+func (r *sp2dkJobRepository) CreateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// PERBAIKAN: Menggunakan `overall_status` di query dan `job.OverallStatus` sebagai parameter
 	query := `INSERT INTO sp2dk_jobs (
 		client_id, assigned_pic_staff_sigma_id, contract_no, contract_date, sp2dk_no, sp2dk_date,
-		bap2dk_no, bap2dk_date, payment_date, report_date, overall_status, created_at, updated_at
+		bap2dk_no, bap2dk_date, payment_date, report_date, overall_status, created_at, updated_at, job_id
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, COALESCE(NULLIF($14, '')::uuid, gen_random_uuid())
 	) RETURNING job_id, created_at, updated_at`
 
 	if job.CreatedAt.IsZero() {
@@ -43,20 +54,36 @@ func (r *sp2dkJobRepository) CreateSp2dkJob(job *models.Sp2dkJob) error {
 	}
 	job.UpdatedAt = time.Now()
 
-	err := r.db.QueryRow(query,
+	err = tx.QueryRowContext(ctx, query,
 		job.ClientID, job.AssignedPicStaffSigmaID, job.ContractNo, job.ContractDate, job.Sp2dkNo, job.Sp2dkDate,
 		job.Bap2dkNo, job.Bap2dkDate, job.PaymentDate, job.ReportDate, job.OverallStatus,
-		job.CreatedAt, job.UpdatedAt,
+		job.CreatedAt, job.UpdatedAt, job.JobID,
 	).Scan(&job.JobID, &job.CreatedAt, &job.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create SP2DK job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetAllSp2dkJobs fetches all SP2DK jobs.
-func (r *sp2dkJobRepository) GetAllSp2dkJobs(staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
+func (r *sp2dkJobRepository) GetAllSp2dkJobs(ctx context.Context, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
+	var sp2dkJobs []models.Sp2dkJob
+	err := r.EachSp2dkJob(ctx, staffIDFilter, isAdmin, func(job *models.Sp2dkJob) error {
+		sp2dkJobs = append(sp2dkJobs, *job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sp2dkJobs, nil
+}
+
+// EachSp2dkJob fetches all SP2DK jobs, calling fn for each job as soon as it is read.
+func (r *sp2dkJobRepository) EachSp2dkJob(ctx context.Context, staffIDFilter string, isAdmin bool, fn func(*models.Sp2dkJob) error) error {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -75,13 +102,12 @@ func (r *sp2dkJobRepository) GetAllSp2dkJobs(staffIDFilter string, isAdmin bool)
 	}
 	query += " ORDER BY sj.created_at DESC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all SP2DK jobs: %w", err)
+		return fmt.Errorf("failed to get all SP2DK jobs: %w", err)
 	}
 	defer rows.Close()
 
-	var sp2dkJobs []models.Sp2dkJob
 	for rows.Next() {
 		var job models.Sp2dkJob
 		// PERBAIKAN: Menyederhanakan Scan langsung ke field model
@@ -92,19 +118,21 @@ func (r *sp2dkJobRepository) GetAllSp2dkJobs(staffIDFilter string, isAdmin bool)
 			&job.PaymentDate, &job.ReportDate, &job.OverallStatus, &job.ProofOfWorkURL, &job.CreatedAt, &job.UpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan SP2DK job row: %w", err)
+			return fmt.Errorf("failed to scan SP2DK job row: %w", err)
+		}
+		if err := fn(&job); err != nil {
+			return err
 		}
-		sp2dkJobs = append(sp2dkJobs, job)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration: %w", err)
+		return fmt.Errorf("error during rows iteration: %w", err)
 	}
-	return sp2dkJobs, nil
+	return nil
 }
 
 // GetSp2dkJobByID fetches a single SP2DK job by its ID.
-func (r *sp2dkJobRepository) GetSp2dkJobByID(id string, staffIDFilter string, isAdmin bool) (*models.Sp2dkJob, error) {
+func (r *sp2dkJobRepository) GetSp2dkJobByID(ctx context.Context, id string, staffIDFilter string, isAdmin bool) (*models.Sp2dkJob, error) {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -125,7 +153,7 @@ func (r *sp2dkJobRepository) GetSp2dkJobByID(id string, staffIDFilter string, is
 
 	var job models.Sp2dkJob
 	// PERBAIKAN: Menyederhanakan Scan langsung ke field model
-	err := r.db.QueryRow(query, args...).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&job.JobID, &job.ClientID, &job.ClientName, &job.NpwpClient,
 		&job.AssignedPicStaffSigmaID, &job.AssignedPicStaffSigmaName,
 		&job.ContractNo, &job.ContractDate, &job.Sp2dkNo, &job.Sp2dkDate, &job.Bap2dkNo, &job.Bap2dkDate,
@@ -142,7 +170,13 @@ func (r *sp2dkJobRepository) GetSp2dkJobByID(id string, staffIDFilter string, is
 }
 
 // UpdateSp2dkJob updates an existing SP2DK job.
-func (r *sp2dkJobRepository) UpdateSp2dkJob(job *models.Sp2dkJob) error {
+func (r *sp2dkJobRepository) UpdateSp2dkJob(ctx context.Context, job *models.Sp2dkJob, events ...models.DomainEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// PERBAIKAN: Menggunakan `overall_status` dan memperbaiki nomor parameter
 	query := `UPDATE sp2dk_jobs SET
 		client_id = $1, assigned_pic_staff_sigma_id = $2, contract_no = $3, contract_date = $4,
@@ -152,7 +186,7 @@ func (r *sp2dkJobRepository) UpdateSp2dkJob(job *models.Sp2dkJob) error {
 
 	job.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err = tx.ExecContext(ctx, query,
 		job.ClientID, job.AssignedPicStaffSigmaID, job.ContractNo, job.ContractDate,
 		job.Sp2dkNo, job.Sp2dkDate, job.Bap2dkNo, job.Bap2dkDate,
 		job.PaymentDate, job.ReportDate, job.OverallStatus, job.ProofOfWorkURL, job.UpdatedAt, job.JobID,
@@ -161,13 +195,16 @@ func (r *sp2dkJobRepository) UpdateSp2dkJob(job *models.Sp2dkJob) error {
 	if err != nil {
 		return fmt.Errorf("failed to update SP2DK job: %w", err)
 	}
-	return nil
+	if err := writeOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DeleteSp2dkJob deletes an SP2DK job by its ID.
-func (r *sp2dkJobRepository) DeleteSp2dkJob(id string) error {
+func (r *sp2dkJobRepository) DeleteSp2dkJob(ctx context.Context, id string) error {
 	query := `DELETE FROM sp2dk_jobs WHERE job_id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete SP2DK job: %w", err)
 	}
@@ -176,7 +213,7 @@ func (r *sp2dkJobRepository) DeleteSp2dkJob(id string) error {
 
 
 // GetSp2dkJobsByClientID fetches all SP2DK jobs for a specific client.
-func (r *sp2dkJobRepository) GetSp2dkJobsByClientID(clientID string, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
+func (r *sp2dkJobRepository) GetSp2dkJobsByClientID(ctx context.Context, clientID string, staffIDFilter string, isAdmin bool) ([]models.Sp2dkJob, error) {
 	// PERBAIKAN: Menggunakan `overall_status` di SELECT statement
 	query := `
 	SELECT
@@ -196,7 +233,7 @@ func (r *sp2dkJobRepository) GetSp2dkJobsByClientID(clientID string, staffIDFilt
 	}
 	query += " ORDER BY sj.created_at DESC;"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SP2DK jobs by client ID: %w", err)
 	}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -11,12 +12,20 @@ import (
 
 // StaffRepository defines the interface for staff data operations
 type StaffRepository interface {
-	CreateStaff(staff *models.Staff) error
-	GetAllStaffs() ([]models.Staff, error)
-	GetStaffByID(id string) (*models.Staff, error)
-	GetStaffByEmail(email string) (*models.Staff, error) // Useful for authentication
-	UpdateStaff(staff *models.Staff) error
-	DeleteStaff(id string) error
+	CreateStaff(ctx context.Context, staff *models.Staff) error
+	GetAllStaffs(ctx context.Context) ([]models.Staff, error)
+	GetStaffByID(ctx context.Context, id string) (*models.Staff, error)
+	GetStaffByEmail(ctx context.Context, email string) (*models.Staff, error) // Useful for authentication
+	UpdateStaff(ctx context.Context, staff *models.Staff) error
+	DeleteStaff(ctx context.Context, id string) error
+	// RecordFailedLogin counts a failed login. When the count reaches threshold the account is
+	// locked for lockout and the count starts again; it returns the resulting locked_until.
+	RecordFailedLogin(ctx context.Context, id string, threshold int, lockout time.Duration) (*time.Time, error)
+	// ResetFailedLogins clears the failed login count and unlocks the account.
+	ResetFailedLogins(ctx context.Context, id string) error
+	// SetPassword saves an already hashed password and unlocks the account. mustChange marks
+	// it as a temporary password that has to be changed on the next login.
+	SetPassword(ctx context.Context, id, passwordHashed string, mustChange bool) error
 }
 
 // staffRepository implements StaffRepository interface
@@ -31,7 +40,7 @@ func NewStaffRepository(db *sql.DB) StaffRepository {
 
 // CreateStaff inserts a new staff member into the database.
 // It generates NIP and hashes the password before saving.
-func (r *staffRepository) CreateStaff(staff *models.Staff) error {
+func (r *staffRepository) CreateStaff(ctx context.Context, staff *models.Staff) error {
 	// Generate NIP
 	staff.NIP = utils.GenerateNIP(staff.Nama)
 
@@ -43,18 +52,19 @@ func (r *staffRepository) CreateStaff(staff *models.Staff) error {
 	staff.PasswordHashed = hashedPassword
 
 	query := `INSERT INTO staffs (
-		nip, nama, email, password_hashed, role, created_at, updated_at
+		nip, nama, email, password_hashed, role, must_change_password, password_changed_at, created_at, updated_at
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7
+		$1, $2, $3, $4, $5, $6, $7, $7, $8
 	) RETURNING staff_id, created_at, updated_at`
 
 	if staff.CreatedAt.IsZero() {
 		staff.CreatedAt = time.Now()
 	}
 	staff.UpdatedAt = time.Now()
+	staff.PasswordChangedAt = &staff.CreatedAt
 
-	err = r.db.QueryRow(query,
-		staff.NIP, staff.Nama, staff.Email, staff.PasswordHashed, staff.Role,
+	err = r.db.QueryRowContext(ctx, query,
+		staff.NIP, staff.Nama, staff.Email, staff.PasswordHashed, staff.Role, staff.MustChangePassword,
 		staff.CreatedAt, staff.UpdatedAt,
 	).Scan(&staff.StaffID, &staff.CreatedAt, &staff.UpdatedAt)
 
@@ -65,12 +75,15 @@ func (r *staffRepository) CreateStaff(staff *models.Staff) error {
 }
 
 // GetAllStaffs fetches all staff members from the database
-func (r *staffRepository) GetAllStaffs() ([]models.Staff, error) {
+func (r *staffRepository) GetAllStaffs(ctx context.Context) ([]models.Staff, error) {
 	query := `SELECT
-		staff_id, nip, nama, email, password_hashed, role, created_at, updated_at
+		staff_id, nip, nama, email, password_hashed, role,
+		must_change_password, password_changed_at, failed_login_attempts, locked_until,
+		EXISTS (SELECT 1 FROM staff_totp t WHERE t.staff_id = staffs.staff_id AND t.enabled_at IS NOT NULL),
+		created_at, updated_at
 	FROM staffs ORDER BY nama ASC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all staffs: %w", err)
 	}
@@ -80,8 +93,9 @@ func (r *staffRepository) GetAllStaffs() ([]models.Staff, error) {
 	for rows.Next() {
 		var staff models.Staff
 		err := rows.Scan(
-			&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email,
-			&staff.PasswordHashed, &staff.Role, &staff.CreatedAt, &staff.UpdatedAt,
+			&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email, &staff.PasswordHashed, &staff.Role,
+			&staff.MustChangePassword, &staff.PasswordChangedAt, &staff.FailedLoginAttempts, &staff.LockedUntil, &staff.TOTPEnabled,
+			&staff.CreatedAt, &staff.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan staff row: %w", err)
@@ -97,15 +111,19 @@ func (r *staffRepository) GetAllStaffs() ([]models.Staff, error) {
 }
 
 // GetStaffByID fetches a staff member by their ID from the database
-func (r *staffRepository) GetStaffByID(id string) (*models.Staff, error) {
+func (r *staffRepository) GetStaffByID(ctx context.Context, id string) (*models.Staff, error) {
 	query := `SELECT
-		staff_id, nip, nama, email, password_hashed, role, created_at, updated_at
+		staff_id, nip, nama, email, password_hashed, role,
+		must_change_password, password_changed_at, failed_login_attempts, locked_until,
+		EXISTS (SELECT 1 FROM staff_totp t WHERE t.staff_id = staffs.staff_id AND t.enabled_at IS NOT NULL),
+		created_at, updated_at
 	FROM staffs WHERE staff_id = $1`
 
 	var staff models.Staff
-	err := r.db.QueryRow(query, id).Scan(
-		&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email,
-		&staff.PasswordHashed, &staff.Role, &staff.CreatedAt, &staff.UpdatedAt,
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email, &staff.PasswordHashed, &staff.Role,
+		&staff.MustChangePassword, &staff.PasswordChangedAt, &staff.FailedLoginAttempts, &staff.LockedUntil, &staff.TOTPEnabled,
+		&staff.CreatedAt, &staff.UpdatedAt,
 	)
 
 	if err != nil {
@@ -118,15 +136,19 @@ func (r *staffRepository) GetStaffByID(id string) (*models.Staff, error) {
 }
 
 // GetStaffByEmail fetches a staff member by their email from the database
-func (r *staffRepository) GetStaffByEmail(email string) (*models.Staff, error) {
+func (r *staffRepository) GetStaffByEmail(ctx context.Context, email string) (*models.Staff, error) {
 	query := `SELECT
-		staff_id, nip, nama, email, password_hashed, role, created_at, updated_at
+		staff_id, nip, nama, email, password_hashed, role,
+		must_change_password, password_changed_at, failed_login_attempts, locked_until,
+		EXISTS (SELECT 1 FROM staff_totp t WHERE t.staff_id = staffs.staff_id AND t.enabled_at IS NOT NULL),
+		created_at, updated_at
 	FROM staffs WHERE email = $1`
 
 	var staff models.Staff
-	err := r.db.QueryRow(query, email).Scan(
-		&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email,
-		&staff.PasswordHashed, &staff.Role, &staff.CreatedAt, &staff.UpdatedAt,
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&staff.StaffID, &staff.NIP, &staff.Nama, &staff.Email, &staff.PasswordHashed, &staff.Role,
+		&staff.MustChangePassword, &staff.PasswordChangedAt, &staff.FailedLoginAttempts, &staff.LockedUntil, &staff.TOTPEnabled,
+		&staff.CreatedAt, &staff.UpdatedAt,
 	)
 
 	if err != nil {
@@ -140,7 +162,7 @@ func (r *staffRepository) GetStaffByEmail(email string) (*models.Staff, error) {
 
 // UpdateStaff updates an existing staff member in the database.
 // It will hash the new password if provided.
-func (r *staffRepository) UpdateStaff(staff *models.Staff) error {
+func (r *staffRepository) UpdateStaff(ctx context.Context, staff *models.Staff) error {
 	// If a new password (in hashed form) is provided in the staff struct, hash it again
 	// This assumes the handler passed a plain text password to PasswordHashed for new hash
 	// Or, if PasswordHashed already contains a new hash from client, it's just saved directly.
@@ -162,7 +184,7 @@ func (r *staffRepository) UpdateStaff(staff *models.Staff) error {
 
 	staff.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		staff.NIP, staff.Nama, staff.Email, staff.PasswordHashed, staff.Role,
 		staff.UpdatedAt, staff.StaffID,
 	)
@@ -174,11 +196,51 @@ func (r *staffRepository) UpdateStaff(staff *models.Staff) error {
 }
 
 // DeleteStaff deletes a staff member from the database
-func (r *staffRepository) DeleteStaff(id string) error {
+func (r *staffRepository) DeleteStaff(ctx context.Context, id string) error {
 	query := `DELETE FROM staffs WHERE staff_id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete staff: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// RecordFailedLogin increments the counter atomically, so parallel wrong guesses are all counted
+func (r *staffRepository) RecordFailedLogin(ctx context.Context, id string, threshold int, lockout time.Duration) (*time.Time, error) {
+	query := `UPDATE staffs SET
+		failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+		locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 millisecond' ELSE locked_until END
+	WHERE staff_id = $1
+	RETURNING locked_until`
+
+	var lockedUntil *time.Time
+	err := r.db.QueryRowContext(ctx, query, id, threshold, lockout.Milliseconds()).Scan(&lockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+	return lockedUntil, nil
+}
+
+// ResetFailedLogins is called after a successful login, and by an admin to unlock an account
+func (r *staffRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	query := `UPDATE staffs SET failed_login_attempts = 0, locked_until = NULL
+	WHERE staff_id = $1 AND (failed_login_attempts <> 0 OR locked_until IS NOT NULL)`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// SetPassword replaces the password hash; the caller hashes and checks the policy
+func (r *staffRepository) SetPassword(ctx context.Context, id, passwordHashed string, mustChange bool) error {
+	query := `UPDATE staffs SET
+		password_hashed = $2, must_change_password = $3, password_changed_at = NOW(),
+		failed_login_attempts = 0, locked_until = NULL, updated_at = NOW()
+	WHERE staff_id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, passwordHashed, mustChange)
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	return nil
+}
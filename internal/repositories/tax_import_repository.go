@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+// TaxImportRepository defines the data operations for the export files imported into
+// monthly tax reports
+type TaxImportRepository interface {
+	// SaveImportSources stores the totals of the given files on a report, replacing those of
+	// a file with the same name, and sets the report's billing_amount to the sum of all its
+	// files, never below zero. It returns the summed totals of the report.
+	SaveImportSources(ctx context.Context, reportID string, sources []models.TaxImportSource) (*models.TaxImportTotal, error)
+}
+
+// taxImportRepository implements TaxImportRepository interface
+type taxImportRepository struct {
+	db *sql.DB
+}
+
+// NewTaxImportRepository creates a new TaxImportRepository
+func NewTaxImportRepository(db *sql.DB) TaxImportRepository {
+	return &taxImportRepository{db: db}
+}
+
+// SaveImportSources writes the files and the new billing amount in one transaction
+func (r *taxImportRepository) SaveImportSources(ctx context.Context, reportID string, sources []models.TaxImportSource) (*models.TaxImportTotal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, src := range sources {
+		_, err := tx.ExecContext(ctx, `INSERT INTO monthly_tax_report_imports (report_id, file_name, line_count, tax_base, tax_amount)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (report_id, file_name) DO UPDATE SET line_count = EXCLUDED.line_count,
+				tax_base = EXCLUDED.tax_base, tax_amount = EXCLUDED.tax_amount, imported_at = CURRENT_TIMESTAMP`,
+			reportID, src.FileName, src.LineCount, src.TaxBase, src.TaxAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save imported file %s: %w", src.FileName, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT file_name, line_count, tax_base, tax_amount
+		FROM monthly_tax_report_imports WHERE report_id = $1 ORDER BY imported_at, file_name`, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get imported files: %w", err)
+	}
+	defer rows.Close()
+
+	total := &models.TaxImportTotal{ReportID: reportID, SourceFiles: []string{}}
+	for rows.Next() {
+		var src models.TaxImportSource
+		if err := rows.Scan(&src.FileName, &src.LineCount, &src.TaxBase, &src.TaxAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan imported file: %w", err)
+		}
+		total.SourceFiles = append(total.SourceFiles, src.FileName)
+		total.LineCount += src.LineCount
+		total.TaxBase += src.TaxBase
+		total.TaxAmount += src.TaxAmount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during imported files iteration: %w", err)
+	}
+	rows.Close() // Koneksi transaksi harus bebas sebelum UPDATE berikutnya
+	total.TaxBase = math.Round(total.TaxBase*100) / 100
+	total.TaxAmount = math.Round(total.TaxAmount*100) / 100
+	total.BillingAmount = math.Max(total.TaxAmount, 0) // Lebih bayar tidak menghasilkan kode billing
+
+	_, err = tx.ExecContext(ctx, `UPDATE monthly_tax_reports SET billing_amount = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE report_id = $2`, total.BillingAmount, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update billing amount: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit imported files: %w", err)
+	}
+	return total, nil
+}
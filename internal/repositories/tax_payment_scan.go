@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
+)
+
+// taxPaymentScan holds the nullable payment evidence columns of a tax report row.
+type taxPaymentScan struct {
+	billingCodeExpiresAt sql.NullTime
+	billingAmount        sql.NullFloat64
+	ntpn                 sql.NullString
+	paymentChannel       sql.NullString
+}
+
+// apply copies the scanned values into the report's evidence and computes the amount mismatch flag.
+func (s *taxPaymentScan) apply(ev *models.TaxPaymentEvidence, paymentAmount *float64) {
+	if s.billingCodeExpiresAt.Valid {
+		t := s.billingCodeExpiresAt.Time
+		ev.BillingCodeExpiresAt = &t
+	}
+	if s.billingAmount.Valid {
+		v := s.billingAmount.Float64
+		ev.BillingAmount = &v
+	}
+	ev.Ntpn = s.ntpn.String
+	ev.PaymentChannel = s.paymentChannel.String
+	ev.AmountMismatch = taxpayment.AmountsMismatch(ev.BillingAmount, paymentAmount)
+}
+
+// nullableString stores empty strings as NULL.
+func nullableString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// TwoFactorRepository defines the data operations for TOTP secrets and recovery codes
+type TwoFactorRepository interface {
+	// GetTOTP returns the TOTP of the staff member, or nil if there is none.
+	GetTOTP(ctx context.Context, staffID string) (*models.StaffTOTP, error)
+	// SavePendingTOTP stores a new unconfirmed secret, replacing an earlier unconfirmed one.
+	// It returns false if 2FA is already enabled.
+	SavePendingTOTP(ctx context.Context, staffID, secret string) (bool, error)
+	// EnableTOTP confirms the pending secret, records the counter of the confirming code and
+	// replaces the recovery codes. It returns false if there is no pending secret.
+	EnableTOTP(ctx context.Context, staffID string, counter int64, recoveryCodeHashes []string) (bool, error)
+	// UseTOTPCounter records that the code of counter was used. It returns false if that
+	// code or a later one was already used, so a code cannot be replayed.
+	UseTOTPCounter(ctx context.Context, staffID string, counter int64) (bool, error)
+	// UseRecoveryCode marks an unused recovery code as used, and returns false if there is none.
+	UseRecoveryCode(ctx context.Context, staffID, codeHash string) (bool, error)
+	// ReplaceRecoveryCodes deletes all recovery codes of the staff member and stores new ones.
+	ReplaceRecoveryCodes(ctx context.Context, staffID string, codeHashes []string) error
+	// CountRecoveryCodes returns the number of unused recovery codes.
+	CountRecoveryCodes(ctx context.Context, staffID string) (int, error)
+	// DeleteTwoFactor removes the TOTP secret and recovery codes (disable or admin reset).
+	DeleteTwoFactor(ctx context.Context, staffID string) error
+}
+
+// twoFactorRepository implements TwoFactorRepository interface
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository creates a new TwoFactorRepository
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// GetTOTP fetches the TOTP row, confirmed or not
+func (r *twoFactorRepository) GetTOTP(ctx context.Context, staffID string) (*models.StaffTOTP, error) {
+	query := `SELECT staff_id, secret, last_counter, enabled_at, created_at
+	FROM staff_totp WHERE staff_id = $1`
+
+	var t models.StaffTOTP
+	err := r.db.QueryRowContext(ctx, query, staffID).Scan(&t.StaffID, &t.Secret, &t.LastCounter, &t.EnabledAt, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+	return &t, nil
+}
+
+// SavePendingTOTP upserts the secret only while the existing row is still unconfirmed
+func (r *twoFactorRepository) SavePendingTOTP(ctx context.Context, staffID, secret string) (bool, error) {
+	query := `INSERT INTO staff_totp (staff_id, secret) VALUES ($1, $2)
+	ON CONFLICT (staff_id) DO UPDATE SET secret = EXCLUDED.secret, last_counter = 0, created_at = NOW()
+	WHERE staff_totp.enabled_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, staffID, secret)
+	if err != nil {
+		return false, fmt.Errorf("failed to save pending TOTP: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save pending TOTP: %w", err)
+	}
+	return n > 0, nil
+}
+
+// EnableTOTP confirms the secret and stores the first recovery codes in one transaction
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, staffID string, counter int64, recoveryCodeHashes []string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE staff_totp SET enabled_at = NOW(), last_counter = $2
+		WHERE staff_id = $1 AND enabled_at IS NULL`, staffID, counter)
+	if err != nil {
+		return false, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	if n == 0 {
+		return false, nil // Tidak ada enrollment yang menunggu konfirmasi
+	}
+	if err := replaceRecoveryCodes(ctx, tx, staffID, recoveryCodeHashes); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit TOTP enrollment: %w", err)
+	}
+	return true, nil
+}
+
+// UseTOTPCounter advances last_counter with a conditional UPDATE, so two logins with the
+// same code cannot both succeed
+func (r *twoFactorRepository) UseTOTPCounter(ctx context.Context, staffID string, counter int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE staff_totp SET last_counter = $2
+		WHERE staff_id = $1 AND enabled_at IS NOT NULL AND last_counter < $2`, staffID, counter)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	return n > 0, nil
+}
+
+// UseRecoveryCode marks the code used
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, staffID, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE staff_recovery_codes SET used_at = NOW()
+		WHERE staff_id = $1 AND code_hash = $2 AND used_at IS NULL`, staffID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ReplaceRecoveryCodes regenerates the recovery codes
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, staffID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, staffID, codeHashes); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, staffID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM staff_recovery_codes WHERE staff_id = $1`, staffID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO staff_recovery_codes (staff_id, code_hash)
+		SELECT $1, unnest($2::text[])`, staffID, pq.Array(codeHashes))
+	if err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+	return nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, staffID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM staff_recovery_codes
+		WHERE staff_id = $1 AND used_at IS NULL`, staffID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteTwoFactor turns 2FA off for the staff member
+func (r *twoFactorRepository) DeleteTwoFactor(ctx context.Context, staffID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM staff_recovery_codes WHERE staff_id = $1`, staffID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM staff_totp WHERE staff_id = $1`, staffID); err != nil {
+		return fmt.Errorf("failed to delete TOTP: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit 2FA reset: %w", err)
+	}
+	return nil
+}
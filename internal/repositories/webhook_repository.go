@@ -0,0 +1,316 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// WebhookRepository defines data operations for webhook subscriptions and their delivery log
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	GetAllSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	// GetSubscriptionByID returns sql.ErrNoRows if the subscription does not exist.
+	GetSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// CreateDeliveries queues one Pending delivery per active subscription of eventType
+	// and returns how many were queued. Subscriptions that already have a delivery for
+	// eventID are skipped, so queuing the same event twice is a no-op.
+	CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) (int64, error)
+	// Redeliver queues a new Pending delivery with the same event and payload as deliveryID.
+	// It returns sql.ErrNoRows if the delivery does not exist.
+	Redeliver(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error)
+	// ClaimDueDeliveries locks up to limit due deliveries for one attempt by pushing their
+	// next_attempt_at forward by lease, so other API instances skip them meanwhile.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, deliveryID string, result models.WebhookAttemptResult) error
+	GetDeliveries(ctx context.Context, subscriptionID, status string, limit int) ([]models.WebhookDelivery, error)
+	// GetDeliveryByID returns sql.ErrNoRows if the delivery does not exist.
+	GetDeliveryByID(ctx context.Context, id string) (*models.WebhookDelivery, error)
+}
+
+// webhookRepository implements WebhookRepository interface
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `subscription_id, url, event_types, description, is_active, created_at, updated_at`
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	var description sql.NullString
+	err := row.Scan(&sub.SubscriptionID, &sub.URL, pq.Array(&sub.EventTypes), &description, &sub.IsActive, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if description.Valid {
+		sub.Description = &description.String
+	}
+	return &sub, nil
+}
+
+// CreateSubscription inserts a new webhook subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `INSERT INTO webhook_subscriptions (url, secret, event_types, description, is_active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING subscription_id`
+
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+	err := r.db.QueryRowContext(ctx, query, sub.URL, sub.Secret, pq.Array(sub.EventTypes), sub.Description, sub.IsActive, sub.CreatedAt, sub.UpdatedAt).
+		Scan(&sub.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetAllSubscriptions returns every webhook subscription without its secret
+func (r *webhookRepository) GetAllSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription row: %w", err)
+		}
+		subs = append(subs, *sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// GetSubscriptionByID returns a webhook subscription without its secret
+func (r *webhookRepository) GetSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	sub, err := scanWebhookSubscription(r.db.QueryRowContext(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions WHERE subscription_id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// UpdateSubscription saves a webhook subscription. The secret is only changed if sub.Secret is set.
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `UPDATE webhook_subscriptions SET
+		url = $1, secret = COALESCE(NULLIF($2, ''), secret), event_types = $3, description = $4, is_active = $5, updated_at = $6
+	WHERE subscription_id = $7`
+
+	sub.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query, sub.URL, sub.Secret, pq.Array(sub.EventTypes), sub.Description, sub.IsActive, sub.UpdatedAt, sub.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription deletes a webhook subscription. Its deliveries are deleted via CASCADE.
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE subscription_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `d.delivery_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_attempt_at, d.response_status, d.response_body, d.last_error, d.created_at, d.updated_at`
+
+func scanWebhookDelivery(row rowScanner, extra ...interface{}) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt, lastAttemptAt sql.NullTime
+	var responseStatus sql.NullInt64
+	var responseBody, lastError sql.NullString
+
+	dest := []interface{}{
+		&d.DeliveryID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&nextAttemptAt, &lastAttemptAt, &responseStatus, &responseBody, &lastError, &d.CreatedAt, &d.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if lastAttemptAt.Valid {
+		d.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		d.ResponseStatus = &status
+	}
+	if responseBody.Valid {
+		d.ResponseBody = &responseBody.String
+	}
+	if lastError.Valid {
+		d.LastError = &lastError.String
+	}
+	return &d, nil
+}
+
+// CreateDeliveries fans an event out to every active subscription that listens for it
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+	SELECT subscription_id, $1, $2, $3, '` + models.WebhookDeliveryPending + `', $4, $4, $4
+	FROM webhook_subscriptions AS ws
+	WHERE is_active AND $2 = ANY(event_types)
+	AND NOT EXISTS (SELECT 1 FROM webhook_deliveries AS wd WHERE wd.event_id = $1 AND wd.subscription_id = ws.subscription_id)`
+
+	result, err := r.db.ExecContext(ctx, query, eventID, eventType, payload, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// Redeliver copies a delivery into a new Pending delivery that is due immediately
+func (r *webhookRepository) Redeliver(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error) {
+	query := `INSERT INTO webhook_deliveries AS d (subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+	SELECT subscription_id, event_id, event_type, payload, '` + models.WebhookDeliveryPending + `', $2, $2, $2
+	FROM webhook_deliveries
+	WHERE delivery_id = $1
+	RETURNING ` + webhookDeliveryColumns
+
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, deliveryID, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+	return d, nil
+}
+
+// ClaimDueDeliveries leases due Pending deliveries of active subscriptions, oldest first
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = $2
+	FROM (
+		SELECT wd.delivery_id
+		FROM webhook_deliveries wd
+		JOIN webhook_subscriptions ws ON ws.subscription_id = wd.subscription_id AND ws.is_active
+		WHERE wd.status = '` + models.WebhookDeliveryPending + `' AND wd.next_attempt_at <= $1
+		ORDER BY wd.next_attempt_at
+		LIMIT $3
+		FOR UPDATE OF wd SKIP LOCKED
+	) due, webhook_subscriptions s
+	WHERE d.delivery_id = due.delivery_id AND s.subscription_id = d.subscription_id
+	RETURNING ` + webhookDeliveryColumns + `, s.url, s.secret`
+
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var url, secret string
+		d, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		d.URL = url
+		d.Secret = secret
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of one delivery attempt and schedules the next one, if any
+func (r *webhookRepository) RecordAttempt(ctx context.Context, deliveryID string, result models.WebhookAttemptResult) error {
+	query := `UPDATE webhook_deliveries SET
+		status = $2, attempts = attempts + 1, next_attempt_at = $3, last_attempt_at = $4,
+		response_status = $5, response_body = $6, last_error = $7, updated_at = $4
+	WHERE delivery_id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, deliveryID, result.Status, result.NextAttemptAt, result.AttemptedAt,
+		result.ResponseStatus, result.ResponseBody, result.Error)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries returns the newest deliveries, optionally filtered by subscription and status
+func (r *webhookRepository) GetDeliveries(ctx context.Context, subscriptionID, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries d WHERE 1=1`
+	args := []interface{}{}
+	if subscriptionID != "" {
+		args = append(args, subscriptionID)
+		query += fmt.Sprintf(" AND d.subscription_id = $%d", len(args))
+	}
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" AND d.status = $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY d.created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration for webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDeliveryByID returns a single webhook delivery
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries d WHERE d.delivery_id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,547 @@
+package routes
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/handlers"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/openapi"
+)
+
+// apiPrefix adalah awalan route yang masuk ke dokumen OpenAPI
+const apiPrefix = "/api/v1"
+
+// operationDoc melengkapi route dari tabel router dengan hal yang tidak bisa dibaca dari
+// handler-nya: ringkasan, model body dan respons, serta parameter query. Parameter path
+// diambil dari pola route.
+type operationDoc struct {
+	Summary  string
+	Request  interface{}     // Model body JSON, nil jika tanpa body JSON
+	Form     *openapi.Schema // Body multipart/form-data
+	Query    []openapi.Parameter
+	Status   int         // Status sukses, default 200
+	Response interface{} // Model respons JSON sukses
+	Files    []string    // Content type respons berupa file, selain atau pengganti JSON
+	Public   bool        // Tanpa token; endpoint publik dibatasi rate limiter login
+}
+
+// Respons gin.H yang dipakai beberapa handler. Struct anonim dijabarkan inline, bukan
+// sebagai component.
+var (
+	messageResponse = struct {
+		Message string `json:"message"`
+	}{}
+	loginResponse = struct {
+		Token                  string `json:"token,omitempty"`
+		MustChangePassword     bool   `json:"must_change_password,omitempty"`
+		TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+		// Jika 2FA aktif, token belum diberikan: kirim mfa_token dan kode ke /auth/login/verify
+		MFARequired bool   `json:"mfa_required,omitempty"`
+		MFAToken    string `json:"mfa_token,omitempty"`
+		ExpiresIn   int    `json:"expires_in,omitempty"`
+	}{}
+	recoveryCodesResponse = struct {
+		Message       string   `json:"message,omitempty"`
+		RecoveryCodes []string `json:"recovery_codes"`
+		Token         string   `json:"token,omitempty"`
+	}{}
+)
+
+var (
+	exportQuery = []openapi.Parameter{
+		queryParam("format", enumSchema("json", "csv", "xlsx", "excel"), "Export format; without it the Accept header decides"),
+		queryParam("columns", &openapi.Schema{Type: "string"}, "Comma-separated column keys of the export"),
+		queryParam("lang", enumSchema("id", "en"), "Language of the export column headers"),
+	}
+	jobFormFields = map[string]*openapi.Schema{
+		"overall_status":              {Type: "string"},
+		"assigned_pic_staff_sigma_id": {Type: "string"},
+		"proof_of_work_pdf":           {Type: "string", Format: "binary", Description: "Proof of work (PDF)"},
+	}
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// operationDocs is keyed by handler, as in the route table, e.g. "ClientHandler.CreateClient"
+var operationDocs = map[string]operationDoc{
+	// Auth
+	"AuthHandler.Login": {Summary: "Log in as staff", Request: handlers.LoginRequest{}, Response: loginResponse, Public: true},
+	"AuthHandler.LoginVerify": {Summary: "Second login step with a TOTP or recovery code",
+		Request: models.LoginVerifyRequest{}, Response: loginResponse, Public: true},
+	"AuthHandler.ForgotPassword": {Summary: "Send a password reset link", Request: models.ForgotPasswordRequest{}, Response: messageResponse, Public: true},
+	"AuthHandler.ResetPassword":  {Summary: "Reset the password with a reset token", Request: models.ResetPasswordRequest{}, Response: messageResponse, Public: true},
+	"AuthHandler.ChangePassword": {Summary: "Change the own password", Request: models.ChangePasswordRequest{},
+		Response: struct {
+			Message string `json:"message"`
+			Token   string `json:"token"`
+		}{}},
+	"TwoFactorHandler.GetStatus":               {Summary: "Two-factor status of the logged-in staff member", Response: models.TwoFactorStatus{}},
+	"TwoFactorHandler.BeginSetup":              {Summary: "Start two-factor enrollment", Response: models.TwoFactorEnrollment{}},
+	"TwoFactorHandler.ConfirmSetup":            {Summary: "Enable two-factor login with the first code", Request: models.TwoFactorCodeRequest{}, Response: recoveryCodesResponse},
+	"TwoFactorHandler.Disable":                 {Summary: "Disable two-factor login", Request: models.DisableTwoFactorRequest{}, Response: messageResponse},
+	"TwoFactorHandler.RegenerateRecoveryCodes": {Summary: "Replace the recovery codes", Request: models.TwoFactorCodeRequest{}, Response: recoveryCodesResponse},
+	"TwoFactorHandler.ResetStaffTwoFactor":     {Summary: "Reset two-factor login of a staff member (admin)", Status: http.StatusNoContent},
+
+	// Portal klien
+	"PortalHandler.Login": {Summary: "Log in to the client portal", Request: handlers.LoginRequest{},
+		Response: struct {
+			Token string `json:"token"`
+		}{}, Public: true},
+	"PortalHandler.GetMe": {Summary: "Logged-in client user",
+		Response: struct {
+			ClientUserID string `json:"client_user_id"`
+			Nama         string `json:"nama"`
+			Email        string `json:"email"`
+			ClientID     string `json:"client_id"`
+			ClientName   string `json:"client_name"`
+		}{}},
+	"PortalHandler.GetJobs": {Summary: "Jobs of the client", Response: []models.PortalJob{}},
+	"PortalHandler.GetTaxPayments": {Summary: "Tax reports and payments of a year",
+		Query:    []openapi.Parameter{queryParam("year", intSchema(2000, 9999), "Defaults to the current year")},
+		Response: models.PortalTaxPaymentSummary{}},
+	"PortalHandler.GetInvoices":      {Summary: "Invoices of the client", Response: []models.PortalInvoice{}},
+	"PortalHandler.GetInvoiceByID":   {Summary: "Invoice with line items", Response: models.PortalInvoice{}},
+	"PortalHandler.GetInvoicePDF":    {Summary: "Invoice as PDF", Files: []string{"application/pdf"}},
+	"PortalHandler.GetDocuments":     {Summary: "Documents of the client", Response: []models.PortalDocument{}},
+	"PortalHandler.DownloadDocument": {Summary: "Download a document", Files: []string{"application/octet-stream"}},
+	"PortalHandler.GetDocumentRequests": {Summary: "Documents requested from the client",
+		Query:    []openapi.Parameter{queryParam("outstanding", &openapi.Schema{Type: "boolean"}, "Only requests that still need an upload")},
+		Response: []models.PortalDocumentRequest{}},
+	"PortalHandler.UploadDocumentRequest": {Summary: "Upload the requested document",
+		Form: formSchema(map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}}, "file"), Response: models.PortalDocumentRequest{}},
+
+	// Clients
+	"ClientHandler.CreateClient": {Summary: "Create a client", Request: models.NewClientRequest{}, Status: http.StatusCreated, Response: models.Client{}},
+	"ClientImportHandler.ImportClients": {Summary: "Import clients from CSV or XLSX",
+		Form: formSchema(map[string]*openapi.Schema{
+			"file":    {Type: "string", Format: "binary", Description: "CSV or XLSX file"},
+			"mode":    {Type: "string", Description: "How existing clients (same NPWP) are handled"},
+			"dry_run": {Type: "boolean", Description: "Validate only, nothing is saved"},
+			"mapping": {Type: "string", Description: "JSON object of client field to column title"},
+		}, "file"),
+		Response: models.ClientImportResult{}},
+	"ClientHandler.GetAllClients": {Summary: "List clients, or export them",
+		Query:    append([]openapi.Parameter{queryParam("q", &openapi.Schema{Type: "string"}, "Filter by client name or NPWP")}, exportQuery...),
+		Response: []models.Client{}, Files: []string{"text/csv", xlsxContentType}},
+	"ClientHandler.GetClientByID": {Summary: "Get a client", Response: models.Client{}},
+	"ClientHandler.GetClientDashboardJobs": {Summary: "All jobs of a client",
+		Response: struct {
+			ClientID        string                  `json:"client_id"`
+			ClientName      string                  `json:"client_name"`
+			NpwpClient      string                  `json:"npwp_client"`
+			MonthlyJobs     []models.MonthlyJob     `json:"monthly_jobs"`
+			AnnualJobs      []models.AnnualJob      `json:"annual_jobs"`
+			Sp2dkJobs       []models.Sp2dkJob       `json:"sp2dk_jobs"`
+			PemeriksaanJobs []models.PemeriksaanJob `json:"pemeriksaan_jobs"`
+		}{}},
+	"ClientHandler.UpdateClient":         {Summary: "Update a client", Request: models.UpdateClientRequest{}, Response: models.Client{}},
+	"ClientHandler.DeleteClient":         {Summary: "Delete a client", Status: http.StatusNoContent},
+	"ClientUserHandler.GetClientUsers":   {Summary: "Portal logins of a client (admin)", Response: []models.ClientUser{}},
+	"ClientUserHandler.CreateClientUser": {Summary: "Create a portal login (admin)", Request: models.NewClientUserRequest{}, Status: http.StatusCreated, Response: models.ClientUser{}},
+	"ClientUserHandler.UpdateClientUser": {Summary: "Update a portal login (admin)", Request: models.UpdateClientUserRequest{}, Response: models.ClientUser{}},
+	"ClientUserHandler.DeleteClientUser": {Summary: "Delete a portal login (admin)", Response: messageResponse},
+
+	// Monthly jobs
+	"MonthlyJobHandler.CreateMonthlyJob": {Summary: "Create a monthly job", Request: models.NewMonthlyJobRequest{}, Status: http.StatusCreated, Response: models.MonthlyJob{}},
+	"MonthlyJobHandler.GetAllMonthlyJobs": {Summary: "List monthly jobs, or export them", Query: exportQuery,
+		Response: []models.MonthlyJob{}, Files: []string{"text/csv", xlsxContentType}},
+	"MonthlyJobHandler.GetMonthlyJobByID": {Summary: "Get a monthly job", Response: models.MonthlyJob{}},
+	"MonthlyJobHandler.UpdateMonthlyJob": {Summary: "Update a monthly job and upload the proof of work",
+		Form: formSchema(withFields(jobFormFields, map[string]*openapi.Schema{"job_month": {Type: "integer"}, "job_year": {Type: "integer"}})), Response: models.MonthlyJob{}},
+	"TaxImportHandler.ImportMonthlyJobFiles": {Summary: "Import e-Bupot and e-Faktur exports into a monthly job",
+		Form: formSchema(map[string]*openapi.Schema{
+			"files": {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}, Description: "Export files; a single file may also be sent as 'file'"},
+		}), Response: models.TaxImportResult{}},
+	"MonthlyJobHandler.CreateMonthlyTaxReport": {Summary: "Add a tax report to a monthly job", Request: models.NewMonthlyTaxReportRequest{}, Status: http.StatusCreated, Response: models.MonthlyTaxReport{}},
+	"MonthlyJobHandler.UpdateMonthlyTaxReport": {Summary: "Update a tax report of a monthly job", Request: models.UpdateMonthlyTaxReportRequest{}, Response: models.MonthlyTaxReport{}},
+	"MonthlyJobHandler.DeleteMonthlyTaxReport": {Summary: "Delete a tax report of a monthly job", Status: http.StatusNoContent},
+
+	// Annual jobs
+	"AnnualJobHandler.CreateAnnualJob": {Summary: "Create an annual job", Request: models.NewAnnualJobRequest{}, Status: http.StatusCreated, Response: models.AnnualJob{}},
+	"AnnualJobHandler.GetAllAnnualJobs": {Summary: "List annual jobs, or export them", Query: exportQuery,
+		Response: []models.AnnualJob{}, Files: []string{"text/csv", xlsxContentType}},
+	"AnnualJobHandler.GetAnnualJobByID": {Summary: "Get an annual job", Response: models.AnnualJob{}},
+	"AnnualJobHandler.UpdateAnnualJob": {Summary: "Update an annual job and upload the proof of work",
+		Form: formSchema(withFields(jobFormFields, map[string]*openapi.Schema{"job_year": {Type: "integer"}})), Response: models.AnnualJob{}},
+	"AnnualJobHandler.CreateAnnualTaxReport":      {Summary: "Add an SPT report to an annual job", Request: models.NewAnnualTaxReportRequest{}, Status: http.StatusCreated, Response: models.AnnualTaxReport{}},
+	"AnnualJobHandler.UpdateAnnualTaxReport":      {Summary: "Update an SPT report", Request: models.UpdateAnnualTaxReportRequest{}, Response: models.AnnualTaxReport{}},
+	"AnnualJobHandler.DeleteAnnualTaxReport":      {Summary: "Delete an SPT report", Status: http.StatusNoContent},
+	"AnnualJobHandler.CreateAnnualDividendReport": {Summary: "Add a dividend report to an annual job", Request: models.NewAnnualDividendReportRequest{}, Status: http.StatusCreated, Response: models.AnnualDividendReport{}},
+	"AnnualJobHandler.UpdateAnnualDividendReport": {Summary: "Update a dividend report", Request: models.UpdateAnnualDividendReportRequest{}, Response: models.AnnualDividendReport{}},
+	"AnnualJobHandler.DeleteAnnualDividendReport": {Summary: "Delete a dividend report", Status: http.StatusNoContent},
+
+	// Staff
+	"StaffHandler.CreateStaff":  {Summary: "Create a staff member", Request: models.NewStaffRequest{}, Status: http.StatusCreated, Response: models.Staff{}},
+	"StaffHandler.GetAllStaffs": {Summary: "List staff members", Response: []models.Staff{}},
+	"StaffHandler.GetStaffByID": {Summary: "Get a staff member", Response: models.Staff{}},
+	"StaffHandler.UpdateStaff":  {Summary: "Update a staff member", Request: models.UpdateStaffRequest{}, Response: models.Staff{}},
+	"StaffHandler.DeleteStaff":  {Summary: "Delete a staff member", Status: http.StatusNoContent},
+	"StaffHandler.ChangeStaffPassword": {Summary: "Set a temporary password for a staff member (admin)",
+		Request: struct {
+			NewPassword string `json:"new_password" binding:"required"`
+		}{}, Response: messageResponse},
+	"StaffHandler.UnlockStaff": {Summary: "Unlock a locked staff account (admin)", Response: messageResponse},
+
+	// SP2DK jobs
+	"Sp2dkJobHandler.CreateSp2dkJob": {Summary: "Create an SP2DK job", Request: models.NewSp2dkJobRequest{}, Status: http.StatusCreated, Response: models.Sp2dkJob{}},
+	"Sp2dkJobHandler.GetAllSp2dkJobs": {Summary: "List SP2DK jobs, or export them", Query: exportQuery,
+		Response: []models.Sp2dkJob{}, Files: []string{"text/csv", xlsxContentType}},
+	"Sp2dkJobHandler.GetSp2dkJobByID": {Summary: "Get an SP2DK job", Response: models.Sp2dkJob{}},
+	"Sp2dkJobHandler.UpdateSp2dkJob": {Summary: "Update an SP2DK job and upload the proof of work",
+		Form: formSchema(withFields(jobFormFields, map[string]*openapi.Schema{
+			"contract_no": {Type: "string"}, "sp2dk_no": {Type: "string"}, "bap2dk_no": {Type: "string"},
+			"contract_date": {Type: "string", Format: "date"}, "sp2dk_date": {Type: "string", Format: "date"}, "bap2dk_date": {Type: "string", Format: "date"},
+			"payment_date": {Type: "string", Format: "date"}, "report_date": {Type: "string", Format: "date"},
+		})), Response: models.Sp2dkJob{}},
+	"Sp2dkJobHandler.DeleteSp2dkJob": {Summary: "Delete an SP2DK job", Status: http.StatusNoContent},
+
+	// Pemeriksaan jobs
+	"PemeriksaanJobHandler.CreatePemeriksaanJob": {Summary: "Create a tax audit job", Request: models.NewPemeriksaanJobRequest{}, Status: http.StatusCreated, Response: models.PemeriksaanJob{}},
+	"PemeriksaanJobHandler.GetAllPemeriksaanJobs": {Summary: "List tax audit jobs, or export them", Query: exportQuery,
+		Response: []models.PemeriksaanJob{}, Files: []string{"text/csv", xlsxContentType}},
+	"PemeriksaanJobHandler.GetPemeriksaanJobByID": {Summary: "Get a tax audit job", Response: models.PemeriksaanJob{}},
+	"PemeriksaanJobHandler.UpdatePemeriksaanJob": {Summary: "Update a tax audit job and upload the proof of work",
+		Form: formSchema(withFields(jobFormFields, map[string]*openapi.Schema{
+			"contract_no": {Type: "string"}, "sp2_no": {Type: "string"}, "skp_no": {Type: "string"},
+			"contract_date": {Type: "string", Format: "date"}, "sp2_date": {Type: "string", Format: "date"}, "skp_date": {Type: "string", Format: "date"},
+		})), Response: models.PemeriksaanJob{}},
+	"PemeriksaanJobHandler.DeletePemeriksaanJob": {Summary: "Delete a tax audit job", Status: http.StatusNoContent},
+
+	// Invoices
+	"InvoiceHandler.CreateInvoice": {Summary: "Create an invoice", Request: models.Invoice{}, Status: http.StatusCreated, Response: models.Invoice{}},
+	"InvoiceHandler.GetAllInvoices": {Summary: "List invoices, or export them", Query: exportQuery,
+		Response: []models.Invoice{}, Files: []string{"text/csv", xlsxContentType}},
+	"InvoiceHandler.GetInvoiceByID":      {Summary: "Get an invoice", Response: models.Invoice{}},
+	"InvoiceHandler.GetInvoicePDF":       {Summary: "Invoice as PDF", Files: []string{"application/pdf"}},
+	"InvoiceHandler.UpdateInvoiceStatus": {Summary: "Change the status of an invoice", Request: models.UpdateInvoiceStatusRequest{}, Response: models.Invoice{}},
+
+	// Documents
+	"DocumentHandler.SearchDocuments": {Summary: "Full-text search in uploaded PDFs",
+		Query: []openapi.Parameter{
+			requiredQueryParam("q", &openapi.Schema{Type: "string", MinLength: intPtr(1)}, "Search terms"),
+			queryParam("client_id", &openapi.Schema{Type: "string"}, ""),
+			queryParam("job_type", &openapi.Schema{Type: "string"}, "Only documents of this job type"),
+			queryParam("limit", &openapi.Schema{Type: "integer"}, ""),
+		},
+		Response: []models.DocumentSearchResult{}},
+	"DocumentHandler.GetDocumentByID":   {Summary: "Get a document with its extracted text", Response: models.Document{}},
+	"DocumentHandler.DownloadDocument":  {Summary: "Download a document, including client portal uploads", Files: []string{"application/octet-stream"}},
+	"DocumentHandler.ReextractDocument": {Summary: "Extract the text of a document again", Status: http.StatusAccepted, Response: models.Document{}},
+
+	// Document requests
+	"DocumentRequestHandler.CreateDocumentRequests": {Summary: "Request documents from the client of a job",
+		Request: models.NewDocumentRequestsRequest{}, Status: http.StatusCreated, Response: []models.DocumentRequest{}},
+	"DocumentRequestHandler.GetDocumentRequests": {Summary: "List document requests",
+		Query: []openapi.Parameter{
+			queryParam("job_type", &openapi.Schema{Type: "string"}, ""),
+			queryParam("job_id", &openapi.Schema{Type: "string"}, ""),
+			queryParam("client_id", &openapi.Schema{Type: "string"}, ""),
+			queryParam("status", enumSchema(models.DocumentRequestStatusPending, models.DocumentRequestStatusUploaded,
+				models.DocumentRequestStatusAccepted, models.DocumentRequestStatusRejected), ""),
+			queryParam("outstanding", &openapi.Schema{Type: "boolean"}, "Only requests that are not accepted yet"),
+		},
+		Response: []models.DocumentRequest{}},
+	"DocumentRequestHandler.GetJobChecklist": {Summary: "Document checklist of a job",
+		Query: []openapi.Parameter{
+			requiredQueryParam("job_type", &openapi.Schema{Type: "string"}, ""),
+			requiredQueryParam("job_id", &openapi.Schema{Type: "string"}, ""),
+		},
+		Response: models.DocumentRequestChecklist{}},
+	"DocumentRequestHandler.GetDocumentRequestByID": {Summary: "Get a document request", Response: models.DocumentRequest{}},
+	"DocumentRequestHandler.UpdateDocumentRequest":  {Summary: "Update a document request", Request: models.UpdateDocumentRequestRequest{}, Response: models.DocumentRequest{}},
+	"DocumentRequestHandler.ReviewDocumentRequest":  {Summary: "Accept or reject an uploaded document", Request: models.ReviewDocumentRequestRequest{}, Response: models.DocumentRequest{}},
+	"DocumentRequestHandler.DeleteDocumentRequest":  {Summary: "Delete a document request", Response: messageResponse},
+
+	// Reports
+	"ReportHandler.GetWorkload": {Summary: "Open jobs per staff member",
+		Query: []openapi.Parameter{
+			queryParam("year", intSchema(2000, 9999), "Period year; without year and month the current period is used"),
+			queryParam("month", intSchema(1, 12), ""),
+		},
+		Response: models.WorkloadReport{}},
+	"ReportHandler.GetComplianceMatrix": {Summary: "Filing and payment status per client, tax type and month",
+		Query: []openapi.Parameter{
+			queryParam("year", intSchema(2000, 9999), "Defaults to the current year"),
+			queryParam("format", enumSchema("json", "xlsx"), ""),
+		},
+		Response: models.ComplianceMatrix{}, Files: []string{xlsxContentType}},
+	"ReportHandler.GetRevenue": {Summary: "Invoiced and paid revenue per month",
+		Query: []openapi.Parameter{
+			queryParam("year", intSchema(2000, 9999), "Defaults to the current year"),
+			queryParam("compare_year", intSchema(2000, 9999), "Year to compare with"),
+		},
+		Response: models.RevenueReport{}},
+
+	// Notifications
+	"NotificationHandler.GetMyPreferences":    {Summary: "Email preferences of the logged-in staff member", Response: models.NotificationPreferences{}},
+	"NotificationHandler.UpdateMyPreferences": {Summary: "Update the email preferences", Request: models.UpdateNotificationPreferencesRequest{}, Response: models.NotificationPreferences{}},
+	"NotificationHandler.RunDaily":            {Summary: "Send the daily emails now (admin)", Response: messageResponse},
+	"NotificationHandler.GetMyNotifications": {Summary: "In-app notifications, newest first",
+		Query: []openapi.Parameter{
+			queryParam("limit", intSchema(1, 100), ""),
+			queryParam("before", &openapi.Schema{Type: "string", Format: "date-time"}, "Only notifications created before this time"),
+			queryParam("unread", &openapi.Schema{Type: "boolean"}, "Only unread notifications"),
+		},
+		Response: models.NotificationInbox{}},
+	"NotificationHandler.StreamNotifications": {Summary: "Stream new notifications (Server-Sent Events)", Files: []string{"text/event-stream"}},
+	"NotificationHandler.MarkAllNotificationsRead": {Summary: "Mark all notifications as read",
+		Response: struct {
+			Message string `json:"message"`
+			Updated int64  `json:"updated"`
+		}{}},
+	"NotificationHandler.MarkNotificationRead": {Summary: "Mark a notification as read", Response: models.Notification{}},
+
+	// Webhooks
+	"WebhookHandler.CreateSubscription":  {Summary: "Subscribe a URL to events", Request: models.NewWebhookSubscriptionRequest{}, Status: http.StatusCreated, Response: models.WebhookSubscription{}},
+	"WebhookHandler.GetAllSubscriptions": {Summary: "List webhook subscriptions", Response: []models.WebhookSubscription{}},
+	"WebhookHandler.GetSubscriptionByID": {Summary: "Get a webhook subscription", Response: models.WebhookSubscription{}},
+	"WebhookHandler.UpdateSubscription":  {Summary: "Update a webhook subscription", Request: models.UpdateWebhookSubscriptionRequest{}, Response: models.WebhookSubscription{}},
+	"WebhookHandler.DeleteSubscription":  {Summary: "Delete a webhook subscription", Response: messageResponse},
+	"WebhookHandler.GetSubscriptionDeliveries": {Summary: "Deliveries of a subscription",
+		Query: deliveryQuery(false), Response: []models.WebhookDelivery{}},
+	"WebhookHandler.GetDeliveries": {Summary: "List webhook deliveries",
+		Query: deliveryQuery(true), Response: []models.WebhookDelivery{}},
+	"WebhookHandler.GetDeliveryByID":   {Summary: "Get a webhook delivery", Response: models.WebhookDelivery{}},
+	"WebhookHandler.RedeliverDelivery": {Summary: "Send a delivery again", Status: http.StatusAccepted, Response: models.WebhookDelivery{}},
+
+	// Audit log
+	"AuditHandler.GetAuditLog": {Summary: "Domain events, newest first (admin)",
+		Query: []openapi.Parameter{
+			queryParam("aggregate_type", &openapi.Schema{Type: "string"}, ""),
+			queryParam("aggregate_id", &openapi.Schema{Type: "string"}, ""),
+			queryParam("limit", intSchema(1, 200), ""),
+			queryParam("before", &openapi.Schema{Type: "string", Format: "date-time"}, "Only events before this time"),
+		},
+		Response: []models.AuditEntry{}},
+}
+
+// buildOpenAPI describes the /api/v1 routes of the router. Routes missing in operationDocs
+// are still listed, with their path parameters only, and logged as a warning.
+func buildOpenAPI(routes gin.RoutesInfo) *openapi.Document {
+	gen := openapi.NewGenerator()
+	gen.Register(models.CustomDate{}, &openapi.Schema{Type: "string", Format: "date", Nullable: true})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Dashboard Pekerjaan API",
+			Description: "API of the tax consulting job dashboard. Staff endpoints use the token from /auth/login, client portal endpoints the token from /portal/auth/login.",
+			Version:     "1.0",
+		},
+		Paths: map[string]openapi.PathItem{},
+	}
+
+	// operationId memakai nama method handler, diberi awalan handler jika namanya dipakai lebih dari sekali
+	methodCount := map[string]int{}
+	for _, route := range routes {
+		if _, method, ok := handlerName(route.Handler); ok {
+			methodCount[method]++
+		}
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, apiPrefix+"/") {
+			continue
+		}
+		path := openapi.PathTemplate(route.Path)
+		key := route.Handler
+		operationID := ""
+		if handler, method, ok := handlerName(route.Handler); ok {
+			key = handler + "." + method
+			operationID = lowerFirst(method)
+			if methodCount[method] > 1 {
+				operationID = lowerFirst(strings.TrimSuffix(handler, "Handler")) + method
+			}
+		}
+		d, documented := operationDocs[key]
+		if !documented {
+			slog.Warn("Route belum ada di dokumentasi OpenAPI", "method", route.Method, "path", route.Path, "handler", key)
+		}
+
+		tag := strings.SplitN(strings.TrimPrefix(route.Path, apiPrefix+"/"), "/", 2)[0]
+		tags[tag] = true
+		op := &openapi.Operation{
+			Tags:        []string{tag},
+			Summary:     d.Summary,
+			OperationID: operationID,
+			Responses:   map[string]*openapi.Response{},
+		}
+		for _, name := range pathParams(path) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+		}
+		op.Parameters = append(op.Parameters, d.Query...)
+
+		switch {
+		case d.Request != nil:
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				"application/json": {Schema: gen.Schema(d.Request)},
+			}}
+		case d.Form != nil:
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				"multipart/form-data": {Schema: d.Form},
+			}}
+		}
+
+		status := d.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &openapi.Response{Description: http.StatusText(status)}
+		if d.Response != nil || len(d.Files) > 0 {
+			success.Content = map[string]openapi.MediaType{}
+		}
+		if d.Response != nil {
+			success.Content["application/json"] = openapi.MediaType{Schema: gen.Schema(d.Response)}
+		}
+		for _, contentType := range d.Files {
+			schema := &openapi.Schema{Type: "string", Format: "binary"}
+			if strings.HasPrefix(contentType, "text/") {
+				schema = &openapi.Schema{Type: "string"}
+			}
+			success.Content[contentType] = openapi.MediaType{Schema: schema}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		addError := func(status int, description string) {
+			op.Responses[strconv.Itoa(status)] = &openapi.Response{Description: description, Content: map[string]openapi.MediaType{
+				"application/json": {Schema: openapi.RefSchema("Error")},
+			}}
+		}
+		if op.RequestBody != nil || len(op.Parameters) > 0 {
+			addError(http.StatusBadRequest, "Invalid request")
+		}
+		if d.Public {
+			addError(http.StatusTooManyRequests, "Too many attempts, see Retry-After")
+		} else {
+			addError(http.StatusUnauthorized, "Missing or invalid token")
+			addError(http.StatusForbidden, "Not allowed for this user")
+			scheme := "bearerAuth"
+			if strings.HasPrefix(route.Path, apiPrefix+"/portal/") {
+				scheme = "portalAuth"
+			}
+			op.Security = []openapi.SecurityRequirement{{scheme: {}}}
+		}
+		if len(pathParams(path)) > 0 {
+			addError(http.StatusNotFound, "Not found")
+		}
+		addError(http.StatusInternalServerError, "Internal error")
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, openapi.Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	schemas := gen.Components()
+	schemas["Error"] = &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	}
+	doc.Components = &openapi.Components{
+		Schemas: schemas,
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Staff token from /auth/login"},
+			"portalAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Client portal token from /portal/auth/login"},
+		},
+	}
+	return doc
+}
+
+// handlerMethodPattern mencocokkan nama method value, mis.
+// "github.com/.../internal/handlers.(*ClientHandler).CreateClient-fm"
+var handlerMethodPattern = regexp.MustCompile(`\.\(\*(\w+)\)\.(\w+)-fm$`)
+
+// handlerName splits the function name of a route handler into handler type and method
+func handlerName(function string) (handler, method string, ok bool) {
+	m := handlerMethodPattern.FindStringSubmatch(function)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// pathParams returns the names of the {param} segments of an OpenAPI path
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, segment[1:len(segment)-1])
+		}
+	}
+	return names
+}
+
+func queryParam(name string, schema *openapi.Schema, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func requiredQueryParam(name string, schema *openapi.Schema, description string) openapi.Parameter {
+	p := queryParam(name, schema, description)
+	p.Required = true
+	return p
+}
+
+// deliveryQuery is the filter of the webhook delivery lists
+func deliveryQuery(withSubscription bool) []openapi.Parameter {
+	params := []openapi.Parameter{
+		queryParam("status", enumSchema(models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed), ""),
+		queryParam("limit", intSchema(1, 200), ""),
+	}
+	if withSubscription {
+		params = append(params, queryParam("subscription_id", &openapi.Schema{Type: "string"}, ""))
+	}
+	return params
+}
+
+func enumSchema(values ...string) *openapi.Schema {
+	s := &openapi.Schema{Type: "string"}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+func intSchema(min, max float64) *openapi.Schema {
+	return &openapi.Schema{Type: "integer", Minimum: &min, Maximum: &max}
+}
+
+func intPtr(n int) *int { return &n }
+
+// formSchema is a multipart/form-data body with the given fields
+func formSchema(fields map[string]*openapi.Schema, required ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "object", Properties: fields, Required: required}
+}
+
+// withFields returns the fields of base and extra together
+func withFields(base, extra map[string]*openapi.Schema) map[string]*openapi.Schema {
+	fields := make(map[string]*openapi.Schema, len(base)+len(extra))
+	for k, v := range base {
+		fields[k] = v
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -1,28 +1,127 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/config"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/handlers"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/middlewares"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/services"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/mailer"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/metrics"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/openapi"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/password"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/ratelimit"
 )
 
-// SetupRouter sets up all application routes
-func SetupRouter(db *sql.DB, cfg *config.Config) *gin.Engine {
-	r := gin.Default()
+// businessMetricsTTL adalah lama cache gauge bisnis di /metrics
+const businessMetricsTTL = 30 * time.Second
 
-	// 1. Initialize Repositories
-clientRepo := repositories.NewClientRepository(db)
-	monthlyJobRepo := repositories.NewMonthlyJobRepository(db)
-	staffRepo := repositories.NewStaffRepository(db)
-	annualJobRepo := repositories.NewAnnualJobRepository(db)
-	sp2dkJobRepo := repositories.NewSp2dkJobRepository(db)
-	pemeriksaanJobRepo := repositories.NewPemeriksaanJobRepository(db)
-	invoiceRepo := repositories.NewInvoiceRepository(db)
+// App is the router together with the parts that must be stopped on shutdown
+type App struct {
+	Router *gin.Engine
+	Health *handlers.HealthHandler
+
+	realtime services.RealtimeService
+	workers  []services.Worker // Sesuai urutan Start
+}
+
+// BeginShutdown marks the instance as not ready and ends the open notification streams, so
+// they do not hold the HTTP server open while it drains.
+func (a *App) BeginShutdown(ctx context.Context) {
+	a.Health.SetDraining()
+	if err := a.realtime.Stop(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Gagal menghentikan realtime service", "error", err)
+	}
+}
+
+// StopWorkers stops the background workers in reverse start order, so the outbox dispatcher
+// stops before the services it feeds. Call it after the HTTP server has drained and before
+// the database is closed.
+func (a *App) StopWorkers(ctx context.Context) error {
+	var errs []error
+	for i := len(a.workers) - 1; i >= 0; i-- {
+		if err := a.workers[i].Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("worker %T: %w", a.workers[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetupRouter sets up all application routes and starts the background workers
+func SetupRouter(db *sql.DB, cfg *config.Config) *App {
+	r := gin.New()
+	// IP klien (untuk log dan rate limiter) hanya diambil dari X-Forwarded-For proxy tepercaya
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
+	// Folder upload dibuat sekali di sini, bukan di setiap handler upload
+	if err := os.MkdirAll(cfg.Storage.UploadDir, 0o755); err != nil {
+		log.Fatalf("Failed to create upload directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.Storage.PortalUploadDir, 0o750); err != nil {
+		log.Fatalf("Failed to create portal upload directory: %v", err)
+	}
+
+	// Probe didaftarkan sebelum middleware agar tidak memenuhi log akses dan metrics
+	healthHandler := handlers.NewHealthHandler(repositories.NewHealthRepository(db), cfg.Storage.UploadDir)
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+
+	// Tanpa gin.Default(): log akses dan recovery ditulis sebagai log terstruktur
+	r.Use(middlewares.RequestLogger(), middlewares.Recovery())
+	r.Use(middlewares.SecurityHeaders(), middlewares.CORS(cfg.CORS.AllowedOrigins, cfg.CORS.MaxAge))
+
+	// 0. Metrics: durasi request HTTP dan panggilan repository, pool koneksi DB dan KPI bisnis
+	metricsRegistry := metrics.NewRegistry()
+	httpDuration := metrics.NewHistogramVec("dashboard_http_request_duration_seconds",
+		"Duration of HTTP requests by method, route and status.", nil, "method", "route", "status")
+	repoDuration := metrics.NewHistogramVec("dashboard_repository_call_duration_seconds",
+		"Duration of repository calls by repository, method and result.", services.RepositoryBuckets, "repository", "method", "result")
+	metricsRegistry.Register(httpDuration, repoDuration, metrics.NewDBStatsCollector(db))
+	r.Use(middlewares.HTTPMetrics(httpDuration))
+	// Setiap panggilan repository dibatasi statement timeout, dicatat ke metrics, dan yang
+	// lambat ditulis ke log bersama request_id-nya
+	instrumentation := &repositories.Instrumentation{
+		Observe:          services.RepositoryObserver(repoDuration),
+		StatementTimeout: cfg.Database.StatementTimeout,
+		Trace:            []repositories.TraceFunc{services.SlowRepositoryLogger(cfg.Database.SlowQueryThreshold)},
+	}
+
+	// 1. Initialize Repositories (dibungkus decorator dari instrumented_gen.go)
+	clientRepo := repositories.InstrumentClientRepository(repositories.NewClientRepository(db), instrumentation)
+	monthlyJobRepo := repositories.InstrumentMonthlyJobRepository(repositories.NewMonthlyJobRepository(db), instrumentation)
+	staffRepo := repositories.InstrumentStaffRepository(repositories.NewStaffRepository(db), instrumentation)
+	annualJobRepo := repositories.InstrumentAnnualJobRepository(repositories.NewAnnualJobRepository(db), instrumentation)
+	sp2dkJobRepo := repositories.InstrumentSp2dkJobRepository(repositories.NewSp2dkJobRepository(db), instrumentation)
+	pemeriksaanJobRepo := repositories.InstrumentPemeriksaanJobRepository(repositories.NewPemeriksaanJobRepository(db), instrumentation)
+	invoiceRepo := repositories.InstrumentInvoiceRepository(repositories.NewInvoiceRepository(db), instrumentation)
+	documentRepo := repositories.InstrumentDocumentRepository(repositories.NewDocumentRepository(db), instrumentation)
+	reportRepo := repositories.InstrumentReportRepository(repositories.NewReportRepository(db), instrumentation)
+	notificationRepo := repositories.InstrumentNotificationRepository(repositories.NewNotificationRepository(db), instrumentation)
+	webhookRepo := repositories.InstrumentWebhookRepository(repositories.NewWebhookRepository(db), instrumentation)
+	outboxRepo := repositories.InstrumentOutboxRepository(repositories.NewOutboxRepository(db), instrumentation)
+	auditRepo := repositories.InstrumentAuditRepository(repositories.NewAuditRepository(db), instrumentation)
+	clientUserRepo := repositories.InstrumentClientUserRepository(repositories.NewClientUserRepository(db), instrumentation)
+	portalRepo := repositories.InstrumentPortalRepository(repositories.NewPortalRepository(db), instrumentation)
+	documentRequestRepo := repositories.InstrumentDocumentRequestRepository(repositories.NewDocumentRequestRepository(db), instrumentation)
+	metricsRepo := repositories.InstrumentMetricsRepository(repositories.NewMetricsRepository(db), instrumentation)
+	passwordResetRepo := repositories.InstrumentPasswordResetRepository(repositories.NewPasswordResetRepository(db), instrumentation)
+	twoFactorRepo := repositories.InstrumentTwoFactorRepository(repositories.NewTwoFactorRepository(db), instrumentation)
+	taxImportRepo := repositories.InstrumentTaxImportRepository(repositories.NewTaxImportRepository(db), instrumentation)
+	metricsRegistry.Register(services.NewBusinessMetricsCollector(metricsRepo, businessMetricsTTL))
 
 		invoiceService := services.NewInvoiceService(
 		invoiceRepo,
@@ -31,27 +130,151 @@ clientRepo := repositories.NewClientRepository(db)
 		sp2dkJobRepo,
 		pemeriksaanJobRepo,
 	)
+	documentService := services.NewDocumentService(documentRepo, cfg.Storage.UploadDir, cfg.Storage.PortalUploadDir)
+	documentService.Start() // Worker ekstraksi teks PDF di background
+	taxImportService := services.NewTaxImportService(monthlyJobRepo, taxImportRepo)
+	clientImportService := services.NewClientImportService(clientRepo, staffRepo)
+	reportService := services.NewReportService(clientRepo, reportRepo)
+
+	// Tanpa SMTP_HOST, email notifikasi hanya dicatat ke log
+	emailSender := mailer.NewLogMailer()
+	if cfg.SMTP.Host != "" {
+		smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure SMTP mailer: %v", err)
+		}
+		emailSender = smtpMailer
+	}
+	// Kebijakan password staf: daftar password umum bawaan ditambah daftar breached dari file
+	passwordPolicy, err := password.NewPolicy(cfg.Password.MinLength, cfg.Password.MinClasses, cfg.Password.BreachedListFile)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	slog.Info("Kebijakan password aktif", "breached_passwords", passwordPolicy.BreachedCount())
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, cfg.Auth.TOTPIssuer, cfg.Auth.TOTPRequiredRoles)
+	accountService := services.NewAccountService(staffRepo, passwordResetRepo, twoFactorService, passwordPolicy, emailSender, services.AccountConfig{
+		AppURL:           cfg.Server.BaseURL,
+		LockoutThreshold: cfg.Password.LockoutThreshold,
+		LockoutDuration:  cfg.Password.LockoutDuration,
+		ResetTokenTTL:    cfg.Password.ResetTokenTTL,
+	})
+	notificationService := services.NewNotificationService(notificationRepo, staffRepo, documentRequestRepo, emailSender, cfg.Server.BaseURL, cfg.Notification.DigestHour)
+	notificationService.Start() // Pengingat batas lapor, invoice jatuh tempo dan ringkasan harian
+	realtimeService := services.NewRealtimeService(cfg.Database.URL)
+	realtimeService.Start() // LISTEN/NOTIFY untuk stream notifikasi ke dashboard
+	webhookService := services.NewWebhookService(webhookRepo)
+	webhookService.Start() // Worker pengiriman webhook dengan retry
+
+	// Domain event dari outbox diteruskan ke subscriber setelah perubahan datanya tersimpan
+	eventDispatcher := services.NewEventDispatcher(outboxRepo, cfg.Database.URL)
+	eventDispatcher.Subscribe("invoicing", invoiceService.HandleJobCompleted, models.DomainEventJobCompleted)
+	eventDispatcher.Subscribe("notifications", notificationService.HandleDomainEvent, models.DomainEventJobReassigned, models.DomainEventJobStatusChanged)
+	eventDispatcher.Subscribe("webhooks", webhookService.HandleDomainEvent, models.DomainEventJobStatusChanged,
+		models.DomainEventInvoiceIssued, models.DomainEventInvoicePaid, models.DomainEventClientCreated)
+	eventDispatcher.Subscribe("audit", services.NewAuditSubscriber(auditRepo), models.DomainEventTypes...)
+	eventDispatcher.Start()
+	workers := []services.Worker{documentService, notificationService, realtimeService, webhookService, eventDispatcher}
+
+	// Rate limiter login: store Postgres dipakai bersama semua instance, memory per instance
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimitRepo := repositories.InstrumentRateLimitRepository(repositories.NewRateLimitRepository(db), instrumentation)
+		rateLimitStore = rateLimitRepo
+		rateLimitJanitor := services.NewRateLimitJanitor(rateLimitRepo)
+		rateLimitJanitor.Start() // Hapus bucket yang sudah penuh kembali
+		workers = append(workers, rateLimitJanitor)
+	}
+	loginIPLimit := ratelimit.Limit{Burst: cfg.RateLimit.LoginIPBurst, Refill: cfg.RateLimit.LoginIPRefill}
+	loginEmailLimit := ratelimit.Limit{Burst: cfg.RateLimit.LoginEmailBurst, Refill: cfg.RateLimit.LoginEmailRefill}
+	loginRateLimit := func(scope string) []gin.HandlerFunc {
+		return []gin.HandlerFunc{
+			middlewares.RateLimit(rateLimitStore, loginIPLimit, middlewares.ClientIPKey(scope)),
+			middlewares.RateLimit(rateLimitStore, loginEmailLimit, middlewares.JSONEmailKey(scope)),
+		}
+	}
 
 	// 2. Initialize Handlers
 	clientHandler := handlers.NewClientHandler(clientRepo, staffRepo, monthlyJobRepo, annualJobRepo, sp2dkJobRepo, pemeriksaanJobRepo)
-	staffHandler := handlers.NewStaffHandler(staffRepo)
-	authHandler := handlers.NewAuthHandler(staffRepo, cfg.JWTSecretKey)
-	monthlyJobHandler := handlers.NewMonthlyJobHandler(monthlyJobRepo, clientRepo, staffRepo, invoiceService)
-	annualJobHandler := handlers.NewAnnualJobHandler(annualJobRepo, clientRepo, staffRepo, invoiceService)
-	sp2dkJobHandler := handlers.NewSp2dkJobHandler(sp2dkJobRepo, clientRepo, staffRepo, invoiceService)
-	pemeriksaanJobHandler := handlers.NewPemeriksaanJobHandler(pemeriksaanJobRepo, clientRepo, staffRepo, invoiceService)
+	staffHandler := handlers.NewStaffHandler(staffRepo, accountService)
+	authHandler := handlers.NewAuthHandler(staffRepo, accountService, twoFactorService, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL, cfg.Auth.MFAChallengeTTL)
+	twoFactorHandler := handlers.NewTwoFactorHandler(staffRepo, twoFactorService, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	monthlyJobHandler := handlers.NewMonthlyJobHandler(monthlyJobRepo, clientRepo, staffRepo, invoiceService, documentService)
+	annualJobHandler := handlers.NewAnnualJobHandler(annualJobRepo, clientRepo, staffRepo, invoiceService, documentService)
+	sp2dkJobHandler := handlers.NewSp2dkJobHandler(sp2dkJobRepo, clientRepo, staffRepo, invoiceService, documentService)
+	pemeriksaanJobHandler := handlers.NewPemeriksaanJobHandler(pemeriksaanJobRepo, clientRepo, staffRepo, invoiceService, documentService)
 	invoiceHandler := handlers.NewInvoiceHandler(invoiceRepo)
+	documentHandler := handlers.NewDocumentHandler(documentRepo, documentService)
+	taxImportHandler := handlers.NewTaxImportHandler(monthlyJobRepo, taxImportService)
+	clientImportHandler := handlers.NewClientImportHandler(clientImportService)
+	reportHandler := handlers.NewReportHandler(reportRepo, reportService)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, notificationService, realtimeService)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo, webhookService)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	clientUserHandler := handlers.NewClientUserHandler(clientUserRepo, clientRepo)
+	portalHandler := handlers.NewPortalHandler(clientUserRepo, portalRepo, invoiceRepo, documentRepo, documentRequestRepo, documentService, cfg.Auth.JWTSecret, cfg.Auth.PortalTokenTTL)
+	documentRequestHandler := handlers.NewDocumentRequestHandler(documentRequestRepo)
 
 	// 3. Initialize Middleware
 	authMiddleware := middlewares.NewAuthMiddleware(cfg)
+	// Dokumen OpenAPI baru diisi setelah semua route terdaftar (langkah 5)
+	apiDoc := new(openapi.Document)
 
 	// 4. Setup Route Groups
+	// /metrics hanya aktif jika METRICS_TOKEN diisi, dan dilindungi token tersebut
+	if cfg.Metrics.Token != "" {
+		r.GET("/metrics", middlewares.MetricsTokenRequired(cfg.Metrics.Token), gin.WrapH(metricsRegistry.Handler()))
+	}
+
 	v1 := r.Group("/api/v1")
+	if cfg.API.ValidateRequests {
+		v1.Use(middlewares.ValidateRequest(apiDoc, int64(cfg.API.MaxJSONBody)))
+	}
 	{
 		// Auth routes (Public)
 		authRoutes := v1.Group("/auth")
 		{
-			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/login", append(loginRateLimit("login"), authHandler.Login)...)
+			authRoutes.POST("/login/verify", append(loginRateLimit("login-verify"), authHandler.LoginVerify)...) // Langkah kedua: kode 2FA
+			authRoutes.POST("/forgot-password", append(loginRateLimit("forgot-password"), authHandler.ForgotPassword)...)
+			authRoutes.POST("/reset-password", append(loginRateLimit("reset-password"), authHandler.ResetPassword)...)
+			// Penyiapan akun: juga menerima token yang masih harus ganti password atau enrollment 2FA
+			accountSetupRoutes := authRoutes.Group("/")
+			accountSetupRoutes.Use(authMiddleware.AuthRequiredForAccountSetup())
+			{
+				accountSetupRoutes.POST("/change-password", authHandler.ChangePassword)
+				accountSetupRoutes.GET("/2fa", twoFactorHandler.GetStatus)
+				accountSetupRoutes.POST("/2fa/setup", twoFactorHandler.BeginSetup)   // Secret baru dan otpauth:// untuk QR code
+				accountSetupRoutes.POST("/2fa/enable", twoFactorHandler.ConfirmSetup) // Konfirmasi dengan kode pertama, kembalikan kode cadangan
+				accountSetupRoutes.POST("/2fa/disable", twoFactorHandler.Disable)
+				accountSetupRoutes.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			}
+		}
+
+		// Portal klien (read-only), dengan login dan audience token sendiri
+		portalRoutes := v1.Group("/portal")
+		{
+			portalRoutes.POST("/auth/login", append(loginRateLimit("portal-login"), portalHandler.Login)...)
+
+			portalProtected := portalRoutes.Group("/")
+			portalProtected.Use(authMiddleware.ClientPortalRequired())
+			{
+				portalProtected.GET("/me", portalHandler.GetMe)
+				portalProtected.GET("/jobs", portalHandler.GetJobs)
+				portalProtected.GET("/tax-payments", portalHandler.GetTaxPayments)
+				portalProtected.GET("/invoices", portalHandler.GetInvoices)
+				portalProtected.GET("/invoices/:id", portalHandler.GetInvoiceByID)
+				portalProtected.GET("/invoices/:id/pdf", portalHandler.GetInvoicePDF)
+				portalProtected.GET("/documents", portalHandler.GetDocuments)
+				portalProtected.GET("/documents/:id/download", portalHandler.DownloadDocument)
+				portalProtected.GET("/document-requests", portalHandler.GetDocumentRequests)
+				portalProtected.POST("/document-requests/:id/upload", portalHandler.UploadDocumentRequest) // Satu-satunya endpoint tulis portal
+			}
 		}
 
 		// Protected Routes Group
@@ -62,15 +285,18 @@ clientRepo := repositories.NewClientRepository(db)
 			clientRoutes := protected.Group("/clients")
 			{
 				clientRoutes.POST("/", clientHandler.CreateClient)
+				clientRoutes.POST("/import", clientImportHandler.ImportClients) // Onboarding klien massal dari CSV/XLSX
 				clientRoutes.GET("/", clientHandler.GetAllClients)
 				clientRoutes.GET("/:id", clientHandler.GetClientByID)
 				clientRoutes.GET("/:id/all-jobs", clientHandler.GetClientDashboardJobs)
 				clientRoutes.PATCH("/:id", clientHandler.UpdateClient)
 				clientRoutes.DELETE("/:id", clientHandler.DeleteClient)
+				clientRoutes.GET("/:id/portal-users", clientUserHandler.GetClientUsers) // Login portal klien (admin)
+				clientRoutes.POST("/:id/portal-users", clientUserHandler.CreateClientUser)
 			}
 
 			// Static route for file uploads
-			r.Static("/uploads", "./uploads")
+			r.Static("/uploads", cfg.Storage.UploadDir)
 
 			// Monthly Job routes
 			monthlyJobRoutes := protected.Group("/monthly-jobs")
@@ -79,6 +305,7 @@ clientRepo := repositories.NewClientRepository(db)
 				monthlyJobRoutes.GET("/", monthlyJobHandler.GetAllMonthlyJobs)
 				monthlyJobRoutes.GET("/:id", monthlyJobHandler.GetMonthlyJobByID)
 				monthlyJobRoutes.PATCH("/:id", monthlyJobHandler.UpdateMonthlyJob)
+				monthlyJobRoutes.POST("/:id/imports", taxImportHandler.ImportMonthlyJobFiles) // Impor ekspor e-Bupot/e-Faktur
 
 				taxReportRoutes := monthlyJobRoutes.Group("/:id/tax-reports")
 				{
@@ -119,7 +346,9 @@ clientRepo := repositories.NewClientRepository(db)
 				staffRoutes.GET("/:id", staffHandler.GetStaffByID)
 				staffRoutes.PATCH("/:id", staffHandler.UpdateStaff)
 				staffRoutes.DELETE("/:id", staffHandler.DeleteStaff)
-				staffRoutes.PATCH("/:id/password", staffHandler.ChangeStaffPassword)
+				staffRoutes.PATCH("/:id/password", staffHandler.ChangeStaffPassword) // Password sementara, wajib diganti saat login
+				staffRoutes.POST("/:id/unlock", staffHandler.UnlockStaff)            // Buka kunci akun setelah login gagal berulang
+				staffRoutes.DELETE("/:id/2fa", twoFactorHandler.ResetStaffTwoFactor) // Admin: staf kehilangan authenticator
 			}
 
 			// SP2DK Job routes
@@ -148,9 +377,100 @@ clientRepo := repositories.NewClientRepository(db)
 				invoiceRoutes.POST("/", invoiceHandler.CreateInvoice)
 				invoiceRoutes.GET("/", invoiceHandler.GetAllInvoices)
 				invoiceRoutes.GET("/:id", invoiceHandler.GetInvoiceByID)
+				invoiceRoutes.GET("/:id/pdf", invoiceHandler.GetInvoicePDF)
+				invoiceRoutes.PATCH("/:id/status", invoiceHandler.UpdateInvoiceStatus) // Paid memicu webhook invoice.paid
+			}
+
+			// Document routes (hasil ekstraksi teks PDF upload)
+			documentRoutes := protected.Group("/documents")
+			{
+				documentRoutes.GET("/search", documentHandler.SearchDocuments)
+				documentRoutes.GET("/:id", documentHandler.GetDocumentByID)
+				documentRoutes.GET("/:id/download", documentHandler.DownloadDocument) // Termasuk upload portal yang tidak ada di /uploads
+				documentRoutes.POST("/:id/reextract", documentHandler.ReextractDocument)
+			}
+
+			// Document request routes (checklist dokumen yang diminta dari klien per pekerjaan)
+			documentRequestRoutes := protected.Group("/document-requests")
+			{
+				documentRequestRoutes.POST("/", documentRequestHandler.CreateDocumentRequests)
+				documentRequestRoutes.GET("/", documentRequestHandler.GetDocumentRequests)
+				documentRequestRoutes.GET("/checklist", documentRequestHandler.GetJobChecklist) // ?job_type=&job_id=, dokumen yang masih kurang
+				documentRequestRoutes.GET("/:id", documentRequestHandler.GetDocumentRequestByID)
+				documentRequestRoutes.PATCH("/:id", documentRequestHandler.UpdateDocumentRequest)
+				documentRequestRoutes.PATCH("/:id/review", documentRequestHandler.ReviewDocumentRequest)
+				documentRequestRoutes.DELETE("/:id", documentRequestHandler.DeleteDocumentRequest)
+			}
+
+			// Report routes (dashboard manajer)
+			reportRoutes := protected.Group("/reports")
+			{
+				reportRoutes.GET("/workload", reportHandler.GetWorkload)
+				reportRoutes.GET("/compliance-matrix", reportHandler.GetComplianceMatrix) // ?format=xlsx untuk unduhan Excel
+				reportRoutes.GET("/revenue", reportHandler.GetRevenue)
+			}
+
+			// Notification routes (preferensi email staf yang login)
+			notificationRoutes := protected.Group("/notifications")
+			{
+				notificationRoutes.GET("/preferences", notificationHandler.GetMyPreferences)
+				notificationRoutes.PATCH("/preferences", notificationHandler.UpdateMyPreferences)
+				notificationRoutes.POST("/run-daily", notificationHandler.RunDaily) // Admin: kirim email harian sekarang
+			}
+
+			// Kotak masuk notifikasi in-app staf yang login
+			meRoutes := protected.Group("/me")
+			{
+				meRoutes.GET("/notifications", notificationHandler.GetMyNotifications)
+				meRoutes.GET("/notifications/stream", notificationHandler.StreamNotifications) // Server-Sent Events
+				meRoutes.POST("/notifications/read-all", notificationHandler.MarkAllNotificationsRead)
+				meRoutes.PATCH("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+			}
+
+			// Webhook routes (admin): langganan event untuk sistem luar dan log pengirimannya
+			webhookRoutes := protected.Group("/webhooks")
+			{
+				webhookRoutes.POST("/", webhookHandler.CreateSubscription)
+				webhookRoutes.GET("/", webhookHandler.GetAllSubscriptions)
+				webhookRoutes.GET("/:id", webhookHandler.GetSubscriptionByID)
+				webhookRoutes.PATCH("/:id", webhookHandler.UpdateSubscription)
+				webhookRoutes.DELETE("/:id", webhookHandler.DeleteSubscription)
+				webhookRoutes.GET("/:id/deliveries", webhookHandler.GetSubscriptionDeliveries)
+			}
+			webhookDeliveryRoutes := protected.Group("/webhook-deliveries")
+			{
+				webhookDeliveryRoutes.GET("/", webhookHandler.GetDeliveries)
+				webhookDeliveryRoutes.GET("/:id", webhookHandler.GetDeliveryByID)
+				webhookDeliveryRoutes.POST("/:id/redeliver", webhookHandler.RedeliverDelivery)
+			}
+
+			// Audit log dari domain event (admin)
+			protected.GET("/audit-log", auditHandler.GetAuditLog)
+
+			portalUserRoutes := protected.Group("/portal-users")
+			{
+				portalUserRoutes.PATCH("/:id", clientUserHandler.UpdateClientUser)
+				portalUserRoutes.DELETE("/:id", clientUserHandler.DeleteClientUser)
 			}
 		}
 	}
 
-	return r
+	// 5. OpenAPI: dibuat dari tabel route di atas, lalu dilayani bersama Swagger UI
+	*apiDoc = *buildOpenAPI(r.Routes())
+	if cfg.API.Docs {
+		openAPIHandler, err := handlers.NewOpenAPIHandler(apiDoc)
+		if err != nil {
+			log.Fatalf("Failed to encode OpenAPI document: %v", err)
+		}
+		r.GET("/api/v1/openapi.json", openAPIHandler.GetSpec)
+		r.GET("/api/v1/docs", openAPIHandler.SwaggerUI)
+		r.GET("/api/v1/docs/:asset", openAPIHandler.SwaggerUIAsset)
+	}
+
+	return &App{
+		Router:   r,
+		Health:   healthHandler,
+		realtime: realtimeService,
+		workers:  workers,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/mailer"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/password"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("email atau password salah")
+	ErrInvalidResetToken  = errors.New("token reset password tidak valid atau sudah kedaluwarsa")
+	ErrPasswordReused     = errors.New("password baru harus berbeda dari password saat ini")
+)
+
+// AccountLockedError dikembalikan Authenticate selama akun terkunci karena login gagal berulang.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("akun terkunci sampai %s", e.Until.Format(time.RFC3339))
+}
+
+// AccountService mengatur password staf: login dengan penguncian akun, kebijakan password,
+// ganti password sendiri, password sementara dari admin, dan reset password lewat email.
+// Kesalahan kebijakan password dikembalikan sebagai *password.PolicyError.
+type AccountService interface {
+	// Authenticate memeriksa email dan password. Hasilnya ErrInvalidCredentials, atau
+	// *AccountLockedError jika akun sedang atau baru saja dikunci. Untuk staf dengan 2FA aktif
+	// login belum selesai: lanjutkan dengan VerifySecondFactor.
+	Authenticate(ctx context.Context, email, plainPassword string) (*models.Staff, error)
+	// VerifySecondFactor adalah langkah kedua login: kode TOTP atau kode cadangan. Kode yang
+	// salah dihitung sebagai login gagal untuk penguncian akun.
+	VerifySecondFactor(ctx context.Context, staffID, code string) (*models.Staff, error)
+	// ValidatePassword memeriksa password terhadap kebijakan; personal adalah email dan nama pemilik akun.
+	ValidatePassword(plainPassword string, personal ...string) error
+	// ChangePassword mengganti password staf yang login setelah password saat ini diperiksa.
+	ChangePassword(ctx context.Context, staffID, currentPassword, newPassword string) error
+	// SetTemporaryPassword dipakai admin: password wajib diganti staf saat login berikutnya.
+	SetTemporaryPassword(ctx context.Context, staff *models.Staff, plainPassword string) error
+	// RequestPasswordReset mengirim tautan reset ke email staf. Email yang tidak terdaftar
+	// tidak menghasilkan error, agar endpoint tidak bisa dipakai menebak akun.
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword mengganti password dengan token dari email. Token hanya bisa dipakai sekali.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// accountService adalah implementasi dari AccountService.
+type accountService struct {
+	staffRepo        repositories.StaffRepository
+	resetRepo        repositories.PasswordResetRepository
+	twoFactor        TwoFactorService
+	policy           *password.Policy
+	mailer           mailer.Mailer
+	appURL           string
+	lockoutThreshold int // 0 berarti akun tidak pernah dikunci
+	lockoutDuration  time.Duration
+	resetTokenTTL    time.Duration
+	dummyHash        string // Dibandingkan untuk email tak dikenal, agar waktu responsnya sama
+	html             *htmltemplate.Template
+	text             *texttemplate.Template
+}
+
+// AccountConfig adalah pengaturan penguncian akun dan reset password untuk NewAccountService.
+type AccountConfig struct {
+	AppURL           string // Alamat dashboard; tautan reset adalah <AppURL>/reset-password?token=...
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	ResetTokenTTL    time.Duration
+}
+
+// NewAccountService adalah constructor untuk accountService.
+func NewAccountService(sRepo repositories.StaffRepository, rRepo repositories.PasswordResetRepository, twoFactor TwoFactorService,
+	policy *password.Policy, m mailer.Mailer, cfg AccountConfig) AccountService {
+	dummyHash, err := utils.HashPassword("dummy password for unknown accounts")
+	if err != nil {
+		panic(fmt.Sprintf("failed to hash dummy password: %v", err))
+	}
+	html, text := parseEmailTemplates(models.NotificationEventPasswordReset)
+	return &accountService{
+		staffRepo:        sRepo,
+		resetRepo:        rRepo,
+		twoFactor:        twoFactor,
+		policy:           policy,
+		mailer:           m,
+		appURL:           strings.TrimSuffix(cfg.AppURL, "/"),
+		lockoutThreshold: cfg.LockoutThreshold,
+		lockoutDuration:  cfg.LockoutDuration,
+		resetTokenTTL:    cfg.ResetTokenTTL,
+		dummyHash:        dummyHash,
+		html:             html,
+		text:             text,
+	}
+}
+
+func (s *accountService) Authenticate(ctx context.Context, email, plainPassword string) (*models.Staff, error) {
+	staff, err := s.staffRepo.GetStaffByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		utils.CheckPasswordHash(plainPassword, s.dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+
+	// Selama terkunci password tidak diperiksa sama sekali, jadi tebakan tidak bisa dilanjutkan
+	now := time.Now()
+	if staff.IsLocked(now) {
+		return nil, &AccountLockedError{Until: *staff.LockedUntil}
+	}
+
+	if !utils.CheckPasswordHash(plainPassword, staff.PasswordHashed) {
+		return nil, s.recordFailedLogin(ctx, staff, now, ErrInvalidCredentials)
+	}
+
+	// Dengan 2FA, hitungan baru direset setelah langkah kedua berhasil; kalau tidak, password
+	// yang bocor bisa dipakai untuk terus menebak kode tanpa pernah terkunci
+	if !staff.TOTPEnabled {
+		s.resetFailedLogins(ctx, staff)
+	}
+	return staff, nil
+}
+
+func (s *accountService) VerifySecondFactor(ctx context.Context, staffID, code string) (*models.Staff, error) {
+	staff, err := s.staffRepo.GetStaffByID(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		return nil, ErrInvalidCredentials
+	}
+	now := time.Now()
+	if staff.IsLocked(now) {
+		return nil, &AccountLockedError{Until: *staff.LockedUntil}
+	}
+
+	if err := s.twoFactor.Verify(ctx, staff.StaffID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			return nil, s.recordFailedLogin(ctx, staff, now, err)
+		}
+		return nil, err
+	}
+	s.resetFailedLogins(ctx, staff)
+	return staff, nil
+}
+
+// recordFailedLogin menghitung login gagal dan mengembalikan *AccountLockedError jika akun
+// baru saja dikunci, atau failure jika belum.
+func (s *accountService) recordFailedLogin(ctx context.Context, staff *models.Staff, now time.Time, failure error) error {
+	if s.lockoutThreshold <= 0 {
+		return failure
+	}
+	lockedUntil, err := s.staffRepo.RecordFailedLogin(ctx, staff.StaffID, s.lockoutThreshold, s.lockoutDuration)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Gagal mencatat login gagal", "staff_id", staff.StaffID, "error", err)
+		return failure
+	}
+	if lockedUntil != nil && lockedUntil.After(now) {
+		logger.FromContext(ctx).Warn("Akun staf dikunci setelah login gagal berulang", "staff_id", staff.StaffID,
+			"locked_until", *lockedUntil, "threshold", s.lockoutThreshold)
+		return &AccountLockedError{Until: *lockedUntil}
+	}
+	return failure
+}
+
+func (s *accountService) resetFailedLogins(ctx context.Context, staff *models.Staff) {
+	if staff.FailedLoginAttempts == 0 && staff.LockedUntil == nil {
+		return
+	}
+	if err := s.staffRepo.ResetFailedLogins(ctx, staff.StaffID); err != nil {
+		logger.FromContext(ctx).Warn("Gagal mereset hitungan login gagal", "staff_id", staff.StaffID, "error", err)
+	}
+}
+
+func (s *accountService) ValidatePassword(plainPassword string, personal ...string) error {
+	return s.policy.Check(plainPassword, personal...)
+}
+
+func (s *accountService) ChangePassword(ctx context.Context, staffID, currentPassword, newPassword string) error {
+	staff, err := s.staffRepo.GetStaffByID(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if staff == nil || !utils.CheckPasswordHash(currentPassword, staff.PasswordHashed) {
+		return ErrInvalidCredentials
+	}
+	if utils.CheckPasswordHash(newPassword, staff.PasswordHashed) {
+		return ErrPasswordReused
+	}
+	return s.setPassword(ctx, staff, newPassword, false)
+}
+
+func (s *accountService) SetTemporaryPassword(ctx context.Context, staff *models.Staff, plainPassword string) error {
+	return s.setPassword(ctx, staff, plainPassword, true)
+}
+
+func (s *accountService) setPassword(ctx context.Context, staff *models.Staff, plainPassword string, mustChange bool) error {
+	if err := s.policy.Check(plainPassword, staff.Email, staff.Nama); err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(plainPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return s.staffRepo.SetPassword(ctx, staff.StaffID, hashed, mustChange)
+}
+
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	staff, err := s.staffRepo.GetStaffByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if staff == nil {
+		return nil
+	}
+
+	token, tokenHash, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	reset := &models.PasswordResetToken{
+		TokenHash: tokenHash,
+		StaffID:   staff.StaffID,
+		ExpiresAt: time.Now().Add(s.resetTokenTTL),
+	}
+	if err := s.resetRepo.CreateResetToken(ctx, reset); err != nil {
+		return err
+	}
+
+	// Email dikirim di background: lama pengiriman SMTP tidak boleh membedakan email
+	// terdaftar dari yang tidak
+	data := emailData{
+		Subject:      "Reset password Dashboard Pekerjaan",
+		StaffName:    staff.Nama,
+		AccountEmail: true,
+		ResetURL:     s.appURL + "/reset-password?token=" + url.QueryEscape(token),
+		ExpiresAt:    reset.ExpiresAt,
+	}
+	log := logger.FromContext(ctx)
+	go func() {
+		if err := sendEmail(s.mailer, s.html, s.text, staff.Email, data); err != nil {
+			log.Warn("Gagal mengirim email reset password", "staff_id", staff.StaffID, "error", err)
+		}
+	}()
+	return nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tokenHash := hashResetToken(token)
+	reset, err := s.resetRepo.GetResetToken(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	if reset == nil || reset.UsedAt != nil || !reset.ExpiresAt.After(time.Now()) {
+		return ErrInvalidResetToken
+	}
+	staff, err := s.staffRepo.GetStaffByID(ctx, reset.StaffID)
+	if err != nil {
+		return err
+	}
+	if staff == nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.policy.Check(newPassword, staff.Email, staff.Nama); err != nil {
+		return err
+	}
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	// Token diklaim lagi di dalam transaksi, jadi request paralel dengan token yang sama gagal
+	staffID, err := s.resetRepo.ConsumeResetToken(ctx, tokenHash, hashed)
+	if err != nil {
+		return err
+	}
+	if staffID == "" {
+		return ErrInvalidResetToken
+	}
+	logger.FromContext(ctx).Info("Password staf direset lewat email", "staff_id", staffID)
+	return nil
+}
+
+// newResetToken membuat token acak 256 bit untuk tautan email beserta hash yang disimpan di database.
+func newResetToken() (token, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashResetToken(token), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/tabular"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+// ErrInvalidClientImportMapping dikembalikan jika pemetaan kolom tidak dapat dipakai.
+var ErrInvalidClientImportMapping = errors.New("pemetaan kolom tidak valid")
+
+// ClientImportOptions adalah opsi impor klien massal.
+type ClientImportOptions struct {
+	// Mapping memetakan field klien (mis. "npwp_client") ke judul kolom di file.
+	// Jika kosong, kolom dicocokkan otomatis dari judulnya.
+	Mapping map[string]string
+	Mode    string // models.ClientImportModeInsert atau models.ClientImportModeUpsert
+	DryRun  bool
+
+	StaffID string // Staf yang melakukan impor, untuk membatasi upsert ke klien miliknya
+	IsAdmin bool
+}
+
+// ClientImportService mendefinisikan operasi impor klien dari file CSV/XLSX.
+type ClientImportService interface {
+	// ImportClients memvalidasi semua baris terlebih dahulu. Data hanya disimpan (dalam satu
+	// transaksi) jika tidak ada baris yang error dan DryRun bernilai false.
+	ImportClients(ctx context.Context, table *tabular.Table, opts ClientImportOptions) (*models.ClientImportResult, error)
+}
+
+// clientImportService adalah implementasi dari ClientImportService.
+type clientImportService struct {
+	clientRepo repositories.ClientRepository
+	staffRepo  repositories.StaffRepository
+}
+
+// NewClientImportService adalah constructor untuk clientImportService.
+func NewClientImportService(cRepo repositories.ClientRepository, sRepo repositories.StaffRepository) ClientImportService {
+	return &clientImportService{clientRepo: cRepo, staffRepo: sRepo}
+}
+
+// clientImportFields adalah field yang bisa diisi dari file, beserta judul kolom
+// yang dikenali otomatis (sudah dinormalisasi, lihat normalizeColumn).
+var clientImportFields = map[string][]string{
+	"client_name":         {"clientname", "namaklien", "nama", "namawajibpajak"},
+	"npwp_client":         {"npwpclient", "npwp", "npwpklien"},
+	"address_client":      {"addressclient", "alamat", "alamatklien"},
+	"membership_status":   {"membershipstatus", "statuskeanggotaan", "status"},
+	"phone_client":        {"phoneclient", "telepon", "notelepon", "nohp", "phone"},
+	"email_client":        {"emailclient", "email"},
+	"pic_client":          {"picclient", "picklien"},
+	"djp_online_username": {"djponlineusername", "usernamedjponline"},
+	"coretax_username":    {"coretaxusername", "usernamecoretax"},
+	"coretax_password":    {"coretaxpassword", "passwordcoretax"},
+	"pic_staff":           {"picstaff", "picstaffsigmaid", "picstaffsigma", "staffpic"},
+	"client_category":     {"clientcategory", "kategori", "kategoriklien"},
+	"pph_final_umkm":      {"pphfinalumkm"},
+	"pph_25":              {"pph25"},
+	"pph_21":              {"pph21"},
+	"pph_unifikasi":       {"pphunifikasi"},
+	"ppn":                 {"ppn"},
+	"spt_tahunan":         {"spttahunan"},
+	"pelaporan_deviden":   {"pelaporandeviden"},
+	"laporan_keuangan":    {"laporankeuangan"},
+	"investasi_deviden":   {"investasideviden"},
+}
+
+type importedClient struct {
+	result   *models.ClientImportRowResult
+	client   *models.Client
+	password string // Password Coretax mentah, di-hash saat commit
+	isUpdate bool
+}
+
+func (s *clientImportService) ImportClients(ctx context.Context, table *tabular.Table, opts ClientImportOptions) (*models.ClientImportResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = models.ClientImportModeInsert
+	}
+	if opts.Mode != models.ClientImportModeInsert && opts.Mode != models.ClientImportModeUpsert {
+		return nil, fmt.Errorf("%w: mode harus %q atau %q", ErrInvalidClientImportMapping, models.ClientImportModeInsert, models.ClientImportModeUpsert)
+	}
+
+	columns, mapping, err := resolveClientImportMapping(table.Header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	staffIndex, err := s.staffIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ambil klien yang NPWP-nya sudah terdaftar sekaligus untuk semua baris
+	var npwps []string
+	for _, row := range table.Rows {
+		if n, err := npwp.Parse(row.Value(columns["npwp_client"])); err == nil {
+			npwps = append(npwps, n.Canonical())
+		}
+	}
+	existing := make(map[string]models.Client)
+	if len(npwps) > 0 {
+		clients, err := s.clientRepo.GetClientsByNpwp(ctx, npwps)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memeriksa NPWP yang sudah terdaftar: %w", err)
+		}
+		for _, c := range clients {
+			existing[c.NpwpCanonical] = c
+		}
+	}
+
+	result := &models.ClientImportResult{
+		DryRun:    opts.DryRun,
+		Mode:      opts.Mode,
+		TotalRows: len(table.Rows),
+		Mapping:   mapping,
+		Rows:      make([]models.ClientImportRowResult, len(table.Rows)),
+	}
+	seen := make(map[string]int) // NPWP -> nomor baris pertama di file
+	var items []importedClient
+
+	for i, row := range table.Rows {
+		rowResult := &result.Rows[i]
+		rowResult.Row = row.Number
+		item := importedClient{result: rowResult, client: &models.Client{}}
+		var errs []string
+
+		canonical, _ := npwp.Normalize(row.Value(columns["npwp_client"]))
+		if current, ok := existing[canonical]; ok && canonical != "" {
+			switch {
+			case opts.Mode == models.ClientImportModeInsert:
+				errs = append(errs, fmt.Sprintf("NPWP sudah terdaftar atas nama %s", current.ClientName))
+			case !opts.IsAdmin && current.PicStaffSigmaID != opts.StaffID:
+				errs = append(errs, "NPWP sudah terdaftar untuk klien yang bukan tanggung jawab Anda")
+			default:
+				c := current
+				item.client = &c
+				item.isUpdate = true
+			}
+		}
+		if first, dup := seen[canonical]; dup && canonical != "" {
+			errs = append(errs, fmt.Sprintf("NPWP duplikat dengan baris %d", first))
+		} else if canonical != "" {
+			seen[canonical] = row.Number
+		}
+
+		password, fieldErrs := applyClientImportRow(item.client, row, columns, staffIndex, item.isUpdate)
+		item.password = password
+		errs = append(errs, fieldErrs...)
+
+		rowResult.ClientID = item.client.ClientID
+		rowResult.ClientName = item.client.ClientName
+		rowResult.NpwpClient = item.client.NpwpClient
+		if len(errs) > 0 {
+			rowResult.Status = models.ClientImportStatusError
+			rowResult.Errors = errs
+			result.ErrorRows++
+			continue
+		}
+		rowResult.Status = models.ClientImportStatusValid
+		rowResult.Action = "create"
+		if item.isUpdate {
+			rowResult.Action = "update"
+		}
+		result.ValidRows++
+		items = append(items, item)
+	}
+
+	if opts.DryRun || result.ErrorRows > 0 || len(items) == 0 {
+		return result, nil
+	}
+
+	var creates, updates []*models.Client
+	for _, item := range items {
+		if item.password != "" {
+			hashed, err := utils.HashPassword(item.password)
+			if err != nil {
+				return nil, fmt.Errorf("gagal meng-hash password Coretax baris %d: %w", item.result.Row, err)
+			}
+			item.client.CoretaxPasswordHashed = hashed
+		}
+		if item.isUpdate {
+			updates = append(updates, item.client)
+		} else {
+			creates = append(creates, item.client)
+		}
+	}
+
+	if err := s.clientRepo.ImportClients(ctx, creates, updates); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		item.result.ClientID = item.client.ClientID
+		if item.isUpdate {
+			item.result.Status = models.ClientImportStatusUpdated
+			result.Updated++
+		} else {
+			item.result.Status = models.ClientImportStatusCreated
+			result.Created++
+		}
+	}
+	result.Committed = true
+	logger.FromContext(ctx).Info("Impor klien selesai", "created", result.Created, "updated", result.Updated)
+	return result, nil
+}
+
+// applyClientImportRow mengisi client dari satu baris. Untuk klien yang sudah ada,
+// sel kosong berarti nilai lama dipertahankan.
+func applyClientImportRow(client *models.Client, row tabular.Row, columns map[string]int, staffIndex map[string]string, isUpdate bool) (string, []string) {
+	var errs []string
+	var password string
+
+	for field, idx := range columns {
+		value := row.Value(idx)
+		if value == "" && isUpdate {
+			continue
+		}
+
+		switch field {
+		case "client_name":
+			client.ClientName = value
+		case "npwp_client":
+			client.NpwpClient = value
+		case "address_client":
+			client.AddressClient = value
+		case "membership_status":
+			client.MembershipStatus = value
+		case "phone_client":
+			client.PhoneClient = value
+		case "email_client":
+			if value != "" {
+				addr, err := mail.ParseAddress(value)
+				if err != nil || addr.Address != value {
+					errs = append(errs, fmt.Sprintf("format email %q tidak valid", value))
+				}
+			}
+			client.EmailClient = value
+		case "pic_client":
+			client.PicClient = value
+		case "djp_online_username":
+			client.DjpOnlineUsername = value
+		case "coretax_username":
+			client.CoretaxUsername = value
+		case "coretax_password":
+			password = value
+		case "pic_staff":
+			if value == "" {
+				client.PicStaffSigmaID = ""
+				continue
+			}
+			staffID, ok := staffIndex[strings.ToLower(value)]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("PIC staff %q tidak ditemukan", value))
+				continue
+			}
+			client.PicStaffSigmaID = staffID
+		case "client_category":
+			client.ClientCategory = value
+		default:
+			b, err := parseImportBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("kolom %s: %v", field, err))
+				continue
+			}
+			*clientTaxFlag(client, field) = b
+		}
+	}
+
+	if strings.TrimSpace(client.ClientName) == "" {
+		errs = append(errs, "nama klien wajib diisi")
+	}
+	if n, err := npwp.Parse(client.NpwpClient); err != nil {
+		errs = append(errs, fmt.Sprintf("NPWP %q: %v", client.NpwpClient, err))
+	} else {
+		client.NpwpClient = n.Format()
+		client.NpwpCanonical = n.Canonical()
+	}
+	sort.Strings(errs)
+	return password, errs
+}
+
+func clientTaxFlag(client *models.Client, field string) *bool {
+	switch field {
+	case "pph_final_umkm":
+		return &client.PphFinalUmkm
+	case "pph_25":
+		return &client.Pph25
+	case "pph_21":
+		return &client.Pph21
+	case "pph_unifikasi":
+		return &client.PphUnifikasi
+	case "ppn":
+		return &client.Ppn
+	case "spt_tahunan":
+		return &client.SptTahunan
+	case "pelaporan_deviden":
+		return &client.PelaporanDeviden
+	case "laporan_keuangan":
+		return &client.LaporanKeuangan
+	default:
+		return &client.InvestasiDeviden
+	}
+}
+
+// resolveClientImportMapping mengubah pemetaan field -> judul kolom menjadi field -> indeks kolom.
+func resolveClientImportMapping(header []string, mapping map[string]string) (map[string]int, map[string]string, error) {
+	headerIndex := make(map[string]int, len(header))
+	for i, h := range header {
+		if _, exists := headerIndex[normalizeColumn(h)]; !exists {
+			headerIndex[normalizeColumn(h)] = i
+		}
+	}
+
+	columns := make(map[string]int)
+	resolved := make(map[string]string)
+	if len(mapping) > 0 {
+		for field, source := range mapping {
+			if _, ok := clientImportFields[field]; !ok {
+				return nil, nil, fmt.Errorf("%w: field %q tidak dikenal", ErrInvalidClientImportMapping, field)
+			}
+			idx, ok := headerIndex[normalizeColumn(source)]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: kolom %q tidak ada di file", ErrInvalidClientImportMapping, source)
+			}
+			columns[field] = idx
+			resolved[field] = header[idx]
+		}
+	} else {
+		for field, aliases := range clientImportFields {
+			for _, alias := range aliases {
+				if idx, ok := headerIndex[alias]; ok {
+					columns[field] = idx
+					resolved[field] = header[idx]
+					break
+				}
+			}
+		}
+	}
+
+	for _, required := range []string{"client_name", "npwp_client"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: kolom untuk %s wajib ada", ErrInvalidClientImportMapping, required)
+		}
+	}
+	return columns, resolved, nil
+}
+
+// staffIndex memetakan staff_id, email dan NIP (huruf kecil) ke staff_id.
+func (s *clientImportService) staffIndex(ctx context.Context) (map[string]string, error) {
+	staffs, err := s.staffRepo.GetAllStaffs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data staf: %w", err)
+	}
+	index := make(map[string]string, len(staffs)*3)
+	for _, st := range staffs {
+		index[strings.ToLower(st.StaffID)] = st.StaffID
+		index[strings.ToLower(st.Email)] = st.StaffID
+		index[strings.ToLower(st.NIP)] = st.StaffID
+	}
+	return index, nil
+}
+
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false", "tidak", "n", "no", "-":
+		return false, nil
+	case "1", "true", "ya", "y", "yes", "x", "v":
+		return true, nil
+	}
+	return false, fmt.Errorf("nilai %q bukan ya/tidak", value)
+}
+
+func normalizeColumn(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/pdftext"
+)
+
+// DocumentService mendefinisikan operasi untuk pencatatan dokumen upload dan ekstraksi teksnya.
+type DocumentService interface {
+	// RegisterUpload mencatat file yang baru diupload dan mengantrikan ekstraksi teksnya.
+	RegisterUpload(ctx context.Context, doc *models.Document) error
+	// Reextract mengantrikan ulang ekstraksi teks untuk dokumen yang sudah ada.
+	Reextract(ctx context.Context, doc *models.Document) error
+	// LocalPath memetakan URL "/uploads/<file>" atau "portal/<file>" ke path file lokalnya.
+	LocalPath(fileURL string) string
+	// UploadPath adalah path file baru bernama filename di folder upload lokal.
+	UploadPath(filename string) string
+	// PortalUploadPath adalah path file baru bernama filename di folder upload portal,
+	// yang FileURL-nya PortalFileURLPrefix + filename.
+	PortalUploadPath(filename string) string
+	// Start menjalankan worker ekstraksi di background.
+	Start()
+	// Stop menghentikan worker setelah dokumen yang sedang diekstraksi selesai.
+	Stop(ctx context.Context) error
+}
+
+// PortalFileURLPrefix menandai file upload klien portal. File ini tidak punya URL publik,
+// sehingga hanya bisa diunduh lewat endpoint dokumen yang memeriksa akses.
+const PortalFileURLPrefix = "portal/"
+
+const (
+	extractionQueueSize     = 100
+	extractionSweepInterval = time.Minute
+	extractionSweepBatch    = 20
+)
+
+// documentService adalah implementasi dari DocumentService.
+type documentService struct {
+	documentRepo repositories.DocumentRepository
+	uploadDir    string
+	portalDir    string
+	queue        chan string
+	*workerLoop
+}
+
+// NewDocumentService adalah constructor untuk documentService.
+// uploadDir adalah folder lokal yang dilayani di /uploads, portalDir folder upload
+// portal yang tidak dilayani publik.
+func NewDocumentService(docRepo repositories.DocumentRepository, uploadDir, portalDir string) DocumentService {
+	return &documentService{
+		documentRepo: docRepo,
+		uploadDir:    uploadDir,
+		portalDir:    portalDir,
+		queue:        make(chan string, extractionQueueSize),
+		workerLoop:   newWorkerLoop(),
+	}
+}
+
+func (s *documentService) RegisterUpload(ctx context.Context, doc *models.Document) error {
+	if err := s.documentRepo.UpsertDocument(ctx, doc); err != nil {
+		return fmt.Errorf("gagal mencatat dokumen: %w", err)
+	}
+	s.enqueue(ctx, doc.DocumentID)
+	return nil
+}
+
+func (s *documentService) Reextract(ctx context.Context, doc *models.Document) error {
+	// Upsert dengan file_url yang sama mengembalikan status ke Pending.
+	if err := s.documentRepo.UpsertDocument(ctx, doc); err != nil {
+		return fmt.Errorf("gagal mengantrikan ulang dokumen: %w", err)
+	}
+	s.enqueue(ctx, doc.DocumentID)
+	return nil
+}
+
+// enqueue tidak pernah memblokir request; jika antrian penuh, dokumen akan
+// diambil oleh sweep berikutnya karena statusnya masih Pending.
+func (s *documentService) enqueue(ctx context.Context, documentID string) {
+	select {
+	case s.queue <- documentID:
+	default:
+		logger.FromContext(ctx).Warn("Antrian ekstraksi penuh, dokumen diproses pada sweep berikutnya", "document_id", documentID)
+	}
+}
+
+func (s *documentService) Start() {
+	s.workerLoop.run(s.run)
+}
+
+func (s *documentService) run() {
+	ticker := time.NewTicker(extractionSweepInterval)
+	defer ticker.Stop()
+
+	ctx := logger.With(s.runContext(), "worker", "document_extraction")
+	s.sweep(ctx)
+	for {
+		select {
+		case <-s.stopping():
+			return // Dokumen yang masih di antrian tetap Pending dan diambil sweep setelah restart
+		case id := <-s.queue:
+			s.processByID(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep memproses dokumen Pending yang tertinggal (mis. setelah restart).
+func (s *documentService) sweep(ctx context.Context) {
+	docs, err := s.documentRepo.GetPendingDocuments(ctx, extractionSweepBatch)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Gagal mengambil dokumen pending", "error", err)
+		return
+	}
+	for i := range docs {
+		if s.isStopping() {
+			return
+		}
+		s.extract(ctx, &docs[i])
+	}
+}
+
+func (s *documentService) processByID(ctx context.Context, id string) {
+	doc, err := s.documentRepo.GetDocumentByID(ctx, id, "", true)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Dokumen tidak dapat diambil untuk ekstraksi", "document_id", id, "error", err)
+		return
+	}
+	if doc.ExtractionStatus != models.ExtractionStatusPending {
+		return // Sudah diproses oleh sweep
+	}
+	s.extract(ctx, doc)
+}
+
+func (s *documentService) extract(ctx context.Context, doc *models.Document) {
+	log := logger.FromContext(ctx).With("document_id", doc.DocumentID)
+	path := s.LocalPath(doc.FileURL)
+	if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+		// Upload klien bisa berupa Excel atau gambar; hanya PDF yang diindeks isinya
+		if err := s.documentRepo.UpdateDocumentExtraction(ctx, doc.DocumentID, models.ExtractionStatusDone, "", ""); err != nil {
+			log.Warn("Gagal menyimpan status ekstraksi dokumen", "error", err)
+		}
+		return
+	}
+	text, err := pdftext.ExtractFile(path)
+	if err != nil {
+		log.Warn("Ekstraksi teks dokumen gagal", "error", err)
+		if uerr := s.documentRepo.UpdateDocumentExtraction(ctx, doc.DocumentID, models.ExtractionStatusFailed, "", err.Error()); uerr != nil {
+			log.Warn("Gagal menyimpan status ekstraksi dokumen", "error", uerr)
+		}
+		return
+	}
+
+	if err := s.documentRepo.UpdateDocumentExtraction(ctx, doc.DocumentID, models.ExtractionStatusDone, text, ""); err != nil {
+		log.Warn("Gagal menyimpan teks dokumen", "error", err)
+		return
+	}
+	log.Info("Teks dokumen berhasil diekstrak", "characters", len(text))
+}
+
+func (s *documentService) LocalPath(fileURL string) string {
+	if name, ok := strings.CutPrefix(fileURL, PortalFileURLPrefix); ok {
+		return filepath.Join(s.portalDir, filepath.Base(name))
+	}
+	name := filepath.Base(strings.TrimPrefix(fileURL, "/uploads/"))
+	return filepath.Join(s.uploadDir, name)
+}
+
+func (s *documentService) UploadPath(filename string) string {
+	return filepath.Join(s.uploadDir, filepath.Base(filename))
+}
+
+func (s *documentService) PortalUploadPath(filename string) string {
+	return filepath.Join(s.portalDir, filepath.Base(filename))
+}
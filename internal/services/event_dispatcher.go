@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// EventHandler memproses satu domain event. Handler bisa dipanggil lebih dari sekali untuk
+// event yang sama (mis. setelah crash), jadi harus idempoten; error membuat event dicoba lagi.
+type EventHandler func(ctx context.Context, event *models.DomainEvent) error
+
+// EventDispatcher meneruskan domain event dari tabel outbox ke subscriber di dalam aplikasi
+// dengan semantik at-least-once.
+type EventDispatcher interface {
+	// Subscribe mendaftarkan handler bernama subscriber untuk jenis event yang diberikan.
+	// Nama subscriber dicatat per event, jadi jangan diganti setelah ada event yang tertunda.
+	Subscribe(subscriber string, handler EventHandler, eventTypes ...string)
+	// Start mulai memproses outbox di background. Panggil setelah semua Subscribe.
+	Start()
+	// Stop menghentikan dispatcher setelah event yang sedang diproses selesai. Event yang
+	// belum diproses tetap di outbox dan diteruskan setelah restart.
+	Stop(ctx context.Context) error
+}
+
+const (
+	outboxSweepInterval = 30 * time.Second
+	outboxBatchSize     = 50
+	outboxLease         = 2 * time.Minute
+	outboxBaseBackoff   = 5 * time.Second
+	outboxMaxBackoff    = time.Hour
+)
+
+type eventSubscription struct {
+	name    string
+	handler EventHandler
+}
+
+// eventDispatcher adalah implementasi dari EventDispatcher.
+type eventDispatcher struct {
+	outboxRepo  repositories.OutboxRepository
+	databaseURL string
+
+	mu          sync.RWMutex
+	subscribers map[string][]eventSubscription // Per jenis event, sesuai urutan Subscribe
+	*workerLoop
+}
+
+// NewEventDispatcher adalah constructor untuk eventDispatcher.
+// databaseURL dipakai untuk LISTEN agar event baru langsung diproses setelah commit.
+func NewEventDispatcher(outboxRepo repositories.OutboxRepository, databaseURL string) EventDispatcher {
+	return &eventDispatcher{
+		outboxRepo:  outboxRepo,
+		databaseURL: databaseURL,
+		subscribers: make(map[string][]eventSubscription),
+		workerLoop:  newWorkerLoop(),
+	}
+}
+
+func (d *eventDispatcher) Subscribe(subscriber string, handler EventHandler, eventTypes ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, eventType := range eventTypes {
+		d.subscribers[eventType] = append(d.subscribers[eventType], eventSubscription{name: subscriber, handler: handler})
+	}
+}
+
+func (d *eventDispatcher) Start() {
+	listener := pq.NewListener(d.databaseURL, listenerMinReconnect, listenerMaxReconnect,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				logger.FromContext(d.runContext()).Warn("Koneksi LISTEN outbox bermasalah", "error", err)
+			}
+		})
+	// Listen menunggu sampai koneksi tersambung, jadi jangan tahan startup. Tanpa LISTEN,
+	// event tetap diproses oleh sweep berkala.
+	go func() {
+		if err := listener.Listen(repositories.OutboxChannel); err != nil {
+			logger.FromContext(d.runContext()).Warn("Gagal LISTEN, outbox hanya diproses oleh sweep berkala",
+				"channel", repositories.OutboxChannel, "sweep_interval", outboxSweepInterval, "error", err)
+		}
+	}()
+	d.workerLoop.run(func() {
+		defer listener.Close()
+		d.run(listener.Notify)
+	})
+}
+
+func (d *eventDispatcher) run(notify <-chan *pq.Notification) {
+	ticker := time.NewTicker(outboxSweepInterval)
+	defer ticker.Stop()
+
+	ctx := logger.With(d.runContext(), "worker", "event_dispatcher")
+	for {
+		d.dispatchPending(ctx)
+		select {
+		case <-d.stopping():
+			return
+		case <-notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchPending memproses semua event yang jatuh tempo, per batch.
+func (d *eventDispatcher) dispatchPending(ctx context.Context) {
+	for {
+		events, err := d.outboxRepo.ClaimPendingEvents(ctx, outboxBatchSize, outboxLease)
+		if err != nil {
+			logger.FromContext(ctx).Warn("Gagal mengambil event outbox", "error", err)
+			return
+		}
+		for i := range events {
+			if d.isStopping() {
+				return // Event yang sudah di-claim diambil lagi setelah lease-nya habis
+			}
+			d.dispatch(ctx, &events[i])
+		}
+		if len(events) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// dispatch memanggil subscriber yang belum berhasil memproses event. Event ditandai selesai
+// hanya jika semua subscriber berhasil; jika tidak, dijadwalkan ulang dengan backoff.
+func (d *eventDispatcher) dispatch(ctx context.Context, event *models.DomainEvent) {
+	// Atribut event ikut ke log subscriber lewat logger.FromContext(ctx)
+	ctx = logger.With(ctx, "event_id", event.EventID, "event_type", event.EventType,
+		"aggregate_type", event.AggregateType, "aggregate_id", event.AggregateID)
+	if event.ActorStaffID != nil {
+		ctx = logger.With(ctx, "staff_id", *event.ActorStaffID)
+	}
+	log := logger.FromContext(ctx)
+
+	d.mu.RLock()
+	subs := d.subscribers[event.EventType]
+	d.mu.RUnlock()
+
+	handled, err := d.outboxRepo.GetHandledSubscribers(ctx, event.EventID)
+	if err != nil {
+		log.Warn("Gagal membaca subscriber yang sudah memproses event", "error", err)
+		return // Event diambil lagi setelah lease habis
+	}
+
+	var failures []string
+	for _, sub := range subs {
+		if handled[sub.name] {
+			continue
+		}
+		if err := d.handle(ctx, sub, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sub.name, err))
+			continue
+		}
+		if err := d.outboxRepo.MarkHandled(ctx, event.EventID, sub.name); err != nil {
+			// Subscriber akan dipanggil ulang; aman karena handler idempoten
+			log.Warn("Gagal menandai event selesai untuk subscriber", "subscriber", sub.name, "error", err)
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := d.outboxRepo.MarkDispatched(ctx, event.EventID); err != nil {
+			log.Warn("Gagal menandai event terkirim", "error", err)
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	lastError := strings.Join(failures, "; ")
+	log.Warn("Event gagal diproses", "attempts", attempts, "error", lastError)
+	if err := d.outboxRepo.MarkFailed(ctx, event.EventID, lastError, time.Now().Add(outboxBackoff(attempts))); err != nil {
+		log.Warn("Gagal menjadwalkan ulang event", "error", err)
+	}
+}
+
+// handle memanggil handler dan mengubah panic menjadi error agar dispatcher tetap berjalan.
+func (d *eventDispatcher) handle(ctx context.Context, sub eventSubscription, event *models.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handler(ctx, event)
+}
+
+// outboxBackoff: 5 detik, 10 detik, 20 detik, ... maksimal 1 jam. Event tidak pernah dibuang.
+func outboxBackoff(attempts int) time.Duration {
+	if attempts-1 >= 20 {
+		return outboxMaxBackoff
+	}
+	if b := outboxBaseBackoff << (attempts - 1); b < outboxMaxBackoff {
+		return b
+	}
+	return outboxMaxBackoff
+}
+
+// NewAuditSubscriber mengembalikan handler yang mencatat setiap event ke audit log.
+func NewAuditSubscriber(auditRepo repositories.AuditRepository) EventHandler {
+	return func(ctx context.Context, event *models.DomainEvent) error {
+		return auditRepo.RecordEvent(ctx, event)
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/pdfdoc"
+)
+
+// Tata letak invoice PDF, dalam point dari kiri atas halaman
+const (
+	pdfMarginX      = 50.0
+	pdfTop          = 60.0
+	pdfBottom       = pdfdoc.PageHeight - 60
+	pdfRight        = pdfdoc.PageWidth - pdfMarginX
+	pdfFontSize     = 10.0
+	pdfLineHeight   = 14.0
+	pdfColQty       = 330.0 // Tepi kanan kolom
+	pdfColUnitPrice = 430.0
+	pdfDescWidth    = 260.0
+)
+
+// WriteInvoicePDF menulis invoice beserta line item-nya sebagai PDF A4.
+// Line item yang tidak muat dilanjutkan ke halaman berikutnya dengan header tabel yang sama.
+func WriteInvoicePDF(w io.Writer, inv *models.Invoice) error {
+	doc := pdfdoc.New()
+	page := doc.AddPage()
+	y := pdfTop
+
+	page.Text(pdfMarginX, y, pdfdoc.Bold, 20, "INVOICE")
+	page.TextRight(pdfRight, y, pdfdoc.Bold, 12, inv.InvoiceNumber)
+	y += 30
+
+	info := [][2]string{
+		{"Klien", inv.ClientName},
+		{"NPWP", inv.NpwpClient},
+		{"Tanggal Invoice", formatTanggal(inv.InvoiceDate.Time)},
+		{"Jatuh Tempo", formatTanggal(inv.DueDate.Time)},
+		{"Status", inv.Status},
+	}
+	for _, row := range info {
+		page.Text(pdfMarginX, y, pdfdoc.Bold, pdfFontSize, row[0])
+		page.Text(pdfMarginX+100, y, pdfdoc.Regular, pdfFontSize, ": "+row[1])
+		y += pdfLineHeight
+	}
+	y += pdfLineHeight
+
+	tableHeader := func() {
+		page.Text(pdfMarginX, y, pdfdoc.Bold, pdfFontSize, "Deskripsi")
+		page.TextRight(pdfColQty, y, pdfdoc.Bold, pdfFontSize, "Qty")
+		page.TextRight(pdfColUnitPrice, y, pdfdoc.Bold, pdfFontSize, "Harga Satuan")
+		page.TextRight(pdfRight, y, pdfdoc.Bold, pdfFontSize, "Jumlah")
+		y += 5
+		page.Line(pdfMarginX, y, pdfRight, y, 0.75)
+		y += pdfLineHeight
+	}
+	tableHeader()
+
+	for _, item := range inv.LineItems {
+		lines := pdfdoc.Wrap(pdfdoc.Regular, pdfFontSize, item.Description, pdfDescWidth)
+		if y+float64(len(lines))*pdfLineHeight > pdfBottom {
+			page = doc.AddPage()
+			y = pdfTop
+			tableHeader()
+		}
+		page.TextRight(pdfColQty, y, pdfdoc.Regular, pdfFontSize, strconv.FormatFloat(item.Quantity, 'f', -1, 64))
+		page.TextRight(pdfColUnitPrice, y, pdfdoc.Regular, pdfFontSize, formatRupiah(item.UnitPrice))
+		page.TextRight(pdfRight, y, pdfdoc.Regular, pdfFontSize, formatRupiah(item.Amount))
+		for _, line := range lines {
+			page.Text(pdfMarginX, y, pdfdoc.Regular, pdfFontSize, line)
+			y += pdfLineHeight
+		}
+	}
+
+	// Total dan catatan butuh beberapa baris; pindah halaman jika tidak muat
+	notes := []string{}
+	if inv.Notes != nil && *inv.Notes != "" {
+		notes = pdfdoc.Wrap(pdfdoc.Regular, pdfFontSize, *inv.Notes, pdfRight-pdfMarginX)
+	}
+	if y+float64(len(notes)+4)*pdfLineHeight > pdfBottom {
+		page = doc.AddPage()
+		y = pdfTop
+	}
+	page.Line(pdfMarginX, y-pdfLineHeight+5, pdfRight, y-pdfLineHeight+5, 0.75)
+	y += 4
+	page.TextRight(pdfColUnitPrice, y, pdfdoc.Bold, 11, "Total")
+	page.TextRight(pdfRight, y, pdfdoc.Bold, 11, formatRupiah(inv.TotalAmount))
+	y += 2 * pdfLineHeight
+
+	if len(notes) > 0 {
+		page.Text(pdfMarginX, y, pdfdoc.Bold, pdfFontSize, "Catatan")
+		y += pdfLineHeight
+		for _, line := range notes {
+			page.Text(pdfMarginX, y, pdfdoc.Regular, pdfFontSize, line)
+			y += pdfLineHeight
+		}
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
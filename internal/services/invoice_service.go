@@ -1,17 +1,22 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
 )
 
 // InvoiceService mendefinisikan operasi-operasi untuk logika bisnis invoice.
 type InvoiceService interface {
-	CreateInvoiceFromJob(jobID, jobType, clientID, assignedStaffID string) (*models.Invoice, error)
+	CreateInvoiceFromJob(ctx context.Context, jobID, jobType, clientID, assignedStaffID string) (*models.Invoice, error)
+	// HandleJobCompleted adalah subscriber event JobCompleted: membuat invoice otomatis untuk
+	// pekerjaan SP2DK dan Pemeriksaan. Aman dipanggil ulang untuk event yang sama.
+	HandleJobCompleted(ctx context.Context, event *models.DomainEvent) error
 }
 
 // invoiceService adalah implementasi dari InvoiceService.
@@ -45,7 +50,32 @@ func NewInvoiceService(
 
 // internal/services/invoice_service.go
 
-func (s *invoiceService) CreateInvoiceFromJob(jobID, jobType, clientID, assignedStaffID string) (*models.Invoice, error) {
+func (s *invoiceService) CreateInvoiceFromJob(ctx context.Context, jobID, jobType, clientID, assignedStaffID string) (*models.Invoice, error) {
+	return s.createInvoiceFromJob(ctx, jobID, jobType, clientID, assignedStaffID, nil)
+}
+
+// HandleJobCompleted membuat invoice untuk pekerjaan yang baru selesai. Hanya SP2DK dan
+// Pemeriksaan yang ditagih per pekerjaan; pekerjaan bulanan dan tahunan diabaikan.
+func (s *invoiceService) HandleJobCompleted(ctx context.Context, event *models.DomainEvent) error {
+	var change models.JobStatusChange
+	if err := event.DecodePayload(&change); err != nil {
+		return err
+	}
+	if change.JobType != models.JobTypeSp2dk && change.JobType != models.JobTypePemeriksaan {
+		return nil
+	}
+
+	log := logger.FromContext(ctx).With("job_type", change.JobType, "job_id", change.JobID)
+	log.Info("Pekerjaan selesai, membuat invoice")
+	_, err := s.createInvoiceFromJob(ctx, change.JobID, change.JobType, change.ClientID, change.StaffID, &event.EventID)
+	if errors.Is(err, repositories.ErrInvoiceAlreadyIssued) {
+		log.Info("Invoice untuk event ini sudah pernah dibuat, dilewati")
+		return nil
+	}
+	return err
+}
+
+func (s *invoiceService) createInvoiceFromJob(ctx context.Context, jobID, jobType, clientID, assignedStaffID string, sourceEventID *string) (*models.Invoice, error) {
 	var lineItems []models.InvoiceLineItem
 	var totalAmount float64
 	var description string
@@ -65,7 +95,7 @@ func (s *invoiceService) CreateInvoiceFromJob(jobID, jobType, clientID, assigned
 		description = fmt.Sprintf("Jasa Respon SP2DK (Job ID: %s)", jobID)
 		totalAmount = 2500000.00
 	default:
-		log.Printf("Tipe pekerjaan tidak dikenal '%s', invoice tidak dibuat.", jobType)
+		logger.FromContext(ctx).Warn("Tipe pekerjaan tidak dikenal, invoice tidak dibuat", "job_type", jobType, "job_id", jobID)
 		return nil, fmt.Errorf("tipe pekerjaan tidak dikenal: %s", jobType)
 	}
 
@@ -98,16 +128,18 @@ func (s *invoiceService) CreateInvoiceFromJob(jobID, jobType, clientID, assigned
 		Status:          "Pending",
 		Notes:           &notes, // Gunakan & untuk mendapatkan pointer string
 		LineItems:       lineItems,
+		SourceEventID:   sourceEventID,
 	}
 
 	// ================== AKHIR BLOK PERBAIKAN ==================
 
 	// Memanggil repository untuk menyimpan invoice ke database
-	err := s.invoiceRepo.CreateInvoice(invoice)
+	err := s.invoiceRepo.CreateInvoice(ctx, invoice)
 	if err != nil {
 		return nil, fmt.Errorf("gagal menyimpan invoice dari service: %w", err)
 	}
 
-	log.Printf("Invoice %s berhasil dibuat untuk pekerjaan %s.", invoice.InvoiceNumber, jobID)
+	logger.FromContext(ctx).Info("Invoice berhasil dibuat", "invoice_id", invoice.InvoiceID,
+		"invoice_number", invoice.InvoiceNumber, "job_type", jobType, "job_id", jobID)
 	return invoice, nil
 }
\ No newline at end of file
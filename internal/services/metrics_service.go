@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/metrics"
+)
+
+// RepositoryBuckets adalah bucket durasi panggilan repository, lebih rapat dari DefBuckets
+// karena kebanyakan query selesai dalam hitungan milidetik.
+var RepositoryBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// RepositoryObserver mencatat durasi setiap panggilan repository ke h, dengan label
+// repository, method dan result (ok, not_found, timeout, canceled atau error).
+func RepositoryObserver(h *metrics.HistogramVec) repositories.ObserveFunc {
+	return func(repository, method string, start time.Time, err error) {
+		result := "ok"
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows):
+			result = "not_found"
+		case errors.Is(err, context.DeadlineExceeded):
+			result = "timeout"
+		case errors.Is(err, context.Canceled):
+			result = "canceled"
+		default:
+			result = "error"
+		}
+		h.WithLabelValues(repository, method, result).Observe(time.Since(start).Seconds())
+	}
+}
+
+// businessMetricsCollector menghitung gauge bisnis dari database. Hasilnya di-cache selama
+// ttl, agar scrape yang sering tidak membebani database dengan query agregat.
+type businessMetricsCollector struct {
+	repo repositories.MetricsRepository
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	cached    []metrics.Family
+	collected time.Time
+}
+
+// NewBusinessMetricsCollector adalah constructor untuk collector gauge bisnis: pekerjaan
+// terbuka per jenis dan status, laporan pajak terlambat, dan invoice belum dibayar.
+func NewBusinessMetricsCollector(repo repositories.MetricsRepository, ttl time.Duration) metrics.Collector {
+	return &businessMetricsCollector{repo: repo, ttl: ttl}
+}
+
+func (c *businessMetricsCollector) Collect(ctx context.Context) ([]metrics.Family, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && time.Since(c.collected) < c.ttl {
+		return c.cached, nil
+	}
+
+	counts, err := c.repo.GetJobStatusCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overdue, err := c.repo.CountOverdueTaxReports(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	unpaid, err := c.repo.GetUnpaidInvoiceSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	openJobs := metrics.Gauge("dashboard_jobs_open", "Jobs that are not completed or cancelled, by job type and overall status.")
+	for _, jc := range counts {
+		openJobs.Samples = append(openJobs.Samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "job_type", Value: jc.JobType}, {Name: "status", Value: jc.Status}},
+			Value:  float64(jc.Count),
+		})
+	}
+	c.cached = []metrics.Family{
+		openJobs,
+		metrics.Gauge("dashboard_tax_reports_overdue", "Monthly and annual tax reports of open jobs past their due date without a report date.",
+			metrics.Sample{Value: float64(overdue)}),
+		metrics.Gauge("dashboard_invoices_unpaid", "Invoices that are issued but not paid or cancelled.",
+			metrics.Sample{Value: float64(unpaid.Count)}),
+		metrics.Gauge("dashboard_invoices_unpaid_amount_rupiah", "Total amount of unpaid invoices in rupiah.",
+			metrics.Sample{Value: unpaid.TotalAmount}),
+	}
+	c.collected = time.Now()
+	return c.cached, nil
+}
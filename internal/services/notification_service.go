@@ -0,0 +1,563 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/mailer"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxcalendar"
+)
+
+//go:embed templates/email/*.html templates/email/*.txt
+var emailTemplateFS embed.FS
+
+// NotificationService mengirim notifikasi ke staf: email sesuai preferensi masing-masing,
+// dan notifikasi in-app (ikon lonceng) yang didorong langsung ke dashboard.
+type NotificationService interface {
+	// HandleDomainEvent adalah subscriber outbox: JobReassigned mengirim notifikasi "pekerjaan
+	// ditugaskan kepada Anda", JobStatusChanged mendorong perubahan status ke dashboard yang
+	// terhubung dan memberi tahu PIC jika statusnya diubah orang lain.
+	HandleDomainEvent(ctx context.Context, event *models.DomainEvent) error
+	// RunDaily mengirim pengingat batas lapor, invoice jatuh tempo, ringkasan harian dan pengingat
+	// dokumen yang belum dikirim klien untuk tanggal now.
+	// Aman dipanggil berulang kali: email yang sudah terkirim tidak dikirim ulang.
+	RunDaily(ctx context.Context, now time.Time) error
+	// Start menjalankan penjadwal harian di background.
+	Start()
+	// Stop menghentikan penjadwal setelah RunDaily yang sedang berjalan selesai.
+	Stop(ctx context.Context) error
+}
+
+const (
+	// reportDueReminderDays adalah jarak hari sebelum batas waktu untuk pengingat batas lapor.
+	reportDueReminderDays     = 3
+	notificationCheckInterval = 15 * time.Minute
+	// Pengingat dokumen ke klien dikirim documentRequestReminderDays hari sebelum batas kirim,
+	// pada hari batas kirim, lalu setiap documentRequestOverdueInterval hari selama masih kurang.
+	documentRequestReminderDays    = 3
+	documentRequestOverdueInterval = 3
+)
+
+// notificationService adalah implementasi dari NotificationService.
+type notificationService struct {
+	notificationRepo repositories.NotificationRepository
+	staffRepo        repositories.StaffRepository
+	docRequestRepo   repositories.DocumentRequestRepository
+	mailer           mailer.Mailer
+	appURL           string
+	digestHour       int // Jam (waktu lokal) mulai dikirimnya email harian
+	html             map[string]*htmltemplate.Template
+	text             map[string]*texttemplate.Template
+	*workerLoop
+}
+
+// NewNotificationService adalah constructor untuk notificationService.
+// appURL ditampilkan sebagai tautan di email; digestHour adalah jam pengiriman email harian (0-23).
+func NewNotificationService(nRepo repositories.NotificationRepository, sRepo repositories.StaffRepository, drRepo repositories.DocumentRequestRepository,
+	m mailer.Mailer, appURL string, digestHour int) NotificationService {
+	s := &notificationService{
+		notificationRepo: nRepo,
+		staffRepo:        sRepo,
+		docRequestRepo:   drRepo,
+		mailer:           m,
+		appURL:           appURL,
+		digestHour:       digestHour,
+		html:             make(map[string]*htmltemplate.Template),
+		text:             make(map[string]*texttemplate.Template),
+		workerLoop:       newWorkerLoop(),
+	}
+	for _, event := range []string{
+		models.NotificationEventJobAssigned,
+		models.NotificationEventInvoiceOverdue,
+		models.NotificationEventReportDue,
+		models.NotificationEventDailyDigest,
+		models.NotificationEventDocumentRequestDue,
+	} {
+		s.html[event], s.text[event] = parseEmailTemplates(event)
+	}
+	return s
+}
+
+// parseEmailTemplates menggabungkan layout dengan template isi email event. Template di-embed
+// ke binary, jadi kegagalan parse adalah bug dan langsung panic.
+func parseEmailTemplates(event string) (*htmltemplate.Template, *texttemplate.Template) {
+	html := htmltemplate.Must(htmltemplate.New("layout.html").Funcs(emailTemplateFuncs).ParseFS(emailTemplateFS,
+		"templates/email/layout.html", "templates/email/"+event+".html"))
+	text := texttemplate.Must(texttemplate.New("layout.txt").Funcs(emailTemplateFuncs).ParseFS(emailTemplateFS,
+		"templates/email/layout.txt", "templates/email/"+event+".txt"))
+	return html, text
+}
+
+// emailData adalah data untuk layout email; field lain diisi sesuai jenis event.
+type emailData struct {
+	Subject   string
+	StaffName string
+	AppURL    string
+
+	Job            *models.JobDeadline
+	AssignedByName string
+	DaysLeft       int
+
+	Invoice     *models.OverdueInvoice
+	DaysOverdue int
+
+	Date         time.Time
+	Jobs         []models.JobDeadline
+	OverdueCount int
+
+	ClientPortal     bool // Email untuk user portal klien, bukan staf
+	ClientName       string
+	DocumentRequests []documentRequestLine
+
+	AccountEmail bool // Email keamanan akun, dikirim walaupun notifikasi email dimatikan
+	ResetURL     string
+	ExpiresAt    time.Time
+}
+
+// documentRequestLine adalah satu dokumen yang belum dikirim pada email pengingat ke klien.
+type documentRequestLine struct {
+	Title      string
+	JobType    string
+	Period     string
+	DueDate    time.Time
+	Overdue    bool
+	ReviewNote string // Alasan penolakan jika upload sebelumnya Ditolak
+}
+
+// HandleDomainEvent adalah subscriber event JobReassigned dan JobStatusChanged dari outbox.
+// Notifikasi diberi key per event, sehingga event yang dikirim ulang tidak menggandakan email.
+func (s *notificationService) HandleDomainEvent(ctx context.Context, event *models.DomainEvent) error {
+	switch event.EventType {
+	case models.DomainEventJobReassigned:
+		var assignment models.JobAssignment
+		if err := event.DecodePayload(&assignment); err != nil {
+			return err
+		}
+		if assignment.StaffID == "" || assignment.StaffID == assignment.AssignedBy {
+			return nil
+		}
+		return s.sendJobAssigned(ctx, event.EventID, assignment)
+	case models.DomainEventJobStatusChanged:
+		var change models.JobStatusChange
+		if err := event.DecodePayload(&change); err != nil {
+			return err
+		}
+		return s.notifyJobStatusChanged(ctx, event.EventID, change)
+	}
+	return nil
+}
+
+func (s *notificationService) sendJobAssigned(ctx context.Context, eventID string, assignment models.JobAssignment) error {
+	staff, err := s.staffRepo.GetStaffByID(ctx, assignment.StaffID)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil data staf: %w", err)
+	}
+	if staff == nil {
+		return nil
+	}
+
+	data := emailData{
+		Subject: fmt.Sprintf("Pekerjaan baru: %s - %s", assignment.JobType, assignment.ClientName),
+		Job: &models.JobDeadline{
+			JobType:    assignment.JobType,
+			JobID:      assignment.JobID,
+			ClientName: assignment.ClientName,
+			Period:     assignment.Period,
+		},
+	}
+	if assignment.AssignedBy != "" {
+		if assigner, err := s.staffRepo.GetStaffByID(ctx, assignment.AssignedBy); err == nil && assigner != nil {
+			data.AssignedByName = assigner.Nama
+		}
+	}
+
+	inApp := &models.Notification{
+		StaffID: staff.StaffID,
+		Event:   models.NotificationEventJobAssigned,
+		Title:   data.Subject,
+		Body:    assignment.Period,
+		JobType: &assignment.JobType,
+		JobID:   &assignment.JobID,
+	}
+	if data.AssignedByName != "" {
+		inApp.Body += " - ditugaskan oleh " + data.AssignedByName
+	}
+
+	key := models.NotificationEventJobAssigned + ":" + eventID
+	if err := s.createOnce(ctx, key, inApp); err != nil {
+		return err
+	}
+	return s.sendOnce(ctx, key, models.NotificationEventJobAssigned, staff.StaffID, staff.Email, staff.Nama, data)
+}
+
+func (s *notificationService) notifyJobStatusChanged(ctx context.Context, eventID string, change models.JobStatusChange) error {
+	// Dorongan realtime boleh terkirim dua kali; dashboard hanya memperbarui tampilan
+	if err := s.notificationRepo.PublishEvent(ctx, &models.RealtimeEvent{Type: models.RealtimeEventJobStatus, JobStatus: &change}); err != nil {
+		logger.FromContext(ctx).Warn("Gagal mendorong perubahan status pekerjaan", "job_type", change.JobType, "job_id", change.JobID, "error", err)
+	}
+	if change.StaffID == "" || change.StaffID == change.ChangedBy {
+		return nil
+	}
+	return s.createOnce(ctx, models.NotificationEventJobStatusChanged+":"+eventID, &models.Notification{
+		StaffID: change.StaffID,
+		Event:   models.NotificationEventJobStatusChanged,
+		Title:   fmt.Sprintf("Status %s - %s: %s", change.JobType, change.ClientName, change.NewStatus),
+		Body:    fmt.Sprintf("%s, sebelumnya %s", change.Period, change.OldStatus),
+		JobType: &change.JobType,
+		JobID:   &change.JobID,
+	})
+}
+
+func (s *notificationService) Start() {
+	s.workerLoop.run(s.run)
+}
+
+func (s *notificationService) run() {
+	ticker := time.NewTicker(notificationCheckInterval)
+	defer ticker.Stop()
+
+	ctx := logger.With(s.runContext(), "worker", "notification")
+	var lastRun string // Tanggal terakhir RunDaily berhasil, agar tidak query ulang setiap tick
+	check := func(now time.Time) {
+		today := now.Format("2006-01-02")
+		if now.Hour() < s.digestHour || today == lastRun {
+			return
+		}
+		if err := s.RunDaily(ctx, now); err != nil {
+			logger.FromContext(ctx).Warn("Gagal menjalankan notifikasi harian", "error", err)
+			return
+		}
+		lastRun = today
+	}
+
+	check(time.Now())
+	for {
+		select {
+		case <-s.stopping():
+			return
+		case now := <-ticker.C:
+			check(now)
+		}
+	}
+}
+
+func (s *notificationService) RunDaily(ctx context.Context, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := today.Format("2006-01-02")
+
+	deadlines, err := s.notificationRepo.GetOpenJobDeadlines(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Pengingat batas lapor: pekerjaan yang jatuh tempo tepat reportDueReminderDays hari lagi
+	reminderDate := today.AddDate(0, 0, reportDueReminderDays)
+	for i := range deadlines {
+		job := &deadlines[i]
+		if job.DueDate == nil || !sameDay(*job.DueDate, reminderDate) {
+			continue
+		}
+		data := emailData{
+			Subject:  fmt.Sprintf("Batas waktu %d hari lagi: %s - %s", reportDueReminderDays, job.ClientName, job.Period),
+			Job:      job,
+			DaysLeft: reportDueReminderDays,
+		}
+		key := fmt.Sprintf("%s:%s:%s", models.NotificationEventReportDue, job.JobID, job.DueDate.Format("2006-01-02"))
+		s.createOnce(ctx, key, &models.Notification{
+			StaffID: job.StaffID,
+			Event:   models.NotificationEventReportDue,
+			Title:   data.Subject,
+			Body:    fmt.Sprintf("%s, batas %s", job.JobType, formatTanggal(*job.DueDate)),
+			JobType: &job.JobType,
+			JobID:   &job.JobID,
+		})
+		s.sendOnce(ctx, key, models.NotificationEventReportDue, job.StaffID, job.StaffEmail, job.StaffName, data)
+	}
+
+	// Invoice lewat jatuh tempo, sekali per invoice
+	invoices, err := s.notificationRepo.GetOverdueInvoices(ctx, today)
+	if err != nil {
+		return err
+	}
+	for i := range invoices {
+		inv := &invoices[i]
+		data := emailData{
+			Subject:     fmt.Sprintf("Invoice %s lewat jatuh tempo - %s", inv.InvoiceNumber, inv.ClientName),
+			Invoice:     inv,
+			DaysOverdue: int(today.Sub(truncateDay(inv.DueDate, today.Location())).Hours() / 24),
+		}
+		key := fmt.Sprintf("%s:%s", models.NotificationEventInvoiceOverdue, inv.InvoiceID)
+		s.createOnce(ctx, key, &models.Notification{
+			StaffID:   inv.StaffID,
+			Event:     models.NotificationEventInvoiceOverdue,
+			Title:     data.Subject,
+			Body:      fmt.Sprintf("Jatuh tempo %s, total %s", formatTanggal(inv.DueDate), formatRupiah(inv.TotalAmount)),
+			InvoiceID: &inv.InvoiceID,
+		})
+		s.sendOnce(ctx, key, models.NotificationEventInvoiceOverdue, inv.StaffID, inv.StaffEmail, inv.StaffName, data)
+	}
+
+	// Ringkasan harian per staf; deadlines sudah terurut per staf
+	for start := 0; start < len(deadlines); {
+		end := start
+		for end < len(deadlines) && deadlines[end].StaffID == deadlines[start].StaffID {
+			end++
+		}
+		jobs := deadlines[start:end]
+		staff := jobs[0]
+		start = end
+
+		overdue := 0
+		for _, job := range jobs {
+			if job.DueDate != nil && isOverdue(job.DueDate, today) {
+				overdue++
+			}
+		}
+		data := emailData{
+			Subject:      fmt.Sprintf("Ringkasan harian %s: %d pekerjaan terbuka", formatTanggal(today), len(jobs)),
+			Date:         today,
+			Jobs:         jobs,
+			OverdueCount: overdue,
+		}
+		key := fmt.Sprintf("%s:%s:%s", models.NotificationEventDailyDigest, staff.StaffID, day)
+		s.sendOnce(ctx, key, models.NotificationEventDailyDigest, staff.StaffID, staff.StaffEmail, staff.StaffName, data)
+	}
+
+	return s.remindDocumentRequests(ctx, today)
+}
+
+// remindDocumentRequests mengirim satu email per user portal klien berisi dokumen yang
+// jatuh jadwal pengingatnya hari ini.
+func (s *notificationService) remindDocumentRequests(ctx context.Context, today time.Time) error {
+	reminders, err := s.docRequestRepo.GetOutstandingReminders(ctx, today.AddDate(0, 0, documentRequestReminderDays))
+	if err != nil {
+		return err
+	}
+	day := today.Format("2006-01-02")
+
+	// reminders sudah terurut per user portal
+	for start := 0; start < len(reminders); {
+		end := start
+		for end < len(reminders) && reminders[end].ClientUserID == reminders[start].ClientUserID {
+			end++
+		}
+		user := reminders[start]
+		var lines []documentRequestLine
+		for _, rem := range reminders[start:end] {
+			due := truncateDay(rem.Request.DueDate.Time, today.Location())
+			daysLeft := int(due.Sub(today).Hours() / 24)
+			if daysLeft != documentRequestReminderDays && daysLeft != 0 &&
+				(daysLeft > 0 || -daysLeft%documentRequestOverdueInterval != 0) {
+				continue
+			}
+			line := documentRequestLine{
+				Title:   rem.Request.Title,
+				JobType: rem.Request.JobType,
+				Period:  rem.Request.Period,
+				DueDate: due,
+				Overdue: daysLeft < 0,
+			}
+			if rem.Request.Status == models.DocumentRequestStatusRejected && rem.Request.ReviewNote != nil {
+				line.ReviewNote = *rem.Request.ReviewNote
+			}
+			lines = append(lines, line)
+		}
+		start = end
+		if len(lines) == 0 {
+			continue
+		}
+
+		data := emailData{
+			Subject:          fmt.Sprintf("Pengingat: %d dokumen belum dikirim - %s", len(lines), user.ClientName),
+			ClientPortal:     true,
+			ClientName:       user.ClientName,
+			DocumentRequests: lines,
+		}
+		key := fmt.Sprintf("%s:%s:%s", models.NotificationEventDocumentRequestDue, user.ClientUserID, day)
+		s.sendClientOnce(ctx, key, models.NotificationEventDocumentRequestDue, user.Email, user.Nama, data)
+	}
+	return nil
+}
+
+// sendOnce mengirim email jika preferensi mengizinkan dan key belum pernah terkirim.
+// Kegagalan dicatat di log dan key dilepas agar dicoba lagi pada jalannya berikutnya.
+func (s *notificationService) sendOnce(ctx context.Context, key, event, staffID, email, staffName string, data emailData) error {
+	if email == "" {
+		return nil
+	}
+	ok, err := s.allowed(ctx, staffID, event)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Gagal membaca preferensi notifikasi", "staff_id", staffID, "event", event, "error", err)
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	log := logger.FromContext(ctx).With("notification_key", key, "staff_id", staffID)
+	claimed, err := s.notificationRepo.ClaimNotification(ctx, key, staffID, event)
+	if err != nil {
+		log.Warn("Gagal mencatat notifikasi", "error", err)
+		return err
+	}
+	if !claimed {
+		return nil // Sudah terkirim sebelumnya
+	}
+	if err := s.send(event, email, staffName, data); err != nil {
+		log.Warn("Gagal mengirim notifikasi", "error", err)
+		if err := s.notificationRepo.ReleaseNotification(ctx, key); err != nil {
+			log.Warn("Gagal melepas notifikasi untuk dicoba lagi", "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// sendClientOnce mengirim email ke user portal klien sekali per key. Seperti sendOnce, tetapi
+// tanpa preferensi notifikasi karena klien tidak punya pengaturan tersebut.
+func (s *notificationService) sendClientOnce(ctx context.Context, key, event, email, name string, data emailData) error {
+	if email == "" {
+		return nil
+	}
+	log := logger.FromContext(ctx).With("notification_key", key)
+	claimed, err := s.notificationRepo.ClaimNotification(ctx, key, "", event)
+	if err != nil {
+		log.Warn("Gagal mencatat notifikasi", "error", err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	if err := s.send(event, email, name, data); err != nil {
+		log.Warn("Gagal mengirim notifikasi", "error", err)
+		if err := s.notificationRepo.ReleaseNotification(ctx, key); err != nil {
+			log.Warn("Gagal melepas notifikasi untuk dicoba lagi", "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// createOnce membuat notifikasi in-app sekali per key. Notifikasi in-app tidak
+// mengikuti preferensi email, karena hanya muncul di ikon lonceng.
+func (s *notificationService) createOnce(ctx context.Context, key string, n *models.Notification) error {
+	inAppKey := "inapp:" + key
+	log := logger.FromContext(ctx).With("notification_key", inAppKey, "staff_id", n.StaffID)
+	claimed, err := s.notificationRepo.ClaimNotification(ctx, inAppKey, n.StaffID, n.Event)
+	if err != nil {
+		log.Warn("Gagal mencatat notifikasi", "error", err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	if err := s.notificationRepo.CreateNotification(ctx, n); err != nil {
+		log.Warn("Gagal membuat notifikasi in-app", "error", err)
+		if err := s.notificationRepo.ReleaseNotification(ctx, inAppKey); err != nil {
+			log.Warn("Gagal melepas notifikasi untuk dicoba lagi", "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *notificationService) allowed(ctx context.Context, staffID, event string) (bool, error) {
+	prefs, err := s.notificationRepo.GetPreferences(ctx, staffID)
+	if err != nil {
+		return false, err
+	}
+	return prefs.Enabled(event), nil
+}
+
+func (s *notificationService) send(event, email, staffName string, data emailData) error {
+	data.StaffName = staffName
+	data.AppURL = s.appURL
+	return sendEmail(s.mailer, s.html[event], s.text[event], email, data)
+}
+
+// sendEmail menyusun email HTML dan teks dari template lalu mengirimnya ke satu alamat.
+func sendEmail(m mailer.Mailer, html *htmltemplate.Template, text *texttemplate.Template, email string, data emailData) error {
+	var htmlBody, textBody bytes.Buffer
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return fmt.Errorf("gagal menyusun email HTML %s: %w", data.Subject, err)
+	}
+	if err := text.Execute(&textBody, data); err != nil {
+		return fmt.Errorf("gagal menyusun email teks %s: %w", data.Subject, err)
+	}
+	return m.Send(mailer.Message{
+		To:      []string{email},
+		Subject: data.Subject,
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	})
+}
+
+var emailTemplateFuncs = map[string]interface{}{
+	"tanggal": func(v interface{}) string {
+		switch t := v.(type) {
+		case time.Time:
+			return formatTanggal(t)
+		case *time.Time:
+			if t != nil {
+				return formatTanggal(*t)
+			}
+		}
+		return "-"
+	},
+	// jam menulis tanggal dan jam di zona waktu kantor, mis. "5 Maret 2025 pukul 14:30 WIB"
+	"jam": func(t time.Time) string {
+		t = t.In(time.Local)
+		return formatTanggal(t) + " pukul " + t.Format("15:04 MST")
+	},
+	"rupiah":  formatRupiah,
+	"overdue": isOverdue,
+}
+
+var indonesianMonths = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// formatTanggal menulis tanggal dalam format Indonesia, mis. "20 Februari 2025".
+func formatTanggal(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// formatRupiah menulis nominal dengan pemisah ribuan titik, mis. "Rp 1.500.000".
+func formatRupiah(amount float64) string {
+	digits := strconv.FormatFloat(amount, 'f', 0, 64)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	if negative {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+func isOverdue(due *time.Time, today time.Time) bool {
+	return due != nil && taxcalendar.IsPastDue(truncateDay(*due, today.Location()), today)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// truncateDay membaca tanggal DATE (yang dipindai sebagai UTC tengah malam) sebagai tanggal di loc.
+func truncateDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
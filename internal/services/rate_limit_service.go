@@ -0,0 +1,43 @@
+package services
+
+import (
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// rateLimitCleanupInterval adalah jarak pembersihan bucket rate limit yang sudah penuh kembali.
+const rateLimitCleanupInterval = 10 * time.Minute
+
+// rateLimitJanitor menghapus bucket idle dari tabel rate_limit_buckets secara berkala, agar
+// tabelnya tidak terus bertambah oleh IP dan email yang hanya sekali mencoba login.
+type rateLimitJanitor struct {
+	repo repositories.RateLimitRepository
+	*workerLoop
+}
+
+// NewRateLimitJanitor adalah constructor untuk worker pembersih store rate limit Postgres.
+func NewRateLimitJanitor(repo repositories.RateLimitRepository) Worker {
+	return &rateLimitJanitor{repo: repo, workerLoop: newWorkerLoop()}
+}
+
+func (j *rateLimitJanitor) Start() {
+	j.workerLoop.run(j.loop)
+}
+
+func (j *rateLimitJanitor) loop() {
+	ticker := time.NewTicker(rateLimitCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.stopping():
+			return
+		case <-ticker.C:
+			if _, err := j.repo.DeleteIdleBuckets(j.runContext()); err != nil {
+				logger.FromContext(j.runContext()).Warn("Gagal membersihkan bucket rate limit", "worker", "rate_limit_janitor", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// RealtimeService meneruskan event dari PostgreSQL LISTEN ke dashboard yang sedang terhubung.
+// Setiap instance API mendengarkan channel yang sama, sehingga event yang di-NOTIFY
+// oleh instance mana pun sampai ke semua pelanggan.
+type RealtimeService interface {
+	// Subscribe mendaftarkan koneksi dashboard milik staf. Panggil Unsubscribe saat koneksi ditutup.
+	Subscribe(staffID string, isAdmin bool) *Subscription
+	Unsubscribe(sub *Subscription)
+	// Start membuka koneksi LISTEN dan mulai meneruskan event di background.
+	Start()
+	// Stop menutup koneksi LISTEN dan semua subscription, sehingga stream dashboard yang
+	// masih terbuka selesai dan tidak menahan shutdown server.
+	Stop(ctx context.Context) error
+}
+
+// Subscription adalah satu koneksi dashboard yang menerima event.
+type Subscription struct {
+	Events  <-chan models.RealtimeEvent
+	events  chan models.RealtimeEvent
+	staffID string
+	isAdmin bool
+}
+
+const (
+	subscriptionBufferSize = 32
+	listenerMinReconnect   = 10 * time.Second
+	listenerMaxReconnect   = time.Minute
+	listenerPingInterval   = 90 * time.Second
+)
+
+// realtimeService adalah implementasi dari RealtimeService.
+type realtimeService struct {
+	databaseURL string
+
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+	*workerLoop
+}
+
+// NewRealtimeService adalah constructor untuk realtimeService.
+// databaseURL dipakai untuk koneksi LISTEN tersendiri di luar pool database/sql.
+func NewRealtimeService(databaseURL string) RealtimeService {
+	return &realtimeService{
+		databaseURL: databaseURL,
+		subs:        make(map[*Subscription]struct{}),
+		workerLoop:  newWorkerLoop(),
+	}
+}
+
+func (s *realtimeService) Subscribe(staffID string, isAdmin bool) *Subscription {
+	events := make(chan models.RealtimeEvent, subscriptionBufferSize)
+	sub := &Subscription{Events: events, events: events, staffID: staffID, isAdmin: isAdmin}
+
+	s.mu.Lock()
+	if s.isStopping() {
+		close(events) // Server sedang shutdown; stream langsung selesai
+	} else {
+		s.subs[sub] = struct{}{}
+	}
+	s.mu.Unlock()
+	return sub
+}
+
+func (s *realtimeService) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.events)
+	}
+	s.mu.Unlock()
+}
+
+func (s *realtimeService) Start() {
+	listener := pq.NewListener(s.databaseURL, listenerMinReconnect, listenerMaxReconnect,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				logger.FromContext(s.runContext()).Warn("Koneksi LISTEN realtime bermasalah", "error", err)
+			}
+		})
+	// Listen menunggu sampai koneksi tersambung, jadi jangan tahan startup. Selama belum
+	// tersambung, stream dashboard tetap terbuka tetapi belum menerima event.
+	go func() {
+		if err := listener.Listen(repositories.RealtimeChannel); err != nil {
+			logger.FromContext(s.runContext()).Warn("Gagal LISTEN, notifikasi realtime nonaktif",
+				"channel", repositories.RealtimeChannel, "error", err)
+		}
+	}()
+	s.workerLoop.run(func() { s.run(listener) })
+}
+
+func (s *realtimeService) Stop(ctx context.Context) error {
+	err := s.workerLoop.Stop(ctx)
+
+	// Stream yang masih terbuka berhenti saat channel event-nya ditutup
+	s.mu.Lock()
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.events)
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *realtimeService) run(listener *pq.Listener) {
+	defer listener.Close()
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	ctx := logger.With(s.runContext(), "worker", "realtime")
+	for {
+		select {
+		case <-s.stopping():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				// Koneksi tersambung ulang; event selama terputus hilang,
+				// dashboard tetap bisa memuat ulang lewat GET /me/notifications.
+				continue
+			}
+			var event models.RealtimeEvent
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				logger.FromContext(ctx).Warn("Payload realtime tidak valid", "error", err)
+				continue
+			}
+			s.dispatch(ctx, event)
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// dispatch mengirim event ke pelanggan yang berhak melihatnya: notifikasi hanya ke pemiliknya,
+// perubahan status pekerjaan ke admin dan PIC pekerjaan tersebut.
+func (s *realtimeService) dispatch(ctx context.Context, event models.RealtimeEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subs {
+		switch event.Type {
+		case models.RealtimeEventNotification:
+			if event.Notification == nil || event.Notification.StaffID != sub.staffID {
+				continue
+			}
+		case models.RealtimeEventJobStatus:
+			if event.JobStatus == nil || (!sub.isAdmin && event.JobStatus.StaffID != sub.staffID) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			// Dashboard terlalu lambat membaca; event dibuang daripada menahan pelanggan lain
+			logger.FromContext(ctx).Warn("Buffer realtime penuh, event dibuang", "staff_id", sub.staffID, "realtime_event", event.Type)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxcalendar"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/taxpayment"
+	"github.com/xuri/excelize/v2"
+)
+
+// ReportService menyusun laporan manajemen yang membutuhkan logika di atas query repository.
+type ReportService interface {
+	// ComplianceMatrix menyusun matriks klien x bulan untuk tahun pajak year.
+	ComplianceMatrix(ctx context.Context, year int, staffIDFilter string, isAdmin bool) (*models.ComplianceMatrix, error)
+	// WriteComplianceMatrixXLSX menulis matriks sebagai file XLSX dengan sel berwarna sesuai status.
+	WriteComplianceMatrixXLSX(w io.Writer, matrix *models.ComplianceMatrix) error
+	// RevenueReport menyusun pendapatan ditagih dan diterima untuk year, dibandingkan dengan compareYear.
+	RevenueReport(ctx context.Context, year, compareYear int, staffIDFilter string, isAdmin bool) (*models.RevenueReport, error)
+}
+
+// reportService adalah implementasi dari ReportService.
+type reportService struct {
+	clientRepo repositories.ClientRepository
+	reportRepo repositories.ReportRepository
+}
+
+// NewReportService adalah constructor untuk reportService.
+func NewReportService(cRepo repositories.ClientRepository, rRepo repositories.ReportRepository) ReportService {
+	return &reportService{
+		clientRepo: cRepo,
+		reportRepo: rRepo,
+	}
+}
+
+func (s *reportService) ComplianceMatrix(ctx context.Context, year int, staffIDFilter string, isAdmin bool) (*models.ComplianceMatrix, error) {
+	clients, err := s.clientRepo.GetAllClients(ctx, staffIDFilter, isAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data klien: %w", err)
+	}
+	entries, err := s.reportRepo.GetComplianceEntries(ctx, year, staffIDFilter, isAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pekerjaan bulanan: %w", err)
+	}
+
+	// Kelompokkan per klien lalu per bulan
+	type monthData struct {
+		jobID     string
+		jobStatus string
+		reports   map[string]*models.MonthlyTaxReport
+	}
+	byClient := make(map[string]map[int]*monthData)
+	for _, entry := range entries {
+		months, ok := byClient[entry.ClientID]
+		if !ok {
+			months = make(map[int]*monthData)
+			byClient[entry.ClientID] = months
+		}
+		month, ok := months[entry.JobMonth]
+		if !ok {
+			month = &monthData{jobID: entry.JobID, jobStatus: entry.OverallStatus, reports: make(map[string]*models.MonthlyTaxReport)}
+			months[entry.JobMonth] = month
+		}
+		if entry.Report != nil {
+			month.reports[entry.Report.TaxType] = entry.Report
+		}
+	}
+
+	now := time.Now()
+	matrix := &models.ComplianceMatrix{
+		Year:        year,
+		GeneratedAt: now,
+		Summary:     make(map[string]int),
+		Rows:        make([]models.ComplianceMatrixRow, 0, len(clients)),
+	}
+
+	for _, client := range clients {
+		months := byClient[client.ClientID]
+
+		// Jenis pajak yang dicentang, ditambah jenis pajak lain yang ternyata punya laporan
+		subscribed := make(map[string]bool)
+		taxTypes := models.SubscribedMonthlyTaxTypes(client)
+		for _, taxType := range taxTypes {
+			subscribed[taxType] = true
+		}
+		shown := make(map[string]bool)
+		for _, month := range months {
+			for taxType := range month.reports {
+				if !subscribed[taxType] && !shown[taxType] {
+					shown[taxType] = true
+					taxTypes = append(taxTypes, taxType)
+				}
+			}
+		}
+
+		row := models.ComplianceMatrixRow{
+			ClientID:          client.ClientID,
+			ClientName:        client.ClientName,
+			NpwpClient:        client.NpwpClient,
+			PicStaffSigmaName: client.PicStaffSigmaName,
+			TaxTypes:          taxTypes,
+			Months:            make([]models.ComplianceMonthCell, 12),
+		}
+
+		for m := 1; m <= 12; m++ {
+			due := taxcalendar.MonthlyDueDate(year, m, now.Location())
+			pastDue := taxcalendar.IsPastDue(due, now)
+			cell := models.ComplianceMonthCell{Month: m, DueDate: due, Taxes: []models.ComplianceTaxCell{}}
+			month := months[m]
+			if month != nil {
+				jobID := month.jobID
+				cell.JobID = &jobID
+				cell.JobStatus = month.jobStatus
+			}
+
+			for _, taxType := range taxTypes {
+				taxCell := models.ComplianceTaxCell{TaxType: taxType, Subscribed: subscribed[taxType]}
+				var report *models.MonthlyTaxReport
+				if month != nil {
+					report = month.reports[taxType]
+				}
+				if report == nil && !taxCell.Subscribed {
+					continue // Layanan tidak dicentang dan tidak ada laporan bulan ini
+				}
+				fillComplianceTaxCell(&taxCell, month != nil, report, pastDue)
+				cell.Taxes = append(cell.Taxes, taxCell)
+				matrix.Summary[taxCell.Status]++
+
+				if cell.Status == "" || models.ComplianceStatusSeverity[taxCell.Status] > models.ComplianceStatusSeverity[cell.Status] {
+					cell.Status = taxCell.Status
+				}
+			}
+			row.Months[m-1] = cell
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	return matrix, nil
+}
+
+// fillComplianceTaxCell menentukan status satu jenis pajak pada satu bulan.
+// Sebelum jatuh tempo, kekurangan apa pun belum dianggap masalah (upcoming).
+func fillComplianceTaxCell(cell *models.ComplianceTaxCell, jobExists bool, report *models.MonthlyTaxReport, pastDue bool) {
+	switch {
+	case !jobExists:
+		cell.Status = models.ComplianceStatusMissingJob
+	case report == nil:
+		cell.Status = models.ComplianceStatusMissingReport
+	default:
+		reportID := report.ReportID
+		cell.ReportID = &reportID
+		// Sama dengan aturan bukti bayar: lunas jika ada NTPN yang valid atau statusnya lunas.
+		// Tanggal bayar saja belum membuktikan pembayaran.
+		cell.Paid = taxpayment.ValidateNTPN(taxpayment.NormalizeNTPN(report.Ntpn)) == nil ||
+			models.IsPaidReportStatus(report.ReportStatus)
+		cell.Nihil = !cell.Paid && ((report.PaymentAmount != nil && *report.PaymentAmount == 0) ||
+			(report.PaymentAmount == nil && report.BillingAmount != nil && *report.BillingAmount == 0))
+		cell.Reported = report.ReportDate != nil
+
+		switch {
+		case cell.Reported && (cell.Paid || cell.Nihil):
+			cell.Status = models.ComplianceStatusComplete
+		case cell.Reported:
+			cell.Status = models.ComplianceStatusUnpaid
+		case cell.Paid:
+			cell.Status = models.ComplianceStatusUnreported
+		default:
+			cell.Status = models.ComplianceStatusPending
+		}
+	}
+	if cell.Status != models.ComplianceStatusComplete && !pastDue {
+		cell.Status = models.ComplianceStatusUpcoming
+	}
+}
+
+// complianceStatusLabels dan complianceStatusColors dipakai untuk ekspor XLSX.
+var complianceStatusLabels = map[string]string{
+	models.ComplianceStatusComplete:      "Bayar & lapor",
+	models.ComplianceStatusUpcoming:      "Belum jatuh tempo",
+	models.ComplianceStatusPending:       "Belum bayar & lapor",
+	models.ComplianceStatusUnreported:    "Bayar, belum lapor",
+	models.ComplianceStatusUnpaid:        "Lapor, belum bayar",
+	models.ComplianceStatusMissingReport: "Tidak ada laporan",
+	models.ComplianceStatusMissingJob:    "Tidak ada pekerjaan",
+}
+
+var complianceStatusColors = map[string]string{
+	models.ComplianceStatusComplete:      "C6EFCE",
+	models.ComplianceStatusUpcoming:      "EDEDED",
+	models.ComplianceStatusPending:       "FFEB9C",
+	models.ComplianceStatusUnreported:    "F8CBAD",
+	models.ComplianceStatusUnpaid:        "F8CBAD",
+	models.ComplianceStatusMissingReport: "FFC7CE",
+	models.ComplianceStatusMissingJob:    "FFC7CE",
+}
+
+var complianceMonthNames = []string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"}
+
+func (s *reportService) WriteComplianceMatrixXLSX(w io.Writer, matrix *models.ComplianceMatrix) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := fmt.Sprintf("Kepatuhan %d", matrix.Year)
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return fmt.Errorf("gagal menyiapkan sheet: %w", err)
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return fmt.Errorf("gagal membuat style header: %w", err)
+	}
+	statusStyles := make(map[string]int)
+	for status, color := range complianceStatusColors {
+		style, err := f.NewStyle(&excelize.Style{
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		})
+		if err != nil {
+			return fmt.Errorf("gagal membuat style status: %w", err)
+		}
+		statusStyles[status] = style
+	}
+
+	header := append([]interface{}{"Klien", "NPWP", "PIC", "Jenis Pajak"}, stringsToInterfaces(complianceMonthNames)...)
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return fmt.Errorf("gagal menulis header: %w", err)
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	if err := f.SetCellStyle(sheet, "A1", lastCol+"1", headerStyle); err != nil {
+		return fmt.Errorf("gagal memberi style header: %w", err)
+	}
+
+	rowNum := 2
+	for _, row := range matrix.Rows {
+		for _, taxType := range row.TaxTypes {
+			values := []interface{}{row.ClientName, row.NpwpClient, row.PicStaffSigmaName, taxType}
+			cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+			if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+				return fmt.Errorf("gagal menulis baris klien %s: %w", row.ClientName, err)
+			}
+			for m, month := range row.Months {
+				taxCell, ok := findComplianceTaxCell(month.Taxes, taxType)
+				if !ok {
+					continue
+				}
+				label := complianceStatusLabels[taxCell.Status]
+				if taxCell.Status == models.ComplianceStatusComplete && taxCell.Nihil {
+					label = "Nihil & lapor"
+				}
+				ref, _ := excelize.CoordinatesToCellName(5+m, rowNum)
+				if err := f.SetCellValue(sheet, ref, label); err != nil {
+					return fmt.Errorf("gagal menulis sel %s: %w", ref, err)
+				}
+				if err := f.SetCellStyle(sheet, ref, ref, statusStyles[taxCell.Status]); err != nil {
+					return fmt.Errorf("gagal memberi style sel %s: %w", ref, err)
+				}
+			}
+			rowNum++
+		}
+	}
+
+	if err := f.SetColWidth(sheet, "A", "A", 32); err != nil {
+		return fmt.Errorf("gagal mengatur lebar kolom: %w", err)
+	}
+	if err := f.SetColWidth(sheet, "B", "D", 20); err != nil {
+		return fmt.Errorf("gagal mengatur lebar kolom: %w", err)
+	}
+	if err := f.SetColWidth(sheet, "E", lastCol, 14); err != nil {
+		return fmt.Errorf("gagal mengatur lebar kolom: %w", err)
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, XSplit: 4, YSplit: 1, TopLeftCell: "E2", ActivePane: "bottomRight"}); err != nil {
+		return fmt.Errorf("gagal membekukan header: %w", err)
+	}
+
+	// Sheet keterangan warna
+	legend := "Keterangan"
+	if _, err := f.NewSheet(legend); err != nil {
+		return fmt.Errorf("gagal membuat sheet keterangan: %w", err)
+	}
+	legendStatuses := []string{
+		models.ComplianceStatusComplete, models.ComplianceStatusUpcoming, models.ComplianceStatusPending,
+		models.ComplianceStatusUnreported, models.ComplianceStatusUnpaid,
+		models.ComplianceStatusMissingReport, models.ComplianceStatusMissingJob,
+	}
+	for i, status := range legendStatuses {
+		labelRef, _ := excelize.CoordinatesToCellName(1, i+1)
+		countRef, _ := excelize.CoordinatesToCellName(2, i+1)
+		if err := f.SetCellValue(legend, labelRef, complianceStatusLabels[status]); err != nil {
+			return fmt.Errorf("gagal menulis keterangan: %w", err)
+		}
+		if err := f.SetCellStyle(legend, labelRef, labelRef, statusStyles[status]); err != nil {
+			return fmt.Errorf("gagal memberi style keterangan: %w", err)
+		}
+		if err := f.SetCellValue(legend, countRef, matrix.Summary[status]); err != nil {
+			return fmt.Errorf("gagal menulis keterangan: %w", err)
+		}
+	}
+	if err := f.SetColWidth(legend, "A", "A", 24); err != nil {
+		return fmt.Errorf("gagal mengatur lebar kolom: %w", err)
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("gagal menulis file XLSX: %w", err)
+	}
+	return nil
+}
+
+func findComplianceTaxCell(cells []models.ComplianceTaxCell, taxType string) (models.ComplianceTaxCell, bool) {
+	for _, cell := range cells {
+		if cell.TaxType == taxType {
+			return cell, true
+		}
+	}
+	return models.ComplianceTaxCell{}, false
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func (s *reportService) RevenueReport(ctx context.Context, year, compareYear int, staffIDFilter string, isAdmin bool) (*models.RevenueReport, error) {
+	entries, err := s.reportRepo.GetRevenueEntries(ctx, []int{year, compareYear}, staffIDFilter, isAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pendapatan: %w", err)
+	}
+
+	report := &models.RevenueReport{Year: year, CompareYear: compareYear}
+	byMonth := newRevenueGroups()
+	byJobType := newRevenueGroups()
+	byCategory := newRevenueGroups()
+	byStaff := newRevenueGroups()
+	for m := 1; m <= 12; m++ {
+		byMonth.get(fmt.Sprintf("%02d", m), complianceMonthNames[m-1])
+	}
+	for _, jobType := range models.JobTypes {
+		byJobType.get(jobType, jobType)
+	}
+
+	for _, entry := range entries {
+		var current bool
+		switch entry.Year {
+		case year:
+			current = true
+		case compareYear:
+			current = false
+		default:
+			continue
+		}
+
+		jobTypeKey, jobTypeLabel := entry.JobType, entry.JobType
+		if jobTypeKey == "" {
+			jobTypeLabel = "Lainnya"
+		}
+		categoryKey, categoryLabel := entry.ClientCategory, entry.ClientCategory
+		if categoryKey == "" {
+			categoryLabel = "Tanpa Kategori"
+		}
+		staffKey, staffLabel := "", "Belum ada PIC"
+		if entry.StaffID != nil {
+			staffKey, staffLabel = *entry.StaffID, entry.StaffName
+		}
+
+		for _, amounts := range []*models.RevenueAmounts{
+			&report.Totals,
+			byMonth.get(fmt.Sprintf("%02d", entry.Month), complianceMonthNames[entry.Month-1]),
+			byJobType.get(jobTypeKey, jobTypeLabel),
+			byCategory.get(categoryKey, categoryLabel),
+			byStaff.get(staffKey, staffLabel),
+		} {
+			addRevenue(amounts, entry.Kind, current, entry.Amount)
+		}
+	}
+
+	fillRevenueGrowth(&report.Totals)
+	report.ByMonth = byMonth.list(false)
+	report.ByJobType = byJobType.list(false)
+	report.ByClientCategory = byCategory.list(true)
+	report.ByStaff = byStaff.list(true)
+	return report, nil
+}
+
+func addRevenue(amounts *models.RevenueAmounts, kind string, current bool, amount float64) {
+	switch {
+	case kind == models.RevenueKindBilled && current:
+		amounts.Billed += amount
+	case kind == models.RevenueKindBilled:
+		amounts.PreviousBilled += amount
+	case kind == models.RevenueKindCollected && current:
+		amounts.Collected += amount
+	case kind == models.RevenueKindCollected:
+		amounts.PreviousCollected += amount
+	}
+}
+
+func fillRevenueGrowth(amounts *models.RevenueAmounts) {
+	growth := func(current, previous float64) *float64 {
+		if previous == 0 {
+			return nil
+		}
+		pct := math.Round((current-previous)/previous*10000) / 100
+		return &pct
+	}
+	amounts.BilledGrowth = growth(amounts.Billed, amounts.PreviousBilled)
+	amounts.CollectedGrowth = growth(amounts.Collected, amounts.PreviousCollected)
+}
+
+// revenueGroups mengumpulkan RevenueAmounts per key dengan urutan kemunculan pertama.
+type revenueGroups struct {
+	items []*models.RevenueBreakdownItem
+	index map[string]*models.RevenueBreakdownItem
+}
+
+func newRevenueGroups() *revenueGroups {
+	return &revenueGroups{index: make(map[string]*models.RevenueBreakdownItem)}
+}
+
+func (g *revenueGroups) get(key, label string) *models.RevenueAmounts {
+	item, ok := g.index[key]
+	if !ok {
+		item = &models.RevenueBreakdownItem{Key: key, Label: label}
+		g.index[key] = item
+		g.items = append(g.items, item)
+	}
+	return &item.RevenueAmounts
+}
+
+// list mengembalikan semua grup; byBilled mengurutkan dari tagihan terbesar.
+func (g *revenueGroups) list(byBilled bool) []models.RevenueBreakdownItem {
+	out := make([]models.RevenueBreakdownItem, 0, len(g.items))
+	for _, item := range g.items {
+		fillRevenueGrowth(&item.RevenueAmounts)
+		out = append(out, *item)
+	}
+	if byBilled {
+		sort.SliceStable(out, func(i, j int) bool {
+			if out[i].Billed != out[j].Billed {
+				return out[i].Billed > out[j].Billed
+			}
+			return out[i].PreviousBilled > out[j].PreviousBilled
+		})
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+)
+
+func TestFillComplianceTaxCell(t *testing.T) {
+	day := time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)
+	zero, amount := 0.0, 1500000.0
+	tests := []struct {
+		name     string
+		report   models.MonthlyTaxReport
+		pastDue  bool
+		paid     bool
+		nihil    bool
+		reported bool
+		status   string
+	}{
+		{"reported and paid with NTPN", models.MonthlyTaxReport{ReportDate: &day, PaymentDate: &day,
+			TaxPaymentEvidence: models.TaxPaymentEvidence{Ntpn: "0A1B2C3D4E5F6G7H"}}, true, true, false, true, models.ComplianceStatusComplete},
+		{"NTPN with separators", models.MonthlyTaxReport{ReportDate: &day,
+			TaxPaymentEvidence: models.TaxPaymentEvidence{Ntpn: "0a1b-2c3d-4e5f-6g7h"}}, true, true, false, true, models.ComplianceStatusComplete},
+		{"paid status without NTPN", models.MonthlyTaxReport{ReportStatus: "Lunas"}, true, true, false, false, models.ComplianceStatusUnreported},
+		// Tanggal bayar tanpa NTPN yang valid belum dihitung lunas
+		{"payment date only", models.MonthlyTaxReport{ReportDate: &day, PaymentDate: &day, PaymentAmount: &amount},
+			true, false, false, true, models.ComplianceStatusUnpaid},
+		{"invalid NTPN", models.MonthlyTaxReport{ReportDate: &day, PaymentDate: &day,
+			TaxPaymentEvidence: models.TaxPaymentEvidence{Ntpn: "12345"}}, true, false, false, true, models.ComplianceStatusUnpaid},
+		{"nihil", models.MonthlyTaxReport{ReportDate: &day, PaymentAmount: &zero}, true, false, true, true, models.ComplianceStatusComplete},
+		{"nothing done", models.MonthlyTaxReport{}, true, false, false, false, models.ComplianceStatusPending},
+		{"nothing done before due date", models.MonthlyTaxReport{}, false, false, false, false, models.ComplianceStatusUpcoming},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.report
+			report.ReportID = "report-1"
+			var cell models.ComplianceTaxCell
+			fillComplianceTaxCell(&cell, true, &report, tt.pastDue)
+			if cell.Paid != tt.paid || cell.Nihil != tt.nihil || cell.Reported != tt.reported || cell.Status != tt.status {
+				t.Errorf("cell = paid %v, nihil %v, reported %v, status %q; want %v, %v, %v, %q",
+					cell.Paid, cell.Nihil, cell.Reported, cell.Status, tt.paid, tt.nihil, tt.reported, tt.status)
+			}
+			if cell.ReportID == nil || *cell.ReportID != "report-1" {
+				t.Errorf("ReportID = %v, want report-1", cell.ReportID)
+			}
+		})
+	}
+
+	var cell models.ComplianceTaxCell
+	fillComplianceTaxCell(&cell, false, nil, true)
+	if cell.Status != models.ComplianceStatusMissingJob {
+		t.Errorf("without job: status %q, want %q", cell.Status, models.ComplianceStatusMissingJob)
+	}
+	cell = models.ComplianceTaxCell{}
+	fillComplianceTaxCell(&cell, true, nil, true)
+	if cell.Status != models.ComplianceStatusMissingReport {
+		t.Errorf("without report: status %q, want %q", cell.Status, models.ComplianceStatusMissingReport)
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// SlowRepositoryLogger adalah trace hook yang mencatat panggilan repository yang lebih lama
+// dari threshold, atau yang dibatalkan karena statement timeout. Log memakai logger di
+// context, jadi request_id dan staff_id request asalnya ikut tercatat.
+func SlowRepositoryLogger(threshold time.Duration) repositories.TraceFunc {
+	return func(ctx context.Context, repository, method string) (context.Context, func(error)) {
+		start := time.Now()
+		return ctx, func(err error) {
+			elapsed := time.Since(start)
+			log := logger.FromContext(ctx)
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				log.Warn("repository call timed out", "repository", repository, "method", method, "duration", elapsed)
+			case errors.Is(err, context.Canceled):
+				log.Debug("repository call canceled", "repository", repository, "method", method, "duration", elapsed)
+			case threshold > 0 && elapsed >= threshold:
+				log.Warn("slow repository call", "repository", repository, "method", method, "duration", elapsed)
+			}
+		}
+	}
+}
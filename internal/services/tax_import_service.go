@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/djpimport"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/npwp"
+)
+
+// TaxImportFile adalah satu file ekspor DJP yang diupload.
+type TaxImportFile struct {
+	Name   string
+	Reader io.Reader
+}
+
+// TaxImportService mendefinisikan operasi impor ekspor e-Bupot dan e-Faktur ke laporan pajak bulanan.
+type TaxImportService interface {
+	// ImportMonthlyJobFiles membaca file-file ekspor, menghitung total per file dan jenis pajak,
+	// lalu membuat atau memperbarui MonthlyTaxReport milik pekerjaan tersebut. Total laporan
+	// mencakup file yang diimpor sebelumnya, sehingga faktur keluaran dan masukan boleh diupload
+	// terpisah; file dengan nama yang sama menggantikan hasil impor sebelumnya.
+	ImportMonthlyJobFiles(ctx context.Context, job *models.MonthlyJob, files []TaxImportFile) (*models.TaxImportResult, error)
+}
+
+// taxImportService adalah implementasi dari TaxImportService.
+type taxImportService struct {
+	monthlyJobRepo repositories.MonthlyJobRepository
+	taxImportRepo  repositories.TaxImportRepository
+}
+
+// NewTaxImportService adalah constructor untuk taxImportService.
+func NewTaxImportService(mjRepo repositories.MonthlyJobRepository, tiRepo repositories.TaxImportRepository) TaxImportService {
+	return &taxImportService{monthlyJobRepo: mjRepo, taxImportRepo: tiRepo}
+}
+
+func (s *taxImportService) ImportMonthlyJobFiles(ctx context.Context, job *models.MonthlyJob, files []TaxImportFile) (*models.TaxImportResult, error) {
+	result := &models.TaxImportResult{
+		JobID:  job.JobID,
+		Files:  []models.TaxImportFileSummary{},
+		Totals: []models.TaxImportTotal{},
+		Errors: []models.TaxImportLineError{},
+	}
+	sources := make(map[string][]models.TaxImportSource) // Per jenis pajak, satu per file
+
+	for _, f := range files {
+		summary := models.TaxImportFileSummary{FileName: f.Name}
+
+		parsed, err := djpimport.Parse(f.Name, f.Reader)
+		if err != nil {
+			summary.Format = "unknown"
+			result.Files = append(result.Files, summary)
+			result.Errors = append(result.Errors, models.TaxImportLineError{FileName: f.Name, Reason: err.Error()})
+			continue
+		}
+		summary.Format = parsed.Format
+		summary.LinesRead = len(parsed.Lines) + len(parsed.Errors)
+
+		for _, e := range parsed.Errors {
+			result.Errors = append(result.Errors, models.TaxImportLineError{
+				FileName: f.Name, Line: e.LineNo, Reference: e.Reference, Reason: e.Reason,
+			})
+		}
+
+		for _, line := range parsed.Lines {
+			if reason := validateImportLine(job, line); reason != "" {
+				result.Errors = append(result.Errors, models.TaxImportLineError{
+					FileName: f.Name, Line: line.LineNo, Reference: line.Reference, Reason: reason,
+				})
+				continue
+			}
+			summary.LinesImported++
+
+			var taxType string
+			switch line.Kind {
+			case djpimport.KindBupotUnifikasi:
+				taxType = models.TaxTypePphUnifikasi
+			case djpimport.KindFakturKeluaran, djpimport.KindFakturMasukan:
+				taxType = models.TaxTypePpn
+			}
+			src := importSource(sources, taxType, f.Name)
+			src.LineCount++
+
+			switch {
+			case line.Kind == djpimport.KindFakturMasukan:
+				// PPN masukan mengurangi PPN terutang hanya jika dapat dikreditkan
+				if line.Creditable {
+					src.TaxAmount -= line.TaxAmount
+				}
+			default:
+				src.TaxBase += line.TaxBase
+				src.TaxAmount += line.TaxAmount
+			}
+		}
+		result.Files = append(result.Files, summary)
+	}
+
+	for _, taxType := range []string{models.TaxTypePphUnifikasi, models.TaxTypePpn} {
+		if len(sources[taxType]) == 0 {
+			continue
+		}
+		total, err := s.saveTotal(ctx, job, taxType, sources[taxType])
+		if err != nil {
+			return nil, err
+		}
+		result.Totals = append(result.Totals, *total)
+	}
+
+	logger.FromContext(ctx).Info("Impor ekspor DJP selesai", "job_id", job.JobID,
+		"files", len(files), "tax_types", len(result.Totals), "rejected_rows", len(result.Errors))
+	return result, nil
+}
+
+// importSource mengembalikan total file name untuk taxType, dan menambahkannya jika belum ada.
+// Upload dengan nama file yang sama dalam satu request digabung.
+func importSource(sources map[string][]models.TaxImportSource, taxType, name string) *models.TaxImportSource {
+	list := sources[taxType]
+	for i := range list {
+		if list[i].FileName == name {
+			return &list[i]
+		}
+	}
+	sources[taxType] = append(list, models.TaxImportSource{FileName: name})
+	return &sources[taxType][len(list)]
+}
+
+// saveTotal menyimpan total per file ke laporan pajak dengan jenis yang sama, atau membuat
+// laporan baru jika belum ada. billing_amount laporan dihitung ulang dari semua file yang
+// pernah diimpor ke laporan tersebut.
+func (s *taxImportService) saveTotal(ctx context.Context, job *models.MonthlyJob, taxType string, sources []models.TaxImportSource) (*models.TaxImportTotal, error) {
+	for i := range sources {
+		sources[i].TaxBase = math.Round(sources[i].TaxBase*100) / 100
+		sources[i].TaxAmount = math.Round(sources[i].TaxAmount*100) / 100
+	}
+
+	var report *models.MonthlyTaxReport
+	action := "updated"
+	for i := range job.TaxReports {
+		if job.TaxReports[i].TaxType == taxType {
+			report = &job.TaxReports[i]
+			break
+		}
+	}
+	if report == nil {
+		report = &models.MonthlyTaxReport{
+			JobID:        job.JobID,
+			TaxType:      taxType,
+			ReportStatus: models.ReportStatusPending,
+		}
+		if err := s.monthlyJobRepo.CreateMonthlyTaxReport(ctx, report); err != nil {
+			return nil, fmt.Errorf("gagal membuat laporan %s: %w", taxType, err)
+		}
+		job.TaxReports = append(job.TaxReports, *report)
+		report = &job.TaxReports[len(job.TaxReports)-1]
+		action = "created"
+	}
+
+	total, err := s.taxImportRepo.SaveImportSources(ctx, report.ReportID, sources)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memperbarui laporan %s: %w", taxType, err)
+	}
+	report.BillingAmount = &total.BillingAmount
+	total.TaxType = taxType
+	total.Action = action
+	return total, nil
+}
+
+// validateImportLine mengembalikan alasan penolakan, atau string kosong jika baris cocok dengan pekerjaan.
+func validateImportLine(job *models.MonthlyJob, line djpimport.Line) string {
+	if line.OwnerNPWP == "" {
+		// Tanpa NPWP pemilik baris tidak dapat dipastikan milik klien ini
+		return fmt.Sprintf("NPWP pemilik tidak tercantum sehingga tidak dapat dicocokkan dengan NPWP klien %s", job.NpwpClient)
+	}
+	if !npwp.Equal(line.OwnerNPWP, job.NpwpClient) {
+		return fmt.Sprintf("NPWP %s tidak sesuai dengan NPWP klien %s", line.OwnerNPWP, job.NpwpClient)
+	}
+	if (line.Month != 0 && line.Month != job.JobMonth) || (line.Year != 0 && line.Year != job.JobYear) {
+		return fmt.Sprintf("masa pajak %02d/%d tidak sesuai dengan pekerjaan %02d/%d", line.Month, line.Year, job.JobMonth, job.JobYear)
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories/memory"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/djpimport"
+)
+
+func TestValidateImportLine(t *testing.T) {
+	job := &models.MonthlyJob{NpwpClient: "01.234.567.8-901.000", JobMonth: 5, JobYear: 2025}
+	tests := []struct {
+		name   string
+		line   djpimport.Line
+		reason string // potongan alasan penolakan, kosong jika baris diterima
+	}{
+		{"matching NPWP and period", djpimport.Line{OwnerNPWP: "012345678901000", Month: 5, Year: 2025}, ""},
+		{"16-digit NPWP", djpimport.Line{OwnerNPWP: "0012345678901000", Month: 5, Year: 2025}, ""},
+		{"period not in file", djpimport.Line{OwnerNPWP: "012345678901000"}, ""},
+		{"other NPWP", djpimport.Line{OwnerNPWP: "098765432109000", Month: 5, Year: 2025}, "tidak sesuai dengan NPWP klien"},
+		{"missing NPWP", djpimport.Line{Month: 5, Year: 2025}, "NPWP pemilik tidak tercantum"},
+		{"other period", djpimport.Line{OwnerNPWP: "012345678901000", Month: 4, Year: 2025}, "masa pajak 04/2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateImportLine(job, tt.line)
+			if tt.reason == "" && got != "" {
+				t.Fatalf("rejected: %s", got)
+			}
+			if !strings.Contains(got, tt.reason) {
+				t.Fatalf("reason = %q, want it to contain %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+// fakeTaxImportRepo keeps the imported files per report the way monthly_tax_report_imports does
+type fakeTaxImportRepo struct {
+	files map[string][]models.TaxImportSource
+}
+
+func (r *fakeTaxImportRepo) SaveImportSources(ctx context.Context, reportID string, sources []models.TaxImportSource) (*models.TaxImportTotal, error) {
+	list := r.files[reportID]
+next:
+	for _, src := range sources {
+		for i := range list {
+			if list[i].FileName == src.FileName {
+				list[i] = src
+				continue next
+			}
+		}
+		list = append(list, src)
+	}
+	r.files[reportID] = list
+
+	total := &models.TaxImportTotal{ReportID: reportID, SourceFiles: []string{}}
+	for _, src := range list {
+		total.SourceFiles = append(total.SourceFiles, src.FileName)
+		total.LineCount += src.LineCount
+		total.TaxBase += src.TaxBase
+		total.TaxAmount += src.TaxAmount
+	}
+	total.BillingAmount = math.Max(total.TaxAmount, 0)
+	return total, nil
+}
+
+// fakturXML returns an e-Faktur XML export of the client with one invoice of base and 11% VAT
+func fakturXML(reference string, base float64) string {
+	return fmt.Sprintf(`<TaxInvoiceBulk><TIN>0012345678901000</TIN><ListOfTaxInvoice><TaxInvoice>
+<TaxInvoiceDate>2025-05-20</TaxInvoiceDate><RefDesc>%s</RefDesc><BuyerTin>0987654321098000</BuyerTin>
+<ListOfGoodService><GoodService><TaxBase>%.0f</TaxBase><VAT>%.0f</VAT></GoodService></ListOfGoodService>
+</TaxInvoice></ListOfTaxInvoice></TaxInvoiceBulk>`, reference, base, base*0.11)
+}
+
+// Impor terpisah tidak boleh menghapus PPN dari file yang diimpor sebelumnya.
+func TestImportMonthlyJobFilesAccumulatesPerFile(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	staff := &models.Staff{Nama: "Staf Pajak", Email: "staf@example.com", PasswordHashed: "Rahasia-Lama-123", Role: "staff"}
+	if err := memory.NewStaffRepository(db).CreateStaff(ctx, staff); err != nil {
+		t.Fatalf("CreateStaff: %v", err)
+	}
+	client := &models.Client{ClientName: "PT Maju", NpwpClient: "01.234.567.8-901.000", NpwpCanonical: "012345678901000",
+		MembershipStatus: "Aktif", PicStaffSigmaID: staff.StaffID, ClientCategory: "Badan", Ppn: true}
+	if err := memory.NewClientRepository(db).CreateClient(ctx, client); err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	monthlyJobs := memory.NewMonthlyJobRepository(db)
+	job := &models.MonthlyJob{ClientID: client.ClientID, JobMonth: 5, JobYear: 2025, AssignedPicStaffSigmaID: staff.StaffID, OverallStatus: "Dikerjakan"}
+	if err := monthlyJobs.CreateMonthlyJob(ctx, job); err != nil {
+		t.Fatalf("CreateMonthlyJob: %v", err)
+	}
+	job.NpwpClient = client.NpwpClient
+	svc := NewTaxImportService(monthlyJobs, &fakeTaxImportRepo{files: map[string][]models.TaxImportSource{}})
+
+	steps := []struct {
+		name        string
+		files       []TaxImportFile
+		wantAction  string
+		wantAmount  float64
+		wantSources []string
+	}{
+		{"first file", []TaxImportFile{{Name: "mei-a.xml", Reader: strings.NewReader(fakturXML("INV-1", 1000000))}},
+			"created", 110000, []string{"mei-a.xml"}},
+		{"second file in another request", []TaxImportFile{{Name: "mei-b.xml", Reader: strings.NewReader(fakturXML("INV-2", 500000))}},
+			"updated", 165000, []string{"mei-a.xml", "mei-b.xml"}},
+		{"corrected first file", []TaxImportFile{{Name: "mei-a.xml", Reader: strings.NewReader(fakturXML("INV-1", 2000000))}},
+			"updated", 275000, []string{"mei-a.xml", "mei-b.xml"}},
+	}
+	for _, step := range steps {
+		res, err := svc.ImportMonthlyJobFiles(ctx, job, step.files)
+		if err != nil {
+			t.Fatalf("%s: ImportMonthlyJobFiles: %v", step.name, err)
+		}
+		if len(res.Errors) != 0 || len(res.Totals) != 1 {
+			t.Fatalf("%s: got %d totals and errors %+v, want one PPN total", step.name, len(res.Totals), res.Errors)
+		}
+		total := res.Totals[0]
+		if total.TaxType != models.TaxTypePpn || total.Action != step.wantAction || total.BillingAmount != step.wantAmount ||
+			!reflect.DeepEqual(total.SourceFiles, step.wantSources) {
+			t.Errorf("%s: total = %+v, want %s with billing amount %v from %v", step.name, total, step.wantAction, step.wantAmount, step.wantSources)
+		}
+	}
+	if len(job.TaxReports) != 1 || job.TaxReports[0].BillingAmount == nil || *job.TaxReports[0].BillingAmount != 275000 {
+		t.Errorf("job reports = %+v, want one PPN report of 275000", job.TaxReports)
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/totp"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/utils"
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("kode 2FA salah atau sudah dipakai")
+	ErrTwoFactorAlreadyEnabled = errors.New("2FA sudah aktif")
+	ErrTwoFactorNotPending     = errors.New("belum ada enrollment 2FA yang menunggu konfirmasi")
+	ErrTwoFactorNotEnabled     = errors.New("2FA belum aktif")
+	ErrTwoFactorRequired       = errors.New("2FA wajib untuk role ini dan tidak bisa dimatikan")
+)
+
+const (
+	// totpSkew menerima kode satu periode sebelum dan sesudahnya untuk jam HP yang meleset.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+// TwoFactorService mengatur login dua langkah staf dengan TOTP (RFC 6238) dan kode cadangan.
+type TwoFactorService interface {
+	// Required melaporkan apakah role wajib memakai 2FA (auth.totp_required_roles).
+	Required(role string) bool
+	// Status mengembalikan status 2FA staf.
+	Status(ctx context.Context, staff *models.Staff) (*models.TwoFactorStatus, error)
+	// BeginEnrollment membuat secret baru yang belum aktif sampai dikonfirmasi dengan ConfirmEnrollment.
+	BeginEnrollment(ctx context.Context, staff *models.Staff) (*models.TwoFactorEnrollment, error)
+	// ConfirmEnrollment mengaktifkan 2FA jika code cocok dengan secret yang menunggu, dan
+	// mengembalikan kode cadangan. Kode cadangan hanya ditampilkan sekali ini.
+	ConfirmEnrollment(ctx context.Context, staffID, code string) ([]string, error)
+	// Verify memeriksa kode TOTP atau kode cadangan. Setiap kode hanya bisa dipakai sekali.
+	Verify(ctx context.Context, staffID, code string) error
+	// RegenerateRecoveryCodes mengganti semua kode cadangan setelah code diperiksa.
+	RegenerateRecoveryCodes(ctx context.Context, staffID, code string) ([]string, error)
+	// Disable mematikan 2FA milik sendiri dengan password dan kode yang masih berlaku.
+	Disable(ctx context.Context, staff *models.Staff, password, code string) error
+	// Reset dipakai admin untuk staf yang kehilangan authenticator dan kode cadangannya.
+	Reset(ctx context.Context, staffID string) error
+}
+
+// twoFactorService adalah implementasi dari TwoFactorService.
+type twoFactorService struct {
+	repo          repositories.TwoFactorRepository
+	issuer        string
+	requiredRoles []string
+}
+
+// NewTwoFactorService adalah constructor untuk twoFactorService. issuer adalah nama yang tampil
+// di aplikasi authenticator.
+func NewTwoFactorService(repo repositories.TwoFactorRepository, issuer string, requiredRoles []string) TwoFactorService {
+	return &twoFactorService{repo: repo, issuer: issuer, requiredRoles: requiredRoles}
+}
+
+func (s *twoFactorService) Required(role string) bool {
+	return slices.Contains(s.requiredRoles, role)
+}
+
+func (s *twoFactorService) Status(ctx context.Context, staff *models.Staff) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{Required: s.Required(staff.Role)}
+	t, err := s.repo.GetTOTP(ctx, staff.StaffID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.EnabledAt == nil {
+		return status, nil
+	}
+	status.Enabled = true
+	status.EnabledAt = t.EnabledAt
+	if status.RecoveryCodesRemaining, err = s.repo.CountRecoveryCodes(ctx, staff.StaffID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *twoFactorService) BeginEnrollment(ctx context.Context, staff *models.Staff) (*models.TwoFactorEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.SavePendingTOTP(ctx, staff.StaffID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	return &models.TwoFactorEnrollment{
+		Secret:     secret,
+		OtpauthURI: totp.ProvisioningURI(secret, s.issuer, staff.Email),
+	}, nil
+}
+
+func (s *twoFactorService) ConfirmEnrollment(ctx context.Context, staffID, code string) ([]string, error) {
+	t, err := s.repo.GetTOTP(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTwoFactorNotPending
+	}
+	if t.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	counter, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.repo.EnableTOTP(ctx, staffID, counter, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorNotPending // Dikonfirmasi bersamaan oleh request lain
+	}
+	logger.FromContext(ctx).Info("2FA diaktifkan", "staff_id", staffID)
+	return codes, nil
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, staffID, code string) error {
+	t, err := s.repo.GetTOTP(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if t == nil || t.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		counter, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		used, err := s.repo.UseTOTPCounter(ctx, staffID, counter)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode // Kode yang sama (atau lebih lama) sudah pernah dipakai
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, staffID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	logger.FromContext(ctx).Info("Kode cadangan 2FA dipakai", "staff_id", staffID)
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, staffID, code string) ([]string, error) {
+	if err := s.Verify(ctx, staffID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, staffID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, staff *models.Staff, password, code string) error {
+	if s.Required(staff.Role) {
+		return ErrTwoFactorRequired
+	}
+	if !utils.CheckPasswordHash(password, staff.PasswordHashed) {
+		return ErrInvalidCredentials
+	}
+	if err := s.Verify(ctx, staff.StaffID, code); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTwoFactor(ctx, staff.StaffID); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("2FA dimatikan oleh staf", "staff_id", staff.StaffID)
+	return nil
+}
+
+func (s *twoFactorService) Reset(ctx context.Context, staffID string) error {
+	if err := s.repo.DeleteTwoFactor(ctx, staffID); err != nil {
+		return err
+	}
+	// staff_id di context adalah admin yang mereset
+	logger.FromContext(ctx).Warn("2FA staf direset oleh admin", "target_staff_id", staffID)
+	return nil
+}
+
+// recoveryCodeEncoding memakai huruf kecil agar kode mudah dibaca dan diketik.
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newRecoveryCodes membuat kode cadangan berformat "xxxx-xxxx-xxxx" (60 bit) beserta hash-nya.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := recoveryCodeEncoding.EncodeToString(b)[:12]
+		codes = append(codes, raw[:4]+"-"+raw[4:8]+"-"+raw[8:])
+		hashes = append(hashes, hashRecoveryCode(raw))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode mengabaikan tanda hubung, spasi dan huruf besar pada kode yang diketik.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
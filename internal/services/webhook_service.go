@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/models"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/repositories"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/hmacsig"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// WebhookService mengirim event dashboard ke endpoint eksternal yang berlangganan.
+// Event dicatat dulu di webhook_deliveries lalu dikirim oleh worker, sehingga pengiriman
+// yang gagal diulang dengan backoff eksponensial dan tetap terkirim setelah restart.
+type WebhookService interface {
+	// HandleDomainEvent adalah subscriber outbox yang meneruskan domain event sebagai webhook
+	// (lihat domainEventWebhooks). ID domain event dipakai sebagai event_id webhook, sehingga
+	// setiap event webhook berasal dari perubahan data yang sudah tersimpan.
+	HandleDomainEvent(ctx context.Context, event *models.DomainEvent) error
+	// Redeliver mengantrikan ulang payload sebuah delivery sebagai delivery baru.
+	Redeliver(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error)
+	// Start menjalankan worker pengiriman di background.
+	Start()
+	// Stop menghentikan worker setelah pengiriman yang sedang berjalan selesai.
+	Stop(ctx context.Context) error
+}
+
+const (
+	webhookSweepInterval   = 30 * time.Second
+	webhookBatchSize       = 20
+	webhookLease           = 2 * time.Minute // Lebih lama dari webhookRequestTimeout
+	webhookRequestTimeout  = 10 * time.Second
+	webhookMaxAttempts     = 8
+	webhookBaseBackoff     = 30 * time.Second
+	webhookMaxBackoff      = 6 * time.Hour
+	webhookMaxResponseBody = 2048
+)
+
+// webhookService adalah implementasi dari WebhookService.
+type webhookService struct {
+	webhookRepo repositories.WebhookRepository
+	client      *http.Client
+	wake        chan struct{}
+	*workerLoop
+}
+
+// NewWebhookService adalah constructor untuk webhookService.
+func NewWebhookService(whRepo repositories.WebhookRepository) WebhookService {
+	return &webhookService{
+		webhookRepo: whRepo,
+		client:      &http.Client{Timeout: webhookRequestTimeout},
+		wake:        make(chan struct{}, 1),
+		workerLoop:  newWorkerLoop(),
+	}
+}
+
+// domainEventWebhooks memetakan domain event ke jenis event webhook
+var domainEventWebhooks = map[string]string{
+	models.DomainEventJobStatusChanged: models.WebhookEventJobStatusChanged,
+	models.DomainEventInvoiceIssued:    models.WebhookEventInvoiceCreated,
+	models.DomainEventInvoicePaid:      models.WebhookEventInvoicePaid,
+	models.DomainEventClientCreated:    models.WebhookEventClientCreated,
+}
+
+func (s *webhookService) HandleDomainEvent(ctx context.Context, event *models.DomainEvent) error {
+	eventType, ok := domainEventWebhooks[event.EventType]
+	if !ok {
+		return nil
+	}
+	return s.publish(ctx, event.EventID, eventType, event.OccurredAt, event.Payload)
+}
+
+func (s *webhookService) publish(ctx context.Context, eventID, eventType string, occurredAt time.Time, data interface{}) error {
+	payload, err := json.Marshal(models.WebhookEnvelope{
+		EventID:    eventID,
+		EventType:  eventType,
+		OccurredAt: occurredAt,
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("gagal menyusun payload webhook: %w", err)
+	}
+
+	queued, err := s.webhookRepo.CreateDeliveries(ctx, eventID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		s.notifyWorker()
+	}
+	return nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.Redeliver(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyWorker()
+	return delivery, nil
+}
+
+// notifyWorker membangunkan worker tanpa memblokir; sinyal yang sudah menunggu sudah cukup.
+func (s *webhookService) notifyWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) Start() {
+	s.workerLoop.run(s.run)
+}
+
+func (s *webhookService) run() {
+	ticker := time.NewTicker(webhookSweepInterval)
+	defer ticker.Stop()
+
+	ctx := logger.With(s.runContext(), "worker", "webhook")
+	for {
+		s.deliverDue(ctx)
+		select {
+		case <-s.stopping():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue mengirim semua delivery yang sudah jatuh tempo, per batch.
+func (s *webhookService) deliverDue(ctx context.Context) {
+	for {
+		deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			logger.FromContext(ctx).Warn("Gagal mengambil antrian webhook", "error", err)
+			return
+		}
+		for i := range deliveries {
+			if s.isStopping() {
+				return // Delivery yang sudah di-claim diambil lagi setelah lease-nya habis
+			}
+			s.attempt(ctx, &deliveries[i])
+		}
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+func (s *webhookService) attempt(ctx context.Context, d *models.WebhookDelivery) {
+	now := time.Now()
+	result := models.WebhookAttemptResult{AttemptedAt: now}
+
+	status, body, err := s.send(ctx, d, now)
+	if status != 0 {
+		result.ResponseStatus = &status
+		result.ResponseBody = &body
+	}
+	if err == nil && status >= 200 && status < 300 {
+		result.Status = models.WebhookDeliverySucceeded
+	} else {
+		if err == nil {
+			err = fmt.Errorf("endpoint membalas HTTP %d", status)
+		}
+		msg := err.Error()
+		result.Error = &msg
+
+		attempts := d.Attempts + 1
+		if attempts >= webhookMaxAttempts {
+			result.Status = models.WebhookDeliveryFailed
+			logger.FromContext(ctx).Warn("Webhook gagal setelah percobaan terakhir", "delivery_id", d.DeliveryID,
+				"subscription_id", d.SubscriptionID, "event_type", d.EventType, "attempts", attempts, "error", err)
+		} else {
+			result.Status = models.WebhookDeliveryPending
+			next := now.Add(webhookBackoff(attempts))
+			result.NextAttemptAt = &next
+		}
+	}
+
+	if err := s.webhookRepo.RecordAttempt(ctx, d.DeliveryID, result); err != nil {
+		logger.FromContext(ctx).Warn("Gagal menyimpan hasil pengiriman webhook", "delivery_id", d.DeliveryID, "error", err)
+	}
+}
+
+// send mengirim payload yang ditandatangani ke URL subscription dan mengembalikan
+// status HTTP beserta potongan body balasan.
+func (s *webhookService) send(ctx context.Context, d *models.WebhookDelivery, now time.Time) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("URL webhook tidak valid: %w", err)
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dashboard-pekerjaan-webhook/1.0")
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Event-ID", d.EventID)
+	req.Header.Set("X-Webhook-Delivery", d.DeliveryID)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", hmacsig.Sign(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	return resp.StatusCode, string(bytes.ToValidUTF8(body, nil)), nil
+}
+
+// webhookBackoff mengembalikan jeda sebelum percobaan berikutnya setelah attempts kali gagal:
+// 30 detik, 1 menit, 2 menit, ... maksimal 6 jam, dengan jitter ±20% agar tidak serempak.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookMaxBackoff
+	if attempts-1 < 20 {
+		if b := webhookBaseBackoff << (attempts - 1); b < webhookMaxBackoff {
+			backoff = b
+		}
+	}
+	jitter := time.Duration(mathrand.Int64N(int64(backoff)*2/5+1)) - backoff/5
+	return backoff + jitter
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker adalah proses background yang hidup bersama server HTTP. Stop dipanggil saat
+// shutdown, sebelum koneksi database ditutup.
+type Worker interface {
+	Start()
+	// Stop menghentikan worker dan menunggu pekerjaan yang sedang berjalan selesai, atau
+	// sampai ctx habis. Aman dipanggil walaupun Start belum pernah dipanggil.
+	Stop(ctx context.Context) error
+}
+
+// workerLoop adalah siklus hidup bersama untuk worker yang berjalan di satu goroutine:
+// loop membaca stopping() dan berhenti saat channel tersebut ditutup.
+type workerLoop struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	done     chan struct{}
+	started  sync.Once
+	stopOnce sync.Once
+	running  bool
+	mu       sync.Mutex
+}
+
+func newWorkerLoop() *workerLoop {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerLoop{ctx: ctx, cancel: cancel, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+// run menjalankan loop di goroutine baru. Pemanggilan berikutnya diabaikan.
+func (w *workerLoop) run(loop func()) {
+	w.started.Do(func() {
+		w.mu.Lock()
+		w.running = true
+		w.mu.Unlock()
+		go func() {
+			defer close(w.done)
+			loop()
+		}()
+	})
+}
+
+// stopping ditutup saat Stop dipanggil.
+func (w *workerLoop) stopping() <-chan struct{} {
+	return w.stop
+}
+
+// runContext adalah context untuk query worker. Context ini baru dibatalkan jika Stop
+// kehabisan waktu, jadi pekerjaan yang sedang berjalan tetap diselesaikan saat shutdown biasa.
+func (w *workerLoop) runContext() context.Context {
+	return w.ctx
+}
+
+// isStopping melaporkan apakah Stop sudah dipanggil, untuk keluar di tengah batch.
+func (w *workerLoop) isStopping() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *workerLoop) Stop(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.mu.Lock()
+	running := w.running
+	w.mu.Unlock()
+	if !running {
+		w.cancel()
+		return nil
+	}
+	select {
+	case <-w.done:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,259 @@
+// Command repometricsgen generates the instrumented decorators of every *Repository
+// interface in a package, so each repository call gets the statement timeout, trace hooks
+// and timing of an Instrumentation without touching the repository implementations. Every
+// repository method must take a context.Context as its first parameter.
+//
+// Usage (via go:generate in internal/repositories):
+//
+//	go run ../tools/repometricsgen -out instrumented_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type method struct {
+	name    string
+	params  []param
+	results []string
+}
+
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+type iface struct {
+	name    string
+	methods []method
+}
+
+func main() {
+	out := flag.String("out", "instrumented_gen.go", "output file, relative to the package directory")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var ifaces []iface
+	imports := map[string]string{} // qualifier -> import path
+	var pkgName string
+	for _, name := range files {
+		if name == *out || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pkgName = file.Name.Name
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			qualifier := filepath.Base(path)
+			if imp.Name != nil {
+				qualifier = imp.Name.Name
+			}
+			imports[qualifier] = path
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				it, ok := ts.Type.(*ast.InterfaceType)
+				if !ok || !ts.Name.IsExported() || !strings.HasSuffix(ts.Name.Name, "Repository") {
+					continue
+				}
+				ifaces = append(ifaces, iface{name: ts.Name.Name, methods: methods(fset, it)})
+			}
+		}
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].name < ifaces[j].name })
+
+	src, err := generate(pkgName, ifaces, imports)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func methods(fset *token.FileSet, it *ast.InterfaceType) []method {
+	var ms []method
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok {
+			log.Fatalf("embedded interfaces are not supported: %s", exprString(fset, field.Type))
+		}
+		for _, name := range field.Names {
+			m := method{name: name.Name}
+			for _, p := range ft.Params.List {
+				n := len(p.Names)
+				if n == 0 {
+					n = 1
+				}
+				for i := 0; i < n; i++ {
+					arg := param{name: fmt.Sprintf("p%d", len(m.params))}
+					if ell, ok := p.Type.(*ast.Ellipsis); ok {
+						arg.variadic = true
+						arg.typ = exprString(fset, ell.Elt)
+					} else {
+						arg.typ = exprString(fset, p.Type)
+					}
+					m.params = append(m.params, arg)
+				}
+			}
+			if ft.Results != nil {
+				for _, r := range ft.Results.List {
+					n := len(r.Names)
+					if n == 0 {
+						n = 1
+					}
+					for i := 0; i < n; i++ {
+						m.results = append(m.results, exprString(fset, r.Type))
+					}
+				}
+			}
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+func exprString(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, e); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
+
+func generate(pkgName string, ifaces []iface, imports map[string]string) ([]byte, error) {
+	var body bytes.Buffer
+	used := map[string]bool{}
+	useTypes := func(typ string) {
+		for qualifier := range imports {
+			if regexp.MustCompile(`\b` + regexp.QuoteMeta(qualifier) + `\.`).MatchString(typ) {
+				used[qualifier] = true
+			}
+		}
+	}
+
+	for _, it := range ifaces {
+		impl := "instrumented" + it.name
+		fmt.Fprintf(&body, "\n// %s runs every %s call through an Instrumentation.\n", impl, it.name)
+		fmt.Fprintf(&body, "type %s struct {\n\tnext %s\n\tin   *Instrumentation\n}\n", impl, it.name)
+		fmt.Fprintf(&body, "\n// Instrument%s wraps next so every call goes through in.\n", it.name)
+		fmt.Fprintf(&body, "func Instrument%s(next %s, in *Instrumentation) %s {\n\treturn &%s{next: next, in: in}\n}\n",
+			it.name, it.name, it.name, impl)
+
+		for _, m := range it.methods {
+			if len(m.params) == 0 || m.params[0].typ != "context.Context" {
+				log.Fatalf("%s.%s: the first parameter must be a context.Context", it.name, m.name)
+			}
+			var params, args, results, resultNames []string
+			for i, p := range m.params {
+				useTypes(p.typ)
+				arg := p.name
+				if i == 0 {
+					arg = "ctx" // Context dari Instrumentation.begin, dengan timeout dan hook
+				}
+				if p.variadic {
+					params = append(params, p.name+" ..."+p.typ)
+					args = append(args, arg+"...")
+				} else {
+					params = append(params, p.name+" "+p.typ)
+					args = append(args, arg)
+				}
+			}
+			errResult := ""
+			for i, r := range m.results {
+				useTypes(r)
+				name := fmt.Sprintf("r%d", i)
+				results = append(results, name+" "+r)
+				resultNames = append(resultNames, name)
+				if r == "error" && i == len(m.results)-1 {
+					errResult = name
+				}
+			}
+
+			signature := fmt.Sprintf("%s(%s)", m.name, strings.Join(params, ", "))
+			if len(results) > 0 {
+				signature += " (" + strings.Join(results, ", ") + ")"
+			}
+			fmt.Fprintf(&body, "\nfunc (r *%s) %s {\n", impl, signature)
+			fmt.Fprintf(&body, "\tctx, end := r.in.begin(%s, %q, %q)\n", m.params[0].name, it.name, m.name)
+			if errResult != "" {
+				fmt.Fprintf(&body, "\tdefer func() { %s = end(%s) }()\n", errResult, errResult)
+			} else {
+				fmt.Fprintf(&body, "\tdefer end(nil)\n")
+			}
+			call := fmt.Sprintf("r.next.%s(%s)", m.name, strings.Join(args, ", "))
+			if len(resultNames) > 0 {
+				fmt.Fprintf(&body, "\t%s = %s\n", strings.Join(resultNames, ", "), call)
+			} else {
+				fmt.Fprintf(&body, "\t%s\n", call)
+			}
+			fmt.Fprintf(&body, "\treturn\n}\n")
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by repometricsgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	var quals []string
+	for q := range used {
+		quals = append(quals, q)
+	}
+	for _, q := range quals {
+		if imports[q] == "" {
+			imports[q] = q
+		}
+	}
+	sort.Slice(quals, func(i, j int) bool { return imports[quals[i]] < imports[quals[j]] })
+	// Standard library dulu, lalu package lain, seperti gaya import di repo ini
+	for _, std := range []bool{true, false} {
+		for _, q := range quals {
+			path := imports[q]
+			if isStd(path) != std {
+				continue
+			}
+			if filepath.Base(path) != q {
+				fmt.Fprintf(&src, "\t%s %q\n", q, path)
+			} else {
+				fmt.Fprintf(&src, "\t%q\n", path)
+			}
+		}
+		if std {
+			src.WriteString("\n")
+		}
+	}
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// isStd melaporkan apakah path adalah package standard library (elemen pertamanya tanpa titik).
+func isStd(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
@@ -1,32 +1,112 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Zona waktu tetap tersedia di image container tanpa tzdata
 
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/config"
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/internal/routes" // Import routes
 	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/database"
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
 )
 
+// readHeaderTimeout membatasi klien lambat yang menahan koneksi sebelum mengirim header.
+const readHeaderTimeout = 10 * time.Second
+
 func main() {
-	// Load configuration
-	cfg := config.LoadConfig()
+	// Subcommand: "config print [--redacted]"
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
+	// Load configuration: default, config.yaml, env (atau <ENV>_FILE), lalu flag
+	fset := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	cfg, err := config.Load(fset, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Tanggal batas lapor dan jadwal notifikasi mengikuti zona waktu kantor, bukan zona server
+	time.Local = cfg.Location()
+
+	// Log JSON terstruktur; log package dari library pihak ketiga ikut diteruskan ke logger ini
+	level, _ := logger.ParseLevel(cfg.Log.Level) // Sudah divalidasi config.Load
+	logger.Setup(level)
 
 	// Initialize database connection
-	db, err := database.InitDB(cfg.DatabaseURL)
+	db, err := database.InitDB(cfg.Database.URL, database.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer db.Close() // Ensure the database connection is closed when main exits
+	defer db.Close() // Ditutup paling akhir, setelah request dan worker selesai
 
-	// Setup Gin router
-	router := routes.SetupRouter(db, cfg) // Pass the database connection to setup routes
+	// Setup Gin router dan worker background
+	app := routes.SetupRouter(db, cfg)
 
-	// Start the HTTP server
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	err = http.ListenAndServe(cfg.ServerPort, router)
-	if err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.Server.Port,
+		Handler:           app.Router,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "port", cfg.Server.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("Server failed to start", "error", err.Error())
+		shutdownWorkers(app, cfg.Server.ShutdownTimeout)
+		db.Close()
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	stop() // Sinyal kedua langsung menghentikan proses
+
+	slog.Info("Shutdown signal received, draining connections", "timeout", cfg.Server.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	app.BeginShutdown(shutdownCtx)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server did not drain in time", "error", err.Error())
+	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("HTTP server stopped with error", "error", err.Error())
+	}
+	if err := app.StopWorkers(shutdownCtx); err != nil {
+		slog.Error("Background workers did not stop cleanly", "error", err.Error())
+	}
+	slog.Info("Server stopped")
+}
+
+// shutdownWorkers menghentikan worker saat server gagal start, sebelum database ditutup.
+func shutdownWorkers(app *routes.App, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	app.BeginShutdown(ctx)
+	if err := app.StopWorkers(ctx); err != nil {
+		slog.Error("Background workers did not stop cleanly", "error", err.Error())
 	}
-}
\ No newline at end of file
+}
@@ -1,9 +1,35 @@
 // pkg/auth/claims.go
 package auth
 
+// Audience JWT. Token staf dan token portal klien ditandatangani dengan secret yang sama,
+// jadi audience yang membedakan keduanya di AuthMiddleware.
+const (
+	AudienceStaff        = "staff"
+	AudienceClientPortal = "client-portal"
+	// AudienceStaffMFA adalah token sementara antara langkah password dan kode 2FA saat login.
+	// Token ini tidak diterima endpoint mana pun selain POST /auth/login/verify.
+	AudienceStaffMFA = "staff-mfa"
+)
+
+// Claim pembatas token staf. Selama salah satunya true, token hanya diterima endpoint
+// penyiapan akun (ganti password dan enrollment 2FA).
+const (
+	ClaimPasswordChange = "pwd_change" // Password sementara dari admin wajib diganti dulu
+	ClaimTwoFactorSetup = "mfa_setup"  // Role staf wajib 2FA, tetapi belum enrollment
+)
+
 // Claims adalah struct kustom yang akan kita gunakan untuk data user dari JWT
 type Claims struct {
 	StaffID string `json:"staff_id"`
 	IsAdmin bool   `json:"is_admin"`
 	Role    string `json:"role"`
-}
\ No newline at end of file
+	// MustChangePassword dan TwoFactorSetupRequired berasal dari claim pembatas token
+	MustChangePassword     bool `json:"must_change_password"`
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required"`
+}
+
+// ClientClaims adalah data user portal klien dari JWT, disimpan di context sebagai "client_claims"
+type ClientClaims struct {
+	ClientUserID string `json:"client_user_id"`
+	ClientID     string `json:"client_id"`
+}
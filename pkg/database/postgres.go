@@ -3,17 +3,33 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
+// PoolConfig holds the connection pool limits of database/sql. Zero values keep the
+// database/sql defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
 // InitDB initializes and returns a PostgreSQL database connection
-func InitDB(databaseURL string) (*sql.DB, error) {
+func InitDB(databaseURL string, pool PoolConfig) (*sql.DB, error) {
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	// Ping the database to verify the connection
 	err = db.Ping()
@@ -22,6 +38,6 @@ func InitDB(databaseURL string) (*sql.DB, error) {
 		return nil, fmt.Errorf("error connecting to the database: %w", err)
 	}
 
-	log.Println("Successfully connected to the database!")
+	slog.Info("Connected to the database", "max_open_conns", pool.MaxOpenConns)
 	return db, nil
 }
\ No newline at end of file
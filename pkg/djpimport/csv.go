@@ -0,0 +1,248 @@
+package djpimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Posisi kolom baku ekspor CSV e-Faktur (baris "FK" untuk faktur keluaran, "FM" untuk masukan).
+// Dipakai jika file tidak menyertakan baris header.
+var defaultFakturColumns = map[string]int{
+	"nomorfaktur":  3,
+	"masapajak":    4,
+	"tahunpajak":   5,
+	"npwp":         7,
+	"jumlahdpp":    10,
+	"jumlahppn":    11,
+	"iscreditable": 13,
+}
+
+// Alias judul kolom pada ekspor CSV e-Bupot Unifikasi. Judul dinormalisasi dengan normalizeHeader.
+var (
+	bupotReferenceColumns = []string{"nomorbuktipotong", "nobuktipotong", "nomorbupot", "nomorbppu", "nomorbukti"}
+	bupotOwnerColumns     = []string{"npwppemotong", "npwppemotongpemungut", "npwpnikpemotong"}
+	bupotPartyColumns     = []string{"npwpnikpenerima", "npwppenerima", "nikpenerima", "idpenerima", "npwpnikdipotong", "npwpdipotong"}
+	bupotMonthColumns     = []string{"masapajak", "masa"}
+	bupotYearColumns      = []string{"tahunpajak", "tahun"}
+	bupotBaseColumns      = []string{"jumlahpenghasilanbruto", "penghasilanbruto", "dasarpengenaanpajak", "dpp", "bruto"}
+	bupotAmountColumns    = []string{"pphdipotong", "pphdipotongdipungut", "jumlahpph", "pphyangdipotong", "pph"}
+)
+
+func parseCSV(data []byte) (*Result, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	type record struct {
+		lineNo int
+		fields []string
+	}
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca CSV: %w", err)
+		}
+		if isEmptyRecord(fields) {
+			continue
+		}
+		lineNo, _ := reader.FieldPos(0)
+		records = append(records, record{lineNo: lineNo, fields: fields})
+	}
+	if len(records) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(records[0].fields[0])) {
+	case "FK", "FM":
+		res := &Result{}
+		columns := defaultFakturColumns
+		for _, rec := range records {
+			marker := strings.ToUpper(strings.TrimSpace(rec.fields[0]))
+			if marker != "FK" && marker != "FM" {
+				continue // Baris LT (lawan transaksi) dan OF (objek faktur) tidak dibutuhkan
+			}
+			if isFakturHeader(rec.fields) {
+				columns = headerIndex(rec.fields)
+				continue
+			}
+			kind := KindFakturKeluaran
+			if marker == "FM" {
+				kind = KindFakturMasukan
+			}
+			line, err := fakturLineFromCSV(kind, rec.fields, columns)
+			line.LineNo = rec.lineNo
+			if err != nil {
+				res.Errors = append(res.Errors, LineError{LineNo: rec.lineNo, Reference: line.Reference, Reason: err.Error()})
+				continue
+			}
+			res.Lines = append(res.Lines, line)
+		}
+		res.Format = "efaktur_csv"
+		return res, nil
+	}
+
+	columns := headerIndex(records[0].fields)
+	if lookup(columns, bupotAmountColumns) < 0 || lookup(columns, bupotReferenceColumns) < 0 {
+		return nil, ErrUnknownFormat
+	}
+	res := &Result{Format: "ebupot_unifikasi_csv"}
+	for _, rec := range records[1:] {
+		line, err := bupotLineFromCSV(rec.fields, columns)
+		line.LineNo = rec.lineNo
+		if err != nil {
+			res.Errors = append(res.Errors, LineError{LineNo: rec.lineNo, Reference: line.Reference, Reason: err.Error()})
+			continue
+		}
+		res.Lines = append(res.Lines, line)
+	}
+	return res, nil
+}
+
+func fakturLineFromCSV(kind Kind, fields []string, columns map[string]int) (Line, error) {
+	line := Line{
+		Kind:             kind,
+		Reference:        field(fields, columns, "nomorfaktur"),
+		CounterpartyNPWP: digitsOnly(field(fields, columns, "npwp")),
+	}
+	if line.Reference == "" {
+		return line, fmt.Errorf("nomor faktur kosong")
+	}
+
+	var err error
+	if line.Month, err = parseMonth(field(fields, columns, "masapajak")); err != nil {
+		return line, err
+	}
+	if line.Year, err = parseYear(field(fields, columns, "tahunpajak")); err != nil {
+		return line, err
+	}
+	if line.TaxBase, err = ParseAmount(field(fields, columns, "jumlahdpp")); err != nil {
+		return line, fmt.Errorf("DPP: %w", err)
+	}
+	if line.TaxAmount, err = ParseAmount(field(fields, columns, "jumlahppn")); err != nil {
+		return line, fmt.Errorf("PPN: %w", err)
+	}
+	if kind == KindFakturMasukan {
+		// Kolom IS_CREDITABLE: 1 = dapat dikreditkan. Jika kolom tidak ada, dianggap dapat dikreditkan.
+		creditable := field(fields, columns, "iscreditable")
+		line.Creditable = creditable == "" || creditable == "1" || strings.EqualFold(creditable, "true")
+	}
+	return line, nil
+}
+
+func bupotLineFromCSV(fields []string, columns map[string]int) (Line, error) {
+	get := func(aliases []string) string {
+		idx := lookup(columns, aliases)
+		if idx < 0 || idx >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[idx])
+	}
+
+	line := Line{
+		Kind:             KindBupotUnifikasi,
+		Reference:        get(bupotReferenceColumns),
+		OwnerNPWP:        digitsOnly(get(bupotOwnerColumns)),
+		CounterpartyNPWP: digitsOnly(get(bupotPartyColumns)),
+	}
+	if line.Reference == "" {
+		return line, fmt.Errorf("nomor bukti potong kosong")
+	}
+
+	var err error
+	if line.Month, err = parseMonth(get(bupotMonthColumns)); err != nil {
+		return line, err
+	}
+	if line.Year, err = parseYear(get(bupotYearColumns)); err != nil {
+		return line, err
+	}
+	if line.TaxBase, err = ParseAmount(get(bupotBaseColumns)); err != nil {
+		return line, fmt.Errorf("penghasilan bruto: %w", err)
+	}
+	if line.TaxAmount, err = ParseAmount(get(bupotAmountColumns)); err != nil {
+		return line, fmt.Errorf("PPh dipotong: %w", err)
+	}
+	return line, nil
+}
+
+// detectDelimiter memilih pemisah kolom dari baris pertama. Excel berbahasa Indonesia
+// menyimpan CSV dengan titik koma.
+func detectDelimiter(data []byte) rune {
+	firstLine := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = data[:idx]
+	}
+	best, bestCount := ',', bytes.Count(firstLine, []byte(","))
+	for _, d := range []rune{';', '\t', '|'} {
+		if n := bytes.Count(firstLine, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// normalizeHeader menyamakan judul kolom: huruf kecil, hanya huruf dan angka.
+func normalizeHeader(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func headerIndex(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeHeader(h)
+		if _, exists := columns[key]; !exists && key != "" {
+			columns[key] = i
+		}
+	}
+	return columns
+}
+
+func lookup(columns map[string]int, aliases []string) int {
+	for _, alias := range aliases {
+		if idx, ok := columns[alias]; ok {
+			return idx
+		}
+	}
+	return -1
+}
+
+func field(fields []string, columns map[string]int, key string) string {
+	idx, ok := columns[key]
+	if !ok || idx >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[idx])
+}
+
+func isFakturHeader(fields []string) bool {
+	for _, f := range fields {
+		if normalizeHeader(f) == "nomorfaktur" {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyRecord(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Package djpimport membaca file ekspor aplikasi DJP (e-Bupot Unifikasi dan e-Faktur)
+// dalam format CSV maupun XML menjadi baris-baris yang seragam.
+package djpimport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Kind adalah jenis dokumen yang dibaca dari file ekspor.
+type Kind string
+
+const (
+	KindBupotUnifikasi Kind = "ebupot_unifikasi"
+	KindFakturKeluaran Kind = "efaktur_keluaran"
+	KindFakturMasukan  Kind = "efaktur_masukan"
+)
+
+// MaxFileSize membatasi ukuran satu file ekspor yang dibaca.
+const MaxFileSize = 20 << 20
+
+// ErrUnknownFormat dikembalikan jika isi file tidak dikenali sebagai ekspor e-Bupot atau e-Faktur.
+var ErrUnknownFormat = errors.New("format file tidak dikenali sebagai ekspor e-Bupot atau e-Faktur")
+
+// Line adalah satu bukti potong atau satu faktur.
+type Line struct {
+	Kind      Kind
+	LineNo    int    // Nomor baris pada CSV, atau urutan dokumen pada XML (mulai dari 1)
+	Reference string // Nomor bukti potong / nomor faktur
+	// OwnerNPWP adalah NPWP wajib pajak pemilik SPT (pemotong atau PKP).
+	// Kosong jika file tidak memuatnya, mis. ekspor CSV faktur e-Faktur desktop; baris seperti
+	// ini ditolak saat diimpor ke pekerjaan karena tidak dapat dicocokkan dengan klien.
+	OwnerNPWP        string
+	CounterpartyNPWP string
+	Month            int // 0 jika tidak tercantum
+	Year             int // 0 jika tidak tercantum
+	TaxBase          float64
+	TaxAmount        float64
+	Creditable       bool // Hanya untuk faktur masukan
+}
+
+// LineError adalah baris yang tidak dapat dibaca.
+type LineError struct {
+	LineNo    int
+	Reference string
+	Reason    string
+}
+
+// Result adalah hasil pembacaan satu file.
+type Result struct {
+	Format string // mis. "ebupot_unifikasi_csv"
+	Lines  []Line
+	Errors []LineError
+}
+
+// Parse membaca satu file ekspor. Jenis file ditentukan dari ekstensi dan isinya.
+func Parse(fileName string, r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file: %w", err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("ukuran file melebihi %d MB", MaxFileSize>>20)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM dari Excel
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xml":
+		return parseXML(data)
+	case ".csv", ".txt":
+		return parseCSV(data)
+	}
+
+	// Ekstensi tidak jelas: tebak dari isi
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		return parseXML(data)
+	}
+	return parseCSV(data)
+}
+
+// ParseAmount membaca nominal rupiah dari ekspor DJP ("1500000.00") maupun
+// hasil olahan Excel berformat Indonesia ("1.500.000,00").
+func ParseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "Rp"), "rp")
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot { // 1.500.000,00
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else { // 1,500,000.00
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(s, ",") == 1 && len(s)-lastComma-1 <= 2 { // 1500000,5
+			s = strings.Replace(s, ",", ".", 1)
+		} else { // 1,500,000
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastDot >= 0:
+		if strings.Count(s, ".") > 1 || len(s)-lastDot-1 == 3 { // 1.500.000
+			s = strings.ReplaceAll(s, ".", "")
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("nominal %q tidak valid", s)
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+var monthNames = map[string]int{
+	"januari": 1, "februari": 2, "maret": 3, "april": 4, "mei": 5, "juni": 6,
+	"juli": 7, "agustus": 8, "september": 9, "oktober": 10, "november": 11, "desember": 12,
+}
+
+// parseMonth menerima "1", "01" atau nama bulan dalam bahasa Indonesia.
+func parseMonth(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	if m, ok := monthNames[s]; ok {
+		return m, nil
+	}
+	m, err := strconv.Atoi(s)
+	if err != nil || m < 1 || m > 12 {
+		return 0, fmt.Errorf("masa pajak %q tidak valid", s)
+	}
+	return m, nil
+}
+
+func parseYear(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	y, err := strconv.Atoi(s)
+	if err != nil || y < 1900 || y > 9999 {
+		return 0, fmt.Errorf("tahun pajak %q tidak valid", s)
+	}
+	return y, nil
+}
+
+// digitsOnly membuang titik, strip dan spasi dari NPWP.
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,254 @@
+package djpimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"1500000", 1500000, false},
+		{"1500000.00", 1500000, false},
+		{"1500000.5", 1500000.5, false},
+		{"1.500.000", 1500000, false},
+		{"1.500.000,00", 1500000, false},
+		{"1,500,000.00", 1500000, false},
+		{"1,500,000", 1500000, false},
+		{"1500000,5", 1500000.5, false},
+		{"Rp 1.500.000", 1500000, false},
+		{"(250.000)", -250000, false},
+		{"", 0, false},
+		{"-", 0, false},
+		{"satu juta", 0, true},
+		{"1.5O0", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"3", 3, false},
+		{"03", 3, false},
+		{"Maret", 3, false},
+		{" desember ", 12, false},
+		{"", 0, false},
+		{"13", 0, true},
+		{"0", 0, true},
+		{"March", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseMonth(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseMonth(%q) = %d, %v; want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseEFakturCSV(t *testing.T) {
+	// Ekspor e-Faktur desktop: baris header FK, LT dan OF, lalu data
+	data := "\xef\xbb\xbf" + `"FK","KD_JENIS_TRANSAKSI","FG_PENGGANTI","NOMOR_FAKTUR","MASA_PAJAK","TAHUN_PAJAK","TANGGAL_FAKTUR","NPWP","NAMA","ALAMAT_LENGKAP","JUMLAH_DPP","JUMLAH_PPN","JUMLAH_PPNBM","ID_KETERANGAN_TAMBAHAN"
+"LT","NPWP","NAMA","JALAN"
+"OF","KODE_OBJEK","NAMA","HARGA_SATUAN"
+"FK","01","0","0100025000000001","3","2025","15/03/2025","01.234.567.8-901.000","PT Maju","Jakarta","10000000","1100000","0",""
+"OF","1","Jasa konsultasi","10000000"
+"FK","01","0","0100025000000002","13","2025","15/03/2025","012345678901000","PT Maju","Jakarta","5000000","550000","0",""
+`
+	res, err := Parse("faktur.csv", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if res.Format != "efaktur_csv" {
+		t.Errorf("Format = %q, want efaktur_csv", res.Format)
+	}
+	want := []Line{{
+		Kind: KindFakturKeluaran, LineNo: 4, Reference: "0100025000000001",
+		CounterpartyNPWP: "012345678901000", Month: 3, Year: 2025, TaxBase: 10000000, TaxAmount: 1100000,
+	}}
+	if !reflect.DeepEqual(res.Lines, want) {
+		t.Errorf("Lines = %+v, want %+v", res.Lines, want)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].LineNo != 6 || res.Errors[0].Reference != "0100025000000002" {
+		t.Errorf("Errors = %+v, want one error on line 6", res.Errors)
+	}
+}
+
+func TestParseEFakturCSVWithoutHeader(t *testing.T) {
+	data := "FM;01;0;0100025000000003;4;2025;10/04/2025;987654321098000;CV Sumber;Bandung;2.000.000;220.000;0;0\n" +
+		"FM;01;0;0100025000000004;4;2025;11/04/2025;987654321098000;CV Sumber;Bandung;1.000.000;110.000;0;1\n"
+	res, err := Parse("masukan.txt", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(res.Lines) != 2 || len(res.Errors) != 0 {
+		t.Fatalf("got %d lines and %d errors, want 2 lines", len(res.Lines), len(res.Errors))
+	}
+	first, second := res.Lines[0], res.Lines[1]
+	if first.Kind != KindFakturMasukan || first.TaxBase != 2000000 || first.TaxAmount != 220000 || first.Creditable {
+		t.Errorf("first line = %+v, want a non-creditable input invoice of 2.000.000", first)
+	}
+	if !second.Creditable {
+		t.Errorf("second line = %+v, want creditable", second)
+	}
+}
+
+func TestParseEBupotCSV(t *testing.T) {
+	data := "Nomor Bukti Potong;NPWP Pemotong;NPWP/NIK Penerima;Masa Pajak;Tahun Pajak;Jumlah Penghasilan Bruto;PPh Dipotong\n" +
+		"2500001;01.234.567.8-901.000;3171010101010001;Januari;2025;1.000.000;20.000\n" +
+		"\n" +
+		";01.234.567.8-901.000;3171010101010001;1;2025;500000;10000\n" +
+		"2500003;01.234.567.8-901.000;3171010101010001;1;2025;abc;10000\n"
+	res, err := Parse("bupot.csv", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if res.Format != "ebupot_unifikasi_csv" {
+		t.Errorf("Format = %q, want ebupot_unifikasi_csv", res.Format)
+	}
+	want := []Line{{
+		Kind: KindBupotUnifikasi, LineNo: 2, Reference: "2500001", OwnerNPWP: "012345678901000",
+		CounterpartyNPWP: "3171010101010001", Month: 1, Year: 2025, TaxBase: 1000000, TaxAmount: 20000,
+	}}
+	if !reflect.DeepEqual(res.Lines, want) {
+		t.Errorf("Lines = %+v, want %+v", res.Lines, want)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].LineNo != 4 || res.Errors[1].Reference != "2500003" {
+		t.Errorf("Errors = %+v, want errors on lines 4 and 5", res.Errors)
+	}
+}
+
+func TestParseBupotXML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<BpuBulk>
+  <TIN>0012345678901000</TIN>
+  <ListOfBpu>
+    <Bpu>
+      <TaxPeriodMonth>2</TaxPeriodMonth>
+      <TaxPeriodYear>2025</TaxPeriodYear>
+      <CounterpartTin>0987654321098000</CounterpartTin>
+      <TaxBase>1000000</TaxBase>
+      <Rate>2</Rate>
+      <DocumentNumber>INV-001</DocumentNumber>
+    </Bpu>
+    <Bpu>
+      <TaxPeriodMonth>2</TaxPeriodMonth>
+      <TaxPeriodYear>2025</TaxPeriodYear>
+      <TaxBase>333333</TaxBase>
+      <Rate>1.5</Rate>
+      <BpuNumber>2500002</BpuNumber>
+    </Bpu>
+    <Bpu>
+      <TaxPeriodMonth>2</TaxPeriodMonth>
+      <TaxPeriodYear>2025</TaxPeriodYear>
+      <TaxBase>1000000</TaxBase>
+      <TaxAmount>15000</TaxAmount>
+      <BpuNumber>2500003</BpuNumber>
+    </Bpu>
+    <Bpu>
+      <TaxPeriodMonth>14</TaxPeriodMonth>
+      <TaxPeriodYear>2025</TaxPeriodYear>
+      <BpuNumber>2500004</BpuNumber>
+    </Bpu>
+  </ListOfBpu>
+</BpuBulk>`
+	// Ekstensi tidak dikenal: format ditebak dari isi
+	res, err := Parse("bupot.dat", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if res.Format != "ebupot_unifikasi_xml" {
+		t.Errorf("Format = %q, want ebupot_unifikasi_xml", res.Format)
+	}
+	if len(res.Lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %+v", len(res.Lines), res.Lines)
+	}
+	if l := res.Lines[0]; l.Reference != "INV-001" || l.OwnerNPWP != "0012345678901000" || l.TaxAmount != 20000 {
+		t.Errorf("line 1 = %+v, want reference INV-001 and PPh 20000", l)
+	}
+	// 333333 x 1,5% = 4999,995, dibulatkan ke bawah
+	if l := res.Lines[1]; l.Reference != "2500002" || l.TaxAmount != 4999 {
+		t.Errorf("line 2 = %+v, want PPh 4999", l)
+	}
+	if l := res.Lines[2]; l.TaxAmount != 15000 {
+		t.Errorf("line 3 = %+v, want the given PPh 15000", l)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].LineNo != 4 {
+		t.Errorf("Errors = %+v, want one error for document 4", res.Errors)
+	}
+}
+
+func TestParseFakturXML(t *testing.T) {
+	data := `<TaxInvoiceBulk>
+  <TIN>0012345678901000</TIN>
+  <ListOfTaxInvoice>
+    <TaxInvoice>
+      <TaxInvoiceDate>2025-05-20</TaxInvoiceDate>
+      <RefDesc>INV/2025/05/001</RefDesc>
+      <BuyerTin>0987654321098000</BuyerTin>
+      <ListOfGoodService>
+        <GoodService><TaxBase>1000000</TaxBase><VAT>110000</VAT></GoodService>
+        <GoodService><TaxBase>500000</TaxBase><VAT>55000</VAT></GoodService>
+      </ListOfGoodService>
+    </TaxInvoice>
+    <TaxInvoice>
+      <TaxInvoiceDate>20/05/2025</TaxInvoiceDate>
+      <TaxInvoiceNumber>04002500000002</TaxInvoiceNumber>
+    </TaxInvoice>
+  </ListOfTaxInvoice>
+</TaxInvoiceBulk>`
+	res, err := Parse("faktur.xml", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Line{{
+		Kind: KindFakturKeluaran, LineNo: 1, Reference: "INV/2025/05/001", OwnerNPWP: "0012345678901000",
+		CounterpartyNPWP: "0987654321098000", Month: 5, Year: 2025, TaxBase: 1500000, TaxAmount: 165000,
+	}}
+	if !reflect.DeepEqual(res.Lines, want) {
+		t.Errorf("Lines = %+v, want %+v", res.Lines, want)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Reference != "04002500000002" {
+		t.Errorf("Errors = %+v, want one error for the invoice with an invalid date", res.Errors)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	tests := []struct {
+		name, fileName, data string
+	}{
+		{"empty CSV", "kosong.csv", "\n\n"},
+		{"CSV without known columns", "lain.csv", "nama,alamat\nBudi,Jakarta\n"},
+		{"other XML", "lain.xml", "<Invoice><Number>1</Number></Invoice>"},
+		{"invalid XML", "rusak.xml", "bukan xml"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.fileName, strings.NewReader(tt.data)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("%s: err = %v, want ErrUnknownFormat", tt.name, err)
+		}
+	}
+}
+
+func TestParseTooLarge(t *testing.T) {
+	data := strings.Repeat("x", MaxFileSize+1)
+	if _, err := Parse("besar.csv", strings.NewReader(data)); err == nil || errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("err = %v, want a file size error", err)
+	}
+}
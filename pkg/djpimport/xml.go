@@ -0,0 +1,175 @@
+package djpimport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// bpuBulk adalah format XML impor/ekspor bukti potong unifikasi Coretax.
+type bpuBulk struct {
+	TIN  string `xml:"TIN"`
+	Bpus []struct {
+		TaxPeriodMonth string `xml:"TaxPeriodMonth"`
+		TaxPeriodYear  string `xml:"TaxPeriodYear"`
+		CounterpartTin string `xml:"CounterpartTin"`
+		TaxObjectCode  string `xml:"TaxObjectCode"`
+		TaxBase        string `xml:"TaxBase"`
+		Rate           string `xml:"Rate"`
+		DocumentNumber string `xml:"DocumentNumber"`
+		// Beberapa ekspor sudah menyertakan nomor dan nilai PPh hasil perhitungan
+		BpuNumber string `xml:"BpuNumber"`
+		TaxAmount string `xml:"TaxAmount"`
+	} `xml:"ListOfBpu>Bpu"`
+}
+
+// taxInvoiceBulk adalah format XML faktur keluaran Coretax.
+type taxInvoiceBulk struct {
+	TIN      string `xml:"TIN"`
+	Invoices []struct {
+		TaxInvoiceDate   string `xml:"TaxInvoiceDate"`
+		TaxInvoiceNumber string `xml:"TaxInvoiceNumber"`
+		RefDesc          string `xml:"RefDesc"`
+		BuyerTin         string `xml:"BuyerTin"`
+		GoodServices     []struct {
+			TaxBase string `xml:"TaxBase"`
+			VAT     string `xml:"VAT"`
+		} `xml:"ListOfGoodService>GoodService"`
+	} `xml:"ListOfTaxInvoice>TaxInvoice"`
+}
+
+func parseXML(data []byte) (*Result, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "BpuBulk":
+		var doc bpuBulk
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("gagal membaca XML e-Bupot: %w", err)
+		}
+		return bupotFromXML(&doc), nil
+	case "TaxInvoiceBulk":
+		var doc taxInvoiceBulk
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("gagal membaca XML e-Faktur: %w", err)
+		}
+		return fakturFromXML(&doc), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+func bupotFromXML(doc *bpuBulk) *Result {
+	res := &Result{Format: "ebupot_unifikasi_xml"}
+	owner := digitsOnly(doc.TIN)
+
+	for i, b := range doc.Bpus {
+		line := Line{
+			Kind:             KindBupotUnifikasi,
+			LineNo:           i + 1,
+			Reference:        strings.TrimSpace(b.BpuNumber),
+			OwnerNPWP:        owner,
+			CounterpartyNPWP: digitsOnly(b.CounterpartTin),
+		}
+		if line.Reference == "" {
+			line.Reference = strings.TrimSpace(b.DocumentNumber)
+		}
+
+		err := func() error {
+			var err error
+			if line.Month, err = parseMonth(b.TaxPeriodMonth); err != nil {
+				return err
+			}
+			if line.Year, err = parseYear(b.TaxPeriodYear); err != nil {
+				return err
+			}
+			if line.TaxBase, err = ParseAmount(b.TaxBase); err != nil {
+				return fmt.Errorf("TaxBase: %w", err)
+			}
+			if strings.TrimSpace(b.TaxAmount) != "" {
+				if line.TaxAmount, err = ParseAmount(b.TaxAmount); err != nil {
+					return fmt.Errorf("TaxAmount: %w", err)
+				}
+				return nil
+			}
+			rate, err := ParseAmount(b.Rate)
+			if err != nil {
+				return fmt.Errorf("Rate: %w", err)
+			}
+			// PPh dibulatkan ke bawah dalam rupiah penuh
+			line.TaxAmount = math.Floor(line.TaxBase * rate / 100)
+			return nil
+		}()
+		if err != nil {
+			res.Errors = append(res.Errors, LineError{LineNo: line.LineNo, Reference: line.Reference, Reason: err.Error()})
+			continue
+		}
+		res.Lines = append(res.Lines, line)
+	}
+	return res
+}
+
+func fakturFromXML(doc *taxInvoiceBulk) *Result {
+	res := &Result{Format: "efaktur_xml"}
+	owner := digitsOnly(doc.TIN)
+
+	for i, inv := range doc.Invoices {
+		line := Line{
+			Kind:             KindFakturKeluaran,
+			LineNo:           i + 1,
+			Reference:        strings.TrimSpace(inv.TaxInvoiceNumber),
+			OwnerNPWP:        owner,
+			CounterpartyNPWP: digitsOnly(inv.BuyerTin),
+		}
+		if line.Reference == "" {
+			line.Reference = strings.TrimSpace(inv.RefDesc)
+		}
+
+		err := func() error {
+			date, err := time.Parse("2006-01-02", strings.TrimSpace(inv.TaxInvoiceDate))
+			if err != nil {
+				return fmt.Errorf("tanggal faktur %q tidak valid", inv.TaxInvoiceDate)
+			}
+			line.Month, line.Year = int(date.Month()), date.Year()
+
+			for _, gs := range inv.GoodServices {
+				base, err := ParseAmount(gs.TaxBase)
+				if err != nil {
+					return fmt.Errorf("TaxBase: %w", err)
+				}
+				vat, err := ParseAmount(gs.VAT)
+				if err != nil {
+					return fmt.Errorf("VAT: %w", err)
+				}
+				line.TaxBase += base
+				line.TaxAmount += vat
+			}
+			return nil
+		}()
+		if err != nil {
+			res.Errors = append(res.Errors, LineError{LineNo: line.LineNo, Reference: line.Reference, Reason: err.Error()})
+			continue
+		}
+		res.Lines = append(res.Lines, line)
+	}
+	return res
+}
+
+// rootElement mengembalikan nama elemen root dokumen XML.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "", ErrUnknownFormat
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package hmacsig menandatangani dan memverifikasi body webhook dengan HMAC-SHA256.
+//
+// Tanda tangan dihitung atas "<timestamp>.<body>" sehingga penerima bisa menolak
+// request lama yang diputar ulang. Formatnya "sha256=<hex>".
+package hmacsig
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const prefix = "sha256="
+
+// ErrInvalidSignature dikembalikan Verify jika tanda tangan tidak cocok atau kedaluwarsa.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign mengembalikan tanda tangan body untuk timestamp (detik Unix) yang diberikan.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa signature untuk body dan timestamp, serta menolak timestamp
+// yang berselisih lebih dari tolerance dari now. tolerance 0 berarti tanpa batas.
+func Verify(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration, now time.Time) error {
+	if !strings.HasPrefix(signature, prefix) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		diff := now.Sub(time.Unix(timestamp, 0))
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			return ErrInvalidSignature
+		}
+	}
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// NewSecret membuat secret acak 32 byte dalam bentuk hex untuk subscription baru.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
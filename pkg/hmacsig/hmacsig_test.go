@@ -0,0 +1,81 @@
+package hmacsig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret    = "whsec_test"
+	testTimestamp = int64(1700000000)
+	testBody      = `{"event_type":"invoice.paid"}`
+	// HMAC-SHA256("whsec_test", "1700000000.{"event_type":"invoice.paid"}"), dihitung terpisah
+	testSignature = "sha256=e200e09b724e3fa7fbb27f7275ac15bf9427dce1f92ba6a389c3988514341b77"
+)
+
+func TestSign(t *testing.T) {
+	if got := Sign(testSecret, testTimestamp, []byte(testBody)); got != testSignature {
+		t.Errorf("Sign = %s, want %s", got, testSignature)
+	}
+	if Sign(testSecret, testTimestamp+1, []byte(testBody)) == testSignature {
+		t.Error("the signature does not depend on the timestamp")
+	}
+	if Sign("other", testTimestamp, []byte(testBody)) == testSignature {
+		t.Error("the signature does not depend on the secret")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	sent := time.Unix(testTimestamp, 0)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		signature string
+		tolerance time.Duration
+		now       time.Time
+		wantErr   bool
+	}{
+		{"valid", testSecret, testTimestamp, testBody, testSignature, 5 * time.Minute, sent, false},
+		{"valid at the end of the tolerance", testSecret, testTimestamp, testBody, testSignature, 5 * time.Minute, sent.Add(5 * time.Minute), false},
+		{"clock of the sender ahead", testSecret, testTimestamp, testBody, testSignature, 5 * time.Minute, sent.Add(-5 * time.Minute), false},
+		{"too old", testSecret, testTimestamp, testBody, testSignature, 5 * time.Minute, sent.Add(5*time.Minute + time.Second), true},
+		{"too far in the future", testSecret, testTimestamp, testBody, testSignature, 5 * time.Minute, sent.Add(-6 * time.Minute), true},
+		{"no tolerance", testSecret, testTimestamp, testBody, testSignature, 0, sent.Add(365 * 24 * time.Hour), false},
+		{"wrong secret", "other", testTimestamp, testBody, testSignature, 0, sent, true},
+		{"changed body", testSecret, testTimestamp, `{"event_type":"client.created"}`, testSignature, 0, sent, true},
+		{"changed timestamp", testSecret, testTimestamp + 1, testBody, testSignature, 0, sent, true},
+		{"missing prefix", testSecret, testTimestamp, testBody, strings.TrimPrefix(testSignature, prefix), 0, sent, true},
+		{"upper-case hex", testSecret, testTimestamp, testBody, prefix + strings.ToUpper(strings.TrimPrefix(testSignature, prefix)), 0, sent, true},
+		{"empty", testSecret, testTimestamp, testBody, "", 0, sent, true},
+	}
+	for _, tt := range tests {
+		err := Verify(tt.secret, tt.timestamp, []byte(tt.body), tt.signature, tt.tolerance, tt.now)
+		if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify = %v, want ErrInvalidSignature", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: Verify = %v, want nil", tt.name, err)
+		}
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	b, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	if len(a) != 64 || strings.Trim(a, "0123456789abcdef") != "" {
+		t.Errorf("NewSecret = %q, want 64 hex characters", a)
+	}
+	if a == b {
+		t.Error("NewSecret returned the same secret twice")
+	}
+}
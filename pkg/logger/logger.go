@@ -0,0 +1,128 @@
+// Package logger menyiapkan log terstruktur (slog JSON) untuk seluruh aplikasi.
+//
+// Logger per request disimpan di context.Context oleh middleware, sehingga handler dan
+// kode di bawahnya cukup memanggil FromContext(ctx) untuk mendapatkan logger yang sudah
+// membawa request_id, route dan identitas user. Log lama lewat package log standar
+// diteruskan ke logger yang sama, dengan level dibaca dari prefiks "PERINGATAN:", "INFO:"
+// dan "DEBUG:". Jalur ini hanya untuk log library pihak ketiga: baris tersebut tidak membawa
+// atribut request, dan penyamaran hanya berlaku untuk nama atribut, bukan isi pesan.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RedactedValue menggantikan nilai field sensitif di log.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys adalah potongan nama field yang nilainya tidak boleh muncul di log,
+// mis. password, password_hashed, coretax_password, jwt_secret_key, access_token.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "coretax", "api_key", "apikey", "cookie"}
+
+// IsSensitiveKey melaporkan apakah nilai field dengan nama key harus disamarkan.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel membaca level log: debug, info, warn atau error. String kosong berarti info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", s)
+}
+
+// New membuat logger JSON ke w yang menyamarkan field sensitif.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+	return a
+}
+
+// Setup membuat logger JSON ke stdout, menjadikannya slog default dan meneruskan
+// package log standar ke logger tersebut.
+func Setup(level slog.Level) *slog.Logger {
+	l := New(os.Stdout, level)
+	slog.SetDefault(l)
+	log.SetFlags(0)
+	log.SetOutput(&stdWriter{logger: l})
+	return l
+}
+
+type contextKey struct{}
+
+// WithLogger menyimpan l di ctx.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext mengembalikan logger di ctx, atau slog.Default() jika tidak ada.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return slog.Default()
+}
+
+// With menambahkan atribut ke logger di ctx dan mengembalikan context baru.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// stdWriter meneruskan output package log ke slog. Prefiks level lama dibuang dari pesan.
+type stdWriter struct {
+	logger *slog.Logger
+}
+
+var stdPrefixes = []struct {
+	prefix string
+	level  slog.Level
+}{
+	{"PERINGATAN:", slog.LevelWarn},
+	{"ERROR:", slog.LevelError},
+	{"INFO:", slog.LevelInfo},
+	{"DEBUG:", slog.LevelDebug},
+}
+
+func (w *stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	level := slog.LevelInfo
+	for _, sp := range stdPrefixes {
+		if strings.HasPrefix(msg, sp.prefix) {
+			level = sp.level
+			msg = strings.TrimSpace(strings.TrimPrefix(msg, sp.prefix))
+			break
+		}
+	}
+	w.logger.Log(context.Background(), level, msg)
+	return len(p), nil
+}
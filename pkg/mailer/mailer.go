@@ -0,0 +1,166 @@
+// Package mailer mengirim email multipart (teks dan HTML) lewat SMTP.
+// Untuk pengembangan lokal, arahkan ke MailHog (SMTP di localhost:1025 tanpa auth).
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message adalah satu email. Text wajib; HTML opsional.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim Message.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPConfig adalah konfigurasi server SMTP. Username kosong berarti tanpa auth.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+var ErrNoRecipient = errors.New("email tidak memiliki penerima")
+
+type smtpMailer struct {
+	cfg  SMTPConfig
+	from mail.Address
+}
+
+// NewSMTPMailer membuat Mailer yang mengirim lewat server SMTP cfg.
+func NewSMTPMailer(cfg SMTPConfig) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("alamat pengirim tidak valid: %w", err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 25
+	}
+	return &smtpMailer{cfg: cfg, from: *from}, nil
+}
+
+func (m *smtpMailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipient
+	}
+	body, err := buildMessage(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if err := smtp.SendMail(addr, auth, m.from.Address, msg.To, body); err != nil {
+		return fmt.Errorf("gagal mengirim email ke %s: %w", strings.Join(msg.To, ", "), err)
+	}
+	return nil
+}
+
+type logMailer struct{}
+
+// NewLogMailer membuat Mailer yang hanya mencatat email ke log. Dipakai jika SMTP belum dikonfigurasi.
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipient
+	}
+	slog.Info("SMTP belum dikonfigurasi, email tidak dikirim", "to", strings.Join(msg.To, ", "), "subject", msg.Subject)
+	return nil
+}
+
+// buildMessage menyusun email RFC 5322 dengan body multipart/alternative.
+func buildMessage(from mail.Address, msg Message, now time.Time) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s.%d@%s>", boundary[:16], now.UnixNano(), domainOf(from.Address)))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", part.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, s string) error {
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return fmt.Errorf("gagal menyusun isi email: %w", err)
+	}
+	return qp.Close()
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("gagal membuat boundary email: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
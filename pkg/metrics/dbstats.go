@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+)
+
+// NewDBStatsCollector exposes the connection pool statistics of db.
+func NewDBStatsCollector(db *sql.DB) Collector {
+	return CollectorFunc(func(context.Context) ([]Family, error) {
+		s := db.Stats()
+		return []Family{
+			Gauge("dashboard_db_max_open_connections", "Maximum number of open connections to the database.", Sample{Value: float64(s.MaxOpenConnections)}),
+			Gauge("dashboard_db_open_connections", "Number of established connections, in use and idle.", Sample{Value: float64(s.OpenConnections)}),
+			Gauge("dashboard_db_in_use_connections", "Number of connections currently in use.", Sample{Value: float64(s.InUse)}),
+			Gauge("dashboard_db_idle_connections", "Number of idle connections.", Sample{Value: float64(s.Idle)}),
+			CounterFamily("dashboard_db_wait_count_total", "Total number of connections waited for.", Sample{Value: float64(s.WaitCount)}),
+			CounterFamily("dashboard_db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", Sample{Value: s.WaitDuration.Seconds()}),
+			CounterFamily("dashboard_db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", Sample{Value: float64(s.MaxIdleClosed)}),
+			CounterFamily("dashboard_db_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.", Sample{Value: float64(s.MaxIdleTimeClosed)}),
+			CounterFamily("dashboard_db_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", Sample{Value: float64(s.MaxLifetimeClosed)}),
+		}, nil
+	})
+}
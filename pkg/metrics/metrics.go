@@ -0,0 +1,372 @@
+// Package metrics adalah implementasi kecil format teks Prometheus (exposition format 0.0.4)
+// tanpa dependensi luar: counter, gauge dan histogram berlabel, serta collector yang
+// nilainya dihitung saat di-scrape.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/logger"
+)
+
+// Label is one name/value pair of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one line of a metric family. Suffix is appended to the family name,
+// e.g. "_bucket" for histograms.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a metric with its help text, type and samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string // counter, gauge atau histogram
+	Samples []Sample
+}
+
+// Collector produces metric families on every scrape. ctx is the context of the scrape
+// request, for collectors that query the database.
+type Collector interface {
+	Collect(ctx context.Context) ([]Family, error)
+}
+
+// CollectorFunc adapts a function to Collector.
+type CollectorFunc func(ctx context.Context) ([]Family, error)
+
+// Collect calls f.
+func (f CollectorFunc) Collect(ctx context.Context) ([]Family, error) { return f(ctx) }
+
+// Registry holds the collectors exposed on /metrics.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds collectors to the registry.
+func (r *Registry) Register(cs ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, cs...)
+}
+
+// Gather collects every family, sorted by name. A failing collector is skipped and its
+// error returned together with the families of the others.
+func (r *Registry) Gather(ctx context.Context) ([]Family, error) {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	var families []Family
+	var errs []string
+	for _, c := range collectors {
+		fs, err := c.Collect(ctx)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		families = append(families, fs...)
+	}
+	sort.SliceStable(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	if len(errs) > 0 {
+		return families, fmt.Errorf("metrics collection failed: %s", strings.Join(errs, "; "))
+	}
+	return families, nil
+}
+
+// ContentType is the content type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes families in the Prometheus text format.
+func WriteText(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l.Name, escapeLabel(l.Value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registry. Collector errors are reported as 500 only if nothing
+// could be collected, so one failing business query does not hide the HTTP metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		families, err := r.Gather(req.Context())
+		if err != nil {
+			logger.FromContext(req.Context()).Warn("Gagal mengumpulkan sebagian metrik", "error", err)
+			if len(families) == 0 {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", ContentType)
+		WriteText(w, families)
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// labelKey joins label values into a map key.
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func labelsOf(names, values []string) []Label {
+	labels := make([]Label, len(names))
+	for i, n := range names {
+		labels[i] = Label{Name: n, Value: values[i]}
+	}
+	return labels
+}
+
+// vec is the shared label bookkeeping of CounterVec and HistogramVec.
+type vec[T any] struct {
+	name, help string
+	labelNames []string
+	newChild   func() *T
+
+	mu       sync.Mutex
+	children map[string]*T
+	values   map[string][]string
+}
+
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+	key := labelKey(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	child, ok := v.children[key]
+	if !ok {
+		child = v.newChild()
+		v.children[key] = child
+		v.values[key] = append([]string(nil), values...)
+	}
+	return child
+}
+
+// each calls fn for every child in a stable order.
+func (v *vec[T]) each(fn func(values []string, child *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type entry struct {
+		values []string
+		child  *T
+	}
+	entries := make([]entry, len(keys))
+	for i, k := range keys {
+		entries[i] = entry{v.values[k], v.children[k]}
+	}
+	v.mu.Unlock()
+	for _, e := range entries {
+		fn(e.values, e.child)
+	}
+}
+
+func newVec[T any](name, help string, labelNames []string, newChild func() *T) vec[T] {
+	return vec[T]{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		newChild:   newChild,
+		children:   make(map[string]*T),
+		values:     make(map[string][]string),
+	}
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// Inc adds one.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds v, which must not be negative.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+// CounterVec is a counter per combination of label values.
+type CounterVec struct {
+	vec[Counter]
+}
+
+// NewCounterVec creates a CounterVec. Register it on a Registry to expose it.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, labelNames, func() *Counter { return &Counter{} })}
+}
+
+// WithLabelValues returns the counter of the given label values, in labelNames order.
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.with(values)
+}
+
+// Collect implements Collector.
+func (v *CounterVec) Collect(context.Context) ([]Family, error) {
+	f := Family{Name: v.name, Help: v.help, Type: "counter"}
+	v.each(func(values []string, c *Counter) {
+		c.mu.Lock()
+		value := c.value
+		c.mu.Unlock()
+		f.Samples = append(f.Samples, Sample{Labels: labelsOf(v.labelNames, values), Value: value})
+	})
+	return []Family{f}, nil
+}
+
+// DefBuckets are the default histogram buckets in seconds, suited to request latencies.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	upperBounds []float64
+
+	mu     sync.Mutex
+	counts []uint64 // Per bucket, non-kumulatif; bucket terakhir adalah +Inf
+	sum    float64
+	count  uint64
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v) // Bucket pertama dengan upper bound >= v
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// HistogramVec is a histogram per combination of label values.
+type HistogramVec struct {
+	vec[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec creates a HistogramVec with the given bucket upper bounds (sorted
+// ascending, without +Inf). nil buckets means DefBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		vec: newVec(name, help, labelNames, func() *Histogram {
+			return &Histogram{upperBounds: buckets, counts: make([]uint64, len(buckets)+1)}
+		}),
+		buckets: buckets,
+	}
+}
+
+// WithLabelValues returns the histogram of the given label values, in labelNames order.
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.with(values)
+}
+
+// Collect implements Collector.
+func (v *HistogramVec) Collect(context.Context) ([]Family, error) {
+	f := Family{Name: v.name, Help: v.help, Type: "histogram"}
+	v.each(func(values []string, h *Histogram) {
+		h.mu.Lock()
+		counts := append([]uint64(nil), h.counts...)
+		sum, count := h.sum, h.count
+		h.mu.Unlock()
+
+		labels := labelsOf(v.labelNames, values)
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += counts[i]
+			f.Samples = append(f.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(append([]Label(nil), labels...), Label{Name: "le", Value: formatFloat(upper)}),
+				Value:  float64(cumulative),
+			})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: append(append([]Label(nil), labels...), Label{Name: "le", Value: "+Inf"}), Value: float64(count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(count)},
+		)
+	})
+	return []Family{f}, nil
+}
+
+// Gauge builds a gauge family from values computed at scrape time.
+func Gauge(name, help string, samples ...Sample) Family {
+	return Family{Name: name, Help: help, Type: "gauge", Samples: samples}
+}
+
+// CounterFamily builds a counter family from values computed at scrape time, e.g. totals
+// kept by another package such as sql.DBStats.
+func CounterFamily(name, help string, samples ...Sample) Family {
+	return Family{Name: name, Help: help, Type: "counter", Samples: samples}
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// text collects c and returns it in the Prometheus text format
+func text(t *testing.T, c Collector) string {
+	t.Helper()
+	families, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	var b strings.Builder
+	if err := WriteText(&b, families); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	return b.String()
+}
+
+func TestWriteTextEscaping(t *testing.T) {
+	families := []Family{
+		Gauge("app_info", "Build info.\nPath C:\\app, \"quoted\" help",
+			Sample{Labels: []Label{{Name: "path", Value: `C:\app`}, {Name: "note", Value: "say \"hi\"\nbye"}}, Value: 1},
+		),
+		{Name: "app_without_help", Type: "gauge", Samples: []Sample{{Value: 0.5}}},
+	}
+	var b strings.Builder
+	if err := WriteText(&b, families); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	// HELP hanya meng-escape backslash dan newline; tanda kutip hanya di-escape pada nilai label
+	want := `# HELP app_info Build info.\nPath C:\\app, "quoted" help
+# TYPE app_info gauge
+app_info{path="C:\\app",note="say \"hi\"\nbye"} 1
+# TYPE app_without_help gauge
+app_without_help 0.5
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{0.005, "0.005"},
+		{2.5, "2.5"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.in); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCounterVec(t *testing.T) {
+	c := NewCounterVec("http_requests_total", "Total HTTP requests.", "method", "status")
+	c.WithLabelValues("GET", "200").Inc()
+	c.WithLabelValues("GET", "200").Add(2)
+	c.WithLabelValues("DELETE", "204").Inc()
+
+	want := `# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="DELETE",status="204"} 1
+http_requests_total{method="GET",status="200"} 3
+`
+	if got := text(t, c); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounterVecPanics(t *testing.T) {
+	c := NewCounterVec("jobs_total", "", "type")
+	for name, fn := range map[string]func(){
+		"wrong label count": func() { c.WithLabelValues("a", "b") },
+		"negative add":      func() { c.WithLabelValues("a").Add(-1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: did not panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	// Bucket tidak urut dan nilai tepat di batas: 0.25 masuk le="0.25"
+	h := NewHistogramVec("http_request_duration_seconds", "Request latency.", []float64{10, 0.25, 0.5}, "route")
+	for _, v := range []float64{0.25, 0.375, 7, 100} {
+		h.WithLabelValues(`/a"b`).Observe(v)
+	}
+	h.WithLabelValues("/health").Observe(0.125)
+
+	want := `# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/a\"b",le="0.25"} 1
+http_request_duration_seconds_bucket{route="/a\"b",le="0.5"} 2
+http_request_duration_seconds_bucket{route="/a\"b",le="10"} 3
+http_request_duration_seconds_bucket{route="/a\"b",le="+Inf"} 4
+http_request_duration_seconds_sum{route="/a\"b"} 107.625
+http_request_duration_seconds_count{route="/a\"b"} 4
+http_request_duration_seconds_bucket{route="/health",le="0.25"} 1
+http_request_duration_seconds_bucket{route="/health",le="0.5"} 1
+http_request_duration_seconds_bucket{route="/health",le="10"} 1
+http_request_duration_seconds_bucket{route="/health",le="+Inf"} 1
+http_request_duration_seconds_sum{route="/health"} 0.125
+http_request_duration_seconds_count{route="/health"} 1
+`
+	if got := text(t, h); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramDefaultBuckets(t *testing.T) {
+	h := NewHistogramVec("db_query_seconds", "", nil)
+	h.WithLabelValues().Observe(0.003)
+	got := text(t, h)
+	if n := strings.Count(got, "db_query_seconds_bucket{"); n != len(DefBuckets)+1 {
+		t.Errorf("got %d bucket lines, want %d:\n%s", n, len(DefBuckets)+1, got)
+	}
+	for _, line := range []string{
+		`db_query_seconds_bucket{le="0.005"} 1`,
+		`db_query_seconds_bucket{le="+Inf"} 1`,
+		"db_query_seconds_sum 0.003",
+		"db_query_seconds_count 1",
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("output does not contain %q:\n%s", line, got)
+		}
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	failing := CollectorFunc(func(context.Context) ([]Family, error) { return nil, errors.New("database down") })
+	static := CollectorFunc(func(context.Context) ([]Family, error) {
+		return []Family{CounterFamily("b_total", "", Sample{Value: 2}), Gauge("a_value", "", Sample{Value: 1})}, nil
+	})
+
+	r := NewRegistry()
+	r.Register(failing, static)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	// Satu collector gagal tidak menyembunyikan metrik yang lain; family diurutkan per nama
+	want := "# TYPE a_value gauge\na_value 1\n# TYPE b_total counter\nb_total 2\n"
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("partial failure: status %d, body:\n%s\nwant 200 with:\n%s", w.Code, w.Body.String(), want)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+
+	r = NewRegistry()
+	r.Register(failing)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("all collectors failed: status %d, want 500", w.Code)
+	}
+}
@@ -0,0 +1,220 @@
+// Package npwp mem-parsing, memvalidasi, menormalisasi dan memformat NPWP.
+//
+// Tiga bentuk yang diterima:
+//   - NPWP 15 digit lama, mis. 01.234.567.8-901.000
+//   - NPWP 16 digit untuk badan/non-NIK, yaitu NPWP 15 digit dengan awalan 0
+//   - NIK 16 digit yang dipakai sebagai NPWP orang pribadi
+//
+// Bentuk kanonik selalu 16 digit tanpa tanda baca, sehingga NPWP 15 digit dan
+// padanan 16 digitnya menghasilkan nilai kanonik yang sama.
+package npwp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// LegacyLength adalah panjang NPWP format lama (15 digit).
+	LegacyLength = 15
+	// Length adalah panjang NPWP format baru dan NIK (16 digit).
+	Length = 16
+)
+
+var (
+	ErrEmpty         = errors.New("NPWP wajib diisi")
+	ErrInvalidChar   = errors.New("NPWP hanya boleh berisi angka, titik, tanda hubung dan spasi")
+	ErrInvalidLength = errors.New("NPWP harus 15 atau 16 digit")
+	ErrInvalidNPWP   = errors.New("NPWP tidak valid")
+	ErrInvalidNIK    = errors.New("NIK tidak valid")
+)
+
+// Kind menunjukkan bentuk NPWP yang diinput.
+type Kind int
+
+const (
+	// KindLegacy adalah NPWP 15 digit.
+	KindLegacy Kind = iota + 1
+	// KindNPWP16 adalah NPWP 16 digit berawalan 0 (padanan NPWP 15 digit).
+	KindNPWP16
+	// KindNIK adalah NIK 16 digit yang berfungsi sebagai NPWP.
+	KindNIK
+)
+
+// Number adalah NPWP yang sudah lolos validasi.
+type Number struct {
+	digits string
+	kind   Kind
+}
+
+// Parse membuang pemisah (titik, tanda hubung, spasi) lalu memvalidasi hasilnya.
+func Parse(s string) (Number, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Number{}, ErrEmpty
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '.' || r == '-' || r == ' ':
+		default:
+			return Number{}, ErrInvalidChar
+		}
+	}
+	digits := b.String()
+
+	switch len(digits) {
+	case LegacyLength:
+		if err := validateLegacy(digits); err != nil {
+			return Number{}, err
+		}
+		return Number{digits: digits, kind: KindLegacy}, nil
+	case Length:
+		if digits[0] == '0' {
+			if err := validateLegacy(digits[1:]); err != nil {
+				return Number{}, err
+			}
+			return Number{digits: digits, kind: KindNPWP16}, nil
+		}
+		if err := validateNIK(digits); err != nil {
+			return Number{}, err
+		}
+		return Number{digits: digits, kind: KindNIK}, nil
+	default:
+		return Number{}, ErrInvalidLength
+	}
+}
+
+// Valid melaporkan apakah s adalah NPWP yang valid.
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// Normalize mengembalikan bentuk kanonik 16 digit dari s.
+func Normalize(s string) (string, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return n.Canonical(), nil
+}
+
+// Equal melaporkan apakah a dan b adalah NPWP yang sama, mis. 01.234.567.8-901.000
+// dan 0012345678901000. Nilai yang tidak valid tidak pernah sama.
+func Equal(a, b string) bool {
+	na, err := Parse(a)
+	if err != nil {
+		return false
+	}
+	nb, err := Parse(b)
+	if err != nil {
+		return false
+	}
+	return na.Canonical() == nb.Canonical()
+}
+
+// Digits mengembalikan hanya digit dari s, tanpa validasi. Berguna untuk pencarian sebagian.
+func Digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Kind mengembalikan bentuk NPWP yang diinput.
+func (n Number) Kind() Kind {
+	return n.kind
+}
+
+// Digits mengembalikan digit NPWP sesuai bentuk yang diinput (15 atau 16 digit).
+func (n Number) Digits() string {
+	return n.digits
+}
+
+// Canonical mengembalikan bentuk 16 digit; NPWP 15 digit diberi awalan 0.
+func (n Number) Canonical() string {
+	if n.kind == KindLegacy {
+		return "0" + n.digits
+	}
+	return n.digits
+}
+
+// Legacy mengembalikan NPWP 15 digit jika ada padanannya (bukan NIK).
+func (n Number) Legacy() (string, bool) {
+	switch n.kind {
+	case KindLegacy:
+		return n.digits, true
+	case KindNPWP16:
+		return n.digits[1:], true
+	default:
+		return "", false
+	}
+}
+
+// Format mengembalikan bentuk tampilan: NPWP 15 digit dengan tanda baca
+// (01.234.567.8-901.000), sedangkan NPWP 16 digit dan NIK ditulis tanpa pemisah
+// seperti di Coretax.
+func (n Number) Format() string {
+	if n.kind != KindLegacy {
+		return n.digits
+	}
+	d := n.digits
+	return fmt.Sprintf("%s.%s.%s.%s-%s.%s", d[0:2], d[2:5], d[5:8], d[8:9], d[9:12], d[12:15])
+}
+
+// String sama dengan Format.
+func (n Number) String() string {
+	return n.Format()
+}
+
+// validateLegacy memeriksa struktur NPWP 15 digit: 9 digit identitas wajib pajak
+// (2 digit jenis + 6 digit nomor urut + 1 digit pemeriksa), 3 digit kode KPP dan
+// 3 digit status pusat/cabang. Digit pemeriksa tidak dihitung ulang karena algoritmanya
+// tidak dipublikasikan DJP; yang ditolak hanya nomor urut dan kode KPP kosong.
+func validateLegacy(d string) error {
+	if d[2:8] == "000000" {
+		return fmt.Errorf("%w: nomor urut wajib pajak tidak boleh 000000", ErrInvalidNPWP)
+	}
+	if d[9:12] == "000" {
+		return fmt.Errorf("%w: kode KPP tidak boleh 000", ErrInvalidNPWP)
+	}
+	return nil
+}
+
+// validateNIK memeriksa struktur NIK: kode wilayah (provinsi 11-94), tanggal lahir
+// (ditambah 40 untuk perempuan), bulan lahir dan nomor urut.
+func validateNIK(d string) error {
+	province := atoi2(d[0:2])
+	if province < 11 || province > 94 {
+		return fmt.Errorf("%w: kode provinsi %s tidak dikenal", ErrInvalidNIK, d[0:2])
+	}
+	if d[2:4] == "00" || d[4:6] == "00" {
+		return fmt.Errorf("%w: kode kabupaten/kecamatan tidak boleh 00", ErrInvalidNIK)
+	}
+	day := atoi2(d[6:8])
+	if day > 40 {
+		day -= 40
+	}
+	if day < 1 || day > 31 {
+		return fmt.Errorf("%w: tanggal lahir %s tidak valid", ErrInvalidNIK, d[6:8])
+	}
+	month := atoi2(d[8:10])
+	if month < 1 || month > 12 {
+		return fmt.Errorf("%w: bulan lahir %s tidak valid", ErrInvalidNIK, d[8:10])
+	}
+	if d[12:16] == "0000" {
+		return fmt.Errorf("%w: nomor urut tidak boleh 0000", ErrInvalidNIK)
+	}
+	return nil
+}
+
+func atoi2(s string) int {
+	return int(s[0]-'0')*10 + int(s[1]-'0')
+}
@@ -0,0 +1,105 @@
+package npwp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		kind      Kind
+		canonical string
+		format    string
+		legacy    string
+		wantErr   error
+	}{
+		{"15 digit formatted", "01.234.567.8-901.000", KindLegacy, "0012345678901000", "01.234.567.8-901.000", "012345678901000", nil},
+		{"15 digit unformatted", "012345678901000", KindLegacy, "0012345678901000", "01.234.567.8-901.000", "012345678901000", nil},
+		{"15 digit with spaces", " 01 234 567 8 901 000 ", KindLegacy, "0012345678901000", "01.234.567.8-901.000", "012345678901000", nil},
+		{"16 digit with leading 0", "0012345678901000", KindNPWP16, "0012345678901000", "0012345678901000", "012345678901000", nil},
+		{"16 digit branch", "0012345678901001", KindNPWP16, "0012345678901001", "0012345678901001", "012345678901001", nil},
+		{"NIK", "3171010101900001", KindNIK, "3171010101900001", "3171010101900001", "", nil},
+		{"NIK female birth date", "3171014501900001", KindNIK, "3171014501900001", "3171014501900001", "", nil},
+
+		{"empty", "  ", 0, "", "", "", ErrEmpty},
+		{"letters", "01.234.567.8-901.00A", 0, "", "", "", ErrInvalidChar},
+		{"slash", "01/234/567", 0, "", "", "", ErrInvalidChar},
+		{"14 digit", "01234567890100", 0, "", "", "", ErrInvalidLength},
+		{"17 digit", "00123456789010001", 0, "", "", "", ErrInvalidLength},
+		{"15 digit zero serial", "010000000901000", 0, "", "", "", ErrInvalidNPWP},
+		{"15 digit zero KPP", "012345678000000", 0, "", "", "", ErrInvalidNPWP},
+		{"16 digit zero serial", "0010000000901000", 0, "", "", "", ErrInvalidNPWP},
+		{"NIK unknown province", "9971010101900001", 0, "", "", "", ErrInvalidNIK},
+		{"NIK zero regency", "3100010101900001", 0, "", "", "", ErrInvalidNIK},
+		{"NIK birth day 32", "3171013201900001", 0, "", "", "", ErrInvalidNIK},
+		{"NIK birth day 72", "3171017201900001", 0, "", "", "", ErrInvalidNIK},
+		{"NIK birth month 13", "3171010113900001", 0, "", "", "", ErrInvalidNIK},
+		{"NIK zero serial", "3171010101900000", 0, "", "", "", ErrInvalidNIK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if Valid(tt.in) != (tt.wantErr == nil) {
+				t.Errorf("Valid(%q) = %v, want %v", tt.in, Valid(tt.in), tt.wantErr == nil)
+			}
+			if err != nil {
+				return
+			}
+			if n.Kind() != tt.kind {
+				t.Errorf("Kind = %v, want %v", n.Kind(), tt.kind)
+			}
+			if got := n.Canonical(); got != tt.canonical {
+				t.Errorf("Canonical = %q, want %q", got, tt.canonical)
+			}
+			if got := n.Format(); got != tt.format {
+				t.Errorf("Format = %q, want %q", got, tt.format)
+			}
+			legacy, ok := n.Legacy()
+			if legacy != tt.legacy || ok != (tt.legacy != "") {
+				t.Errorf("Legacy = %q, %v; want %q", legacy, ok, tt.legacy)
+			}
+			if got, _ := Normalize(tt.in); got != tt.canonical {
+				t.Errorf("Normalize = %q, want %q", got, tt.canonical)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"01.234.567.8-901.000", "012345678901000", true},
+		{"01.234.567.8-901.000", "0012345678901000", true},
+		{"012345678901000", "0012345678901000", true},
+		{"3171010101900001", "3171010101900001", true},
+		{"01.234.567.8-901.000", "01.234.567.8-901.001", false},
+		{"0012345678901000", "3171010101900001", false},
+		// Nilai yang tidak valid tidak pernah sama, termasuk dengan dirinya sendiri
+		{"", "", false},
+		{"012345678000000", "012345678000000", false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := Equal(tt.b, tt.a); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestDigits(t *testing.T) {
+	if got := Digits("01.234.567.8-901.000"); got != "012345678901000" {
+		t.Errorf("Digits = %q, want 012345678901000", got)
+	}
+	if got := Digits("NPWP: 01.234"); got != "01234" {
+		t.Errorf("Digits = %q, want 01234", got)
+	}
+}
@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generator builds schemas from Go types. Named structs become component schemas and are
+// referenced with $ref, so a model used by many operations is described once.
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	custom  map[reflect.Type]*Schema
+}
+
+// NewGenerator returns a generator without component schemas.
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+		custom:  map[reflect.Type]*Schema{},
+	}
+}
+
+// Register sets the schema of the type of v, for types with their own JSON encoding such as
+// a date type. Types implementing json.Marshaler that are not registered accept any value.
+func (g *Generator) Register(v interface{}, s *Schema) {
+	g.custom[reflect.TypeOf(v)] = s
+}
+
+// Schema returns the schema of the type of v.
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// Components returns the component schemas collected so far, keyed by name.
+func (g *Generator) Components() map[string]*Schema {
+	return g.schemas
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if s, ok := g.custom[t]; ok {
+		c := *s
+		return &c
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Kind() == reflect.Ptr {
+		s := g.schemaOf(t.Elem())
+		if s.Ref != "" {
+			// $ref tidak boleh punya saudara di OpenAPI 3.0, jadi nullable lewat allOf
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return &Schema{} // Encoding sendiri yang tidak didaftarkan, mis. json.RawMessage
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// Slice nil dikodekan sebagai null
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return RefSchema(g.component(t))
+	}
+	return &Schema{} // interface{}: nilai apa saja
+}
+
+// component registers the named struct t and returns its component name. The name is the
+// type name, prefixed with the package name if another package already uses it.
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.schemas[name] = &Schema{Type: "object"} // Placeholder untuk tipe rekursif
+	g.schemas[name] = g.structSchema(t)
+	return name
+}
+
+// structSchema describes the JSON object of struct t. Embedded structs are flattened like
+// encoding/json does, and the binding tags of gin's validator become constraints.
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := g.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs := g.schemaOf(f.Type)
+		if applyBinding(fs, f.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+// applyBinding adds the constraints of a gin binding tag to s and reports whether the field
+// is required. Rules after "dive" apply to the elements and are ignored.
+func applyBinding(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			return required
+		case "required":
+			required = true
+			if s.Type == "string" && s.MinLength == nil {
+				one := 1
+				s.MinLength = &one // "required" di gin menolak string kosong
+			}
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "min":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			switch s.Type {
+			case "string":
+				s.MinLength = &n
+			case "array":
+				s.MinItems = &n
+			default:
+				f := float64(n)
+				s.Minimum = &f
+			}
+		case "gt", "gte":
+			if f, err := strconv.ParseFloat(arg, 64); err == nil {
+				s.Minimum = &f
+				s.ExclusiveMinimum = key == "gt"
+			}
+		case "max", "lte":
+			if f, err := strconv.ParseFloat(arg, 64); err == nil && (s.Type == "integer" || s.Type == "number") {
+				s.Maximum = &f
+			}
+		}
+	}
+	return required
+}
+
+func enumValue(typ, v string) interface{} {
+	if typ == "integer" || typ == "number" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator checks requests against the operations of a document. It only checks what the
+// document describes: parameters, the content type and JSON bodies. Unknown body fields and
+// query parameters are allowed, as gin's binding does.
+type Validator struct {
+	doc         *Document
+	maxBodySize int64
+}
+
+// NewValidator returns a validator for doc. JSON bodies larger than maxBodySize bytes are
+// rejected without reading them completely; 0 means no limit.
+func NewValidator(doc *Document, maxBodySize int64) *Validator {
+	return &Validator{doc: doc, maxBodySize: maxBodySize}
+}
+
+// Validate checks r against op and returns the problems found, or nil. pathParams are the
+// values of the parameters in the path template. A JSON body is read and put back, so the
+// handler can still bind it. err is only set if the body could not be read; it is an
+// *http.MaxBytesError if the body is larger than the limit.
+func (v *Validator) Validate(op *Operation, r *http.Request, pathParams map[string]string) (problems []string, err error) {
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var value string
+		var present bool
+		switch p.In {
+		case "path":
+			value, present = pathParams[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			value = query.Get(p.Name)
+		default:
+			continue
+		}
+		label := fmt.Sprintf("%s parameter %s", p.In, p.Name)
+		if !present {
+			if p.Required {
+				problems = append(problems, label+" is required")
+			}
+			continue
+		}
+		v.checkParameter(p.Schema, value, label, &problems)
+	}
+
+	if op.RequestBody != nil {
+		bodyProblems, err := v.checkBody(op.RequestBody, r)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, bodyProblems...)
+	}
+	return problems, nil
+}
+
+// checkParameter parses a parameter value according to its schema type and checks it
+func (v *Validator) checkParameter(s *Schema, value, at string, problems *[]string) {
+	s = v.doc.resolve(s)
+	if s == nil {
+		return
+	}
+	var decoded interface{} = value
+	switch s.Type {
+	case "integer", "number":
+		decoded = json.Number(value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			*problems = append(*problems, at+" must be true or false")
+			return
+		}
+		decoded = b
+	}
+	v.checkValue(s, decoded, at, problems)
+}
+
+func (v *Validator) checkBody(body *RequestBody, r *http.Request) ([]string, error) {
+	empty := r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0
+	contentType := r.Header.Get("Content-Type")
+	if empty && contentType == "" {
+		if body.Required {
+			return []string{"request body is required"}, nil
+		}
+		return nil, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid Content-Type %q", contentType)}, nil
+	}
+	content, ok := body.Content[mediaType]
+	if !ok {
+		var allowed []string
+		for mt := range body.Content {
+			allowed = append(allowed, mt)
+		}
+		sort.Strings(allowed)
+		return []string{fmt.Sprintf("Content-Type %s is not supported, use %s", mediaType, strings.Join(allowed, " or "))}, nil
+	}
+	// Form multipart diperiksa handler-nya sendiri
+	if mediaType != "application/json" || content.Schema == nil || r.Body == nil {
+		return nil, nil
+	}
+
+	reader := r.Body
+	if v.maxBodySize > 0 {
+		reader = http.MaxBytesReader(nil, r.Body, v.maxBodySize)
+	}
+	raw, err := io.ReadAll(reader)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if body.Required {
+			return []string{"request body is required"}, nil
+		}
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return []string{"request body is not valid JSON: " + err.Error()}, nil
+	}
+	var problems []string
+	v.checkValue(content.Schema, value, "body", &problems)
+	return problems, nil
+}
+
+// checkValue appends the problems of value against s to problems. at is the location of the
+// value for the messages, e.g. "body.line_items[0].amount".
+func (v *Validator) checkValue(s *Schema, value interface{}, at string, problems *[]string) {
+	if s == nil {
+		return
+	}
+	if value == nil && s.Nullable {
+		return
+	}
+	for _, sub := range s.AllOf {
+		v.checkValue(sub, value, at, problems)
+	}
+	if s.Ref != "" {
+		v.checkValue(v.doc.resolve(s), value, at, problems)
+		return
+	}
+	if s.Type == "" {
+		return
+	}
+	if value == nil {
+		*problems = append(*problems, at+" must not be null")
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*problems = append(*problems, at+" must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*problems = append(*problems, join(at, name)+" is required")
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				v.checkValue(ps, obj[k], join(at, k), problems)
+			} else if s.AdditionalProperties != nil {
+				v.checkValue(s.AdditionalProperties, obj[k], join(at, k), problems)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			*problems = append(*problems, at+" must be an array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			*problems = append(*problems, fmt.Sprintf("%s must have at least %d items", at, *s.MinItems))
+		}
+		for i, item := range arr {
+			v.checkValue(s.Items, item, fmt.Sprintf("%s[%d]", at, i), problems)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*problems = append(*problems, at+" must be a string")
+			return
+		}
+		if s.MinLength != nil && utf8.RuneCountInString(str) < *s.MinLength {
+			if *s.MinLength == 1 {
+				*problems = append(*problems, at+" must not be empty")
+			} else {
+				*problems = append(*problems, fmt.Sprintf("%s must be at least %d characters", at, *s.MinLength))
+			}
+		}
+		if msg := checkFormat(s.Format, str); msg != "" {
+			*problems = append(*problems, at+" "+msg)
+		}
+	case "integer", "number":
+		want := " must be a number"
+		if s.Type == "integer" {
+			want = " must be an integer"
+		}
+		num, ok := value.(json.Number)
+		if !ok {
+			*problems = append(*problems, at+want)
+			return
+		}
+		f, err := num.Float64()
+		if s.Type == "integer" {
+			_, err = num.Int64()
+		}
+		if err != nil {
+			*problems = append(*problems, at+want)
+			return
+		}
+		if s.Minimum != nil && (f < *s.Minimum || (s.ExclusiveMinimum && f == *s.Minimum)) {
+			op := "at least"
+			if s.ExclusiveMinimum {
+				op = "greater than"
+			}
+			*problems = append(*problems, fmt.Sprintf("%s must be %s %v", at, op, *s.Minimum))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			*problems = append(*problems, fmt.Sprintf("%s must be at most %v", at, *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*problems = append(*problems, at+" must be true or false")
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		*problems = append(*problems, fmt.Sprintf("%s must be one of %s", at, strings.Join(allowed, ", ")))
+	}
+}
+
+// checkFormat checks the string formats used by the models
+func checkFormat(format, s string) string {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be an email address"
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URL"
+		}
+	case "uuid":
+		if !isUUID(s) {
+			return "must be a UUID"
+		}
+	}
+	return ""
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case !strings.ContainsRune("0123456789abcdefABCDEF", c):
+			return false
+		}
+	}
+	return true
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	if num, ok := value.(json.Number); ok {
+		f, _ := num.Float64()
+		value = f
+	}
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateBodySize(t *testing.T) {
+	op := &Operation{RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{
+		"application/json": {Schema: &Schema{Type: "object", Required: []string{"name"},
+			Properties: map[string]*Schema{"name": {Type: "string"}}}},
+	}}}
+	v := NewValidator(&Document{}, 32)
+
+	tests := []struct {
+		name         string
+		body         string
+		wantProblems int
+		wantTooLarge bool
+	}{
+		{"within the limit", `{"name":"PT Maju"}`, 0, false},
+		{"exactly the limit", `{"name":"` + strings.Repeat("a", 21) + `"}`, 0, false},
+		{"invalid but small", `{}`, 1, false},
+		{"over the limit", `{"name":"` + strings.Repeat("a", 64) + `"}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/clients", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			problems, err := v.Validate(op, r, nil)
+
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) != tt.wantTooLarge {
+				t.Fatalf("Validate error = %v, want too large %v", err, tt.wantTooLarge)
+			}
+			if tt.wantTooLarge {
+				if tooLarge.Limit != 32 {
+					t.Errorf("Limit = %d, want 32", tooLarge.Limit)
+				}
+				return
+			}
+			if err != nil || len(problems) != tt.wantProblems {
+				t.Fatalf("Validate = %v, %v; want %d problems", problems, err, tt.wantProblems)
+			}
+			// Body dikembalikan utuh agar handler tetap bisa bind
+			if raw, _ := io.ReadAll(r.Body); string(raw) != tt.body {
+				t.Errorf("body after Validate = %q, want %q", raw, tt.body)
+			}
+		})
+	}
+
+	// Tanpa batas, body besar tetap divalidasi
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/clients", strings.NewReader(`{"name":"`+strings.Repeat("a", 4096)+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if problems, err := NewValidator(&Document{}, 0).Validate(op, r, nil); err != nil || len(problems) != 0 {
+		t.Errorf("Validate without limit = %v, %v; want no problems", problems, err)
+	}
+}
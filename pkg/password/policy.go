@@ -0,0 +1,170 @@
+// Package password berisi kebijakan password staf: panjang minimal, variasi jenis karakter,
+// tidak memuat data pribadi, dan tidak ada di daftar password bocor (breached) lokal.
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength adalah batas bcrypt: byte setelah 72 diabaikan saat hashing.
+const MaxLength = 72
+
+// commonPasswords adalah daftar bawaan password yang paling sering dipakai, sehingga
+// pemeriksaan tetap berjalan walaupun daftar breached dari file tidak dikonfigurasi.
+//
+//go:embed common.txt
+var commonPasswords string
+
+// Policy adalah kebijakan password. Buat dengan NewPolicy.
+type Policy struct {
+	MinLength  int
+	MinClasses int // Jumlah minimal jenis karakter: huruf kecil, huruf besar, angka, simbol
+	breached   map[[sha1.Size]byte]struct{}
+}
+
+// PolicyError berisi semua alasan password ditolak, agar pengguna bisa memperbaikinya sekaligus.
+type PolicyError struct {
+	Problems []string
+}
+
+func (e *PolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Problems, "; ")
+}
+
+// NewPolicy membuat kebijakan password. breachedFile opsional: satu password per baris, atau
+// hash SHA-1 heksadesimal seperti dump Have I Been Pwned ("HASH:jumlah").
+func NewPolicy(minLength, minClasses int, breachedFile string) (*Policy, error) {
+	p := &Policy{
+		MinLength:  minLength,
+		MinClasses: minClasses,
+		breached:   make(map[[sha1.Size]byte]struct{}),
+	}
+	if err := p.load(strings.NewReader(commonPasswords)); err != nil {
+		return nil, err
+	}
+	if breachedFile != "" {
+		f, err := os.Open(breachedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open breached password list: %w", err)
+		}
+		defer f.Close()
+		if err := p.load(f); err != nil {
+			return nil, fmt.Errorf("failed to read breached password list %s: %w", breachedFile, err)
+		}
+	}
+	return p, nil
+}
+
+// BreachedCount mengembalikan jumlah entri daftar password bocor yang dimuat.
+func (p *Policy) BreachedCount() int {
+	return len(p.breached)
+}
+
+func (p *Policy) load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if sum, ok := parseSHA1(line); ok {
+			p.breached[sum] = struct{}{}
+			continue
+		}
+		p.breached[sha1.Sum([]byte(line))] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// parseSHA1 menerima "HASH" atau "HASH:jumlah" dengan HASH 40 digit heksadesimal.
+func parseSHA1(line string) ([sha1.Size]byte, bool) {
+	var sum [sha1.Size]byte
+	hash, _, _ := strings.Cut(line, ":")
+	if len(hash) != 2*sha1.Size {
+		return sum, false
+	}
+	if _, err := hex.Decode(sum[:], []byte(hash)); err != nil {
+		return sum, false
+	}
+	return sum, true
+}
+
+// Check memeriksa password terhadap kebijakan. personal adalah data pemilik akun (email, nama)
+// yang tidak boleh dipakai sebagai password. Hasilnya nil atau *PolicyError.
+func (p *Policy) Check(password string, personal ...string) error {
+	var problems []string
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes", MaxLength))
+	}
+	if classes := countClasses(password); classes < p.MinClasses {
+		problems = append(problems, fmt.Sprintf("must mix at least %d of: lowercase letters, uppercase letters, digits, symbols", p.MinClasses))
+	}
+	if containsPersonal(password, personal) {
+		problems = append(problems, "must not contain your name or email")
+	}
+	if p.isBreached(password) {
+		problems = append(problems, "appears in a list of breached or common passwords")
+	}
+	if len(problems) > 0 {
+		return &PolicyError{Problems: problems}
+	}
+	return nil
+}
+
+// isBreached juga memeriksa versi huruf kecil, karena "Password123" sama lemahnya dengan "password123".
+func (p *Policy) isBreached(password string) bool {
+	if _, ok := p.breached[sha1.Sum([]byte(password))]; ok {
+		return true
+	}
+	_, ok := p.breached[sha1.Sum([]byte(strings.ToLower(password)))]
+	return ok
+}
+
+func countClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// containsPersonal memeriksa bagian lokal email dan setiap kata nama yang cukup panjang
+// untuk bermakna (minimal 4 huruf), tanpa membedakan huruf besar/kecil.
+func containsPersonal(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(part) >= 4 && strings.Contains(lower, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
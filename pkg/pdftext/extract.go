@@ -0,0 +1,79 @@
+// Package pdftext extracts plain text from PDF files without cgo or external tools.
+package pdftext
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// MaxTextLength limits how much text is kept per document so a huge scan
+// doesn't bloat the search index.
+const MaxTextLength = 1 << 20 // 1 MiB
+
+// ExtractFile opens the PDF at path and returns its text content with
+// whitespace collapsed. Scanned PDFs without a text layer return "".
+func ExtractFile(path string) (text string, err error) {
+	// The underlying parser panics on malformed input, so convert that into an error.
+	defer func() {
+		if r := recover(); r != nil {
+			text = ""
+			err = fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+
+	f, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF text: %w", err)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(plain, MaxTextLength*2))
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF text: %w", err)
+	}
+
+	return Normalize(string(raw)), nil
+}
+
+// Normalize collapses runs of whitespace and drops control characters so the
+// stored text is stable for indexing and snippets. The result is cut at a rune
+// boundary to at most MaxTextLength bytes.
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case unicode.IsControl(r) || r == unicode.ReplacementChar:
+			continue
+		default:
+			sep := space && b.Len() > 0
+			n := utf8.RuneLen(r)
+			if sep {
+				n++
+			}
+			// Potong di batas karakter, jangan melebihi MaxTextLength
+			if b.Len()+n > MaxTextLength {
+				return b.String()
+			}
+			if sep {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
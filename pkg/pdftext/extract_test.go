@@ -0,0 +1,105 @@
+package pdftext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/iqsanfm/dashboard-pekerjaan-backend/pkg/pdfdoc"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", "", ""},
+		{"only whitespace", " \n\t\r\n ", ""},
+		{"trims and collapses", "  Bukti\tPenerimaan\n\n  Elektronik  ", "Bukti Penerimaan Elektronik"},
+		{"unicode spaces", "NTPN : 0A1B2C3D", "NTPN : 0A1B2C3D"},
+		{"control and replacement characters", "Fak\x00tur� Pajak\x07", "Faktur Pajak"},
+		{"keeps non-ASCII text", "Rp 1.250.000 — PPh Pasal 21 ✓", "Rp 1.250.000 — PPh Pasal 21 ✓"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTruncates(t *testing.T) {
+	for name, unit := range map[string]string{
+		"ASCII words":     "kata ",
+		"multibyte runes": "pajak—ñ ", // em dash 3 byte, ñ 2 byte
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := Normalize(strings.Repeat(unit, MaxTextLength/len(unit)+10))
+			if len(got) > MaxTextLength || len(got) < MaxTextLength-utf8.UTFMax-1 {
+				t.Errorf("len = %d, want at most %d and close to it", len(got), MaxTextLength)
+			}
+			if !utf8.ValidString(got) {
+				t.Error("result was cut inside a rune")
+			}
+			if strings.HasSuffix(got, " ") {
+				t.Error("result ends with a space")
+			}
+		})
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	doc := pdfdoc.New()
+	doc.AddPage().Text(72, 72, pdfdoc.Regular, 12, "Bukti Penerimaan Negara")
+	page := doc.AddPage()
+	page.Text(72, 72, pdfdoc.Regular, 12, "NTPN 0A1B2C3D4E5F6G7H")
+	page.Text(72, 96, pdfdoc.Bold, 12, "Jumlah Rp 125.000")
+
+	path := filepath.Join(t.TempDir(), "bpn.pdf")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.WriteTo(f); err != nil {
+		t.Fatalf("write PDF: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := ExtractFile(path)
+	if err != nil {
+		t.Fatalf("ExtractFile: %v", err)
+	}
+	for _, want := range []string{"Bukti Penerimaan Negara", "NTPN 0A1B2C3D4E5F6G7H", "Jumlah Rp 125.000"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text %q does not contain %q", text, want)
+		}
+	}
+	if text != Normalize(text) {
+		t.Errorf("text %q is not normalized", text)
+	}
+}
+
+// Parser PDF bisa panic pada file rusak; ExtractFile harus mengembalikan error
+func TestExtractFileMalformed(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"not a PDF":      "bukan pdf",
+		"truncated PDF":  "%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R",
+		"broken trailer": "%PDF-1.4\nxref\n0 1\n0000000000 65535 f \ntrailer\n<< /Root 9 0 R /Size 1 >>\nstartxref\n9\n%%EOF\n",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".pdf")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if text, err := ExtractFile(path); err == nil {
+			t.Errorf("%s: ExtractFile = %q, want an error", name, text)
+		}
+	}
+	if _, err := ExtractFile(filepath.Join(dir, "missing.pdf")); err == nil {
+		t.Error("missing file: want an error")
+	}
+}
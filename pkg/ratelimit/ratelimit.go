@@ -0,0 +1,99 @@
+// Package ratelimit berisi rate limiter token bucket dengan store yang bisa diganti: di memori
+// untuk satu instance, atau store bersama (mis. PostgreSQL) untuk beberapa instance.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit adalah ukuran bucket: maksimal Burst request sekaligus, lalu satu token kembali
+// setiap Refill.
+type Limit struct {
+	Burst  int
+	Refill time.Duration
+}
+
+// Result adalah hasil satu pengambilan token.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter adalah waktu sampai token berikutnya tersedia; nol jika Allowed.
+	RetryAfter time.Duration
+}
+
+// Store mengambil satu token dari bucket key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Apply menghitung bucket setelah elapsed berlalu sejak tokens terakhir disimpan, lalu
+// mengambil satu token jika ada. Dipakai semua Store agar perhitungannya sama.
+func Apply(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	if elapsed > 0 && limit.Refill > 0 {
+		tokens += float64(elapsed) / float64(limit.Refill)
+	}
+	tokens = math.Min(tokens, float64(limit.Burst))
+	if tokens >= 1 {
+		tokens--
+		return tokens, Result{Allowed: true, Remaining: int(tokens)}
+	}
+	wait := time.Duration((1 - tokens) * float64(limit.Refill))
+	return tokens, Result{RetryAfter: wait}
+}
+
+// IdleAfter adalah lama sebuah bucket penuh kembali; setelah itu bucket boleh dihapus karena
+// sama dengan bucket baru.
+func (l Limit) IdleAfter() time.Duration {
+	return time.Duration(l.Burst) * l.Refill
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	idle    time.Duration
+}
+
+// MemoryStore menyimpan bucket di memori proses. Cocok untuk satu instance; dengan beberapa
+// instance setiap instance punya batasnya sendiri.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// memorySweepInterval adalah jarak pembersihan bucket yang sudah penuh kembali.
+const memorySweepInterval = time.Minute
+
+// NewMemoryStore membuat MemoryStore kosong.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for k, b := range s.buckets {
+			if now.Sub(b.updated) >= b.idle {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	tokens, result := Apply(b.tokens, now.Sub(b.updated), limit)
+	b.tokens, b.updated, b.idle = tokens, now, limit.IdleAfter()
+	return result, nil
+}
@@ -0,0 +1,158 @@
+// Package tabular membaca file tabel sederhana (CSV atau XLSX) menjadi header dan baris data.
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxFileSize membatasi ukuran file yang dibaca.
+const MaxFileSize = 10 << 20
+
+// ErrUnsupportedFormat dikembalikan untuk file selain .csv dan .xlsx.
+var ErrUnsupportedFormat = errors.New("format file harus .csv atau .xlsx")
+
+// Row adalah satu baris data beserta nomor barisnya di file (mulai dari 1, termasuk header).
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Table adalah isi file: baris tidak kosong pertama dianggap header.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Value mengembalikan isi sel pada kolom idx, atau string kosong jika kolom tidak ada.
+func (r Row) Value(idx int) string {
+	if idx < 0 || idx >= len(r.Cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.Cells[idx])
+}
+
+// Read membaca file CSV atau XLSX (sheet pertama).
+func Read(fileName string, r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file: %w", err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("ukuran file melebihi %d MB", MaxFileSize>>20)
+	}
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{}
+	for i, rec := range records {
+		if isEmpty(rec) {
+			continue
+		}
+		if table.Header == nil {
+			table.Header = trimAll(rec)
+			continue
+		}
+		table.Rows = append(table.Rows, Row{Number: i + 1, Cells: rec})
+	}
+	if table.Header == nil {
+		return nil, errors.New("file tidak berisi data")
+	}
+	return table, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM dari Excel
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	// Nomor baris dipertahankan agar sama dengan yang dilihat user di file,
+	// jadi baris kosong tidak dibuang di sini.
+	var records [][]string
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, nil)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca XLSX: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("file XLSX tidak memiliki sheet")
+	}
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca sheet %s: %w", sheets[0], err)
+	}
+	return rows, nil
+}
+
+// detectDelimiter memilih pemisah kolom dari baris pertama. Excel berbahasa Indonesia
+// menyimpan CSV dengan titik koma.
+func detectDelimiter(data []byte) rune {
+	firstLine := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = data[:idx]
+	}
+	best, bestCount := ',', bytes.Count(firstLine, []byte(","))
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(firstLine, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func isEmpty(rec []string) bool {
+	for _, c := range rec {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func trimAll(rec []string) []string {
+	out := make([]string, len(rec))
+	for i, c := range rec {
+		out[i] = strings.TrimSpace(c)
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+// Package taxcalendar menghitung batas waktu pelaporan pajak yang dipakai
+// untuk laporan kepatuhan dan beban kerja.
+//
+// Query agregat di repositories menghitung batas waktu yang sama di SQL dari
+// konstanta di bawah, sehingga aturannya hanya ditulis di sini.
+package taxcalendar
+
+import "time"
+
+const (
+	// MonthlyReportDay adalah tanggal batas lapor SPT Masa pada bulan berikutnya.
+	MonthlyReportDay = 20
+	// AnnualReportMonth dan AnnualReportDay adalah batas lapor SPT Tahunan badan
+	// pada tahun berikutnya (30 April).
+	AnnualReportMonth = time.April
+	AnnualReportDay   = 30
+	// Sp2dkResponseDays adalah batas tanggapan SP2DK dalam hari setelah tanggal SP2DK.
+	Sp2dkResponseDays = 14
+	// AuditMonths adalah target penyelesaian pemeriksaan dalam bulan setelah tanggal SP2.
+	AuditMonths = 6
+)
+
+// MonthlyDueDate mengembalikan batas lapor SPT Masa untuk masa pajak year/month,
+// yaitu tanggal 20 bulan berikutnya (00:00 di zona waktu loc).
+func MonthlyDueDate(year, month int, loc *time.Location) time.Time {
+	return time.Date(year, time.Month(month)+1, MonthlyReportDay, 0, 0, 0, 0, loc)
+}
+
+// IsPastDue melaporkan apakah due sudah terlewati pada waktu now.
+// Hari jatuh tempo itu sendiri belum dihitung terlambat.
+func IsPastDue(due, now time.Time) bool {
+	return !now.Before(due.AddDate(0, 0, 1))
+}
@@ -0,0 +1,101 @@
+// Package taxpayment berisi validasi bukti pembayaran pajak: kode billing dan NTPN.
+package taxpayment
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// BillingCodeLength adalah panjang kode billing DJP (15 digit).
+	BillingCodeLength = 15
+	// NTPNLength adalah panjang Nomor Transaksi Penerimaan Negara (16 karakter).
+	NTPNLength = 16
+)
+
+var (
+	ErrInvalidBillingCode = errors.New("kode billing harus 15 digit angka")
+	ErrInvalidNTPN        = errors.New("NTPN harus 16 karakter huruf/angka")
+	ErrBillingCodeExpired = errors.New("tanggal pembayaran melewati masa berlaku kode billing")
+)
+
+// NormalizeBillingCode membuang pemisah yang biasa dipakai saat menyalin kode billing
+// (spasi, titik, tanda hubung).
+func NormalizeBillingCode(code string) string {
+	return stripSeparators(code)
+}
+
+// ValidateBillingCode memeriksa kode billing yang sudah dinormalisasi.
+func ValidateBillingCode(code string) error {
+	if len(code) != BillingCodeLength {
+		return ErrInvalidBillingCode
+	}
+	allZero := true
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return ErrInvalidBillingCode
+		}
+		if r != '0' {
+			allZero = false
+		}
+	}
+	if allZero {
+		return ErrInvalidBillingCode
+	}
+	return nil
+}
+
+// NormalizeNTPN membuang pemisah dan mengubah NTPN menjadi huruf besar.
+func NormalizeNTPN(ntpn string) string {
+	return strings.ToUpper(stripSeparators(ntpn))
+}
+
+// ValidateNTPN memeriksa NTPN yang sudah dinormalisasi.
+func ValidateNTPN(ntpn string) error {
+	if len(ntpn) != NTPNLength {
+		return ErrInvalidNTPN
+	}
+	for _, r := range ntpn {
+		if !(r >= '0' && r <= '9') && !(r >= 'A' && r <= 'Z') {
+			return ErrInvalidNTPN
+		}
+	}
+	return nil
+}
+
+// CheckExpiry memastikan pembayaran tidak dilakukan setelah kode billing kedaluwarsa.
+// Perbandingan dilakukan per tanggal karena payment_date disimpan sebagai DATE.
+func CheckExpiry(expiresAt, paymentDate *time.Time) error {
+	if expiresAt == nil || paymentDate == nil {
+		return nil
+	}
+	y1, m1, d1 := paymentDate.Date()
+	y2, m2, d2 := expiresAt.Date()
+	paid := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	if paid.After(expiry) {
+		return ErrBillingCodeExpired
+	}
+	return nil
+}
+
+// AmountsMismatch bernilai true jika jumlah pada kode billing berbeda dengan jumlah
+// yang dibayar. Jika salah satu belum diisi, tidak dianggap selisih.
+func AmountsMismatch(billingAmount, paidAmount *float64) bool {
+	if billingAmount == nil || paidAmount == nil {
+		return false
+	}
+	return math.Abs(*billingAmount-*paidAmount) >= 0.005
+}
+
+func stripSeparators(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, s)
+}
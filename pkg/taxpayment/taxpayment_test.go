@@ -0,0 +1,112 @@
+package taxpayment
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBillingCode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{"123456789012345", "123456789012345", nil},
+		{"1234 5678 9012 345", "123456789012345", nil},
+		{"12345-67890-12345", "123456789012345", nil},
+		{"123.456.789.012.345", "123456789012345", nil},
+		{"12345678901234", "12345678901234", ErrInvalidBillingCode},
+		{"1234567890123456", "1234567890123456", ErrInvalidBillingCode},
+		{"12345678901234A", "12345678901234A", ErrInvalidBillingCode},
+		{"000000000000000", "000000000000000", ErrInvalidBillingCode},
+		{"", "", ErrInvalidBillingCode},
+	}
+	for _, tt := range tests {
+		got := NormalizeBillingCode(tt.in)
+		if got != tt.want {
+			t.Errorf("NormalizeBillingCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if err := ValidateBillingCode(got); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateBillingCode(%q) = %v, want %v", got, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNTPN(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{"0A1B2C3D4E5F6G7H", "0A1B2C3D4E5F6G7H", nil},
+		{"0a1b-2c3d-4e5f-6g7h", "0A1B2C3D4E5F6G7H", nil},
+		{"1234 5678 9012 3456", "1234567890123456", nil},
+		{"0A1B2C3D4E5F6G7", "0A1B2C3D4E5F6G7", ErrInvalidNTPN},
+		{"0A1B2C3D4E5F6G7H8", "0A1B2C3D4E5F6G7H8", ErrInvalidNTPN},
+		{"0A1B2C3D4E5F6G/H", "0A1B2C3D4E5F6G/H", ErrInvalidNTPN},
+		{"", "", ErrInvalidNTPN},
+	}
+	for _, tt := range tests {
+		got := NormalizeNTPN(tt.in)
+		if got != tt.want {
+			t.Errorf("NormalizeNTPN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if err := ValidateNTPN(got); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateNTPN(%q) = %v, want %v", got, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckExpiry(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	expiry := time.Date(2025, 3, 10, 23, 59, 0, 0, jakarta)
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		paidAt    *time.Time
+		wantErr   error
+	}{
+		{"before expiry", &expiry, date(2025, 3, 9), nil},
+		{"on the expiry date", &expiry, date(2025, 3, 10), nil},
+		{"after expiry", &expiry, date(2025, 3, 11), ErrBillingCodeExpired},
+		{"no expiry", nil, date(2025, 3, 11), nil},
+		{"not paid", &expiry, nil, nil},
+	}
+	for _, tt := range tests {
+		if err := CheckExpiry(tt.expiresAt, tt.paidAt); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: CheckExpiry = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAmountsMismatch(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		billing, paid *float64
+		want          bool
+	}{
+		{f(1500000), f(1500000), false},
+		{f(1500000), f(1500000.004), false},
+		{f(1500000), f(1500000.01), true},
+		{f(1500000), f(1000000), true},
+		{nil, f(1000000), false},
+		{f(1500000), nil, false},
+	}
+	for _, tt := range tests {
+		if got := AmountsMismatch(tt.billing, tt.paid); got != tt.want {
+			t.Errorf("AmountsMismatch(%v, %v) = %v, want %v", deref(tt.billing), deref(tt.paid), got, tt.want)
+		}
+	}
+}
+
+func deref(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
@@ -0,0 +1,127 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret ASCII "12345678901234567890" dari lampiran B RFC 6238, dalam base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// Vektor SHA1 dari RFC 6238 (8 digit), diambil 6 digit terakhir
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeSecretFormat(t *testing.T) {
+	want, _ := Code(rfcSecret, 1)
+	// Secret yang diketik manual: huruf kecil, spasi dan padding tetap diterima
+	for _, secret := range []string{strings.ToLower(rfcSecret), "GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ", rfcSecret + "===="} {
+		if got, err := Code(secret, 1); err != nil || got != want {
+			t.Errorf("Code(%q) = %s, %v; want %s", secret, got, err, want)
+		}
+	}
+	if _, err := Code("bukan base32!", 1); err == nil {
+		t.Error("Code with an invalid secret did not fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	counter := Counter(now)
+	code := func(offset int64) string {
+		c, err := Code(rfcSecret, counter+offset)
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		skew        int
+		wantCounter int64
+		wantOK      bool
+	}{
+		{"current period", code(0), 1, counter, true},
+		{"surrounding spaces", " " + code(0) + " ", 1, counter, true},
+		{"previous period within skew", code(-1), 1, counter - 1, true},
+		{"next period within skew", code(1), 1, counter + 1, true},
+		{"two periods ago outside skew", code(-2), 1, 0, false},
+		{"two periods ahead outside skew", code(2), 1, 0, false},
+		{"previous period without skew", code(-1), 0, 0, false},
+		{"two periods ago with skew 2", code(-2), 2, counter - 2, true},
+		{"wrong code", "000000", 1, 0, false},
+		{"too short", code(0)[:5], 1, 0, false},
+		{"empty", "", 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || got != tt.wantCounter {
+				t.Errorf("Validate = %d, %v; want %d, %v", got, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	b, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(a)
+	if err != nil || len(key) != SecretSize {
+		t.Errorf("GenerateSecret = %q, want %d bytes of base32 without padding", a, SecretSize)
+	}
+	if a == b {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI(rfcSecret, "Dashboard Pekerjaan", "budi@kantor.id")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", uri, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Dashboard Pekerjaan:budi@kantor.id" {
+		t.Errorf("URI = %s, want otpauth://totp/Dashboard%%20Pekerjaan:budi@kantor.id", uri)
+	}
+	if strings.Contains(uri, "+") {
+		t.Errorf("URI = %s, spaces must be encoded as %%20 for authenticator apps", uri)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "Dashboard Pekerjaan", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := q.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}